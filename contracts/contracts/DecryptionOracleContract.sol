@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: MIT
+
+pragma solidity =0.8.4;
+
+import {ConfigContract} from "./ConfigContract.sol";
+import {KeyBroadcastContract} from "./KeyBroadcastContract.sol";
+
+/// @title A contract to which keypers submit epoch secret keys once they have been generated. The
+///     keys are verified against the eon public key voted for in the key broadcast contract, so
+///     consumers can use them to decrypt data without having to trust the submitter.
+contract DecryptionOracleContract {
+    /// @notice The event emitted when an epoch secret key has been submitted.
+    /// @param batchIndex The batch index, i.e. the epoch, the key belongs to.
+    /// @param eonStartBatchIndex The start batch index of the eon the key belongs to.
+    /// @param key The epoch secret key as a marshaled G1 point.
+    /// @param submitter The keyper who submitted the key.
+    event EpochKeySubmitted(
+        uint64 indexed batchIndex,
+        uint64 eonStartBatchIndex,
+        bytes key,
+        address submitter
+    );
+
+    // The field modulus of the alt_bn128 curve
+    uint256 private constant FIELD_MODULUS =
+        21888242871839275222246405745257275060479133081116023110063138880059578383591;
+
+    // The generator of G2 in the encoding expected by the pairing precompile
+    uint256 private constant G2_X_IMAG =
+        11559732032986387107991004021392285783925812861821192530917403151452391805634;
+    uint256 private constant G2_X_REAL =
+        10857046999023057135944570762232829481370756359578518086990519993285655852781;
+    uint256 private constant G2_Y_IMAG =
+        4082367875863433681332203403145435568316851327593401208105741076214120093531;
+    uint256 private constant G2_Y_REAL =
+        8495653923123431417604973247489272438418190587263600148770280649306958101930;
+
+    ConfigContract public configContract;
+    KeyBroadcastContract public keyBroadcastContract;
+
+    mapping(uint64 => bytes) private _keys; // batch index => epoch secret key
+
+    constructor(
+        ConfigContract configContractAddress,
+        KeyBroadcastContract keyBroadcastContractAddress
+    ) {
+        configContract = configContractAddress;
+        keyBroadcastContract = keyBroadcastContractAddress;
+    }
+
+    /// @notice Submit the epoch secret key for a batch.
+    /// @notice Can only be called by keypers defined in the config responsible for `batchIndex`,
+    ///     and only once per batch.
+    /// @param keyperIndex The index of the calling keyper in the batch config.
+    /// @param batchIndex The index of the batch the key belongs to.
+    /// @param eonStartBatchIndex The start batch index of the eon the key belongs to.
+    /// @param key The epoch secret key.
+    function submitEpochKey(
+        uint64 keyperIndex,
+        uint64 batchIndex,
+        uint64 eonStartBatchIndex,
+        bytes memory key
+    ) external {
+        uint64 configIndex = configContract.configIndexForBatchIndex(
+            batchIndex
+        );
+        require(
+            keyperIndex < configContract.configNumKeypers(configIndex),
+            "DecryptionOracleContract: keyper index out of range"
+        );
+        require(
+            msg.sender ==
+                configContract.configKeypers(configIndex, keyperIndex),
+            "DecryptionOracleContract: sender is not keyper"
+        );
+        require(
+            _keys[batchIndex].length == 0,
+            "DecryptionOracleContract: key already submitted"
+        );
+        require(
+            eonStartBatchIndex <= batchIndex,
+            "DecryptionOracleContract: eon starts after batch"
+        );
+
+        uint64 eonConfigIndex = configContract.configIndexForBatchIndex(
+            eonStartBatchIndex
+        );
+        require(
+            keyBroadcastContract.getBestKeyNumVotes(eonStartBatchIndex) >=
+                configContract.configThreshold(eonConfigIndex),
+            "DecryptionOracleContract: eon key not confirmed"
+        );
+        bytes memory eonKey = keyBroadcastContract.getBestKey(
+            eonStartBatchIndex
+        );
+        require(
+            verifyEpochKey(key, eonKey, batchIndex),
+            "DecryptionOracleContract: invalid key"
+        );
+
+        _keys[batchIndex] = key;
+        emit EpochKeySubmitted({
+            batchIndex: batchIndex,
+            eonStartBatchIndex: eonStartBatchIndex,
+            key: key,
+            submitter: msg.sender
+        });
+    }
+
+    function hasEpochKey(uint64 batchIndex) public view returns (bool) {
+        return _keys[batchIndex].length > 0;
+    }
+
+    function getEpochKey(uint64 batchIndex)
+        public
+        view
+        returns (bytes memory)
+    {
+        return _keys[batchIndex];
+    }
+
+    /// @notice Check that `key` is the epoch secret key for `batchIndex` given the eon public
+    ///     key, i.e. that e(key, g2) == e(epochID, eonKey) holds.
+    function verifyEpochKey(
+        bytes memory key,
+        bytes memory eonKey,
+        uint64 batchIndex
+    ) public view returns (bool) {
+        if (key.length != 64 || eonKey.length != 128) {
+            return false;
+        }
+        (uint256 idX, uint256 idY) = _epochID(batchIndex);
+
+        uint256[12] memory input;
+        (input[0], input[1]) = abi.decode(key, (uint256, uint256));
+        input[2] = G2_X_IMAG;
+        input[3] = G2_X_REAL;
+        input[4] = G2_Y_IMAG;
+        input[5] = G2_Y_REAL;
+        input[6] = idX;
+        input[7] = (FIELD_MODULUS - idY) % FIELD_MODULUS;
+        (input[8], input[9], input[10], input[11]) = abi.decode(
+            eonKey,
+            (uint256, uint256, uint256, uint256)
+        );
+
+        uint256[1] memory result;
+        bool success;
+        assembly {
+            success := staticcall(gas(), 8, input, 384, result, 32)
+        }
+        return success && result[0] == 1;
+    }
+
+    // _epochID computes (batchIndex + 1) * g1 like shcrypto.ComputeEpochID does.
+    function _epochID(uint64 batchIndex)
+        internal
+        view
+        returns (uint256, uint256)
+    {
+        uint256[3] memory input;
+        input[0] = 1;
+        input[1] = 2;
+        input[2] = uint256(batchIndex) + 1;
+        uint256[2] memory result;
+        bool success;
+        assembly {
+            success := staticcall(gas(), 7, input, 96, result, 64)
+        }
+        require(success, "DecryptionOracleContract: ecMul failed");
+        return (result[0], result[1]);
+    }
+}
@@ -7,6 +7,7 @@ pragma solidity =0.8.4;
 
 import "./BatcherContract.sol";
 import "./ConfigContract.sol";
+import "./DecryptionOracleContract.sol";
 import "./DepositContract.sol";
 import "./ExecutorContract.sol";
 import "./FeeBankContract.sol";
@@ -156,6 +156,19 @@ def key_broadcast_contract(
     return key_broadcast_contract
 
 
+@pytest.fixture
+def decryption_oracle_contract(
+    DecryptionOracleContract: ContractContainer,
+    config_contract: Any,
+    key_broadcast_contract: Any,
+    accounts: Sequence[Account],
+) -> Any:
+    decryption_oracle_contract = accounts[0].deploy(
+        DecryptionOracleContract, config_contract.address, key_broadcast_contract.address
+    )
+    return decryption_oracle_contract
+
+
 @pytest.fixture
 def target_proxy_contract(
     TargetProxyContract: ContractContainer,
@@ -0,0 +1,61 @@
+from typing import Any
+from typing import Sequence
+
+import brownie
+from brownie.network.account import Account
+from eth_utils import to_canonical_address
+
+from tests.contract_helpers import schedule_config
+from tests.factories import make_batch_config
+from tests.factories import make_bytes
+
+
+def test_submit_checks_sender(
+    decryption_oracle_contract: Any,
+    config_contract: Any,
+    owner: Account,
+    accounts: Sequence[Account],
+) -> None:
+    keypers = accounts[1:4]
+    keyper_addresses = [to_canonical_address(k.address) for k in keypers]
+    config = make_batch_config(start_batch_index=0, keypers=keyper_addresses, batch_span=1)
+    schedule_config(config_contract, config, owner=owner)
+
+    key = make_bytes(64)
+    for keyper_index, sender in [
+        (1, accounts[5]),
+        (1, keypers[2]),
+    ]:
+        with brownie.reverts("DecryptionOracleContract: sender is not keyper"):
+            decryption_oracle_contract.submitEpochKey(keyper_index, 10, 0, key, {"from": sender})
+    with brownie.reverts("DecryptionOracleContract: keyper index out of range"):
+        decryption_oracle_contract.submitEpochKey(3, 10, 0, key, {"from": keypers[0]})
+
+
+def test_submit_requires_confirmed_eon_key(
+    decryption_oracle_contract: Any,
+    key_broadcast_contract: Any,
+    config_contract: Any,
+    owner: Account,
+    accounts: Sequence[Account],
+) -> None:
+    keypers = accounts[1:4]
+    keyper_addresses = [to_canonical_address(k.address) for k in keypers]
+    config = make_batch_config(
+        start_batch_index=0, keypers=keyper_addresses, threshold=2, batch_span=1
+    )
+    schedule_config(config_contract, config, owner=owner)
+
+    key = make_bytes(64)
+    with brownie.reverts("DecryptionOracleContract: eon starts after batch"):
+        decryption_oracle_contract.submitEpochKey(0, 10, 11, key, {"from": keypers[0]})
+
+    key_broadcast_contract.vote(0, 0, make_bytes(128), {"from": keypers[0]})
+    with brownie.reverts("DecryptionOracleContract: eon key not confirmed"):
+        decryption_oracle_contract.submitEpochKey(0, 10, 0, key, {"from": keypers[0]})
+
+
+def test_verify_rejects_malformed_keys(decryption_oracle_contract: Any) -> None:
+    assert not decryption_oracle_contract.verifyEpochKey(make_bytes(63), make_bytes(128), 0)
+    assert not decryption_oracle_contract.verifyEpochKey(make_bytes(64), make_bytes(127), 0)
+    assert not decryption_oracle_contract.hasEpochKey(0)
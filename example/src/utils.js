@@ -99,7 +99,12 @@ async function encodeMessage(receiver, message, nonce, privateKey) {
   return proxyContractData;
 }
 
-async function encryptMessage(message, eonPublicKey, batchIndex) {
+async function encryptMessage(
+  message,
+  eonPublicKey,
+  batchIndex,
+  encryptionVersion = 0
+) {
   var sigma = new Uint8Array(32);
   window.crypto.getRandomValues(sigma);
   const messageArray = ethers.utils.arrayify(message);
@@ -108,7 +113,8 @@ async function encryptMessage(message, eonPublicKey, batchIndex) {
     messageArray,
     publicKeyArray,
     batchIndex.toNumber(),
-    sigma
+    sigma,
+    encryptionVersion
   );
   if (result.error !== null) {
     throw result.error;
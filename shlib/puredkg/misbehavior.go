@@ -0,0 +1,91 @@
+package puredkg
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+type MisbehaviorKind int
+
+//go:generate stringer -type=MisbehaviorKind
+const (
+	// MissingCommitment means the keyper did not send a poly commitment.
+	MissingCommitment = MisbehaviorKind(iota)
+	// MissingApology means the keyper did not apologize for an accusation.
+	MissingApology
+	// InvalidApology means the keyper apologized with a poly eval that doesn't match their
+	// commitment.
+	InvalidApology
+)
+
+// Misbehavior describes why a keyper has been considered corrupt during the DKG process.
+type Misbehavior struct {
+	Keyper KeyperIndex
+	Kind   MisbehaviorKind
+	// Accuser references the accusation the misbehavior is based on. It is only set for
+	// MissingApology and InvalidApology.
+	Accuser KeyperIndex
+	// Eval is the poly eval sent in the apology. It is only set for InvalidApology.
+	Eval *big.Int
+}
+
+func (m Misbehavior) String() string {
+	switch m.Kind {
+	case MissingCommitment:
+		return fmt.Sprintf("keyper %d: %s", m.Keyper, m.Kind)
+	default:
+		return fmt.Sprintf("keyper %d: %s (accuser %d)", m.Keyper, m.Kind, m.Accuser)
+	}
+}
+
+// Misbehaviors returns the list of misbehaviors detected during the DKG process, sorted by keyper
+// and accuser. An error is returned if this is called before finalization.
+func (pure *PureDKG) Misbehaviors() ([]Misbehavior, error) {
+	if pure.Phase < Finalized {
+		return nil, errors.Errorf("dkg is not finalized yet")
+	}
+	var res []Misbehavior
+	for dealer := uint64(0); dealer < pure.NumKeypers; dealer++ {
+		res = append(res, pure.misbehaviorsOf(dealer)...)
+	}
+	return res, nil
+}
+
+// misbehaviorsOf returns the misbehaviors of the given keyper known so far.
+func (pure *PureDKG) misbehaviorsOf(dealer KeyperIndex) []Misbehavior {
+	c := pure.Commitments[dealer]
+	if c == nil {
+		return []Misbehavior{{Keyper: dealer, Kind: MissingCommitment}}
+	}
+
+	var res []Misbehavior
+	for key := range pure.Accusations {
+		if key.Accused != dealer {
+			continue
+		}
+		_, apologized := pure.Apologies[key]
+		if !apologized {
+			res = append(res, Misbehavior{Keyper: dealer, Kind: MissingApology, Accuser: key.Accuser})
+		}
+	}
+	for key, eval := range pure.Apologies {
+		if key.Accused != dealer {
+			continue
+		}
+		if !shcrypto.VerifyPolyEval(int(key.Accuser), eval, c, pure.Threshold) {
+			res = append(res, Misbehavior{Keyper: dealer, Kind: InvalidApology, Accuser: key.Accuser, Eval: eval})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Accuser != res[j].Accuser {
+			return res[i].Accuser < res[j].Accuser
+		}
+		return res[i].Kind < res[j].Kind
+	})
+	return res
+}
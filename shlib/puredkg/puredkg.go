@@ -83,6 +83,7 @@ type PureDKG struct {
 	Evals       []*big.Int
 	Accusations map[accusationKey]struct{}
 	Apologies   map[accusationKey]*big.Int
+	Destroyed   bool // the secret parts have been zeroed, see Destroy
 }
 
 func NewPureDKG(eon uint64, numKeypers uint64, threshold uint64, keyper KeyperIndex) PureDKG {
@@ -190,18 +191,33 @@ func (pure *PureDKG) StartPhase2Accusing() []AccusationMsg {
 	return accusations
 }
 
+// VerifyOwnEval checks that the poly eval we've dealt to the given keyper matches the commitment
+// the other keypers have received from us.
+func (pure *PureDKG) VerifyOwnEval(receiver KeyperIndex) bool {
+	c := pure.Commitments[pure.Keyper]
+	if c == nil || pure.Polynomial == nil {
+		return false
+	}
+	eval := pure.Polynomial.EvalForKeyper(int(receiver))
+	return shcrypto.VerifyPolyEval(int(receiver), eval, c, pure.Threshold)
+}
+
+// StartPhase3Apologizing returns the apologies for the accusations against us. We only apologize
+// with poly evals that match the commitment the other keypers have received from us, as anything
+// else would not clear us and only reveal information about our polynomial.
 func (pure *PureDKG) StartPhase3Apologizing() []ApologyMsg {
 	pure.setPhase(Apologizing)
 	var apologies []ApologyMsg
 	for key := range pure.Accusations {
-		if key.Accused == pure.Keyper {
-			apologies = append(apologies, ApologyMsg{
-				Eon:     pure.Eon,
-				Accuser: key.Accuser,
-				Accused: key.Accused,
-				Eval:    pure.Polynomial.EvalForKeyper(int(key.Accuser)),
-			})
+		if key.Accused != pure.Keyper || !pure.VerifyOwnEval(key.Accuser) {
+			continue
 		}
+		apologies = append(apologies, ApologyMsg{
+			Eon:     pure.Eon,
+			Accuser: key.Accuser,
+			Accused: key.Accused,
+			Eval:    pure.Polynomial.EvalForKeyper(int(key.Accuser)),
+		})
 	}
 	return apologies
 }
@@ -210,6 +226,20 @@ func (pure *PureDKG) Finalize() {
 	pure.setPhase(Finalized)
 }
 
+// Destroy overwrites our polynomial and the poly evals we've received with zeros. Call it once
+// the result has been computed, or the DKG has failed. Afterwards, only the public parts of the
+// DKG can be used, e.g. to compute the transcript or the misbehaviors, while ComputeResult fails.
+// The poly evals revealed in apologies are public and kept.
+func (pure *PureDKG) Destroy() {
+	pure.Polynomial.Zero()
+	pure.Polynomial = nil
+	for i, eval := range pure.Evals {
+		shcrypto.ZeroInt(eval)
+		pure.Evals[i] = nil
+	}
+	pure.Destroyed = true
+}
+
 // ShortInfo returns a short string to be used in log output, which describes the current state of the DKG.
 func (pure *PureDKG) ShortInfo() string {
 	var numCommitments, numCorrupt, numAccusations, numApologies int
@@ -226,7 +256,7 @@ func (pure *PureDKG) ShortInfo() string {
 
 		if pure.isCorrupt(dealer) {
 			numCorrupt++
-		} else if pure.Phase > Dealing && (eval == nil || !shcrypto.VerifyPolyEval(int(pure.Keyper), eval, c, pure.Threshold)) {
+		} else if pure.Phase > Dealing && !pure.Destroyed && (eval == nil || !shcrypto.VerifyPolyEval(int(pure.Keyper), eval, c, pure.Threshold)) {
 			numCorrupt++
 		}
 	}
@@ -246,6 +276,9 @@ func (pure *PureDKG) ComputeResult() (Result, error) {
 	if pure.Phase < Finalized {
 		return Result{}, errors.Errorf("dkg is not finalized yet")
 	}
+	if pure.Destroyed {
+		return Result{}, errors.Errorf("dkg secrets have been destroyed")
+	}
 
 	numParticipants := 0
 	commitments := []*shcrypto.Gammas{}
@@ -298,35 +331,7 @@ func (pure *PureDKG) ComputeResult() (Result, error) {
 // isCorrupt checks if the given keyper is considered corrupt. Note that this might change when
 // new messages are received.
 func (pure *PureDKG) isCorrupt(dealer KeyperIndex) bool {
-	// a keyper is corrupt if they haven't sent a commitment
-	c := pure.Commitments[dealer]
-	if c == nil {
-		return true
-	}
-
-	// a keyper is corrupt if they have apologized with a poly eval that doesn't match their
-	// commitment
-	for accusationKey, polyEval := range pure.Apologies {
-		if accusationKey.Accused != dealer {
-			continue
-		}
-		if !shcrypto.VerifyPolyEval(int(accusationKey.Accuser), polyEval, c, pure.Threshold) {
-			return true
-		}
-	}
-
-	// a keyper is corrupt if they haven't apologized in case of an accusation
-	for accusationKey := range pure.Accusations {
-		if accusationKey.Accused != dealer {
-			continue
-		}
-		_, apologized := pure.Apologies[accusationKey]
-		if !apologized {
-			return true
-		}
-	}
-
-	return false
+	return len(pure.misbehaviorsOf(dealer)) > 0
 }
 
 // polyEval returns the poly eval received from the given dealer. Only call this function if the
@@ -63,10 +63,39 @@ func TestPureDKGFull(t *testing.T) {
 		result, err := dkg.ComputeResult()
 		assert.NilError(t, err)
 		results = append(results, result)
+
+		misbehaviors, err := dkg.Misbehaviors()
+		assert.NilError(t, err)
+		assert.Equal(t, 0, len(misbehaviors))
 	}
 	for _, r := range results {
 		assert.Assert(t, reflect.DeepEqual(r.PublicKey, results[0].PublicKey))
 	}
+
+	// destroying the secrets keeps the public parts
+	dkg := dkgs[0]
+	info := dkg.ShortInfo()
+	transcript, err := dkg.Transcript()
+	assert.NilError(t, err)
+	evalWords := dkg.Evals[1].Bits()
+	coefficientWords := (*dkg.Polynomial)[0].Bits()
+
+	dkg.Destroy()
+	assert.Assert(t, dkg.Polynomial == nil)
+	for _, eval := range dkg.Evals {
+		assert.Assert(t, eval == nil)
+	}
+	for _, words := range [][]big.Word{evalWords, coefficientWords} {
+		for _, w := range words {
+			assert.Equal(t, w, big.Word(0))
+		}
+	}
+	assert.Equal(t, dkg.ShortInfo(), info)
+	destroyedTranscript, err := dkg.Transcript()
+	assert.NilError(t, err)
+	assert.Assert(t, reflect.DeepEqual(destroyedTranscript, transcript))
+	_, err = dkg.ComputeResult()
+	assert.ErrorContains(t, err, "destroyed")
 }
 
 // TestPureDKGOfflineSendAccusation tests that we send accusations when we don't receive any
@@ -172,10 +201,64 @@ func TestPureDKGCorrupt(t *testing.T) {
 		result, err := dkg.ComputeResult()
 		assert.NilError(t, err)
 		results = append(results, result)
+
+		misbehaviors, err := dkg.Misbehaviors()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, []Misbehavior{{
+			Keyper:  2,
+			Kind:    InvalidApology,
+			Accuser: 0,
+			Eval:    big.NewInt(121212),
+		}}, misbehaviors, shtest.BigIntComparer)
 	}
 	for _, r := range results {
 		assert.Assert(t, reflect.DeepEqual(r.PublicKey, results[0].PublicKey))
 	}
+
+	for i, dkg := range dkgs {
+		transcript, err := dkg.Transcript()
+		assert.NilError(t, err)
+		assert.NilError(t, transcript.Verify())
+		assert.NilError(t, transcript.VerifyCommitments(dkg.Commitments))
+		assert.Assert(t, transcript.IsParticipant(0))
+		assert.Assert(t, transcript.IsParticipant(1))
+		assert.Assert(t, !transcript.IsParticipant(2))
+		assert.Assert(t, transcript.PublicKey.Equal(results[i].PublicKey))
+		for j, share := range results[i].PublicKeyShares {
+			assert.Assert(t, transcript.PublicKeyShare(uint64(j)).Equal(share))
+		}
+
+		transcript.Participants[0] |= 4
+		assert.ErrorContains(t, transcript.VerifyCommitments(dkg.Commitments), "does not match")
+		transcript.Participants[0] = 1
+		assert.ErrorContains(t, transcript.Verify(), "threshold")
+	}
+}
+
+func TestMisbehaviors(t *testing.T) {
+	dkg := NewPureDKG(5, 3, 2, 0)
+	_, err := dkg.Misbehaviors()
+	assert.ErrorContains(t, err, "not finalized")
+
+	ownCommitmentMsg, _, err := dkg.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.NilError(t, dkg.HandlePolyCommitmentMsg(ownCommitmentMsg))
+	other := NewPureDKG(5, 3, 2, 1)
+	commitmentMsg, _, err := other.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.NilError(t, dkg.HandlePolyCommitmentMsg(commitmentMsg))
+
+	dkg.StartPhase2Accusing()
+	assert.NilError(t, dkg.HandleAccusationMsg(AccusationMsg{Eon: 5, Accuser: 2, Accused: 1}))
+	dkg.StartPhase3Apologizing()
+	dkg.Finalize()
+
+	misbehaviors, err := dkg.Misbehaviors()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []Misbehavior{
+		{Keyper: 1, Kind: MissingApology, Accuser: 2},
+		{Keyper: 2, Kind: MissingCommitment},
+	}, misbehaviors, shtest.BigIntComparer)
 }
 
 func TestDealingSendsCorrectMsgs(t *testing.T) {
@@ -273,7 +356,9 @@ func TestApologizing(t *testing.T) {
 	threshold := uint64(3)
 	keyper := uint64(3)
 	dkg := NewPureDKG(eon, numKeypers, threshold, keyper)
-	_, _, err := dkg.StartPhase1Dealing()
+	commitmentMsg, _, err := dkg.StartPhase1Dealing()
+	assert.NilError(t, err)
+	err = dkg.HandlePolyCommitmentMsg(commitmentMsg)
 	assert.NilError(t, err)
 
 	_ = dkg.StartPhase2Accusing()
@@ -294,6 +379,39 @@ func TestApologizing(t *testing.T) {
 	assert.DeepEqual(t, dkg.Polynomial.EvalForKeyper(int(accusation.Accuser)), apologies[0].Eval, shtest.BigIntComparer)
 }
 
+func TestVerifyOwnEval(t *testing.T) {
+	dkg := NewPureDKG(5, 4, 3, 3)
+	assert.Assert(t, !dkg.VerifyOwnEval(1))
+	_, _, err := dkg.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.Assert(t, !dkg.VerifyOwnEval(1), "commitment not received yet")
+
+	// the chain holds a commitment that doesn't match our polynomial
+	other := NewPureDKG(5, 4, 3, 3)
+	otherCommitment, _, err := other.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.NilError(t, dkg.HandlePolyCommitmentMsg(otherCommitment))
+	assert.Assert(t, !dkg.VerifyOwnEval(1))
+
+	dkg = NewPureDKG(5, 4, 3, 3)
+	commitment, _, err := dkg.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.NilError(t, dkg.HandlePolyCommitmentMsg(commitment))
+	assert.Assert(t, dkg.VerifyOwnEval(1))
+}
+
+// TestApologizingWithoutCommitment tests that we don't apologize if our commitment is unknown, as
+// we cannot clear ourselves in that case.
+func TestApologizingWithoutCommitment(t *testing.T) {
+	dkg := NewPureDKG(5, 4, 3, 3)
+	_, _, err := dkg.StartPhase1Dealing()
+	assert.NilError(t, err)
+	_ = dkg.StartPhase2Accusing()
+	err = dkg.HandleAccusationMsg(AccusationMsg{Eon: 5, Accuser: 1, Accused: 3})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(dkg.StartPhase3Apologizing()))
+}
+
 func TestInvalidCommitmentHandling(t *testing.T) {
 	eon := uint64(5)
 	numKeypers := uint64(4)
@@ -0,0 +1,135 @@
+package puredkg
+
+import (
+	"math/big"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// Transcript is a succinct summary of a finished DKG process. It allows to verify where an eon
+// public key comes from without replaying all DKG messages.
+type Transcript struct {
+	Eon          uint64
+	NumKeypers   uint64
+	Threshold    uint64
+	Participants []byte           // bitmap of the keypers that contributed to the key
+	Commitment   *shcrypto.Gammas // sum of the participants' poly commitments
+	PublicKey    *shcrypto.EonPublicKey
+}
+
+// Transcript returns the transcript of the DKG process. An error is returned if this is called
+// before finalization.
+func (pure *PureDKG) Transcript() (*Transcript, error) {
+	if pure.Phase < Finalized {
+		return nil, errors.Errorf("dkg is not finalized yet")
+	}
+	t := &Transcript{
+		Eon:          pure.Eon,
+		NumKeypers:   pure.NumKeypers,
+		Threshold:    pure.Threshold,
+		Participants: make([]byte, (pure.NumKeypers+7)/8),
+	}
+	var commitments []*shcrypto.Gammas
+	for dealer := uint64(0); dealer < pure.NumKeypers; dealer++ {
+		if pure.isCorrupt(dealer) {
+			continue
+		}
+		t.Participants[dealer/8] |= 1 << (dealer % 8)
+		commitments = append(commitments, pure.Commitments[dealer])
+	}
+	t.Commitment = sumGammas(shcrypto.DegreeFromThreshold(pure.Threshold), commitments)
+	t.PublicKey = shcrypto.ComputeEonPublicKey(commitments)
+	return t, nil
+}
+
+// IsParticipant checks if the given keyper contributed to the eon key.
+func (t *Transcript) IsParticipant(keyper KeyperIndex) bool {
+	if keyper >= t.NumKeypers || keyper/8 >= uint64(len(t.Participants)) {
+		return false
+	}
+	return t.Participants[keyper/8]&(1<<(keyper%8)) != 0
+}
+
+// NumParticipants returns the number of keypers that contributed to the eon key.
+func (t *Transcript) NumParticipants() uint64 {
+	n := uint64(0)
+	for keyper := uint64(0); keyper < t.NumKeypers; keyper++ {
+		if t.IsParticipant(keyper) {
+			n++
+		}
+	}
+	return n
+}
+
+// Verify checks that the transcript is consistent, i.e. that enough keypers participated and
+// that the public key matches the aggregated commitment.
+func (t *Transcript) Verify() error {
+	if t.Threshold == 0 || t.Threshold > t.NumKeypers {
+		return errors.Errorf("invalid threshold %d for %d keypers", t.Threshold, t.NumKeypers)
+	}
+	if uint64(len(t.Participants)) != (t.NumKeypers+7)/8 {
+		return errors.Errorf("participant bitmap has wrong length %d", len(t.Participants))
+	}
+	for i := t.NumKeypers; i < uint64(len(t.Participants))*8; i++ {
+		if t.Participants[i/8]&(1<<(i%8)) != 0 {
+			return errors.Errorf("participant bitmap contains unknown keyper %d", i)
+		}
+	}
+	if n := t.NumParticipants(); n < t.Threshold {
+		return errors.Errorf("only %d keypers participated, but threshold is %d", n, t.Threshold)
+	}
+	if t.Commitment == nil || t.PublicKey == nil {
+		return errors.Errorf("incomplete transcript")
+	}
+	if t.Commitment.Degree() != shcrypto.DegreeFromThreshold(t.Threshold) {
+		return errors.Errorf("commitment has unexpected degree %d", t.Commitment.Degree())
+	}
+	pk := shcrypto.EonPublicKey(*t.Commitment.Pi(big.NewInt(0)))
+	if !pk.Equal(t.PublicKey) {
+		return errors.Errorf("public key does not match commitment")
+	}
+	return nil
+}
+
+// VerifyCommitments checks that the aggregated commitment is the sum of the participants'
+// commitments. commitments must contain the poly commitment of each keyper as broadcast during
+// the DKG, or nil for keypers that did not send one.
+func (t *Transcript) VerifyCommitments(commitments []*shcrypto.Gammas) error {
+	if uint64(len(commitments)) != t.NumKeypers {
+		return errors.Errorf("got %d commitments for %d keypers", len(commitments), t.NumKeypers)
+	}
+	var participating []*shcrypto.Gammas
+	for keyper, c := range commitments {
+		if !t.IsParticipant(uint64(keyper)) {
+			continue
+		}
+		if c == nil || c.Degree() != shcrypto.DegreeFromThreshold(t.Threshold) {
+			return errors.Errorf("participant %d has no valid commitment", keyper)
+		}
+		participating = append(participating, c)
+	}
+	sum := sumGammas(shcrypto.DegreeFromThreshold(t.Threshold), participating)
+	if t.Commitment == nil || !sum.Equal(*t.Commitment) {
+		return errors.Errorf("aggregated commitment does not match")
+	}
+	return nil
+}
+
+// PublicKeyShare returns the eon public key share of the given keyper.
+func (t *Transcript) PublicKeyShare(keyper KeyperIndex) *shcrypto.EonPublicKeyShare {
+	return shcrypto.ComputeEonPublicKeyShare(int(keyper), []*shcrypto.Gammas{t.Commitment})
+}
+
+// sumGammas adds up the given commitments coefficient-wise.
+func sumGammas(degree uint64, commitments []*shcrypto.Gammas) *shcrypto.Gammas {
+	sum := shcrypto.ZeroGammas(degree)
+	for _, c := range commitments {
+		for i := range *sum {
+			(*sum)[i] = new(bn256.G2).Add((*sum)[i], (*c)[i])
+		}
+	}
+	return sum
+}
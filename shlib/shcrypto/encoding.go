@@ -7,34 +7,72 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Marshal serializes the EncryptedMessage object. It panics, if C1 is nil.
+// Marshal serializes the EncryptedMessage object. It panics, if C1 is nil or the version is unknown.
+//
+// Messages of LegacyVersion are serialized as C1 || C2 || C3. Later versions are prefixed with a
+// single version byte and followed by the MAC if the version has one. As C1 and the blocks are
+// multiples of BlockSize long, the length of legacy messages is always a multiple of BlockSize,
+// while the length of versioned ones is one more than that.
 func (m *EncryptedMessage) Marshal() []byte {
 	if m.C1 == nil {
 		panic("not a valid encrypted message. C1==nil")
 	}
+	p, err := m.Params()
+	if err != nil {
+		panic(err)
+	}
 
 	buff := bytes.Buffer{}
+	if p.Version != LegacyVersion {
+		buff.WriteByte(p.Version)
+	}
 	buff.Write(m.C1.Marshal())
 	buff.Write(m.C2[:])
 	for i := range m.C3 {
 		buff.Write(m.C3[i][:])
 	}
+	if p.MAC != MACNone {
+		buff.Write(m.Tag[:])
+	}
 
 	return buff.Bytes()
 }
 
 // Unmarshal deserializes an EncryptedMessage from the given byte slice.
 func (m *EncryptedMessage) Unmarshal(d []byte) error {
-	if m.C1 == nil {
-		m.C1 = new(bn256.G2)
+	p := params[LegacyVersion]
+	if len(d)%BlockSize == 1 {
+		var err error
+		p, err = ParamsForVersion(d[0])
+		if err != nil {
+			return err
+		}
+		if p.Version == LegacyVersion {
+			return errors.Errorf("legacy messages have no version byte")
+		}
+		d = d[1:]
+	}
+	if len(d) < G2Size {
+		return errors.Wrapf(ErrInvalidPointLength, "expected at least %d bytes, got %d", G2Size, len(d))
 	}
-	d, err := m.C1.Unmarshal(d)
+	c1, err := UnmarshalG2(d[:G2Size])
 	if err != nil {
 		return err
 	}
+	m.Version = p.Version
+	m.C1 = c1
+	d = d[G2Size:]
 	if len(d)%BlockSize != 0 {
 		return errors.Errorf("length not a multiple of %d", BlockSize)
 	}
+	m.Tag = Block{}
+	if p.MAC != MACNone {
+		if len(d) < BlockSize {
+			return errors.Errorf("missing MAC")
+		}
+		copy(m.Tag[:], d[len(d)-BlockSize:])
+		d = d[:len(d)-BlockSize]
+	}
 	if len(d) < BlockSize {
 		return errors.Errorf("short block")
 	}
@@ -55,8 +93,12 @@ func (eonpubkey *EonPublicKey) Marshal() []byte {
 	return (*bn256.G2)(eonpubkey).Marshal()
 }
 
-// Unmarshal deserializes an eon public key from the given byte slice.
+// Unmarshal deserializes an eon public key from the given byte slice, see ValidateEonPublicKey.
 func (eonpubkey *EonPublicKey) Unmarshal(m []byte) error {
-	_, err := (*bn256.G2)(eonpubkey).Unmarshal(m)
-	return err
+	p, err := ValidateEonPublicKey(m)
+	if err != nil {
+		return err
+	}
+	(*bn256.G2)(eonpubkey).Set((*bn256.G2)(p))
+	return nil
 }
@@ -13,9 +13,11 @@ import (
 
 // EncryptedMessage represents the full output of the encryption procedure.
 type EncryptedMessage struct {
-	C1 *bn256.G2
-	C2 Block
-	C3 []Block
+	Version uint8 // version of the encryption scheme, see ParamsForVersion
+	C1      *bn256.G2
+	C2      Block
+	C3      []Block
+	Tag     Block // the MAC, if the version has one
 }
 
 // Block represents a block of data.
@@ -68,7 +70,8 @@ func RandomSigma(r io.Reader) (Block, error) {
 }
 
 // Encrypt encrypts a message for the epoch given by its id. It uses the eon public key and randomness
-// provided in sigma.
+// provided in sigma. The message is encrypted with LegacyVersion, see EncryptWithParams for the
+// others.
 func Encrypt(message []byte, eonPublicKey *EonPublicKey, epochID *EpochID, sigma Block) *EncryptedMessage {
 	messageBlocks := PadMessage(message)
 	r := computeR(sigma)
@@ -117,9 +120,17 @@ func computeBlockKeys(sigma Block, n int) []Block {
 	return keys
 }
 
-// Decrypt decrypts the given message using the given epoch secret key.
+// Decrypt decrypts the given message using the given epoch secret key. It returns an error if the
+// message has been tampered with and its version has a MAC.
 func (m *EncryptedMessage) Decrypt(epochSecretKey *EpochSecretKey) ([]byte, error) {
+	p, err := m.Params()
+	if err != nil {
+		return nil, err
+	}
 	sigma := m.Sigma(epochSecretKey)
+	if err := m.verifyTag(p, sigma); err != nil {
+		return nil, err
+	}
 	decryptedBlocks := decryptBlocks(m.C3, sigma)
 	return UnpadMessage(decryptedBlocks)
 }
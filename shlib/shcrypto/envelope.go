@@ -0,0 +1,152 @@
+package shcrypto
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+)
+
+// EnvelopeMarker is the first byte of an encoded envelope. Bare encrypted messages never start
+// with it: versioned ones start with their version and legacy ones with C1, whose coordinates are
+// smaller than the field modulus 0x30644e72..., so that their first byte is at most 0x30.
+const EnvelopeMarker byte = 0xff
+
+// EnvelopeVersion is the version of the envelope format written by Marshal.
+const EnvelopeVersion uint8 = 1
+
+// MaxSenderHintSize is the maximum size of the sender hint of an envelope.
+const MaxSenderHintSize = 255
+
+// envelopeHeaderSize is the size of the encoded envelope before the sender hint: marker, version,
+// eon, epoch ID and the length of the sender hint.
+const envelopeHeaderSize = 1 + 1 + 8 + G1Size + 1
+
+// Envelope wraps an encrypted message with the metadata needed to route and check it without
+// decrypting it. The metadata is not authenticated, it is only a hint to the receivers, which
+// check it against what they expect, see Validate.
+//
+// Envelopes are encoded as EnvelopeMarker || EnvelopeVersion || EonStartBatchIndex (8 bytes, big
+// endian) || EpochID || len(SenderHint) (1 byte) || SenderHint || Message.
+type Envelope struct {
+	EonStartBatchIndex uint64   // the start batch index of the eon whose public key has been used
+	EpochID            *EpochID // the epoch ID the message has been encrypted for
+	SenderHint         []byte   // optional, e.g. the address of the sender
+	Message            *EncryptedMessage
+}
+
+// IsEnvelope checks if the given ciphertext is an encoded envelope, as opposed to a bare encrypted
+// message.
+func IsEnvelope(d []byte) bool {
+	return len(d) > 0 && d[0] == EnvelopeMarker
+}
+
+// Marshal serializes the envelope. It panics if EpochID or Message is nil or the sender hint is
+// too large.
+func (e *Envelope) Marshal() []byte {
+	if e.EpochID == nil || e.Message == nil {
+		panic("not a valid envelope, EpochID or Message is nil")
+	}
+	if len(e.SenderHint) > MaxSenderHintSize {
+		panic("sender hint too large")
+	}
+	buff := bytes.Buffer{}
+	buff.WriteByte(EnvelopeMarker)
+	buff.WriteByte(EnvelopeVersion)
+	var eon [8]byte
+	binary.BigEndian.PutUint64(eon[:], e.EonStartBatchIndex)
+	buff.Write(eon[:])
+	buff.Write((*bn256.G1)(e.EpochID).Marshal())
+	buff.WriteByte(byte(len(e.SenderHint)))
+	buff.Write(e.SenderHint)
+	buff.Write(e.Message.Marshal())
+	return buff.Bytes()
+}
+
+// Unmarshal deserializes an envelope from the given byte slice.
+func (e *Envelope) Unmarshal(d []byte) error {
+	if !IsEnvelope(d) {
+		return errors.New("not an envelope")
+	}
+	if len(d) < envelopeHeaderSize {
+		return errors.Errorf("short envelope of %d bytes", len(d))
+	}
+	if d[1] != EnvelopeVersion {
+		return errors.Errorf("unknown envelope version %d", d[1])
+	}
+	eonStartBatchIndex := binary.BigEndian.Uint64(d[2:10])
+	epochID, err := UnmarshalG1(d[10 : 10+G1Size])
+	if err != nil {
+		return errors.Wrap(err, "invalid epoch ID")
+	}
+	hintSize := int(d[envelopeHeaderSize-1])
+	d = d[envelopeHeaderSize:]
+	if len(d) < hintSize {
+		return errors.Errorf("short sender hint")
+	}
+	senderHint := append([]byte(nil), d[:hintSize]...)
+	message := new(EncryptedMessage)
+	if err := message.Unmarshal(d[hintSize:]); err != nil {
+		return err
+	}
+
+	e.EonStartBatchIndex = eonStartBatchIndex
+	e.EpochID = (*EpochID)(epochID)
+	e.SenderHint = senderHint
+	e.Message = message
+	return nil
+}
+
+// EnvelopeMessageBytes returns the encoded message of the given ciphertext, i.e. the ciphertext
+// without the envelope if it is one. Unlike Unmarshal, it doesn't decode any points.
+func EnvelopeMessageBytes(d []byte) ([]byte, error) {
+	if !IsEnvelope(d) {
+		return d, nil
+	}
+	if len(d) < envelopeHeaderSize {
+		return nil, errors.Errorf("short envelope of %d bytes", len(d))
+	}
+	hintSize := int(d[envelopeHeaderSize-1])
+	if len(d) < envelopeHeaderSize+hintSize {
+		return nil, errors.Errorf("short sender hint")
+	}
+	return d[envelopeHeaderSize+hintSize:], nil
+}
+
+// UnmarshalCiphertext deserializes a ciphertext, which is either an envelope or a bare encrypted
+// message. Bare messages are returned in an envelope without EpochID, as they have no metadata.
+func UnmarshalCiphertext(d []byte) (*Envelope, error) {
+	e := new(Envelope)
+	if IsEnvelope(d) {
+		if err := e.Unmarshal(d); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+	e.Message = new(EncryptedMessage)
+	if err := e.Message.Unmarshal(d); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Validate checks that the envelope has been made for the given eon and epoch ID and that its
+// message has been encrypted with the given version of the encryption scheme. The eon and epoch
+// ID of bare messages are not checked, as they have none.
+func (e *Envelope) Validate(eonStartBatchIndex uint64, epochID *EpochID, version uint8) error {
+	if e.Message.Version != version {
+		return errors.Errorf("encryption version %d instead of %d", e.Message.Version, version)
+	}
+	if e.EpochID == nil {
+		return nil
+	}
+	if e.EonStartBatchIndex != eonStartBatchIndex {
+		return errors.Errorf("encrypted for the eon starting at batch %d instead of %d",
+			e.EonStartBatchIndex, eonStartBatchIndex)
+	}
+	if !e.EpochID.Equal(epochID) {
+		return errors.New("encrypted for another epoch")
+	}
+	return nil
+}
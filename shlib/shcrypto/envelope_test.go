@@ -0,0 +1,84 @@
+package shcrypto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	eonPublicKey, epochID, epochSecretKey := testKeys()
+	m, err := EncryptWithVersion(Version1, []byte("hello"), eonPublicKey, epochID, rand.Reader)
+	assert.NilError(t, err)
+	e := &Envelope{EonStartBatchIndex: 12, EpochID: epochID, SenderHint: []byte("sender"), Message: m}
+
+	d := e.Marshal()
+	assert.Assert(t, IsEnvelope(d))
+	decoded, err := UnmarshalCiphertext(d)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, e, decoded, G2Comparer)
+	decrypted, err := decoded.Message.Decrypt(epochSecretKey)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, decrypted, []byte("hello"))
+
+	messageBytes, err := EnvelopeMessageBytes(d)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, messageBytes, m.Marshal())
+
+	e.SenderHint = nil
+	decoded, err = UnmarshalCiphertext(e.Marshal())
+	assert.NilError(t, err)
+	assert.Equal(t, len(decoded.SenderHint), 0)
+}
+
+func TestBareMessagesAreNotEnvelopes(t *testing.T) {
+	eonPublicKey, epochID, _ := testKeys()
+	for i := 0; i < 100; i++ {
+		for v := LegacyVersion; v <= LatestVersion; v++ {
+			m, err := EncryptWithVersion(v, []byte("hello"), eonPublicKey, epochID, rand.Reader)
+			assert.NilError(t, err)
+			d := m.Marshal()
+			assert.Assert(t, !IsEnvelope(d))
+
+			e, err := UnmarshalCiphertext(d)
+			assert.NilError(t, err)
+			assert.Assert(t, e.EpochID == nil)
+			messageBytes, err := EnvelopeMessageBytes(d)
+			assert.NilError(t, err)
+			assert.DeepEqual(t, messageBytes, d)
+		}
+	}
+}
+
+func TestEnvelopeUnmarshalBroken(t *testing.T) {
+	eonPublicKey, epochID, _ := testKeys()
+	m, err := EncryptWithVersion(Version1, []byte("hello"), eonPublicKey, epochID, rand.Reader)
+	assert.NilError(t, err)
+	d := (&Envelope{EpochID: epochID, SenderHint: []byte("sender"), Message: m}).Marshal()
+	e := Envelope{}
+
+	assert.ErrorContains(t, e.Unmarshal(d[:envelopeHeaderSize-1]), "short envelope")
+	assert.ErrorContains(t, e.Unmarshal(d[:envelopeHeaderSize+2]), "short sender hint")
+	_, err = EnvelopeMessageBytes(d[:envelopeHeaderSize+2])
+	assert.ErrorContains(t, err, "short sender hint")
+	assert.Assert(t, e.Unmarshal(d[:len(d)-1]) != nil)
+
+	d[1] = EnvelopeVersion + 1
+	assert.ErrorContains(t, e.Unmarshal(d), "unknown envelope version")
+}
+
+func TestEnvelopeValidate(t *testing.T) {
+	eonPublicKey, epochID, _ := testKeys()
+	m, err := EncryptWithVersion(Version1, []byte("hello"), eonPublicKey, epochID, rand.Reader)
+	assert.NilError(t, err)
+	e := &Envelope{EonStartBatchIndex: 12, EpochID: epochID, Message: m}
+
+	assert.NilError(t, e.Validate(12, epochID, Version1))
+	assert.ErrorContains(t, e.Validate(12, epochID, LegacyVersion), "encryption version")
+	assert.ErrorContains(t, e.Validate(13, epochID, Version1), "eon")
+	assert.ErrorContains(t, e.Validate(12, ComputeEpochID(8), Version1), "another epoch")
+
+	bare := &Envelope{Message: m}
+	assert.NilError(t, bare.Validate(13, ComputeEpochID(8), Version1))
+}
@@ -118,7 +118,7 @@ func ValidEval(v *big.Int) bool {
 func (p *Polynomial) Gammas() *Gammas {
 	gammas := Gammas{}
 	for _, c := range *p {
-		gamma := new(bn256.G2).ScalarBaseMult(c)
+		gamma := g2BaseMult(c)
 		gammas = append(gammas, gamma)
 	}
 	return &gammas
@@ -200,7 +200,7 @@ func VerifyPolyEval(keyperIndex int, polyEval *big.Int, gammas *Gammas, threshol
 	if gammas.Degree() != threshold-1 {
 		return false
 	}
-	rhs := new(bn256.G2).ScalarBaseMult(polyEval)
+	rhs := g2BaseMult(polyEval)
 	lhs := gammas.Pi(KeyperX(keyperIndex))
 	return EqualG2(lhs, rhs)
 }
@@ -3,8 +3,10 @@ package shcrypto
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/binary"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
 	"github.com/pkg/errors"
 )
@@ -44,8 +46,12 @@ func (g2 *EonPublicKeyShare) GobEncode() ([]byte, error) {
 }
 
 func (g2 *EonPublicKeyShare) GobDecode(data []byte) error {
-	_, err := (*bn256.G2)(g2).Unmarshal(data)
-	return err
+	p, err := ValidateEonPublicKeyShare(data)
+	if err != nil {
+		return err
+	}
+	(*bn256.G2)(g2).Set((*bn256.G2)(p))
+	return nil
 }
 
 func (g2 *EonPublicKeyShare) Equal(pk2 *EonPublicKeyShare) bool {
@@ -57,8 +63,12 @@ func (g *EpochID) GobEncode() ([]byte, error) {
 }
 
 func (g *EpochID) GobDecode(data []byte) error {
-	_, err := (*bn256.G1)(g).Unmarshal(data)
-	return err
+	p, err := ValidateEpochID(data)
+	if err != nil {
+		return err
+	}
+	(*bn256.G1)(g).Set((*bn256.G1)(p))
+	return nil
 }
 
 func (g *EpochID) Equal(g2 *EpochID) bool {
@@ -70,8 +80,12 @@ func (g *EpochSecretKeyShare) GobEncode() ([]byte, error) {
 }
 
 func (g *EpochSecretKeyShare) GobDecode(data []byte) error {
-	_, err := (*bn256.G1)(g).Unmarshal(data)
-	return err
+	p, err := ValidateEpochSecretKeyShare(data)
+	if err != nil {
+		return err
+	}
+	(*bn256.G1)(g).Set((*bn256.G1)(p))
+	return nil
 }
 
 func (g *EpochSecretKeyShare) Equal(g2 *EpochSecretKeyShare) bool {
@@ -83,8 +97,12 @@ func (g *EpochSecretKey) GobEncode() ([]byte, error) {
 }
 
 func (g *EpochSecretKey) GobDecode(data []byte) error {
-	_, err := (*bn256.G1)(g).Unmarshal(data)
-	return err
+	p, err := ValidateEpochSecretKey(data)
+	if err != nil {
+		return err
+	}
+	(*bn256.G1)(g).Set((*bn256.G1)(p))
+	return nil
 }
 
 func (g *EpochSecretKey) Equal(g2 *EpochSecretKey) bool {
@@ -147,11 +165,37 @@ func ComputeEpochSecretKeyShare(eonSecretKeyShare *EonSecretKeyShare, epochID *E
 
 // ComputeEpochID computes the id of the given epoch.
 func ComputeEpochID(epochIndex uint64) *EpochID {
-	epochIndexBig := new(big.Int).SetUint64(epochIndex + 1)
-	id := EpochID(*new(bn256.G1).ScalarBaseMult(epochIndexBig))
+	id := EpochID(*g1BaseMult(epochScalar("", epochIndex)))
 	return &id
 }
 
+// ComputeNamespacedEpochID computes the id of the given epoch inside the given namespace. The
+// empty namespace is the default namespace and results in the same id as ComputeEpochID, so
+// existing users are not affected. Keys derived for different namespaces are independent of each
+// other, i.e. releasing the key for an epoch in one namespace does not reveal the key for the same
+// epoch in another namespace.
+func ComputeNamespacedEpochID(namespace string, epochIndex uint64) *EpochID {
+	id := EpochID(*g1BaseMult(epochScalar(namespace, epochIndex)))
+	return &id
+}
+
+// epochScalar returns the scalar the generator of G1 is multiplied by to get the id of the given
+// epoch in the given namespace.
+func epochScalar(namespace string, epochIndex uint64) *big.Int {
+	if namespace == "" {
+		return new(big.Int).SetUint64(epochIndex + 1)
+	}
+	epochIndexBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(epochIndexBytes, epochIndex)
+	h := crypto.Keccak256([]byte("shutter-epoch-namespace"), []byte(namespace), epochIndexBytes)
+	scalar := new(big.Int).SetBytes(h)
+	scalar.Mod(scalar, bn256.Order)
+	if scalar.Sign() == 0 {
+		scalar.SetInt64(1)
+	}
+	return scalar
+}
+
 // ComputeEpochSecretKey computes the epoch secret key from a set of shares.
 func ComputeEpochSecretKey(keyperIndices []int, epochSecretKeyShares []*EpochSecretKeyShare, threshold uint64) (*EpochSecretKey, error) {
 	if len(keyperIndices) != len(epochSecretKeyShares) {
@@ -174,6 +218,12 @@ func ComputeEpochSecretKey(keyperIndices []int, epochSecretKeyShares []*EpochSec
 	return &sk, nil
 }
 
+// VerifyEonSecretKeyShare checks that an eon secret key share matches the keyper's eon public key
+// share.
+func VerifyEonSecretKeyShare(eonSecretKeyShare *EonSecretKeyShare, eonPublicKeyShare *EonPublicKeyShare) bool {
+	return EqualG2(g2BaseMult((*big.Int)(eonSecretKeyShare)), (*bn256.G2)(eonPublicKeyShare))
+}
+
 // VerifyEpochSecretKeyShare checks that an epoch sk share published by a keyper is correct.
 func VerifyEpochSecretKeyShare(epochSecretKeyShare *EpochSecretKeyShare, eonPublicKeyShare *EonPublicKeyShare, epochID *EpochID) bool {
 	g1s := []*bn256.G1{
@@ -181,7 +231,7 @@ func VerifyEpochSecretKeyShare(epochSecretKeyShare *EpochSecretKeyShare, eonPubl
 		new(bn256.G1).Neg((*bn256.G1)(epochID)),
 	}
 	g2s := []*bn256.G2{
-		new(bn256.G2).ScalarBaseMult(big.NewInt(1)),
+		g2Generator,
 		(*bn256.G2)(eonPublicKeyShare),
 	}
 	return bn256.PairingCheck(g1s, g2s)
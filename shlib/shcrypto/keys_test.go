@@ -118,6 +118,10 @@ func TestEonSharesMatch(t *testing.T) {
 	assert.DeepEqual(t, epk1, epk1Exp)
 	assert.DeepEqual(t, epk2, epk2Exp)
 	assert.DeepEqual(t, epk3, epk3Exp)
+
+	assert.Assert(t, VerifyEonSecretKeyShare(esk1, epk1))
+	assert.Assert(t, VerifyEonSecretKeyShare(esk2, epk2))
+	assert.Assert(t, !VerifyEonSecretKeyShare(esk1, epk2))
 }
 
 func TestEonPublicKey(t *testing.T) {
@@ -271,6 +275,14 @@ func TestComputeEpochSecretKeyShare(t *testing.T) {
 	assert.DeepEqual(t, expectedEpochSecretKeyShare, (*bn256.G1)(epochSecretKeyShare), G1Comparer)
 }
 
+func TestComputeNamespacedEpochID(t *testing.T) {
+	assert.Assert(t, ComputeNamespacedEpochID("", 7).Equal(ComputeEpochID(7)))
+	assert.Assert(t, !ComputeNamespacedEpochID("a", 7).Equal(ComputeEpochID(7)))
+	assert.Assert(t, !ComputeNamespacedEpochID("a", 7).Equal(ComputeNamespacedEpochID("b", 7)))
+	assert.Assert(t, !ComputeNamespacedEpochID("a", 7).Equal(ComputeNamespacedEpochID("a", 8)))
+	assert.Assert(t, ComputeNamespacedEpochID("a", 7).Equal(ComputeNamespacedEpochID("a", 7)))
+}
+
 func TestVerifyEpochSecretKeyShare(t *testing.T) {
 	threshold := uint64(2)
 	epochID := ComputeEpochID(uint64(10))
@@ -0,0 +1,121 @@
+package shcrypto
+
+import (
+	"crypto/subtle"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Versions of the encryption scheme. The version is chosen per eon in the batch config and
+// carried in the header of the encrypted messages, so that the scheme can evolve while messages
+// encrypted with older versions can still be decrypted.
+const (
+	// LegacyVersion is the original scheme. Its messages have no header and no MAC.
+	LegacyVersion uint8 = 0
+	// Version1 adds a MAC, so that tampering with an encrypted message is detected on decryption.
+	Version1 uint8 = 1
+	// LatestVersion is the most recent version.
+	LatestVersion = Version1
+)
+
+// KDF identifies the function deriving the keys of the message blocks from sigma.
+type KDF uint8
+
+const (
+	// KDFKeccak256 derives the key of block i as keccak256(sigma || varint(i)).
+	KDFKeccak256 KDF = iota
+)
+
+// MAC identifies the message authentication code of an encrypted message.
+type MAC uint8
+
+const (
+	// MACNone means the message is not authenticated.
+	MACNone MAC = iota
+	// MACKeccak256 authenticates the message with keccak256(macKey || C1 || C2 || C3), where
+	// macKey is derived from sigma.
+	MACKeccak256
+)
+
+// macDomain separates the MAC key from the block keys, which are derived from sigma as well.
+var macDomain = []byte("shutter-mac")
+
+// Params are the parameters of a version of the encryption scheme.
+type Params struct {
+	Version   uint8
+	BlockSize int // the same for all versions so far, as blocks are fixed size arrays
+	KDF       KDF
+	MAC       MAC
+}
+
+var params = []Params{
+	LegacyVersion: {Version: LegacyVersion, BlockSize: BlockSize, KDF: KDFKeccak256, MAC: MACNone},
+	Version1:      {Version: Version1, BlockSize: BlockSize, KDF: KDFKeccak256, MAC: MACKeccak256},
+}
+
+// ParamsForVersion returns the parameters of the given version of the encryption scheme.
+func ParamsForVersion(version uint8) (Params, error) {
+	if int(version) >= len(params) {
+		return Params{}, errors.Errorf("unknown encryption version %d", version)
+	}
+	return params[version], nil
+}
+
+// EncryptWithParams encrypts a message like Encrypt, but with the given version of the
+// encryption scheme.
+func EncryptWithParams(p Params, message []byte, eonPublicKey *EonPublicKey, epochID *EpochID, sigma Block) *EncryptedMessage {
+	m := Encrypt(message, eonPublicKey, epochID, sigma)
+	m.Version = p.Version
+	if p.MAC == MACKeccak256 {
+		m.Tag = m.computeTag(sigma)
+	}
+	return m
+}
+
+// EncryptWithVersion encrypts a message with the given version of the encryption scheme, reading
+// sigma from r.
+func EncryptWithVersion(
+	version uint8, message []byte, eonPublicKey *EonPublicKey, epochID *EpochID, r io.Reader,
+) (*EncryptedMessage, error) {
+	p, err := ParamsForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	sigma, err := RandomSigma(r)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptWithParams(p, message, eonPublicKey, epochID, sigma), nil
+}
+
+// Params returns the parameters of the version the message has been encrypted with.
+func (m *EncryptedMessage) Params() (Params, error) {
+	return ParamsForVersion(m.Version)
+}
+
+// computeTag computes the MAC of the message for MACKeccak256.
+func (m *EncryptedMessage) computeTag(sigma Block) Block {
+	macKey := HashBytesToBlock(sigma[:], macDomain)
+	d := [][]byte{macKey[:], m.C1.Marshal(), m.C2[:]}
+	for i := range m.C3 {
+		d = append(d, m.C3[i][:])
+	}
+	return HashBytesToBlock(d...)
+}
+
+// verifyTag checks the MAC of the message, given its parameters and sigma.
+func (m *EncryptedMessage) verifyTag(p Params, sigma Block) error {
+	switch p.MAC {
+	case MACNone:
+		return nil
+	case MACKeccak256:
+		tag := m.computeTag(sigma)
+		if subtle.ConstantTimeCompare(tag[:], m.Tag[:]) != 1 {
+			return errors.New("invalid MAC")
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown MAC %d", p.MAC)
+	}
+}
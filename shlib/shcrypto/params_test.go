@@ -0,0 +1,92 @@
+package shcrypto
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+)
+
+func testKeys() (*EonPublicKey, *EpochID, *EpochSecretKey) {
+	eonSecretKey := big.NewInt(4242)
+	eonPublicKey := (*EonPublicKey)(new(bn256.G2).ScalarBaseMult(eonSecretKey))
+	epochID := ComputeEpochID(7)
+	epochSecretKey := (*EpochSecretKey)(new(bn256.G1).ScalarMult((*bn256.G1)(epochID), eonSecretKey))
+	return eonPublicKey, epochID, epochSecretKey
+}
+
+func TestParamsForVersion(t *testing.T) {
+	for v := LegacyVersion; v <= LatestVersion; v++ {
+		p, err := ParamsForVersion(v)
+		assert.NilError(t, err)
+		assert.Equal(t, p.Version, v)
+		assert.Equal(t, p.BlockSize, BlockSize)
+	}
+	_, err := ParamsForVersion(LatestVersion + 1)
+	assert.ErrorContains(t, err, "unknown encryption version")
+}
+
+func TestEncryptWithParamsRoundTrip(t *testing.T) {
+	eonPublicKey, epochID, epochSecretKey := testKeys()
+	message := []byte("hello")
+	for v := LegacyVersion; v <= LatestVersion; v++ {
+		encrypted, err := EncryptWithVersion(v, message, eonPublicKey, epochID, rand.Reader)
+		assert.NilError(t, err)
+
+		d := encrypted.Marshal()
+		if v == LegacyVersion {
+			assert.Equal(t, len(d)%BlockSize, 0)
+		} else {
+			assert.Equal(t, len(d)%BlockSize, 1)
+			assert.Equal(t, d[0], v)
+		}
+
+		decoded := &EncryptedMessage{}
+		assert.NilError(t, decoded.Unmarshal(d))
+		assert.DeepEqual(t, encrypted, decoded, G2Comparer)
+		decrypted, err := decoded.Decrypt(epochSecretKey)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, message, decrypted)
+	}
+}
+
+func TestEncryptLegacyCompatible(t *testing.T) {
+	eonPublicKey, epochID, _ := testKeys()
+	sigma, err := RandomSigma(rand.Reader)
+	assert.NilError(t, err)
+	legacy := Encrypt([]byte("hello"), eonPublicKey, epochID, sigma)
+	p, err := ParamsForVersion(LegacyVersion)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, legacy, EncryptWithParams(p, []byte("hello"), eonPublicKey, epochID, sigma), G2Comparer)
+}
+
+func TestDecryptTampered(t *testing.T) {
+	eonPublicKey, epochID, epochSecretKey := testKeys()
+	encrypted, err := EncryptWithVersion(Version1, []byte("hello"), eonPublicKey, epochID, rand.Reader)
+	assert.NilError(t, err)
+	encrypted.C3[0][0] ^= 1
+	_, err = encrypted.Decrypt(epochSecretKey)
+	assert.ErrorContains(t, err, "invalid MAC")
+
+	encrypted.C3[0][0] ^= 1
+	encrypted.Version = LegacyVersion
+	_, err = encrypted.Decrypt(epochSecretKey)
+	assert.NilError(t, err, "legacy messages are not authenticated")
+}
+
+func TestUnmarshalVersioned(t *testing.T) {
+	eonPublicKey, epochID, _ := testKeys()
+	encrypted, err := EncryptWithVersion(Version1, []byte("hello"), eonPublicKey, epochID, rand.Reader)
+	assert.NilError(t, err)
+	d := encrypted.Marshal()
+	m := EncryptedMessage{}
+
+	d[0] = LatestVersion + 1
+	assert.ErrorContains(t, m.Unmarshal(d), "unknown encryption version")
+	d[0] = LegacyVersion
+	assert.Assert(t, m.Unmarshal(d) != nil)
+	d[0] = Version1
+	assert.Assert(t, m.Unmarshal(d[:1+G2Size+BlockSize]) != nil, "C2 or the MAC missing")
+}
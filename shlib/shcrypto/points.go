@@ -0,0 +1,152 @@
+package shcrypto
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+)
+
+// Sizes of marshaled curve points.
+const (
+	G1Size = 64
+	G2Size = 128
+)
+
+// Errors returned when decoding a curve point fails. Use errors.Is to check for them.
+var (
+	ErrInvalidPointLength = errors.New("invalid point length")
+	ErrIdentityPoint      = errors.New("point at infinity")
+	ErrNotInGroup         = errors.New("point not on curve or not in group")
+)
+
+// maxCachedPoints is the number of G2 encodings remembered by g2Cache.
+const maxCachedPoints = 1 << 16
+
+type g2CacheEntry struct {
+	point *bn256.G2
+	err   error
+}
+
+// g2Cache remembers the result of decoding G2 points. Unmarshaling a G2 point includes a
+// subgroup check, which is expensive, and the same points are decoded multiple times, e.g. when
+// replaying events.
+type g2Cache struct {
+	mux     sync.Mutex
+	entries map[string]g2CacheEntry
+}
+
+func (c *g2Cache) get(data []byte) (*bn256.G2, error, bool) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	entry, ok := c.entries[string(data)]
+	if !ok {
+		return nil, nil, false
+	}
+	if entry.err != nil {
+		return nil, entry.err, true
+	}
+	return new(bn256.G2).Set(entry.point), nil, true
+}
+
+func (c *g2Cache) put(data []byte, p *bn256.G2, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.entries == nil || len(c.entries) >= maxCachedPoints {
+		c.entries = make(map[string]g2CacheEntry)
+	}
+	entry := g2CacheEntry{err: err}
+	if err == nil {
+		entry.point = new(bn256.G2).Set(p)
+	}
+	c.entries[string(data)] = entry
+}
+
+var decodedG2Points g2Cache
+
+// checkEncoding rejects encodings of the wrong length and of the point at infinity before doing
+// any curve arithmetic.
+func checkEncoding(data []byte, size int) error {
+	if len(data) != size {
+		return errors.Wrapf(ErrInvalidPointLength, "expected %d bytes, got %d", size, len(data))
+	}
+	if bytes.Equal(data, make([]byte, size)) {
+		return ErrIdentityPoint
+	}
+	return nil
+}
+
+// UnmarshalG1 deserializes a G1 point, rejecting the point at infinity.
+func UnmarshalG1(data []byte) (*bn256.G1, error) {
+	if err := checkEncoding(data, G1Size); err != nil {
+		return nil, err
+	}
+	p := new(bn256.G1)
+	if _, err := p.Unmarshal(data); err != nil {
+		return nil, errors.Wrap(ErrNotInGroup, err.Error())
+	}
+	return p, nil
+}
+
+// UnmarshalG2 deserializes a G2 point, rejecting the point at infinity and points not in the
+// group. Results are cached, so decoding the same point again is cheap.
+func UnmarshalG2(data []byte) (*bn256.G2, error) {
+	if err := checkEncoding(data, G2Size); err != nil {
+		return nil, err
+	}
+	if p, err, ok := decodedG2Points.get(data); ok {
+		return p, err
+	}
+	p := new(bn256.G2)
+	_, err := p.Unmarshal(data)
+	if err != nil {
+		err = errors.Wrap(ErrNotInGroup, err.Error())
+	}
+	decodedG2Points.put(data, p, err)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UnmarshalG2Batch deserializes a list of G2 points like UnmarshalG2. All encodings are checked
+// for the right length and the point at infinity first, then the points are decoded in parallel.
+func UnmarshalG2Batch(encodings [][]byte) ([]*bn256.G2, error) {
+	for i, data := range encodings {
+		if err := checkEncoding(data, G2Size); err != nil {
+			return nil, errors.Wrapf(err, "point %d", i)
+		}
+	}
+
+	points := make([]*bn256.G2, len(encodings))
+	errs := make([]error, len(encodings))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	numWorkers := runtime.NumCPU()
+	if numWorkers > len(encodings) {
+		numWorkers = len(encodings)
+	}
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				points[i], errs[i] = UnmarshalG2(encodings[i])
+			}
+		}()
+	}
+	for i := range encodings {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "point %d", i)
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,146 @@
+package shcrypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+)
+
+// sqrtFp computes a square root modulo bn256.P (which is 3 mod 4), if one exists.
+func sqrtFp(a *big.Int) (*big.Int, bool) {
+	e := new(big.Int).Add(bn256.P, big.NewInt(1))
+	e.Rsh(e, 2)
+	r := new(big.Int).Exp(a, e, bn256.P)
+	check := new(big.Int).Mul(r, r)
+	return r, check.Mod(check, bn256.P).Cmp(new(big.Int).Mod(a, bn256.P)) == 0
+}
+
+// sqrtFp2 computes a square root of a0+a1*i in F_p², if one exists.
+func sqrtFp2(a0, a1 *big.Int) (*big.Int, *big.Int, bool) {
+	p := bn256.P
+	norm := new(big.Int).Add(new(big.Int).Mul(a0, a0), new(big.Int).Mul(a1, a1))
+	n, ok := sqrtFp(norm.Mod(norm, p))
+	if !ok {
+		return nil, nil, false
+	}
+	half := new(big.Int).ModInverse(big.NewInt(2), p)
+	for _, sign := range []int64{1, -1} {
+		d := new(big.Int).Add(a0, new(big.Int).Mul(big.NewInt(sign), n))
+		d.Mul(d, half).Mod(d, p)
+		x0, ok := sqrtFp(d)
+		if !ok || x0.Sign() == 0 {
+			continue
+		}
+		x1 := new(big.Int).ModInverse(new(big.Int).Lsh(x0, 1), p)
+		x1.Mul(x1, a1).Mod(x1, p)
+		return x0, x1, true
+	}
+	return nil, nil, false
+}
+
+// twistPointOutsideSubgroup returns the encoding of a point on the twist curve
+// y² = x³ + 3/(i+9) which is (with overwhelming probability) not in G2.
+func twistPointOutsideSubgroup(t *testing.T) []byte {
+	t.Helper()
+	p := bn256.P
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, p) }
+
+	// b = 3/(9+i) = 3(9-i)/82
+	inv82 := new(big.Int).ModInverse(big.NewInt(82), p)
+	b0 := mod(new(big.Int).Mul(big.NewInt(27), inv82))
+	b1 := mod(new(big.Int).Mul(big.NewInt(-3), inv82))
+
+	for x0 := int64(1); x0 < 1000; x0++ {
+		// x = x0 + i, x³ = (x0³ - 3x0) + (3x0² - 1)i
+		c0 := mod(big.NewInt(x0*x0*x0 - 3*x0))
+		c1 := mod(big.NewInt(3*x0*x0 - 1))
+		y0, y1, ok := sqrtFp2(mod(c0.Add(c0, b0)), mod(c1.Add(c1, b1)))
+		if !ok {
+			continue
+		}
+		// encoded as imaginary and real part of x, then of y
+		data := make([]byte, G2Size)
+		big.NewInt(1).FillBytes(data[0:32])
+		big.NewInt(x0).FillBytes(data[32:64])
+		y1.FillBytes(data[64:96])
+		y0.FillBytes(data[96:128])
+		return data
+	}
+	t.Fatal("no point found")
+	return nil
+}
+
+func TestUnmarshalG1(t *testing.T) {
+	g := new(bn256.G1).ScalarBaseMult(big.NewInt(7))
+	p, err := UnmarshalG1(g.Marshal())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, g, p, G1Comparer)
+
+	_, err = UnmarshalG1(make([]byte, G1Size))
+	assert.Assert(t, errors.Is(err, ErrIdentityPoint), err)
+	_, err = UnmarshalG1(g.Marshal()[1:])
+	assert.Assert(t, errors.Is(err, ErrInvalidPointLength), err)
+}
+
+func TestUnmarshalG2(t *testing.T) {
+	g := new(bn256.G2).ScalarBaseMult(big.NewInt(7))
+	p, err := UnmarshalG2(g.Marshal())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, g, p, G2Comparer)
+
+	_, err = UnmarshalG2(make([]byte, G2Size))
+	assert.Assert(t, errors.Is(err, ErrIdentityPoint), err)
+	_, err = UnmarshalG2(append(g.Marshal(), 0))
+	assert.Assert(t, errors.Is(err, ErrInvalidPointLength), err)
+
+	offCurve := g.Marshal()
+	offCurve[G2Size-1] ^= 1
+	_, err = UnmarshalG2(offCurve)
+	assert.ErrorContains(t, err, "malformed point")
+
+	// bn256 reports points outside the subgroup as malformed as well
+	outside := twistPointOutsideSubgroup(t)
+	_, err = UnmarshalG2(outside)
+	assert.ErrorContains(t, err, "malformed point")
+	_, _, ok := decodedG2Points.get(outside)
+	assert.Assert(t, ok)
+	_, err = UnmarshalG2(outside)
+	assert.ErrorContains(t, err, "malformed point")
+
+	// modifying a decoded point does not affect the cache
+	p.Add(p, p)
+	p2, err := UnmarshalG2(g.Marshal())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, g, p2, G2Comparer)
+}
+
+func TestUnmarshalG2Batch(t *testing.T) {
+	var encodings [][]byte
+	var expected []*bn256.G2
+	for i := int64(1); i <= 20; i++ {
+		g := new(bn256.G2).ScalarBaseMult(big.NewInt(i))
+		encodings = append(encodings, g.Marshal())
+		expected = append(expected, g)
+	}
+	points, err := UnmarshalG2Batch(encodings)
+	assert.NilError(t, err)
+	assert.Equal(t, len(points), len(expected))
+	for i := range points {
+		assert.DeepEqual(t, expected[i], points[i], G2Comparer)
+	}
+
+	encodings[5] = twistPointOutsideSubgroup(t)
+	_, err = UnmarshalG2Batch(encodings)
+	assert.ErrorContains(t, err, "point 5: bn256: malformed point")
+
+	encodings[7] = encodings[7][:10]
+	_, err = UnmarshalG2Batch(encodings)
+	assert.Assert(t, errors.Is(err, ErrInvalidPointLength), err)
+
+	points, err = UnmarshalG2Batch(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(points), 0)
+}
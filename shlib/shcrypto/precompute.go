@@ -0,0 +1,162 @@
+package shcrypto
+
+import (
+	"math/big"
+	"sync"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+// Window sizes of the precomputation tables. A table with window w holds the multiples
+// 1..2^w-1 of 2^(w*i)*P for each of the 256/w windows of a scalar, so that multiplying P by a
+// scalar takes one point addition per nonzero window and no doublings. The G1 generator table is
+// used for every epoch id and thus gets a larger window, at the cost of a table of about 350KB.
+const (
+	tableWindow       = 4
+	g1BaseTableWindow = 6
+)
+
+// scalarBits is the number of bits of a scalar reduced modulo bn256.Order.
+const scalarBits = 256
+
+var g2Generator = new(bn256.G2).ScalarBaseMult(big.NewInt(1))
+
+// The generator tables are only computed once they are needed, so that e.g. one-off encryptions
+// don't pay for them.
+var (
+	g1BaseTableOnce sync.Once
+	g1BaseTable     *G1Table
+	g2BaseTableOnce sync.Once
+	g2BaseTable     *G2Table
+)
+
+// g1BaseMult multiplies the generator of G1 by k like bn256.G1.ScalarBaseMult.
+func g1BaseMult(k *big.Int) *bn256.G1 {
+	g1BaseTableOnce.Do(func() {
+		g1BaseTable = newG1Table(new(bn256.G1).ScalarBaseMult(big.NewInt(1)), g1BaseTableWindow)
+	})
+	return g1BaseTable.Mul(k)
+}
+
+// g2BaseMult multiplies the generator of G2 by k like bn256.G2.ScalarBaseMult.
+func g2BaseMult(k *big.Int) *bn256.G2 {
+	g2BaseTableOnce.Do(func() {
+		g2BaseTable = newG2Table(g2Generator, tableWindow)
+	})
+	return g2BaseTable.Mul(k)
+}
+
+// reduceScalar returns k modulo bn256.Order, which is k itself if it's in range already.
+func reduceScalar(k *big.Int) *big.Int {
+	if k.Sign() < 0 || k.Cmp(bn256.Order) >= 0 {
+		return new(big.Int).Mod(k, bn256.Order)
+	}
+	return k
+}
+
+// scalarDigit returns the i-th window of k, counting from the least significant one.
+func scalarDigit(k *big.Int, window int, i int) int {
+	d := 0
+	for b := window - 1; b >= 0; b-- {
+		d = d<<1 | int(k.Bit(i*window+b))
+	}
+	return d
+}
+
+// G1Table speeds up multiplying a fixed G1 point by many scalars. It may be used concurrently.
+type G1Table struct {
+	window int
+	points [][]*bn256.G1 // points[i][d-1] is d*2^(window*i) times the point
+}
+
+// NewG1Table precomputes the multiples of p needed by Mul.
+func NewG1Table(p *bn256.G1) *G1Table {
+	return newG1Table(p, tableWindow)
+}
+
+func newG1Table(p *bn256.G1, window int) *G1Table {
+	t := &G1Table{window: window, points: make([][]*bn256.G1, (scalarBits+window-1)/window)}
+	base := new(bn256.G1).Set(p)
+	for i := range t.points {
+		row := make([]*bn256.G1, 1<<window-1)
+		row[0] = base
+		for d := 1; d < len(row); d++ {
+			row[d] = new(bn256.G1).Add(row[d-1], base)
+		}
+		t.points[i] = row
+		base = new(bn256.G1).Add(row[len(row)-1], base)
+	}
+	return t
+}
+
+// Mul returns the point multiplied by k, like bn256.G1.ScalarMult.
+func (t *G1Table) Mul(k *big.Int) *bn256.G1 {
+	k = reduceScalar(k)
+	res := new(bn256.G1).Set(zeroG1)
+	for i := 0; i*t.window < k.BitLen(); i++ {
+		if d := scalarDigit(k, t.window, i); d != 0 {
+			res.Add(res, t.points[i][d-1])
+		}
+	}
+	return res
+}
+
+// G2Table speeds up multiplying a fixed G2 point by many scalars. It may be used concurrently.
+type G2Table struct {
+	window int
+	points [][]*bn256.G2 // points[i][d-1] is d*2^(window*i) times the point
+}
+
+// NewG2Table precomputes the multiples of p needed by Mul.
+func NewG2Table(p *bn256.G2) *G2Table {
+	return newG2Table(p, tableWindow)
+}
+
+func newG2Table(p *bn256.G2, window int) *G2Table {
+	t := &G2Table{window: window, points: make([][]*bn256.G2, (scalarBits+window-1)/window)}
+	base := new(bn256.G2).Set(p)
+	for i := range t.points {
+		row := make([]*bn256.G2, 1<<window-1)
+		row[0] = base
+		for d := 1; d < len(row); d++ {
+			row[d] = new(bn256.G2).Add(row[d-1], base)
+		}
+		t.points[i] = row
+		base = new(bn256.G2).Add(row[len(row)-1], base)
+	}
+	return t
+}
+
+// Mul returns the point multiplied by k, like bn256.G2.ScalarMult.
+func (t *G2Table) Mul(k *big.Int) *bn256.G2 {
+	k = reduceScalar(k)
+	res := new(bn256.G2).Set(zeroG2)
+	for i := 0; i*t.window < k.BitLen(); i++ {
+		if d := scalarDigit(k, t.window, i); d != 0 {
+			res.Add(res, t.points[i][d-1])
+		}
+	}
+	return res
+}
+
+// EpochSecretKeyShareTable computes a keyper's epoch secret key shares for many epochs of an eon.
+// An epoch id is the G1 generator multiplied by a scalar derived from the epoch, so instead of
+// multiplying the epoch id by the eon secret key share, it multiplies the fixed point
+// eonSecretKeyShare*g1 by the epoch's scalar. The result is the same as the one of
+// ComputeEpochSecretKeyShare, but computing the table only pays off for more than a few epochs.
+type EpochSecretKeyShareTable struct {
+	table *G1Table
+}
+
+// NewEpochSecretKeyShareTable precomputes the table for the given eon secret key share.
+func NewEpochSecretKeyShareTable(eonSecretKeyShare *EonSecretKeyShare) *EpochSecretKeyShareTable {
+	p := g1BaseMult((*big.Int)(eonSecretKeyShare))
+	return &EpochSecretKeyShareTable{table: NewG1Table(p)}
+}
+
+// Compute computes the epoch secret key share for the given epoch in the given namespace, see
+// ComputeNamespacedEpochID.
+func (t *EpochSecretKeyShareTable) Compute(namespace string, epochIndex uint64) *EpochSecretKeyShare {
+	share := EpochSecretKeyShare(*t.table.Mul(epochScalar(namespace, epochIndex)))
+	return &share
+}
@@ -0,0 +1,150 @@
+package shcrypto
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+)
+
+// testScalars returns scalars covering the edge cases of the table lookups and some random ones.
+func testScalars(t testing.TB) []*big.Int {
+	t.Helper()
+	scalars := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(15),
+		big.NewInt(16),
+		big.NewInt(1 << 40),
+		new(big.Int).Sub(bn256.Order, big.NewInt(1)),
+		bn256.Order,
+		new(big.Int).Add(bn256.Order, big.NewInt(5)),
+		big.NewInt(-5),
+		new(big.Int).Lsh(big.NewInt(1), 300),
+	}
+	for i := 0; i < 10; i++ {
+		k, err := rand.Int(rand.Reader, bn256.Order)
+		assert.NilError(t, err)
+		scalars = append(scalars, k)
+	}
+	return scalars
+}
+
+func TestG1Table(t *testing.T) {
+	p := new(bn256.G1).ScalarBaseMult(big.NewInt(12345))
+	table := NewG1Table(p)
+	for _, k := range testScalars(t) {
+		expected := new(bn256.G1).ScalarMult(p, new(big.Int).Mod(k, bn256.Order))
+		assert.Assert(t, EqualG1(table.Mul(k), expected), "k=%s", k)
+		assert.Assert(t, EqualG1(g1BaseMult(k), new(bn256.G1).ScalarBaseMult(new(big.Int).Mod(k, bn256.Order))), "k=%s", k)
+	}
+}
+
+func TestG2Table(t *testing.T) {
+	p := new(bn256.G2).ScalarBaseMult(big.NewInt(12345))
+	table := NewG2Table(p)
+	for _, k := range testScalars(t) {
+		expected := new(bn256.G2).ScalarMult(p, new(big.Int).Mod(k, bn256.Order))
+		assert.Assert(t, EqualG2(table.Mul(k), expected), "k=%s", k)
+		assert.Assert(t, EqualG2(g2BaseMult(k), new(bn256.G2).ScalarBaseMult(new(big.Int).Mod(k, bn256.Order))), "k=%s", k)
+	}
+}
+
+func TestEpochSecretKeyShareTable(t *testing.T) {
+	k, err := rand.Int(rand.Reader, bn256.Order)
+	assert.NilError(t, err)
+	eonSecretKeyShare := (*EonSecretKeyShare)(k)
+	table := NewEpochSecretKeyShareTable(eonSecretKeyShare)
+	for _, namespace := range []string{"", "test"} {
+		for _, epoch := range []uint64{0, 1, 1000, 1 << 40} {
+			epochID := ComputeNamespacedEpochID(namespace, epoch)
+			expected := ComputeEpochSecretKeyShare(eonSecretKeyShare, epochID)
+			assert.Assert(t, table.Compute(namespace, epoch).Equal(expected), "namespace=%q epoch=%d", namespace, epoch)
+		}
+	}
+}
+
+func BenchmarkComputeEpochID(b *testing.B) {
+	b.Run("ScalarBaseMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			new(bn256.G1).ScalarBaseMult(new(big.Int).SetUint64(uint64(i) + 1))
+		}
+	})
+	b.Run("Table", func(b *testing.B) {
+		g1BaseMult(big.NewInt(1))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ComputeEpochID(uint64(i))
+		}
+	})
+}
+
+func BenchmarkComputeNamespacedEpochID(b *testing.B) {
+	b.Run("ScalarBaseMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			new(bn256.G1).ScalarBaseMult(epochScalar("test", uint64(i)))
+		}
+	})
+	b.Run("Table", func(b *testing.B) {
+		g1BaseMult(big.NewInt(1))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			ComputeNamespacedEpochID("test", uint64(i))
+		}
+	})
+}
+
+// BenchmarkEpochSecretKeyShare compares computing our epoch secret key shares from the epoch ids
+// with computing them from the precomputed table, which includes computing the epoch ids.
+func BenchmarkEpochSecretKeyShare(b *testing.B) {
+	k, err := rand.Int(rand.Reader, bn256.Order)
+	assert.NilError(b, err)
+	eonSecretKeyShare := (*EonSecretKeyShare)(k)
+	for _, namespace := range []string{"", "test"} {
+		name := namespace
+		if name == "" {
+			name = "default"
+		}
+		b.Run(name+"/ScalarMult", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ComputeEpochSecretKeyShare(eonSecretKeyShare, ComputeNamespacedEpochID(namespace, uint64(i)))
+			}
+		})
+		b.Run(name+"/Table", func(b *testing.B) {
+			table := NewEpochSecretKeyShareTable(eonSecretKeyShare)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				table.Compute(namespace, uint64(i))
+			}
+		})
+	}
+}
+
+func BenchmarkNewEpochSecretKeyShareTable(b *testing.B) {
+	k, err := rand.Int(rand.Reader, bn256.Order)
+	assert.NilError(b, err)
+	for i := 0; i < b.N; i++ {
+		NewEpochSecretKeyShareTable((*EonSecretKeyShare)(k))
+	}
+}
+
+func BenchmarkVerifyPolyEval(b *testing.B) {
+	polynomial, err := RandomPolynomial(rand.Reader, 9)
+	assert.NilError(b, err)
+	gammas := polynomial.Gammas()
+	polyEval := polynomial.EvalForKeyper(3)
+	b.Run("ScalarBaseMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			EqualG2(gammas.Pi(KeyperX(3)), new(bn256.G2).ScalarBaseMult(polyEval))
+		}
+	})
+	b.Run("Table", func(b *testing.B) {
+		g2BaseMult(big.NewInt(1))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			VerifyPolyEval(3, polyEval, gammas, 10)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package shcrypto
+
+import (
+	"fmt"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+)
+
+// KeyError is returned by the Validate functions if the given bytes don't encode a valid key. Err
+// is one of ErrInvalidPointLength, ErrIdentityPoint and ErrNotInGroup, possibly wrapped.
+type KeyError struct {
+	Key string // the kind of key, e.g. "eon public key"
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+func validateG1(key string, data []byte) (*bn256.G1, error) {
+	p, err := UnmarshalG1(data)
+	if err != nil {
+		return nil, &KeyError{Key: key, Err: err}
+	}
+	return p, nil
+}
+
+func validateG2(key string, data []byte) (*bn256.G2, error) {
+	p, err := UnmarshalG2(data)
+	if err != nil {
+		return nil, &KeyError{Key: key, Err: err}
+	}
+	return p, nil
+}
+
+// ValidateEonPublicKey decodes an eon public key, checking that it is a correctly encoded element
+// of G2 other than the identity.
+func ValidateEonPublicKey(data []byte) (*EonPublicKey, error) {
+	p, err := validateG2("eon public key", data)
+	return (*EonPublicKey)(p), err
+}
+
+// ValidateEonPublicKeyShare decodes an eon public key share like ValidateEonPublicKey.
+func ValidateEonPublicKeyShare(data []byte) (*EonPublicKeyShare, error) {
+	p, err := validateG2("eon public key share", data)
+	return (*EonPublicKeyShare)(p), err
+}
+
+// ValidateEpochID decodes an epoch id, checking that it is a correctly encoded element of G1
+// other than the identity.
+func ValidateEpochID(data []byte) (*EpochID, error) {
+	p, err := validateG1("epoch id", data)
+	return (*EpochID)(p), err
+}
+
+// ValidateEpochSecretKeyShare decodes an epoch secret key share like ValidateEpochID.
+func ValidateEpochSecretKeyShare(data []byte) (*EpochSecretKeyShare, error) {
+	p, err := validateG1("epoch secret key share", data)
+	return (*EpochSecretKeyShare)(p), err
+}
+
+// ValidateEpochSecretKey decodes an epoch secret key like ValidateEpochID.
+func ValidateEpochSecretKey(data []byte) (*EpochSecretKey, error) {
+	p, err := validateG1("epoch secret key", data)
+	return (*EpochSecretKey)(p), err
+}
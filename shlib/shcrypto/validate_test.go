@@ -0,0 +1,55 @@
+package shcrypto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateKeys(t *testing.T) {
+	g1 := new(bn256.G1).ScalarBaseMult(big.NewInt(3)).Marshal()
+	g2 := new(bn256.G2).ScalarBaseMult(big.NewInt(3)).Marshal()
+
+	pk, err := ValidateEonPublicKey(g2)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pk.Marshal(), g2)
+	_, err = ValidateEonPublicKeyShare(g2)
+	assert.NilError(t, err)
+	for _, validate := range []func([]byte) error{
+		func(d []byte) error { _, err := ValidateEpochID(d); return err },
+		func(d []byte) error { _, err := ValidateEpochSecretKeyShare(d); return err },
+		func(d []byte) error { _, err := ValidateEpochSecretKey(d); return err },
+	} {
+		assert.NilError(t, validate(g1))
+
+		err = validate(g1[1:])
+		assert.Assert(t, errors.Is(err, ErrInvalidPointLength), err)
+		err = validate(make([]byte, G1Size))
+		assert.Assert(t, errors.Is(err, ErrIdentityPoint), err)
+		offCurve := append([]byte{}, g1...)
+		offCurve[G1Size-1] ^= 1
+		err = validate(offCurve)
+		assert.Assert(t, errors.Is(err, ErrNotInGroup), err)
+		var keyErr *KeyError
+		assert.Assert(t, errors.As(err, &keyErr))
+	}
+
+	_, err = ValidateEonPublicKey(twistPointOutsideSubgroup(t))
+	assert.Assert(t, errors.Is(err, ErrNotInGroup), err)
+	assert.ErrorContains(t, err, "invalid eon public key")
+	_, err = ValidateEonPublicKeyShare(make([]byte, G2Size))
+	assert.Assert(t, errors.Is(err, ErrIdentityPoint), err)
+}
+
+func TestGobDecodeValidates(t *testing.T) {
+	share := new(EpochSecretKeyShare)
+	err := share.GobDecode(make([]byte, G1Size))
+	assert.Assert(t, errors.Is(err, ErrIdentityPoint), err)
+
+	pk := new(EonPublicKey)
+	err = pk.GobDecode(twistPointOutsideSubgroup(t))
+	assert.Assert(t, errors.Is(err, ErrNotInGroup), err)
+}
@@ -0,0 +1,39 @@
+package shcrypto
+
+import "math/big"
+
+// ZeroInt overwrites the memory backing x with zeros and sets x to 0. It is meant for secrets
+// that are not needed anymore. Copies of the value, e.g. serializations or results of arithmetic
+// on x, are not affected.
+func ZeroInt(x *big.Int) {
+	if x == nil {
+		return
+	}
+	words := x.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	x.SetInt64(0)
+}
+
+// ZeroBytes overwrites b with zeros.
+func ZeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// Zero overwrites the key share with zeros.
+func (esks *EonSecretKeyShare) Zero() {
+	ZeroInt((*big.Int)(esks))
+}
+
+// Zero overwrites the coefficients of the polynomial with zeros.
+func (p *Polynomial) Zero() {
+	if p == nil {
+		return
+	}
+	for _, c := range *p {
+		ZeroInt(c)
+	}
+}
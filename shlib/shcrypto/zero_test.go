@@ -0,0 +1,69 @@
+package shcrypto
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// assertZeroWords checks that the given words, taken from a big.Int before zeroing it, have been
+// overwritten.
+func assertZeroWords(t *testing.T, words []big.Word) {
+	t.Helper()
+	assert.Assert(t, len(words) > 0)
+	for _, w := range words {
+		assert.Equal(t, w, big.Word(0))
+	}
+}
+
+func TestZeroInt(t *testing.T) {
+	x, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 256))
+	assert.NilError(t, err)
+	x.SetBit(x, 255, 1)
+	words := x.Bits()
+
+	ZeroInt(x)
+	assert.Equal(t, x.Sign(), 0)
+	assertZeroWords(t, words)
+	ZeroInt(nil)
+}
+
+func TestZeroBytes(t *testing.T) {
+	b := []byte{1, 2, 3}
+	ZeroBytes(b)
+	assert.DeepEqual(t, b, []byte{0, 0, 0})
+}
+
+func TestEonSecretKeyShareZero(t *testing.T) {
+	p, err := RandomPolynomial(rand.Reader, 2)
+	assert.NilError(t, err)
+	esks := ComputeEonSecretKeyShare([]*big.Int{p.EvalForKeyper(0), p.EvalForKeyper(1)})
+	words := (*big.Int)(esks).Bits()
+
+	esks.Zero()
+	assert.Equal(t, (*big.Int)(esks).Sign(), 0)
+	assertZeroWords(t, words)
+}
+
+func TestPolynomialZero(t *testing.T) {
+	p, err := RandomPolynomial(rand.Reader, 3)
+	assert.NilError(t, err)
+	var words [][]big.Word
+	for _, c := range *p {
+		if c.Sign() != 0 {
+			words = append(words, c.Bits())
+		}
+	}
+
+	p.Zero()
+	for _, c := range *p {
+		assert.Equal(t, c.Sign(), 0)
+	}
+	for _, w := range words {
+		assertZeroWords(t, w)
+	}
+	var nilPoly *Polynomial
+	nilPoly.Zero()
+}
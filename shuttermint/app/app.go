@@ -2,6 +2,7 @@ package app
 
 import (
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/gob"
 	"fmt"
 	"log"
@@ -53,13 +54,16 @@ func init() {
 // CheckTx checks if a transaction is valid. If return Code != 0, it will be rejected from the
 // mempool and hence not broadcasted to other peers and not included in a proposal block.
 func (app *ShutterApp) CheckTx(req abcitypes.RequestCheckTx) abcitypes.ResponseCheckTx {
-	signer, msg, err := app.decodeTx(req.Tx)
+	signer, msg, work, err := app.decodeTx(req.Tx)
 	if err != nil {
 		return abcitypes.ResponseCheckTx{Code: 1}
 	}
 	if string(msg.ChainId) != app.ChainID {
 		return abcitypes.ResponseCheckTx{Code: 1}
 	}
+	if work < app.MessageWorkBits {
+		return abcitypes.ResponseCheckTx{Code: 1, Log: insufficientWorkLog(work, app.MessageWorkBits)}
+	}
 	if !app.NonceTracker.Check(signer, msg.RandomNonce) {
 		return abcitypes.ResponseCheckTx{Code: 1}
 	}
@@ -140,7 +144,7 @@ func (app *ShutterApp) checkConfig(cfg BatchConfig) error {
 			lastConfig.ConfigIndex,
 		)
 	}
-	return nil
+	return cfg.EnsureEpochsFollow(lastConfig)
 }
 
 func (app *ShutterApp) addConfig(cfg BatchConfig) error {
@@ -177,6 +181,9 @@ func (app *ShutterApp) getBatchState(batchIndex uint64) BatchState {
 	return bs
 }
 
+// Query is not implemented. ABCI queries are open to everyone who can reach the node's RPC server,
+// so it must not serve data the keypers' APIs restrict to keypers, e.g. poly evals (see
+// keyper/access).
 func (app *ShutterApp) Query(_ abcitypes.RequestQuery) abcitypes.ResponseQuery {
 	return abcitypes.ResponseQuery{
 		Code: 1,
@@ -241,6 +248,9 @@ func (app *ShutterApp) InitChain(req abcitypes.RequestInitChain) abcitypes.Respo
 	if err != nil {
 		log.Fatalf("Invalid genesis app state: %s", err)
 	}
+	if genesisState.MessageWorkBits > shmsg.MaxMessageWorkBits {
+		log.Fatalf("Invalid genesis app state: message work must not exceed %d bits", shmsg.MaxMessageWorkBits)
+	}
 
 	if len(app.Configs) == 1 && len(app.Configs[0].Keypers) == 0 {
 		log.Print("Initializing new chain")
@@ -261,17 +271,24 @@ func (app *ShutterApp) InitChain(req abcitypes.RequestInitChain) abcitypes.Respo
 	}
 
 	app.ChainID = req.ChainId
+	app.MessageWorkBits = genesisState.MessageWorkBits
 
 	return abcitypes.ResponseInitChain{}
 }
 
-func (ShutterApp) BeginBlock(_ abcitypes.RequestBeginBlock) abcitypes.ResponseBeginBlock {
+func (app *ShutterApp) BeginBlock(req abcitypes.RequestBeginBlock) abcitypes.ResponseBeginBlock {
+	app.LastBlockHash = req.Hash
 	return abcitypes.ResponseBeginBlock{}
 }
 
-// decodeTx decodes the given transaction.  It's kind of strange that we have do URL decode the
-// message outselves instead of tendermint doing it for us.
-func (ShutterApp) decodeTx(tx []byte) (signer common.Address, msg *shmsg.MessageWithNonce, err error) {
+func insufficientWorkLog(work, required uint64) string {
+	return fmt.Sprintf("insufficient message work (%d bits instead of %d)", work, required)
+}
+
+// decodeTx decodes the given transaction and returns the work the sender has put into it.  It's
+// kind of strange that we have do URL decode the message outselves instead of tendermint doing it
+// for us.
+func (ShutterApp) decodeTx(tx []byte) (signer common.Address, msg *shmsg.MessageWithNonce, work uint64, err error) {
 	var signedMsg []byte
 	signedMsg, err = base64.RawURLEncoding.DecodeString(string(tx))
 	if err != nil {
@@ -287,11 +304,12 @@ func (ShutterApp) decodeTx(tx []byte) (signer common.Address, msg *shmsg.Message
 	if err != nil {
 		return
 	}
+	work = shmsg.MessageWork(signedMsg[crypto.SignatureLength:])
 	return
 }
 
 func (app *ShutterApp) DeliverTx(req abcitypes.RequestDeliverTx) abcitypes.ResponseDeliverTx {
-	signer, msg, err := app.decodeTx(req.Tx)
+	signer, msg, work, err := app.decodeTx(req.Tx)
 	if err != nil {
 		msg := fmt.Sprintf("Error while decoding transaction: %s", err)
 		log.Print(msg)
@@ -300,6 +318,10 @@ func (app *ShutterApp) DeliverTx(req abcitypes.RequestDeliverTx) abcitypes.Respo
 	if string(msg.ChainId) != app.ChainID {
 		return makeErrorResponse(fmt.Sprintf("wrong chain id (expected %s, got %s)", app.ChainID, msg.ChainId))
 	}
+	// A proposer may include txs that didn't pass CheckTx, so we have to check again
+	if work < app.MessageWorkBits {
+		return makeErrorResponse(insufficientWorkLog(work, app.MessageWorkBits))
+	}
 	if !app.NonceTracker.Check(signer, msg.RandomNonce) {
 		msg := fmt.Sprintf("Nonce %d of %s already used", msg.RandomNonce, signer.Hex())
 		return makeErrorResponse(msg)
@@ -373,6 +395,7 @@ func (app *ShutterApp) deliverBatchConfig(msg *shmsg.BatchConfig, sender common.
 			events = append(events, shutterevents.EonStarted{
 				Eon:        dkg.Eon,
 				BatchIndex: batchIndex,
+				Seed:       dkg.Seed,
 			}.MakeABCIEvent())
 		}
 	}
@@ -474,6 +497,7 @@ func (app *ShutterApp) deliverEonStartVoteMsg(msg *shmsg.EonStartVote, sender co
 			shutterevents.EonStarted{
 				Eon:        dkg.Eon,
 				BatchIndex: startBatchIndex,
+				Seed:       dkg.Seed,
 			}.MakeABCIEvent(),
 		},
 	}
@@ -526,6 +550,40 @@ func (app *ShutterApp) deliverDecryptionSignature(msg *shmsg.DecryptionSignature
 	}
 }
 
+func (app *ShutterApp) deliverSkipCipherVote(msg *shmsg.SkipCipherVote, sender common.Address) abcitypes.ResponseDeliverTx {
+	bs := app.getBatchState(msg.BatchIndex)
+	err := bs.AddSkipCipherVote(sender)
+	if err != nil {
+		msg := fmt.Sprintf("Error: cannot add skip cipher vote: %+v", err)
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+	app.BatchStates[msg.BatchIndex] = bs
+
+	event := shutterevents.SkipCipherVote{
+		BatchIndex: msg.BatchIndex,
+		Sender:     sender,
+	}.MakeABCIEvent()
+	return abcitypes.ResponseDeliverTx{
+		Code:   0,
+		Events: []abcitypes.Event{event},
+	}
+}
+
+func (app *ShutterApp) deliverHaltResumeVote(msg *shmsg.HaltResumeVote, sender common.Address) abcitypes.ResponseDeliverTx {
+	if !app.isKeyper(sender) {
+		return notAKeyper(sender)
+	}
+	event := shutterevents.HaltResumeVote{
+		Sender:     sender,
+		HaltHeight: msg.HaltHeight,
+	}.MakeABCIEvent()
+	return abcitypes.ResponseDeliverTx{
+		Code:   0,
+		Events: []abcitypes.Event{event},
+	}
+}
+
 func (app *ShutterApp) handlePolyEvalMsg(msg *shmsg.PolyEval, sender common.Address) abcitypes.ResponseDeliverTx {
 	appMsg, err := ParsePolyEvalMsg(msg, sender)
 	if err != nil {
@@ -584,6 +642,59 @@ func (app *ShutterApp) handlePolyCommitmentMsg(msg *shmsg.PolyCommitment, sender
 	}
 }
 
+// handlePolyCommitmentsMsg handles a message with the poly commitments for several eons. Either
+// all of them are registered or none.
+func (app *ShutterApp) handlePolyCommitmentsMsg(msg *shmsg.PolyCommitments, sender common.Address) abcitypes.ResponseDeliverTx {
+	if len(msg.Commitments) == 0 {
+		msg := "Error: Received PolyCommitments message without commitments"
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+
+	var appMsgs []*PolyCommitment
+	eons := make(map[uint64]struct{})
+	for _, commitment := range msg.Commitments {
+		appMsg, err := ParsePolyCommitmentMsg(commitment, sender)
+		if err != nil {
+			msg := fmt.Sprintf("Error: Failed to parse PolyCommitments message: %+v", err)
+			log.Print(msg)
+			return makeErrorResponse(msg)
+		}
+		if _, ok := eons[appMsg.Eon]; ok {
+			msg := fmt.Sprintf("Error: Received PolyCommitments message with multiple commitments for eon %d", appMsg.Eon)
+			log.Print(msg)
+			return makeErrorResponse(msg)
+		}
+		eons[appMsg.Eon] = struct{}{}
+
+		dkg := app.DKGMap[appMsg.Eon]
+		if dkg == nil {
+			msg := fmt.Sprintf("Error: Received PolyCommitments message for eon %d while DKG is not active", appMsg.Eon)
+			log.Print(msg)
+			return makeErrorResponse(msg)
+		}
+		if err := dkg.checkPolyCommitmentMsg(*appMsg); err != nil {
+			msg := fmt.Sprintf("Error: Failed to register PolyCommitments message: %+v", err)
+			log.Print(msg)
+			return makeErrorResponse(msg)
+		}
+		appMsgs = append(appMsgs, appMsg)
+	}
+
+	var events []abcitypes.Event
+	for _, appMsg := range appMsgs {
+		err := app.DKGMap[appMsg.Eon].RegisterPolyCommitmentMsg(*appMsg)
+		if err != nil {
+			panic(err) // checked above
+		}
+		events = append(events, appMsg.MakeABCIEvent())
+	}
+	return abcitypes.ResponseDeliverTx{
+		Code:   0,
+		Events: events,
+	}
+}
+
 func (app *ShutterApp) handleAccusationMsg(msg *shmsg.Accusation, sender common.Address) abcitypes.ResponseDeliverTx {
 	appMsg, err := ParseAccusationMsg(msg, sender)
 	if err != nil {
@@ -657,6 +768,65 @@ func (app *ShutterApp) handleEpochSecretKeyShareMsg(msg *shmsg.EpochSecretKeySha
 	}
 }
 
+func (app *ShutterApp) deliverDirectMessage(msg *shmsg.DirectMessage, sender common.Address) abcitypes.ResponseDeliverTx {
+	appMsg, err := ParseDirectMessage(msg, sender)
+	if err != nil {
+		msg := fmt.Sprintf("Error: Failed to parse DirectMessage message: %+v", err)
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+	if !app.isKeyper(sender) || !app.isKeyper(appMsg.Receiver) {
+		msg := "Error: DirectMessage messages can only be sent between keypers"
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+	return abcitypes.ResponseDeliverTx{
+		Code:   0,
+		Events: []abcitypes.Event{appMsg.MakeABCIEvent()},
+	}
+}
+
+func (app *ShutterApp) deliverCapabilities(msg *shmsg.Capabilities, sender common.Address) abcitypes.ResponseDeliverTx {
+	if !app.isKeyper(sender) {
+		return notAKeyper(sender)
+	}
+	appMsg, err := ParseCapabilities(msg, sender)
+	if err != nil {
+		msg := fmt.Sprintf("Error: Failed to parse Capabilities message: %+v", err)
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+	return abcitypes.ResponseDeliverTx{
+		Code:   0,
+		Events: []abcitypes.Event{appMsg.MakeABCIEvent()},
+	}
+}
+
+// deliverDecryptionApproval accepts approvals from the members of the eon's committee only, as
+// only their shares can decrypt the epoch.
+func (app *ShutterApp) deliverDecryptionApproval(msg *shmsg.DecryptionApproval, sender common.Address) abcitypes.ResponseDeliverTx {
+	dkg := app.DKGMap[msg.Eon]
+	if dkg == nil {
+		msg := fmt.Sprintf("Error: Received DecryptionApproval message for unknown eon %d", msg.Eon)
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+	if !dkg.isMember(sender) {
+		msg := fmt.Sprintf("Error: DecryptionApproval sender %s is not a keyper of eon %d", sender.Hex(), dkg.Eon)
+		log.Print(msg)
+		return makeErrorResponse(msg)
+	}
+	event := shutterevents.DecryptionApproval{
+		Sender: sender,
+		Eon:    msg.Eon,
+		Epoch:  msg.Epoch,
+	}.MakeABCIEvent()
+	return abcitypes.ResponseDeliverTx{
+		Code:   0,
+		Events: []abcitypes.Event{event},
+	}
+}
+
 func (app *ShutterApp) deliverMessage(msg *shmsg.Message, sender common.Address) abcitypes.ResponseDeliverTx {
 	if msg.GetBatchConfig() != nil {
 		return app.deliverBatchConfig(msg.GetBatchConfig(), sender)
@@ -673,6 +843,18 @@ func (app *ShutterApp) deliverMessage(msg *shmsg.Message, sender common.Address)
 	if msg.GetDecryptionSignature() != nil {
 		return app.deliverDecryptionSignature(msg.GetDecryptionSignature(), sender)
 	}
+	if msg.GetSkipCipherVote() != nil {
+		return app.deliverSkipCipherVote(msg.GetSkipCipherVote(), sender)
+	}
+	if msg.GetHaltResumeVote() != nil {
+		return app.deliverHaltResumeVote(msg.GetHaltResumeVote(), sender)
+	}
+	if msg.GetCapabilities() != nil {
+		return app.deliverCapabilities(msg.GetCapabilities(), sender)
+	}
+	if msg.GetDecryptionApproval() != nil {
+		return app.deliverDecryptionApproval(msg.GetDecryptionApproval(), sender)
+	}
 
 	if msg.GetPolyEval() != nil {
 		return app.handlePolyEvalMsg(msg.GetPolyEval(), sender)
@@ -680,6 +862,9 @@ func (app *ShutterApp) deliverMessage(msg *shmsg.Message, sender common.Address)
 	if msg.GetPolyCommitment() != nil {
 		return app.handlePolyCommitmentMsg(msg.GetPolyCommitment(), sender)
 	}
+	if msg.GetPolyCommitments() != nil {
+		return app.handlePolyCommitmentsMsg(msg.GetPolyCommitments(), sender)
+	}
 	if msg.GetAccusation() != nil {
 		return app.handleAccusationMsg(msg.GetAccusation(), sender)
 	}
@@ -689,6 +874,9 @@ func (app *ShutterApp) deliverMessage(msg *shmsg.Message, sender common.Address)
 	if msg.GetEpochSecretKeyShare() != nil {
 		return app.handleEpochSecretKeyShareMsg(msg.GetEpochSecretKeyShare(), sender)
 	}
+	if msg.GetDirectMessage() != nil {
+		return app.deliverDirectMessage(msg.GetDirectMessage(), sender)
+	}
 	log.Print("Error: cannot deliver messsage: ", msg)
 	return makeErrorResponse("cannot deliver message")
 }
@@ -701,15 +889,23 @@ func (app *ShutterApp) ShouldStartDKG(config BatchConfig) bool {
 		return true
 	}
 	previousConfig := dkg.Config
-	if previousConfig.Threshold != config.Threshold {
+	if previousConfig.Threshold != config.Threshold || previousConfig.CommitteeSize != config.CommitteeSize {
 		return true
 	}
 	return !reflect.DeepEqual(previousConfig.Keypers, config.Keypers)
 }
 
+// eonSeed returns the seed used to sample the committee of the given eon. It's derived from the
+// hash of the block the eon is started in, which is not known in advance to the keypers.
+func (app *ShutterApp) eonSeed(eon uint64) []byte {
+	var eonBytes [8]byte
+	binary.BigEndian.PutUint64(eonBytes[:], eon)
+	return crypto.Keccak256(app.LastBlockHash, eonBytes[:])
+}
+
 func (app *ShutterApp) StartDKG(config BatchConfig) *DKGInstance {
 	app.EONCounter++
-	dkg := NewDKGInstance(config, app.EONCounter)
+	dkg := NewDKGInstance(config, app.EONCounter, app.eonSeed(app.EONCounter))
 	app.DKGMap[dkg.Eon] = &dkg
 	return &dkg
 }
@@ -1,14 +1,18 @@
 package app
 
 import (
+	"crypto/rand"
 	"encoding/base64"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 
+	"github.com/shutter-network/shutter/shlib/shcrypto"
 	"github.com/shutter-network/shutter/shlib/shtest"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
 	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
 
@@ -145,6 +149,97 @@ func TestAddDecryptionSignature(t *testing.T) {
 	assert.Assert(t, is.Len(res1.Events, 0))
 }
 
+func TestAddSkipCipherVote(t *testing.T) {
+	app := NewShutterApp()
+	keypers := addresses[:3]
+	err := app.addConfig(BatchConfig{
+		ConfigIndex:     1,
+		StartBatchIndex: 100,
+		Threshold:       2,
+		Keypers:         keypers,
+	})
+	assert.NilError(t, err)
+
+	res := app.deliverMessage(shmsg.NewSkipCipherVote(200), addresses[3])
+	assert.Assert(t, res.IsErr())
+
+	res = app.deliverMessage(shmsg.NewSkipCipherVote(200), keypers[0])
+	assert.Assert(t, res.IsOK())
+	assert.Equal(t, 1, len(res.Events))
+	ev, err := shutterevents.MakeEvent(res.Events[0], 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ev, &shutterevents.SkipCipherVote{BatchIndex: 200, Sender: keypers[0]})
+
+	res = app.deliverMessage(shmsg.NewSkipCipherVote(200), keypers[0])
+	assert.Assert(t, res.IsErr())
+	res = app.deliverMessage(shmsg.NewSkipCipherVote(201), keypers[0])
+	assert.Assert(t, res.IsOK())
+	assert.DeepEqual(t, app.BatchStates[200].SkipCipherVotes, []common.Address{keypers[0]})
+}
+
+func TestDeliverHaltResumeVote(t *testing.T) {
+	app := NewShutterApp()
+	keypers := addresses[:3]
+	err := app.addConfig(BatchConfig{
+		ConfigIndex:     1,
+		StartBatchIndex: 100,
+		Threshold:       2,
+		Keypers:         keypers,
+	})
+	assert.NilError(t, err)
+
+	res := app.deliverMessage(shmsg.NewHaltResumeVote(50), addresses[3])
+	assert.Assert(t, res.IsErr())
+
+	res = app.deliverMessage(shmsg.NewHaltResumeVote(50), keypers[1])
+	assert.Assert(t, res.IsOK())
+	assert.Equal(t, 1, len(res.Events))
+	ev, err := shutterevents.MakeEvent(res.Events[0], 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ev, &shutterevents.HaltResumeVote{Sender: keypers[1], HaltHeight: 50})
+}
+
+func TestDeliverCapabilities(t *testing.T) {
+	app := NewShutterApp()
+	keypers := addresses[:3]
+	err := app.addConfig(BatchConfig{
+		ConfigIndex:     1,
+		StartBatchIndex: 100,
+		Threshold:       2,
+		Keypers:         keypers,
+	})
+	assert.NilError(t, err)
+
+	res := app.deliverMessage(shmsg.NewCapabilities("v1", []string{"a"}), addresses[3])
+	assert.Assert(t, res.IsErr())
+
+	res = app.deliverMessage(shmsg.NewCapabilities("v1", []string{"a"}), keypers[1])
+	assert.Assert(t, res.IsOK())
+	assert.Equal(t, 1, len(res.Events))
+	ev, err := shutterevents.MakeEvent(res.Events[0], 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ev, &shutterevents.Capabilities{Sender: keypers[1], Version: "v1", Features: []string{"a"}})
+}
+
+func TestDeliverDecryptionApproval(t *testing.T) {
+	app := NewShutterApp()
+	keypers := addresses[:3]
+	dkg := NewDKGInstance(BatchConfig{Threshold: 2, Keypers: keypers}, 2, nil)
+	app.DKGMap[2] = &dkg
+
+	res := app.deliverMessage(shmsg.NewDecryptionApproval(2, 17), addresses[3])
+	assert.Assert(t, res.IsErr())
+	res = app.deliverMessage(shmsg.NewDecryptionApproval(3, 17), keypers[1])
+	assert.Assert(t, res.IsErr())
+
+	res = app.deliverMessage(shmsg.NewDecryptionApproval(2, 17), keypers[1])
+	assert.Assert(t, res.IsOK())
+	assert.Equal(t, 1, len(res.Events))
+	ev, err := shutterevents.MakeEvent(res.Events[0], 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ev, &shutterevents.DecryptionApproval{Sender: keypers[1], Eon: 2, Epoch: 17})
+}
+
 func TestGobDKG(t *testing.T) {
 	var eon uint64 = 201
 	var err error
@@ -154,7 +249,7 @@ func TestGobDKG(t *testing.T) {
 		StartBatchIndex: 100,
 		Threshold:       1,
 		Keypers:         keypers,
-	}, eon)
+	}, eon, nil)
 
 	err = dkg.RegisterAccusationMsg(Accusation{
 		Sender:  keypers[0],
@@ -186,3 +281,53 @@ func TestGobDKG(t *testing.T) {
 
 	shtest.EnsureGobable(t, &dkg, new(DKGInstance))
 }
+
+func TestPolyCommitmentsMsg(t *testing.T) {
+	app := NewShutterApp()
+	keypers := addr[:3]
+	for eon := uint64(1); eon <= 2; eon++ {
+		dkg := NewDKGInstance(BatchConfig{Threshold: 2, Keypers: keypers}, eon, nil)
+		app.DKGMap[eon] = &dkg
+	}
+	commitment := func(eon uint64) *shmsg.PolyCommitment {
+		poly, err := shcrypto.RandomPolynomial(rand.Reader, 1)
+		assert.NilError(t, err)
+		return shmsg.NewPolyCommitmentPayload(eon, poly.Gammas())
+	}
+
+	// nothing is registered if one of the commitments is invalid
+	res := app.deliverMessage(shmsg.NewPolyCommitments([]*shmsg.PolyCommitment{commitment(1), commitment(3)}), keypers[0])
+	assert.Assert(t, res.IsErr())
+	res = app.deliverMessage(shmsg.NewPolyCommitments([]*shmsg.PolyCommitment{commitment(1), commitment(1)}), keypers[0])
+	assert.Assert(t, res.IsErr())
+	res = app.deliverMessage(shmsg.NewPolyCommitments(nil), keypers[0])
+	assert.Assert(t, res.IsErr())
+	assert.Equal(t, len(app.DKGMap[1].PolyCommitmentsSeen), 0)
+
+	res = app.deliverMessage(shmsg.NewPolyCommitments([]*shmsg.PolyCommitment{commitment(1), commitment(2)}), keypers[0])
+	assert.Assert(t, res.IsOK(), res.Log)
+	assert.Equal(t, len(res.Events), 2)
+	for i, ev := range res.Events {
+		e, err := shutterevents.MakeEvent(ev, 0)
+		assert.NilError(t, err)
+		pc, ok := e.(*shutterevents.PolyCommitment)
+		assert.Assert(t, ok)
+		assert.Equal(t, pc.Eon, uint64(i+1))
+		assert.Equal(t, pc.Sender, keypers[0])
+	}
+
+	// a second commitment for the same eon is rejected, whether batched or not
+	res = app.deliverMessage(&shmsg.Message{Payload: &shmsg.Message_PolyCommitment{PolyCommitment: commitment(2)}}, keypers[0])
+	assert.Assert(t, res.IsErr())
+	res = app.deliverMessage(shmsg.NewPolyCommitments([]*shmsg.PolyCommitment{commitment(2)}), keypers[1])
+	assert.Assert(t, res.IsOK(), res.Log)
+}
+
+func TestQueryServesNothing(t *testing.T) {
+	app := NewShutterApp()
+	for _, path := range []string{"", "/polyevals", "/eons/1"} {
+		res := app.Query(abcitypes.RequestQuery{Path: path})
+		assert.Equal(t, res.Code, uint32(1))
+		assert.Equal(t, len(res.Value), 0)
+	}
+}
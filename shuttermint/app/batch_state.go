@@ -1,6 +1,9 @@
 package app
 
-import "github.com/pkg/errors"
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
 
 // AddDecryptionSignature adds a decryption signature to the batch.
 func (bs *BatchState) AddDecryptionSignature(ds DecryptionSignature) error {
@@ -18,3 +21,20 @@ func (bs *BatchState) AddDecryptionSignature(ds DecryptionSignature) error {
 
 	return nil
 }
+
+// AddSkipCipherVote adds a keyper's vote to skip the cipher execution of the batch.
+func (bs *BatchState) AddSkipCipherVote(sender common.Address) error {
+	if !bs.Config.IsKeyper(sender) {
+		return errors.Errorf("sender %s is not a keyper", sender.Hex())
+	}
+
+	for _, voter := range bs.SkipCipherVotes {
+		if voter == sender {
+			return errors.Errorf("already have skip cipher vote from %s", sender.Hex())
+		}
+	}
+
+	bs.SkipCipherVotes = append(bs.SkipCipherVotes, sender)
+
+	return nil
+}
@@ -1,10 +1,16 @@
 package app
 
 import (
+	"context"
+	"encoding/base64"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"google.golang.org/protobuf/proto"
 	"gotest.tools/v3/assert"
 
 	"github.com/shutter-network/shutter/shuttermint/shmsg"
@@ -64,3 +70,42 @@ func TestCheckTxStateNonce(t *testing.T) {
 	s.Reset()
 	assert.Assert(t, s.AddTx(a1, msg1))
 }
+
+func TestMessageWork(t *testing.T) {
+	app := NewShutterApp()
+	app.ChainID = "test"
+	app.MessageWorkBits = 8
+	privateKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+
+	makeTx := func(msg *shmsg.MessageWithNonce) []byte {
+		signed, err := shmsg.SignMessage(msg, privateKey)
+		assert.NilError(t, err)
+		return []byte(base64.RawURLEncoding.EncodeToString(signed))
+	}
+	msg := &shmsg.MessageWithNonce{
+		ChainId: []byte("test"),
+		Msg:     shmsg.NewBatchConfigStarted(0),
+	}
+	// find a nonce that doesn't happen to provide enough work
+	for {
+		marshaled, err := proto.Marshal(msg)
+		assert.NilError(t, err)
+		if shmsg.MessageWork(marshaled) < app.MessageWorkBits {
+			break
+		}
+		msg.RandomNonce++
+	}
+	tx := makeTx(msg)
+	assert.Equal(t, app.CheckTx(abcitypes.RequestCheckTx{Tx: tx}).Code, uint32(1))
+	res := app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx})
+	assert.Assert(t, res.Code == 1)
+	assert.Assert(t, strings.Contains(res.Log, "insufficient message work"), res.Log)
+
+	_, err = shmsg.AddWork(context.Background(), msg, app.MessageWorkBits)
+	assert.NilError(t, err)
+	tx = makeTx(msg)
+	assert.Equal(t, app.CheckTx(abcitypes.RequestCheckTx{Tx: tx}).Code, uint32(0))
+	res = app.DeliverTx(abcitypes.RequestDeliverTx{Tx: tx})
+	assert.Assert(t, !strings.Contains(res.Log, "insufficient message work"), res.Log)
+}
@@ -5,11 +5,14 @@ import (
 	"github.com/pkg/errors"
 )
 
-// NewDKGInstance creates a new DKGInstance.
-func NewDKGInstance(config BatchConfig, eon uint64) DKGInstance {
+// NewDKGInstance creates a new DKGInstance. The seed is used to sample the committee of keypers
+// taking part.
+func NewDKGInstance(config BatchConfig, eon uint64, seed []byte) DKGInstance {
 	return DKGInstance{
 		Config:              config,
 		Eon:                 eon,
+		Seed:                seed,
+		Committee:           config.Committee(seed),
 		PolyEvalsSeen:       make(map[SenderReceiverPair]struct{}),
 		PolyCommitmentsSeen: make(map[common.Address]struct{}),
 		AccusationsSeen:     make(map[common.Address]struct{}),
@@ -17,6 +20,20 @@ func NewDKGInstance(config BatchConfig, eon uint64) DKGInstance {
 	}
 }
 
+// isMember checks if the given address is part of the committee running the DKG.
+func (dkg *DKGInstance) isMember(a common.Address) bool {
+	if dkg.Committee == nil {
+		// instances created by older versions do not store the committee
+		return dkg.Config.IsKeyper(a)
+	}
+	for _, m := range dkg.Committee {
+		if m == a {
+			return true
+		}
+	}
+	return false
+}
+
 // RegisterPolyEvalMsg adds a polynomial evaluation message to the instance. It makes sure the
 // message meets the basic requirements, i.e. the sender and receivers are keypers and we do not
 // send multiple messages from one sender to one receiver.
@@ -26,12 +43,12 @@ func (dkg *DKGInstance) RegisterPolyEvalMsg(msg PolyEval) error {
 	}
 
 	sender := msg.Sender
-	if !dkg.Config.IsKeyper(sender) {
+	if !dkg.isMember(sender) {
 		return errors.Errorf("sender %s is not a keyper", sender.Hex())
 	}
 
 	for _, receiver := range msg.Receivers {
-		if !dkg.Config.IsKeyper(receiver) {
+		if !dkg.isMember(receiver) {
 			return errors.Errorf("receiver %s is not a keyper", msg.Sender.Hex())
 		}
 		if receiver == sender {
@@ -52,18 +69,25 @@ func (dkg *DKGInstance) RegisterPolyEvalMsg(msg PolyEval) error {
 
 // RegisterPolyCommitmentMsg adds a polynomial commitment message to the instance.
 func (dkg *DKGInstance) RegisterPolyCommitmentMsg(msg PolyCommitment) error {
+	if err := dkg.checkPolyCommitmentMsg(msg); err != nil {
+		return err
+	}
+	dkg.PolyCommitmentsSeen[msg.Sender] = struct{}{}
+
+	return nil
+}
+
+// checkPolyCommitmentMsg checks if the polynomial commitment message could be registered.
+func (dkg *DKGInstance) checkPolyCommitmentMsg(msg PolyCommitment) error {
 	if msg.Eon != dkg.Eon {
 		return errors.Errorf("msg is from eon %d, not %d", msg.Eon, dkg.Eon)
 	}
-	if !dkg.Config.IsKeyper(msg.Sender) {
+	if !dkg.isMember(msg.Sender) {
 		return errors.Errorf("sender %s is not a keyper", msg.Sender.Hex())
 	}
-
 	if _, ok := dkg.PolyCommitmentsSeen[msg.Sender]; ok {
 		return errors.Errorf("polynomial commitment from keyper %s already present", msg.Sender.Hex())
 	}
-	dkg.PolyCommitmentsSeen[msg.Sender] = struct{}{}
-
 	return nil
 }
 
@@ -72,11 +96,11 @@ func (dkg *DKGInstance) RegisterAccusationMsg(msg Accusation) error {
 	if msg.Eon != dkg.Eon {
 		return errors.Errorf("msg is from eon %d, not %d", msg.Eon, dkg.Eon)
 	}
-	if !dkg.Config.IsKeyper(msg.Sender) {
+	if !dkg.isMember(msg.Sender) {
 		return errors.Errorf("sender %s is not a keyper", msg.Sender.Hex())
 	}
 	for _, accused := range msg.Accused {
-		if !dkg.Config.IsKeyper(accused) {
+		if !dkg.isMember(accused) {
 			return errors.Errorf("accused %s is not a keyper", accused.Hex())
 		}
 		if msg.Sender == accused {
@@ -97,11 +121,11 @@ func (dkg *DKGInstance) RegisterApologyMsg(msg Apology) error {
 	if msg.Eon != dkg.Eon {
 		return errors.Errorf("msg is from eon %d, not %d", msg.Eon, dkg.Eon)
 	}
-	if !dkg.Config.IsKeyper(msg.Sender) {
+	if !dkg.isMember(msg.Sender) {
 		return errors.Errorf("sender %s is not a keyper", msg.Sender.Hex())
 	}
 	for _, accuser := range msg.Accusers {
-		if !dkg.Config.IsKeyper(accuser) {
+		if !dkg.isMember(accuser) {
 			return errors.Errorf("accuser %s is not a keyper", msg.Sender.Hex())
 		}
 		if msg.Sender == accuser {
@@ -22,7 +22,7 @@ func TestRegisterMsgs(t *testing.T) {
 	}
 
 	t.Run("RegisterPolyEvalMsg", func(t *testing.T) {
-		dkg := NewDKGInstance(config, eon)
+		dkg := NewDKGInstance(config, eon, nil)
 
 		// fail if wrong eon
 		msg := PolyEval{
@@ -86,7 +86,7 @@ func TestRegisterMsgs(t *testing.T) {
 	})
 
 	t.Run("RegisterPolyCommitmentMsg", func(t *testing.T) {
-		dkg := NewDKGInstance(config, eon)
+		dkg := NewDKGInstance(config, eon, nil)
 
 		// fail if wrong eon
 		msg := PolyCommitment{
@@ -128,7 +128,7 @@ func TestRegisterMsgs(t *testing.T) {
 	})
 
 	t.Run("RegisterAccusationMsg", func(t *testing.T) {
-		dkg := NewDKGInstance(config, eon)
+		dkg := NewDKGInstance(config, eon, nil)
 
 		// fail if wrong eon
 		msg := Accusation{
@@ -196,7 +196,7 @@ func TestRegisterMsgs(t *testing.T) {
 	})
 
 	t.Run("RegisterApologyMsg", func(t *testing.T) {
-		dkg := NewDKGInstance(config, eon)
+		dkg := NewDKGInstance(config, eon, nil)
 
 		// fail if wrong eon
 		msg := Apology{
@@ -269,3 +269,27 @@ func TestRegisterMsgs(t *testing.T) {
 		assert.Assert(t, err != nil)
 	})
 }
+
+func TestCommitteeMembership(t *testing.T) {
+	eon := uint64(10)
+	keypers := []common.Address{}
+	for i := 0; i < 10; i++ {
+		keypers = append(keypers, common.BigToAddress(big.NewInt(int64(i+10))))
+	}
+	config := BatchConfig{
+		Keypers:       keypers,
+		Threshold:     2,
+		CommitteeSize: 3,
+	}
+	dkg := NewDKGInstance(config, eon, []byte("seed"))
+	assert.Equal(t, 3, len(dkg.Committee))
+
+	for _, k := range keypers {
+		err := dkg.RegisterPolyCommitmentMsg(PolyCommitment{Sender: k, Eon: eon})
+		if dkg.isMember(k) {
+			assert.NilError(t, err)
+		} else {
+			assert.ErrorContains(t, err, "not a keyper")
+		}
+	}
+}
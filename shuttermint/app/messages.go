@@ -4,7 +4,6 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
 	"github.com/pkg/errors"
 
 	"github.com/shutter-network/shutter/shlib/shcrypto"
@@ -12,6 +11,18 @@ import (
 	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
 
+// Limits of direct messages. They are meant for small protocol messages, not bulk data.
+const (
+	maxDirectMessageTopicLength = 64
+	maxDirectMessagePayloadSize = 16 * 1024
+)
+
+// Limits of capability announcements, which keypers send every now and then.
+const (
+	maxCapabilityLength   = 64 // of the version and of each feature
+	maxCapabilityFeatures = 64
+)
+
 func validateAddress(address []byte) (common.Address, error) {
 	if len(address) != common.AddressLength {
 		return common.Address{}, errors.Errorf(
@@ -53,15 +64,11 @@ func ParsePolyEvalMsg(msg *shmsg.PolyEval, sender common.Address) (*PolyEval, er
 
 // ParsePolyCommitmentMsg converts a shmsg.PolyCommitmentMsg to an app.PolyCommitmentMsg.
 func ParsePolyCommitmentMsg(msg *shmsg.PolyCommitment, sender common.Address) (*PolyCommitment, error) {
-	gammas := shcrypto.Gammas{}
-	for _, g := range msg.Gammas {
-		g2 := new(bn256.G2)
-		_, err := g2.Unmarshal(g)
-		if err != nil {
-			return nil, err
-		}
-		gammas = append(gammas, g2)
+	points, err := shcrypto.UnmarshalG2Batch(msg.Gammas)
+	if err != nil {
+		return nil, err
 	}
+	gammas := shcrypto.Gammas(points)
 	return &PolyCommitment{
 		Sender: sender,
 		Eon:    msg.Eon,
@@ -129,15 +136,59 @@ func ParseApologyMsg(msg *shmsg.Apology, sender common.Address) (*Apology, error
 
 // ParseEpochSecretKeyShareMsg converts a shmsg.EpochSecretKeyShareMsg to an app.EpochSecretShareMsg.
 func ParseEpochSecretKeyShareMsg(msg *shmsg.EpochSecretKeyShare, sender common.Address) (*EpochSecretKeyShare, error) {
-	share := new(shcrypto.EpochSecretKeyShare)
-	err := share.GobDecode(msg.Share)
+	share, err := shcrypto.ValidateEpochSecretKeyShare(msg.Share)
 	if err != nil {
 		return nil, err
 	}
 	return &EpochSecretKeyShare{
-		Sender: sender,
-		Eon:    msg.Eon,
-		Epoch:  msg.Epoch,
-		Share:  share,
+		Sender:    sender,
+		Eon:       msg.Eon,
+		Epoch:     msg.Epoch,
+		Share:     share,
+		Namespace: msg.Namespace,
+	}, nil
+}
+
+// ParseDirectMessage converts a shmsg.DirectMessage to an app.DirectMessage.
+func ParseDirectMessage(msg *shmsg.DirectMessage, sender common.Address) (*DirectMessage, error) {
+	receiver, err := validateAddress(msg.Receiver)
+	if err != nil {
+		return nil, err
+	}
+	if receiver == sender {
+		return nil, errors.Errorf("sender and receiver are the same")
+	}
+	if msg.Topic == "" || len(msg.Topic) > maxDirectMessageTopicLength {
+		return nil, errors.Errorf("invalid topic length %d", len(msg.Topic))
+	}
+	if len(msg.EncryptedPayload) > maxDirectMessagePayloadSize {
+		return nil, errors.Errorf(
+			"payload too large (%d > %d bytes)", len(msg.EncryptedPayload), maxDirectMessagePayloadSize)
+	}
+	return &DirectMessage{
+		Sender:           sender,
+		Receiver:         receiver,
+		Topic:            msg.Topic,
+		EncryptedPayload: msg.EncryptedPayload,
+	}, nil
+}
+
+// ParseCapabilities converts a shmsg.Capabilities message to an app.Capabilities message.
+func ParseCapabilities(msg *shmsg.Capabilities, sender common.Address) (*Capabilities, error) {
+	if len(msg.Version) > maxCapabilityLength {
+		return nil, errors.Errorf("version too long (%d > %d bytes)", len(msg.Version), maxCapabilityLength)
+	}
+	if len(msg.Features) > maxCapabilityFeatures {
+		return nil, errors.Errorf("too many features (%d > %d)", len(msg.Features), maxCapabilityFeatures)
+	}
+	for _, feature := range msg.Features {
+		if feature == "" || len(feature) > maxCapabilityLength {
+			return nil, errors.Errorf("invalid feature length %d", len(feature))
+		}
+	}
+	return &Capabilities{
+		Sender:   sender,
+		Version:  msg.Version,
+		Features: msg.Features,
 	}, nil
 }
@@ -107,4 +107,43 @@ func TestMessageParsing(t *testing.T) {
 		assert.Equal(t, epoch, msg.Epoch)
 		assert.DeepEqual(t, share, msg.Share)
 	})
+
+	t.Run("ParseDirectMessage", func(t *testing.T) {
+		smsg := shmsg.NewDirectMessage(anotherAddress, "vote", data).GetDirectMessage()
+		msg, err := ParseDirectMessage(smsg, sender)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, sender, msg.Sender)
+		assert.DeepEqual(t, anotherAddress, msg.Receiver)
+		assert.Equal(t, "vote", msg.Topic)
+		assert.DeepEqual(t, data, msg.EncryptedPayload)
+
+		smsg = &shmsg.DirectMessage{Receiver: badAddressBytes, Topic: "vote", EncryptedPayload: data}
+		_, err = ParseDirectMessage(smsg, sender)
+		assert.Assert(t, err != nil)
+
+		smsg = shmsg.NewDirectMessage(anotherAddress, "", data).GetDirectMessage()
+		_, err = ParseDirectMessage(smsg, sender)
+		assert.Assert(t, err != nil)
+
+		smsg = shmsg.NewDirectMessage(sender, "vote", data).GetDirectMessage()
+		_, err = ParseDirectMessage(smsg, sender)
+		assert.Assert(t, err != nil)
+	})
+
+	t.Run("ParseCapabilities", func(t *testing.T) {
+		smsg := shmsg.NewCapabilities("v1.2.3", []string{"a", "b"}).GetCapabilities()
+		msg, err := ParseCapabilities(smsg, sender)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, sender, msg.Sender)
+		assert.Equal(t, "v1.2.3", msg.Version)
+		assert.DeepEqual(t, []string{"a", "b"}, msg.Features)
+
+		smsg = shmsg.NewCapabilities("v1.2.3", []string{"a", ""}).GetCapabilities()
+		_, err = ParseCapabilities(smsg, sender)
+		assert.Assert(t, err != nil)
+
+		smsg = shmsg.NewCapabilities("v1.2.3", make([]string, maxCapabilityFeatures+1)).GetCapabilities()
+		_, err = ParseCapabilities(smsg, sender)
+		assert.Assert(t, err != nil)
+	})
 }
@@ -18,6 +18,9 @@ import (
 type GenesisAppState struct {
 	Keypers   []common.MixedcaseAddress `json:"keypers"`
 	Threshold uint64                    `json:"threshold"`
+	// MessageWorkBits is the work every message must carry as fee, see shmsg.MessageWork. 0
+	// disables the fee.
+	MessageWorkBits uint64 `json:"message_work_bits,omitempty"`
 }
 
 func NewGenesisAppState(keypers []common.Address, threshold int) GenesisAppState {
@@ -66,6 +69,7 @@ type BatchState struct {
 	BatchIndex           uint64
 	Config               *BatchConfig
 	DecryptionSignatures []DecryptionSignature
+	SkipCipherVotes      []common.Address
 }
 
 // ValidatorPubkey holds the raw 32 byte ed25519 public key to be used as tendermint validator key
@@ -100,6 +104,7 @@ type ShutterApp struct {
 	Gobpath         string
 	LastSaved       time.Time
 	LastBlockHeight int64
+	LastBlockHash   []byte // hash of the block currently being executed, used as randomness beacon
 	Identities      map[common.Address]ValidatorPubkey
 	StartedVotes    map[common.Address]struct{}
 	Validators      Powermap
@@ -108,6 +113,7 @@ type ShutterApp struct {
 	CheckTxState    *CheckTxState
 	NonceTracker    *NonceTracker
 	ChainID         string
+	MessageWorkBits uint64 // minimum work of every message, taken from the genesis app state
 }
 
 // CheckTxState is a part of the state used by CheckTx calls that is reset at every commit.
@@ -128,8 +134,10 @@ type SenderReceiverPair struct {
 
 // DKGInstance manages the state of one eon key generation instance.
 type DKGInstance struct {
-	Config BatchConfig
-	Eon    uint64
+	Config    BatchConfig
+	Eon       uint64
+	Seed      []byte
+	Committee []common.Address // the keypers taking part, sampled from Config using Seed
 
 	PolyEvalsSeen       map[SenderReceiverPair]struct{}
 	PolyCommitmentsSeen map[common.Address]struct{}
@@ -144,4 +152,6 @@ type (
 	PolyCommitment      = shutterevents.PolyCommitment
 	PolyEval            = shutterevents.PolyEval
 	EpochSecretKeyShare = shutterevents.EpochSecretKeyShare
+	DirectMessage       = shutterevents.DirectMessage
+	Capabilities        = shutterevents.Capabilities
 )
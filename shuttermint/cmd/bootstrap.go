@@ -150,6 +150,12 @@ func bootstrap() {
 		batchConfigIndex,
 		false,
 		false,
+		nil,
+		false,
+		0,
+		0,
+		0,
+		0,
 	)
 
 	err = ms.SendMessage(context.Background(), batchConfigMsg)
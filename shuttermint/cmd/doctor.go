@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/shutter/shuttermint/app"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/abidrift"
+)
+
+// maxClockSkew is the maximum difference between the local clock and the time of the latest
+// Shuttermint block we accept without warning.
+const maxClockSkew = 30 * time.Second
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the keyper's configuration and environment",
+	Long: `This command checks the keyper configuration, the keys, file permissions, the
+connections to the Ethereum and Shuttermint nodes, the deployed contracts, the keyper's
+deposit, and the local clock. For every problem found it prints a suggestion how to fix it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doctorMain()
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(doctorCmd)
+}
+
+type doctor struct {
+	numWarnings int
+	numFailures int
+}
+
+func (d *doctor) ok(check string, format string, args ...interface{}) {
+	fmt.Printf("[ OK ] %s: %s\n", check, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) warn(check string, fix string, format string, args ...interface{}) {
+	d.numWarnings++
+	fmt.Printf("[WARN] %s: %s\n", check, fmt.Sprintf(format, args...))
+	fmt.Printf("       fix: %s\n", fix)
+}
+
+func (d *doctor) fail(check string, fix string, format string, args ...interface{}) {
+	d.numFailures++
+	fmt.Printf("[FAIL] %s: %s\n", check, fmt.Sprintf(format, args...))
+	fmt.Printf("       fix: %s\n", fix)
+}
+
+func doctorMain() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	d := &doctor{}
+
+	config, err := readKeyperConfig()
+	if err != nil {
+		d.fail("config", "Check the syntax of the config file and the KEYPER_* environment variables", "%s", err)
+		return d.summary()
+	}
+	d.ok("config", "read config from %s", viper.ConfigFileUsed())
+
+	if !d.checkKeys(config) {
+		return d.summary()
+	}
+	d.checkPermissions(config)
+
+	ethcl := d.checkEthereum(ctx, config)
+	if ethcl != nil {
+		d.checkContracts(ctx, config, ethcl)
+		d.checkDeposit(ctx, config, ethcl)
+	}
+	d.checkShuttermint(ctx, config, ethcl)
+
+	return d.summary()
+}
+
+func (d *doctor) summary() error {
+	fmt.Printf("\n%d failures, %d warnings\n", d.numFailures, d.numWarnings)
+	if d.numFailures > 0 {
+		return errors.Errorf("found %d problems that prevent the keyper from working", d.numFailures)
+	}
+	return nil
+}
+
+func (d *doctor) checkKeys(config keyper.Config) bool {
+	ok := true
+	if config.SigningKey == nil {
+		d.fail("keys", "Set SigningKey in the config file or KEYPER_SIGNINGKEY", "no signing key")
+		ok = false
+	}
+	if config.EncryptionKey == nil {
+		d.fail("keys", "Set EncryptionKey in the config file or KEYPER_ENCRYPTIONKEY", "no encryption key")
+		ok = false
+	}
+	if len(config.ValidatorKey) != ed25519.PrivateKeySize {
+		d.fail("keys", "Set ValidatorSeed in the config file or KEYPER_VALIDATORSEED", "no validator key")
+		ok = false
+	}
+	if ok {
+		d.ok("keys", "keyper address is %s", config.Address().Hex())
+	}
+	return ok
+}
+
+func (d *doctor) checkPermissions(config keyper.Config) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			d.warn("permissions", "Make sure the config file is readable", "cannot stat %s: %s", path, err)
+		case info.Mode().Perm()&0o077 != 0:
+			d.warn(
+				"permissions",
+				fmt.Sprintf("Run 'chmod 600 %s'", path),
+				"config file %s contains secret keys but is accessible by other users (mode %s)",
+				path, info.Mode().Perm(),
+			)
+		default:
+			d.ok("permissions", "config file is only accessible by its owner")
+		}
+	}
+
+	dir := config.DBDir
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				d.fail("permissions", "Set DBDir to a directory", "%s is not a directory", dir)
+				return
+			}
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	f, err := ioutil.TempFile(dir, ".keyper-doctor")
+	if err != nil {
+		d.fail(
+			"permissions",
+			fmt.Sprintf("Make %s writable by the user running the keyper", dir),
+			"cannot write to database directory %s: %s", config.DBDir, err,
+		)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	d.ok("permissions", "database directory %s is writable", config.DBDir)
+}
+
+func (d *doctor) checkEthereum(ctx context.Context, config keyper.Config) *ethclient.Client {
+	ethcl, err := ethclient.DialContext(ctx, config.EthereumURL)
+	if err != nil {
+		d.fail("ethereum", "Check EthereumURL and that the Ethereum node is running", "cannot connect to %s: %s", config.EthereumURL, err)
+		return nil
+	}
+	chainID, err := ethcl.ChainID(ctx)
+	if err != nil {
+		d.fail("ethereum", "Check EthereumURL and that the Ethereum node is running", "cannot query chain id: %s", err)
+		return nil
+	}
+	header, err := ethcl.HeaderByNumber(ctx, nil)
+	if err != nil {
+		d.fail("ethereum", "Check that the Ethereum node is running", "cannot query latest block: %s", err)
+		return nil
+	}
+	d.ok("ethereum", "connected to chain %s at block %d", chainID, header.Number)
+	if err := config.CheckChain(chainID); err != nil {
+		d.fail("ethereum", "Choose a Profile and confirmations suitable for the chain", "%s", err)
+	}
+
+	progress, err := ethcl.SyncProgress(ctx)
+	if err != nil {
+		d.warn("ethereum", "Check the Ethereum node's logs", "cannot query sync progress: %s", err)
+	} else if progress != nil {
+		d.warn(
+			"ethereum",
+			"Wait for the Ethereum node to finish syncing",
+			"node is syncing (block %d of %d)", progress.CurrentBlock, progress.HighestBlock,
+		)
+	}
+
+	balance, err := ethcl.BalanceAt(ctx, config.Address(), nil)
+	if err != nil {
+		d.warn("ethereum", "Check the Ethereum node's logs", "cannot query balance: %s", err)
+	} else if balance.Sign() == 0 {
+		d.fail(
+			"ethereum",
+			fmt.Sprintf("Send some ETH to %s to pay for transactions", config.Address().Hex()),
+			"keyper account has no funds",
+		)
+	}
+	return ethcl
+}
+
+func (d *doctor) checkContracts(ctx context.Context, config keyper.Config, ethcl *ethclient.Client) {
+	contracts := []struct {
+		name     string
+		address  common.Address
+		optional bool
+	}{
+		{"ConfigContract", config.ConfigContractAddress, false},
+		{"KeyBroadcastContract", config.KeyBroadcastContractAddress, false},
+		{"BatcherContract", config.BatcherContractAddress, false},
+		{"ExecutorContract", config.ExecutorContractAddress, false},
+		{"DepositContract", config.DepositContractAddress, false},
+		{"KeyperSlasher", config.KeyperSlasherAddress, false},
+		{"DecryptionOracleContract", config.DecryptionOracleAddress, true},
+	}
+	if err := config.RegisterContractCodeHashes(); err != nil {
+		d.fail("contracts", "Fix the entries in ContractCodeHashes", "%s", err)
+		return
+	}
+	var deployed []abidrift.Contract
+	for _, c := range contracts {
+		if c.address == (common.Address{}) {
+			if !c.optional {
+				d.fail("contracts", fmt.Sprintf("Set %s in the config file", c.name), "no address configured for %s", c.name)
+			}
+			continue
+		}
+		check, err := contract.CheckVersion(ctx, ethcl, c.name, c.address)
+		if err != nil {
+			d.fail("contracts", fmt.Sprintf("Check the address of %s and that you're connected to the right chain", c.name), "%s", err)
+			continue
+		}
+		deployed = append(deployed, abidrift.Contract{Name: c.name, Address: c.address})
+		switch check.Status {
+		case contract.VersionKnown:
+			d.ok("contracts", "%s", check)
+		case contract.VersionCompatible:
+			d.warn(
+				"contracts",
+				fmt.Sprintf("If you trust this deployment, add \"%s=%s\" to ContractCodeHashes", c.name, check.CodeHash.Hex()),
+				"%s", check,
+			)
+		default:
+			d.fail("contracts", "Check the address or update the keyper to a version matching the contracts", "%s", check)
+		}
+	}
+
+	checker, err := abidrift.NewChecker(ethcl, deployed)
+	if err != nil {
+		d.fail("contracts", "Report this as a bug", "%s", err)
+		return
+	}
+	drifts, err := checker.Check(ctx)
+	switch {
+	case err != nil:
+		d.warn("contracts", "Check that the Ethereum node serves logs", "cannot check contract logs against our ABIs: %s", err)
+	case len(drifts) > 0:
+		for _, drift := range drifts {
+			d.fail("contracts", "Update the keyper to a version matching the contracts", "log doesn't match our ABI: %s", drift)
+		}
+	default:
+		d.ok("contracts", "recent logs match our ABIs")
+	}
+}
+
+func (d *doctor) checkDeposit(ctx context.Context, config keyper.Config, ethcl *ethclient.Client) {
+	if config.DepositContractAddress == (common.Address{}) {
+		return
+	}
+	depositContract, err := contract.NewDepositContract(config.DepositContractAddress, ethcl)
+	if err != nil {
+		d.fail("deposit", "Check DepositContract in the config file", "%s", err)
+		return
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	slashed, err := depositContract.IsSlashed(opts, config.Address())
+	if err != nil {
+		d.fail("deposit", "Check DepositContract in the config file", "cannot query deposit: %s", err)
+		return
+	}
+	if slashed {
+		d.fail("deposit", "Use a new signing key and make a new deposit", "keyper %s has been slashed", config.Address().Hex())
+		return
+	}
+	amount, err := depositContract.GetDepositAmount(opts, config.Address())
+	if err != nil {
+		d.fail("deposit", "Check DepositContract in the config file", "cannot query deposit: %s", err)
+		return
+	}
+	if amount.Cmp(big.NewInt(0)) == 0 {
+		d.warn("deposit", "Deposit tokens by sending them to the deposit contract", "no deposit")
+		return
+	}
+	requested, err := depositContract.GetWithdrawalRequestedBlock(opts, config.Address())
+	if err != nil {
+		d.fail("deposit", "Check DepositContract in the config file", "cannot query deposit: %s", err)
+		return
+	}
+	if requested != 0 {
+		d.warn("deposit", "Make a new deposit if you want to keep running the keyper", "withdrawal requested at block %d", requested)
+		return
+	}
+	d.ok("deposit", "deposit of %s", amount)
+}
+
+func (d *doctor) checkShuttermint(ctx context.Context, config keyper.Config, ethcl *ethclient.Client) {
+	shmcl, err := http.New(config.ShuttermintURL, "/websocket")
+	if err != nil {
+		d.fail("shuttermint", "Check ShuttermintURL", "%s", err)
+		return
+	}
+	status, err := shmcl.Status(ctx)
+	if err != nil {
+		d.fail("shuttermint", "Check ShuttermintURL and that the Shuttermint node is running", "cannot query status: %s", err)
+		return
+	}
+	d.ok("shuttermint", "connected to chain %s at height %d", status.NodeInfo.Network, status.SyncInfo.LatestBlockHeight)
+	if status.SyncInfo.CatchingUp {
+		d.warn("shuttermint", "Wait for the Shuttermint node to finish syncing", "node is catching up")
+	}
+
+	skew := time.Since(status.SyncInfo.LatestBlockTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	if !status.SyncInfo.CatchingUp && skew > maxClockSkew {
+		d.warn(
+			"clock",
+			"Synchronize the system clock, e.g., with NTP, or check that the Shuttermint chain is producing blocks",
+			"local clock differs from the latest Shuttermint block time by %s", skew.Round(time.Second),
+		)
+	} else if !status.SyncInfo.CatchingUp {
+		d.ok("clock", "local clock differs from the latest Shuttermint block time by %s", skew.Round(time.Second))
+	}
+
+	validatorPubkey := config.ValidatorKey.Public().(ed25519.PublicKey)
+	if bytes.Equal(status.ValidatorInfo.PubKey.Bytes(), validatorPubkey) {
+		d.ok("shuttermint", "connected node uses our validator key")
+	} else {
+		d.warn(
+			"shuttermint",
+			"Make sure ValidatorSeed matches the key in the validator node's priv_validator_key.json",
+			"connected node doesn't use our validator key",
+		)
+	}
+
+	genesis, err := shmcl.Genesis(ctx)
+	if err != nil {
+		d.fail("genesis", "Check that the Shuttermint node is running", "cannot query genesis: %s", err)
+		return
+	}
+	var appState app.GenesisAppState
+	if err := json.Unmarshal(genesis.Genesis.AppState, &appState); err != nil {
+		d.fail("genesis", "Make sure the node runs a Shuttermint chain", "cannot parse genesis app state: %s", err)
+		return
+	}
+	if ethcl == nil || config.ConfigContractAddress == (common.Address{}) {
+		return
+	}
+	configContract, err := contract.NewConfigContract(config.ConfigContractAddress, ethcl)
+	if err != nil {
+		d.fail("genesis", "Check ConfigContract in the config file", "%s", err)
+		return
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	numConfigs, err := configContract.NumConfigs(opts)
+	if err != nil {
+		d.fail("genesis", "Check ConfigContract in the config file", "cannot query configs: %s", err)
+		return
+	}
+	for i := uint64(0); i < numConfigs; i++ {
+		bc, err := configContract.GetConfigByIndex(opts, i)
+		if err != nil {
+			d.fail("genesis", "Check ConfigContract in the config file", "cannot query config %d: %s", i, err)
+			return
+		}
+		if bc.Threshold == appState.Threshold && genesisKeypersMatch(appState, bc.Keypers) {
+			d.ok("genesis", "genesis keypers match config %d of the config contract", i)
+			return
+		}
+	}
+	d.fail(
+		"genesis",
+		"Make sure ShuttermintURL and ConfigContract belong to the same Shutter deployment",
+		"the genesis keypers of chain %s don't match any config of the config contract", genesis.Genesis.ChainID,
+	)
+}
+
+func genesisKeypersMatch(appState app.GenesisAppState, keypers []common.Address) bool {
+	if len(appState.Keypers) != len(keypers) {
+		return false
+	}
+	for i, k := range appState.Keypers {
+		if k.Address() != keypers[i] {
+			return false
+		}
+	}
+	return true
+}
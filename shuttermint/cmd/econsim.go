@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/econsim"
+)
+
+var econsimFlags struct {
+	Params      econsim.Params
+	ReportEvery int
+}
+
+var econsimCmd = &cobra.Command{
+	Use:   "econsim",
+	Short: "Simulate the economics of a keyper set",
+	Long: `This command simulates the fees keypers earn by executing batches, the gas they spend on
+executions, accusations and appeals, and the deposits faulty keypers lose to slashing, for the
+given configuration parameters. Faulty keypers execute some batches incorrectly for a profit and
+accuse correct executions. An execution can be appealed if at least a threshold of keypers is
+willing to sign its decryption key, otherwise the accused keyper is slashed.
+
+The results are averaged over a number of runs and printed per honest and per faulty keyper.
+Amounts of ETH are given in ETH, deposits in deposit tokens.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return econsimMain()
+	},
+}
+
+func init() {
+	p := &econsimFlags.Params
+	flags := econsimCmd.Flags()
+	flags.IntVar(&p.NumKeypers, "keypers", 10, "number of keypers")
+	flags.IntVar(&p.Threshold, "threshold", 7, "threshold")
+	flags.IntVar(&p.NumFaulty, "faulty", 1, "number of faulty keypers")
+	flags.Float64Var(&p.Deposit, "deposit", 1000, "deposit of each keyper in tokens")
+	flags.Float64Var(&p.TokenPrice, "token-price", 0.001, "price of a deposit token in ETH")
+	flags.Float64Var(&p.SlashFraction, "slash-fraction", 1, "fraction of the deposit lost when being slashed")
+	flags.IntVar(&p.Days, "days", 30, "number of days to simulate")
+	flags.IntVar(&p.BatchesPerDay, "batches-per-day", 1000, "number of batches per day")
+	flags.Float64Var(&p.Fee, "fee", 0.005, "fee paid to the executor of a batch in ETH")
+	flags.Float64Var(&p.GasPrice, "gas-price", 50, "gas price in gwei")
+	flags.Uint64Var(&p.ExecuteGas, "execute-gas", 150000, "gas used to execute a batch")
+	flags.Uint64Var(&p.AccuseGas, "accuse-gas", 100000, "gas used to accuse an executor")
+	flags.Uint64Var(&p.AppealGas, "appeal-gas", 250000, "gas used to appeal an accusation")
+	flags.Uint64Var(&p.SlashGas, "slash-gas", 60000, "gas used to slash an executor")
+	flags.Float64Var(&p.FaultRate, "fault-rate", 0.01, "probability that a faulty keyper executes a batch incorrectly")
+	flags.Float64Var(&p.FaultGain, "fault-gain", 0.1, "profit in ETH of executing a batch incorrectly")
+	flags.Float64Var(&p.DetectionRate, "detection-rate", 0.9, "probability that an incorrect execution is accused")
+	flags.Float64Var(
+		&p.FalseAccusationRate,
+		"false-accusation-rate",
+		0,
+		"probability that faulty keypers accuse a correct execution",
+	)
+	flags.IntVar(&p.Runs, "runs", 100, "number of runs to average over")
+	flags.Int64Var(&p.Seed, "seed", 0, "seed of the random number generator")
+	flags.IntVar(&econsimFlags.ReportEvery, "report-every", 1, "print results every this many days")
+}
+
+func econsimMain() error {
+	snapshots, err := econsim.Simulate(econsimFlags.Params)
+	if err != nil {
+		return err
+	}
+	reportEvery := econsimFlags.ReportEvery
+	if reportEvery <= 0 {
+		reportEvery = 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Day\tExecutions\tWrong\tAccusations\tAppeals\t")
+	fmt.Fprintf(w, "Honest ETH\tHonest Deposit\tHonest Net\tHonest Slashed\t")
+	fmt.Fprintf(w, "Faulty ETH\tFaulty Deposit\tFaulty Net\tFaulty Slashed\t\n")
+	for i, s := range snapshots {
+		if s.Day%reportEvery != 0 && i != len(snapshots)-1 {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%.0f\t%.1f\t%.1f\t%.1f\t", s.Day, s.Executions, s.WrongExecutions, s.Accusations, s.Appeals)
+		for _, o := range []econsim.Outcome{s.Honest, s.Faulty} {
+			fmt.Fprintf(w, "%.4f\t%.1f\t%.4f\t%.0f%%\t", o.ETH, o.Deposit, o.Net, o.Slashed*100)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+	return w.Flush()
+}
@@ -26,15 +26,17 @@ import (
 
 	"github.com/shutter-network/shutter/shuttermint/app"
 	"github.com/shutter-network/shutter/shuttermint/sandbox"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
 
 var (
-	logger                 = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
-	rootDir                = ""
-	devMode                = false
-	index                  = 0
-	blockTime      float64 = 1.0
-	genesisKeypers         = []string{}
+	logger                  = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+	rootDir                 = ""
+	devMode                 = false
+	index                   = 0
+	blockTime       float64 = 1.0
+	genesisKeypers          = []string{}
+	messageWorkBits uint64
 )
 
 var initCmd = &cobra.Command{
@@ -50,6 +52,12 @@ func init() {
 	initCmd.PersistentFlags().IntVar(&index, "index", 0, "keyper index")
 	initCmd.PersistentFlags().Float64Var(&blockTime, "blocktime", 1.0, "block time in seconds")
 	initCmd.PersistentFlags().StringSliceVar(&genesisKeypers, "genesis-keyper", nil, "genesis keyper address")
+	initCmd.PersistentFlags().Uint64Var(
+		&messageWorkBits,
+		"message-work-bits",
+		0,
+		"proof of work in bits every message must carry as anti-spam fee, 0 to disable",
+	)
 	initCmd.MarkPersistentFlagRequired("root")
 }
 
@@ -68,6 +76,9 @@ func scaleToBlockTime(config *cfg.Config, blockTime float64) {
 }
 
 func initFiles(_ *cobra.Command, _ []string) error {
+	if messageWorkBits > shmsg.MaxMessageWorkBits {
+		return errors.Errorf("--message-work-bits must not exceed %d", shmsg.MaxMessageWorkBits)
+	}
 	keypers := []common.Address{}
 
 	for _, a := range genesisKeypers {
@@ -110,6 +121,7 @@ func initFiles(_ *cobra.Command, _ []string) error {
 	// let's overwrite it.
 	cfg.WriteConfigFile(filepath.Join(rootDir, "config", "config.toml"), config)
 	appState := app.NewGenesisAppState(keypers, (2*len(keypers)+2)/3)
+	appState.MessageWorkBits = messageWorkBits
 
 	return initFilesWithConfig(config, appState)
 }
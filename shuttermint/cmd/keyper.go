@@ -2,14 +2,17 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/shutter-network/shutter/shuttermint/cmd/shversion"
@@ -29,28 +32,80 @@ Shuttermint node which have to be started separately in advance.`,
 	},
 }
 
+// keyperConfigFlags maps config keys to the flags overriding them.
+var keyperConfigFlags = make(map[string]*pflag.Flag)
+
+// strictKeyperConfig makes unknown keys in the config file an error instead of a warning.
+var strictKeyperConfig bool
+
 func init() {
 	keyperCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file")
+	keyperCmd.PersistentFlags().BoolVar(
+		&strictKeyperConfig,
+		"strict-config",
+		false,
+		"reject unknown keys in the config file instead of ignoring them with a warning",
+	)
+
+	// Every config value except for the secret keys can be overridden with a flag. Secrets
+	// would be visible in the process list, so they can only be set in the config file or
+	// the environment.
+	for _, key := range keyper.ConfigKeys() {
+		if key.Secret {
+			continue
+		}
+		keyperCmd.PersistentFlags().String(
+			key.FlagName(),
+			"",
+			fmt.Sprintf("override %s from the config file and %s", key.Name, key.EnvName()),
+		)
+		keyperConfigFlags[key.Name] = keyperCmd.PersistentFlags().Lookup(key.FlagName())
+	}
+}
+
+// applyKeyperConfigFlags sets the config values given as flags. Values set with viper.Set take
+// precedence over the environment, the config file, and defaults.
+func applyKeyperConfigFlags() {
+	for _, key := range keyper.ConfigKeys() {
+		if flag, ok := keyperConfigFlags[key.Name]; ok && flag.Changed {
+			viper.Set(key.Name, flag.Value.String())
+		}
+	}
+}
+
+// keyperConfigSources returns where the value of each config key comes from.
+func keyperConfigSources() map[string]string {
+	sources := make(map[string]string)
+	profile := viper.GetString("Profile")
+	for _, key := range keyper.ConfigKeys() {
+		flag, hasFlag := keyperConfigFlags[key.Name]
+		_, isDefault := keyper.ConfigDefaults[key.Name]
+		_, isProfileDefault := keyper.ConfigProfiles[profile].Defaults[key.Name]
+		switch {
+		case hasFlag && flag.Changed:
+			sources[key.Name] = "flag --" + key.FlagName()
+		case os.Getenv(key.EnvName()) != "":
+			sources[key.Name] = "env " + key.EnvName()
+		case viper.InConfig(strings.ToLower(key.Name)):
+			sources[key.Name] = "file"
+		case isProfileDefault:
+			sources[key.Name] = "profile " + profile
+		case isDefault:
+			sources[key.Name] = "default"
+		default:
+			sources[key.Name] = "unset"
+		}
+	}
+	return sources
 }
 
 func readKeyperConfig() (keyper.Config, error) {
-	viper.SetEnvPrefix("KEYPER")
-	viper.BindEnv("ShuttermintURL")
-	viper.BindEnv("EthereumURL")
-	viper.BindEnv("SigningKey")
-	viper.BindEnv("ValidatorSeed")
-	viper.BindEnv("EncryptionKey")
-	viper.BindEnv("ConfigContract")
-	viper.BindEnv("BatcherContract")
-	viper.BindEnv("KeyBroadcastContract")
-	viper.BindEnv("ExecutorContract")
-	viper.BindEnv("DepositContract")
-	viper.BindEnv("KeyperSlasher")
-	viper.BindEnv("MainChainFollowDistance")
-	viper.BindEnv("ExecutionStaggering")
-	viper.BindEnv("DKGPhaseLength")
-
-	viper.SetDefault("ShuttermintURL", "http://localhost:26657")
+	viper.SetEnvPrefix(keyper.ConfigEnvPrefix)
+	for _, key := range keyper.ConfigKeys() {
+		viper.BindEnv(key.Name)
+	}
+	keyper.SetConfigDefaults(viper.GetViper())
+	applyKeyperConfigFlags()
 
 	defer func() {
 		if viper.ConfigFileUsed() != "" {
@@ -73,13 +128,26 @@ func readKeyperConfig() (keyper.Config, error) {
 			return config, err
 		}
 	} else if err != nil {
+		if path := viper.ConfigFileUsed(); path != "" {
+			if verr := keyper.ValidateConfigFile(path, strictKeyperConfig); verr != nil {
+				return config, verr
+			}
+		}
 		return config, err // Config file was found but another error was produced
+	} else if err := keyper.ValidateConfigFile(viper.ConfigFileUsed(), strictKeyperConfig); err != nil {
+		return config, err
+	}
+	if err := keyper.ApplyProfileDefaults(viper.GetViper()); err != nil {
+		return config, err
 	}
-	err = config.Unmarshal(viper.GetViper())
+	err = config.Unmarshal(viper.GetViper(), strictKeyperConfig)
 
 	if err != nil {
 		return config, err
 	}
+	if err := config.CheckProfile(); err != nil {
+		return config, err
+	}
 
 	if !filepath.IsAbs(config.DBDir) {
 		r := filepath.Dir(viper.ConfigFileUsed())
@@ -115,6 +183,7 @@ func keyperMain() error {
 		kc.EthereumURL,
 	)
 	kpr := keyper.NewKeyper(kc)
+	kpr.LoadConfig = readKeyperConfig
 	err = kpr.LoadState()
 	if err != nil {
 		return errors.WithMessage(err, "LoadState")
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+)
+
+var (
+	accessTokenAPI     string
+	accessTokenKeyper  string
+	accessTokenKeyFile string
+)
+
+var keyperAccessTokenCmd = &cobra.Command{
+	Use:   "access-token",
+	Short: "Print a token authenticating the keyper to closed keyper APIs",
+	Long: fmt.Sprintf(`This command prints a token signed with the keyper's signing key. Keypers
+with APIAccess set to "closed" serve restricted data, e.g. poly evals, to clients sending it in the
+Authorization header or gRPC metadata entry as "Shutter <token>". The keyper's admin API requires
+the token as well. Further admin accounts configured in the keyper's AdminAccounts sign their
+tokens with their own key given with --key-file.
+
+A token is only valid for the API given with --api of the keyper given with --keyper (our own
+one by default), it is accepted only once and for at most %s. Send it via TLS only.`,
+		access.MaxTokenAge),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if accessTokenAPI != access.EventStreamAPI && accessTokenAPI != access.AdminAPI {
+			return errors.Errorf("invalid API %q, must be %q or %q", accessTokenAPI, access.EventStreamAPI, access.AdminAPI)
+		}
+		var kc keyper.Config
+		if accessTokenKeyFile == "" || accessTokenKeyper == "" {
+			var err error
+			kc, err = readKeyperConfig()
+			if err != nil {
+				return err
+			}
+		}
+		key := kc.SigningKey
+		if accessTokenKeyFile != "" {
+			var err error
+			key, err = crypto.LoadECDSA(accessTokenKeyFile)
+			if err != nil {
+				return errors.Wrap(err, "failed to load signing key")
+			}
+		}
+		var keyperAddress common.Address
+		if accessTokenKeyper != "" {
+			if !common.IsHexAddress(accessTokenKeyper) {
+				return errors.Errorf("invalid keyper address %q", accessTokenKeyper)
+			}
+			keyperAddress = common.HexToAddress(accessTokenKeyper)
+		} else {
+			keyperAddress = kc.Address()
+		}
+		token, err := access.MakeToken(key, access.Audience(accessTokenAPI, keyperAddress), time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Println(token)
+		return nil
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperAccessTokenCmd)
+	keyperAccessTokenCmd.Flags().StringVar(
+		&accessTokenAPI,
+		"api",
+		access.EventStreamAPI,
+		fmt.Sprintf("API the token is meant for, %q or %q", access.EventStreamAPI, access.AdminAPI),
+	)
+	keyperAccessTokenCmd.Flags().StringVar(
+		&accessTokenKeyper,
+		"keyper",
+		"",
+		"address of the keyper serving the API, our own address if empty",
+	)
+	keyperAccessTokenCmd.Flags().StringVar(
+		&accessTokenKeyFile,
+		"key-file",
+		"",
+		"file containing the hex encoded key to sign the token with instead of the keyper's signing key",
+	)
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+)
+
+// adminGet fetches the given path from the keyper's admin API, authenticating with the keyper's
+// signing key.
+func adminGet(kc keyper.Config, path string) ([]byte, error) {
+	if kc.AdminListenAddress == "" {
+		return nil, errors.New("the admin API is disabled, set AdminListenAddress to enable it")
+	}
+	host, port, err := net.SplitHostPort(kc.AdminListenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	url := "http://" + net.JoinHostPort(host, port)
+	if kc.DeploymentID != "" {
+		url += "/" + kc.DeploymentID
+	}
+	url += path
+
+	token, err := access.MakeToken(kc.SigningKey, access.Audience(access.AdminAPI, kc.Address()), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", access.Authorization(token))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("admin API responded with %d: %s", res.StatusCode, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
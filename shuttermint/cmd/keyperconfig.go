@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+)
+
+var keyperConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the keyper configuration",
+}
+
+var printEffectiveCmd = &cobra.Command{
+	Use:   "print-effective",
+	Short: "Print the resolved keyper configuration",
+	Long: `This command reads and validates the keyper configuration the same way the keyper
+does and prints the resulting values, including defaults. Secret keys are redacted.
+
+Values are taken from the following sources, the first one that sets a value wins:
+
+  1. command line flags, e.g. --ethereum-url
+  2. environment variables, e.g. KEYPER_ETHEREUMURL
+  3. the config file
+  4. built-in defaults`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printEffectiveMain()
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperConfigCmd)
+	keyperConfigCmd.AddCommand(printEffectiveCmd)
+}
+
+func printEffectiveMain() error {
+	config, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	if path := viper.ConfigFileUsed(); path != "" {
+		fmt.Printf("# Effective keyper configuration read from %s\n", path)
+	} else {
+		fmt.Printf("# Effective keyper configuration\n")
+	}
+	fmt.Printf("# Precedence: flags > environment (%s_*) > config file > defaults\n", keyper.ConfigEnvPrefix)
+	for _, line := range config.EffectiveValues(keyperConfigSources()) {
+		fmt.Println(line)
+	}
+	return nil
+}
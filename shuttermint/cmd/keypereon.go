@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/sharebackup"
+)
+
+var keyperEonExportFlags struct {
+	Eon uint64
+	Out string
+}
+
+var keyperEonCmd = &cobra.Command{
+	Use:   "eon",
+	Short: "Move the keys generated for an eon between keyper installations or recover them",
+}
+
+var keyperEonExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the result of an eon's DKG to a file",
+	Long: `This command writes the result of the keyper's DKG for the given eon to a JSON file: the
+keyper set, the threshold, the eon public key and public key shares, the DKG transcript and the
+keyper's eon secret key share, encrypted to the keyper's EncryptionKey. The file does not depend
+on the format of the keyper's state, so it can be imported with "keyper eon import" by other
+versions of the keyper, e.g. when migrating to a version that can't read the old state.
+
+The export is verified before it's written. Stop the keyper first or point the config to a copy
+of its database directory. Keep the file as safe as the config, anyone with the EncryptionKey
+can decrypt the key share.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperEonExportMain()
+	},
+}
+
+var keyperEonImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import the result of an eon's DKG exported with \"keyper eon export\"",
+	Long: `This command checks the integrity of an export and adds the eon's key to the keyper's state
+in DBDir, creating a new state if there is none. The config must contain the SigningKey and
+EncryptionKey of the keyper the export has been made for. Besides a checksum, the eon secret key
+share is checked against the keyper's public key share and the public key shares against the
+DKG transcript.
+
+A different key already present for the eon is never replaced, and importing an eon whose DKG the
+keyper is still running is refused. The keyper must be stopped while importing.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperEonImportMain(args[0])
+	},
+}
+
+var keyperEonRecoverShareFlags struct {
+	KeyFiles []string
+}
+
+var keyperEonRecoverShareCmd = &cobra.Command{
+	Use:   "recover-share <backup>...",
+	Short: "Recover an eon secret key share from the backups exported to the custodians",
+	Long: `This command recovers a keyper's eon secret key share from the backups exported to the
+custodians configured in ShareBackupKeys. Each backup is decrypted with the custodian's private
+key, given in the files passed with --key-file like the keyper's SigningKey, and at least
+ShareBackupThreshold backups are required. Backups of custodians whose key is not given are
+skipped. The recovered share is checked against the keyper's public key share and printed in
+hex. It doesn't depend on the keyper's config, so it can be run on an offline machine.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperEonRecoverShareMain(args)
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperEonCmd)
+	keyperEonCmd.AddCommand(keyperEonExportCmd)
+	keyperEonCmd.AddCommand(keyperEonImportCmd)
+	keyperEonCmd.AddCommand(keyperEonRecoverShareCmd)
+	keyperEonRecoverShareCmd.Flags().StringArrayVar(
+		&keyperEonRecoverShareFlags.KeyFiles, "key-file", nil, "file with a custodian's private key, can be repeated")
+	keyperEonRecoverShareCmd.MarkFlagRequired("key-file")
+	keyperEonExportCmd.Flags().Uint64Var(&keyperEonExportFlags.Eon, "eon", 0, "eon to export")
+	keyperEonExportCmd.MarkFlagRequired("eon")
+	keyperEonExportCmd.Flags().StringVar(
+		&keyperEonExportFlags.Out, "out", "", "file to write the export to (default eon-<eon>.json)")
+}
+
+func keyperEonExportMain() error {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	x, err := keyper.ExportEon(kc, keyperEonExportFlags.Eon)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return err
+	}
+	out := keyperEonExportFlags.Out
+	if out == "" {
+		out = fmt.Sprintf("eon-%d.json", x.Eon)
+	}
+	if err := ioutil.WriteFile(out, data, 0o600); err != nil {
+		return err
+	}
+	fmt.Printf("Exported eon %d to %s\n", x.Eon, out)
+	return nil
+}
+
+func keyperEonImportMain(path string) error {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	x := &keyper.EonExport{}
+	if err := json.Unmarshal(data, x); err != nil {
+		return errors.Wrapf(err, "failed to decode eon export %s", path)
+	}
+	imported, err := keyper.ImportEon(kc, x)
+	if err != nil {
+		return err
+	}
+	if !imported {
+		fmt.Printf("The state in %s already contains the key for eon %d\n", kc.DBDir, x.Eon)
+		return nil
+	}
+	fmt.Printf("Imported eon %d into the state in %s\n", x.Eon, kc.DBDir)
+	return nil
+}
+
+func keyperEonRecoverShareMain(paths []string) error {
+	var keys []*ecdsa.PrivateKey
+	for _, path := range keyperEonRecoverShareFlags.KeyFiles {
+		key, err := crypto.LoadECDSA(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load custodian key %s", path)
+		}
+		keys = append(keys, key)
+	}
+	var backups []*sharebackup.Backup
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		b := &sharebackup.Backup{}
+		if err := json.Unmarshal(data, b); err != nil {
+			return errors.Wrapf(err, "failed to decode backup %s", path)
+		}
+		backups = append(backups, b)
+	}
+	share, err := sharebackup.Recover(backups, keys)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recovered the eon secret key share of keyper %s for eon %d:\n", backups[0].Keyper.Hex(), backups[0].Eon)
+	fmt.Printf("%x\n", common.BigToHash((*big.Int)(share)))
+	return nil
+}
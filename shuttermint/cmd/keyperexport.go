@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/eonstore"
+)
+
+var keyperExportFlags struct {
+	OutDir string
+}
+
+var keyperExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export protocol statistics from the eon archive as CSV files",
+	Long: `This command reads the eons archived in the keyper's database directory and writes two CSV
+files to the output directory:
+
+  eons.csv    one row per eon with the timing, participation and size of its DKG
+  epochs.csv  one row per epoch and namespace with the epoch secret key shares published for it
+
+Heights are Shuttermint block heights, sizes are in bytes. Only archived eons are exported, i.e.
+archiving must be enabled with ArchiveKeepEons. Main chain data like fees is not part of the
+archive. The database can only be opened by one process at a time, so stop the keyper first or
+point the config to a copy of its database directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperExportMain()
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperExportCmd)
+	keyperExportCmd.Flags().StringVar(&keyperExportFlags.OutDir, "out", ".", "directory to write the CSV files to")
+}
+
+var (
+	eonsHeader = []string{
+		"eon", "start_batch_index", "start_height", "end_height", "dealers", "poly_evals",
+		"accusations", "accused", "apologies", "dkg_bytes", "epochs", "key_share_bytes",
+	}
+	epochsHeader = []string{
+		"eon", "epoch", "namespace", "shares", "senders", "first_height", "last_height", "bytes",
+	}
+)
+
+func keyperExportMain() error {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	store, err := eonstore.Open(kc.DBDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	eons, err := store.Eons()
+	if err != nil {
+		return err
+	}
+
+	eonsFile, eonsWriter, err := createCSV(filepath.Join(keyperExportFlags.OutDir, "eons.csv"), eonsHeader)
+	if err != nil {
+		return err
+	}
+	defer eonsFile.Close()
+	epochsFile, epochsWriter, err := createCSV(filepath.Join(keyperExportFlags.OutDir, "epochs.csv"), epochsHeader)
+	if err != nil {
+		return err
+	}
+	defer epochsFile.Close()
+
+	u := func(n uint64) string { return strconv.FormatUint(n, 10) }
+	i := func(n int64) string { return strconv.FormatInt(n, 10) }
+	numEpochs := 0
+	for _, eon := range eons {
+		stats, epochs, err := store.Stats(eon)
+		if err != nil {
+			return err
+		}
+		err = eonsWriter.Write([]string{
+			u(stats.Eon), u(stats.StartBatchIndex), i(stats.StartHeight), i(stats.EndHeight),
+			strconv.Itoa(stats.Dealers), strconv.Itoa(stats.PolyEvals), strconv.Itoa(stats.Accusations),
+			strconv.Itoa(stats.Accused), strconv.Itoa(stats.Apologies), strconv.Itoa(stats.DKGBytes),
+			strconv.Itoa(stats.Epochs), strconv.Itoa(stats.KeyShareBytes),
+		})
+		if err != nil {
+			return err
+		}
+		for _, epoch := range epochs {
+			err = epochsWriter.Write([]string{
+				u(epoch.Eon), u(epoch.Epoch), epoch.Namespace, strconv.Itoa(epoch.Shares),
+				strconv.Itoa(epoch.Senders), i(epoch.FirstHeight), i(epoch.LastHeight), strconv.Itoa(epoch.Bytes),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		numEpochs += len(epochs)
+	}
+
+	for _, w := range []*csv.Writer{eonsWriter, epochsWriter} {
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	}
+	for _, f := range []*os.File{eonsFile, epochsFile} {
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Exported %d eons and %d epochs to %s\n", len(eons), numEpochs, keyperExportFlags.OutDir)
+	return nil
+}
+
+// createCSV creates a CSV file with the given header row.
+func createCSV(path string, header []string) (*os.File, *csv.Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write(header); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, w, nil
+}
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/deploy"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/medley"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// checkInSyncTimeout is how long keyper init waits for the Shuttermint node to sync before
+// giving up on sending the check-in message.
+const checkInSyncTimeout = 30 * time.Minute
+
+var keyperInitFlags struct {
+	Output        string
+	ContractsPath string
+}
+
+var keyperInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up a new keyper interactively",
+	Long: `This command walks through setting up a new keyper. It asks for the node URLs and the
+contract addresses, generates new keys or imports the signing key from an encrypted Ethereum
+keystore file, and writes a validated config file. Optionally, it deposits tokens in the deposit
+contract and sends the keyper's check-in message once the Shuttermint node is synced. Finally, it
+runs the checks of "shuttermint keyper doctor".
+
+Hardware wallets are not supported: the keyper signs transactions and Shuttermint messages all
+the time, so the signing key has to be available to it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+		return keyperInitMain(p)
+	},
+}
+
+func init() {
+	keyperInitCmd.Flags().StringVarP(
+		&keyperInitFlags.Output,
+		"output",
+		"o",
+		"$HOME/.config/shutter/keyper.toml",
+		"path of the config file to create",
+	)
+	keyperInitCmd.Flags().StringVarP(
+		&keyperInitFlags.ContractsPath,
+		"contracts",
+		"c",
+		"",
+		"path to a contracts.json file written by \"shuttermint deploy\" to take the contract addresses from",
+	)
+	keyperCmd.AddCommand(keyperInitCmd)
+}
+
+// prompter asks the user questions on the terminal.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask asks the given question and returns the answer, or def if the answer is empty.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", errors.Wrap(err, "failed to read answer")
+	}
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// askValid asks the given question until the answer passes validation.
+func (p *prompter) askValid(question, def string, validate func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		err = validate(answer)
+		if err == nil {
+			return answer, nil
+		}
+		fmt.Fprintf(p.out, "  %s\n", err)
+	}
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	answer, err := p.askValid(question+" ("+choices+")", "", func(s string) error {
+		switch strings.ToLower(s) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		}
+		return errors.New("please answer yes or no")
+	})
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return def, nil
+}
+
+// askAddress asks for a checksummed address.
+func (p *prompter) askAddress(question string) (common.Address, error) {
+	answer, err := p.askValid(question, "", func(s string) error {
+		if !common.IsHexAddress(s) || common.HexToAddress(s).Hex() != s {
+			return errors.Errorf("not a checksummed address: %s", s)
+		}
+		return nil
+	})
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.HexToAddress(answer), nil
+}
+
+func keyperInitMain(p *prompter) error {
+	path := os.ExpandEnv(keyperInitFlags.Output)
+	if _, err := os.Stat(path); err == nil {
+		return errors.Errorf("%s already exists, remove it or choose another path with --output", path)
+	}
+
+	profile, err := p.askValid(
+		fmt.Sprintf("Config profile (%s, or none)", strings.Join(keyper.ProfileNames(), ", ")),
+		"none",
+		func(s string) error {
+			if _, ok := keyper.ConfigProfiles[s]; !ok && s != "none" {
+				return errors.Errorf("unknown profile %s", s)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if profile == "none" {
+		profile = ""
+	}
+	config, err := newKeyperConfig(profile)
+	if err != nil {
+		return err
+	}
+	if err := askConnections(p, config); err != nil {
+		return err
+	}
+	if err := askContracts(p, config); err != nil {
+		return err
+	}
+	if err := askKeys(p, config); err != nil {
+		return err
+	}
+	if err := writeKeyperConfig(config, path); err != nil {
+		return err
+	}
+	fmt.Fprintf(p.out, "\nWrote config for keyper %s to %s\n\n", config.Address().Hex(), path)
+
+	ctx := context.Background()
+	deposit, err := p.confirm("Deposit tokens in the deposit contract now?", false)
+	if err != nil {
+		return err
+	}
+	if deposit {
+		if err := makeDeposit(ctx, p, config); err != nil {
+			return err
+		}
+	}
+	checkIn, err := p.confirm("Send the check-in message once the Shuttermint node is synced?", false)
+	if err != nil {
+		return err
+	}
+	if checkIn {
+		if err := sendCheckIn(ctx, p, config); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(p.out, "\nChecking the keyper's readiness\n\n")
+	cfgFile = path
+	return doctorMain()
+}
+
+// newKeyperConfig creates a config with the default values of the given profile.
+func newKeyperConfig(profile string) (*keyper.Config, error) {
+	v := viper.New()
+	keyper.SetConfigDefaults(v)
+	v.Set("Profile", profile)
+	if err := keyper.ApplyProfileDefaults(v); err != nil {
+		return nil, err
+	}
+	config := &keyper.Config{}
+	if err := config.Unmarshal(v, false); err != nil {
+		return nil, err
+	}
+	config.DKGPhaseLength = 30
+	config.ExecutionStaggering = 5
+	config.OracleSubmissionStaggering = 5
+	config.GasPriceMultiplier = 1.5
+	return config, nil
+}
+
+func askConnections(p *prompter, config *keyper.Config) error {
+	var err error
+	config.EthereumURL, err = p.askValid("Ethereum websocket URL", "ws://localhost:8545", func(s string) error {
+		if !keyper.IsWebsocketURL(s) {
+			return errors.New("the URL must start with ws:// or wss://")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	config.ShuttermintURL, err = p.ask("Shuttermint RPC URL", config.ShuttermintURL)
+	if err != nil {
+		return err
+	}
+	config.DBDir, err = p.ask("Database directory, relative to the config file", "db")
+	return err
+}
+
+func askContracts(p *prompter, config *keyper.Config) error {
+	if keyperInitFlags.ContractsPath != "" {
+		contracts, err := deploy.LoadContractsJSON(keyperInitFlags.ContractsPath)
+		if err != nil {
+			return err
+		}
+		config.ConfigContractAddress = contracts.ConfigContract
+		config.BatcherContractAddress = contracts.BatcherContract
+		config.KeyBroadcastContractAddress = contracts.KeyBroadcastContract
+		config.ExecutorContractAddress = contracts.ExecutorContract
+		config.DepositContractAddress = contracts.DepositContract
+		config.KeyperSlasherAddress = contracts.KeyperSlasherContract
+		return nil
+	}
+
+	addresses := []struct {
+		name string
+		addr *common.Address
+	}{
+		{"ConfigContract", &config.ConfigContractAddress},
+		{"BatcherContract", &config.BatcherContractAddress},
+		{"KeyBroadcastContract", &config.KeyBroadcastContractAddress},
+		{"ExecutorContract", &config.ExecutorContractAddress},
+		{"DepositContract", &config.DepositContractAddress},
+		{"KeyperSlasher", &config.KeyperSlasherAddress},
+	}
+	for _, a := range addresses {
+		addr, err := p.askAddress(a.name + " address")
+		if err != nil {
+			return err
+		}
+		*a.addr = addr
+	}
+	return nil
+}
+
+// askKeys generates new keys and optionally replaces the signing key with one from a keystore
+// file.
+func askKeys(p *prompter, config *keyper.Config) error {
+	if err := config.GenerateNewKeys(); err != nil {
+		return err
+	}
+	generate, err := p.confirm("Generate a new signing key?", true)
+	if err != nil || generate {
+		return err
+	}
+	for {
+		keyPath, err := p.ask("Path to the keystore file with the signing key", "")
+		if err != nil {
+			return err
+		}
+		passphrase, err := p.ask("Passphrase of the keystore file (will be shown)", "")
+		if err != nil {
+			return err
+		}
+		key, err := readKeystore(keyPath, passphrase)
+		if err == nil {
+			config.SigningKey = key
+			return nil
+		}
+		fmt.Fprintf(p.out, "  %s\n", err)
+	}
+}
+
+func readKeystore(path, passphrase string) (*ecdsa.PrivateKey, error) {
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(d, passphrase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt %s", path)
+	}
+	return key.PrivateKey, nil
+}
+
+// writeKeyperConfig writes the config file and checks that it can be read.
+func writeKeyperConfig(config *keyper.Config, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create config directory")
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create keyper config file")
+	}
+	defer file.Close()
+	if err := config.WriteTOML(file); err != nil {
+		return errors.Wrap(err, "failed to write keyper config file")
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+
+	if err := keyper.ValidateConfigFile(path, true); err != nil {
+		return err
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+	return (&keyper.Config{}).Unmarshal(v, true)
+}
+
+// makeDeposit sends deposit tokens from the keyper's account to the deposit contract.
+func makeDeposit(ctx context.Context, p *prompter, config *keyper.Config) error {
+	ethcl, err := ethclient.DialContext(ctx, config.EthereumURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to Ethereum node at %s", config.EthereumURL)
+	}
+	defer ethcl.Close()
+	callOpts := &bind.CallOpts{Context: ctx}
+	depositContract, err := contract.NewDepositContract(config.DepositContractAddress, ethcl)
+	if err != nil {
+		return err
+	}
+	tokenAddress, err := depositContract.Token(callOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to query deposit token")
+	}
+	token, err := contract.NewIERC777(tokenAddress, ethcl)
+	if err != nil {
+		return err
+	}
+	balance, err := token.BalanceOf(callOpts, config.Address())
+	if err != nil {
+		return errors.Wrap(err, "failed to query token balance")
+	}
+	if balance.Sign() == 0 {
+		fmt.Fprintf(p.out, "Account %s holds no deposit tokens, skipping the deposit\n", config.Address().Hex())
+		return nil
+	}
+
+	var amount *big.Int
+	_, err = p.askValid("Amount to deposit, in the token's smallest unit", balance.String(), func(s string) error {
+		a, ok := new(big.Int).SetString(s, 10)
+		if !ok || a.Sign() <= 0 || a.Cmp(balance) > 0 {
+			return errors.Errorf("the amount must be between 1 and %s", balance)
+		}
+		amount = a
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var delay uint64
+	_, err = p.askValid("Withdrawal delay in main chain blocks", "", func(s string) error {
+		var perr error
+		delay, perr = strconv.ParseUint(s, 10, 64)
+		return perr
+	})
+	if err != nil {
+		return err
+	}
+
+	chainID, err := ethcl.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to query chain id")
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(config.SigningKey, chainID)
+	if err != nil {
+		return err
+	}
+	auth.Context = ctx
+	userData := common.LeftPadBytes(new(big.Int).SetUint64(delay).Bytes(), 32)
+	tx, err := token.Send(auth, config.DepositContractAddress, amount, userData)
+	if err != nil {
+		return errors.Wrap(err, "failed to send deposit transaction")
+	}
+	fmt.Fprintf(p.out, "Sent deposit transaction %s, waiting for it to be mined\n", tx.Hash().Hex())
+	receipt, err := medley.WaitMined(ctx, ethcl, tx.Hash())
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.Errorf("deposit transaction %s failed", tx.Hash().Hex())
+	}
+	fmt.Fprintf(p.out, "Deposited %s tokens\n", amount)
+	return nil
+}
+
+// sendCheckIn waits for the Shuttermint node to sync and sends the check-in message. The keyper
+// would send it on startup as well, but doing it now shows early whether the keyper is part of
+// the keyper set.
+func sendCheckIn(ctx context.Context, p *prompter, config *keyper.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, checkInSyncTimeout)
+	defer cancel()
+	shmcl, err := http.New(config.ShuttermintURL, "/websocket")
+	if err != nil {
+		return err
+	}
+	for {
+		status, err := shmcl.Status(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to query status of Shuttermint node at %s", config.ShuttermintURL)
+		}
+		if !status.SyncInfo.CatchingUp {
+			break
+		}
+		fmt.Fprintf(p.out, "Shuttermint node is catching up at height %d\n", status.SyncInfo.LatestBlockHeight)
+		medley.Sleep(ctx, 10*time.Second)
+	}
+
+	ms := fx.NewRPCMessageSender(shmcl, config.SigningKey)
+	validatorPublicKey := config.ValidatorKey.Public().(ed25519.PublicKey)
+	msg := shmsg.NewCheckIn([]byte(validatorPublicKey), &config.EncryptionKey.PublicKey)
+	if err := ms.SendMessage(ctx, msg); err != nil {
+		return errors.Wrap(err, "failed to send check-in message")
+	}
+	fmt.Fprintf(p.out, "Checked in\n")
+	return nil
+}
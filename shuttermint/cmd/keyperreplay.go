@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+)
+
+var keyperReplayFlags struct {
+	FromHeight int64
+	ToHeight   int64
+	State      string
+	Bundle     string
+	Verbose    bool
+}
+
+var keyperReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-run the keyper's decisions against recorded chain data",
+	Long: `This command reconstructs the observed state of the Shuttermint chain and the main chain
+block by block and runs the keyper's decider for every Shuttermint block, without executing any
+actions. It prints the actions decided in the given range of Shuttermint blocks and compares the
+Shuttermint messages among them with the ones the keyper actually sent in that range.
+
+Messages are usually sent a block or two after they have been decided on, so differences at the
+ends of the range are expected. Replaying requires an archive node for the main chain. Unless
+--state is given, the replay starts from genesis with fresh secrets, so the DKG will not match
+what happened if the keyper took part in it; pass a copy of the keyper's state.gob from before the
+incident instead. A support bundle (see "keyper support-bundle") can be passed with --bundle, but
+lacks the keyper's secrets, so the DKG messages and epoch secret key shares are not reproduced.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperReplayMain()
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperReplayCmd)
+	keyperReplayCmd.Flags().Int64Var(&keyperReplayFlags.FromHeight, "from-height", 1, "first Shuttermint block to report")
+	keyperReplayCmd.Flags().Int64Var(&keyperReplayFlags.ToHeight, "to-height", 0, "last Shuttermint block to replay")
+	keyperReplayCmd.Flags().StringVar(&keyperReplayFlags.State, "state", "", "state.gob snapshot to start from")
+	keyperReplayCmd.Flags().StringVar(&keyperReplayFlags.Bundle, "bundle", "", "support bundle to start from")
+	keyperReplayCmd.Flags().BoolVar(&keyperReplayFlags.Verbose, "verbose", false, "print blocks without actions as well")
+	keyperReplayCmd.MarkFlagRequired("to-height")
+}
+
+func keyperReplayMain() error {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	shmcl, err := http.New(kc.ShuttermintURL, "/websocket")
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to %s", kc.ShuttermintURL)
+	}
+	caller, err := keyper.NewContractCallerFromConfig(kc)
+	if err != nil {
+		return err
+	}
+
+	replayer := keyper.NewReplayer(kc, shmcl, &caller)
+	switch {
+	case keyperReplayFlags.State != "" && keyperReplayFlags.Bundle != "":
+		return errors.New("--state and --bundle are mutually exclusive")
+	case keyperReplayFlags.State != "":
+		if err := replayer.LoadSnapshot(keyperReplayFlags.State); err != nil {
+			return err
+		}
+	case keyperReplayFlags.Bundle != "":
+		if err := replayer.LoadSupportBundle(keyperReplayFlags.Bundle); err != nil {
+			return err
+		}
+	}
+	diff, err := replayer.Replay(
+		context.Background(),
+		keyperReplayFlags.FromHeight,
+		keyperReplayFlags.ToHeight,
+		func(step keyper.ReplayStep) {
+			if len(step.Actions) == 0 && !keyperReplayFlags.Verbose {
+				return
+			}
+			fmt.Printf("block %d (main chain %d): %d actions\n", step.Height, step.MainChainBlock, len(step.Actions))
+			for _, a := range step.Actions {
+				fmt.Printf("    %s\n", a)
+			}
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(diff.Missing) == 0 && len(diff.Unexpected) == 0 {
+		fmt.Println("The replay produced the same Shuttermint messages as the keyper sent")
+		return nil
+	}
+	for _, k := range diff.Missing {
+		fmt.Printf("- %s (sent, but not produced in replay)\n", k)
+	}
+	for _, k := range diff.Unexpected {
+		fmt.Printf("+ %s (produced in replay, but not sent)\n", k)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+)
+
+var keyperSnapshotDiffCmd = &cobra.Command{
+	Use:   "snapshot-diff SNAPSHOT [OTHER-SNAPSHOT]",
+	Short: "Compare the observed state of two state snapshots",
+	Long: `This command compares the observed state of the Shuttermint chain and the main chain in two
+snapshots field by field and prints the discrepancies per eon and batch. It is meant for debugging
+keypers that make different decisions because their views of the chains diverged.
+
+A snapshot is either a copy of a keyper's state.gob or a support bundle (see "keyper
+support-bundle"). Use support bundles to compare keypers of different operators, state.gob
+contains the keyper's secrets. Events and other items only present in the first snapshot are
+prefixed with "-", the ones only present in the second one with "+".
+
+If only one snapshot is given, it is compared with the state reconstructed from the chains at the
+heights of the snapshot. This requires the keyper config and an archive node for the main chain.
+
+Snapshots taken at different heights differ in the events between these heights, so compare
+snapshots taken at the same heights if possible.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperSnapshotDiffMain(args)
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperSnapshotDiffCmd)
+}
+
+func keyperSnapshotDiffMain(args []string) error {
+	a, err := keyper.ReadObservedSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	var b keyper.ObservedSnapshot
+	nameB := "reconstructed"
+	if len(args) == 2 {
+		nameB = args[1]
+		b, err = keyper.ReadObservedSnapshot(args[1])
+		if err != nil {
+			return err
+		}
+	} else {
+		b, err = reconstructObservedSnapshot(a)
+		if err != nil {
+			return err
+		}
+	}
+
+	printSnapshotHeights := func(name string, s keyper.ObservedSnapshot) {
+		fmt.Printf("%s: shuttermint height %d, main chain block %d\n",
+			name, s.Shutter.CurrentBlock, s.MainChain.CurrentBlock)
+	}
+	printSnapshotHeights("- "+args[0], a)
+	printSnapshotHeights("+ "+nameB, b)
+	if a.Shutter.CurrentBlock != b.Shutter.CurrentBlock || a.MainChain.CurrentBlock != b.MainChain.CurrentBlock {
+		fmt.Println("Warning: the snapshots have been taken at different heights")
+	}
+
+	diff := keyper.DiffObservedSnapshots(a, b)
+	if len(diff) == 0 {
+		fmt.Println("The observed states are the same")
+		return nil
+	}
+	for _, section := range diff {
+		fmt.Printf("\n%s:\n", section.Name)
+		for _, line := range section.Lines {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	return nil
+}
+
+func reconstructObservedSnapshot(like keyper.ObservedSnapshot) (keyper.ObservedSnapshot, error) {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return keyper.ObservedSnapshot{}, errors.WithMessage(err, "Please check your configuration")
+	}
+	shmcl, err := http.New(kc.ShuttermintURL, "/websocket")
+	if err != nil {
+		return keyper.ObservedSnapshot{}, errors.Wrapf(err, "failed to connect to %s", kc.ShuttermintURL)
+	}
+	caller, err := keyper.NewContractCallerFromConfig(kc)
+	if err != nil {
+		return keyper.ObservedSnapshot{}, err
+	}
+	return keyper.ReconstructObservedSnapshot(context.Background(), shmcl, &caller, like)
+}
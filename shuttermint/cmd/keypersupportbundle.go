@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/shversion"
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+)
+
+var keyperSupportBundleFlags struct {
+	Out      string
+	Log      string
+	LogLines int
+	Traces   int
+}
+
+var keyperSupportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Write an archive describing the keyper for attaching to bug reports",
+	Long: `This command writes a gzipped tar archive with the following files:
+
+  version.json  the version of this binary
+  config.toml   the effective configuration, see "keyper config print-effective"
+  state.json    the keyper's state file as canonical JSON
+  state.gob     the same state for "keyper replay --bundle"
+  traces.json   recent decision traces fetched from the admin API, if it is enabled and running
+  keyper.log    the last lines of the log file given with --log
+
+Secrets are removed: the secret keys are redacted from the config and the log, and the secrets of
+the DKGs and EKGs, e.g. our polynomials, poly evals and eon secret key shares, are removed from the
+state. Replaying from a bundle therefore doesn't reproduce the messages requiring them. Check the
+archive before sharing it nevertheless, it contains e.g. the keyper's addresses and node URLs.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperSupportBundleMain()
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperSupportBundleCmd)
+	keyperSupportBundleCmd.Flags().StringVar(
+		&keyperSupportBundleFlags.Out, "out", "keyper-support-bundle.tar.gz", "file to write the bundle to")
+	keyperSupportBundleCmd.Flags().StringVar(
+		&keyperSupportBundleFlags.Log, "log", "", "keyper log file to include the end of")
+	keyperSupportBundleCmd.Flags().IntVar(
+		&keyperSupportBundleFlags.LogLines, "log-lines", 5000, "number of log lines to include")
+	keyperSupportBundleCmd.Flags().IntVar(
+		&keyperSupportBundleFlags.Traces, "traces", 100, "number of decision traces to include, 0 for none")
+}
+
+func keyperSupportBundleMain() error {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	bundle, err := keyper.NewSupportBundle(kc, keyperConfigSources())
+	if err != nil {
+		return err
+	}
+	bundle.Version, err = json.MarshalIndent(shversion.GetInfo(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if keyperSupportBundleFlags.Log != "" {
+		data, err := ioutil.ReadFile(keyperSupportBundleFlags.Log)
+		if err != nil {
+			return errors.Wrap(err, "failed to read log file")
+		}
+		bundle.Log = kc.Redact(lastLines(data, keyperSupportBundleFlags.LogLines))
+	}
+	if kc.AdminListenAddress != "" && keyperSupportBundleFlags.Traces > 0 {
+		bundle.Traces, err = fetchTraces(kc, keyperSupportBundleFlags.Traces)
+		if err != nil {
+			log.Printf("Warning: not including decision traces: %s", err)
+		}
+	}
+
+	file, err := os.Create(keyperSupportBundleFlags.Out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := bundle.Write(file); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote support bundle to %s\n", keyperSupportBundleFlags.Out)
+	return nil
+}
+
+// lastLines returns the last n lines of data.
+func lastLines(data []byte, n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	start := end
+	for i := 0; i < n; i++ {
+		j := bytes.LastIndexByte(data[:start], '\n')
+		if j < 0 {
+			return data
+		}
+		start = j
+	}
+	return data[start+1:]
+}
+
+// fetchTraces fetches the last n decision traces from the keyper's admin API.
+func fetchTraces(kc keyper.Config, n int) ([]byte, error) {
+	return adminGet(kc, fmt.Sprintf("/trace?n=%d", n))
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/top"
+)
+
+// ANSI escape sequences to draw on the alternate screen, so that the terminal's content is
+// restored when we're done
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+var keyperTopFlags struct {
+	Interval time.Duration
+	Once     bool
+}
+
+var keyperTopCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Monitor the running keyper",
+	Long: `This command shows what the running keyper is doing and refreshes the screen until it's
+interrupted: how far it has synced the chains, the eons it has keys for and the DKGs in progress,
+the shuttermint messages and main chain transactions waiting to be sent or mined, recent errors and
+the key release latencies.
+
+The data is fetched from the keyper's admin API, which must be enabled with AdminListenAddress.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return keyperTopMain()
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperTopCmd)
+	keyperTopCmd.Flags().DurationVar(
+		&keyperTopFlags.Interval, "interval", time.Second, "time between refreshes")
+	keyperTopCmd.Flags().BoolVar(
+		&keyperTopFlags.Once, "once", false, "print the status once instead of refreshing the screen")
+}
+
+func fetchStatus(kc keyper.Config) (keyper.Status, error) {
+	var status keyper.Status
+	body, err := adminGet(kc, "/status")
+	if err != nil {
+		return status, err
+	}
+	err = json.Unmarshal(body, &status)
+	return status, err
+}
+
+func keyperTopMain() error {
+	kc, err := readKeyperConfig()
+	if err != nil {
+		return errors.WithMessage(err, "Please check your configuration")
+	}
+	if keyperTopFlags.Interval <= 0 {
+		return errors.New("--interval must be positive")
+	}
+	if keyperTopFlags.Once {
+		status, err := fetchStatus(kc)
+		if err != nil {
+			return err
+		}
+		return top.Render(os.Stdout, status, time.Now())
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	fmt.Print(enterAltScreen)
+	defer fmt.Print(exitAltScreen)
+
+	ticker := time.NewTicker(keyperTopFlags.Interval)
+	defer ticker.Stop()
+	for {
+		// Render into a buffer first, so that the screen isn't blank while fetching
+		buf := new(bytes.Buffer)
+		buf.WriteString(clearScreen)
+		status, err := fetchStatus(kc)
+		if err != nil {
+			fmt.Fprintf(buf, "keyper top - %s\n\nfailed to fetch the status: %s\n", time.Now().Format("15:04:05"), err)
+		} else if err := top.Render(buf, status, time.Now()); err != nil {
+			return err
+		}
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/shversion"
+)
+
+var keyperVersionFlags struct {
+	JSON bool
+}
+
+var keyperVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the keyper's version",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !keyperVersionFlags.JSON {
+			fmt.Println(shversion.Version())
+			return nil
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(shversion.GetInfo())
+	},
+}
+
+func init() {
+	keyperCmd.AddCommand(keyperVersionCmd)
+	keyperVersionCmd.Flags().BoolVar(&keyperVersionFlags.JSON, "json", false, "print version information as JSON")
+}
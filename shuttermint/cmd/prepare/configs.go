@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -93,8 +94,9 @@ func validateConfigFlags() error {
 	return nil
 }
 
-func generateConfigJSON(keypers []common.Address) error {
-	cfg := contract.BatchConfig{
+// NewBatchConfig returns the batch config for a test run with the given keypers.
+func NewBatchConfig(keypers []common.Address, contracts *deploy.Contracts) contract.BatchConfig {
+	return contract.BatchConfig{
 		StartBatchIndex:        0,
 		StartBlockNumber:       0,
 		Keypers:                keypers,
@@ -104,11 +106,14 @@ func generateConfigJSON(keypers []common.Address) error {
 		TransactionSizeLimit:   1000,
 		TransactionGasLimit:    10000,
 		FeeReceiver:            common.HexToAddress("0x1111111111111111111111111111111111111111"),
-		TargetAddress:          contractsJSON.TargetContract,
+		TargetAddress:          contracts.TargetContract,
 		TargetFunctionSelector: [4]byte{0x94, 0x3d, 0x72, 0x09},
 		ExecutionTimeout:       15,
 	}
+}
 
+func generateConfigJSON(keypers []common.Address) error {
+	cfg := NewBatchConfig(keypers, &contractsJSON)
 	j, err := json.MarshalIndent(cfg, "", "    ")
 	if err != nil {
 		return errors.Wrap(err, "marshal json")
@@ -136,12 +141,12 @@ func generateConfigJSON(keypers []common.Address) error {
 func configs() error {
 	keypers := []common.Address{}
 	for i := 0; i < configFlags.NumKeypers; i++ {
-		config, err := newConfig()
+		config, err := NewKeyperConfig(&contractsJSON, configFlags.EthereumURL, configFlags.ShuttermintURL)
 		if err != nil {
 			return err
 		}
 		dir := filepath.Join(configFlags.Dir, "keyper"+strconv.Itoa(i))
-		err = saveConfig(config, dir)
+		err = SaveKeyperConfig(config, dir)
 		if err != nil {
 			return err
 		}
@@ -150,21 +155,57 @@ func configs() error {
 	return generateConfigJSON(keypers)
 }
 
-func newConfig() (*keyper.Config, error) {
+// NewKeyperConfig returns the config of a keyper for a test run with newly generated keys.
+func NewKeyperConfig(contracts *deploy.Contracts, ethereumURL, shuttermintURL string) (*keyper.Config, error) {
 	config := keyper.Config{
-		ShuttermintURL:              configFlags.ShuttermintURL,
-		EthereumURL:                 configFlags.EthereumURL,
+		Profile:                     "dev",
+		ShuttermintURL:              shuttermintURL,
+		EthereumURL:                 ethereumURL,
 		DBDir:                       "",
-		ConfigContractAddress:       contractsJSON.ConfigContract,
-		BatcherContractAddress:      contractsJSON.BatcherContract,
-		KeyBroadcastContractAddress: contractsJSON.KeyBroadcastContract,
-		ExecutorContractAddress:     contractsJSON.ExecutorContract,
-		DepositContractAddress:      contractsJSON.DepositContract,
-		KeyperSlasherAddress:        contractsJSON.KeyperSlasherContract,
+		ConfigContractAddress:       contracts.ConfigContract,
+		BatcherContractAddress:      contracts.BatcherContract,
+		KeyBroadcastContractAddress: contracts.KeyBroadcastContract,
+		ExecutorContractAddress:     contracts.ExecutorContract,
+		DepositContractAddress:      contracts.DepositContract,
+		KeyperSlasherAddress:        contracts.KeyperSlasherContract,
 		MainChainFollowDistance:     0,
 		ExecutionStaggering:         5,
+		OracleSubmissionStaggering:  5,
 		DKGPhaseLength:              30,
 		GasPriceMultiplier:          1.5,
+		DuplicateCiphertexts:        "allow",
+		CiphertextReplayWindow:      100,
+		TransactionFormat:           "raw",
+		ContractCacheTTL:            12 * time.Second,
+		ABIDriftCheckInterval:       10 * time.Minute,
+		RPCCacheSize:                1000,
+		ActionTimeout:               time.Minute,
+		ReplaceTXsAfter:             3 * time.Minute,
+		MaxTXReplacements:           5,
+		MessageDeliveryBlocks:       10,
+		MessageMaxResends:           3,
+		ThrottleReleaseBlocks:       5,
+		SyncTolerance:               5,
+		MaxBlocksBehind:             20,
+		ArchiveKeepEons:             10,
+		StateRetentionEons:          10,
+		KeepBatches:                 1000,
+		AppealTimeout:               100,
+		AuthorizationTimeout:        20,
+		ShuttermintHaltTimeout:      time.Minute,
+		CapabilityInterval:          10000,
+		ApologyDeadlineMargin:       10,
+		EncryptionKeyDeadlineMargin: 10,
+		DecisionTraceSize:           100,
+		APIAccess:                   "open",
+		ValidatorWatchWindow:        100,
+		ValidatorMaxMissedBlocks:    5,
+		MaxClockSkew:                2 * time.Second,
+		StandbyFailoverDelay:        time.Minute,
+		RelayerQuotaWindow:          time.Hour,
+		RelayerSenderQuota:          10,
+		RelayerTotalQuota:           1000,
+		RelayerMaxSize:              10000,
 	}
 	err := config.GenerateNewKeys()
 	if err != nil {
@@ -173,7 +214,9 @@ func newConfig() (*keyper.Config, error) {
 	return &config, nil
 }
 
-func saveConfig(c *keyper.Config, dir string) error {
+// SaveKeyperConfig writes the keyper config to config.toml in the given directory, which is
+// created if necessary. Existing configs are not overwritten.
+func SaveKeyperConfig(c *keyper.Config, dir string) error {
 	var err error
 	if err = os.MkdirAll(dir, 0o755); err != nil {
 		return errors.Wrap(err, "failed to create keyper directory")
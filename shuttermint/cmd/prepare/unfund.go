@@ -153,7 +153,7 @@ func readKeyFromConfig(path string) (*ecdsa.PrivateKey, error) {
 		return nil, err
 	}
 	config := keyper.Config{}
-	err = config.Unmarshal(v)
+	err = config.Unmarshal(v, false)
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/shutter-network/shutter/shuttermint/cmd/deploy"
 	"github.com/shutter-network/shutter/shuttermint/cmd/prepare"
 	"github.com/shutter-network/shutter/shuttermint/cmd/shversion"
+	"github.com/shutter-network/shutter/shuttermint/cmd/testnet"
 	"github.com/shutter-network/shutter/shuttermint/medley"
 )
 
@@ -74,4 +75,6 @@ func init() {
 	rootCmd.AddCommand(deploy.DeployCmd)
 	rootCmd.AddCommand(prepare.PrepareCmd)
 	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(econsimCmd)
+	rootCmd.AddCommand(testnet.TestnetCmd)
 }
@@ -8,19 +8,25 @@ import (
 	"github.com/tendermint/tendermint/rpc/client"
 	"github.com/tendermint/tendermint/rpc/client/http"
 
+	"github.com/shutter-network/shutter/shuttermint/keyper/eonstore"
 	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
 )
 
 var showFlags struct {
 	ShuttermintURL string
 	Height         int64
+	ArchiveDir     string
+	KeepEons       int
 }
 
 var showCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show the internal state of a Shuttermint node",
 	Long: `This command queries transactions from a running shuttermint node and rebuilds the
-internal shutter state object according to the results. It then prints the result to stdout.`,
+internal shutter state object according to the results. It then prints the result to stdout.
+
+With --archive-dir, the events of all but the most recent eons are moved to an eon store on disk
+instead of being kept in memory and printed.`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		showMain()
@@ -42,9 +48,23 @@ func init() {
 		-1,
 		"target height",
 	)
+	showCmd.PersistentFlags().StringVarP(
+		&showFlags.ArchiveDir,
+		"archive-dir",
+		"",
+		"",
+		"move the events of historical eons to an eon store in this directory",
+	)
+	showCmd.PersistentFlags().IntVarP(
+		&showFlags.KeepEons,
+		"keep-eons",
+		"",
+		2,
+		"number of most recent eons not to archive",
+	)
 }
 
-func showShutter(shuttermintURL string, height int64) {
+func showShutter(shuttermintURL string, height int64, archiveDir string, keepEons int) {
 	var cl client.Client
 	cl, err := http.New(shuttermintURL, "/websocket")
 	if err != nil {
@@ -63,9 +83,20 @@ func showShutter(shuttermintURL string, height int64) {
 	if err != nil {
 		panic(err)
 	}
+	if archiveDir != "" {
+		store, err := eonstore.Open(archiveDir)
+		if err != nil {
+			panic(err)
+		}
+		defer store.Close()
+		s, err = s.ArchiveEons(store, keepEons)
+		if err != nil {
+			panic(err)
+		}
+	}
 	pretty.Println("Synced:", s)
 }
 
 func showMain() {
-	showShutter(showFlags.ShuttermintURL, showFlags.Height)
+	showShutter(showFlags.ShuttermintURL, showFlags.Height, showFlags.ArchiveDir, showFlags.KeepEons)
 }
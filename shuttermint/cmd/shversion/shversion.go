@@ -1,5 +1,5 @@
 // Package shversion contains version information being set via linker flags when building via the
-// Makefile
+// Makefile or the release package
 package shversion
 
 import (
@@ -7,7 +7,35 @@ import (
 	"runtime"
 )
 
-var version string = "(unknown)"
+var (
+	version   string = "(unknown)"
+	commit    string = ""
+	buildDate string = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version      string `json:"version"`
+	Commit       string `json:"commit,omitempty"`
+	BuildDate    string `json:"buildDate,omitempty"`
+	GoVersion    string `json:"goVersion"`
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	RaceDetector bool   `json:"raceDetector"`
+}
+
+// GetInfo returns the version information of the running binary.
+func GetInfo() Info {
+	return Info{
+		Version:      version,
+		Commit:       commit,
+		BuildDate:    buildDate,
+		GoVersion:    runtime.Version(),
+		OS:           runtime.GOOS,
+		Arch:         runtime.GOARCH,
+		RaceDetector: raceDetectorEnabled,
+	}
+}
 
 // Version returns shuttermint's version string.
 func Version() string {
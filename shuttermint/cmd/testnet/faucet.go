@@ -0,0 +1,95 @@
+package testnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+)
+
+// faucetAmount is the amount of ether the faucet sends per request.
+var faucetAmount = big.NewInt(params.Ether)
+
+// faucet sends ether from the owner account to whoever asks for it with
+//
+//	curl -X POST 'http://localhost:8090/fund?address=0x...'
+type faucet struct {
+	client *ethclient.Client
+	key    *ecdsa.PrivateKey
+
+	mux sync.Mutex // serializes the transactions, they share the nonce
+}
+
+func (f *faucet) send(ctx context.Context, receiver common.Address) (*types.Transaction, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	chainID, err := f.client.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query chain id")
+	}
+	nonce, err := f.client.PendingNonceAt(ctx, crypto.PubkeyToAddress(f.key.PublicKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query nonce")
+	}
+	gasPrice, err := f.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query gas price")
+	}
+	unsignedTx := types.NewTransaction(nonce, receiver, faucetAmount, 21000, gasPrice, []byte{})
+	tx, err := types.SignTx(unsignedTx, types.NewEIP155Signer(chainID), f.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign transaction")
+	}
+	if err := f.client.SendTransaction(ctx, tx); err != nil {
+		return nil, errors.Wrap(err, "failed to send transaction")
+	}
+	return tx, nil
+}
+
+func (f *faucet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	address := r.URL.Query().Get("address")
+	if !common.IsHexAddress(address) {
+		http.Error(w, "invalid or missing address", http.StatusBadRequest)
+		return
+	}
+	receiver := common.HexToAddress(address)
+	tx, err := f.send(r.Context(), receiver)
+	if err != nil {
+		log.Printf("Error: faucet failed to fund %s: %s", receiver.Hex(), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Faucet sent %s wei to %s in tx %s", faucetAmount, receiver.Hex(), tx.Hash().Hex())
+	fmt.Fprintln(w, tx.Hash().Hex())
+}
+
+// serveFaucet runs the faucet until the context is canceled.
+func serveFaucet(ctx context.Context, listenAddress string, f *faucet) error {
+	mux := http.NewServeMux()
+	mux.Handle("/fund", f)
+	server := &http.Server{Addr: listenAddress, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	err := server.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
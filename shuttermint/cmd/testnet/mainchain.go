@@ -0,0 +1,112 @@
+package testnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/medley"
+)
+
+// The main chain is either a ganache or a geth dev chain, started as a local process or, for
+// ganache, with docker. Ganache's deterministic accounts are funded from the start, so the first
+// one is the owner of the contracts and the faucet. Geth's dev account is random, so we move
+// ether from it to the same account.
+
+const (
+	mainChainGanache = "ganache"
+	mainChainGeth    = "geth"
+	mainChainDocker  = "docker"
+
+	ganacheDockerImage = "trufflesuite/ganache-cli:latest"
+	mainChainTimeout   = time.Minute
+)
+
+// devAccountFunds is the amount moved from geth's dev account to the owner account.
+var devAccountFunds = new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether))
+
+func startMainChain(ps *processes, kind string, dir string, port int, blockTime int) error {
+	portStr := strconv.Itoa(port)
+	blockTimeStr := strconv.Itoa(blockTime)
+	switch kind {
+	case mainChainGanache:
+		return ps.start("ganache", "ganache-cli", "-d", "-p", portStr, "-b", blockTimeStr)
+	case mainChainDocker:
+		return ps.start(
+			"ganache",
+			"docker", "run", "--rm", "-p", fmt.Sprintf("127.0.0.1:%d:8545", port),
+			ganacheDockerImage, "-d", "-b", blockTimeStr,
+		)
+	case mainChainGeth:
+		return ps.start(
+			"geth",
+			"geth", "--dev", "--dev.period", blockTimeStr,
+			"--datadir", filepath.Join(dir, "geth"),
+			"--http", "--http.addr", "127.0.0.1", "--http.port", portStr,
+			"--http.api", "eth,net,web3,personal",
+		)
+	default:
+		return errors.Errorf("unknown main chain %q, possible values: %s, %s, %s",
+			kind, mainChainGanache, mainChainGeth, mainChainDocker)
+	}
+}
+
+// waitForMainChain waits until the main chain node answers RPC requests.
+func waitForMainChain(ctx context.Context, url string) (*rpc.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, mainChainTimeout)
+	defer cancel()
+	for {
+		rpcClient, err := rpc.DialContext(ctx, url)
+		if err == nil {
+			_, err = ethclient.NewClient(rpcClient).ChainID(ctx)
+			if err == nil {
+				return rpcClient, nil
+			}
+			rpcClient.Close()
+		}
+		medley.Sleep(ctx, 500*time.Millisecond)
+		if ctx.Err() != nil {
+			return nil, errors.Wrapf(err, "main chain node at %s not reachable", url)
+		}
+	}
+}
+
+// fundFromDevAccount sends ether from geth's unlocked dev account to the owner account.
+func fundFromDevAccount(ctx context.Context, rpcClient *rpc.Client, owner *ecdsa.PrivateKey) error {
+	var accounts []common.Address
+	if err := rpcClient.CallContext(ctx, &accounts, "eth_accounts"); err != nil {
+		return errors.Wrap(err, "failed to query dev account")
+	}
+	if len(accounts) == 0 {
+		return errors.New("geth has no dev account")
+	}
+	ownerAddress := crypto.PubkeyToAddress(owner.PublicKey)
+	var txHash common.Hash
+	err := rpcClient.CallContext(ctx, &txHash, "eth_sendTransaction", map[string]interface{}{
+		"from":  accounts[0],
+		"to":    ownerAddress,
+		"value": (*hexutil.Big)(devAccountFunds),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to send ether from the dev account")
+	}
+	_, err = medley.WaitMined(ctx, ethclient.NewClient(rpcClient), txHash)
+	if err != nil {
+		return err
+	}
+	log.Printf("Funded owner account %s from dev account %s", ownerAddress.Hex(), accounts[0].Hex())
+	return nil
+}
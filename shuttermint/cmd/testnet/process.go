@@ -0,0 +1,110 @@
+package testnet
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stopTimeout is how long we wait for a process to exit after interrupting it before killing it.
+const stopTimeout = 10 * time.Second
+
+type process struct {
+	name string
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// processes runs the processes of the test network. The output of each process goes to its own
+// file in the log directory.
+type processes struct {
+	logDir string
+
+	mux     sync.Mutex
+	running []*process
+	exited  chan *process
+}
+
+func newProcesses(logDir string) *processes {
+	return &processes{logDir: logDir, exited: make(chan *process, 64)}
+}
+
+// start starts a long running process.
+func (ps *processes) start(name string, command string, args ...string) error {
+	if err := os.MkdirAll(ps.logDir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create log directory")
+	}
+	logPath := filepath.Join(ps.logDir, name+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create log file %s", logPath)
+	}
+	cmd := exec.Command(command, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return errors.Wrapf(err, "failed to start %s", name)
+	}
+	log.Printf("Started %s (pid %d), logging to %s", name, cmd.Process.Pid, logPath)
+
+	p := &process{name: name, cmd: cmd, done: make(chan struct{})}
+	go func() {
+		p.err = cmd.Wait()
+		logFile.Close()
+		close(p.done)
+		ps.exited <- p
+	}()
+	ps.mux.Lock()
+	ps.running = append(ps.running, p)
+	ps.mux.Unlock()
+	return nil
+}
+
+// run runs a process to completion, e.g. one of our own setup subcommands.
+func (ps *processes) run(ctx context.Context, name string, command string, args ...string) error {
+	log.Printf("Running %s", name)
+	cmd := exec.CommandContext(ctx, command, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "%s failed:\n%s", name, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// stop interrupts the running processes in reverse order of their start and waits for them to
+// exit.
+func (ps *processes) stop() {
+	ps.mux.Lock()
+	running := ps.running
+	ps.running = nil
+	ps.mux.Unlock()
+
+	for i := len(running) - 1; i >= 0; i-- {
+		p := running[i]
+		select {
+		case <-p.done:
+			continue
+		default:
+		}
+		log.Printf("Stopping %s", p.name)
+		if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+			log.Printf("Error: failed to interrupt %s: %s", p.name, err)
+		}
+		select {
+		case <-p.done:
+		case <-time.After(stopTimeout):
+			log.Printf("%s didn't stop within %s, killing it", p.name, stopTimeout)
+			_ = p.cmd.Process.Kill()
+			<-p.done
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package testnet
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/rpc/client/http"
+	"github.com/tendermint/tendermint/types"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/medley"
+)
+
+// Each keyper runs its own shuttermint validator. The nodes are created with 'shuttermint init',
+// which uses the same port scheme as we do here, and afterwards share the genesis of the first
+// one, extended by the validators of the others, and know each other as persistent peers.
+
+const (
+	firstP2PPort       = 26656
+	firstRPCPort       = 26657
+	validatorPower     = 10
+	shuttermintTimeout = time.Minute
+)
+
+func nodeDir(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("node%d", index))
+}
+
+func nodeRPCURL(index int) string {
+	return fmt.Sprintf("http://localhost:%d", firstRPCPort+2*index)
+}
+
+func nodeP2PAddress(index int) string {
+	return fmt.Sprintf("127.0.0.1:%d", firstP2PPort+2*index)
+}
+
+func nodeConfigFile(dir string, index int) string {
+	return filepath.Join(nodeDir(dir, index), "config", "config.toml")
+}
+
+// writeValidatorKey stores the keyper's validator key as the node's validator key, so that the
+// keyper's check-in refers to the node.
+func writeValidatorKey(root string, config *keyper.Config) {
+	tmconfig := cfg.DefaultConfig()
+	tmconfig.SetRoot(root)
+	cfg.EnsureRoot(root)
+	privKey := ed25519.PrivKey(config.ValidatorKey)
+	privval.NewFilePV(privKey, tmconfig.PrivValidatorKeyFile(), tmconfig.PrivValidatorStateFile()).Save()
+}
+
+func loadNodeConfig(configFile string) (*cfg.Config, error) {
+	config := cfg.DefaultConfig()
+	config.SetRoot(filepath.Dir(filepath.Dir(configFile)))
+	v := viper.New()
+	v.SetConfigFile(configFile)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", configFile)
+	}
+	if err := v.Unmarshal(config); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", configFile)
+	}
+	return config, nil
+}
+
+// connectNodes makes the nodes share one genesis with all of the keypers' validators and
+// configures them as persistent peers of each other.
+func connectNodes(dir string, configs []*keyper.Config) error {
+	nodeConfigs := make([]*cfg.Config, len(configs))
+	peers := make([]string, len(configs))
+	for i := range configs {
+		config, err := loadNodeConfig(nodeConfigFile(dir, i))
+		if err != nil {
+			return err
+		}
+		nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
+		if err != nil {
+			return errors.Wrapf(err, "failed to load node key of node %d", i)
+		}
+		nodeConfigs[i] = config
+		peers[i] = p2p.IDAddressString(nodeKey.ID(), nodeP2PAddress(i))
+	}
+
+	genDoc, err := types.GenesisDocFromFile(nodeConfigs[0].GenesisFile())
+	if err != nil {
+		return errors.Wrap(err, "failed to load genesis")
+	}
+	genDoc.Validators = nil
+	for i, config := range configs {
+		pubKey := ed25519.PrivKey(config.ValidatorKey).PubKey()
+		genDoc.Validators = append(genDoc.Validators, types.GenesisValidator{
+			Address: pubKey.Address(),
+			PubKey:  pubKey,
+			Power:   validatorPower,
+			Name:    fmt.Sprintf("node%d", i),
+		})
+	}
+
+	for i, config := range nodeConfigs {
+		if err := genDoc.SaveAs(config.GenesisFile()); err != nil {
+			return errors.Wrapf(err, "failed to save genesis of node %d", i)
+		}
+		var otherPeers []string
+		for j, peer := range peers {
+			if j != i {
+				otherPeers = append(otherPeers, peer)
+			}
+		}
+		config.P2P.PersistentPeers = strings.Join(otherPeers, ",")
+		cfg.WriteConfigFile(nodeConfigFile(dir, i), config)
+	}
+	return nil
+}
+
+// waitForShuttermint waits until the node at the given URL has produced its first block.
+func waitForShuttermint(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, shuttermintTimeout)
+	defer cancel()
+	shmcl, err := http.New(url, "/websocket")
+	if err != nil {
+		return err
+	}
+	for {
+		status, err := shmcl.Status(ctx)
+		if err == nil && status.SyncInfo.LatestBlockHeight > 0 {
+			return nil
+		}
+		medley.Sleep(ctx, 500*time.Millisecond)
+		if ctx.Err() != nil {
+			return errors.Errorf("shuttermint node at %s didn't produce a block within %s", url, shuttermintTimeout)
+		}
+	}
+}
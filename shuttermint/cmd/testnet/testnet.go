@@ -0,0 +1,327 @@
+// Package testnet contains the implementation of the testnet subcommand
+package testnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/deploy"
+	"github.com/shutter-network/shutter/shuttermint/cmd/prepare"
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/medley"
+	"github.com/shutter-network/shutter/shuttermint/sandbox"
+)
+
+var TestnetCmd = &cobra.Command{
+	Use:   "testnet",
+	Short: "Run a local Shutter test network",
+	Run:   medley.ShowHelpAndExit,
+}
+
+var upFlags struct {
+	Dir           string
+	NumKeypers    int
+	MainChain     string
+	MainChainPort int
+	EthereumURL   string
+	OwnerKey      string
+	BlockTime     int
+	FaucetAddress string
+}
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Start a local test network",
+	Long: `This command starts a main chain with the Shutter contracts deployed, a Shuttermint
+validator and a keyper for each of the given number of keypers, and a faucet. The main
+chain is a ganache (ganache-cli must be installed) or geth dev chain, or a ganache chain
+run with docker. All files, including the logs of the processes, are stored in the given
+directory, which must not exist yet. The network runs until the command is interrupted.
+
+Ask the faucet for ether with: curl -X POST 'http://<faucet address>/fund?address=0x...'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateUpFlags(); err != nil {
+			return err
+		}
+		return up()
+	},
+}
+
+func init() {
+	TestnetCmd.AddCommand(upCmd)
+
+	upCmd.Flags().StringVarP(
+		&upFlags.Dir,
+		"dir",
+		"d",
+		"testnet",
+		"directory in which the files of the test network shall be stored",
+	)
+	upCmd.Flags().IntVarP(
+		&upFlags.NumKeypers,
+		"num-keypers",
+		"n",
+		3,
+		"number of keypers",
+	)
+	upCmd.Flags().StringVar(
+		&upFlags.MainChain,
+		"main-chain",
+		mainChainGanache,
+		"main chain to start, possible values: ganache, geth, docker",
+	)
+	upCmd.Flags().IntVar(
+		&upFlags.MainChainPort,
+		"main-chain-port",
+		8545,
+		"RPC port of the started main chain",
+	)
+	upCmd.Flags().StringVarP(
+		&upFlags.EthereumURL,
+		"ethereum-url",
+		"e",
+		"",
+		"use the main chain at this Ethereum JSON RPC URL instead of starting one",
+	)
+	upCmd.Flags().StringVarP(
+		&upFlags.OwnerKey,
+		"owner-key",
+		"k",
+		"",
+		"private key of the funded account deploying the contracts (default: first ganache account)",
+	)
+	upCmd.Flags().IntVar(
+		&upFlags.BlockTime,
+		"blocktime",
+		1,
+		"block time of both chains in seconds",
+	)
+	upCmd.Flags().StringVar(
+		&upFlags.FaucetAddress,
+		"faucet",
+		"localhost:8090",
+		"listen address of the faucet",
+	)
+}
+
+func validateUpFlags() error {
+	if upFlags.NumKeypers <= 0 {
+		return errors.Errorf("invalid flag --num-keypers: must be at least 1")
+	}
+	if upFlags.BlockTime <= 0 {
+		return errors.Errorf("invalid flag --blocktime: must be at least 1")
+	}
+	if _, err := os.Stat(upFlags.Dir); !os.IsNotExist(err) {
+		return errors.Errorf("invalid flag --dir: %s already exists", upFlags.Dir)
+	}
+	return nil
+}
+
+func ownerKey() (*ecdsa.PrivateKey, error) {
+	if upFlags.OwnerKey == "" {
+		return sandbox.GanacheKey(0), nil
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(upFlags.OwnerKey, "0x"))
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid flag --owner-key")
+	}
+	return key, nil
+}
+
+func up() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "failed to find our executable")
+	}
+	dir, err := filepath.Abs(upFlags.Dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", dir)
+	}
+	key, err := ownerKey()
+	if err != nil {
+		return err
+	}
+	ownerKeyHex := hex.EncodeToString(crypto.FromECDSA(key))
+
+	ps := newProcesses(filepath.Join(dir, "logs"))
+	defer ps.stop()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		log.Printf("Got signal '%s'. Shutting down the test network.", sig)
+		cancel()
+	}()
+
+	ethereumURL := upFlags.EthereumURL
+	if ethereumURL == "" {
+		ethereumURL = fmt.Sprintf("http://localhost:%d", upFlags.MainChainPort)
+		if err := startMainChain(ps, upFlags.MainChain, dir, upFlags.MainChainPort, upFlags.BlockTime); err != nil {
+			return err
+		}
+	}
+	rpcClient, err := waitForMainChain(ctx, ethereumURL)
+	if err != nil {
+		return err
+	}
+	client := ethclient.NewClient(rpcClient)
+	if upFlags.EthereumURL == "" && upFlags.MainChain == mainChainGeth {
+		if err := fundFromDevAccount(ctx, rpcClient, key); err != nil {
+			return err
+		}
+	}
+
+	contractsPath := filepath.Join(dir, "contracts.json")
+	err = ps.run(ctx, "deploy", executable,
+		"deploy", "-e", ethereumURL, "-k", ownerKeyHex, "-o", contractsPath)
+	if err != nil {
+		return err
+	}
+	contracts, err := deploy.LoadContractsJSON(contractsPath)
+	if err != nil {
+		return err
+	}
+
+	configs, batchConfigPath, err := writeKeyperConfigs(dir, ethereumURL, contracts)
+	if err != nil {
+		return err
+	}
+	err = ps.run(ctx, "fund keypers", executable,
+		"prepare", "fund", "-e", ethereumURL, "-k", ownerKeyHex, "--config", batchConfigPath)
+	if err != nil {
+		return err
+	}
+	err = ps.run(ctx, "schedule batch config", executable,
+		"config", "schedule", "-e", ethereumURL, "-c", contractsPath, "-k", ownerKeyHex, "--config", batchConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if err := initNodes(ctx, ps, executable, dir, configs); err != nil {
+		return err
+	}
+	for i := range configs {
+		err := ps.start(fmt.Sprintf("node%d", i), executable, "chain", "--config", nodeConfigFile(dir, i))
+		if err != nil {
+			return err
+		}
+	}
+	if err := waitForShuttermint(ctx, nodeRPCURL(0)); err != nil {
+		return err
+	}
+	err = ps.run(ctx, "bootstrap", executable,
+		"bootstrap", "-e", ethereumURL, "-c", contractsPath, "-s", nodeRPCURL(0),
+		"-k", hex.EncodeToString(crypto.FromECDSA(configs[0].SigningKey)))
+	if err != nil {
+		return err
+	}
+	for i := range configs {
+		err := ps.start(fmt.Sprintf("keyper%d", i), executable, "keyper", "--config", keyperConfigFile(dir, i))
+		if err != nil {
+			return err
+		}
+	}
+
+	faucetErr := make(chan error, 1)
+	go func() {
+		faucetErr <- serveFaucet(ctx, upFlags.FaucetAddress, &faucet{client: client, key: key})
+	}()
+
+	log.Printf("Test network is up")
+	log.Printf("  Ethereum:    %s", ethereumURL)
+	for i, config := range configs {
+		log.Printf("  Keyper %d:    %s, shuttermint %s", i, config.Address().Hex(), nodeRPCURL(i))
+	}
+	log.Printf("  Faucet:      http://%s/fund?address=0x...", upFlags.FaucetAddress)
+	log.Printf("  Files, logs: %s", dir)
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case p := <-ps.exited:
+		if ctx.Err() != nil {
+			return nil // the process got the signal as well
+		}
+		return errors.Errorf("%s exited unexpectedly (%v), see %s", p.name, p.err, filepath.Join(ps.logDir, p.name+".log"))
+	case err := <-faucetErr:
+		return errors.Wrap(err, "faucet failed")
+	}
+}
+
+func keyperConfigFile(dir string, index int) string {
+	return filepath.Join(dir, "keyper"+strconv.Itoa(index), "config.toml")
+}
+
+// writeKeyperConfigs creates the keyper configs, each one using its own shuttermint node, and the
+// batch config of the keyper set. It returns the configs and the path of the batch config.
+func writeKeyperConfigs(
+	dir string, ethereumURL string, contracts *deploy.Contracts,
+) ([]*keyper.Config, string, error) {
+	configs := []*keyper.Config{}
+	keypers := []common.Address{}
+	for i := 0; i < upFlags.NumKeypers; i++ {
+		config, err := prepare.NewKeyperConfig(contracts, ethereumURL, nodeRPCURL(i))
+		if err != nil {
+			return nil, "", err
+		}
+		keyperDir := filepath.Dir(keyperConfigFile(dir, i))
+		config.DBDir = filepath.Join(keyperDir, "db")
+		if err := prepare.SaveKeyperConfig(config, keyperDir); err != nil {
+			return nil, "", err
+		}
+		configs = append(configs, config)
+		keypers = append(keypers, config.Address())
+	}
+
+	j, err := json.MarshalIndent(prepare.NewBatchConfig(keypers, contracts), "", "    ")
+	if err != nil {
+		return nil, "", errors.Wrap(err, "marshal json")
+	}
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, j, 0o644); err != nil {
+		return nil, "", errors.Wrapf(err, "write to %s", path)
+	}
+	return configs, path, nil
+}
+
+// initNodes creates the shuttermint nodes of the keypers.
+func initNodes(ctx context.Context, ps *processes, executable string, dir string, configs []*keyper.Config) error {
+	args := []string{"init", "--blocktime", strconv.Itoa(upFlags.BlockTime)}
+	for _, config := range configs {
+		args = append(args, "--genesis-keyper", config.Address().Hex())
+	}
+	for i, config := range configs {
+		root := nodeDir(dir, i)
+		writeValidatorKey(root, config)
+		nodeArgs := append(append([]string{}, args...), "--root", root, "--index", strconv.Itoa(i))
+		if err := ps.run(ctx, fmt.Sprintf("init node%d", i), executable, nodeArgs...); err != nil {
+			return err
+		}
+	}
+	return connectNodes(dir, configs)
+}
@@ -0,0 +1,169 @@
+// Package commitreveal implements sealed-bid and commit-reveal flows on top of the epoch
+// encryption scheme. Instead of committing to a vote and revealing it later, participants encrypt
+// their vote to a deadline epoch. The keypers release the epoch secret key only once the deadline
+// batch has been closed, so nobody, not even the voter, can reveal a vote early or withhold it.
+package commitreveal
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// Sealed is a vote or bid encrypted to a deadline epoch.
+type Sealed struct {
+	Namespace string
+	Epoch     uint64
+	Message   *shcrypto.EncryptedMessage
+}
+
+// Opened is a sealed vote or bid after decryption. Err is set if the vote could not be decrypted.
+type Opened struct {
+	Index   int // index into the list of sealed votes
+	Payload []byte
+	Err     error
+}
+
+// DeadlineEpoch returns the epoch to encrypt to so that the key is released after the given main
+// chain block. Keypers publish their shares for an epoch once the corresponding batch is closed,
+// so the key is not available before the end of the batch containing deadlineBlock. The epoch is
+// determined by the shuttermint batch config of the batch, i.e. its epoch mapping, or the block
+// itself if it uses per-block epochs.
+func DeadlineEpoch(
+	config contract.BatchConfig,
+	shutterConfig *shutterevents.BatchConfig,
+	deadlineBlock uint64,
+) (uint64, error) {
+	if !config.IsActive() {
+		return 0, errors.Errorf("batch config is inactive")
+	}
+	if deadlineBlock < config.StartBlockNumber {
+		return 0, errors.Errorf(
+			"deadline block %d is before start of batch config (%d)",
+			deadlineBlock,
+			config.StartBlockNumber,
+		)
+	}
+	if shutterConfig.PerBlockEpochs {
+		return deadlineBlock, nil
+	}
+	return shutterConfig.Epoch(config.BatchIndex(deadlineBlock)), nil
+}
+
+// Seal encrypts the given payload to the given epoch in the given namespace with the given version
+// of the encryption scheme, i.e. the EncryptionVersion of the eon's batch config. Use the empty
+// namespace for keys released by the keypers for every batch.
+func Seal(
+	random io.Reader,
+	eonPublicKey *shcrypto.EonPublicKey,
+	version uint8,
+	namespace string,
+	epoch uint64,
+	payload []byte,
+) (*Sealed, error) {
+	epochID := shcrypto.ComputeNamespacedEpochID(namespace, epoch)
+	m, err := shcrypto.EncryptWithVersion(version, payload, eonPublicKey, epochID, random)
+	if err != nil {
+		return nil, err
+	}
+	return &Sealed{
+		Namespace: namespace,
+		Epoch:     epoch,
+		Message:   m,
+	}, nil
+}
+
+// Open decrypts the sealed payload with the epoch secret key.
+func (s *Sealed) Open(epochSecretKey *shcrypto.EpochSecretKey) ([]byte, error) {
+	return s.Message.Decrypt(epochSecretKey)
+}
+
+// Marshal serializes the sealed vote, e.g. for submitting it to a contract.
+func (s *Sealed) Marshal() []byte {
+	namespace := []byte(s.Namespace)
+	d := make([]byte, 16, 16+len(namespace))
+	binary.BigEndian.PutUint64(d[:8], s.Epoch)
+	binary.BigEndian.PutUint64(d[8:16], uint64(len(namespace)))
+	d = append(d, namespace...)
+	return append(d, s.Message.Marshal()...)
+}
+
+// Unmarshal deserializes a sealed vote created with Marshal.
+func (s *Sealed) Unmarshal(d []byte) error {
+	if len(d) < 16 {
+		return errors.Errorf("sealed vote too short (%d bytes)", len(d))
+	}
+	epoch := binary.BigEndian.Uint64(d[:8])
+	namespaceLength := binary.BigEndian.Uint64(d[8:16])
+	d = d[16:]
+	if namespaceLength > uint64(len(d)) {
+		return errors.Errorf("invalid namespace length %d", namespaceLength)
+	}
+	message := new(shcrypto.EncryptedMessage)
+	if err := message.Unmarshal(d[namespaceLength:]); err != nil {
+		return err
+	}
+	s.Namespace = string(d[:namespaceLength])
+	s.Epoch = epoch
+	s.Message = message
+	return nil
+}
+
+// OpenAll decrypts all sealed votes for the given namespace and epoch. Votes that have been
+// encrypted to a different namespace or epoch or that cannot be decrypted are returned with the
+// error set, so that the application can decide how to handle them.
+func OpenAll(sealed []*Sealed, namespace string, epoch uint64, epochSecretKey *shcrypto.EpochSecretKey) []Opened {
+	var res []Opened
+	for i, s := range sealed {
+		opened := Opened{Index: i}
+		if s.Namespace != namespace || s.Epoch != epoch {
+			opened.Err = errors.Errorf(
+				"vote sealed for epoch %d in namespace %q, expected epoch %d in namespace %q",
+				s.Epoch,
+				s.Namespace,
+				epoch,
+				namespace,
+			)
+		} else {
+			opened.Payload, opened.Err = s.Open(epochSecretKey)
+		}
+		res = append(res, opened)
+	}
+	return res
+}
+
+// Count is the number of votes for a single choice.
+type Count struct {
+	Choice string
+	Votes  uint64
+}
+
+// Tally counts the successfully opened votes by payload. The result is sorted by number of votes
+// in descending order, ties are broken by the choice.
+func Tally(opened []Opened) []Count {
+	votes := make(map[string]uint64)
+	for _, o := range opened {
+		if o.Err != nil {
+			continue
+		}
+		votes[string(o.Payload)]++
+	}
+
+	var res []Count
+	for choice, n := range votes {
+		res = append(res, Count{Choice: choice, Votes: n})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Votes != res[j].Votes {
+			return res[i].Votes > res[j].Votes
+		}
+		return res[i].Choice < res[j].Choice
+	})
+	return res
+}
@@ -0,0 +1,89 @@
+package commitreveal
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func makeKeys(t *testing.T, namespace string, epoch uint64) (*shcrypto.EonPublicKey, *shcrypto.EpochSecretKey) {
+	t.Helper()
+	p, err := shcrypto.RandomPolynomial(rand.Reader, 0)
+	assert.NilError(t, err)
+	eonPublicKey := shcrypto.ComputeEonPublicKey([]*shcrypto.Gammas{p.Gammas()})
+	eonSecretKeyShare := shcrypto.ComputeEonSecretKeyShare([]*big.Int{p.Eval(big.NewInt(1))})
+	epochID := shcrypto.ComputeNamespacedEpochID(namespace, epoch)
+	share := shcrypto.ComputeEpochSecretKeyShare(eonSecretKeyShare, epochID)
+	key, err := shcrypto.ComputeEpochSecretKey([]int{0}, []*shcrypto.EpochSecretKeyShare{share}, 1)
+	assert.NilError(t, err)
+	return eonPublicKey, key
+}
+
+func TestDeadlineEpoch(t *testing.T) {
+	config := contract.BatchConfig{
+		StartBatchIndex:  10,
+		StartBlockNumber: 100,
+		BatchSpan:        5,
+	}
+	shutterConfig := &shutterevents.BatchConfig{StartBatchIndex: 10}
+	epoch, err := DeadlineEpoch(config, shutterConfig, 112)
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(12), epoch)
+
+	// the epoch is mapped like the keypers do, not just the batch index
+	shutterConfig.EpochOffset = 1000
+	shutterConfig.EpochStride = 3
+	epoch, err = DeadlineEpoch(config, shutterConfig, 112)
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(1036), epoch)
+	assert.Equal(t, epoch, shutterConfig.Epoch(12))
+
+	perBlock := &shutterevents.BatchConfig{StartBatchIndex: 10, PerBlockEpochs: true}
+	epoch, err = DeadlineEpoch(config, perBlock, 112)
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(112), epoch)
+
+	_, err = DeadlineEpoch(config, shutterConfig, 99)
+	assert.Assert(t, err != nil)
+
+	config.BatchSpan = 0
+	_, err = DeadlineEpoch(config, shutterConfig, 112)
+	assert.Assert(t, err != nil)
+}
+
+func TestSealOpenTally(t *testing.T) {
+	namespace := "vote"
+	epoch := uint64(12)
+	eonPublicKey, key := makeKeys(t, namespace, epoch)
+
+	var sealed []*Sealed
+	for _, v := range []string{"yes", "no", "yes"} {
+		s, err := Seal(rand.Reader, eonPublicKey, shcrypto.LatestVersion, namespace, epoch, []byte(v))
+		assert.NilError(t, err)
+
+		// make sure the vote survives serialization
+		s2 := new(Sealed)
+		assert.NilError(t, s2.Unmarshal(s.Marshal()))
+		assert.Equal(t, s.Namespace, s2.Namespace)
+		assert.Equal(t, s.Epoch, s2.Epoch)
+		assert.Equal(t, s2.Message.Version, shcrypto.LatestVersion)
+		sealed = append(sealed, s2)
+	}
+	wrongEpoch, err := Seal(rand.Reader, eonPublicKey, shcrypto.LatestVersion, namespace, epoch+1, []byte("no"))
+	assert.NilError(t, err)
+	sealed = append(sealed, wrongEpoch)
+
+	opened := OpenAll(sealed, namespace, epoch, key)
+	assert.Equal(t, len(sealed), len(opened))
+	assert.NilError(t, opened[0].Err)
+	assert.Equal(t, "yes", string(opened[0].Payload))
+	assert.Assert(t, opened[3].Err != nil)
+
+	assert.DeepEqual(t, []Count{{Choice: "yes", Votes: 2}, {Choice: "no", Votes: 1}}, Tally(opened))
+}
@@ -3249,6 +3249,480 @@ func (_Context *ContextTransactorRaw) Transact(opts *bind.TransactOpts, method s
 	return _Context.Contract.contract.Transact(opts, method, params...)
 }
 
+// DecryptionOracleContractMetaData contains all meta data concerning the DecryptionOracleContract contract.
+var DecryptionOracleContractMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"contractConfigContract\",\"name\":\"configContractAddress\",\"type\":\"address\"},{\"internalType\":\"contractKeyBroadcastContract\",\"name\":\"keyBroadcastContractAddress\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"uint64\",\"name\":\"batchIndex\",\"type\":\"uint64\"},{\"indexed\":false,\"internalType\":\"uint64\",\"name\":\"eonStartBatchIndex\",\"type\":\"uint64\"},{\"indexed\":false,\"internalType\":\"bytes\",\"name\":\"key\",\"type\":\"bytes\"},{\"indexed\":false,\"internalType\":\"address\",\"name\":\"submitter\",\"type\":\"address\"}],\"name\":\"EpochKeySubmitted\",\"type\":\"event\"},{\"inputs\":[],\"name\":\"configContract\",\"outputs\":[{\"internalType\":\"contractConfigContract\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"batchIndex\",\"type\":\"uint64\"}],\"name\":\"getEpochKey\",\"outputs\":[{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"batchIndex\",\"type\":\"uint64\"}],\"name\":\"hasEpochKey\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"keyBroadcastContract\",\"outputs\":[{\"internalType\":\"contractKeyBroadcastContract\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint64\",\"name\":\"keyperIndex\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"batchIndex\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"eonStartBatchIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes\",\"name\":\"key\",\"type\":\"bytes\"}],\"name\":\"submitEpochKey\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes\",\"name\":\"key\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"eonKey\",\"type\":\"bytes\"},{\"internalType\":\"uint64\",\"name\":\"batchIndex\",\"type\":\"uint64\"}],\"name\":\"verifyEpochKey\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// DecryptionOracleContractABI is the input ABI used to generate the binding from.
+// Deprecated: Use DecryptionOracleContractMetaData.ABI instead.
+var DecryptionOracleContractABI = DecryptionOracleContractMetaData.ABI
+
+// DecryptionOracleContract is an auto generated Go binding around an Ethereum contract.
+type DecryptionOracleContract struct {
+	DecryptionOracleContractCaller     // Read-only binding to the contract
+	DecryptionOracleContractTransactor // Write-only binding to the contract
+	DecryptionOracleContractFilterer   // Log filterer for contract events
+}
+
+// DecryptionOracleContractCaller is an auto generated read-only Go binding around an Ethereum contract.
+type DecryptionOracleContractCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DecryptionOracleContractTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type DecryptionOracleContractTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DecryptionOracleContractFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type DecryptionOracleContractFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DecryptionOracleContractSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type DecryptionOracleContractSession struct {
+	Contract     *DecryptionOracleContract // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts             // Call options to use throughout this session
+	TransactOpts bind.TransactOpts         // Transaction auth options to use throughout this session
+}
+
+// DecryptionOracleContractCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type DecryptionOracleContractCallerSession struct {
+	Contract *DecryptionOracleContractCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts                   // Call options to use throughout this session
+}
+
+// DecryptionOracleContractTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type DecryptionOracleContractTransactorSession struct {
+	Contract     *DecryptionOracleContractTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts                   // Transaction auth options to use throughout this session
+}
+
+// DecryptionOracleContractRaw is an auto generated low-level Go binding around an Ethereum contract.
+type DecryptionOracleContractRaw struct {
+	Contract *DecryptionOracleContract // Generic contract binding to access the raw methods on
+}
+
+// DecryptionOracleContractCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type DecryptionOracleContractCallerRaw struct {
+	Contract *DecryptionOracleContractCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// DecryptionOracleContractTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type DecryptionOracleContractTransactorRaw struct {
+	Contract *DecryptionOracleContractTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewDecryptionOracleContract creates a new instance of DecryptionOracleContract, bound to a specific deployed contract.
+func NewDecryptionOracleContract(address common.Address, backend bind.ContractBackend) (*DecryptionOracleContract, error) {
+	contract, err := bindDecryptionOracleContract(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionOracleContract{DecryptionOracleContractCaller: DecryptionOracleContractCaller{contract: contract}, DecryptionOracleContractTransactor: DecryptionOracleContractTransactor{contract: contract}, DecryptionOracleContractFilterer: DecryptionOracleContractFilterer{contract: contract}}, nil
+}
+
+// NewDecryptionOracleContractCaller creates a new read-only instance of DecryptionOracleContract, bound to a specific deployed contract.
+func NewDecryptionOracleContractCaller(address common.Address, caller bind.ContractCaller) (*DecryptionOracleContractCaller, error) {
+	contract, err := bindDecryptionOracleContract(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionOracleContractCaller{contract: contract}, nil
+}
+
+// NewDecryptionOracleContractTransactor creates a new write-only instance of DecryptionOracleContract, bound to a specific deployed contract.
+func NewDecryptionOracleContractTransactor(address common.Address, transactor bind.ContractTransactor) (*DecryptionOracleContractTransactor, error) {
+	contract, err := bindDecryptionOracleContract(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionOracleContractTransactor{contract: contract}, nil
+}
+
+// NewDecryptionOracleContractFilterer creates a new log filterer instance of DecryptionOracleContract, bound to a specific deployed contract.
+func NewDecryptionOracleContractFilterer(address common.Address, filterer bind.ContractFilterer) (*DecryptionOracleContractFilterer, error) {
+	contract, err := bindDecryptionOracleContract(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionOracleContractFilterer{contract: contract}, nil
+}
+
+// bindDecryptionOracleContract binds a generic wrapper to an already deployed contract.
+func bindDecryptionOracleContract(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(DecryptionOracleContractABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_DecryptionOracleContract *DecryptionOracleContractRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _DecryptionOracleContract.Contract.DecryptionOracleContractCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_DecryptionOracleContract *DecryptionOracleContractRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DecryptionOracleContract.Contract.DecryptionOracleContractTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_DecryptionOracleContract *DecryptionOracleContractRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _DecryptionOracleContract.Contract.DecryptionOracleContractTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_DecryptionOracleContract *DecryptionOracleContractCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _DecryptionOracleContract.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_DecryptionOracleContract *DecryptionOracleContractTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DecryptionOracleContract.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_DecryptionOracleContract *DecryptionOracleContractTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _DecryptionOracleContract.Contract.contract.Transact(opts, method, params...)
+}
+
+// ConfigContract is a free data retrieval call binding the contract method 0xbf66a182.
+//
+// Solidity: function configContract() view returns(address)
+func (_DecryptionOracleContract *DecryptionOracleContractCaller) ConfigContract(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _DecryptionOracleContract.contract.Call(opts, &out, "configContract")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// ConfigContract is a free data retrieval call binding the contract method 0xbf66a182.
+//
+// Solidity: function configContract() view returns(address)
+func (_DecryptionOracleContract *DecryptionOracleContractSession) ConfigContract() (common.Address, error) {
+	return _DecryptionOracleContract.Contract.ConfigContract(&_DecryptionOracleContract.CallOpts)
+}
+
+// ConfigContract is a free data retrieval call binding the contract method 0xbf66a182.
+//
+// Solidity: function configContract() view returns(address)
+func (_DecryptionOracleContract *DecryptionOracleContractCallerSession) ConfigContract() (common.Address, error) {
+	return _DecryptionOracleContract.Contract.ConfigContract(&_DecryptionOracleContract.CallOpts)
+}
+
+// GetEpochKey is a free data retrieval call binding the contract method 0xad38317f.
+//
+// Solidity: function getEpochKey(uint64 batchIndex) view returns(bytes)
+func (_DecryptionOracleContract *DecryptionOracleContractCaller) GetEpochKey(opts *bind.CallOpts, batchIndex uint64) ([]byte, error) {
+	var out []interface{}
+	err := _DecryptionOracleContract.contract.Call(opts, &out, "getEpochKey", batchIndex)
+
+	if err != nil {
+		return *new([]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([]byte)).(*[]byte)
+
+	return out0, err
+
+}
+
+// GetEpochKey is a free data retrieval call binding the contract method 0xad38317f.
+//
+// Solidity: function getEpochKey(uint64 batchIndex) view returns(bytes)
+func (_DecryptionOracleContract *DecryptionOracleContractSession) GetEpochKey(batchIndex uint64) ([]byte, error) {
+	return _DecryptionOracleContract.Contract.GetEpochKey(&_DecryptionOracleContract.CallOpts, batchIndex)
+}
+
+// GetEpochKey is a free data retrieval call binding the contract method 0xad38317f.
+//
+// Solidity: function getEpochKey(uint64 batchIndex) view returns(bytes)
+func (_DecryptionOracleContract *DecryptionOracleContractCallerSession) GetEpochKey(batchIndex uint64) ([]byte, error) {
+	return _DecryptionOracleContract.Contract.GetEpochKey(&_DecryptionOracleContract.CallOpts, batchIndex)
+}
+
+// HasEpochKey is a free data retrieval call binding the contract method 0x8a7660e1.
+//
+// Solidity: function hasEpochKey(uint64 batchIndex) view returns(bool)
+func (_DecryptionOracleContract *DecryptionOracleContractCaller) HasEpochKey(opts *bind.CallOpts, batchIndex uint64) (bool, error) {
+	var out []interface{}
+	err := _DecryptionOracleContract.contract.Call(opts, &out, "hasEpochKey", batchIndex)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// HasEpochKey is a free data retrieval call binding the contract method 0x8a7660e1.
+//
+// Solidity: function hasEpochKey(uint64 batchIndex) view returns(bool)
+func (_DecryptionOracleContract *DecryptionOracleContractSession) HasEpochKey(batchIndex uint64) (bool, error) {
+	return _DecryptionOracleContract.Contract.HasEpochKey(&_DecryptionOracleContract.CallOpts, batchIndex)
+}
+
+// HasEpochKey is a free data retrieval call binding the contract method 0x8a7660e1.
+//
+// Solidity: function hasEpochKey(uint64 batchIndex) view returns(bool)
+func (_DecryptionOracleContract *DecryptionOracleContractCallerSession) HasEpochKey(batchIndex uint64) (bool, error) {
+	return _DecryptionOracleContract.Contract.HasEpochKey(&_DecryptionOracleContract.CallOpts, batchIndex)
+}
+
+// KeyBroadcastContract is a free data retrieval call binding the contract method 0xdf640631.
+//
+// Solidity: function keyBroadcastContract() view returns(address)
+func (_DecryptionOracleContract *DecryptionOracleContractCaller) KeyBroadcastContract(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _DecryptionOracleContract.contract.Call(opts, &out, "keyBroadcastContract")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// KeyBroadcastContract is a free data retrieval call binding the contract method 0xdf640631.
+//
+// Solidity: function keyBroadcastContract() view returns(address)
+func (_DecryptionOracleContract *DecryptionOracleContractSession) KeyBroadcastContract() (common.Address, error) {
+	return _DecryptionOracleContract.Contract.KeyBroadcastContract(&_DecryptionOracleContract.CallOpts)
+}
+
+// KeyBroadcastContract is a free data retrieval call binding the contract method 0xdf640631.
+//
+// Solidity: function keyBroadcastContract() view returns(address)
+func (_DecryptionOracleContract *DecryptionOracleContractCallerSession) KeyBroadcastContract() (common.Address, error) {
+	return _DecryptionOracleContract.Contract.KeyBroadcastContract(&_DecryptionOracleContract.CallOpts)
+}
+
+// VerifyEpochKey is a free data retrieval call binding the contract method 0xbf824512.
+//
+// Solidity: function verifyEpochKey(bytes key, bytes eonKey, uint64 batchIndex) view returns(bool)
+func (_DecryptionOracleContract *DecryptionOracleContractCaller) VerifyEpochKey(opts *bind.CallOpts, key []byte, eonKey []byte, batchIndex uint64) (bool, error) {
+	var out []interface{}
+	err := _DecryptionOracleContract.contract.Call(opts, &out, "verifyEpochKey", key, eonKey, batchIndex)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// VerifyEpochKey is a free data retrieval call binding the contract method 0xbf824512.
+//
+// Solidity: function verifyEpochKey(bytes key, bytes eonKey, uint64 batchIndex) view returns(bool)
+func (_DecryptionOracleContract *DecryptionOracleContractSession) VerifyEpochKey(key []byte, eonKey []byte, batchIndex uint64) (bool, error) {
+	return _DecryptionOracleContract.Contract.VerifyEpochKey(&_DecryptionOracleContract.CallOpts, key, eonKey, batchIndex)
+}
+
+// VerifyEpochKey is a free data retrieval call binding the contract method 0xbf824512.
+//
+// Solidity: function verifyEpochKey(bytes key, bytes eonKey, uint64 batchIndex) view returns(bool)
+func (_DecryptionOracleContract *DecryptionOracleContractCallerSession) VerifyEpochKey(key []byte, eonKey []byte, batchIndex uint64) (bool, error) {
+	return _DecryptionOracleContract.Contract.VerifyEpochKey(&_DecryptionOracleContract.CallOpts, key, eonKey, batchIndex)
+}
+
+// SubmitEpochKey is a paid mutator transaction binding the contract method 0xc7372aa1.
+//
+// Solidity: function submitEpochKey(uint64 keyperIndex, uint64 batchIndex, uint64 eonStartBatchIndex, bytes key) returns()
+func (_DecryptionOracleContract *DecryptionOracleContractTransactor) SubmitEpochKey(opts *bind.TransactOpts, keyperIndex uint64, batchIndex uint64, eonStartBatchIndex uint64, key []byte) (*types.Transaction, error) {
+	return _DecryptionOracleContract.contract.Transact(opts, "submitEpochKey", keyperIndex, batchIndex, eonStartBatchIndex, key)
+}
+
+// SubmitEpochKey is a paid mutator transaction binding the contract method 0xc7372aa1.
+//
+// Solidity: function submitEpochKey(uint64 keyperIndex, uint64 batchIndex, uint64 eonStartBatchIndex, bytes key) returns()
+func (_DecryptionOracleContract *DecryptionOracleContractSession) SubmitEpochKey(keyperIndex uint64, batchIndex uint64, eonStartBatchIndex uint64, key []byte) (*types.Transaction, error) {
+	return _DecryptionOracleContract.Contract.SubmitEpochKey(&_DecryptionOracleContract.TransactOpts, keyperIndex, batchIndex, eonStartBatchIndex, key)
+}
+
+// SubmitEpochKey is a paid mutator transaction binding the contract method 0xc7372aa1.
+//
+// Solidity: function submitEpochKey(uint64 keyperIndex, uint64 batchIndex, uint64 eonStartBatchIndex, bytes key) returns()
+func (_DecryptionOracleContract *DecryptionOracleContractTransactorSession) SubmitEpochKey(keyperIndex uint64, batchIndex uint64, eonStartBatchIndex uint64, key []byte) (*types.Transaction, error) {
+	return _DecryptionOracleContract.Contract.SubmitEpochKey(&_DecryptionOracleContract.TransactOpts, keyperIndex, batchIndex, eonStartBatchIndex, key)
+}
+
+// DecryptionOracleContractEpochKeySubmittedIterator is returned from FilterEpochKeySubmitted and is used to iterate over the raw logs and unpacked data for EpochKeySubmitted events raised by the DecryptionOracleContract contract.
+type DecryptionOracleContractEpochKeySubmittedIterator struct {
+	Event *DecryptionOracleContractEpochKeySubmitted // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DecryptionOracleContractEpochKeySubmittedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DecryptionOracleContractEpochKeySubmitted)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DecryptionOracleContractEpochKeySubmitted)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DecryptionOracleContractEpochKeySubmittedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DecryptionOracleContractEpochKeySubmittedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DecryptionOracleContractEpochKeySubmitted represents a EpochKeySubmitted event raised by the DecryptionOracleContract contract.
+type DecryptionOracleContractEpochKeySubmitted struct {
+	BatchIndex         uint64
+	EonStartBatchIndex uint64
+	Key                []byte
+	Submitter          common.Address
+	Raw                types.Log // Blockchain specific contextual infos
+}
+
+// FilterEpochKeySubmitted is a free log retrieval operation binding the contract event 0x16ad50c6f32cbcdab2f0add3da39d5de61c11e17142779daa86361fb6ecab087.
+//
+// Solidity: event EpochKeySubmitted(uint64 indexed batchIndex, uint64 eonStartBatchIndex, bytes key, address submitter)
+func (_DecryptionOracleContract *DecryptionOracleContractFilterer) FilterEpochKeySubmitted(opts *bind.FilterOpts, batchIndex []uint64) (*DecryptionOracleContractEpochKeySubmittedIterator, error) {
+
+	var batchIndexRule []interface{}
+	for _, batchIndexItem := range batchIndex {
+		batchIndexRule = append(batchIndexRule, batchIndexItem)
+	}
+
+	logs, sub, err := _DecryptionOracleContract.contract.FilterLogs(opts, "EpochKeySubmitted", batchIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionOracleContractEpochKeySubmittedIterator{contract: _DecryptionOracleContract.contract, event: "EpochKeySubmitted", logs: logs, sub: sub}, nil
+}
+
+// WatchEpochKeySubmitted is a free log subscription operation binding the contract event 0x16ad50c6f32cbcdab2f0add3da39d5de61c11e17142779daa86361fb6ecab087.
+//
+// Solidity: event EpochKeySubmitted(uint64 indexed batchIndex, uint64 eonStartBatchIndex, bytes key, address submitter)
+func (_DecryptionOracleContract *DecryptionOracleContractFilterer) WatchEpochKeySubmitted(opts *bind.WatchOpts, sink chan<- *DecryptionOracleContractEpochKeySubmitted, batchIndex []uint64) (event.Subscription, error) {
+
+	var batchIndexRule []interface{}
+	for _, batchIndexItem := range batchIndex {
+		batchIndexRule = append(batchIndexRule, batchIndexItem)
+	}
+
+	logs, sub, err := _DecryptionOracleContract.contract.WatchLogs(opts, "EpochKeySubmitted", batchIndexRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DecryptionOracleContractEpochKeySubmitted)
+				if err := _DecryptionOracleContract.contract.UnpackLog(event, "EpochKeySubmitted", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseEpochKeySubmitted is a log parse operation binding the contract event 0x16ad50c6f32cbcdab2f0add3da39d5de61c11e17142779daa86361fb6ecab087.
+//
+// Solidity: event EpochKeySubmitted(uint64 indexed batchIndex, uint64 eonStartBatchIndex, bytes key, address submitter)
+func (_DecryptionOracleContract *DecryptionOracleContractFilterer) ParseEpochKeySubmitted(log types.Log) (*DecryptionOracleContractEpochKeySubmitted, error) {
+	event := new(DecryptionOracleContractEpochKeySubmitted)
+	if err := _DecryptionOracleContract.contract.UnpackLog(event, "EpochKeySubmitted", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
 // DepositContractMetaData contains all meta data concerning the DepositContract contract.
 var DepositContractMetaData = &bind.MetaData{
 	ABI: "[{\"inputs\":[{\"internalType\":\"contractIERC777\",\"name\":\"tokenContract\",\"type\":\"address\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"indexed\":false,\"internalType\":\"uint64\",\"name\":\"withdrawalDelayBlocks\",\"type\":\"uint64\"},{\"indexed\":false,\"internalType\":\"uint64\",\"name\":\"withdrawalRequestedBlock\",\"type\":\"uint64\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"withdrawn\",\"type\":\"bool\"},{\"indexed\":false,\"internalType\":\"bool\",\"name\":\"slashed\",\"type\":\"bool\"}],\"name\":\"DepositChanged\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"previousOwner\",\"type\":\"address\"},{\"indexed\":true,\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"OwnershipTransferred\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"newSlashingReceiver\",\"type\":\"address\"}],\"name\":\"SlashingReceiverSet\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getDepositAmount\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getWithdrawalDelayBlocks\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"getWithdrawalRequestedBlock\",\"outputs\":[{\"internalType\":\"uint64\",\"name\":\"\",\"type\":\"uint64\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"isSlashed\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"owner\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"renounceOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"requestWithdrawal\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"slasherAddress\",\"type\":\"address\"}],\"name\":\"setSlasher\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newSlashingReceiver\",\"type\":\"address\"}],\"name\":\"setSlashingReceiver\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"}],\"name\":\"slash\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"slasher\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"slashingReceiver\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"token\",\"outputs\":[{\"internalType\":\"contractIERC777\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"from\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"userData\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"\",\"type\":\"bytes\"}],\"name\":\"tokensReceived\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOwner\",\"type\":\"address\"}],\"name\":\"transferOwnership\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"recipient\",\"type\":\"address\"}],\"name\":\"withdraw\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
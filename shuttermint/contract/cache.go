@@ -0,0 +1,229 @@
+package contract
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/pkg/errors"
+)
+
+// cacheKey identifies a cached contract call by the name of the call and an index argument.
+type cacheKey struct {
+	name  string
+	index uint64
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time // zero if the value never expires
+}
+
+// Cache caches the results of contract calls. Values either expire after a fixed TTL or, if they
+// cannot change anymore, stay valid until they are invalidated explicitly, usually in response to
+// a contract event. A nil Cache is valid and doesn't cache anything.
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[cacheKey]cacheEntry
+	hits    uint64
+	misses  uint64
+}
+
+// NewCache creates a new cache with the given TTL for mutable values.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[cacheKey]cacheEntry),
+	}
+}
+
+func (c *Cache) get(key cacheKey) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if ok && !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		ok = false
+	}
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	return entry.value, ok
+}
+
+// set stores a value. If permanent is false, the value expires after the cache's TTL.
+func (c *Cache) set(key cacheKey, value interface{}, permanent bool) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := cacheEntry{value: value}
+	if !permanent {
+		if c.ttl <= 0 {
+			return
+		}
+		entry.expires = c.now().Add(c.ttl)
+	}
+	c.entries[key] = entry
+}
+
+// invalidate removes all entries of the given call with an index of at least fromIndex.
+func (c *Cache) invalidate(name string, fromIndex uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.name == name && key.index >= fromIndex {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Stats returns the number of cache hits and misses.
+func (c *Cache) Stats() (hits uint64, misses uint64) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+const (
+	cacheNumConfigs  = "numConfigs"
+	cacheConfig      = "config"
+	cacheHasEpochKey = "hasEpochKey"
+)
+
+// InvalidateConfigs removes all cached configs with an index of at least numConfigs, e.g.,
+// because they have been unscheduled.
+func (cc *Caller) InvalidateConfigs(numConfigs uint64) {
+	cc.Cache.invalidate(cacheNumConfigs, 0)
+	cc.Cache.invalidate(cacheConfig, numConfigs)
+}
+
+// NumConfigs returns the number of scheduled configs. The value is cached only for queries of the
+// latest block.
+func (cc *Caller) NumConfigs(opts *bind.CallOpts) (uint64, error) {
+	latest := opts == nil || opts.BlockNumber == nil
+	key := cacheKey{name: cacheNumConfigs}
+	if latest {
+		if v, ok := cc.Cache.get(key); ok {
+			return v.(uint64), nil
+		}
+	}
+	numConfigs, err := cc.ConfigContract.NumConfigs(opts)
+	if err != nil {
+		return 0, err
+	}
+	if latest {
+		cc.Cache.set(key, numConfigs, false)
+	}
+	return numConfigs, nil
+}
+
+// ConfigByIndex returns the config with the given index. Scheduled configs don't change unless
+// they are unscheduled, so they are cached until a ConfigUnscheduled event is seen.
+func (cc *Caller) ConfigByIndex(opts *bind.CallOpts, configIndex uint64) (BatchConfig, error) {
+	key := cacheKey{name: cacheConfig, index: configIndex}
+	if v, ok := cc.Cache.get(key); ok {
+		return v.(BatchConfig), nil
+	}
+	config, err := cc.ConfigContract.GetConfigByIndex(opts, configIndex)
+	if err != nil {
+		return BatchConfig{}, err
+	}
+	cc.Cache.set(key, config, true)
+	return config, nil
+}
+
+// HasEpochKey checks if the epoch key for the given batch has been submitted to the decryption
+// oracle. Once submitted, keys can't be removed, so positive answers are cached permanently.
+func (cc *Caller) HasEpochKey(opts *bind.CallOpts, batchIndex uint64) (bool, error) {
+	key := cacheKey{name: cacheHasEpochKey, index: batchIndex}
+	if v, ok := cc.Cache.get(key); ok {
+		return v.(bool), nil
+	}
+	submitted, err := cc.DecryptionOracleContract.HasEpochKey(opts, batchIndex)
+	if err != nil {
+		return false, err
+	}
+	cc.Cache.set(key, submitted, submitted)
+	return submitted, nil
+}
+
+// WatchCacheInvalidations subscribes to the events that change cached values and updates the
+// cache accordingly. It requires a websocket connection to the main chain and runs until the
+// context is canceled or the subscription fails.
+func (cc *Caller) WatchCacheInvalidations(ctx context.Context) error {
+	opts := &bind.WatchOpts{Context: ctx}
+	scheduled := make(chan *ConfigContractConfigScheduled)
+	unscheduled := make(chan *ConfigContractConfigUnscheduled)
+	submitted := make(chan *DecryptionOracleContractEpochKeySubmitted)
+
+	var subs []event.Subscription
+	defer func() {
+		for _, sub := range subs {
+			sub.Unsubscribe()
+		}
+	}()
+	sub, err := cc.ConfigContract.WatchConfigScheduled(opts, scheduled)
+	if err != nil {
+		return errors.Wrap(err, "failed to watch ConfigScheduled events")
+	}
+	subs = append(subs, sub)
+	sub, err = cc.ConfigContract.WatchConfigUnscheduled(opts, unscheduled)
+	if err != nil {
+		return errors.Wrap(err, "failed to watch ConfigUnscheduled events")
+	}
+	subs = append(subs, sub)
+	if cc.DecryptionOracleContract != nil {
+		sub, err = cc.DecryptionOracleContract.WatchEpochKeySubmitted(opts, submitted, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to watch EpochKeySubmitted events")
+		}
+		subs = append(subs, sub)
+	}
+
+	errs := make(chan error, len(subs))
+	for _, sub := range subs {
+		go func(sub event.Subscription) {
+			if err, ok := <-sub.Err(); ok && err != nil {
+				errs <- err
+			}
+		}(sub)
+	}
+
+	for {
+		select {
+		case ev := <-scheduled:
+			cc.Cache.invalidate(cacheNumConfigs, 0)
+			if ev.NumConfigs > 0 {
+				cc.Cache.invalidate(cacheConfig, ev.NumConfigs-1)
+			}
+		case ev := <-unscheduled:
+			cc.InvalidateConfigs(ev.NumConfigs)
+		case ev := <-submitted:
+			cc.Cache.set(cacheKey{name: cacheHasEpochKey, index: ev.BatchIndex}, true, true)
+		case err := <-errs:
+			return errors.Wrap(err, "contract event subscription failed")
+		case <-ctx.Done():
+			log.Printf("Stopped watching contract events for cache invalidation")
+			return nil
+		}
+	}
+}
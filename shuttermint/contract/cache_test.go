@@ -0,0 +1,69 @@
+package contract
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewCache(10 * time.Second)
+	c.now = func() time.Time { return now }
+
+	key := cacheKey{name: "x", index: 1}
+	_, ok := c.get(key)
+	assert.Assert(t, !ok)
+
+	c.set(key, 5, false)
+	v, ok := c.get(key)
+	assert.Assert(t, ok)
+	assert.Equal(t, v, 5)
+
+	now = now.Add(10 * time.Second)
+	_, ok = c.get(key)
+	assert.Assert(t, !ok)
+
+	c.set(key, 6, true)
+	now = now.Add(time.Hour)
+	v, ok = c.get(key)
+	assert.Assert(t, ok)
+	assert.Equal(t, v, 6)
+
+	hits, misses := c.Stats()
+	assert.Equal(t, hits, uint64(2))
+	assert.Equal(t, misses, uint64(2))
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := NewCache(time.Minute)
+	for i := uint64(0); i < 5; i++ {
+		c.set(cacheKey{name: cacheConfig, index: i}, i, true)
+		c.set(cacheKey{name: cacheHasEpochKey, index: i}, true, true)
+	}
+	c.invalidate(cacheConfig, 3)
+	for i := uint64(0); i < 5; i++ {
+		_, ok := c.get(cacheKey{name: cacheConfig, index: i})
+		assert.Equal(t, ok, i < 3)
+		_, ok = c.get(cacheKey{name: cacheHasEpochKey, index: i})
+		assert.Assert(t, ok)
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	var c *Cache
+	c.set(cacheKey{name: "x"}, 1, true)
+	_, ok := c.get(cacheKey{name: "x"})
+	assert.Assert(t, !ok)
+	c.invalidate("x", 0)
+
+	// a zero TTL only disables caching of mutable values
+	c = NewCache(0)
+	c.set(cacheKey{name: "x"}, 1, false)
+	_, ok = c.get(cacheKey{name: "x"})
+	assert.Assert(t, !ok)
+	c.set(cacheKey{name: "x"}, 1, true)
+	_, ok = c.get(cacheKey{name: "x"})
+	assert.Assert(t, ok)
+}
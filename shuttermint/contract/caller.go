@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 
@@ -24,6 +25,53 @@ type Caller struct {
 	ExecutorContract     *ExecutorContract
 	DepositContract      *DepositContract
 	KeyperSlasher        *KeyperSlasher
+
+	DecryptionOracleContract *DecryptionOracleContract
+
+	// ExecutorRoutes optionally assigns batches to different executor contracts. If empty, all
+	// batches are executed by ExecutorContract.
+	ExecutorRoutes ExecutorRoutes
+
+	// Cache caches the results of some contract calls. It may be nil to disable caching.
+	Cache *Cache
+
+	// EventSources are the addresses of the contracts whose events the keyper observes. If set,
+	// the events are fetched with a single log query instead of one per event type.
+	EventSources EventSources
+
+	// HeaderReader optionally reads block headers instead of Ethclient, e.g. from a cache. It may
+	// be nil.
+	HeaderReader HeaderReader
+
+	// GasPriceOracle determines the fees of our transactions. If nil, the gas price suggested by
+	// the node is used, see gaspricer.NodeOracle.
+	GasPriceOracle gaspricer.Oracle
+}
+
+// HeaderReader reads main chain block headers.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Headers returns the HeaderReader if set and Ethclient otherwise.
+func (cc *Caller) Headers() HeaderReader {
+	if cc.HeaderReader != nil {
+		return cc.HeaderReader
+	}
+	return cc.Ethclient
+}
+
+// EventSources are the addresses of the contracts emitting the events observed on the main chain.
+type EventSources struct {
+	Batcher       common.Address
+	Deposit       common.Address
+	KeyperSlasher common.Address
+}
+
+// IsSet checks if all addresses are set.
+func (s EventSources) IsSet() bool {
+	zero := common.Address{}
+	return s.Batcher != zero && s.Deposit != zero && s.KeyperSlasher != zero
 }
 
 // NewCaller creates a new ContractCaller.
@@ -36,6 +84,7 @@ func NewCaller(
 	executorContract *ExecutorContract,
 	depositContract *DepositContract,
 	keyperSlasher *KeyperSlasher,
+	decryptionOracleContract *DecryptionOracleContract,
 ) Caller {
 	return Caller{
 		Ethclient:  ethcl,
@@ -47,6 +96,8 @@ func NewCaller(
 		ExecutorContract:     executorContract,
 		DepositContract:      depositContract,
 		KeyperSlasher:        keyperSlasher,
+
+		DecryptionOracleContract: decryptionOracleContract,
 	}
 }
 
@@ -55,9 +106,32 @@ func (cc *Caller) Address() common.Address {
 	return crypto.PubkeyToAddress(cc.signingKey.PublicKey)
 }
 
-// Auth returns a new transactor with initialized key, nonce, and gas price.
-func (cc *Caller) Auth() (*bind.TransactOpts, error) {
-	chainID, err := cc.Ethclient.ChainID(context.Background())
+// WithSigningKey returns a copy of the caller sending transactions from the account of the given
+// key instead.
+func (cc Caller) WithSigningKey(signingKey *ecdsa.PrivateKey) *Caller {
+	cc.signingKey = signingKey
+	return &cc
+}
+
+// ExecutorFor returns the executor contract responsible for the given batch.
+func (cc *Caller) ExecutorFor(batchIndex uint64) Executor {
+	if executor := cc.ExecutorRoutes.For(batchIndex); executor != nil {
+		return executor
+	}
+	return cc.ExecutorContract
+}
+
+// NumExecutionHalfSteps returns the number of execution half steps across all executor contracts.
+func (cc *Caller) NumExecutionHalfSteps(opts *bind.CallOpts) (uint64, error) {
+	if len(cc.ExecutorRoutes) == 0 {
+		return cc.ExecutorContract.NumExecutionHalfSteps(opts)
+	}
+	return cc.ExecutorRoutes.NumExecutionHalfSteps(opts)
+}
+
+// Auth returns a new transactor with initialized key, nonce, and fees.
+func (cc *Caller) Auth(ctx context.Context) (*bind.TransactOpts, error) {
+	chainID, err := cc.Ethclient.ChainID(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -67,17 +141,20 @@ func (cc *Caller) Auth() (*bind.TransactOpts, error) {
 		return nil, err
 	}
 
-	nonce, err := cc.Ethclient.PendingNonceAt(context.Background(), cc.Address())
+	nonce, err := cc.Ethclient.PendingNonceAt(ctx, cc.Address())
 	if err != nil {
 		return nil, err
 	}
 	auth.Nonce = big.NewInt(int64(nonce))
 
-	gasPrice, err := cc.Ethclient.SuggestGasPrice(context.Background())
+	oracle := cc.GasPriceOracle
+	if oracle == nil {
+		oracle = gaspricer.NodeOracle{}
+	}
+	fees, err := oracle.Fees(ctx, cc.Ethclient)
 	if err != nil {
 		return nil, err
 	}
-	gasPrice = gaspricer.Adjust(gasPrice)
-	auth.GasPrice = gasPrice
+	fees.Apply(auth)
 	return auth, nil
 }
@@ -0,0 +1,192 @@
+package contract
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// ConfigReader reads the batch configs from the config contract. *ConfigContract implements it.
+type ConfigReader interface {
+	NumConfigs(opts *bind.CallOpts) (uint64, error)
+	GetConfigByIndex(opts *bind.CallOpts, configIndex uint64) (BatchConfig, error)
+}
+
+// EonKeyReader reads the eon public keys the keypers have voted for from the key broadcast
+// contract. *KeyBroadcastContract implements it.
+type EonKeyReader interface {
+	GetBestKey(opts *bind.CallOpts, startBatchIndex uint64) ([]byte, error)
+}
+
+// EncryptionTarget holds everything needed to encrypt a transaction for a batch.
+type EncryptionTarget struct {
+	BatchIndex         uint64
+	Config             BatchConfig // the config the batch belongs to
+	EonStartBatchIndex uint64      // the start batch index of the eon whose key is used
+	EonPublicKey       *shcrypto.EonPublicKey
+	Epoch              uint64
+	EpochID            *shcrypto.EpochID
+	EncryptionVersion  uint8 // the version of the encryption scheme the keypers accept
+}
+
+// Encrypt encrypts the given message for the target batch, reading the randomness from r.
+func (t EncryptionTarget) Encrypt(message []byte, r io.Reader) (*shcrypto.EncryptedMessage, error) {
+	return shcrypto.EncryptWithVersion(t.EncryptionVersion, message, t.EonPublicKey, t.EpochID, r)
+}
+
+// Seal encrypts the given message like Encrypt and wraps it in an envelope carrying the target's
+// eon and epoch ID and the given sender hint, which may be nil.
+func (t EncryptionTarget) Seal(message []byte, senderHint []byte, r io.Reader) (*shcrypto.Envelope, error) {
+	if len(senderHint) > shcrypto.MaxSenderHintSize {
+		return nil, errors.Errorf("sender hint of %d bytes exceeds the maximum of %d", len(senderHint), shcrypto.MaxSenderHintSize)
+	}
+	m, err := t.Encrypt(message, r)
+	if err != nil {
+		return nil, err
+	}
+	return &shcrypto.Envelope{
+		EonStartBatchIndex: t.EonStartBatchIndex,
+		EpochID:            t.EpochID,
+		SenderHint:         senderHint,
+		Message:            m,
+	}, nil
+}
+
+// EncryptionTargetFinder determines the batch, the eon public key and the epoch to encrypt a
+// transaction with, so that integrators don't have to reimplement the mapping of blocks to
+// batches, batches to eons and batches to epochs.
+type EncryptionTargetFinder struct {
+	Configs ConfigReader
+	EonKeys EonKeyReader
+	// EonStartBatchIndices are the start batch indices of the eons keys have been voted for, see
+	// VotedStartBatchIndices. An eon is used from its start batch index until a later one starts.
+	EonStartBatchIndices []uint64
+	// EpochMapping maps batches to epochs like the one in the shuttermint batch config. The zero
+	// value maps each batch to the epoch with the same index.
+	EpochMapping protocol.EpochMapping
+	// EncryptionVersion is the version of the encryption scheme in the shuttermint batch config.
+	EncryptionVersion uint8
+}
+
+// ForBlock returns the target for the batch that is open at the given main chain block. Its
+// transactions are executed once the batch has ended, i.e. at Config.BatchEndBlock(BatchIndex)
+// at the earliest. The block may be in the future, as long as the configs and the eon key for it
+// are known already.
+func (f *EncryptionTargetFinder) ForBlock(ctx context.Context, blockNumber uint64) (EncryptionTarget, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	config, err := f.configForBlock(opts, blockNumber)
+	if err != nil {
+		return EncryptionTarget{}, err
+	}
+	batchIndex := config.BatchIndex(blockNumber)
+
+	eonStart, ok := f.eonStartBatchIndex(batchIndex)
+	if !ok {
+		return EncryptionTarget{}, errors.Errorf("no eon starts at or before batch %d", batchIndex)
+	}
+	keyBytes, err := f.EonKeys.GetBestKey(opts, eonStart)
+	if err != nil {
+		return EncryptionTarget{}, errors.Wrapf(err, "failed to query eon key for start batch %d", eonStart)
+	}
+	if len(keyBytes) == 0 {
+		return EncryptionTarget{}, errors.Errorf("eon key for start batch %d not broadcast yet", eonStart)
+	}
+	key := new(shcrypto.EonPublicKey)
+	if err := key.Unmarshal(keyBytes); err != nil {
+		return EncryptionTarget{}, errors.Wrapf(err, "invalid eon key for start batch %d", eonStart)
+	}
+
+	epoch := uint64(f.EpochMapping.Epoch(protocol.BatchIndex(batchIndex)))
+	return EncryptionTarget{
+		BatchIndex:         batchIndex,
+		Config:             config,
+		EonStartBatchIndex: eonStart,
+		EonPublicKey:       key,
+		Epoch:              epoch,
+		EpochID:            shcrypto.ComputeEpochID(epoch),
+		EncryptionVersion:  f.EncryptionVersion,
+	}, nil
+}
+
+// ForTime returns the target for the batch that is open at the given time, estimating the block
+// number from the given head block and the average block time.
+func (f *EncryptionTargetFinder) ForTime(
+	ctx context.Context, head *types.Header, blockTime time.Duration, t time.Time,
+) (EncryptionTarget, error) {
+	return f.ForBlock(ctx, EstimateBlockNumber(head, blockTime, t))
+}
+
+// EstimateBlockNumber estimates the number of the block that will be mined at the given time,
+// based on the given head block and the average block time. Times before the head block map to
+// the head block.
+func EstimateBlockNumber(head *types.Header, blockTime time.Duration, t time.Time) uint64 {
+	headTime := time.Unix(int64(head.Time), 0)
+	if blockTime <= 0 || !t.After(headTime) {
+		return head.Number.Uint64()
+	}
+	return head.Number.Uint64() + uint64(t.Sub(headTime)/blockTime)
+}
+
+// configForBlock returns the latest config starting at or before the given block.
+func (f *EncryptionTargetFinder) configForBlock(opts *bind.CallOpts, blockNumber uint64) (BatchConfig, error) {
+	numConfigs, err := f.Configs.NumConfigs(opts)
+	if err != nil {
+		return BatchConfig{}, errors.Wrap(err, "failed to query number of configs")
+	}
+	for i := numConfigs; i >= 1; i-- {
+		config, err := f.Configs.GetConfigByIndex(opts, i-1)
+		if err != nil {
+			return BatchConfig{}, errors.Wrapf(err, "failed to query config %d", i-1)
+		}
+		if config.StartBlockNumber > blockNumber {
+			continue
+		}
+		if !config.IsActive() {
+			return BatchConfig{}, errors.Errorf("batching is paused at block %d (config %d)", blockNumber, i-1)
+		}
+		return config, nil
+	}
+	return BatchConfig{}, errors.Errorf("no config starts at or before block %d", blockNumber)
+}
+
+// eonStartBatchIndex returns the latest eon start batch index not after the given batch.
+func (f *EncryptionTargetFinder) eonStartBatchIndex(batchIndex uint64) (uint64, bool) {
+	start, ok := uint64(0), false
+	for _, s := range f.EonStartBatchIndices {
+		if s <= batchIndex && (!ok || s > start) {
+			start, ok = s, true
+		}
+	}
+	return start, ok
+}
+
+// VotedStartBatchIndices returns the start batch indices of the eons keys have been voted for in
+// the key broadcast contract from the given block on, in ascending order.
+func VotedStartBatchIndices(ctx context.Context, kbc *KeyBroadcastContract, fromBlock uint64) ([]uint64, error) {
+	it, err := kbc.FilterVoted(&bind.FilterOpts{Start: fromBlock, Context: ctx}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query key broadcast votes")
+	}
+	defer it.Close()
+	seen := make(map[uint64]struct{})
+	for it.Next() {
+		seen[it.Event.StartBatchIndex] = struct{}{}
+	}
+	if err := it.Error(); err != nil {
+		return nil, errors.Wrap(err, "failed to query key broadcast votes")
+	}
+	indices := make([]uint64, 0, len(seen))
+	for s := range seen {
+		indices = append(indices, s)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return indices, nil
+}
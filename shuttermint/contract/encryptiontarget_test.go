@@ -0,0 +1,101 @@
+package contract
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+type testConfigReader []BatchConfig
+
+func (r testConfigReader) NumConfigs(*bind.CallOpts) (uint64, error) {
+	return uint64(len(r)), nil
+}
+
+func (r testConfigReader) GetConfigByIndex(_ *bind.CallOpts, configIndex uint64) (BatchConfig, error) {
+	return r[configIndex], nil
+}
+
+type testEonKeyReader map[uint64][]byte
+
+func (r testEonKeyReader) GetBestKey(_ *bind.CallOpts, startBatchIndex uint64) ([]byte, error) {
+	return r[startBatchIndex], nil
+}
+
+func TestEncryptionTargetFinder(t *testing.T) {
+	p, err := shcrypto.RandomPolynomial(rand.Reader, 0)
+	assert.NilError(t, err)
+	eonKey := shcrypto.ComputeEonPublicKey([]*shcrypto.Gammas{p.Gammas()})
+
+	f := &EncryptionTargetFinder{
+		Configs: testConfigReader{
+			{},
+			{StartBatchIndex: 0, StartBlockNumber: 100, BatchSpan: 10},
+			{StartBatchIndex: 5, StartBlockNumber: 150, BatchSpan: 0},
+			{StartBatchIndex: 5, StartBlockNumber: 200, BatchSpan: 5},
+		},
+		EonKeys:              testEonKeyReader{3: eonKey.Marshal()},
+		EonStartBatchIndices: []uint64{0, 3, 9},
+		EpochMapping:         protocol.EpochMapping{Offset: 1000, Stride: 2},
+		EncryptionVersion:    shcrypto.Version1,
+	}
+	ctx := context.Background()
+
+	target, err := f.ForBlock(ctx, 135)
+	assert.NilError(t, err)
+	assert.Equal(t, target.BatchIndex, uint64(3))
+	assert.Equal(t, target.Config.BatchEndBlock(3), uint64(140))
+	assert.Equal(t, target.EonStartBatchIndex, uint64(3))
+	assert.Assert(t, target.EonPublicKey.Equal(eonKey))
+	assert.Equal(t, target.Epoch, uint64(1006))
+	assert.Assert(t, target.EpochID.Equal(shcrypto.ComputeEpochID(1006)))
+
+	// batches of later configs continue the batch indices of earlier ones
+	target, err = f.ForBlock(ctx, 212)
+	assert.NilError(t, err)
+	assert.Equal(t, target.BatchIndex, uint64(7))
+	assert.Equal(t, target.EonStartBatchIndex, uint64(3))
+
+	_, err = f.ForBlock(ctx, 99)
+	assert.ErrorContains(t, err, "batching is paused at block 99 (config 0)")
+	_, err = f.ForBlock(ctx, 160)
+	assert.ErrorContains(t, err, "batching is paused at block 160 (config 2)")
+	_, err = (&EncryptionTargetFinder{Configs: testConfigReader{}}).ForBlock(ctx, 99)
+	assert.ErrorContains(t, err, "no config starts at or before block 99")
+	_, err = f.ForBlock(ctx, 125)
+	assert.ErrorContains(t, err, "eon key for start batch 0 not broadcast yet")
+	_, err = f.ForBlock(ctx, 222)
+	assert.ErrorContains(t, err, "eon key for start batch 9 not broadcast yet")
+
+	target, err = f.ForBlock(ctx, 135)
+	assert.NilError(t, err)
+	encrypted, err := target.Encrypt([]byte("hello"), rand.Reader)
+	assert.NilError(t, err)
+	decoded := &shcrypto.EncryptedMessage{}
+	assert.NilError(t, decoded.Unmarshal(encrypted.Marshal()))
+	assert.Equal(t, decoded.Version, shcrypto.Version1)
+
+	envelope, err := target.Seal([]byte("hello"), []byte("sender"), rand.Reader)
+	assert.NilError(t, err)
+	unsealed, err := shcrypto.UnmarshalCiphertext(envelope.Marshal())
+	assert.NilError(t, err)
+	assert.NilError(t, unsealed.Validate(3, shcrypto.ComputeEpochID(1006), shcrypto.Version1))
+	assert.DeepEqual(t, unsealed.SenderHint, []byte("sender"))
+}
+
+func TestEstimateBlockNumber(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(100), Time: 1000}
+	assert.Equal(t, EstimateBlockNumber(head, 5*time.Second, time.Unix(1000, 0)), uint64(100))
+	assert.Equal(t, EstimateBlockNumber(head, 5*time.Second, time.Unix(900, 0)), uint64(100))
+	assert.Equal(t, EstimateBlockNumber(head, 5*time.Second, time.Unix(1052, 0)), uint64(110))
+	assert.Equal(t, EstimateBlockNumber(head, 0, time.Unix(1052, 0)), uint64(100))
+}
@@ -0,0 +1,194 @@
+package contract
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Executor is the interface to the executor contract used by the keyper. It is implemented by
+// the generated ExecutorContract binding as well as by adapters for other versions of the
+// contract.
+type Executor interface {
+	ExecuteCipherBatch(opts *bind.TransactOpts, batchIndex uint64, cipherBatchHash [32]byte, transactions [][]byte, keyperIndex uint64) (*types.Transaction, error)
+	ExecutePlainBatch(opts *bind.TransactOpts, batchIndex uint64, transactions [][]byte) (*types.Transaction, error)
+	SkipCipherExecution(opts *bind.TransactOpts, batchIndex uint64) (*types.Transaction, error)
+	NumExecutionHalfSteps(opts *bind.CallOpts) (uint64, error)
+	GetReceipt(opts *bind.CallOpts, halfStep uint64) (CipherExecutionReceipt, error)
+}
+
+var _ Executor = &ExecutorContract{}
+
+// CurrentExecutorVersion is the version of the executor contract the bindings in this package
+// have been generated for.
+const CurrentExecutorVersion = "v1"
+
+// ExecutorABIs maps executor contract versions to their ABI. When the executor contract is
+// upgraded, the ABI of the previous version should be added here so that keypers can keep
+// executing batches on the old contract during the migration.
+var ExecutorABIs = map[string]string{
+	CurrentExecutorVersion: ExecutorContractABI,
+}
+
+// NewExecutor creates an Executor for the given version of the executor contract deployed at
+// address. The empty version denotes the current one.
+func NewExecutor(address common.Address, version string, backend bind.ContractBackend) (Executor, error) {
+	if version == "" || version == CurrentExecutorVersion {
+		return NewExecutorContract(address, backend)
+	}
+	abiJSON, ok := ExecutorABIs[version]
+	if !ok {
+		return nil, errors.Errorf("unknown executor contract version %s", version)
+	}
+	return newABIExecutor(address, abiJSON, backend)
+}
+
+// abiExecutor implements Executor for executor contracts we don't have generated bindings for.
+// It adapts the calls to the methods and arguments present in the contract's ABI.
+type abiExecutor struct {
+	abi      abi.ABI
+	contract *bind.BoundContract
+}
+
+func newABIExecutor(address common.Address, abiJSON string, backend bind.ContractBackend) (*abiExecutor, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse executor contract ABI")
+	}
+	for _, name := range []string{"executeCipherBatch", "executePlainBatch", "skipCipherExecution", "numExecutionHalfSteps"} {
+		if _, ok := parsed.Methods[name]; !ok {
+			return nil, errors.Errorf("executor contract ABI lacks method %s", name)
+		}
+	}
+	return &abiExecutor{
+		abi:      parsed,
+		contract: bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}, nil
+}
+
+func (e *abiExecutor) ExecuteCipherBatch(opts *bind.TransactOpts, batchIndex uint64, cipherBatchHash [32]byte, transactions [][]byte, keyperIndex uint64) (*types.Transaction, error) {
+	switch n := len(e.abi.Methods["executeCipherBatch"].Inputs); n {
+	case 4:
+		return e.contract.Transact(opts, "executeCipherBatch", batchIndex, cipherBatchHash, transactions, keyperIndex)
+	case 3:
+		// older versions did not check that the sender is the keyper with the given index
+		return e.contract.Transact(opts, "executeCipherBatch", batchIndex, cipherBatchHash, transactions)
+	default:
+		return nil, errors.Errorf("unsupported executeCipherBatch signature with %d arguments", n)
+	}
+}
+
+func (e *abiExecutor) ExecutePlainBatch(opts *bind.TransactOpts, batchIndex uint64, transactions [][]byte) (*types.Transaction, error) {
+	return e.contract.Transact(opts, "executePlainBatch", batchIndex, transactions)
+}
+
+func (e *abiExecutor) SkipCipherExecution(opts *bind.TransactOpts, batchIndex uint64) (*types.Transaction, error) {
+	return e.contract.Transact(opts, "skipCipherExecution", batchIndex)
+}
+
+func (e *abiExecutor) NumExecutionHalfSteps(opts *bind.CallOpts) (uint64, error) {
+	var out []interface{}
+	err := e.contract.Call(opts, &out, "numExecutionHalfSteps")
+	if err != nil {
+		return 0, err
+	}
+	return *abi.ConvertType(out[0], new(uint64)).(*uint64), nil
+}
+
+func (e *abiExecutor) GetReceipt(opts *bind.CallOpts, halfStep uint64) (CipherExecutionReceipt, error) {
+	if _, ok := e.abi.Methods["getReceipt"]; !ok {
+		// versions without receipts don't allow us to tell who executed the batch
+		return CipherExecutionReceipt{HalfStep: halfStep}, nil
+	}
+	var out []interface{}
+	err := e.contract.Call(opts, &out, "getReceipt", halfStep)
+	if err != nil {
+		return CipherExecutionReceipt{}, err
+	}
+	return *abi.ConvertType(out[0], new(CipherExecutionReceipt)).(*CipherExecutionReceipt), nil
+}
+
+// ExecutorRoute assigns an executor contract to all batches starting at StartBatchIndex.
+type ExecutorRoute struct {
+	StartBatchIndex uint64
+	Address         common.Address
+	Executor        Executor
+}
+
+// ExecutorRoutes selects the executor contract responsible for a batch. This allows to keep
+// executing batches on the old contract while migrating to a new one.
+type ExecutorRoutes []ExecutorRoute
+
+// NewExecutorRoutes creates ExecutorRoutes from the given routes, which may be in any order.
+func NewExecutorRoutes(routes ...ExecutorRoute) (ExecutorRoutes, error) {
+	rs := append(ExecutorRoutes{}, routes...)
+	sort.Slice(rs, func(i, j int) bool { return rs[i].StartBatchIndex < rs[j].StartBatchIndex })
+	for i := 1; i < len(rs); i++ {
+		if rs[i].StartBatchIndex == rs[i-1].StartBatchIndex {
+			return nil, errors.Errorf("multiple executor contracts starting at batch %d", rs[i].StartBatchIndex)
+		}
+	}
+	return rs, nil
+}
+
+// For returns the executor contract responsible for the given batch or nil if there is none.
+func (rs ExecutorRoutes) For(batchIndex uint64) Executor {
+	i := sort.Search(len(rs), func(i int) bool { return rs[i].StartBatchIndex > batchIndex })
+	if i == 0 {
+		return nil
+	}
+	return rs[i-1].Executor
+}
+
+// NumExecutionHalfSteps returns the number of execution half steps, i.e., the maximum over all
+// executor contracts. Executor contracts take over from their predecessor by setting their
+// half step counter at the start of their batch range.
+func (rs ExecutorRoutes) NumExecutionHalfSteps(opts *bind.CallOpts) (uint64, error) {
+	var res uint64
+	for _, r := range rs {
+		n, err := r.Executor.NumExecutionHalfSteps(opts)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to query executor contract at %s", r.Address.Hex())
+		}
+		if n > res {
+			res = n
+		}
+	}
+	return res, nil
+}
+
+// ParseExecutorRoute parses an executor route from a string of the form
+// "startBatchIndex:address[:version]".
+func ParseExecutorRoute(s string, backend bind.ContractBackend) (ExecutorRoute, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ExecutorRoute{}, errors.Errorf("invalid executor route %q, expected startBatchIndex:address[:version]", s)
+	}
+	startBatchIndex, ok := new(big.Int).SetString(parts[0], 10)
+	if !ok || !startBatchIndex.IsUint64() {
+		return ExecutorRoute{}, errors.Errorf("invalid start batch index in executor route %q", s)
+	}
+	if !common.IsHexAddress(parts[1]) {
+		return ExecutorRoute{}, errors.Errorf("invalid address in executor route %q", s)
+	}
+	address := common.HexToAddress(parts[1])
+	version := ""
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+	executor, err := NewExecutor(address, version, backend)
+	if err != nil {
+		return ExecutorRoute{}, err
+	}
+	return ExecutorRoute{
+		StartBatchIndex: startBatchIndex.Uint64(),
+		Address:         address,
+		Executor:        executor,
+	}, nil
+}
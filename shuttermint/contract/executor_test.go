@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+)
+
+func TestExecutorRoutes(t *testing.T) {
+	e1 := &ExecutorContract{}
+	e2 := &ExecutorContract{}
+	routes, err := NewExecutorRoutes(
+		ExecutorRoute{StartBatchIndex: 100, Executor: e2},
+		ExecutorRoute{StartBatchIndex: 10, Executor: e1},
+	)
+	assert.NilError(t, err)
+	assert.Assert(t, routes.For(9) == nil)
+	assert.Assert(t, routes.For(10) == e1)
+	assert.Assert(t, routes.For(99) == e1)
+	assert.Assert(t, routes.For(100) == e2)
+	assert.Assert(t, routes.For(1000) == e2)
+
+	cc := Caller{ExecutorContract: e1}
+	assert.Assert(t, cc.ExecutorFor(1000) == e1)
+	cc.ExecutorRoutes = routes
+	assert.Assert(t, cc.ExecutorFor(1000) == e2)
+
+	_, err = NewExecutorRoutes(
+		ExecutorRoute{StartBatchIndex: 10, Executor: e1},
+		ExecutorRoute{StartBatchIndex: 10, Executor: e2},
+	)
+	assert.ErrorContains(t, err, "multiple executor contracts")
+}
+
+func TestParseExecutorRoute(t *testing.T) {
+	address := "0x6ab87f620cb46764C6466e9Ca7Ac193711855D09"
+	route, err := ParseExecutorRoute("5:"+address, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, route.StartBatchIndex, uint64(5))
+	assert.Equal(t, route.Address, common.HexToAddress(address))
+	_, ok := route.Executor.(*ExecutorContract)
+	assert.Assert(t, ok)
+
+	for _, s := range []string{"", "5", "x:" + address, "5:0x12", "5:" + address + ":v0", "5:" + address + ":v1:x"} {
+		_, err := ParseExecutorRoute(s, nil)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+// removeMethod returns the given ABI without the named method.
+func removeMethod(t *testing.T, abiJSON string, name string) string {
+	t.Helper()
+	var entries []map[string]interface{}
+	assert.NilError(t, json.Unmarshal([]byte(abiJSON), &entries))
+	var res []map[string]interface{}
+	for _, e := range entries {
+		if e["name"] != name {
+			res = append(res, e)
+		}
+	}
+	b, err := json.Marshal(res)
+	assert.NilError(t, err)
+	return string(b)
+}
+
+func TestABIExecutor(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	assert.NilError(t, err)
+	alloc := make(core.GenesisAlloc)
+	alloc[auth.From] = core.GenesisAccount{Balance: big.NewInt(100000000000000000)}
+	blockchain := backends.NewSimulatedBackend(alloc, 8000000)
+	defer blockchain.Close()
+
+	auth.GasLimit = 5000000
+	address, _, _, err := DeployExecutorContract(auth, blockchain, common.Address{}, common.Address{}, common.Address{})
+	assert.NilError(t, err)
+	blockchain.Commit()
+
+	ExecutorABIs["test"] = ExecutorContractABI
+	ExecutorABIs["noreceipts"] = removeMethod(t, ExecutorContractABI, "getReceipt")
+	ExecutorABIs["broken"] = removeMethod(t, ExecutorContractABI, "executePlainBatch")
+	defer func() {
+		delete(ExecutorABIs, "test")
+		delete(ExecutorABIs, "noreceipts")
+		delete(ExecutorABIs, "broken")
+	}()
+
+	executor, err := NewExecutor(address, "test", blockchain)
+	assert.NilError(t, err)
+	_, ok := executor.(*abiExecutor)
+	assert.Assert(t, ok)
+	n, err := executor.NumExecutionHalfSteps(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, n, uint64(0))
+	_, err = executor.GetReceipt(nil, 0)
+	assert.NilError(t, err)
+
+	executor, err = NewExecutor(address, "noreceipts", blockchain)
+	assert.NilError(t, err)
+	receipt, err := executor.GetReceipt(nil, 4)
+	assert.NilError(t, err)
+	assert.Equal(t, receipt.HalfStep, uint64(4))
+	assert.Assert(t, !receipt.Executed)
+
+	_, err = NewExecutor(address, "broken", blockchain)
+	assert.ErrorContains(t, err, "executePlainBatch")
+}
@@ -0,0 +1,160 @@
+package contract
+
+// This file checks that the contracts we talk to match the bindings in this package.
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// CodeReader is used to fetch the code of deployed contracts.
+type CodeReader interface {
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// ContractVersion describes a contract as this package has been built against.
+type ContractVersion struct {
+	Name string
+	ABI  string
+	// CodeHashes are the hashes of the runtime code of known deployments of this version.
+	// Contracts with immutable variables have different code for different constructor
+	// arguments, so each deployment may need its own entry.
+	CodeHashes []common.Hash
+}
+
+// ABIVersion returns a short fingerprint of the contract's ABI.
+func (v ContractVersion) ABIVersion() string {
+	return crypto.Keccak256Hash([]byte(v.ABI)).Hex()[:10]
+}
+
+// KnownVersions contains the versions of the contracts used by the keyper.
+var KnownVersions = map[string]*ContractVersion{
+	"BatcherContract":          {Name: "BatcherContract", ABI: BatcherContractABI},
+	"ConfigContract":           {Name: "ConfigContract", ABI: ConfigContractABI},
+	"DecryptionOracleContract": {Name: "DecryptionOracleContract", ABI: DecryptionOracleContractABI},
+	"DepositContract":          {Name: "DepositContract", ABI: DepositContractABI},
+	"ExecutorContract":         {Name: "ExecutorContract", ABI: ExecutorContractABI},
+	"KeyBroadcastContract":     {Name: "KeyBroadcastContract", ABI: KeyBroadcastContractABI},
+	"KeyperSlasher":            {Name: "KeyperSlasher", ABI: KeyperSlasherABI},
+}
+
+// RegisterCodeHash adds the code hash of a known deployment of the named contract.
+func RegisterCodeHash(name string, codeHash common.Hash) error {
+	v, ok := KnownVersions[name]
+	if !ok {
+		return errors.Errorf("unknown contract %s", name)
+	}
+	v.CodeHashes = append(v.CodeHashes, codeHash)
+	return nil
+}
+
+// VersionStatus is the result of checking a deployed contract.
+type VersionStatus int
+
+const (
+	// VersionKnown means the code hash matches a known deployment.
+	VersionKnown VersionStatus = iota
+	// VersionCompatible means the code is unknown, but implements all functions of our ABI.
+	VersionCompatible
+	// VersionIncompatible means the code lacks functions of our ABI.
+	VersionIncompatible
+)
+
+func (s VersionStatus) String() string {
+	switch s {
+	case VersionKnown:
+		return "known"
+	case VersionCompatible:
+		return "compatible"
+	case VersionIncompatible:
+		return "incompatible"
+	default:
+		return fmt.Sprintf("VersionStatus(%d)", int(s))
+	}
+}
+
+// VersionCheck is the result of checking the contract deployed at an address.
+type VersionCheck struct {
+	Name           string
+	Address        common.Address
+	CodeHash       common.Hash
+	Status         VersionStatus
+	MissingMethods []string
+}
+
+func (c VersionCheck) String() string {
+	s := fmt.Sprintf("%s at %s (code hash %s): %s", c.Name, c.Address.Hex(), c.CodeHash.Hex(), c.Status)
+	if len(c.MissingMethods) > 0 {
+		s += ", missing " + strings.Join(c.MissingMethods, ", ")
+	}
+	return s
+}
+
+// CheckVersion checks that the code deployed at the given address matches the named contract.
+func CheckVersion(ctx context.Context, client CodeReader, name string, address common.Address) (VersionCheck, error) {
+	v, ok := KnownVersions[name]
+	if !ok {
+		return VersionCheck{}, errors.Errorf("unknown contract %s", name)
+	}
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return VersionCheck{}, errors.Wrapf(err, "failed to fetch code of %s at %s", name, address.Hex())
+	}
+	if len(code) == 0 {
+		return VersionCheck{}, errors.Errorf("no contract code for %s at %s", name, address.Hex())
+	}
+
+	check := VersionCheck{Name: name, Address: address, CodeHash: crypto.Keccak256Hash(code)}
+	for _, h := range v.CodeHashes {
+		if h == check.CodeHash {
+			check.Status = VersionKnown
+			return check, nil
+		}
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(v.ABI))
+	if err != nil {
+		return VersionCheck{}, errors.Wrapf(err, "failed to parse ABI of %s", name)
+	}
+	pushed := pushedValues(code)
+	for _, m := range parsed.Methods {
+		selector := uint32(new(big.Int).SetBytes(m.ID).Uint64())
+		if !pushed[selector] {
+			check.MissingMethods = append(check.MissingMethods, m.Sig)
+		}
+	}
+	if len(check.MissingMethods) > 0 {
+		check.Status = VersionIncompatible
+	} else {
+		check.Status = VersionCompatible
+	}
+	return check, nil
+}
+
+// pushedValues returns the values of up to 4 bytes pushed by the given code. The function
+// dispatcher generated by solidity compares the call's selector to the selectors of all
+// functions, which have to be pushed to the stack for that. Selectors with leading zero bytes
+// may be pushed with fewer than 4 bytes.
+func pushedValues(code []byte) map[uint32]bool {
+	res := make(map[uint32]bool)
+	for pc := 0; pc < len(code); pc++ {
+		op := vm.OpCode(code[pc])
+		if op < vm.PUSH1 || op > vm.PUSH32 {
+			continue
+		}
+		n := int(op-vm.PUSH1) + 1
+		if n <= 4 && pc+n < len(code) {
+			res[uint32(new(big.Int).SetBytes(code[pc+1:pc+1+n]).Uint64())] = true
+		}
+		pc += n
+	}
+	return res
+}
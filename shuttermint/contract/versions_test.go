@@ -0,0 +1,70 @@
+package contract
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+)
+
+func TestPushedValues(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH4), 0x12, 0x34, 0x56, 0x78,
+		byte(vm.PUSH2), 0xab, 0xcd,
+		byte(vm.PUSH32),
+	}
+	// the data of the PUSH32 contains a PUSH1 that must be skipped
+	code = append(code, byte(vm.PUSH1), 0x99)
+	code = append(code, make([]byte, 30)...)
+	pushed := pushedValues(code)
+	assert.Assert(t, pushed[0x12345678])
+	assert.Assert(t, pushed[0xabcd])
+	assert.Assert(t, !pushed[0x99])
+	assert.Equal(t, len(pushed), 2)
+}
+
+func TestCheckVersion(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	assert.NilError(t, err)
+	alloc := make(core.GenesisAlloc)
+	alloc[auth.From] = core.GenesisAccount{Balance: big.NewInt(100000000000000000)}
+	blockchain := backends.NewSimulatedBackend(alloc, 8000000)
+	defer blockchain.Close()
+
+	auth.GasLimit = 5000000
+	configAddress, _, _, err := DeployConfigContract(auth, blockchain, 10)
+	assert.NilError(t, err)
+	blockchain.Commit()
+
+	check, err := CheckVersion(ctx, blockchain, "ConfigContract", configAddress)
+	assert.NilError(t, err)
+	assert.Equal(t, check.Status, VersionCompatible)
+	assert.Equal(t, len(check.MissingMethods), 0)
+
+	check, err = CheckVersion(ctx, blockchain, "ExecutorContract", configAddress)
+	assert.NilError(t, err)
+	assert.Equal(t, check.Status, VersionIncompatible)
+	assert.Assert(t, len(check.MissingMethods) > 0)
+
+	_, err = CheckVersion(ctx, blockchain, "ConfigContract", common.HexToAddress("0x1234"))
+	assert.ErrorContains(t, err, "no contract code")
+	_, err = CheckVersion(ctx, blockchain, "Unknown", configAddress)
+	assert.ErrorContains(t, err, "unknown contract")
+
+	codeHashes := KnownVersions["ConfigContract"].CodeHashes
+	defer func() { KnownVersions["ConfigContract"].CodeHashes = codeHashes }()
+	assert.NilError(t, RegisterCodeHash("ConfigContract", check.CodeHash))
+	check, err = CheckVersion(ctx, blockchain, "ConfigContract", configAddress)
+	assert.NilError(t, err)
+	assert.Equal(t, check.Status, VersionKnown)
+}
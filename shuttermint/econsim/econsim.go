@@ -0,0 +1,265 @@
+// Package econsim simulates the economics of a keyper set: the fees keypers earn by executing
+// batches, the gas they spend on executions, accusations and appeals, and the deposits lost to
+// slashing. It is a model to answer "what if" questions about the configuration parameters, not a
+// prediction.
+package econsim
+
+import (
+	"math/rand"
+
+	"github.com/pkg/errors"
+)
+
+// Params are the parameters of a simulation. Amounts of ETH are given in ETH, deposits in deposit
+// tokens.
+type Params struct {
+	NumKeypers    int
+	Threshold     int
+	NumFaulty     int     // number of faulty keypers
+	Deposit       float64 // deposit of each keyper
+	TokenPrice    float64 // price of a deposit token in ETH
+	SlashFraction float64 // fraction of the remaining deposit lost when being slashed
+
+	Days          int
+	BatchesPerDay int
+	Fee           float64 // fee paid to the executor of a batch
+	GasPrice      float64 // in gwei
+	ExecuteGas    uint64
+	AccuseGas     uint64
+	AppealGas     uint64
+	SlashGas      uint64
+
+	FaultRate           float64 // probability that a faulty keyper executes a batch incorrectly
+	FaultGain           float64 // profit a faulty keyper makes by executing a batch incorrectly
+	DetectionRate       float64 // probability that an incorrect execution is accused
+	FalseAccusationRate float64 // probability that faulty keypers accuse a correct execution
+
+	Runs int   // number of runs the results are averaged over
+	Seed int64 // seed of the random number generator
+}
+
+// Validate checks that the parameters are consistent.
+func (p Params) Validate() error {
+	if p.NumKeypers <= 0 {
+		return errors.New("number of keypers must be positive")
+	}
+	if p.Threshold <= 0 || p.Threshold > p.NumKeypers {
+		return errors.Errorf("threshold must be between 1 and %d", p.NumKeypers)
+	}
+	if p.NumFaulty < 0 || p.NumFaulty > p.NumKeypers {
+		return errors.Errorf("number of faulty keypers must be between 0 and %d", p.NumKeypers)
+	}
+	if p.Days <= 0 || p.BatchesPerDay <= 0 || p.Runs <= 0 {
+		return errors.New("days, batches per day and runs must be positive")
+	}
+	if p.Deposit < 0 || p.TokenPrice < 0 || p.Fee < 0 || p.GasPrice < 0 || p.FaultGain < 0 {
+		return errors.New("amounts and prices must not be negative")
+	}
+	probabilities := map[string]float64{
+		"slash fraction":        p.SlashFraction,
+		"fault rate":            p.FaultRate,
+		"detection rate":        p.DetectionRate,
+		"false accusation rate": p.FalseAccusationRate,
+	}
+	for name, v := range probabilities {
+		if v < 0 || v > 1 {
+			return errors.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	return nil
+}
+
+// Outcome is the average financial outcome of a group of keypers.
+type Outcome struct {
+	ETH     float64 // change of the ETH balance
+	Deposit float64 // remaining deposit
+	Net     float64 // change of the ETH balance plus the change of the deposit's value in ETH
+	Slashed float64 // fraction of keypers that have been slashed
+}
+
+// Snapshot is the state of the simulation at the end of a day, averaged over all runs.
+type Snapshot struct {
+	Day             int
+	Honest          Outcome // outcome per honest keyper
+	Faulty          Outcome // outcome per faulty keyper
+	Executions      float64 // batches executed
+	WrongExecutions float64 // batches executed incorrectly
+	Accusations     float64
+	Appeals         float64
+}
+
+type keyper struct {
+	faulty  bool
+	slashed bool
+	eth     float64
+	deposit float64
+}
+
+type run struct {
+	p       Params
+	rng     *rand.Rand
+	keypers []*keyper
+
+	executions, wrongExecutions, accusations, appeals int
+}
+
+// Simulate runs the simulation and returns a snapshot for each day.
+func Simulate(p Params) ([]Snapshot, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(p.Seed))
+	snapshots := make([]Snapshot, p.Days)
+	for i := 0; i < p.Runs; i++ {
+		r := newRun(p, rng)
+		for day := 0; day < p.Days; day++ {
+			for b := 0; b < p.BatchesPerDay; b++ {
+				r.batch()
+			}
+			r.addTo(&snapshots[day])
+		}
+	}
+	for day := range snapshots {
+		s := &snapshots[day]
+		s.Day = day + 1
+		runs := float64(p.Runs)
+		s.Honest.divide(runs)
+		s.Faulty.divide(runs)
+		s.Executions /= runs
+		s.WrongExecutions /= runs
+		s.Accusations /= runs
+		s.Appeals /= runs
+	}
+	return snapshots, nil
+}
+
+func newRun(p Params, rng *rand.Rand) *run {
+	r := &run{p: p, rng: rng}
+	for i := 0; i < p.NumKeypers; i++ {
+		r.keypers = append(r.keypers, &keyper{
+			faulty:  i < p.NumFaulty,
+			deposit: p.Deposit,
+		})
+	}
+	return r
+}
+
+func (r *run) gas(amount uint64) float64 {
+	return float64(amount) * r.p.GasPrice * 1e-9
+}
+
+// pick returns a random keyper matching the given predicate or nil if there is none.
+func (r *run) pick(match func(k *keyper) bool) *keyper {
+	var candidates []*keyper
+	for _, k := range r.keypers {
+		if match(k) {
+			candidates = append(candidates, k)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[r.rng.Intn(len(candidates))]
+}
+
+// batch simulates the execution of a single batch. Any keyper that hasn't been slashed may
+// execute it.
+func (r *run) batch() {
+	executor := r.pick(func(k *keyper) bool { return !k.slashed })
+	if executor == nil {
+		return
+	}
+	r.executions++
+	executor.eth += r.p.Fee - r.gas(r.p.ExecuteGas)
+
+	if executor.faulty {
+		if r.rng.Float64() >= r.p.FaultRate {
+			return
+		}
+		r.wrongExecutions++
+		executor.eth += r.p.FaultGain
+		if r.rng.Float64() < r.p.DetectionRate {
+			accuser := r.pick(func(k *keyper) bool { return !k.faulty })
+			if accuser != nil {
+				r.accuse(accuser, executor)
+			}
+		}
+		return
+	}
+	if r.rng.Float64() < r.p.FalseAccusationRate {
+		accuser := r.pick(func(k *keyper) bool { return k.faulty })
+		if accuser != nil {
+			r.accuse(accuser, executor)
+		}
+	}
+}
+
+// accuse lets the accuser accuse the executor of the current batch. The executor can appeal if
+// enough keypers are willing to sign the decryption key it used: for a correct execution these are
+// the honest keypers, for an incorrect one the faulty keypers. Otherwise, the accuser slashes the
+// executor.
+func (r *run) accuse(accuser, executor *keyper) {
+	r.accusations++
+	accuser.eth -= r.gas(r.p.AccuseGas)
+
+	signers := 0
+	for _, k := range r.keypers {
+		if k.faulty == executor.faulty {
+			signers++
+		}
+	}
+	if signers >= r.p.Threshold {
+		r.appeals++
+		executor.eth -= r.gas(r.p.AppealGas)
+		return
+	}
+
+	accuser.eth -= r.gas(r.p.SlashGas)
+	executor.deposit -= executor.deposit * r.p.SlashFraction
+	executor.slashed = true
+}
+
+// outcome computes the average outcome of the honest or faulty keypers.
+func (r *run) outcome(faulty bool) Outcome {
+	var o Outcome
+	n := 0
+	for _, k := range r.keypers {
+		if k.faulty != faulty {
+			continue
+		}
+		n++
+		o.ETH += k.eth
+		o.Deposit += k.deposit
+		o.Net += k.eth + (k.deposit-r.p.Deposit)*r.p.TokenPrice
+		if k.slashed {
+			o.Slashed++
+		}
+	}
+	if n > 0 {
+		o.divide(float64(n))
+	}
+	return o
+}
+
+func (r *run) addTo(s *Snapshot) {
+	s.Honest.add(r.outcome(false))
+	s.Faulty.add(r.outcome(true))
+	s.Executions += float64(r.executions)
+	s.WrongExecutions += float64(r.wrongExecutions)
+	s.Accusations += float64(r.accusations)
+	s.Appeals += float64(r.appeals)
+}
+
+func (o *Outcome) add(o2 Outcome) {
+	o.ETH += o2.ETH
+	o.Deposit += o2.Deposit
+	o.Net += o2.Net
+	o.Slashed += o2.Slashed
+}
+
+func (o *Outcome) divide(n float64) {
+	o.ETH /= n
+	o.Deposit /= n
+	o.Net /= n
+	o.Slashed /= n
+}
@@ -0,0 +1,95 @@
+package econsim
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func testParams() Params {
+	return Params{
+		NumKeypers:    5,
+		Threshold:     3,
+		NumFaulty:     1,
+		Deposit:       1000,
+		TokenPrice:    0.01,
+		SlashFraction: 1,
+		Days:          10,
+		BatchesPerDay: 100,
+		Fee:           0.01,
+		GasPrice:      10,
+		ExecuteGas:    200000,
+		AccuseGas:     100000,
+		AppealGas:     300000,
+		SlashGas:      50000,
+		FaultRate:     0.1,
+		FaultGain:     0.5,
+		DetectionRate: 1,
+		Runs:          20,
+		Seed:          1,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NilError(t, testParams().Validate())
+
+	p := testParams()
+	p.Threshold = 6
+	assert.ErrorContains(t, p.Validate(), "threshold")
+	p = testParams()
+	p.DetectionRate = 1.5
+	assert.ErrorContains(t, p.Validate(), "detection rate")
+}
+
+func TestSimulateAllHonest(t *testing.T) {
+	p := testParams()
+	p.NumFaulty = 0
+	snapshots, err := Simulate(p)
+	assert.NilError(t, err)
+	assert.Equal(t, len(snapshots), p.Days)
+
+	last := snapshots[p.Days-1]
+	assert.Equal(t, last.Day, p.Days)
+	assert.Equal(t, last.Executions, float64(p.Days*p.BatchesPerDay))
+	assert.Equal(t, last.Accusations, 0.0)
+	assert.Equal(t, last.Honest.Deposit, p.Deposit)
+	perBatch := p.Fee - float64(p.ExecuteGas)*p.GasPrice*1e-9
+	expected := last.Executions * perBatch / float64(p.NumKeypers)
+	assert.Assert(t, last.Honest.ETH > expected*0.999 && last.Honest.ETH < expected*1.001, last.Honest.ETH)
+}
+
+func TestSimulateSlashing(t *testing.T) {
+	p := testParams()
+	snapshots, err := Simulate(p)
+	assert.NilError(t, err)
+	last := snapshots[p.Days-1]
+	assert.Equal(t, last.Faulty.Slashed, 1.0)
+	assert.Equal(t, last.Faulty.Deposit, 0.0)
+	assert.Assert(t, last.Faulty.Net < last.Honest.Net)
+	assert.Equal(t, last.Honest.Slashed, 0.0)
+	assert.Equal(t, last.Appeals, 0.0)
+
+	// with a colluding threshold of faulty keypers, incorrect executions can't be slashed
+	p.NumFaulty = 3
+	snapshots, err = Simulate(p)
+	assert.NilError(t, err)
+	last = snapshots[p.Days-1]
+	assert.Equal(t, last.Faulty.Slashed, 0.0)
+	assert.Assert(t, last.Appeals > 0)
+	assert.Equal(t, last.Appeals, last.Accusations)
+
+	// and honest keypers can be slashed by false accusations they can't appeal
+	p.FalseAccusationRate = 0.1
+	snapshots, err = Simulate(p)
+	assert.NilError(t, err)
+	last = snapshots[p.Days-1]
+	assert.Equal(t, last.Honest.Slashed, 1.0)
+}
+
+func TestSimulateDeterministic(t *testing.T) {
+	s1, err := Simulate(testParams())
+	assert.NilError(t, err)
+	s2, err := Simulate(testParams())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s1, s2)
+}
@@ -0,0 +1,258 @@
+// Package abidrift checks that the logs emitted by the deployed contracts decode consistently
+// with the ABIs the keyper has been built against. If a contract changes underneath us, e.g.
+// after a proxy upgrade, events may still match our event signatures, but carry their fields in a
+// different layout, so that they would be silently mis-decoded and corrupt the observed state.
+// The checker decodes a sample of recent logs of each contract, re-encodes them, and raises an
+// alert about every log that doesn't round-trip to exactly the same topics and data.
+package abidrift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+// sampleBlocks is the number of most recent main chain blocks we check the logs of. Later checks
+// only look at the blocks added since the previous one, but at most at this many.
+const sampleBlocks = 1000
+
+// maxSamples is the maximum number of logs we check per contract and check, the most recent ones.
+const maxSamples = 20
+
+// Client is the part of the ethclient used to fetch logs.
+type Client interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Contract is a deployed contract to check, named as in contract.KnownVersions.
+type Contract struct {
+	Name    string
+	Address common.Address
+}
+
+// Drift is a log that isn't consistent with our ABI.
+type Drift struct {
+	Contract string
+	Address  common.Address
+	Block    uint64
+	TxHash   common.Hash
+	Index    uint   // of the log in the block
+	Event    string // empty if the event is unknown
+	Reason   string
+}
+
+func (d Drift) String() string {
+	event := d.Event
+	if event == "" {
+		event = "unknown event"
+	}
+	return fmt.Sprintf(
+		"%s at %s, %s in log %d of tx %s in block %d: %s",
+		d.Contract, d.Address.Hex(), event, d.Index, d.TxHash.Hex(), d.Block, d.Reason,
+	)
+}
+
+type checkedContract struct {
+	Contract
+	abi abi.ABI
+}
+
+// Checker checks the logs of a set of contracts against our ABIs.
+type Checker struct {
+	client    Client
+	contracts []checkedContract
+
+	mux    sync.Mutex
+	next   uint64 // first block not checked yet, 0 if we haven't checked any
+	drifts []Drift
+}
+
+// NewChecker creates a checker for the given contracts.
+func NewChecker(client Client, contracts []Contract) (*Checker, error) {
+	c := &Checker{client: client}
+	for _, ct := range contracts {
+		v, ok := contract.KnownVersions[ct.Name]
+		if !ok {
+			return nil, errors.Errorf("unknown contract %s", ct.Name)
+		}
+		parsed, err := abi.JSON(strings.NewReader(v.ABI))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse ABI of %s", ct.Name)
+		}
+		c.contracts = append(c.contracts, checkedContract{Contract: ct, abi: parsed})
+	}
+	return c, nil
+}
+
+// Drifts returns the drifts detected so far.
+func (c *Checker) Drifts() []Drift {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return append([]Drift(nil), c.drifts...)
+}
+
+// Run checks the logs of new blocks in the given interval until the context is canceled and
+// logs an alert for every drift. Errors are logged, but don't stop the checker.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+		drifts, err := c.Check(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error checking contract logs against our ABIs: %+v", err)
+			}
+			continue
+		}
+		for _, drift := range drifts {
+			log.Printf("Warning: contract log doesn't match our ABI, the contract may have been upgraded: %s", drift)
+		}
+	}
+}
+
+// Check checks the logs of the blocks added since the previous check, or of the most recent
+// blocks on the first check, and returns the new drifts.
+func (c *Checker) Check(ctx context.Context) ([]Drift, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	latest, err := c.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get main chain block number")
+	}
+	from := c.next
+	if latest >= sampleBlocks && from <= latest-sampleBlocks {
+		from = latest - sampleBlocks + 1
+	}
+	if from > latest {
+		return nil, nil
+	}
+
+	var drifts []Drift
+	for _, ct := range c.contracts {
+		logs, err := c.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(latest),
+			Addresses: []common.Address{ct.Address},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to filter logs of %s", ct.Name)
+		}
+		if len(logs) > maxSamples {
+			logs = logs[len(logs)-maxSamples:]
+		}
+		for _, l := range logs {
+			if l.Removed {
+				continue
+			}
+			event, reason := CheckLog(&ct.abi, l)
+			if reason == "" {
+				continue
+			}
+			drifts = append(drifts, Drift{
+				Contract: ct.Name,
+				Address:  ct.Address,
+				Block:    l.BlockNumber,
+				TxHash:   l.TxHash,
+				Index:    l.Index,
+				Event:    event,
+				Reason:   reason,
+			})
+		}
+	}
+	c.next = latest + 1
+	c.drifts = append(c.drifts, drifts...)
+	return drifts, nil
+}
+
+// CheckLog checks that the given log decodes consistently with the given ABI. It returns the name
+// of the log's event, if known, and the reason the log is inconsistent, or an empty string if it
+// is consistent.
+func CheckLog(parsed *abi.ABI, l types.Log) (string, string) {
+	if len(l.Topics) == 0 {
+		return "", "log has no event topic"
+	}
+	event, err := parsed.EventByID(l.Topics[0])
+	if err != nil {
+		return "", fmt.Sprintf("unknown event topic %s", l.Topics[0].Hex())
+	}
+
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(l.Topics)-1 != len(indexed) {
+		return event.Name, fmt.Sprintf("%d indexed fields, expected %d", len(l.Topics)-1, len(indexed))
+	}
+	for i, arg := range indexed {
+		if !validTopic(arg.Type, l.Topics[i+1]) {
+			return event.Name, fmt.Sprintf("indexed field %s is not a valid %s", arg.Name, arg.Type)
+		}
+	}
+
+	nonIndexed := event.Inputs.NonIndexed()
+	values, err := nonIndexed.Unpack(l.Data)
+	if err != nil {
+		return event.Name, fmt.Sprintf("cannot decode data: %s", err)
+	}
+	packed, err := nonIndexed.Pack(values...)
+	if err != nil {
+		return event.Name, fmt.Sprintf("cannot re-encode data: %s", err)
+	}
+	if !bytes.Equal(packed, l.Data) {
+		return event.Name, fmt.Sprintf("%d bytes of data re-encode to %d different bytes", len(l.Data), len(packed))
+	}
+	return event.Name, ""
+}
+
+// validTopic checks that the given topic is a valid encoding of an indexed field of the given
+// type. Indexed fields of dynamic types are hashed and can't be checked.
+func validTopic(t abi.Type, topic common.Hash) bool {
+	switch t.T {
+	case abi.AddressTy:
+		return allEqual(topic[:common.HashLength-common.AddressLength], 0)
+	case abi.BoolTy:
+		return allEqual(topic[:common.HashLength-1], 0) && topic[common.HashLength-1] <= 1
+	case abi.UintTy:
+		return new(big.Int).SetBytes(topic[:]).BitLen() <= t.Size
+	case abi.IntTy:
+		n := common.HashLength - t.Size/8
+		var sign byte
+		if topic[n]&0x80 != 0 {
+			sign = 0xff
+		}
+		return allEqual(topic[:n], sign)
+	case abi.FixedBytesTy:
+		return allEqual(topic[t.Size:], 0)
+	default:
+		return true
+	}
+}
+
+func allEqual(b []byte, v byte) bool {
+	for _, x := range b {
+		if x != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,142 @@
+package abidrift
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+func parseABI(t *testing.T, abiJSON string) *abi.ABI {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	assert.NilError(t, err)
+	return &parsed
+}
+
+func transactionAddedLog(t *testing.T, batcher *abi.ABI) types.Log {
+	t.Helper()
+	event := batcher.Events["TransactionAdded"]
+	data, err := event.Inputs.NonIndexed().Pack(uint64(5), uint8(1), []byte("transaction"), [32]byte{1})
+	assert.NilError(t, err)
+	return types.Log{Topics: []common.Hash{event.ID}, Data: data}
+}
+
+func accusedLog(slasher *abi.ABI) types.Log {
+	return types.Log{Topics: []common.Hash{
+		slasher.Events["Accused"].ID,
+		common.BigToHash(common.Big3),
+		common.BigToAddress(common.Big1).Hash(),
+		common.BigToAddress(common.Big2).Hash(),
+	}}
+}
+
+func TestCheckLog(t *testing.T) {
+	batcher := parseABI(t, contract.BatcherContractABI)
+	slasher := parseABI(t, contract.KeyperSlasherABI)
+
+	event, reason := CheckLog(batcher, transactionAddedLog(t, batcher))
+	assert.Equal(t, event, "TransactionAdded")
+	assert.Equal(t, reason, "")
+	_, reason = CheckLog(slasher, accusedLog(slasher))
+	assert.Equal(t, reason, "")
+
+	// a transaction type that doesn't fit into the uint8 we decode it as
+	l := transactionAddedLog(t, batcher)
+	l.Data[2*32-2] = 1
+	_, reason = CheckLog(batcher, l)
+	assert.Assert(t, strings.Contains(reason, "re-encode"), reason)
+
+	l = transactionAddedLog(t, batcher)
+	l.Data = append(l.Data, make([]byte, 32)...)
+	_, reason = CheckLog(batcher, l)
+	assert.Assert(t, reason != "")
+
+	l = transactionAddedLog(t, batcher)
+	l.Data = l.Data[:64]
+	_, reason = CheckLog(batcher, l)
+	assert.Assert(t, strings.HasPrefix(reason, "cannot decode data"), reason)
+
+	l = accusedLog(slasher)
+	l.Topics = l.Topics[:3]
+	_, reason = CheckLog(slasher, l)
+	assert.Equal(t, reason, "2 indexed fields, expected 3")
+
+	l = accusedLog(slasher)
+	l.Topics[2][0] = 1
+	_, reason = CheckLog(slasher, l)
+	assert.Equal(t, reason, "indexed field executor is not a valid address")
+
+	l = accusedLog(slasher)
+	l.Topics[1][23] = 1
+	_, reason = CheckLog(slasher, l)
+	assert.Equal(t, reason, "indexed field halfStep is not a valid uint64")
+
+	event, reason = CheckLog(batcher, accusedLog(slasher))
+	assert.Equal(t, event, "")
+	assert.Assert(t, strings.HasPrefix(reason, "unknown event topic"), reason)
+}
+
+type testClient struct {
+	blockNumber uint64
+	logs        []types.Log
+	queries     []ethereum.FilterQuery
+}
+
+func (c *testClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.blockNumber, nil
+}
+
+func (c *testClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	c.queries = append(c.queries, q)
+	return c.logs, nil
+}
+
+func TestChecker(t *testing.T) {
+	ctx := context.Background()
+	batcher := parseABI(t, contract.BatcherContractABI)
+	address := common.BigToAddress(common.Big1)
+	client := &testClient{blockNumber: 5000}
+	for i := 0; i < 2*maxSamples; i++ {
+		l := transactionAddedLog(t, batcher)
+		l.BlockNumber = uint64(4500 + i)
+		client.logs = append(client.logs, l)
+	}
+	// the oldest log is drifted, but not part of the sample
+	client.logs[0].Data = nil
+
+	checker, err := NewChecker(client, []Contract{{Name: "BatcherContract", Address: address}})
+	assert.NilError(t, err)
+	drifts, err := checker.Check(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(drifts), 0)
+	assert.Equal(t, client.queries[0].FromBlock.Uint64(), uint64(5000-sampleBlocks+1))
+	assert.Equal(t, client.queries[0].ToBlock.Uint64(), uint64(5000))
+	assert.DeepEqual(t, client.queries[0].Addresses, []common.Address{address})
+
+	// later checks look at the new blocks only
+	client.blockNumber = 5010
+	client.logs[len(client.logs)-1].Data = nil
+	drifts, err = checker.Check(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(drifts), 1)
+	assert.Equal(t, drifts[0].Event, "TransactionAdded")
+	assert.Equal(t, drifts[0].Block, uint64(4500+2*maxSamples-1))
+	assert.Equal(t, client.queries[1].FromBlock.Uint64(), uint64(5001))
+	assert.DeepEqual(t, checker.Drifts(), drifts)
+
+	drifts, err = checker.Check(ctx)
+	assert.NilError(t, err)
+	assert.Equal(t, len(drifts), 0)
+	assert.Equal(t, len(client.queries), 2)
+
+	_, err = NewChecker(client, []Contract{{Name: "Unknown"}})
+	assert.ErrorContains(t, err, "unknown contract")
+}
@@ -0,0 +1,252 @@
+// Package access restricts who may read sensitive data from the keyper's public APIs. In open
+// mode everything is served to everyone. In closed mode payloads only the keyper set should see,
+// e.g. poly eval ciphertexts, are only served to clients authenticating as one of the keypers,
+// while public data like eon public keys and released epoch keys stays open.
+//
+// Keypers authenticate with a token consisting of a unix timestamp, a random nonce and their
+// signature of both together with the token's audience, i.e. the API and the keyper it is meant
+// for. The token is sent in the Authorization header (HTTP) or metadata entry (gRPC) as "Shutter
+// <token>". A token is only accepted once and only by the API it's been created for, but it is a
+// bearer token nonetheless: APIs accepting tokens must be served via TLS, e.g. behind a reverse
+// proxy, otherwise a token intercepted on the way can be used in place of the actual client.
+//
+// The admin API is restricted by role instead, see RoleChecker: besides the keyper itself, the
+// operator may give further accounts the viewer, operator or security-admin role.
+//
+// Access modes only apply to the keyper's own APIs. The events are part of the shuttermint chain,
+// so the shuttermint node's RPC server serves them to anyone who can reach it. The app's ABCI
+// Query method does not serve any data.
+package access
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
+)
+
+// MaxTokenAge is how long a token is accepted after it has been created. Tokens created up to
+// this long in the future are accepted as well to allow for clock skew.
+const MaxTokenAge = 5 * time.Minute
+
+const (
+	authorizationKey = "authorization"
+	authScheme       = "Shutter "
+	tokenHashPrefix  = "shutter-access:"
+)
+
+// Mode is the access mode of the keyper's APIs.
+type Mode string
+
+const (
+	// Open serves all data to everyone.
+	Open Mode = "open"
+	// Closed serves sensitive data to keypers only.
+	Closed Mode = "closed"
+)
+
+// ParseMode parses an access mode. The empty string is parsed as Open.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", Open:
+		return Open, nil
+	case Closed:
+		return Closed, nil
+	default:
+		return "", errors.Errorf("invalid access mode %q, must be %q or %q", s, Open, Closed)
+	}
+}
+
+// APIs a token can be created for, see Audience.
+const (
+	EventStreamAPI = "eventstream"
+	AdminAPI       = "admin"
+)
+
+// nonceSize is the number of random bytes in a token.
+const nonceSize = 16
+
+// Audience identifies the API of the given keyper a token is meant for.
+func Audience(api string, keyper common.Address) string {
+	return api + "@" + keyper.Hex()
+}
+
+func tokenHash(audience string, timestamp int64, nonce string) []byte {
+	return ethcrypto.Keccak256([]byte(tokenHashPrefix + audience + ":" + strconv.FormatInt(timestamp, 10) + ":" + nonce))
+}
+
+// MakeToken creates a token for the given audience authenticating the owner of the given key at
+// the given time.
+func MakeToken(key *ecdsa.PrivateKey, audience string, now time.Time) (string, error) {
+	nonceBytes := make([]byte, nonceSize)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return "", errors.Wrap(err, "failed to create access token nonce")
+	}
+	nonce := hexutil.Encode(nonceBytes)
+	timestamp := now.Unix()
+	sig, err := ethcrypto.Sign(tokenHash(audience, timestamp, nonce), key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to sign access token")
+	}
+	return fmt.Sprintf("%d.%s.%s", timestamp, nonce, hexutil.Encode(sig)), nil
+}
+
+// Token is a parsed access token.
+type Token struct {
+	Signer    common.Address
+	Nonce     string
+	Timestamp int64
+}
+
+// ParseToken checks that the given token is well formed, meant for the given audience and not
+// expired. It does not check whether the token has been used before, see Checker.
+func ParseToken(token string, audience string, now time.Time) (Token, error) {
+	parts := strings.SplitN(token, ".", 3)
+	if len(parts) != 3 {
+		return Token{}, errors.New("malformed access token")
+	}
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Token{}, errors.Wrap(err, "malformed access token timestamp")
+	}
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > MaxTokenAge || age < -MaxTokenAge {
+		return Token{}, errors.New("access token expired")
+	}
+	nonce := parts[1]
+	if b, err := hexutil.Decode(nonce); err != nil || len(b) != nonceSize {
+		return Token{}, errors.New("malformed access token nonce")
+	}
+	sig, err := hexutil.Decode(parts[2])
+	if err != nil {
+		return Token{}, errors.Wrap(err, "malformed access token signature")
+	}
+	pubkey, err := ethcrypto.SigToPub(tokenHash(audience, timestamp, nonce), sig)
+	if err != nil {
+		return Token{}, errors.Wrap(err, "invalid access token signature")
+	}
+	return Token{
+		Signer:    ethcrypto.PubkeyToAddress(*pubkey),
+		Nonce:     nonce,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// Checker decides whether a client may read sensitive data. It may be used concurrently.
+type Checker struct {
+	mode     Mode
+	audience string
+	isKeyper func(common.Address) bool
+	now      func() time.Time
+
+	mux        sync.Mutex
+	usedNonces map[string]time.Time // nonce => expiry of the token it's been used with
+}
+
+// NewChecker creates a checker for the given mode. In closed mode, sensitive data is served to
+// clients authenticating with a token for the given audience as an address for which isKeyper
+// returns true.
+func NewChecker(mode Mode, audience string, isKeyper func(common.Address) bool) *Checker {
+	return &Checker{
+		mode:       mode,
+		audience:   audience,
+		isKeyper:   isKeyper,
+		now:        time.Now,
+		usedNonces: make(map[string]time.Time),
+	}
+}
+
+// Mode returns the checker's access mode.
+func (c *Checker) Mode() Mode {
+	return c.mode
+}
+
+// Allowed checks if a client sending the given authorization value may read sensitive data. A
+// token is only accepted the first time it is presented.
+func (c *Checker) Allowed(authorization string) bool {
+	if c == nil || c.mode == Open {
+		return true
+	}
+	_, ok := c.authenticate(authorization)
+	return ok
+}
+
+// authenticate checks the token in the given authorization value regardless of the mode and
+// returns its signer. A token is only accepted the first time it is presented.
+func (c *Checker) authenticate(authorization string) (common.Address, bool) {
+	if !strings.HasPrefix(authorization, authScheme) {
+		return common.Address{}, false
+	}
+	now := c.now()
+	token, err := ParseToken(strings.TrimPrefix(authorization, authScheme), c.audience, now)
+	if err != nil || !c.isKeyper(token.Signer) {
+		return common.Address{}, false
+	}
+	if !c.useNonce(token, now) {
+		return common.Address{}, false
+	}
+	return token.Signer, true
+}
+
+// useNonce records the token's nonce as used. It returns false if it has been used before.
+func (c *Checker) useNonce(token Token, now time.Time) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for nonce, expiry := range c.usedNonces {
+		if now.After(expiry) {
+			delete(c.usedNonces, nonce)
+		}
+	}
+	if _, ok := c.usedNonces[token.Nonce]; ok {
+		return false
+	}
+	c.usedNonces[token.Nonce] = time.Unix(token.Timestamp, 0).Add(MaxTokenAge)
+	return true
+}
+
+// AllowedHTTP checks if the client sending the given request may read sensitive data.
+func (c *Checker) AllowedHTTP(r *http.Request) bool {
+	return c.Allowed(r.Header.Get(authorizationKey))
+}
+
+// RequireHTTP wraps the given handler so that it only serves clients allowed to read sensitive
+// data. Everyone else gets a 401 Unauthorized response.
+func (c *Checker) RequireHTTP(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.AllowedHTTP(r) {
+			w.Header().Set("WWW-Authenticate", strings.TrimSpace(authScheme))
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// AllowedGRPC checks if the client of the gRPC call with the given context may read sensitive
+// data.
+func (c *Checker) AllowedGRPC(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(authorizationKey) {
+		if c.Allowed(v) {
+			return true
+		}
+	}
+	return c.Allowed("")
+}
+
+// Authorization returns the value of the Authorization header or metadata entry for the given
+// token.
+func Authorization(token string) string {
+	return authScheme + token
+}
@@ -0,0 +1,111 @@
+package access
+
+import (
+	"crypto/ecdsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseMode(t *testing.T) {
+	for s, expected := range map[string]Mode{"": Open, "open": Open, "closed": Closed} {
+		mode, err := ParseMode(s)
+		assert.NilError(t, err)
+		assert.Equal(t, mode, expected)
+	}
+	_, err := ParseMode("private")
+	assert.ErrorContains(t, err, "invalid access mode")
+}
+
+func TestToken(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	now := time.Unix(1000000, 0)
+	audience := Audience(EventStreamAPI, common.BigToAddress(common.Big1))
+
+	token, err := MakeToken(key, audience, now)
+	assert.NilError(t, err)
+	parsed, err := ParseToken(token, audience, now.Add(time.Minute))
+	assert.NilError(t, err)
+	assert.Equal(t, parsed.Signer, ethcrypto.PubkeyToAddress(key.PublicKey))
+	assert.Equal(t, parsed.Timestamp, now.Unix())
+
+	other, err := MakeToken(key, audience, now)
+	assert.NilError(t, err)
+	otherParsed, err := ParseToken(other, audience, now)
+	assert.NilError(t, err)
+	assert.Assert(t, parsed.Nonce != otherParsed.Nonce)
+
+	// a token for another audience is signed by someone else as far as we're concerned
+	parsed, err = ParseToken(token, Audience(AdminAPI, common.BigToAddress(common.Big1)), now)
+	if err == nil {
+		assert.Assert(t, parsed.Signer != ethcrypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	_, err = ParseToken(token, audience, now.Add(MaxTokenAge+time.Second))
+	assert.ErrorContains(t, err, "expired")
+	_, err = ParseToken("1000000", audience, now)
+	assert.ErrorContains(t, err, "malformed")
+}
+
+func TestChecker(t *testing.T) {
+	keyperKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	otherKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	keyper := ethcrypto.PubkeyToAddress(keyperKey.PublicKey)
+	isKeyper := func(addr common.Address) bool { return addr == keyper }
+	audience := Audience(EventStreamAPI, keyper)
+	newToken := func(key *ecdsa.PrivateKey, audience string) string {
+		token, err := MakeToken(key, audience, time.Now())
+		assert.NilError(t, err)
+		return token
+	}
+
+	open := NewChecker(Open, audience, isKeyper)
+	assert.Assert(t, open.Allowed(""))
+	var unset *Checker
+	assert.Assert(t, unset.Allowed(""))
+
+	closed := NewChecker(Closed, audience, isKeyper)
+	assert.Assert(t, !closed.Allowed(""))
+	assert.Assert(t, !closed.Allowed(newToken(keyperKey, audience)))
+	assert.Assert(t, !closed.Allowed(Authorization(newToken(otherKey, audience))))
+	assert.Assert(t, !closed.Allowed(Authorization(newToken(keyperKey, Audience(AdminAPI, keyper)))))
+	keyperToken := newToken(keyperKey, audience)
+	assert.Assert(t, closed.Allowed(Authorization(keyperToken)))
+	assert.Assert(t, !closed.Allowed(Authorization(keyperToken)), "token must not be accepted twice")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	assert.Assert(t, !closed.AllowedHTTP(req))
+	req.Header.Set("Authorization", Authorization(newToken(keyperKey, audience)))
+	assert.Assert(t, closed.AllowedHTTP(req))
+}
+
+func TestRequireHTTP(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	owner := ethcrypto.PubkeyToAddress(key.PublicKey)
+	audience := Audience(AdminAPI, owner)
+	token, err := MakeToken(key, audience, time.Now())
+	assert.NilError(t, err)
+	checker := NewChecker(Closed, audience, func(addr common.Address) bool { return addr == owner })
+	handler := checker.RequireHTTP(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, rec.Code, http.StatusUnauthorized)
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", Authorization(token))
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, rec.Code, http.StatusNoContent)
+}
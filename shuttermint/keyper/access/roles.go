@@ -0,0 +1,170 @@
+package access
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Role is the role of an admin API account. Each role may do everything the lower ones may do.
+type Role int
+
+const (
+	// NoRole is the role of clients that haven't authenticated as an admin account.
+	NoRole Role = iota
+	// Viewer may read diagnostics, e.g. decision traces and accusations.
+	Viewer
+	// Operator may additionally run maintenance operations, e.g. pruning the state.
+	Operator
+	// SecurityAdmin may additionally access our secrets and stop key releases, e.g. pause them,
+	// fetch the state or fence the keyper.
+	SecurityAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case NoRole:
+		return "none"
+	case Viewer:
+		return "viewer"
+	case Operator:
+		return "operator"
+	case SecurityAdmin:
+		return "security-admin"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRole parses the name of a role other than NoRole.
+func ParseRole(s string) (Role, error) {
+	for _, r := range []Role{Viewer, Operator, SecurityAdmin} {
+		if s == r.String() {
+			return r, nil
+		}
+	}
+	return NoRole, errors.Errorf(
+		"invalid role %q, must be %q, %q or %q", s, Viewer, Operator, SecurityAdmin)
+}
+
+// ParseAccounts parses admin accounts given as "0xaddress=role".
+func ParseAccounts(specs []string) (map[common.Address]Role, error) {
+	accounts := make(map[common.Address]Role)
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+			return nil, errors.Errorf("invalid admin account %q, expected 0xaddress=role", spec)
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid admin account %q", spec)
+		}
+		addr := common.HexToAddress(parts[0])
+		if _, ok := accounts[addr]; ok {
+			return nil, errors.Errorf("duplicate admin account %s", addr.Hex())
+		}
+		accounts[addr] = role
+	}
+	return accounts, nil
+}
+
+// AuditEntry describes a request to an API protected by a RoleChecker.
+type AuditEntry struct {
+	Time       time.Time
+	Account    common.Address // zero if the client didn't authenticate
+	Role       Role           // the role of the account, NoRole if the client didn't authenticate
+	Required   Role
+	Method     string
+	Path       string
+	RemoteAddr string
+	Status     int // the response status
+}
+
+// LogAudit writes the entry to the log.
+func LogAudit(e AuditEntry) {
+	client := "unauthenticated client"
+	if e.Role != NoRole {
+		client = e.Account.Hex() + " (" + e.Role.String() + ")"
+	}
+	log.Printf("Admin API: %s %s by %s from %s: %d", e.Method, e.Path, client, e.RemoteAddr, e.Status)
+}
+
+// RoleChecker restricts an API to accounts with a role. It may be used concurrently.
+type RoleChecker struct {
+	checker  *Checker
+	accounts map[common.Address]Role
+	audit    func(AuditEntry)
+}
+
+// NewRoleChecker creates a checker for the given accounts authenticating with tokens for the
+// given audience. Every request is passed to audit once it has been handled.
+func NewRoleChecker(audience string, accounts map[common.Address]Role, audit func(AuditEntry)) *RoleChecker {
+	rc := &RoleChecker{accounts: accounts, audit: audit}
+	rc.checker = NewChecker(Closed, audience, func(addr common.Address) bool {
+		return rc.accounts[addr] != NoRole
+	})
+	return rc
+}
+
+// Authenticate checks the token in the given authorization value and returns the account it
+// authenticates and its role. A token is only accepted the first time it is presented.
+func (rc *RoleChecker) Authenticate(authorization string) (common.Address, Role) {
+	addr, ok := rc.checker.authenticate(authorization)
+	if !ok {
+		return common.Address{}, NoRole
+	}
+	return addr, rc.accounts[addr]
+}
+
+// RequireRole wraps the given handler so that it only serves accounts with at least the given
+// role. Unauthenticated clients get a 401 Unauthorized response, accounts with a lower role a 403
+// Forbidden one.
+func (rc *RoleChecker) RequireRole(role Role, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, have := rc.Authenticate(r.Header.Get(authorizationKey))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		switch {
+		case have == NoRole:
+			rec.Header().Set("WWW-Authenticate", strings.TrimSpace(authScheme))
+			http.Error(rec, "unauthorized", http.StatusUnauthorized)
+		case have < role:
+			http.Error(rec, "forbidden, requires role "+role.String(), http.StatusForbidden)
+		default:
+			handler.ServeHTTP(rec, r)
+		}
+		rc.audit(AuditEntry{
+			Time:       time.Now(),
+			Account:    addr,
+			Role:       have,
+			Required:   role,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			RemoteAddr: r.RemoteAddr,
+			Status:     rec.status,
+		})
+	})
+}
+
+// statusRecorder remembers the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(data []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(data)
+}
@@ -0,0 +1,82 @@
+package access
+
+import (
+	"crypto/ecdsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseAccounts(t *testing.T) {
+	addr := common.BigToAddress(common.Big1)
+	accounts, err := ParseAccounts([]string{
+		addr.Hex() + "=viewer",
+		common.BigToAddress(common.Big2).Hex() + "=security-admin",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, accounts, map[common.Address]Role{
+		addr:                             Viewer,
+		common.BigToAddress(common.Big2): SecurityAdmin,
+	})
+
+	_, err = ParseAccounts([]string{addr.Hex() + "=admin"})
+	assert.ErrorContains(t, err, "invalid role")
+	_, err = ParseAccounts([]string{addr.Hex()})
+	assert.ErrorContains(t, err, "expected 0xaddress=role")
+	_, err = ParseAccounts([]string{addr.Hex() + "=viewer", addr.Hex() + "=operator"})
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestRequireRole(t *testing.T) {
+	owner := common.BigToAddress(common.Big1)
+	audience := Audience(AdminAPI, owner)
+	viewerKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	adminKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	otherKey, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	viewer := ethcrypto.PubkeyToAddress(viewerKey.PublicKey)
+	admin := ethcrypto.PubkeyToAddress(adminKey.PublicKey)
+	accounts := map[common.Address]Role{viewer: Viewer, admin: SecurityAdmin}
+
+	var entries []AuditEntry
+	checker := NewRoleChecker(audience, accounts, func(e AuditEntry) { entries = append(entries, e) })
+	handler := checker.RequireRole(Operator, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	request := func(key *ecdsa.PrivateKey) int {
+		req := httptest.NewRequest("POST", "/state/prune", nil)
+		if key != nil {
+			token, err := MakeToken(key, audience, time.Now())
+			assert.NilError(t, err)
+			req.Header.Set("Authorization", Authorization(token))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, request(nil), http.StatusUnauthorized)
+	assert.Equal(t, request(otherKey), http.StatusUnauthorized)
+	assert.Equal(t, request(viewerKey), http.StatusForbidden)
+	assert.Equal(t, request(adminKey), http.StatusAccepted)
+
+	assert.Equal(t, len(entries), 4)
+	assert.Equal(t, entries[0].Role, NoRole)
+	assert.Equal(t, entries[1].Account, common.Address{})
+	assert.Equal(t, entries[2].Account, viewer)
+	assert.Equal(t, entries[2].Role, Viewer)
+	assert.Equal(t, entries[2].Status, http.StatusForbidden)
+	assert.Equal(t, entries[3].Account, admin)
+	assert.Equal(t, entries[3].Role, SecurityAdmin)
+	assert.Equal(t, entries[3].Required, Operator)
+	assert.Equal(t, entries[3].Method, "POST")
+	assert.Equal(t, entries[3].Path, "/state/prune")
+	assert.Equal(t, entries[3].Status, http.StatusAccepted)
+}
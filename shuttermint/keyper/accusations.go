@@ -0,0 +1,45 @@
+package keyper
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+)
+
+func (kpr *Keyper) accusationinfo() string {
+	var total uint64
+	for _, n := range kpr.State.UnjustifiedAccusations {
+		total += n
+	}
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", unjustified accusations: %d", total)
+}
+
+// updateAccusations publishes a copy of the unjustified accusations against us, so that they can
+// be served while the decider keeps updating the state.
+func (kpr *Keyper) updateAccusations() {
+	counts := make(map[common.Address]uint64, len(kpr.State.UnjustifiedAccusations))
+	for accuser, n := range kpr.State.UnjustifiedAccusations {
+		counts[accuser] = n
+	}
+	kpr.accusations.Store(counts)
+}
+
+func (kpr *Keyper) accusationsHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveAccusations)
+}
+
+// serveAccusations serves the number of unjustified accusations against us by accuser as JSON.
+func (kpr *Keyper) serveAccusations(w http.ResponseWriter, _ *http.Request) {
+	counts, _ := kpr.accusations.Load().(map[common.Address]uint64)
+	res := make(map[string]uint64, len(counts))
+	for accuser, n := range counts {
+		res[accuser.Hex()] = n
+	}
+	httpapi.WriteJSON(w, res)
+}
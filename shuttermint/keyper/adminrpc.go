@@ -0,0 +1,173 @@
+package keyper
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// The admin RPC API lets operators inspect the decider's state and make it run a step on demand,
+// e.g. while debugging a keyper that doesn't act as expected. It is served with JSON-RPC over HTTP
+// at /rpc of the admin API in the "keyper" namespace: keyper_state, keyper_decide and
+// keyper_dumpState. The state is owned by the sync loop, so every call is run there in between
+// two steps, see runInSyncLoop.
+
+// syncLoopCall is a function run by the sync loop. An error returned by it stops the keyper.
+type syncLoopCall func(ctx context.Context) error
+
+// EKGInfo describes an eon we've generated the key for.
+type EKGInfo struct {
+	Eon             uint64
+	StartBatchIndex uint64
+	NumKeypers      int
+	Threshold       uint64
+}
+
+// DeciderState is the decider's view of the world, as reported by keyper_state.
+type DeciderState struct {
+	ShutterHeight         int64
+	ShutterBehind         uint64 // number of blocks the shuttermint node has, but we haven't synced
+	MainChainHeight       uint64
+	MainChainBehind       uint64 // number of main chain blocks we have to sync to be up to date
+	SyncHeight            int64  // shuttermint height the decider has synced its state to
+	Syncing               bool
+	NumExecutionHalfSteps uint64
+
+	DKGs             []DKGStatus
+	EKGs             []EKGInfo
+	PendingHalfStep  *uint64  // half step we're executing, if any
+	PendingAppeals   []uint64 // half steps we have to appeal accusations for
+	HalfStepsChecked uint64
+	ActionCounter    uint64 // number of actions the decider has produced so far
+}
+
+// DecideResult is the result of keyper_decide.
+type DecideResult struct {
+	Skipped    bool // the step was skipped, because we're syncing or the state is bad
+	NumActions uint64
+}
+
+// StateDump is the result of keyper_dumpState.
+type StateDump struct {
+	ShutterHeight   int64
+	MainChainHeight uint64
+	State           json.RawMessage // without our secrets, see RedactState
+}
+
+type adminRPC struct {
+	kpr *Keyper
+}
+
+// newAdminRPCHandler returns the HTTP handler serving the admin RPC API.
+func (kpr *Keyper) newAdminRPCHandler() (*rpc.Server, error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("keyper", &adminRPC{kpr: kpr}); err != nil {
+		return nil, err
+	}
+	return srv, nil
+}
+
+// runInSyncLoop makes the sync loop call f and waits until it's done. f's error is returned and
+// stops the keyper. The results must not share memory with the state, as the sync loop goes on
+// changing it once f has returned.
+func (kpr *Keyper) runInSyncLoop(ctx context.Context, f syncLoopCall) error {
+	done := make(chan error, 1)
+	call := func(loopCtx context.Context) error {
+		err := f(loopCtx)
+		done <- err
+		return err
+	}
+	select {
+	case kpr.syncLoopCalls <- call:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return <-done
+}
+
+// computeDeciderState computes the report of keyper_state.
+func (kpr *Keyper) computeDeciderState() DeciderState {
+	world := kpr.CurrentWorld()
+	st := kpr.State
+	res := DeciderState{
+		ShutterHeight:         world.Shutter.CurrentBlock,
+		ShutterBehind:         world.Shutter.BlocksBehind(),
+		MainChainHeight:       world.MainChain.CurrentBlock,
+		MainChainBehind:       world.MainChain.BlocksBehind(),
+		SyncHeight:            st.SyncHeight,
+		Syncing:               kpr.syncing,
+		NumExecutionHalfSteps: world.MainChain.NumExecutionHalfSteps,
+		DKGs:                  []DKGStatus{},
+		EKGs:                  []EKGInfo{},
+		PendingAppeals:        []uint64{},
+		HalfStepsChecked:      st.HalfStepsChecked,
+		ActionCounter:         st.ActionCounter,
+	}
+	if st.PendingHalfStep != nil {
+		halfStep := *st.PendingHalfStep
+		res.PendingHalfStep = &halfStep
+	}
+	for _, dkg := range st.DKGs {
+		res.DKGs = append(res.DKGs, dkgStatus(dkg, world.Shutter))
+	}
+	for _, ekg := range st.EKGs {
+		info := EKGInfo{Eon: ekg.Eon, StartBatchIndex: ekg.StartBatchIndex, NumKeypers: len(ekg.Keypers)}
+		if ekg.EpochKG != nil {
+			info.Threshold = ekg.EpochKG.Threshold
+		}
+		res.EKGs = append(res.EKGs, info)
+	}
+	for halfStep := range st.PendingAppeals {
+		res.PendingAppeals = append(res.PendingAppeals, halfStep)
+	}
+	sort.Slice(res.PendingAppeals, func(i, j int) bool { return res.PendingAppeals[i] < res.PendingAppeals[j] })
+	return res
+}
+
+// State reports the decider's state and how far we've synced the chains.
+func (api *adminRPC) State(ctx context.Context) (DeciderState, error) {
+	var res DeciderState
+	err := api.kpr.runInSyncLoop(ctx, func(context.Context) error {
+		res = api.kpr.computeDeciderState()
+		return nil
+	})
+	return res, err
+}
+
+// Decide runs a decider step with the chains as synced so far, without waiting for the next
+// block. Like any other step, it is skipped while we're syncing or if the state is bad.
+func (api *adminRPC) Decide(ctx context.Context) (DecideResult, error) {
+	var res DecideResult
+	err := api.kpr.runInSyncLoop(ctx, func(loopCtx context.Context) error {
+		kpr := api.kpr
+		world := kpr.CurrentWorld()
+		if kpr.checkSyncing(world) || kpr.shouldSkipStep(world) {
+			res.Skipped = true
+			return nil
+		}
+		counter := kpr.State.ActionCounter
+		err := kpr.runOneStep(loopCtx)
+		res.NumActions = kpr.State.ActionCounter - counter
+		return err
+	})
+	return res, err
+}
+
+// DumpState returns the decider's state without our secrets.
+func (api *adminRPC) DumpState(ctx context.Context) (StateDump, error) {
+	var res StateDump
+	var marshalErr error
+	err := api.kpr.runInSyncLoop(ctx, func(context.Context) error {
+		world := api.kpr.CurrentWorld()
+		res.ShutterHeight = world.Shutter.CurrentBlock
+		res.MainChainHeight = world.MainChain.CurrentBlock
+		res.State, marshalErr = json.Marshal(RedactState(api.kpr.State))
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	return res, marshalErr
+}
@@ -0,0 +1,85 @@
+package keyper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestAdminRPC(t *testing.T) {
+	k := NewKeyper(Config{})
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 100
+	shutter.NodeStatus = &rpctypes.ResultStatus{}
+	shutter.NodeStatus.SyncInfo.LatestBlockHeight = 200
+	shutter.Eons = []observe.Eon{{Eon: 2, StartHeight: 90}}
+	k.world.Store(observe.World{Shutter: shutter, MainChain: observe.NewMainChain(0)})
+
+	keypers := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	pure := puredkg.NewPureDKG(2, 2, 2, 0)
+	halfStep := uint64(7)
+	k.State.DKGs = []DKG{{Eon: 1, Keypers: keypers}, {Eon: 2, Keypers: keypers, Pure: &pure}}
+	k.State.EKGs = []*EKG{{Eon: 1, Keypers: keypers, EpochKG: &epochkg.EpochKG{Threshold: 2}}}
+	k.State.PendingHalfStep = &halfStep
+	k.State.PendingAppeals[9] = struct{}{}
+	k.State.PendingAppeals[5] = struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler, err := k.newAdminRPCHandler()
+	assert.NilError(t, err)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	client, err := rpc.DialHTTP(ts.URL)
+	assert.NilError(t, err)
+	defer client.Close()
+
+	// calls wait for the sync loop
+	callCtx, callCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer callCancel()
+	var state DeciderState
+	err = client.CallContext(callCtx, &state, "keyper_state")
+	assert.ErrorContains(t, err, "deadline exceeded")
+
+	go func() {
+		_ = k.syncLoop(ctx)
+	}()
+
+	assert.NilError(t, client.Call(&state, "keyper_state"))
+	assert.Equal(t, state.ShutterHeight, int64(100))
+	assert.Equal(t, state.ShutterBehind, uint64(100))
+	assert.Assert(t, state.Syncing)
+	assert.DeepEqual(t, state.DKGs, []DKGStatus{
+		{Eon: 1, Phase: "Finalized", NumKeypers: 2},
+		{Eon: 2, Phase: "Off", NumKeypers: 2, StartHeight: 90},
+	})
+	assert.DeepEqual(t, state.EKGs, []EKGInfo{{Eon: 1, NumKeypers: 2, Threshold: 2}})
+	assert.Equal(t, *state.PendingHalfStep, uint64(7))
+	assert.DeepEqual(t, state.PendingAppeals, []uint64{5, 9})
+
+	var dump StateDump
+	assert.NilError(t, client.Call(&dump, "keyper_dumpState"))
+	assert.Equal(t, dump.ShutterHeight, int64(100))
+	var dumped State
+	assert.NilError(t, json.Unmarshal(dump.State, &dumped))
+	assert.Equal(t, len(dumped.DKGs), 2)
+	assert.Assert(t, dumped.DKGs[1].Pure == nil)
+	assert.Assert(t, dumped.EKGs[0].EpochKG == nil)
+	assert.Equal(t, len(dumped.PendingAppeals), 2)
+
+	// we're 100 shuttermint blocks behind and therefore still syncing
+	var res DecideResult
+	assert.NilError(t, client.Call(&res, "keyper_decide"))
+	assert.DeepEqual(t, res, DecideResult{Skipped: true})
+}
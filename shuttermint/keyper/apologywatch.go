@@ -0,0 +1,54 @@
+package keyper
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// apologyResendInterval is the minimum number of shuttermint blocks between sending our
+// apologies again.
+const apologyResendInterval = 2
+
+// hasApologized checks if an apology by the given keyper is in the chain.
+func hasApologized(eon observe.Eon, keyper common.Address) bool {
+	for _, apology := range eon.Apologies {
+		if apology.Sender == keyper {
+			return true
+		}
+	}
+	return false
+}
+
+// watchApologyDeadline makes sure our apologies make it into the chain before the apologizing
+// phase ends. Missing the deadline makes the DKG fail or gets us slashed, so if the apologies are
+// not in the chain ApologyDeadlineMargin blocks before the deadline, we raise an alert and send
+// them again ahead of any other action.
+func (dcdr *Decider) watchApologyDeadline(dkg *DKG, eon observe.Eon) {
+	if dkg.Pure.Phase != puredkg.Apologizing || len(dkg.Apologies) == 0 {
+		return
+	}
+	if hasApologized(eon, dcdr.Config.Address()) {
+		return
+	}
+	deadline := eon.StartHeight + dkg.PhaseLength.Apologizing
+	remaining := deadline - (dcdr.Shutter.CurrentBlock + 1)
+	if remaining < 0 || remaining > int64(dcdr.Config.ApologyDeadlineMargin) {
+		return
+	}
+	log.Printf(
+		"Urgent: our apologies for %d accusations in eon %d are not in the chain, %d blocks left until the deadline at %d",
+		len(dkg.Apologies), dkg.Eon, remaining, deadline,
+	)
+	if dcdr.Shutter.CurrentBlock < dkg.ApologySentHeight+apologyResendInterval {
+		return
+	}
+	dkg.ApologySentHeight = dcdr.Shutter.CurrentBlock
+	dcdr.addPriorityShuttermintMessage(
+		fmt.Sprintf("apologies (re-sent), eon=%d, count=%d", dkg.Eon, len(dkg.Apologies)),
+		dkg.newApology(dkg.Apologies))
+}
@@ -0,0 +1,61 @@
+package keyper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestWatchApologyDeadline(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	config := Config{SigningKey: signingKey, ApologyDeadlineMargin: 3}
+	keypers := []common.Address{config.Address(), common.BigToAddress(common.Big2)}
+
+	pure := puredkg.NewPureDKG(1, 2, 2, 0)
+	pure.Phase = puredkg.Apologizing
+	dkg := DKG{
+		Eon:               1,
+		Keypers:           keypers,
+		Pure:              &pure,
+		PhaseLength:       NewConstantPhaseLength(10),
+		Apologies:         []puredkg.ApologyMsg{{Eon: 1, Accuser: 1, Accused: 0, Eval: big.NewInt(5)}},
+		ApologySentHeight: 120,
+	}
+	eon := observe.Eon{Eon: 1, StartHeight: 100} // apologizing phase ends at 130
+	shutter := observe.NewShutter()
+	dcdr := Decider{Config: config, Shutter: shutter}
+
+	// enough time left
+	shutter.CurrentBlock = 125
+	dcdr.watchApologyDeadline(&dkg, eon)
+	assert.Equal(t, len(dcdr.Actions), 0)
+
+	dcdr.Actions = []fx.IAction{&fx.SendShuttermintMessage{Description: "other"}}
+	shutter.CurrentBlock = 126
+	dcdr.watchApologyDeadline(&dkg, eon)
+	assert.Equal(t, len(dcdr.Actions), 2)
+	resent := dcdr.Actions[1].(*fx.SendShuttermintMessage)
+	assert.Equal(t, resent.Msg.GetApology().Eon, uint64(1))
+	assert.Assert(t, resent.Priority)
+	assert.Equal(t, dkg.ApologySentHeight, int64(126))
+
+	// don't re-send in every block
+	shutter.CurrentBlock = 127
+	dcdr.watchApologyDeadline(&dkg, eon)
+	assert.Equal(t, len(dcdr.Actions), 2)
+
+	// apology made it into the chain
+	eon.Apologies = append(eon.Apologies, shutterevents.Apology{Height: 127, Sender: keypers[0], Eon: 1})
+	shutter.CurrentBlock = 128
+	dcdr.watchApologyDeadline(&dkg, eon)
+	assert.Equal(t, len(dcdr.Actions), 2)
+}
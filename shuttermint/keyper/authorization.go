@@ -0,0 +1,215 @@
+package keyper
+
+import (
+	"bytes"
+	"encoding/gob"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// Appeals and the execution of cipher batches require the signatures of a threshold of keypers on
+// the decryption of the batch. Usually we get them from the decryption signatures every keyper
+// broadcasts, but some may be missing, e.g. if a keyper's message didn't make it into the chain.
+// In that case we ask the other keypers of the batch for their signature with direct messages and
+// collect the answers per half step in the state. If we still lack signatures AuthorizationTimeout
+// shuttermint blocks later, we ask again.
+
+const (
+	authorizationRequestTopic   = "authorization-request"
+	authorizationSignatureTopic = "authorization-signature"
+)
+
+func init() {
+	registerDirectMessageHandler(authorizationRequestTopic, handleAuthorizationRequest)
+	registerDirectMessageHandler(authorizationSignatureTopic, handleAuthorizationSignature)
+}
+
+// AuthorizationRequest holds the signatures we've collected for the authorization of a cipher
+// half step.
+type AuthorizationRequest struct {
+	BatchHash     []byte // the decrypted batch hash we've asked to sign
+	RequestHeight int64  // shuttermint height we've last asked at
+	Signatures    map[common.Address][]byte
+}
+
+// authorizationMessage is the payload of requests and of the answers to them. Signature is empty
+// in requests.
+type authorizationMessage struct {
+	HalfStep  uint64
+	BatchHash []byte
+	Signature []byte
+}
+
+func encodeAuthorizationMessage(msg authorizationMessage) []byte {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(msg); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func decodeAuthorizationMessage(payload []byte) (authorizationMessage, error) {
+	var msg authorizationMessage
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&msg); err != nil {
+		return authorizationMessage{}, errors.Wrap(err, "failed to decode authorization message")
+	}
+	return msg, nil
+}
+
+// authorizationSignatures returns the signatures we have for the decryption of the batch of the
+// given cipher half step, the broadcast ones as well as the ones collected with direct messages.
+func (dcdr *Decider) authorizationSignatures(halfStep uint64, batch *Batch) map[common.Address][]byte {
+	signatures := make(map[common.Address][]byte)
+	for sender, signature := range batch.VerifiedSignatures {
+		signatures[sender] = signature
+	}
+	req, ok := dcdr.State.AuthorizationRequests[halfStep]
+	if ok && bytes.Equal(req.BatchHash, batch.DecryptedBatchHash) {
+		for sender, signature := range req.Signatures {
+			signatures[sender] = signature
+		}
+	}
+	return signatures
+}
+
+// collectAuthorization returns the signatures for the authorization of the given cipher half step
+// and true if we have enough of them. Otherwise, it asks the keypers we lack signatures from.
+func (dcdr *Decider) collectAuthorization(
+	halfStep uint64, batch *Batch, config contract.BatchConfig,
+) (map[common.Address][]byte, bool) {
+	signatures := dcdr.authorizationSignatures(halfStep, batch)
+	if uint64(len(signatures)) >= config.Threshold {
+		return signatures, true
+	}
+	dcdr.requestAuthorizationSignatures(halfStep, batch, config, signatures)
+	return signatures, false
+}
+
+func (dcdr *Decider) requestAuthorizationSignatures(
+	halfStep uint64, batch *Batch, config contract.BatchConfig, signatures map[common.Address][]byte,
+) {
+	if dcdr.State.AuthorizationRequests == nil {
+		dcdr.State.AuthorizationRequests = make(map[uint64]*AuthorizationRequest)
+	}
+	req, ok := dcdr.State.AuthorizationRequests[halfStep]
+	if ok && bytes.Equal(req.BatchHash, batch.DecryptedBatchHash) {
+		timeout := int64(dcdr.Config.AuthorizationTimeout)
+		if timeout == 0 || dcdr.Shutter.CurrentBlock < req.RequestHeight+timeout {
+			return // still waiting for answers
+		}
+		log.Printf("Only %d of %d signatures for half step %d after %d blocks, asking again",
+			len(signatures), config.Threshold, halfStep, dcdr.Shutter.CurrentBlock-req.RequestHeight)
+	} else {
+		req = &AuthorizationRequest{
+			BatchHash:  batch.DecryptedBatchHash,
+			Signatures: make(map[common.Address][]byte),
+		}
+		dcdr.State.AuthorizationRequests[halfStep] = req
+	}
+	req.RequestHeight = dcdr.Shutter.CurrentBlock
+
+	payload := encodeAuthorizationMessage(authorizationMessage{
+		HalfStep:  halfStep,
+		BatchHash: batch.DecryptedBatchHash,
+	})
+	us := dcdr.Config.Address()
+	for _, keyper := range config.Keypers {
+		if _, ok := signatures[keyper]; ok || keyper == us {
+			continue
+		}
+		err := dcdr.sendDirectMessage(keyper, authorizationRequestTopic, payload)
+		if err != nil {
+			log.Printf("Cannot ask keyper %s for the signature of half step %d: %s", keyper.Hex(), halfStep, err)
+		}
+	}
+}
+
+// authorizationBatch returns the batch of the given cipher half step if the sender is one of its
+// keypers.
+func (dcdr *Decider) authorizationBatch(halfStep uint64, sender common.Address) (*Batch, error) {
+	step := protocol.HalfStep(halfStep)
+	if !step.IsCipher() {
+		return nil, errors.Errorf("half step %d is not a cipher half step", halfStep)
+	}
+	batchIndex := uint64(step.BatchIndex())
+	config, ok := dcdr.MainChain.ConfigForBatchIndex(batchIndex)
+	if !ok || !config.IsKeyper(sender) {
+		return nil, errors.Errorf("%s is not a keyper of batch %d", sender.Hex(), batchIndex)
+	}
+	batch, ok := dcdr.State.Batches[batchIndex]
+	if !ok || batch.IsEmpty {
+		return nil, errors.Errorf("batch %d unknown", batchIndex)
+	}
+	return batch, nil
+}
+
+// handleAuthorizationRequest sends our signature to the sender if we agree on the decryption.
+func handleAuthorizationRequest(dcdr *Decider, sender common.Address, payload []byte) {
+	msg, err := decodeAuthorizationMessage(payload)
+	if err != nil {
+		log.Printf("Ignoring authorization request from %s: %s", sender.Hex(), err)
+		return
+	}
+	batch, err := dcdr.authorizationBatch(msg.HalfStep, sender)
+	if err != nil {
+		log.Printf("Ignoring authorization request from %s: %s", sender.Hex(), err)
+		return
+	}
+	if !bytes.Equal(batch.DecryptedBatchHash, msg.BatchHash) {
+		log.Printf("Not signing half step %d for %s, we've decrypted a different batch", msg.HalfStep, sender.Hex())
+		return
+	}
+	signature, err := crypto.Sign(batch.DecryptionSignatureHash, dcdr.Config.SigningKey)
+	if err != nil {
+		log.Panicf("Cannot sign the decryption signature: %s", err)
+	}
+	msg.Signature = signature
+	err = dcdr.sendDirectMessage(sender, authorizationSignatureTopic, encodeAuthorizationMessage(msg))
+	if err != nil {
+		log.Printf("Cannot send the signature of half step %d to %s: %s", msg.HalfStep, sender.Hex(), err)
+	}
+}
+
+// handleAuthorizationSignature adds the signature to the ones collected for the half step if we've
+// asked for it.
+func handleAuthorizationSignature(dcdr *Decider, sender common.Address, payload []byte) {
+	msg, err := decodeAuthorizationMessage(payload)
+	if err != nil {
+		log.Printf("Ignoring authorization signature from %s: %s", sender.Hex(), err)
+		return
+	}
+	req, ok := dcdr.State.AuthorizationRequests[msg.HalfStep]
+	if !ok || !bytes.Equal(req.BatchHash, msg.BatchHash) {
+		return // we haven't asked for it, or not anymore
+	}
+	batch, err := dcdr.authorizationBatch(msg.HalfStep, sender)
+	if err != nil {
+		log.Printf("Ignoring authorization signature from %s: %s", sender.Hex(), err)
+		return
+	}
+	if !batch.VerifySignature(sender, msg.Signature) {
+		log.Printf("Ignoring bad authorization signature for half step %d from %s", msg.HalfStep, sender.Hex())
+		return
+	}
+	req.Signatures[sender] = msg.Signature
+}
+
+// syncAuthorizationRequests removes the signatures collected for half steps that have been
+// executed and can't be appealed anymore.
+func (dcdr *Decider) syncAuthorizationRequests() {
+	for halfStep := range dcdr.State.AuthorizationRequests {
+		if halfStep >= dcdr.MainChain.NumExecutionHalfSteps {
+			continue
+		}
+		if accusation, ok := dcdr.MainChain.Accusations[halfStep]; ok && !accusation.Appealed {
+			continue
+		}
+		delete(dcdr.State.AuthorizationRequests, halfStep)
+	}
+}
@@ -0,0 +1,109 @@
+package keyper
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// deliverDirectMessages puts the direct messages sent by the decider into the shutter state as if
+// they had been included at the given height.
+func deliverDirectMessages(t *testing.T, from *Decider, shutter *observe.Shutter, height int64) {
+	t.Helper()
+	for _, action := range from.Actions {
+		msg := action.(*fx.SendShuttermintMessage).Msg.GetDirectMessage()
+		assert.Assert(t, msg != nil)
+		shutter.DirectMessages = append(shutter.DirectMessages, shutterevents.DirectMessage{
+			Height:           height,
+			Sender:           from.Config.Address(),
+			Receiver:         common.BytesToAddress(msg.Receiver),
+			Topic:            msg.Topic,
+			EncryptedPayload: msg.EncryptedPayload,
+		})
+	}
+	from.Actions = nil
+}
+
+func TestCollectAuthorization(t *testing.T) {
+	shutter := observe.NewShutter()
+	mainChain := observe.NewMainChain(0)
+	config := contract.BatchConfig{Threshold: 2, BatchSpan: 10}
+	batchIndex := uint64(1)
+	halfStep := uint64(protocol.BatchIndex(batchIndex).CipherHalfStep())
+	decryptionSignatureHash := crypto.Keccak256([]byte("decryption"))
+
+	var deciders []*Decider
+	for i := 0; i < 3; i++ {
+		signingKey, err := crypto.GenerateKey()
+		assert.NilError(t, err)
+		encryptionKey, err := ecies.GenerateKey(rand.Reader, crypto.S256(), nil)
+		assert.NilError(t, err)
+		kc := Config{SigningKey: signingKey, EncryptionKey: encryptionKey, AuthorizationTimeout: 20}
+		config.Keypers = append(config.Keypers, kc.Address())
+		publicKey := encryptionKey.PublicKey
+		shutter.KeyperEncryptionKeys[kc.Address()] = (*observe.EncryptionPublicKey)(&publicKey)
+		shutter.Capabilities[kc.Address()] = shutterevents.Capabilities{Sender: kc.Address(), Features: supportedFeatures()}
+
+		st := NewState()
+		st.Batches[batchIndex] = &Batch{
+			BatchIndex:              batchIndex,
+			DecryptionSignatureHash: decryptionSignatureHash,
+			DecryptedBatchHash:      []byte("batch"),
+		}
+		deciders = append(deciders, &Decider{Config: kc, State: st, Shutter: shutter, MainChain: mainChain})
+	}
+	mainChain.BatchConfigs = []contract.BatchConfig{config}
+	a, b, c := deciders[0], deciders[1], deciders[2]
+	c.State.Batches[batchIndex].DecryptedBatchHash = []byte("other batch")
+
+	// we have our own signature, but need another one
+	ownSignature, err := crypto.Sign(decryptionSignatureHash, a.Config.SigningKey)
+	assert.NilError(t, err)
+	batch := a.State.Batches[batchIndex]
+	batch.AddSignature(a.Config.Address(), ownSignature)
+
+	shutter.CurrentBlock = 10
+	_, ok := a.collectAuthorization(halfStep, batch, config)
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(a.Actions), 2)
+
+	// don't ask again before the timeout
+	shutter.CurrentBlock = 15
+	_, ok = a.collectAuthorization(halfStep, batch, config)
+	assert.Assert(t, !ok)
+	assert.Equal(t, len(a.Actions), 2)
+
+	// b agrees and answers, c has decrypted something else
+	deliverDirectMessages(t, a, shutter, 16)
+	b.State.SyncHeight = 16
+	b.handleDirectMessages()
+	c.State.SyncHeight = 16
+	c.handleDirectMessages()
+	assert.Equal(t, len(b.Actions), 1)
+	assert.Equal(t, len(c.Actions), 0)
+
+	deliverDirectMessages(t, b, shutter, 17)
+	a.State.SyncHeight = 17
+	a.handleDirectMessages()
+	signatures, ok := a.collectAuthorization(halfStep, batch, config)
+	assert.Assert(t, ok)
+	assert.Equal(t, len(signatures), 2)
+	assert.Assert(t, batch.VerifySignature(b.Config.Address(), signatures[b.Config.Address()]))
+
+	// the collected signatures are kept until the half step has been executed
+	a.syncAuthorizationRequests()
+	assert.Equal(t, len(a.State.AuthorizationRequests), 1)
+	mainChain.NumExecutionHalfSteps = halfStep + 1
+	a.syncAuthorizationRequests()
+	assert.Equal(t, len(a.State.AuthorizationRequests), 0)
+}
@@ -0,0 +1,117 @@
+package keyper
+
+import (
+	"log"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// messageOverhead approximates the bytes a shuttermint transaction adds to the message it
+// carries, i.e. the signature, the chain id and the nonce.
+const messageOverhead = crypto.SignatureLength + 48
+
+// shuttermintMessageSize approximates the size of the transaction sending the given message.
+func shuttermintMessageSize(msg *shmsg.Message) uint64 {
+	return uint64(proto.Size(msg) + messageOverhead)
+}
+
+// bandwidthThrottle holds back the release of our epoch secret key shares while we use more than
+// our fair share of the shuttermint chain's bandwidth, see Config.ShuttermintBandwidth. The other
+// keypers may release the keys in the meantime, in which case we don't send our shares at all.
+// Releases are held back for a limited number of blocks only, so that keys are still released
+// if all keypers are above their share. Once a hold expires, all pending releases are allowed
+// for the rest of the block, and releases pending afterwards are held back again.
+type bandwidthThrottle struct {
+	bandwidth  uint64 // bytes per block for all keypers together
+	maxBlocks  int64  // hold back releases at most this many blocks
+	holding    bool   // releases are currently held back
+	heldSince  int64  // shuttermint height the current hold has started at
+	releasedAt int64  // shuttermint height the last hold has expired at
+}
+
+func newBandwidthThrottle(bandwidth uint64, maxBlocks uint64) *bandwidthThrottle {
+	return &bandwidthThrottle{
+		bandwidth:  bandwidth,
+		maxBlocks:  int64(maxBlocks),
+		releasedAt: -1,
+	}
+}
+
+// fairShare returns the number of bytes each of the given number of keypers may send in the
+// usage window ending at the given height.
+func (t *bandwidthThrottle) fairShare(height int64, numKeypers int) uint64 {
+	blocks := int64(observe.UsageWindow)
+	if height+1 < blocks {
+		blocks = height + 1
+	}
+	if blocks < 1 {
+		blocks = 1
+	}
+	return t.bandwidth * uint64(blocks) / uint64(numKeypers)
+}
+
+// holdBack checks if a release should be held back at the given height, given our projected
+// usage and our fair share.
+func (t *bandwidthThrottle) holdBack(height int64, projected uint64, share uint64) bool {
+	if projected <= share {
+		if t.holding {
+			log.Printf("Shuttermint usage of %d bytes back within our share of %d bytes, no longer throttling", projected, share)
+			t.holding = false
+		}
+		return false
+	}
+	if height == t.releasedAt {
+		return false
+	}
+	if !t.holding {
+		log.Printf("Warning: shuttermint usage of %d bytes exceeds our share of %d bytes, holding back key releases", projected, share)
+		t.holding = true
+		t.heldSince = height
+	}
+	if height-t.heldSince < t.maxBlocks {
+		return true
+	}
+	t.holding = false
+	t.releasedAt = height
+	return false
+}
+
+// projectedUsage returns the number of bytes we've sent in the current usage window, including
+// the messages we're about to send.
+func (dcdr *Decider) projectedUsage() uint64 {
+	height := dcdr.Shutter.CurrentBlock
+	usage := dcdr.Shutter.Usage.Recent(height)[dcdr.Config.Address()].Bytes + dcdr.PendingBytes
+	for _, action := range dcdr.Actions {
+		if a, ok := action.(*fx.SendShuttermintMessage); ok {
+			usage += shuttermintMessageSize(a.Msg)
+		}
+	}
+	return usage
+}
+
+// throttleKeyRelease checks if the release of our next share should be held back because we're
+// above our fair share of the shuttermint chain's bandwidth, see bandwidthThrottle.
+func (dcdr *Decider) throttleKeyRelease(numKeypers int) bool {
+	if dcdr.Throttle == nil || numKeypers == 0 {
+		return false
+	}
+	height := dcdr.Shutter.CurrentBlock
+	return dcdr.Throttle.holdBack(height, dcdr.projectedUsage(), dcdr.Throttle.fairShare(height, numKeypers))
+}
+
+// pendingMessageBytes returns the size of the shuttermint messages among the pending actions.
+func (kpr *Keyper) pendingMessageBytes() uint64 {
+	if kpr.runenv == nil {
+		return 0
+	}
+	var size uint64
+	for _, msg := range kpr.runenv.PendingActions.ShuttermintMessages() {
+		size += shuttermintMessageSize(msg)
+	}
+	return size
+}
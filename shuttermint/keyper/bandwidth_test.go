@@ -0,0 +1,65 @@
+package keyper
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestBandwidthThrottle(t *testing.T) {
+	throttle := newBandwidthThrottle(100, 3)
+	assert.Equal(t, throttle.fairShare(9, 4), uint64(250))
+	assert.Equal(t, throttle.fairShare(10*observe.UsageWindow, 4), uint64(25*observe.UsageWindow))
+
+	assert.Assert(t, !throttle.holdBack(10, 100, 100))
+	assert.Assert(t, throttle.holdBack(10, 101, 100))
+	assert.Assert(t, throttle.holdBack(12, 101, 100))
+	// the hold expires, everything pending is released in that block
+	assert.Assert(t, !throttle.holdBack(13, 101, 100))
+	assert.Assert(t, !throttle.holdBack(13, 101, 100))
+	// and a new hold starts afterwards
+	assert.Assert(t, throttle.holdBack(14, 101, 100))
+	assert.Assert(t, !throttle.holdBack(15, 50, 100))
+	assert.Assert(t, throttle.holdBack(16, 101, 100))
+}
+
+func TestThrottleKeyRelease(t *testing.T) {
+	dcdr := policyTestDecider(t, nil)
+	dcdr.State.EKGs[0].Keypers = dcdr.Shutter.BatchConfigs[0].Keypers
+	dcdr.State.EKGs[0].EpochKG = singleKeyperEpochKG(t, 1)
+	dcdr.Throttle = newBandwidthThrottle(10, 5)
+	dcdr.Shutter.CurrentBlock = 100
+	dcdr.Shutter.Usage = &observe.Usage{
+		Total: map[common.Address]observe.MessageUsage{},
+		Blocks: []observe.BlockUsage{{Height: 90, Senders: map[common.Address]observe.MessageUsage{
+			dcdr.Config.Address(): {Messages: 10, Bytes: 5000},
+		}}},
+	}
+
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, len(dcdr.Actions), 0)
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(0))
+
+	dcdr.Shutter.CurrentBlock = 105
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, len(dcdr.Actions), 5)
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(5))
+
+	// the shares we're about to send count towards our usage
+	dcdr = policyTestDecider(t, nil)
+	dcdr.State.EKGs[0].Keypers = dcdr.Shutter.BatchConfigs[0].Keypers
+	dcdr.State.EKGs[0].EpochKG = singleKeyperEpochKG(t, 1)
+	dcdr.Shutter.CurrentBlock = 0
+	size := func(epoch uint64) uint64 {
+		share := dcdr.State.EKGs[0].EpochKG.ComputeEpochSecretKeyShare(epoch)
+		return shuttermintMessageSize(shmsg.NewNamespacedEpochSecretKeyShare(1, "", epoch, share))
+	}
+	dcdr.Throttle = newBandwidthThrottle(size(0)+size(1)-1, 5)
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, len(dcdr.Actions), 2)
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(2))
+}
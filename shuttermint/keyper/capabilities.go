@@ -0,0 +1,107 @@
+package keyper
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/shversion"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// Keypers announce their software version and the optional sub-protocols they support, their
+// features, with a Capabilities message. Before using an optional sub-protocol with another
+// keyper, we check that the keyper has announced support for it, see supports. That way new
+// sub-protocols can be rolled out one keyper at a time: upgraded keypers use them among each
+// other and keep working with the others as before. Keypers that haven't announced anything, e.g.
+// because they run an older version, are assumed to support none of them.
+//
+// We announce our capabilities whenever they differ from our latest announcement in the chain,
+// e.g. after an upgrade, and again every CapabilityInterval shuttermint blocks, so that the other
+// keypers' view doesn't depend on a single old message.
+
+// capabilityAnnouncementTimeout is the number of shuttermint blocks after which we send our
+// announcement again, if it hasn't made it into the chain.
+const capabilityAnnouncementTimeout int64 = 10
+
+var features = make(map[string]bool)
+
+// registerFeature registers an optional sub-protocol we support. It is meant to be called from
+// init functions.
+func registerFeature(feature string) {
+	if features[feature] {
+		panic("duplicate feature " + feature)
+	}
+	features[feature] = true
+}
+
+// supportedFeatures returns the features we support, sorted.
+func supportedFeatures() []string {
+	var res []string
+	for feature := range features {
+		res = append(res, feature)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// directMessageFeature is the feature of handling direct messages with the given topic. It is
+// registered together with the handler, see registerDirectMessageHandler.
+func directMessageFeature(topic string) string {
+	return "direct-message/" + topic
+}
+
+// supports checks if the given keyper supports the feature according to its latest announcement.
+func (dcdr *Decider) supports(keyper common.Address, feature string) bool {
+	if keyper == dcdr.Config.Address() {
+		return features[feature]
+	}
+	capabilities, ok := dcdr.Shutter.Capabilities[keyper]
+	if !ok {
+		return false
+	}
+	for _, f := range capabilities.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// announcedCapabilities checks if the announcement matches the given version and features.
+func announcedCapabilities(announcement shutterevents.Capabilities, version string, features []string) bool {
+	if announcement.Version != version || len(announcement.Features) != len(features) {
+		return false
+	}
+	for i, feature := range features {
+		if announcement.Features[i] != feature {
+			return false
+		}
+	}
+	return true
+}
+
+// maybeAnnounceCapabilities sends our capabilities if they have changed since our latest
+// announcement or if it is older than CapabilityInterval.
+func (dcdr *Decider) maybeAnnounceCapabilities() {
+	version := shversion.GetInfo().Version
+	features := supportedFeatures()
+	height := dcdr.Shutter.CurrentBlock
+	latest, ok := dcdr.Shutter.Capabilities[dcdr.Config.Address()]
+	if ok && announcedCapabilities(latest, version, features) {
+		interval := int64(dcdr.Config.CapabilityInterval)
+		if interval == 0 || height < latest.Height+interval {
+			return
+		}
+	}
+	sent := dcdr.State.CapabilitiesSentHeight
+	if sent > latest.Height && height < sent+capabilityAnnouncementTimeout {
+		return // still waiting for our announcement to make it into the chain
+	}
+	dcdr.State.CapabilitiesSentHeight = height
+	dcdr.sendShuttermintMessage(
+		fmt.Sprintf("capabilities, version=%s, features=%v", version, features),
+		shmsg.NewCapabilities(version, features))
+}
@@ -0,0 +1,70 @@
+package keyper
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/shversion"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestMaybeAnnounceCapabilities(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	kc := Config{SigningKey: signingKey, CapabilityInterval: 100}
+	shutter := observe.NewShutter()
+	dcdr := Decider{Config: kc, State: NewState(), Shutter: shutter}
+	announce := func(height int64) int {
+		dcdr.Actions = nil
+		shutter.CurrentBlock = height
+		dcdr.maybeAnnounceCapabilities()
+		return len(dcdr.Actions)
+	}
+
+	assert.Equal(t, announce(10), 1)
+	msg := dcdr.Actions[0].(*fx.SendShuttermintMessage).Msg.GetCapabilities()
+	assert.Assert(t, msg != nil)
+	assert.Equal(t, msg.Version, shversion.GetInfo().Version)
+	assert.DeepEqual(t, msg.Features, supportedFeatures())
+
+	// we wait for the announcement to make it into the chain before sending it again
+	assert.Equal(t, announce(15), 0)
+	assert.Equal(t, announce(20), 1)
+
+	shutter.Capabilities[kc.Address()] = shutterevents.Capabilities{
+		Height:   21,
+		Sender:   kc.Address(),
+		Version:  msg.Version,
+		Features: msg.Features,
+	}
+	assert.Equal(t, announce(22), 0)
+	assert.Equal(t, announce(121), 1)
+
+	// announcements not matching our capabilities are replaced right away
+	shutter.Capabilities[kc.Address()] = shutterevents.Capabilities{Height: 122, Sender: kc.Address(), Version: "old"}
+	assert.Equal(t, announce(123), 1)
+}
+
+func TestSupports(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	kc := Config{SigningKey: signingKey}
+	other, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	otherAddress := crypto.PubkeyToAddress(other.PublicKey)
+	shutter := observe.NewShutter()
+	dcdr := Decider{Config: kc, State: NewState(), Shutter: shutter}
+
+	feature := directMessageFeature(authorizationRequestTopic)
+	assert.Assert(t, dcdr.supports(kc.Address(), feature))
+	assert.Assert(t, !dcdr.supports(kc.Address(), "unknown"))
+	assert.Assert(t, !dcdr.supports(otherAddress, feature), "keypers that haven't announced anything support nothing")
+
+	shutter.Capabilities[otherAddress] = shutterevents.Capabilities{Sender: otherAddress, Features: []string{feature}}
+	assert.Assert(t, dcdr.supports(otherAddress, feature))
+	assert.Assert(t, !dcdr.supports(otherAddress, directMessageFeature(authorizationSignatureTopic)))
+}
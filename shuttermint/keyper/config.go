@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -16,30 +18,162 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
 )
 
-// Config contains validated configuration parameters for the keyper client.
+// Config contains validated configuration parameters for the keyper client. The fields are
+// grouped by feature, the config file template documents them in more detail.
 type Config struct {
-	ShuttermintURL              string
-	EthereumURL                 string
-	DBDir                       string
-	SigningKey                  *ecdsa.PrivateKey
-	ValidatorKey                ed25519.PrivateKey `mapstructure:"ValidatorSeed"`
-	EncryptionKey               *ecies.PrivateKey
+	Profile string // "mainnet", "testnet", or "dev" for the defaults and interlocks of a profile, empty for none
+
+	// Connections and keys
+	ShuttermintURL string
+	EthereumURL    string
+	DBDir          string
+	SigningKey     *ecdsa.PrivateKey
+	ValidatorKey   ed25519.PrivateKey `mapstructure:"ValidatorSeed"`
+	EncryptionKey  *ecies.PrivateKey
+
+	// Contracts
 	ConfigContractAddress       common.Address `mapstructure:"ConfigContract"`
 	BatcherContractAddress      common.Address `mapstructure:"BatcherContract"`
 	KeyBroadcastContractAddress common.Address `mapstructure:"KeyBroadcastContract"`
 	ExecutorContractAddress     common.Address `mapstructure:"ExecutorContract"`
 	DepositContractAddress      common.Address `mapstructure:"DepositContract"`
 	KeyperSlasherAddress        common.Address `mapstructure:"KeyperSlasher"`
-	MainChainFollowDistance     uint64         // in main chain blocks
-	ExecutionStaggering         uint64         // in main chain blocks
-	DKGPhaseLength              uint64         // in shuttermint blocks
-	GasPriceMultiplier          float64
+	DecryptionOracleAddress     common.Address `mapstructure:"DecryptionOracleContract"` // optional
+	ContractCodeHashes          []string       // known contract deployments as "Name=0xcodehash"
+	RequireKnownContracts       bool           // refuse to start if a contract's code hash is unknown
+	ABIDriftCheckInterval       time.Duration  // check recent contract logs against our ABIs this often, 0 to check at startup only
+	ExecutorRoutes              []string       // additional executor contracts as "startBatchIndex:address[:version]"
+	ContractCacheTTL            time.Duration  // how long to cache mutable contract state, 0 to disable
+	RPCCacheSize                int            // number of node answers about final blocks cached in memory, 0 to disable
+	RPCCacheOnDisk              bool           // also cache them in DBDir
+
+	// Main chain transactions
+	MainChainFollowDistance    uint64 // in main chain blocks
+	BatchClosureConfirmations  uint64 // in main chain blocks, 0 for MainChainFollowDistance
+	ConfigChangeConfirmations  uint64 // in main chain blocks, 0 for MainChainFollowDistance
+	AccusationConfirmations    uint64 // in main chain blocks, 0 for MainChainFollowDistance
+	OracleSubmissionStaggering uint64 // in main chain blocks
+	ExecutionStaggering        uint64 // in main chain blocks
+	GasPriceMultiplier         float64
+	GasPriceOracle             string        // "node", "fixed:<gwei>", "eip1559" or "percentile:<percentile>[:<blocks>]"
+	MaxGasPrices               []string      // caps on the fees per gas by action type as "ActionType=gwei"
+	ReplaceTXsAfter            time.Duration // replace transactions not mined by then with ones paying more, 0 to disable
+	MaxTXReplacements          uint64        // number of times a transaction is replaced at most
+
+	// Cipher batches, these must be the same for all keypers
+	DuplicateCiphertexts   string   // "drop" to leave copied ciphertexts out of cipher batches, "allow" otherwise
+	CiphertextReplayWindow uint64   // number of preceding batches whose ciphertexts must not be copied
+	TransactionFormat      string   // "raw", "proxy" or "signed", the format the target contract accepts
+	MaxTransactionSize     uint64   // maximum size of a decrypted transaction in bytes, 0 for no limit
+	DeniedTargets          []string // receivers proxy transactions must not be forwarded to
+
+	// DKG
+	DKGPhaseLength        uint64 // in shuttermint blocks
+	ApologyDeadlineMargin uint64 // in shuttermint blocks, alert and re-send missing apologies this close to the deadline
+
+	// in shuttermint blocks, alert about keypers we can't deal to for lack of an encryption key
+	// this close to the end of the dealing phase
+	EncryptionKeyDeadlineMargin uint64
+	AccuseMissingEncryptionKeys bool // accuse keypers we couldn't deal to for lack of an encryption key
+
+	// Backups of our eon secret key shares, see sharebackup
+	ShareBackupKeys      []string // public keys of the custodians, empty to disable
+	ShareBackupThreshold uint64   // number of custodians needed to recover a share
+	ShareBackupDir       string   // directory to export the backups to, empty for DBDir/share-backups
+
+	// Proposals for new batch configs, these must be the same for all keypers
+	EpochNamespaces   []string // epoch namespaces proposed in new batch configs
+	PerBlockEpochs    bool     // propose releasing keys per main chain block instead of per batch
+	CommitteeSize     uint64   // proposed number of keypers taking part in each DKG, 0 for all
+	EpochOffset       uint64   // proposed epoch of batch 0
+	EpochStride       uint64   // proposed number of epochs per batch, 0 for 1
+	EncryptionVersion uint64   // proposed version of the threshold encryption scheme
+
+	// Running actions and sending shuttermint messages
+	ActionTimeout         time.Duration // limit for a single attempt to run an action, 0 for none
+	ActionTimeouts        []string      // limits for specific action types as "ActionType=duration"
+	MessageDeliveryBlocks uint64        // in shuttermint blocks, re-send messages not in the chain by then, 0 to disable
+	MessageMaxResends     uint64        // give up on a message after re-sending it this often
+
+	// Self-throttling when sending more than our fair share of shuttermint messages
+	ShuttermintBandwidth  uint64 // bytes per shuttermint block all keypers together should stay below, 0 to disable
+	ThrottleReleaseBlocks uint64 // in shuttermint blocks, hold back our key releases at most this long
+
+	// Syncing
+	SyncTolerance      uint64   // start making decisions once this close to both chain tips
+	MaxBlocksBehind    uint64   // don't make decisions if further behind a chain tip, 0 to disable
+	ArchiveKeepEons    uint64   // number of recent eons kept in memory, older ones are archived in DBDir, 0 to disable archiving
+	StateRetentionEons uint64   // number of recent eons whose DKGs and EKGs are kept in the state, 0 to keep all
+	KeepBatches        uint64   // number of executed batches whose transactions are kept in memory, 0 to keep all
+	Retention          []string // retention policies by dataset, "dataset=full[:keep]"
+	AppealTimeout      uint64   // in main chain blocks, appeal again if our appeal hasn't been handled after this long
+
+	// in shuttermint blocks, ask the keypers for missing authorization signatures again after this
+	// long, 0 to ask only once
+	AuthorizationTimeout uint64
+
+	// freeze key releases and executions if shuttermint hasn't produced a block for this long, 0
+	// to disable
+	ShuttermintHaltTimeout time.Duration
+
+	// in shuttermint blocks, announce our version and supported features again after this long, 0
+	// to announce them only when they change
+	CapabilityInterval uint64
+
+	// Monitoring
+	KeyReleaseSLO            time.Duration // alert if key generation takes longer, 0 to disable
+	ValidatorWatchWindow     uint64        // number of recent shuttermint blocks to check our validator's signatures in, 0 to disable
+	ValidatorMaxMissedBlocks uint64        // alert if our validator missed more blocks within the watch window
+	MaxClockSkew             time.Duration // alert if the validators' clocks are further apart, 0 to disable
+	WatchKeyLeaks            bool          // alert about main chain transactions copying decrypted ones before execution
+	WebhookURLs              []string      // URLs to post accusations and slashings of any keyper to, empty to disable
+	WebhookDigestInterval    time.Duration // post incidents as a digest this often, 0 to post them right away
+	DecisionTraceSize        int           // number of decider steps to keep traces of, 0 to disable
+
+	// APIs
+	LightAPIListenAddress    string // address to serve eon key provenance on, empty to disable
+	EventStreamListenAddress string // address to serve the gRPC event stream on, empty to disable
+	ExplorerListenAddress    string // address to serve the batch explorer API on, empty to disable
+	MetricsListenAddress     string // address to serve Prometheus metrics on, empty to disable
+	AdminListenAddress       string // address to serve the admin API on, localhost if no host is given, empty to disable
+	APIAccess                string // "closed" to serve poly evals to keypers only, "open" otherwise
+	DeploymentID             string // namespace of the APIs and the event stream, empty for none
+	IPFSPublishURL           string // IPFS HTTP API to publish epoch secret keys and ceremony reports to, empty to disable
+
+	// further accounts allowed to use the admin API as "0xaddress=role", see access.ParseAccounts
+	AdminAccounts []string
+
+	// Relaying encrypted transactions of users without funds, see relayer
+	RelayerListenAddress string            // address to accept signed ciphertexts on, empty to disable
+	RelayerKey           *ecdsa.PrivateKey // key of the account paying for relayed transactions
+	RelayerQuotaWindow   time.Duration     // length of the windows the relayer quotas apply to
+	RelayerSenderQuota   uint64            // transactions relayed per sender and window, 0 for no limit
+	RelayerTotalQuota    uint64            // transactions relayed for all senders per window, 0 for no limit
+	RelayerMaxSize       uint64            // maximum size of a relayed ciphertext in bytes, 0 for no limit
+
+	// Hot standby
+	StandbyPrimaryURL    string        // admin API of the primary keyper to replicate, empty to run as primary
+	StandbyFailoverDelay time.Duration // take over once the primary has been down this long
 }
 
 const configTemplate = `# Shutter keyper configuration for {{ .Address }}
 
+# Profile with conservative defaults for chains with real funds ("mainnet") or permissive ones for
+# public test networks ("testnet") and local test setups ("dev"). Values set here override the
+# profile's defaults, but the keyper refuses to start with values that are dangerous for the
+# profile, e.g. too few confirmations for "mainnet", or with "testnet" or "dev" on a chain with
+# real funds. Leave empty to use the plain defaults.
+Profile = "{{ .Profile }}"
+
+# Send SIGHUP to reload this file. ExecutionStaggering, OracleSubmissionStaggering, the gas price
+# settings, ReplaceTXsAfter, MaxTXReplacements and the action timeouts are applied right away,
+# other changes after a restart. Changes of the keys, the contracts or DBDir are rejected.
+
 # Contract addresses
 BatcherContract		= "{{ .BatcherContractAddress }}"
 ConfigContract		= "{{ .ConfigContractAddress }}"
@@ -47,6 +181,8 @@ DepositContract		= "{{ .DepositContractAddress }}"
 ExecutorContract	= "{{ .ExecutorContractAddress }}"
 KeyBroadcastContract	= "{{ .KeyBroadcastContractAddress }}"
 KeyperSlasher		= "{{ .KeyperSlasherAddress }}"
+# Set to the zero address to disable submitting epoch keys to the decryption oracle
+DecryptionOracleContract	= "{{ .DecryptionOracleAddress }}"
 
 EthereumURL		= "{{ .EthereumURL }}"
 ShuttermintURL		= "{{ .ShuttermintURL }}"
@@ -54,12 +190,284 @@ DBDir			= "{{ .DBDir }}"
 DKGPhaseLength		= {{ .DKGPhaseLength }}
 ExecutionStaggering	= {{ .ExecutionStaggering }}
 MainChainFollowDistance = {{ .MainChainFollowDistance }}
+# Confirmations required before treating a batch as closed, applying a config change or acting on
+# an accusation or appeal, 0 to only require MainChainFollowDistance
+BatchClosureConfirmations = {{ .BatchClosureConfirmations }}
+ConfigChangeConfirmations = {{ .ConfigChangeConfirmations }}
+AccusationConfirmations = {{ .AccusationConfirmations }}
+OracleSubmissionStaggering = {{ .OracleSubmissionStaggering }}
 GasPriceMultiplier      = {{ .GasPriceMultiplier }}
+# How the fees of our main chain transactions are determined: "node" uses the gas price suggested
+# by the node times GasPriceMultiplier, "fixed:<gwei>" a fixed gas price, "eip1559" sends EIP-1559
+# transactions with the priority fee suggested by the node times GasPriceMultiplier, and
+# "percentile:<percentile>[:<blocks>]" pays the given percentile of the priority fees in the
+# recent blocks, 20 by default.
+GasPriceOracle          = "{{ .GasPriceOracle }}"
+# Never pay more than this many gwei per gas for the transactions of these action types, e.g.
+# "ExecuteCipherBatch=200". Transactions that aren't mined because of a cap are retried like
+# others. Capping Accuse and Appeal risks losing them.
+MaxGasPrices = [{{ range $i, $p := .MaxGasPrices }}{{ if $i }}, {{ end }}{{ printf "%q" $p }}{{ end }}]
+# Replace transactions that haven't been mined after ReplaceTXsAfter, e.g. because of a gas price
+# spike, with transactions using the same nonce and paying at least 10% higher fees, up to
+# MaxTXReplacements times. This keeps batch executions from stalling. "0s" disables replacing.
+ReplaceTXsAfter = "{{ .ReplaceTXsAfter }}"
+MaxTXReplacements = {{ .MaxTXReplacements }}
+KeyReleaseSLO           = "{{ .KeyReleaseSLO }}"
+ContractCacheTTL        = "{{ .ContractCacheTTL }}"
+# Cache the main chain headers and blocks and the Shuttermint transactions of final blocks, which
+# the observers and APIs query over and over, in memory, keeping up to this many entries. Set
+# RPCCacheOnDisk to also keep them in DBDir across restarts. 0 disables the cache.
+RPCCacheSize            = {{ .RPCCacheSize }}
+RPCCacheOnDisk          = {{ .RPCCacheOnDisk }}
+# Number of blocks we may be behind the Shuttermint and main chain tips after startup before we
+# start making decisions
+SyncTolerance           = {{ .SyncTolerance }}
+# Don't make any decisions while we're more than this many blocks behind one of the chains, 0 to
+# disable
+MaxBlocksBehind         = {{ .MaxBlocksBehind }}
+# Move the events of all but this many recent eons from memory to the eon archive in DBDir (see
+# "shuttermint show --archive-dir"). 0 disables archiving.
+ArchiveKeepEons         = {{ .ArchiveKeepEons }}
+# Remove the DKGs and EKGs of all but this many recent eons from the state once the eons are
+# archived and all their batches have been executed. 0 keeps them forever. The admin API prunes the
+# state on demand at /state/prune. The eons before the oldest one left in the state are dropped
+# from memory too, together with the record of the DKG messages received for them.
+StateRetentionEons      = {{ .StateRetentionEons }}
+# Drop the main chain transactions of all but this many executed batches from memory. The receipts
+# of their execution are kept for appeals. Must be at least CiphertextReplayWindow. 0 keeps them
+# forever.
+KeepBatches             = {{ .KeepBatches }}
+# Retention policies of the data kept about past eons, each given as "dataset=full[:keep]": the
+# most recent full eons are kept fully, older eons only as a summary, and nothing is kept about the
+# eons beyond the most recent keep ones. 0 as full or no keep keeps all eons. The datasets are
+# "observe", the events in memory, "state", the DKGs and EKGs in the state, and "archive", the
+# events in the eon archive, whose summaries keep the DKG and epoch statistics only. The policies
+# of observe and state default to ArchiveKeepEons and StateRetentionEons, the one of archive to
+# keeping everything.
+Retention               = [{{ range $i, $r := .Retention }}{{ if $i }}, {{ end }}{{ printf "%q" $r }}{{ end }}]
+# Send our appeal against an accusation again if it hasn't been handled this many main chain
+# blocks after we've sent it. 0 never sends it again.
+AppealTimeout           = {{ .AppealTimeout }}
+# Appeals and cipher executions need the signatures of a threshold of keypers. Ask the keypers for
+# missing ones again if they haven't arrived this many Shuttermint blocks after we've asked. 0 asks
+# only once.
+AuthorizationTimeout    = {{ .AuthorizationTimeout }}
+# If Shuttermint hasn't produced a block for this long, e.g. because its consensus halted, stop
+# releasing keys and executing batches. Once it produces blocks again, the keypers vote on
+# Shuttermint and resume when a threshold of them agrees. The admin API ends the freeze on demand
+# at /halt/resume. 0 disables the check.
+ShuttermintHaltTimeout  = "{{ .ShuttermintHaltTimeout }}"
+# The keypers announce their version and the optional sub-protocols they support, and only use a
+# sub-protocol with keypers that have announced it. Announce ours again after this many
+# Shuttermint blocks. 0 announces them only when they change, e.g. after an upgrade.
+CapabilityInterval      = {{ .CapabilityInterval }}
+# Raise an alert and re-send our apologies if they're not in the Shuttermint chain this many blocks
+# before the end of the apologizing phase
+ApologyDeadlineMargin   = {{ .ApologyDeadlineMargin }}
+# Poly evals can only be sent to keypers that have checked in with their encryption key. Raise an
+# alert about keypers we still can't deal to this many blocks before the end of the dealing phase.
+EncryptionKeyDeadlineMargin = {{ .EncryptionKeyDeadlineMargin }}
+# Accuse the keypers whose encryption key didn't arrive before the end of the dealing phase. They
+# will accuse us anyway, and unless they apologize, they are excluded from the eon key.
+AccuseMissingEncryptionKeys = {{ .AccuseMissingEncryptionKeys }}
+# Check our validator's signatures in this many recent Shuttermint blocks and raise an alert if it
+# missed more than ValidatorMaxMissedBlocks of them or is about to leave the validator set. 0
+# disables the check.
+ValidatorWatchWindow    = {{ .ValidatorWatchWindow }}
+ValidatorMaxMissedBlocks = {{ .ValidatorMaxMissedBlocks }}
+# Raise an alert if the clocks of the Shuttermint validators, as seen in the timestamps of their
+# votes, are further apart than this within the watch window, e.g. "2s". 0 disables the check.
+MaxClockSkew            = "{{ .MaxClockSkew }}"
+# Scan the main chain for transactions copying decrypted transactions of a batch before the batch
+# is executed, which indicates that the key leaked, and raise an alert if there are any. This
+# fetches every main chain block.
+WatchKeyLeaks           = {{ .WatchKeyLeaks }}
+# Post the accusations and slashings of any keyper, observed on Shuttermint or in the keyper
+# slasher, as JSON to these URLs. The payload has a "text" field with a summary, so chat webhooks
+# can be used directly. Incidents that happened before the keyper started aren't reported.
+WebhookURLs             = [{{ range $i, $u := .WebhookURLs }}{{ if $i }}, {{ end }}{{ printf "%q" $u }}{{ end }}]
+# Post the incidents as a digest this often, e.g. "1h", instead of right away. 0 disables digests.
+WebhookDigestInterval   = "{{ .WebhookDigestInterval }}"
+# The HTTP APIs below may share a listen address, their endpoints don't overlap.
+# Serve DKG transcripts for light clients on this address, e.g. ":8080". Leave empty to disable.
+LightAPIListenAddress   = "{{ .LightAPIListenAddress }}"
+# Stream the observed Shuttermint events via gRPC on this address, e.g. ":9090". Leave empty to
+# disable.
+EventStreamListenAddress = "{{ .EventStreamListenAddress }}"
+# Serve information about recent batches as JSON on this address, e.g. ":8081". Leave empty to
+# disable.
+ExplorerListenAddress   = "{{ .ExplorerListenAddress }}"
+# Serve Prometheus metrics at /metrics on this address, e.g. ":9100": decision steps and the
+# actions they produce, the DKG phase of each eon, epoch secret key shares sent, pending actions,
+# how far behind both chains we are, and failed action attempts. Leave empty to disable.
+MetricsListenAddress    = "{{ .MetricsListenAddress }}"
+# Serve the admin API on this address, e.g. "localhost:8082". Addresses without a host are bound to
+# localhost. Clients must authenticate with a token signed by our signing key or the key of one of
+# the AdminAccounts (see "shuttermint keyper access-token --api admin"), each token is accepted
+# once. Besides decision traces, it serves the unjustified accusations against us at /accusations,
+# the keypers whose missing check-in blocks our dealing at /dealing/blocked, histograms of when the
+# DKG messages of past eons arrived relative to the start of their phase at /dkg/arrivals, the
+# status of each keyper of the current config at /keypers/status (checked in, encryption key known,
+# poly commitment and eval for the active eon, epoch secret key shares published for its recent
+# epochs), the actions we've given up on at /actions/dead, an overview of what we're doing at
+# /status (shown by "shuttermint keyper top"), and our state to standby keypers at /standby/ (see
+# StandbyPrimaryURL). POST /state/prune?keep=<eons> prunes the state (see StateRetentionEons), POST
+# /releases/pause and /releases/resume pause and resume the release of our epoch secret key shares
+# until the next restart, POST /decryptions/approve?eon=<eon>&epoch=<epoch> approves the decryption
+# of a past epoch whose key hasn't been released, which happens once a threshold of the eon's
+# keypers has approved it. /rpc serves JSON-RPC for debugging: keyper_state reports the decider's
+# state and sync heights, keyper_decide runs a decider step right away and keyper_dumpState returns
+# the state without our secrets. Leave empty to disable.
+AdminListenAddress      = "{{ .AdminListenAddress }}"
+# Further accounts allowed to use the admin API as "0xaddress=role". Viewers may read the traces,
+# accusations, blocked dealings, DKG message arrivals, keyper status, dead actions and our status,
+# operators may additionally prune the state and use /rpc, and security admins may additionally
+# pause releases, approve decryptions and use the standby endpoints. Our own signing key is always a security admin.
+# Every request is logged with the account making it.
+AdminAccounts = [{{ range $i, $a := .AdminAccounts }}{{ if $i }}, {{ end }}{{ printf "%q" $a }}{{ end }}]
+# Keep traces of this many decider steps, served by the admin API at /trace. 0 disables tracing.
+DecisionTraceSize       = {{ .DecisionTraceSize }}
+# Set to "closed" to stream events carrying poly evals only to clients authenticating as keypers
+# (see "shuttermint keyper access-token"), or to "open" to stream them to everyone. Tokens must only
+# be sent via TLS. Note that the Shuttermint node's RPC server serves all events to everyone.
+APIAccess               = "{{ .APIAccess }}"
+# Serve all HTTP endpoints under /<DeploymentID>/, e.g. /<DeploymentID>/eons, and stream events only
+# to clients requesting this deployment in the "shutter-namespace" gRPC metadata entry. Requests for
+# other deployments are rejected, so that clients of keypers serving several deployments, e.g. a
+# test and a production one, can't mix up their keys. Up to 64 letters, digits, '.', '_', or '-'.
+# Leave empty to serve the APIs without namespace.
+DeploymentID            = "{{ .DeploymentID }}"
+# Publish every epoch secret key and the report of every DKG ceremony to the IPFS node whose HTTP
+# API is served at this URL, e.g. "http://localhost:5001". The documents are pinned and linked into
+# the node's files at /shutter/<DeploymentID>/eon-<eon>/ceremony.json and
+# /shutter/<DeploymentID>/eon-<eon>/<namespace or "default">/epoch-<epoch>.json. They don't depend
+# on the keyper, so all keypers publish the same CIDs. The light API lists the CIDs at /ipfs. Leave
+# empty to disable.
+IPFSPublishURL          = "{{ .IPFSPublishURL }}"
+# Relay encrypted transactions for users without funds on the main chain. Users post a ciphertext
+# for a batch together with their signature of it (see the relayer package) as JSON to /relay on
+# this address, e.g. ":8083", and the keyper adds it to the batcher contract from the account of
+# RelayerKey, paying the batcher fee and the gas. RelayerKey must be funded and must not be our
+# SigningKey, our own transactions would compete with the relayed ones for nonces. Within each
+# RelayerQuotaWindow, e.g. "1h", at most RelayerSenderQuota transactions per sender and
+# RelayerTotalQuota transactions in total are relayed (0 for no limit), ciphertexts larger than
+# RelayerMaxSize bytes (0 for no limit) are rejected, and each ciphertext is relayed only once.
+# Leave empty to disable.
+RelayerListenAddress    = "{{ .RelayerListenAddress }}"
+RelayerQuotaWindow      = "{{ .RelayerQuotaWindow }}"
+RelayerSenderQuota      = {{ .RelayerSenderQuota }}
+RelayerTotalQuota       = {{ .RelayerTotalQuota }}
+RelayerMaxSize          = {{ .RelayerMaxSize }}
+
+# Named epoch namespaces to generate epoch keys for in addition to the default namespace. All
+# keypers must use the same list, otherwise they will not agree on the next batch config.
+EpochNamespaces = [{{ range $i, $ns := .EpochNamespaces }}{{ if $i }}, {{ end }}{{ printf "%q" $ns }}{{ end }}]
+
+# Release epoch keys for every main chain block instead of for every batch. Cipher batches are not
+# decrypted in this mode. Like EpochNamespaces, this must be the same for all keypers.
+PerBlockEpochs = {{ .PerBlockEpochs }}
+
+# Run the DKG only within a committee of this many keypers sampled for each eon, 0 to use all
+# keypers. The threshold applies to the committee. This must be the same for all keypers.
+CommitteeSize = {{ .CommitteeSize }}
+
+# The epoch whose key is released for a batch is EpochOffset + batchIndex * EpochStride (a stride
+# of 0 means 1). A new mapping must not reuse the epochs of earlier batches. This must be the same
+# for all keypers and cannot be combined with PerBlockEpochs.
+EpochOffset = {{ .EpochOffset }}
+EpochStride = {{ .EpochStride }}
+
+# The version of the threshold encryption scheme users should encrypt their transactions with.
+# Version 0 is the original scheme, version 1 authenticates ciphertexts with a MAC. Ciphertexts of
+# other versions are left out of decrypted batches. This must be the same for all keypers.
+EncryptionVersion = {{ .EncryptionVersion }}
+
+# Set DuplicateCiphertexts to "drop" to leave those ciphertexts out of the decrypted cipher batches
+# that copy an earlier ciphertext of the batch or a ciphertext of one of the CiphertextReplayWindow
+# preceding batches, so that spammers can't fill batches with copies of the same encrypted payload.
+# "allow" decrypts and executes copies like any other transaction. Both must be the same for all
+# keypers, otherwise they will not agree on the decrypted batches.
+DuplicateCiphertexts = "{{ .DuplicateCiphertexts }}"
+CiphertextReplayWindow = {{ .CiphertextReplayWindow }}
+
+# Decrypted transactions violating the deployment policy are replaced by empty transactions before
+# the cipher batch is executed. TransactionFormat is the format the target contract accepts:
+# "proxy" transactions are forwarded to a receiver by the target proxy contract and must not be
+# forwarded to one of the DeniedTargets, "signed" transactions must carry a valid signature and
+# must not reuse the nonce of an earlier transaction of the batch from the same sender, and "raw"
+# transactions aren't checked. Transactions larger than MaxTransactionSize bytes are replaced as
+# well, 0 disables the limit. Like DuplicateCiphertexts, these must be the same for all keypers.
+TransactionFormat = "{{ .TransactionFormat }}"
+MaxTransactionSize = {{ .MaxTransactionSize }}
+DeniedTargets = [{{ range $i, $a := .DeniedTargets }}{{ if $i }}, {{ end }}{{ printf "%q" $a }}{{ end }}]
+
+# Code hashes of the deployed contracts we trust, as "ContractName=0x<keccak256 of runtime code>".
+# Contracts with unknown code are only accepted if they implement all functions we use, unless
+# RequireKnownContracts is set.
+ContractCodeHashes = [{{ range $i, $h := .ContractCodeHashes }}{{ if $i }}, {{ end }}{{ printf "%q" $h }}{{ end }}]
+RequireKnownContracts = {{ .RequireKnownContracts }}
+
+# Decode a sample of the recent logs of each contract at startup and every ABIDriftCheckInterval,
+# e.g. "10m", and raise an alert about logs that aren't consistent with the ABIs this keyper has
+# been built against, e.g. because a contract has been upgraded behind a proxy. If
+# RequireKnownContracts is set, the keyper refuses to start if there are any. 0 checks at startup
+# only.
+ABIDriftCheckInterval = "{{ .ABIDriftCheckInterval }}"
+
+# Additional executor contracts to use while migrating to a new executor contract, as
+# "startBatchIndex:address[:version]". Batches before the first start batch index are executed by
+# ExecutorContract. The version defaults to the one this keyper has been built for.
+ExecutorRoutes = [{{ range $i, $r := .ExecutorRoutes }}{{ if $i }}, {{ end }}{{ printf "%q" $r }}{{ end }}]
+
+# Give up on a single attempt to send a transaction or Shuttermint message after this time and try
+# again, so that a hanging node doesn't stall the keyper. 0 disables the limit. ActionTimeouts
+# overrides it for specific action types, e.g. "ExecuteCipherBatch=30s".
+ActionTimeout = "{{ .ActionTimeout }}"
+ActionTimeouts = [{{ range $i, $t := .ActionTimeouts }}{{ if $i }}, {{ end }}{{ printf "%q" $t }}{{ end }}]
+
+# Send Shuttermint messages again if they're not in the chain this many blocks after sending them,
+# e.g. because they've been dropped from the mempool, but at most MessageMaxResends times. 0
+# disables checking that messages make it into the chain.
+MessageDeliveryBlocks = {{ .MessageDeliveryBlocks }}
+MessageMaxResends = {{ .MessageMaxResends }}
+
+# Throttle ourselves if we've recently sent more than our fair share of the Shuttermint chain's
+# bandwidth, i.e. more than ShuttermintBandwidth bytes per block divided by the number of keypers.
+# Messages we're about to send count as well. While above our share, the release of our epoch
+# secret key shares is held back for up to ThrottleReleaseBlocks Shuttermint blocks, so that the
+# other keypers can release the key without us, in which case we don't send our share at all.
+# Setting either to 0 disables throttling. The usage per sender is served by the explorer API at
+# /usage.
+ShuttermintBandwidth = {{ .ShuttermintBandwidth }}
+ThrottleReleaseBlocks = {{ .ThrottleReleaseBlocks }}
+
+# Run as a passive standby of the primary keyper whose admin API is served at this URL, e.g.
+# "https://primary:8082", including the primary's DeploymentID if it has one, e.g.
+# "https://primary:8082/production". The standby uses the same keys and config as the primary except for this
+# setting. It replicates the primary's state and takes over once the primary hasn't made progress
+# for StandbyFailoverDelay, after telling it to stop if it can still be reached. It refuses to take
+# over if the primary can be reached but can't be stopped, or if the primary sent messages the
+# replicated state doesn't know about. A primary that has been taken over from must not be
+# restarted. Leave empty to run as primary.
+StandbyPrimaryURL = "{{ .StandbyPrimaryURL }}"
+StandbyFailoverDelay = "{{ .StandbyFailoverDelay }}"
+
+# Back up our eon secret key share of every eon to custodians controlled by the operator, given by
+# their hex encoded secp256k1 public keys. Once the DKG of an eon is done, the share is split among
+# the custodians with Shamir's scheme, so that any ShareBackupThreshold of them can recover it,
+# and each custodian's part is encrypted to its key. The backups are exported to ShareBackupDir
+# (DBDir/share-backups if empty) as eon-<eon>-custodian-<index>.json, from where they should be
+# copied off the host. "shuttermint keyper eon recover-share" recovers a share from the backups.
+# Leave ShareBackupKeys empty to disable.
+ShareBackupKeys = [{{ range $i, $k := .ShareBackupKeys }}{{ if $i }}, {{ end }}{{ printf "%q" $k }}{{ end }}]
+ShareBackupThreshold = {{ .ShareBackupThreshold }}
+ShareBackupDir = "{{ .ShareBackupDir }}"
 
 # Secret Keys
 EncryptionKey	= "{{ .EncryptionKey.ExportECDSA | FromECDSA | printf "%x" }}"
 SigningKey	= "{{ .SigningKey | FromECDSA | printf "%x" }}"
 ValidatorSeed	= "{{ .ValidatorKey.Seed | printf "%x" }}"
+{{ if .RelayerKey }}RelayerKey	= "{{ .RelayerKey | FromECDSA | printf "%x" }}"{{ else }}# RelayerKey	= ""{{ end }}
 `
 
 var tmpl *template.Template
@@ -161,28 +569,48 @@ func (config *Config) GenerateNewKeys() error {
 	return nil
 }
 
-// Unmarshal unmarshals a keyper Config from the the given Viper object.
-func (config *Config) Unmarshal(v *viper.Viper) error {
+// Unmarshal unmarshals a keyper Config from the the given Viper object. Unknown keys are rejected
+// if strict is set and ignored otherwise, see ValidateConfigFile.
+func (config *Config) Unmarshal(v *viper.Viper, strict bool) error {
 	return v.Unmarshal(
 		config,
-		viper.DecodeHook(
-			mapstructure.ComposeDecodeHookFunc(
-				stringToEd25519PrivateKey,
-				stringToEcdsaPrivateKey,
-				stringToEciesPrivateKey,
-				stringToAddress,
-				mapstructure.StringToTimeDurationHookFunc(),
-				mapstructure.StringToSliceHookFunc(","),
-			),
-		),
+		viper.DecodeHook(configDecodeHook()),
+		func(dc *mapstructure.DecoderConfig) {
+			dc.ErrorUnused = strict
+		},
 	)
 }
 
+// confirmations returns the confirmations required by the main chain observer.
+func (config *Config) confirmations() observe.Confirmations {
+	return observe.Confirmations{
+		BatchClosures: config.BatchClosureConfirmations,
+		ConfigChanges: config.ConfigChangeConfirmations,
+		Accusations:   config.AccusationConfirmations,
+	}
+}
+
 // Address returns the keyper's Ethereum address.
 func (config *Config) Address() common.Address {
 	return crypto.PubkeyToAddress(config.SigningKey.PublicKey)
 }
 
+// RegisterContractCodeHashes registers the code hashes of known contract deployments given in
+// ContractCodeHashes.
+func (config *Config) RegisterContractCodeHashes() error {
+	for _, s := range config.ContractCodeHashes {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return errors.Errorf("invalid contract code hash %q, expected Name=0xhash", s)
+		}
+		err := contract.RegisterCodeHash(strings.TrimSpace(parts[0]), common.HexToHash(strings.TrimSpace(parts[1])))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // WriteTOML writes a toml configuratio file with the given config.
 func (config *Config) WriteTOML(w io.Writer) error {
 	return tmpl.Execute(w, config)
@@ -0,0 +1,376 @@
+package keyper
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"fmt"
+	"log"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// ConfigKey describes a key of the keyper config file.
+type ConfigKey struct {
+	Name   string // the key as used in the config file and environment variables
+	Field  string // the name of the corresponding field in Config
+	Secret bool   // true if the value must not be printed
+}
+
+// ConfigKeys returns the keys of the keyper config file in the order of the fields in Config.
+func ConfigKeys() []ConfigKey {
+	var keys []ConfigKey
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("mapstructure")
+		if name == "" {
+			name = f.Name
+		}
+		keys = append(keys, ConfigKey{
+			Name:   name,
+			Field:  f.Name,
+			Secret: isSecretType(f.Type),
+		})
+	}
+	return keys
+}
+
+// ConfigEnvPrefix is the prefix of environment variables overriding config values.
+const ConfigEnvPrefix = "KEYPER"
+
+// EnvName returns the name of the environment variable overriding the key.
+func (k ConfigKey) EnvName() string {
+	return ConfigEnvPrefix + "_" + strings.ToUpper(k.Name)
+}
+
+// FlagName returns the name of the command line flag overriding the key, e.g. "ethereum-url"
+// for EthereumURL.
+func (k ConfigKey) FlagName() string {
+	runes := []rune(k.Name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func isSecretType(t reflect.Type) bool {
+	return t == reflect.TypeOf(&ecdsa.PrivateKey{}) ||
+		t == reflect.TypeOf(&ecies.PrivateKey{}) ||
+		t == reflect.TypeOf(ed25519.PrivateKey{})
+}
+
+// ConfigDefaults are the values used for keys that are neither set in the config file nor in the
+// environment.
+var ConfigDefaults = map[string]interface{}{
+	"ShuttermintURL":              "http://localhost:26657",
+	"ContractCacheTTL":            "12s",
+	"ABIDriftCheckInterval":       "10m",
+	"RPCCacheSize":                1000,
+	"DuplicateCiphertexts":        "allow",
+	"CiphertextReplayWindow":      100,
+	"TransactionFormat":           "raw",
+	"ActionTimeout":               "1m",
+	"ReplaceTXsAfter":             "3m",
+	"MaxTXReplacements":           5,
+	"MessageDeliveryBlocks":       10,
+	"MessageMaxResends":           3,
+	"ThrottleReleaseBlocks":       5,
+	"SyncTolerance":               5,
+	"MaxBlocksBehind":             20,
+	"ArchiveKeepEons":             10,
+	"StateRetentionEons":          10,
+	"KeepBatches":                 1000,
+	"AppealTimeout":               100,
+	"AuthorizationTimeout":        20,
+	"ShuttermintHaltTimeout":      "1m",
+	"CapabilityInterval":          10000,
+	"ApologyDeadlineMargin":       10,
+	"EncryptionKeyDeadlineMargin": 10,
+	"DecisionTraceSize":           100,
+	"APIAccess":                   "open",
+	"ValidatorWatchWindow":        100,
+	"ValidatorMaxMissedBlocks":    5,
+	"MaxClockSkew":                "2s",
+	"StandbyFailoverDelay":        "1m",
+	"RelayerQuotaWindow":          "1h",
+	"RelayerSenderQuota":          10,
+	"RelayerTotalQuota":           1000,
+	"RelayerMaxSize":              10000,
+}
+
+// SetConfigDefaults sets ConfigDefaults as defaults in the given Viper object.
+func SetConfigDefaults(v *viper.Viper) {
+	for key, value := range ConfigDefaults {
+		v.SetDefault(key, value)
+	}
+}
+
+func configDecodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		stringToEd25519PrivateKey,
+		stringToEcdsaPrivateKey,
+		stringToEciesPrivateKey,
+		stringToAddress,
+		stringToWei,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+var weiUnits = map[string]*big.Int{
+	"":      big.NewInt(1),
+	"wei":   big.NewInt(1),
+	"gwei":  big.NewInt(1e9),
+	"ether": big.NewInt(1e18),
+	"eth":   big.NewInt(1e18),
+}
+
+var amountRegexp = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)\s*$`)
+
+// ParseWei parses an amount of ether like "1gwei", "0.5 ether", or "100" (in wei).
+func ParseWei(s string) (*big.Int, error) {
+	m := amountRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, errors.Errorf("invalid amount %q", s)
+	}
+	unit, ok := weiUnits[strings.ToLower(m[2])]
+	if !ok {
+		return nil, errors.Errorf("invalid unit %q in amount %q, use wei, gwei, or ether", m[2], s)
+	}
+	amount, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return nil, errors.Errorf("invalid amount %q", s)
+	}
+	amount.Mul(amount, new(big.Rat).SetInt(unit))
+	if !amount.IsInt() {
+		return nil, errors.Errorf("amount %q is not a whole number of wei", s)
+	}
+	return amount.Num(), nil
+}
+
+func stringToWei(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if f.Kind() != reflect.String || t != reflect.TypeOf(&big.Int{}) {
+		return data, nil
+	}
+	return ParseWei(data.(string))
+}
+
+// ConfigError is an error in the config file at a specific position.
+type ConfigError struct {
+	Path   string
+	Line   int
+	Column int
+	Key    string
+	Err    error
+}
+
+func (e *ConfigError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.Path, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.Path, e.Line, e.Column, e.Key, e.Err)
+}
+
+// ConfigErrors is a list of errors found in a config file.
+type ConfigErrors []*ConfigError
+
+func (errs ConfigErrors) Error() string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+var tomlErrorPosition = regexp.MustCompile(`^\((\d+), (\d+)\): (.*)$`)
+
+// ValidateConfigFile checks the given config file for syntax errors, unknown keys, and values
+// of the wrong type. All errors found are returned as ConfigErrors. Unknown keys are only errors
+// if strict is set. Otherwise they're logged as warnings, so that config files written for older
+// or newer versions of the keyper keep working.
+func ValidateConfigFile(path string, strict bool) error {
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		cerr := &ConfigError{Path: path, Line: 1, Column: 1, Err: err}
+		if m := tomlErrorPosition.FindStringSubmatch(err.Error()); m != nil {
+			fmt.Sscan(m[1], &cerr.Line)
+			fmt.Sscan(m[2], &cerr.Column)
+			cerr.Err = errors.New(m[3])
+		}
+		return ConfigErrors{cerr}
+	}
+	return validateConfigTree(path, tree, strict)
+}
+
+func validateConfigTree(path string, tree *toml.Tree, strict bool) error {
+	known := make(map[string]ConfigKey)
+	for _, k := range ConfigKeys() {
+		known[strings.ToLower(k.Name)] = k
+	}
+
+	var errs ConfigErrors
+	names := tree.Keys()
+	sort.Strings(names)
+	for _, name := range names {
+		pos := tree.GetPosition(name)
+		cerr := &ConfigError{Path: path, Line: pos.Line, Column: pos.Col, Key: name}
+		key, ok := known[strings.ToLower(name)]
+		if !ok {
+			cerr.Err = errors.New("unknown key")
+			if suggestion := suggestConfigKey(name); suggestion != "" {
+				cerr.Err = errors.Errorf("unknown key, did you mean %s?", suggestion)
+			}
+			if strict {
+				errs = append(errs, cerr)
+			} else {
+				log.Printf("Warning: ignoring config value: %s", cerr)
+			}
+			continue
+		}
+		if err := decodeConfigValue(key, tree.Get(name)); err != nil {
+			cerr.Err = err
+			errs = append(errs, cerr)
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decodeConfigValue checks that value can be decoded into the field of the given key.
+func decodeConfigValue(key ConfigKey, value interface{}) error {
+	var config Config
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       configDecodeHook(),
+		WeaklyTypedInput: true,
+		Result:           &config,
+	})
+	if err != nil {
+		return err
+	}
+	if tree, ok := value.(*toml.Tree); ok {
+		return errors.Errorf("expected a value, got table with keys %s", strings.Join(tree.Keys(), ", "))
+	}
+	err = decoder.Decode(map[string]interface{}{key.Name: value})
+	if err == nil {
+		return nil
+	}
+	// strip mapstructure's generic prefix, the key is part of the ConfigError already
+	if merr, ok := err.(*mapstructure.Error); ok && len(merr.Errors) == 1 {
+		msg := merr.Errors[0]
+		if i := strings.Index(msg, ": "); i >= 0 && strings.Contains(msg[:i], key.Name) {
+			msg = msg[i+2:]
+		}
+		return errors.New(msg)
+	}
+	return err
+}
+
+// suggestConfigKey returns the known key closest to name, if any is close enough.
+func suggestConfigKey(name string) string {
+	best := ""
+	bestDistance := 4
+	for _, k := range ConfigKeys() {
+		d := editDistance(strings.ToLower(name), strings.ToLower(k.Name))
+		if d < bestDistance {
+			best = k.Name
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// EffectiveValues returns the values of all config keys formatted for display. Secret keys are
+// not included, only whether they are set. If sources is given, the source of each value is
+// appended as a comment.
+func (config *Config) EffectiveValues(sources map[string]string) []string {
+	var lines []string
+	v := reflect.ValueOf(config).Elem()
+	for _, key := range ConfigKeys() {
+		line := fmt.Sprintf("%s = %s", key.Name, formatConfigValue(key, v.FieldByName(key.Field)))
+		if source, ok := sources[key.Name]; ok {
+			line += " # " + source
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func formatConfigValue(key ConfigKey, v reflect.Value) string {
+	if key.Secret {
+		if v.IsNil() {
+			return `""`
+		}
+		return `"<redacted>"`
+	}
+	switch x := v.Interface().(type) {
+	case common.Address:
+		return fmt.Sprintf("%q", x.Hex())
+	case time.Duration:
+		return fmt.Sprintf("%q", x.String())
+	case string:
+		return fmt.Sprintf("%q", x)
+	case *big.Int:
+		if x == nil {
+			return `""`
+		}
+		return fmt.Sprintf("%q", x.String()+"wei")
+	case []string:
+		quoted := make([]string, len(x))
+		for i, s := range x {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
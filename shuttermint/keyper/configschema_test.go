@@ -0,0 +1,150 @@
+package keyper
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/viper"
+	"gotest.tools/v3/assert"
+)
+
+// writeTestConfig writes a valid config file with the given keys replaced by extra.
+func writeTestConfig(t *testing.T, extra string, replacedKeys ...string) string {
+	t.Helper()
+	config := Config{
+		ShuttermintURL:        "http://localhost:26657",
+		EthereumURL:           "ws://localhost:8545",
+		ConfigContractAddress: common.HexToAddress("0x6ab87f620cb46764C6466e9Ca7Ac193711855D09"),
+		KeyReleaseSLO:         time.Minute,
+		EpochNamespaces:       []string{"a", "b"},
+	}
+	assert.NilError(t, config.GenerateNewKeys())
+	buf := bytes.Buffer{}
+	assert.NilError(t, config.WriteTOML(&buf))
+	content := buf.String()
+	for _, key := range replacedKeys {
+		content = regexp.MustCompile(`(?m)^`+key+`\s*=.*\n`).ReplaceAllString(content, "")
+	}
+	content += extra
+
+	dir, err := ioutil.TempDir("", "keyper-config")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "config.toml")
+	assert.NilError(t, ioutil.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	path := writeTestConfig(t, "")
+	assert.NilError(t, ValidateConfigFile(path, true))
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	SetConfigDefaults(v)
+	assert.NilError(t, v.ReadInConfig())
+	config := Config{}
+	assert.NilError(t, config.Unmarshal(v, true))
+	assert.Equal(t, config.KeyReleaseSLO, time.Minute)
+	assert.Equal(t, config.ContractCacheTTL, time.Duration(0))
+
+	values := strings.Join(config.EffectiveValues(map[string]string{"DBDir": "file"}), "\n")
+	assert.Assert(t, strings.Contains(values, `SigningKey = "<redacted>"`))
+	assert.Assert(t, strings.Contains(values, `RelayerKey = ""`))
+	assert.Assert(t, strings.Contains(values, `EpochNamespaces = ["a", "b"]`))
+	assert.Assert(t, strings.Contains(values, `DBDir = "" # file`))
+	assert.Assert(t, strings.Contains(values, `ConfigContract = "0x6ab87f620cb46764C6466e9Ca7Ac193711855D09"`))
+}
+
+func TestValidateConfigFileErrors(t *testing.T) {
+	path := writeTestConfig(t, "DKGPhaseLenght = 3\nKeyperSlasher = \"0x1234\"\nDBDir = [1, 2]\n", "KeyperSlasher", "DBDir")
+	err := ValidateConfigFile(path, true)
+	errs, ok := err.(ConfigErrors)
+	assert.Assert(t, ok, err)
+	assert.Equal(t, len(errs), 3, err)
+
+	content, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	numLines := strings.Count(string(content), "\n")
+	assert.Equal(t, errs[0].Key, "DKGPhaseLenght")
+	assert.Equal(t, errs[0].Line, numLines-2)
+	assert.ErrorContains(t, errs[0], "did you mean DKGPhaseLength?")
+	assert.Equal(t, errs[1].Key, "KeyperSlasher")
+	assert.ErrorContains(t, errs[1], "not a checksummed address")
+	assert.Equal(t, errs[2].Key, "DBDir")
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	assert.NilError(t, v.ReadInConfig())
+	config := Config{}
+	assert.ErrorContains(t, config.Unmarshal(v, true), "dkgphaselenght")
+}
+
+func TestValidateConfigFileLenient(t *testing.T) {
+	path := writeTestConfig(t, "DKGPhaseLenght = 3\nKeyperSlasher = \"0x1234\"\n", "KeyperSlasher")
+	err := ValidateConfigFile(path, false)
+	errs, ok := err.(ConfigErrors)
+	assert.Assert(t, ok, err)
+	assert.Equal(t, len(errs), 1, err)
+	assert.Equal(t, errs[0].Key, "KeyperSlasher")
+
+	path = writeTestConfig(t, "DKGPhaseLenght = 3\n")
+	assert.NilError(t, ValidateConfigFile(path, false))
+	v := viper.New()
+	v.SetConfigFile(path)
+	SetConfigDefaults(v)
+	assert.NilError(t, v.ReadInConfig())
+	config := Config{}
+	assert.NilError(t, config.Unmarshal(v, false))
+}
+
+func TestValidateConfigFileSyntaxError(t *testing.T) {
+	path := writeTestConfig(t, "Foo = \n")
+	err := ValidateConfigFile(path, true)
+	errs, ok := err.(ConfigErrors)
+	assert.Assert(t, ok, err)
+	assert.Equal(t, len(errs), 1)
+	assert.Assert(t, errs[0].Line > 1)
+}
+
+func TestConfigKeyNames(t *testing.T) {
+	for name, expected := range map[string]string{
+		"EthereumURL":           "ethereum-url",
+		"DKGPhaseLength":        "dkg-phase-length",
+		"ConfigContract":        "config-contract",
+		"ContractCacheTTL":      "contract-cache-ttl",
+		"LightAPIListenAddress": "light-api-listen-address",
+	} {
+		key := ConfigKey{Name: name}
+		assert.Equal(t, key.FlagName(), expected)
+		assert.Equal(t, key.EnvName(), "KEYPER_"+strings.ToUpper(name))
+	}
+}
+
+func TestParseWei(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"100":       100,
+		"100wei":    100,
+		"1gwei":     1e9,
+		"1.5 gwei":  15e8,
+		"2 ether":   2e18,
+		"0.001ETH":  1e15,
+		" 7 Gwei  ": 7e9,
+	} {
+		amount, err := ParseWei(s)
+		assert.NilError(t, err, s)
+		assert.Equal(t, amount.Cmp(big.NewInt(expected)), 0, s)
+	}
+	for _, s := range []string{"", "gwei", "1.5", "1 finney", "-1", "1e9"} {
+		_, err := ParseWei(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
@@ -0,0 +1,16 @@
+package keyper
+
+import (
+	"net/http"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+)
+
+func (kpr *Keyper) deadActionsHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveDeadActions)
+}
+
+// serveDeadActions serves the actions we've given up on as JSON, most recent first.
+func (kpr *Keyper) serveDeadActions(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, kpr.runenv.DeadLetters.Info())
+}
@@ -0,0 +1,132 @@
+package keyper
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// encryptionKeyLogInterval is the number of shuttermint blocks between two log messages about
+// missing encryption keys while the end of the dealing phase is still far.
+const encryptionKeyLogInterval = 10
+
+// BlockedDealing lists the keypers we couldn't send our poly evals to in a DKG, because they
+// haven't checked in with their encryption key.
+type BlockedDealing struct {
+	Eon              uint64
+	DealingEndHeight int64
+	Missed           bool // the dealing phase is over, they will accuse us
+	Keypers          []common.Address
+}
+
+// waitingReceivers returns the keypers whose poly evals are still waiting for their encryption
+// keys.
+func (dkg *DKG) waitingReceivers() []common.Address {
+	var receivers []common.Address
+	for _, p := range dkg.OutgoingPolyEvalMsgs {
+		receivers = append(receivers, dkg.Keypers[p.Receiver])
+	}
+	return receivers
+}
+
+// watchEncryptionKeys logs the keypers that keep us from dealing, because we don't know their
+// encryption key. The closer the end of the dealing phase, the more urgent: every
+// encryptionKeyLogInterval blocks at first, and in every block once we're within
+// EncryptionKeyDeadlineMargin blocks of the deadline. Receivers we can't deal to will accuse us.
+func (dcdr *Decider) watchEncryptionKeys(dkg *DKG, eon observe.Eon) {
+	if dkg.Pure.Phase != puredkg.Dealing || len(dkg.OutgoingPolyEvalMsgs) == 0 {
+		return
+	}
+	deadline := eon.StartHeight + dkg.PhaseLength.Dealing
+	remaining := deadline - (dcdr.Shutter.CurrentBlock + 1)
+	if remaining < 0 {
+		return
+	}
+	if remaining <= int64(dcdr.Config.EncryptionKeyDeadlineMargin) {
+		log.Printf(
+			"Urgent: cannot deal to %d keypers in eon %d, their encryption keys are missing, %d blocks left until the deadline at %d: %s",
+			len(dkg.OutgoingPolyEvalMsgs), dkg.Eon, remaining, deadline, dkg.waitingReceivers(),
+		)
+	} else if remaining%encryptionKeyLogInterval == 0 {
+		log.Printf(
+			"Waiting for the encryption keys of %d keypers to deal to them in eon %d, %d blocks left: %s",
+			len(dkg.OutgoingPolyEvalMsgs), dkg.Eon, remaining, dkg.waitingReceivers(),
+		)
+	}
+}
+
+// accuseMissingEncryptionKeys adds accusations against the keypers we couldn't deal to, because
+// they haven't checked in with their encryption key, if AccuseMissingEncryptionKeys is set.
+// Keypers that don't check in are likely offline and wouldn't apologize, so they are excluded
+// from the eon key in the same DKG instead of leaving it to the other keypers to notice.
+func (dcdr *Decider) accuseMissingEncryptionKeys(
+	dkg *DKG,
+	accusations []puredkg.AccusationMsg,
+) []puredkg.AccusationMsg {
+	if !dcdr.Config.AccuseMissingEncryptionKeys {
+		return accusations
+	}
+	accused := make(map[puredkg.KeyperIndex]bool)
+	for _, a := range accusations {
+		accused[a.Accused] = true
+	}
+	for _, p := range dkg.OutgoingPolyEvalMsgs {
+		if accused[p.Receiver] {
+			continue
+		}
+		log.Printf("Accusing keyper %s in eon %d, it didn't check in in time to receive our poly eval",
+			dkg.Keypers[p.Receiver].Hex(), dkg.Eon)
+		accusations = append(accusations, puredkg.AccusationMsg{
+			Eon:     dkg.Eon,
+			Accuser: dkg.Pure.Keyper,
+			Accused: p.Receiver,
+		})
+		accused[p.Receiver] = true
+	}
+	return accusations
+}
+
+// updateBlockedDealing publishes the keypers blocking our dealing in the ongoing DKGs, so that
+// they can be served while the decider keeps updating the state.
+func (kpr *Keyper) updateBlockedDealing() {
+	var blocked []BlockedDealing
+	for _, dkg := range kpr.State.DKGs {
+		if dkg.IsFinalized() {
+			continue
+		}
+		b := BlockedDealing{
+			Eon:     dkg.Eon,
+			Missed:  len(dkg.MissingEncryptionKeys) > 0,
+			Keypers: dkg.waitingReceivers(),
+		}
+		if b.Missed {
+			b.Keypers = dkg.MissingEncryptionKeys
+		}
+		if len(b.Keypers) == 0 {
+			continue
+		}
+		if eon, err := kpr.CurrentWorld().Shutter.FindEon(dkg.Eon); err == nil {
+			b.DealingEndHeight = eon.StartHeight + dkg.PhaseLength.Dealing
+		}
+		blocked = append(blocked, b)
+	}
+	kpr.blockedDealing.Store(blocked)
+}
+
+func (kpr *Keyper) blockedDealingHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveBlockedDealing)
+}
+
+// serveBlockedDealing serves the keypers whose missing check-ins block our dealing as JSON.
+func (kpr *Keyper) serveBlockedDealing(w http.ResponseWriter, _ *http.Request) {
+	blocked, _ := kpr.blockedDealing.Load().([]BlockedDealing)
+	if blocked == nil {
+		blocked = []BlockedDealing{}
+	}
+	httpapi.WriteJSON(w, blocked)
+}
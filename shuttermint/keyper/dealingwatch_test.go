@@ -0,0 +1,48 @@
+package keyper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestMissingEncryptionKeys(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	config := Config{SigningKey: signingKey}
+	keypers := []common.Address{config.Address(), common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2))}
+
+	pure := puredkg.NewPureDKG(1, 3, 2, 0)
+	_, polyEvals, err := pure.StartPhase1Dealing()
+	assert.NilError(t, err)
+	dkg := DKG{
+		Eon:                  1,
+		Keypers:              keypers,
+		Pure:                 &pure,
+		PhaseLength:          NewConstantPhaseLength(10),
+		OutgoingPolyEvalMsgs: polyEvals,
+	}
+	dcdr := Decider{Config: config, Shutter: observe.NewShutter()}
+	dcdr.watchEncryptionKeys(&dkg, observe.Eon{Eon: 1, StartHeight: 100})
+
+	// keyper 1 didn't give us a valid poly eval, keyper 2 is missing the encryption key
+	dkg.OutgoingPolyEvalMsgs = polyEvals[1:]
+	accusations := []puredkg.AccusationMsg{{Eon: 1, Accuser: 0, Accused: 1}}
+	assert.DeepEqual(t, dcdr.accuseMissingEncryptionKeys(&dkg, accusations), accusations)
+	dcdr.Config.AccuseMissingEncryptionKeys = true
+	assert.DeepEqual(t, dcdr.accuseMissingEncryptionKeys(&dkg, accusations), []puredkg.AccusationMsg{
+		{Eon: 1, Accuser: 0, Accused: 1},
+		{Eon: 1, Accuser: 0, Accused: 2},
+	})
+
+	pure.Phase = puredkg.Accusing
+	dcdr.sendPolyEvals(&dkg)
+	assert.Equal(t, len(dkg.OutgoingPolyEvalMsgs), 0)
+	assert.DeepEqual(t, dkg.MissingEncryptionKeys, []common.Address{keypers[2]})
+}
@@ -11,6 +11,7 @@ import (
 	"math/big"
 	"reflect"
 	"sort"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -20,9 +21,17 @@ import (
 	"github.com/shutter-network/shutter/shlib/puredkg"
 	"github.com/shutter-network/shutter/shlib/shcrypto"
 	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/dkgarrival"
 	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
 	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/latency"
+	"github.com/shutter-network/shutter/shuttermint/keyper/metrics"
 	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/policy"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/keyper/trace"
 	"github.com/shutter-network/shutter/shuttermint/medley"
 	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
@@ -31,6 +40,14 @@ import (
 // steps.
 const maxParallelHalfSteps uint64 = 10
 
+// numPrecomputedEpochs is the number of upcoming epochs we compute our epoch secret key shares
+// for in advance.
+const numPrecomputedEpochs uint64 = 10
+
+// maxBlockEpochBacklog is the maximum number of past main chain blocks we publish epoch secret key
+// shares for in per-block mode, e.g. after a restart.
+const maxBlockEpochBacklog uint64 = 100
+
 type decryptfn func(encrypted []byte) ([]byte, error)
 
 // Batch is used to store local state about a single Batch.
@@ -61,6 +78,13 @@ func (batch *Batch) AddSignature(sender common.Address, signature []byte) {
 	batch.VerifiedSignatures[sender] = signature
 }
 
+// EpochKeySubmission is an epoch secret key waiting to be submitted to the decryption oracle.
+type EpochKeySubmission struct {
+	EonStartBatchIndex uint64
+	Key                *shcrypto.EpochSecretKey
+	SubmitBlock        uint64 // main chain block from which on we submit the key
+}
+
 // DKG is used to store local state about active DKG processes. Each DKG has a corresponding
 // observe.Eon struct stored in observe.Shutter, which we can find with Shutter's FindEon method.
 type DKG struct {
@@ -70,6 +94,15 @@ type DKG struct {
 	Pure                 *puredkg.PureDKG
 	OutgoingPolyEvalMsgs []puredkg.PolyEvalMsg
 	PhaseLength          PhaseLength
+
+	// Apologies we need to get into the chain before the apologizing phase ends and the
+	// shuttermint block at which we've last sent them
+	Apologies         []puredkg.ApologyMsg
+	ApologySentHeight int64
+
+	// MissingEncryptionKeys are the keypers we couldn't send our poly eval to before the dealing
+	// phase ended, because they haven't checked in with their encryption key.
+	MissingEncryptionKeys []common.Address
 }
 
 // EKG is used to store local state about the epoch key generation process.
@@ -77,6 +110,40 @@ type EKG struct {
 	Eon     uint64
 	Keypers []common.Address
 	EpochKG *epochkg.EpochKG
+
+	// Information about the DKG process the key has been generated in, served to light clients
+	StartBatchIndex uint64
+	DKGStartHeight  int64
+	DKGEndHeight    int64
+	Transcript      *puredkg.Transcript
+	// CommitmentHeights are the heights of the keypers' poly commitments, indexed like Keypers
+	CommitmentHeights []int64
+	// Arrivals are the arrival histograms of the DKG messages, relative to the start of their phase
+	Arrivals *dkgarrival.Report
+}
+
+// EonSummary is what the state keeps about an eon once its EKG has been pruned, the information
+// about its DKG without the key material.
+type EonSummary struct {
+	Eon               uint64
+	Keypers           []common.Address
+	StartBatchIndex   uint64
+	DKGStartHeight    int64
+	DKGEndHeight      int64
+	CommitmentHeights []int64
+	Arrivals          *dkgarrival.Report
+}
+
+func newEonSummary(ekg *EKG) *EonSummary {
+	return &EonSummary{
+		Eon:               ekg.Eon,
+		Keypers:           ekg.Keypers,
+		StartBatchIndex:   ekg.StartBatchIndex,
+		DKGStartHeight:    ekg.DKGStartHeight,
+		DKGEndHeight:      ekg.DKGEndHeight,
+		CommitmentHeights: ekg.CommitmentHeights,
+		Arrivals:          ekg.Arrivals,
+	}
 }
 
 func (dkg *DKG) ShortInfo() string {
@@ -108,6 +175,26 @@ func (dkg *DKG) newAccusation(accusations []puredkg.AccusationMsg) *shmsg.Messag
 	return shmsg.NewAccusation(dkg.Eon, accused)
 }
 
+// deliveredPolyEval checks if a poly eval from us to the given receiver has made it into the chain
+// during the dealing phase.
+func (dkg *DKG) deliveredPolyEval(eon observe.Eon, receiver common.Address) bool {
+	self := dkg.Keypers[dkg.Pure.Keyper]
+	for _, eval := range eon.PolyEvals {
+		if eval.Sender != self {
+			continue
+		}
+		if dkg.PhaseLength.getPhaseAtHeight(eval.Height, eon.StartHeight) != puredkg.Dealing {
+			continue
+		}
+		for _, r := range eval.Receivers {
+			if r == receiver {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (dkg *DKG) syncCommitments(syncHeight int64, eon observe.Eon) {
 	for _, comm := range eon.GetPolyCommitments(syncHeight) {
 		phase := dkg.PhaseLength.getPhaseAtHeight(comm.Height, eon.StartHeight)
@@ -130,7 +217,9 @@ func (dkg *DKG) syncCommitments(syncHeight int64, eon observe.Eon) {
 	}
 }
 
-func (dkg *DKG) syncPolyEvals(syncHeight int64, eon observe.Eon, decrypt decryptfn) {
+// syncPolyEvals handles the poly evals sent to us. Evals of keypers we already have an eval of
+// are skipped without decrypting them, the others are decrypted via the given cache.
+func (dkg *DKG) syncPolyEvals(syncHeight int64, eon observe.Eon, decrypt decryptfn, cache *PolyEvalCache) {
 	keyperIndex := dkg.Pure.Keyper
 	for _, eval := range eon.GetPolyEvals(syncHeight) {
 		phase := dkg.PhaseLength.getPhaseAtHeight(eval.Height, eon.StartHeight)
@@ -143,7 +232,7 @@ func (dkg *DKG) syncPolyEvals(syncHeight int64, eon observe.Eon, decrypt decrypt
 		if err != nil {
 			continue
 		}
-		if uint64(sender) == keyperIndex {
+		if uint64(sender) == keyperIndex || dkg.Pure.Evals[sender] != nil {
 			continue
 		}
 
@@ -156,14 +245,11 @@ func (dkg *DKG) syncPolyEvals(syncHeight int64, eon observe.Eon, decrypt decrypt
 			if uint64(receiverIndex) != keyperIndex {
 				continue
 			}
-			encrypted := eval.EncryptedEvals[j]
-			evalBytes, err := decrypt(encrypted)
+			b, err := cache.Eval(eval.Eon, eval.Sender, receiver, eval.EncryptedEvals[j], decrypt)
 			if err != nil {
 				log.Printf("Error in syncPolyEvals: %+v", err)
 				continue
 			}
-			b := new(big.Int)
-			b.SetBytes(evalBytes)
 			err = dkg.Pure.HandlePolyEvalMsg(
 				puredkg.PolyEvalMsg{
 					Eon:      eval.Eon,
@@ -250,11 +336,39 @@ type State struct {
 	LastEonStarted           uint64
 	DKGs                     []DKG
 	EKGs                     []*EKG
+	EonSummaries             []*EonSummary   // summaries of the eons whose EKGs have been pruned, in order
+	EonActivations           []EonActivation // batch ranges of the started eons, in order
 	PendingHalfStep          *uint64
 	PendingAppeals           map[uint64]struct{}
+	AppealSentBlocks         map[uint64]uint64 // half step => main chain block we've sent the appeal at
 	NextEpochSecretShare     uint64
 	Batches                  map[uint64]*Batch
 	HalfStepsChecked         uint64
+	EpochKeySubmissions      map[uint64]*EpochKeySubmission   // batch index => submission
+	AuthorizationRequests    map[uint64]*AuthorizationRequest // half step => collected signatures
+	SkipCipherVotes          map[uint64]*SkipCipherVote       // batch index => vote state
+	Halt                     *ShuttermintHalt                 // nil unless frozen because of a shuttermint halt
+	HaltVotedHeight          uint64                           // halt height we've last voted to resume after
+	CapabilitiesSentHeight   int64                            // shuttermint height we've last announced our capabilities at
+
+	// DecryptionApprovals are the past epochs our operator or a threshold of keypers has
+	// approved the decryption of, see handleDecryptionApprovals.
+	DecryptionApprovals []*DecryptionApproval
+
+	// UnjustifiedAccusations counts the accusations against us by keypers we've delivered our
+	// poly eval to.
+	UnjustifiedAccusations map[common.Address]uint64
+
+	// NextBlockEpochSecretShare is the next main chain block to publish an epoch secret key
+	// share for if per-block epochs are used.
+	NextBlockEpochSecretShare uint64
+
+	// EpochShares records the epochs we've published our epoch secret key shares for and the
+	// ones we've skipped, by eon and namespace. EpochSharesReconciled holds the shuttermint
+	// height up to which our shares in the chain have been recorded, by eon, see
+	// reconcileEpochShares.
+	EpochShares           []*EpochShares
+	EpochSharesReconciled map[uint64]int64
 
 	// We store the actions that should be executed together with a counter. When starting the
 	// program, we feed these actions into runenv, which can use the counter to identify the
@@ -263,22 +377,69 @@ type State struct {
 	Actions       []fx.IAction
 
 	SyncHeight int64
+
+	// ArchivedHeight is the last shuttermint block whose events have been archived, see
+	// Config.ArchiveKeepEons.
+	ArchivedHeight int64
 }
 
 // NewState creates an empty State object.
 func NewState() *State {
 	return &State{
-		PendingAppeals: make(map[uint64]struct{}),
-		Batches:        make(map[uint64]*Batch),
+		PendingAppeals:         make(map[uint64]struct{}),
+		AppealSentBlocks:       make(map[uint64]uint64),
+		Batches:                make(map[uint64]*Batch),
+		EpochKeySubmissions:    make(map[uint64]*EpochKeySubmission),
+		UnjustifiedAccusations: make(map[common.Address]uint64),
 	}
 }
 
-// GetShutterFilter returns the shutter filter to be applied to the Shutter state.
+// GetShutterFilter returns the shutter filter to be applied to the Shutter state. Eons before the
+// oldest eon we still have a DKG or EKG for are dropped, so the Shutter state follows the pruning
+// of our own state, see Decider.pruneState.
 func (st *State) GetShutterFilter(mainChain *observe.MainChain) observe.ShutterFilter {
-	return observe.ShutterFilter{
+	filter := observe.ShutterFilter{
 		SyncHeight: st.SyncHeight,
-		BatchIndex: mainChain.NumExecutionHalfSteps / 2,
+		BatchIndex: uint64(protocol.NextBatchIndex(mainChain.NumExecutionHalfSteps)),
+	}
+	if eon, ok := st.oldestEon(); ok {
+		filter.Eon = eon
 	}
+	return filter
+}
+
+// oldestEon returns the oldest eon we have a DKG or EKG for, and false if there is none.
+func (st *State) oldestEon() (uint64, bool) {
+	var eon uint64
+	found := false
+	for _, dkg := range st.DKGs {
+		if !found || dkg.Eon < eon {
+			eon, found = dkg.Eon, true
+		}
+	}
+	for _, ekg := range st.EKGs {
+		if !found || ekg.Eon < eon {
+			eon, found = ekg.Eon, true
+		}
+	}
+	return eon, found
+}
+
+// GetMainChainFilter returns the filter to be applied to the MainChain state. It drops the
+// transactions of the batches more than keep batches before the next batch to be executed or to
+// be checked for accusations, whichever comes first. Nothing is dropped if keep is 0.
+func (st *State) GetMainChainFilter(mainChain *observe.MainChain, keep uint64) observe.MainChainFilter {
+	if keep == 0 {
+		return observe.MainChainFilter{}
+	}
+	next := uint64(protocol.NextBatchIndex(mainChain.NumExecutionHalfSteps))
+	if checked := uint64(protocol.HalfStep(st.HalfStepsChecked).BatchIndex()); checked < next {
+		next = checked
+	}
+	if next <= keep {
+		return observe.MainChainFilter{}
+	}
+	return observe.MainChainFilter{BatchIndex: next - keep}
 }
 
 // Decider decides on the next actions to take based on our internal State and the current Shutter
@@ -291,18 +452,79 @@ type Decider struct {
 	MainChain   *observe.MainChain
 	Actions     []fx.IAction
 	PhaseLength PhaseLength
+	ShareCache  *epochkg.ShareCache
+	Latency     *latency.Tracker
+	Trace       *trace.Step      // nil if tracing is disabled
+	Policy      policy.Policy    // nil for the default policy
+	Metrics     *metrics.Metrics // nil if disabled
+
+	PolyEvalCache *PolyEvalCache // decrypted poly evals, nil to decrypt them in every step
+
+	// Throttle holds back key releases while we send too much, nil if disabled. PendingBytes is
+	// the size of the shuttermint messages among the pending actions, only set if throttling.
+	Throttle     *bandwidthThrottle
+	PendingBytes uint64
+
+	PruneKeepEons uint64             // see pruneState
+	Retention     retention.Policies // see archived and pruneState
+
+	// ShutterHalted is set if shuttermint hasn't produced a block for ShuttermintHaltTimeout,
+	// ForceHaltResume if the operator wants to end the freeze after a halt, see handleHalt.
+	ShutterHalted   bool
+	ForceHaltResume bool
+
+	// ResumePolyEvals is set for the first step after a restart, see resumePolyEvals.
+	// PendingMessages are the shuttermint messages we've sent, but haven't seen in the chain yet.
+	ResumePolyEvals bool
+	PendingMessages []*shmsg.Message
+
+	tracedActions    []string                // actions emitted by the decision currently being traced
+	polyEvalsResumed bool                    // resumePolyEvals has been run for all DKGs
+	polyCommitments  []*shmsg.PolyCommitment // poly commitments to send, see sendPolyCommitments
+	// epochSharesReconciled is set once reconcileEpochShares has been run, see
+	// epochSecretKeyShareSent
+	epochSharesReconciled bool
 }
 
 func NewDecider(kpr *Keyper) Decider {
 	world := kpr.CurrentWorld()
-	return Decider{
+	dcdr := Decider{
 		Config:      kpr.Config,
 		State:       kpr.State,
 		Shutter:     world.Shutter,
 		MainChain:   world.MainChain,
 		Actions:     []fx.IAction{},
 		PhaseLength: NewConstantPhaseLength(int64(kpr.Config.DKGPhaseLength)),
+		ShareCache:  kpr.shareCache,
+		Latency:     kpr.latency,
+		Policy:      kpr.policy(),
+		Metrics:     kpr.metrics,
+
+		PolyEvalCache: kpr.polyEvalCache,
+		PruneKeepEons: kpr.pruneKeepEons(),
+		Retention:     kpr.retention,
+
+		ShutterHalted:   shutterHalted(world.Shutter, kpr.Config.ShuttermintHaltTimeout, time.Now()),
+		ForceHaltResume: kpr.takeHaltResumeRequest(),
+	}
+	if kpr.throttle != nil {
+		dcdr.Throttle = kpr.throttle
+		dcdr.PendingBytes = kpr.pendingMessageBytes()
+	}
+	if !kpr.polyEvalsResumed {
+		dcdr.ResumePolyEvals = true
+		if kpr.runenv != nil {
+			dcdr.PendingMessages = kpr.runenv.PendingActions.ShuttermintMessages()
+		}
 	}
+	if kpr.trace != nil {
+		dcdr.Trace = &trace.Step{
+			Time:           time.Now(),
+			ShutterHeight:  world.Shutter.CurrentBlock,
+			MainChainBlock: world.MainChain.CurrentBlock,
+		}
+	}
+	return dcdr
 }
 
 var errEKGNotFound = errors.New("EKG not found")
@@ -316,12 +538,28 @@ func (st *State) FindEKGByEon(eon uint64) (*EKG, error) {
 	return nil, pkgErrors.WithStack(errEKGNotFound)
 }
 
+func (dcdr *Decider) policy() policy.Policy {
+	p := dcdr.Policy
+	if p == nil {
+		p = policy.Default{}
+	}
+	if dcdr.State.Halt != nil {
+		return haltedPolicy{p}
+	}
+	return p
+}
+
+func (dcdr *Decider) world() observe.World {
+	return observe.World{Shutter: dcdr.Shutter, MainChain: dcdr.MainChain}
+}
+
 // addAction stores the given IAction to be run later.
 func (dcdr *Decider) addAction(a fx.IAction) {
 	if reflect.ValueOf(a).Kind() != reflect.Ptr {
 		panic("internal error: addAction: expected pointer")
 	}
 	dcdr.Actions = append(dcdr.Actions, a)
+	dcdr.traceAction(a)
 }
 
 func (dcdr *Decider) sendShuttermintMessage(description string, msg *shmsg.Message) {
@@ -331,6 +569,16 @@ func (dcdr *Decider) sendShuttermintMessage(description string, msg *shmsg.Messa
 	})
 }
 
+// addPriorityShuttermintMessage is like sendShuttermintMessage, but the executor starts the action
+// before other actions waiting to be run.
+func (dcdr *Decider) addPriorityShuttermintMessage(description string, msg *shmsg.Message) {
+	dcdr.addAction(&fx.SendShuttermintMessage{
+		Description: description,
+		Msg:         msg,
+		Priority:    true,
+	})
+}
+
 // shouldSendCheckIn returns true if we should send the CheckIn message.
 func (dcdr *Decider) shouldSendCheckIn() bool {
 	if dcdr.State.CheckInMessageSent {
@@ -364,6 +612,12 @@ func (dcdr *Decider) sendBatchConfig(configIndex uint64, config contract.BatchCo
 		configIndex,
 		false,
 		false,
+		dcdr.Config.EpochNamespaces,
+		dcdr.Config.PerBlockEpochs,
+		dcdr.Config.CommitteeSize,
+		dcdr.Config.EpochOffset,
+		dcdr.Config.EpochStride,
+		dcdr.Config.EncryptionVersion,
 	)
 	dcdr.sendShuttermintMessage(fmt.Sprintf("batch config, index=%d", configIndex), msg)
 }
@@ -373,6 +627,9 @@ func (dcdr *Decider) maybeSendBatchConfig() {
 		log.Printf("Shutter is not bootstrapped")
 		return
 	}
+	if configsLagging(dcdr.Shutter, dcdr.MainChain) {
+		return // wait until we see the batch configs shuttermint knows about on the main chain
+	}
 	configIndex := 1 + dcdr.Shutter.BatchConfigs[len(dcdr.Shutter.BatchConfigs)-1].ConfigIndex
 
 	if configIndex <= dcdr.State.LastSentBatchConfigIndex {
@@ -387,17 +644,21 @@ func (dcdr *Decider) maybeSendBatchConfig() {
 
 func (dcdr *Decider) startDKG(eon *observe.Eon) {
 	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(eon.StartEvent.BatchIndex)
-	keyperIndex, err := medley.FindAddressIndex(batchConfig.Keypers, dcdr.Config.Address())
+	committee := batchConfig.Committee(eon.StartEvent.Seed)
+	keyperIndex, err := medley.FindAddressIndex(committee, dcdr.Config.Address())
 	if err != nil {
+		if batchConfig.IsKeyper(dcdr.Config.Address()) {
+			log.Printf("Not part of the committee for eon %d", eon.Eon)
+		}
 		return
 	}
 
-	pure := puredkg.NewPureDKG(eon.Eon, uint64(len(batchConfig.Keypers)), batchConfig.Threshold, uint64(keyperIndex))
+	pure := puredkg.NewPureDKG(eon.Eon, uint64(len(committee)), batchConfig.Threshold, uint64(keyperIndex))
 	dkg := DKG{
 		Eon:             eon.Eon,
 		StartBatchIndex: eon.StartEvent.BatchIndex,
 		Pure:            &pure,
-		Keypers:         batchConfig.Keypers,
+		Keypers:         committee,
 		PhaseLength:     dcdr.PhaseLength,
 	}
 	dcdr.State.DKGs = append(dcdr.State.DKGs, dkg)
@@ -459,10 +720,15 @@ func (dcdr *Decider) sendPolyEvals(dkg *DKG) {
 	}
 
 	if dkg.Pure.Phase > puredkg.Dealing {
+		dkg.MissingEncryptionKeys = dkg.waitingReceivers()
 		log.Printf(
-			"Warning: could not send %d poly eval messages for eon %d, because the dealing phase is already over",
+			"Warning: could not send %d poly eval messages for eon %d, because the dealing phase is already over, missing encryption keys: %s",
 			len(dkg.OutgoingPolyEvalMsgs),
-			dkg.Eon)
+			dkg.Eon,
+			dkg.MissingEncryptionKeys)
+		for _, p := range dkg.OutgoingPolyEvalMsgs {
+			shcrypto.ZeroInt(p.Eval)
+		}
 		dkg.OutgoingPolyEvalMsgs = nil
 		return
 	}
@@ -475,10 +741,13 @@ func (dcdr *Decider) sendPolyEvals(dkg *DKG) {
 		receiver := dkg.Keypers[p.Receiver]
 		encryptionKey, ok := dcdr.Shutter.KeyperEncryptionKeys[receiver]
 		if ok {
-			encrypted, err := encryptionKey.Encrypt(rand.Reader, p.Eval.Bytes())
+			evalBytes := p.Eval.Bytes()
+			encrypted, err := encryptionKey.Encrypt(rand.Reader, evalBytes)
 			if err != nil {
 				panic(err)
 			}
+			shcrypto.ZeroBytes(evalBytes)
+			shcrypto.ZeroInt(p.Eval)
 			encryptedEvals = append(encryptedEvals, encrypted)
 			receivers = append(receivers, receiver)
 		} else {
@@ -506,9 +775,31 @@ func (dcdr *Decider) startPhase1Dealing(dkg *DKG, phaseAtNextBlockHeight puredkg
 	}
 
 	dkg.OutgoingPolyEvalMsgs = polyEvals
-	dcdr.sendShuttermintMessage(
-		fmt.Sprintf("poly commitment, eon=%d", dkg.Eon),
-		shmsg.NewPolyCommitment(dkg.Eon, commitment.Gammas))
+	dcdr.polyCommitments = append(dcdr.polyCommitments, shmsg.NewPolyCommitmentPayload(dkg.Eon, commitment.Gammas))
+}
+
+// sendPolyCommitments sends the poly commitments collected by startPhase1Dealing. If we have to
+// deal in several DKGs at once, e.g. after catching up, the commitments are sent in a single
+// message.
+func (dcdr *Decider) sendPolyCommitments() {
+	switch len(dcdr.polyCommitments) {
+	case 0:
+		return
+	case 1:
+		c := dcdr.polyCommitments[0]
+		dcdr.sendShuttermintMessage(
+			fmt.Sprintf("poly commitment, eon=%d", c.Eon),
+			&shmsg.Message{Payload: &shmsg.Message_PolyCommitment{PolyCommitment: c}})
+	default:
+		var eons []uint64
+		for _, c := range dcdr.polyCommitments {
+			eons = append(eons, c.Eon)
+		}
+		dcdr.sendShuttermintMessage(
+			fmt.Sprintf("poly commitments, eons=%v", eons),
+			shmsg.NewPolyCommitments(dcdr.polyCommitments))
+	}
+	dcdr.polyCommitments = nil
 }
 
 func (dcdr *Decider) startPhase2Accusing(dkg *DKG, phaseAtNextBlockHeight puredkg.Phase) {
@@ -516,6 +807,7 @@ func (dcdr *Decider) startPhase2Accusing(dkg *DKG, phaseAtNextBlockHeight puredk
 	if phaseAtNextBlockHeight != puredkg.Accusing {
 		return
 	}
+	accusations = dcdr.accuseMissingEncryptionKeys(dkg, accusations)
 
 	if len(accusations) > 0 {
 		dcdr.sendShuttermintMessage(
@@ -526,12 +818,41 @@ func (dcdr *Decider) startPhase2Accusing(dkg *DKG, phaseAtNextBlockHeight puredk
 	}
 }
 
-func (dcdr *Decider) startPhase3Apologizing(dkg *DKG, phaseAtNextBlockHeight puredkg.Phase) {
+// checkAccusationsAgainstUs verifies the accusations against us. Accusations by keypers who
+// received a poly eval from us in time that matches our commitment are recorded as unjustified.
+// Accusations we do not apologize for are logged.
+func (dcdr *Decider) checkAccusationsAgainstUs(dkg *DKG, eon observe.Eon, apologies []puredkg.ApologyMsg) {
+	apologized := make(map[puredkg.KeyperIndex]bool)
+	for _, a := range apologies {
+		apologized[a.Accuser] = true
+	}
+	for key := range dkg.Pure.Accusations {
+		if key.Accused != dkg.Pure.Keyper {
+			continue
+		}
+		accuser := dkg.Keypers[key.Accuser]
+		if !apologized[key.Accuser] {
+			log.Printf("Warning: cannot apologize for accusation by %s in eon %d", accuser.Hex(), dkg.Eon)
+		}
+		if dkg.deliveredPolyEval(eon, accuser) && dkg.Pure.VerifyOwnEval(key.Accuser) {
+			log.Printf("Unjustified accusation by %s in eon %d", accuser.Hex(), dkg.Eon)
+			if dcdr.State.UnjustifiedAccusations == nil {
+				dcdr.State.UnjustifiedAccusations = make(map[common.Address]uint64)
+			}
+			dcdr.State.UnjustifiedAccusations[accuser]++
+		}
+	}
+}
+
+func (dcdr *Decider) startPhase3Apologizing(dkg *DKG, eon observe.Eon, phaseAtNextBlockHeight puredkg.Phase) {
 	apologies := dkg.Pure.StartPhase3Apologizing()
+	dcdr.checkAccusationsAgainstUs(dkg, eon, apologies)
 	if phaseAtNextBlockHeight != puredkg.Apologizing {
 		return
 	}
 	if len(apologies) > 0 {
+		dkg.Apologies = apologies
+		dkg.ApologySentHeight = dcdr.Shutter.CurrentBlock
 		dcdr.sendShuttermintMessage(
 			fmt.Sprintf("apologies, eon=%d, count=%d", dkg.Eon, len(apologies)),
 			dkg.newApology(apologies))
@@ -540,9 +861,13 @@ func (dcdr *Decider) startPhase3Apologizing(dkg *DKG, phaseAtNextBlockHeight pur
 	}
 }
 
-func (dcdr *Decider) dkgFinalize(dkg *DKG) {
+func (dcdr *Decider) dkgFinalize(dkg *DKG, eon observe.Eon) {
 	dkg.Pure.Finalize()
+	dcdr.logMisbehaviors(dkg)
 	dkgresult, err := dkg.Pure.ComputeResult()
+	// Our polynomial and the poly evals we've received are only needed for the result
+	dkg.Pure.Destroy()
+	dcdr.PolyEvalCache.Forget(dkg.Eon)
 	if err != nil {
 		log.Printf("Error: DKG process failed for %s: %+v", dkg.ShortInfo(), err)
 		dcdr.sendShuttermintMessage(
@@ -552,15 +877,61 @@ func (dcdr *Decider) dkgFinalize(dkg *DKG) {
 		return
 	}
 	log.Printf("Success: DKG process succeeded for %s", dkg.ShortInfo())
+	transcript, err := dkg.Pure.Transcript()
+	if err != nil {
+		log.Printf("Error: cannot compute DKG transcript for %s: %+v", dkg.ShortInfo(), err)
+	}
 	ekg := &EKG{
-		Eon:     dkg.Eon,
-		Keypers: dkg.Keypers,
-		EpochKG: epochkg.NewEpochKG(&dkgresult),
+		Eon:               dkg.Eon,
+		Keypers:           dkg.Keypers,
+		EpochKG:           epochkg.NewEpochKG(&dkgresult),
+		StartBatchIndex:   dkg.StartBatchIndex,
+		DKGStartHeight:    eon.StartHeight,
+		DKGEndHeight:      eon.StartHeight + dkg.PhaseLength.Apologizing,
+		Transcript:        transcript,
+		CommitmentHeights: commitmentHeights(dkg.Keypers, eon.Commitments),
+		Arrivals:          dkgarrival.Compute(eon, dkg.PhaseLength.arrivalPhases(eon.StartHeight)),
+	}
+	log.Printf("DKG message arrivals in %s", ekg.Arrivals)
+	version := dcdr.Shutter.FindBatchConfigByBatchIndex(dkg.StartBatchIndex).EncryptionVersion
+	if err := selfTestEpochKG(ekg.EpochKG, uint8(version)); err != nil {
+		log.Printf(
+			"ALERT: self test of the key material generated in eon %d failed, we will not be able to help decrypting its batches: %+v",
+			dkg.Eon, err,
+		)
+	} else {
+		log.Printf("Self test of the key material generated in eon %d passed", dkg.Eon)
 	}
 	dcdr.State.EKGs = append(dcdr.State.EKGs, ekg)
 	dcdr.broadcastEonPublicKey(&dkgresult, dkg.StartBatchIndex)
 }
 
+// commitmentHeights returns the height of each keyper's poly commitment, or 0 if the keyper
+// didn't send one.
+func commitmentHeights(keypers []common.Address, commitments []shutterevents.PolyCommitment) []int64 {
+	heights := make([]int64, len(keypers))
+	for _, c := range commitments {
+		for i, k := range keypers {
+			if k == c.Sender && heights[i] == 0 {
+				heights[i] = c.Height
+			}
+		}
+	}
+	return heights
+}
+
+// logMisbehaviors logs the misbehaviors detected in the given finalized DKG process.
+func (dcdr *Decider) logMisbehaviors(dkg *DKG) {
+	misbehaviors, err := dkg.Pure.Misbehaviors()
+	if err != nil {
+		log.Printf("Error: cannot determine misbehaviors for %s: %+v", dkg.ShortInfo(), err)
+		return
+	}
+	for _, m := range misbehaviors {
+		log.Printf("Misbehavior in eon %d by %s: %s", dkg.Eon, dkg.Keypers[m.Keyper].Hex(), m)
+	}
+}
+
 func (dcdr *Decider) broadcastEonPublicKey(dkgResult *puredkg.Result, startBatchIndex uint64) {
 	action := fx.EonKeyBroadcast{
 		KeyperIndex:     dkgResult.Keyper,
@@ -583,7 +954,7 @@ func (dcdr *Decider) syncDKGWithEon(dkg *DKG, eon observe.Eon) {
 		dcdr.startPhase1Dealing(dkg, phaseAtNextBlockHeight)
 	}
 	dkg.syncCommitments(syncHeight, eon)
-	dkg.syncPolyEvals(syncHeight, eon, decrypt)
+	dkg.syncPolyEvals(syncHeight, eon, decrypt, dcdr.PolyEvalCache)
 
 	if dkg.Pure.Phase == puredkg.Dealing && phaseAtNextBlockHeight >= puredkg.Accusing {
 		dcdr.startPhase2Accusing(dkg, phaseAtNextBlockHeight)
@@ -591,16 +962,22 @@ func (dcdr *Decider) syncDKGWithEon(dkg *DKG, eon observe.Eon) {
 	dkg.syncAccusations(syncHeight, eon)
 
 	if dkg.Pure.Phase == puredkg.Accusing && phaseAtNextBlockHeight >= puredkg.Apologizing {
-		dcdr.startPhase3Apologizing(dkg, phaseAtNextBlockHeight)
+		dcdr.startPhase3Apologizing(dkg, eon, phaseAtNextBlockHeight)
 	}
 	dkg.syncApologies(syncHeight, eon)
+	dcdr.watchApologyDeadline(dkg, eon)
 
 	if dkg.Pure.Phase == puredkg.Apologizing && phaseAtNextBlockHeight >= puredkg.Finalized {
-		dcdr.dkgFinalize(dkg)
+		dcdr.dkgFinalize(dkg, eon)
 	}
 }
 
 func (dcdr *Decider) handleDKGs() {
+	type active struct {
+		dkg *DKG
+		eon *observe.Eon
+	}
+	var dkgs []active
 	for i := range dcdr.State.DKGs {
 		dkg := &dcdr.State.DKGs[i]
 		if dkg.IsFinalized() {
@@ -611,32 +988,69 @@ func (dcdr *Decider) handleDKGs() {
 			panic(err)
 		}
 		dcdr.syncDKGWithEon(dkg, *eon)
-		dcdr.sendPolyEvals(dkg)
+		dkgs = append(dkgs, active{dkg: dkg, eon: eon})
+	}
+	dcdr.sendPolyCommitments()
+
+	// The poly evals are sent after the commitments, so that the receivers can verify them.
+	for _, a := range dkgs {
+		if dcdr.ResumePolyEvals {
+			dcdr.resumePolyEvals(a.dkg, *a.eon)
+		}
+		dcdr.sendPolyEvals(a.dkg)
+		dcdr.watchEncryptionKeys(a.dkg, *a.eon)
 	}
+	dcdr.polyEvalsResumed = dcdr.ResumePolyEvals
 }
 
-func (dcdr *Decider) publishEpochSecretKeyShare(batchIndex uint64) {
+func (dcdr *Decider) publishEpochSecretKeyShare(batchIndex uint64) policy.Decision {
+	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(batchIndex)
+	return dcdr.publishEpochSecretKeyShareInEon(batchIndex, batchConfig.Epoch(batchIndex))
+}
+
+// publishEpochSecretKeyShareInEon publishes our epoch secret key shares for the given epoch using
+// the eon the given batch belongs to. In batch mode, the epoch is derived from the batch index
+// using the batch config's epoch mapping, in per-block mode it is a main chain block number. It
+// returns the decision of the key release policy, or Allow if there's nothing we could publish.
+func (dcdr *Decider) publishEpochSecretKeyShareInEon(batchIndex uint64, epoch uint64) policy.Decision {
 	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(batchIndex)
 	if !batchConfig.IsKeyper(dcdr.Config.Address()) {
 		// not a keyper, cannot publish epoch secret key
-		return
+		return policy.Allow
 	}
 
-	epoch := batchIndex
-	eon, err := dcdr.Shutter.FindEonByBatchIndex(batchIndex)
-	if err != nil {
-		return
+	eon, ok := dcdr.State.ActiveEon(batchIndex)
+	if !ok {
+		return policy.Allow
 	}
 
-	ekg, err := dcdr.State.FindEKGByEon(eon.Eon)
+	ekg, err := dcdr.State.FindEKGByEon(eon)
 	if err != nil {
-		log.Printf("Cannot publish epoch secret key for epoch %d in eon %d, no eon key", epoch, eon.Eon)
-		return
+		log.Printf("Cannot publish epoch secret key for epoch %d in eon %d, no eon key", epoch, eon)
+		return policy.Allow
+	}
+	decision := dcdr.policy().ReleaseKey(dcdr.world(), policy.KeyRelease{
+		BatchIndex: protocol.BatchIndex(batchIndex),
+		Epoch:      protocol.EpochIndex(epoch),
+		Eon:        protocol.EonIndex(eon),
+		Namespaces: batchConfig.EpochNamespaces,
+	})
+	if decision != policy.Allow {
+		log.Printf("Key release policy decided to %s releasing epoch %d in eon %d", decision, epoch, eon)
+		return decision
+	}
+	if _, ok := ekg.EpochKG.SecretKey("", epoch); !ok && dcdr.throttleKeyRelease(len(batchConfig.Keypers)) {
+		return policy.Delay
+	}
+	dcdr.sendEpochSecretKeyShare(ekg.EpochKG, "", epoch)
+	for _, namespace := range batchConfig.EpochNamespaces {
+		dcdr.sendEpochSecretKeyShare(ekg.EpochKG, namespace, epoch)
 	}
-	dcdr.sendEpochSecretKeyShare(ekg.EpochKG, epoch)
+	return policy.Allow
 }
 
 func (dcdr *Decider) syncEKGWithEon(syncHeight int64, ekg *EKG, eon *observe.Eon) {
+	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(eon.StartEvent.BatchIndex)
 	shares := eon.GetEpochSecretKeyShares(syncHeight)
 	for _, share := range shares {
 		sender, err := medley.FindAddressIndex(ekg.Keypers, share.Sender)
@@ -644,31 +1058,121 @@ func (dcdr *Decider) syncEKGWithEon(syncHeight int64, ekg *EKG, eon *observe.Eon
 			continue
 		}
 		// Ignore this epoch secret key share, if we already have the secret key
-		if _, ok := ekg.EpochKG.SecretKeys[share.Epoch]; ok {
+		if _, ok := ekg.EpochKG.SecretKey(share.Namespace, share.Epoch); ok {
 			continue
 		}
 		err = ekg.EpochKG.HandleEpochSecretKeyShare(
 			&epochkg.EpochSecretKeyShare{
-				Eon:    share.Eon,
-				Epoch:  share.Epoch,
-				Sender: uint64(sender),
-				Share:  share.Share,
+				Eon:       share.Eon,
+				Epoch:     share.Epoch,
+				Sender:    uint64(sender),
+				Share:     share.Share,
+				Namespace: share.Namespace,
 			},
 		)
 		if err != nil {
 			log.Printf("Error while handling epoch secret key share: %+v", err)
 			continue
 		}
+		if share.Namespace != "" {
+			if _, ok := ekg.EpochKG.SecretKey(share.Namespace, share.Epoch); ok {
+				log.Printf("Epoch secret key generated for epoch %d in namespace %q", share.Epoch, share.Namespace)
+			}
+			// Only the default namespace is used to decrypt batches
+			continue
+		}
+		if batchConfig.PerBlockEpochs {
+			if _, ok := ekg.EpochKG.SecretKeys[share.Epoch]; ok {
+				log.Printf("Epoch secret key generated for block %d", share.Epoch)
+				dcdr.Latency.KeyGenerated(share.Epoch)
+			}
+			// Epochs are block numbers, so there are no batches to decrypt
+			continue
+		}
 		if key, ok := ekg.EpochKG.SecretKeys[share.Epoch]; ok {
-			log.Printf("Epoch secret key generated for epoch %d", share.Epoch)
-			dcdr.decryptTransactions(key, share.Epoch)
-			if !dcdr.executionTimeoutReachedOrInactive(share.Epoch) {
-				dcdr.sendDecryptionSignature(share.Epoch)
+			batchIndex, ok := dcdr.Shutter.FindBatchIndexByEpoch(share.Epoch)
+			if !ok {
+				log.Printf("Epoch secret key generated for epoch %d, which is not used by any batch", share.Epoch)
+				continue
+			}
+			log.Printf("Epoch secret key generated for epoch %d, batch %d", share.Epoch, batchIndex)
+			dcdr.Latency.KeyGenerated(share.Epoch)
+			if active, ok := dcdr.State.ActiveEon(batchIndex); !ok || active != ekg.Eon {
+				// the batch has been encrypted for another eon, so the key can't decrypt it
+				log.Printf("Warning: batch %d belongs to eon %d, not decrypting it with the key of eon %d", batchIndex, active, ekg.Eon)
+				continue
+			}
+			dcdr.decryptTransactions(key, batchIndex, share.Epoch, eon.StartEvent.BatchIndex, uint8(batchConfig.EncryptionVersion))
+			if !dcdr.executionTimeoutReachedOrInactive(batchIndex) {
+				dcdr.sendDecryptionSignature(batchIndex)
 			}
+			dcdr.scheduleEpochKeySubmission(eon.StartEvent.BatchIndex, batchIndex, key)
 		}
 	}
 }
 
+// scheduleEpochKeySubmission remembers to submit the epoch secret key to the decryption oracle
+// contract. Like executions, submissions are staggered so that usually only one keyper pays for
+// the transaction.
+func (dcdr *Decider) scheduleEpochKeySubmission(
+	eonStartBatchIndex uint64,
+	batchIndex uint64,
+	key *shcrypto.EpochSecretKey,
+) {
+	if dcdr.Config.DecryptionOracleAddress == (common.Address{}) {
+		return
+	}
+	config, ok := dcdr.MainChain.ConfigForBatchIndex(batchIndex)
+	if !ok {
+		return
+	}
+	keyperIndex, ok := config.KeyperIndex(dcdr.Config.Address())
+	if !ok {
+		return
+	}
+	place := (batchIndex + keyperIndex) % uint64(len(config.Keypers))
+
+	if dcdr.State.EpochKeySubmissions == nil {
+		dcdr.State.EpochKeySubmissions = make(map[uint64]*EpochKeySubmission)
+	}
+	dcdr.State.EpochKeySubmissions[batchIndex] = &EpochKeySubmission{
+		EonStartBatchIndex: eonStartBatchIndex,
+		Key:                key,
+		SubmitBlock:        dcdr.MainChain.CurrentBlock + place*dcdr.Config.OracleSubmissionStaggering,
+	}
+}
+
+// maybeSubmitEpochKeys submits the epoch secret keys whose submission block has been reached.
+func (dcdr *Decider) maybeSubmitEpochKeys() {
+	var batchIndices []uint64
+	for batchIndex, submission := range dcdr.State.EpochKeySubmissions {
+		if dcdr.MainChain.CurrentBlock >= submission.SubmitBlock {
+			batchIndices = append(batchIndices, batchIndex)
+		}
+	}
+	sort.Slice(batchIndices, func(i, j int) bool { return batchIndices[i] < batchIndices[j] })
+
+	for _, batchIndex := range batchIndices {
+		submission := dcdr.State.EpochKeySubmissions[batchIndex]
+		delete(dcdr.State.EpochKeySubmissions, batchIndex)
+
+		config, ok := dcdr.MainChain.ConfigForBatchIndex(batchIndex)
+		if !ok {
+			continue
+		}
+		keyperIndex, ok := config.KeyperIndex(dcdr.Config.Address())
+		if !ok {
+			continue
+		}
+		dcdr.addAction(&fx.SubmitEpochKey{
+			KeyperIndex:        keyperIndex,
+			BatchIndex:         batchIndex,
+			EonStartBatchIndex: submission.EonStartBatchIndex,
+			Key:                submission.Key,
+		})
+	}
+}
+
 // Add a prefix to avoid accidentally signing data with special meaning in different context, in
 // particular Ethereum transactions (c.f. EIP191 https://eips.ethereum.org/EIPS/eip-191).
 var hashPrefix = []byte{0x19, 'd', 'e', 'c', 't', 'x'}
@@ -720,8 +1224,11 @@ func (dcdr *Decider) computeDecryptionSignatureHash(batchIndex uint64, cipherBat
 	return keccak.Sum(nil)
 }
 
-func (dcdr *Decider) decryptTransactions(key *shcrypto.EpochSecretKey, epoch uint64) {
-	batchIndex := epoch
+// decryptTransactions decrypts the given batch with the key of the given epoch. Ciphertexts made for
+// another eon or epoch or with another version of the encryption scheme are left out.
+func (dcdr *Decider) decryptTransactions(
+	key *shcrypto.EpochSecretKey, batchIndex, epoch, eonStartBatchIndex uint64, version uint8,
+) {
 	batch, ok := dcdr.MainChain.Batches[batchIndex]
 	if !ok {
 		// We may run into this case if our main chain node is lagging behind or if the
@@ -729,7 +1236,8 @@ func (dcdr *Decider) decryptTransactions(key *shcrypto.EpochSecretKey, epoch uin
 		log.Printf("Batch missing for batch index=%d", batchIndex)
 		batch = &observe.Batch{BatchIndex: batchIndex}
 	}
-	txs := batch.DecryptTransactions(key)
+	txs := dcdr.dropDuplicateCiphertexts(batch).DecryptTransactions(key, eonStartBatchIndex, shcrypto.ComputeEpochID(epoch), version)
+	txs = dcdr.replaceInvalidTransactions(batchIndex, txs)
 	decryptedBatchHash := transactionsHash(txs)
 	hash := dcdr.computeDecryptionSignatureHash(batchIndex, batch.EncryptedBatchHash.Bytes(), decryptedBatchHash)
 
@@ -744,8 +1252,7 @@ func (dcdr *Decider) decryptTransactions(key *shcrypto.EpochSecretKey, epoch uin
 	dcdr.State.Batches[batchIndex] = stBatch
 }
 
-func (dcdr *Decider) sendDecryptionSignature(epoch uint64) {
-	batchIndex := epoch
+func (dcdr *Decider) sendDecryptionSignature(batchIndex uint64) {
 	stBatch, ok := dcdr.State.Batches[batchIndex]
 	if !ok {
 		log.Printf("Batch %d is missing", batchIndex)
@@ -784,26 +1291,115 @@ func (dcdr *Decider) syncEKGs() {
 }
 
 func (dcdr *Decider) publishEpochSecretKeyShares() {
-	blockNum := dcdr.MainChain.CurrentBlock
+	blockNum := dcdr.MainChain.BatchClosureBlock()
 
 	// Find the active config for the given block on the main chain
 	activeCFGIdx := dcdr.MainChain.ActiveConfigIndex(blockNum)
 	bc := dcdr.MainChain.BatchConfigs[activeCFGIdx]
 
 	// find the corresponding config on shutter
-	_, err := dcdr.Shutter.FindBatchConfigByConfigIndex(uint64(activeCFGIdx))
+	shutterBC, err := dcdr.Shutter.FindBatchConfigByConfigIndex(uint64(activeCFGIdx))
 	if err != nil {
 		return
 	}
 
 	currentBatchIndex := bc.BatchIndex(blockNum)
-	// publish the private epoch key share for batch indexes < currentBatchIndex
-	for batchIndex := dcdr.State.NextEpochSecretShare; batchIndex < currentBatchIndex; batchIndex++ {
-		if !dcdr.executionTimeoutReachedOrInactive(batchIndex) {
-			dcdr.publishEpochSecretKeyShare(batchIndex)
+	if shutterBC.PerBlockEpochs {
+		dcdr.publishBlockEpochSecretKeyShares(bc)
+		dcdr.State.NextEpochSecretShare = currentBatchIndex
+		dcdr.precomputeEpochSecretKeyShares(currentBatchIndex, blockNum)
+		return
+	}
+	// publish the private epoch key share for batch indexes < currentBatchIndex, stopping at the
+	// first one the policy delays
+	batchIndex := dcdr.State.NextEpochSecretShare
+	var numSkipped, firstSkipped, lastSkipped uint64
+	for ; batchIndex < currentBatchIndex; batchIndex++ {
+		if dcdr.executionTimeoutReachedOrInactive(batchIndex) {
+			batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(batchIndex)
+			epoch := batchConfig.Epoch(batchIndex)
+			dcdr.skipEpochSecretKeyShares(batchIndex, epoch, epoch)
+			if numSkipped == 0 {
+				firstSkipped = batchIndex
+			}
+			numSkipped++
+			lastSkipped = batchIndex
+			continue
+		}
+		batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(batchIndex)
+		dcdr.Latency.EpochEnded(batchConfig.Epoch(batchIndex))
+		if dcdr.publishEpochSecretKeyShare(batchIndex) == policy.Delay {
+			break
+		}
+	}
+	if numSkipped > 0 {
+		// usually because we've been offline, the batches can't be executed anymore anyway
+		log.Printf("Warning: skipping the epoch secret key shares of %d batches from %d to %d, "+
+			"their execution timeout has been reached or their config is inactive",
+			numSkipped, firstSkipped, lastSkipped)
+	}
+	dcdr.State.NextEpochSecretShare = batchIndex
+	dcdr.precomputeEpochSecretKeyShares(currentBatchIndex, shutterBC.Epoch(currentBatchIndex))
+}
+
+// precomputeEpochSecretKeyShares starts computing our epoch secret key shares for the upcoming
+// epochs starting at firstEpoch, so that they are ready when the epochs are over. The eon is the
+// one the given batch belongs to.
+func (dcdr *Decider) precomputeEpochSecretKeyShares(batchIndex uint64, firstEpoch uint64) {
+	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(batchIndex)
+	if !batchConfig.IsKeyper(dcdr.Config.Address()) {
+		return
+	}
+	ekg, err := dcdr.State.FindEKGByBatchIndex(batchIndex)
+	if err != nil {
+		return
+	}
+	dcdr.ShareCache.Precompute(ekg.EpochKG, "", firstEpoch, numPrecomputedEpochs)
+	for _, namespace := range batchConfig.EpochNamespaces {
+		dcdr.ShareCache.Precompute(ekg.EpochKG, namespace, firstEpoch, numPrecomputedEpochs)
+	}
+}
+
+// publishBlockEpochSecretKeyShares publishes the epoch secret key shares for the main chain blocks
+// of the given config mined since the last call. It is used instead of publishing shares per
+// batch if the config uses per-block epochs.
+func (dcdr *Decider) publishBlockEpochSecretKeyShares(bc contract.BatchConfig) {
+	currentBlock := dcdr.MainChain.BatchClosureBlock()
+
+	block := dcdr.State.NextBlockEpochSecretShare
+	if block < bc.StartBlockNumber {
+		block = bc.StartBlockNumber
+	}
+	if currentBlock > maxBlockEpochBacklog && block < currentBlock-maxBlockEpochBacklog {
+		first := block
+		block = currentBlock - maxBlockEpochBacklog
+		log.Printf("Warning: skipping the epoch secret key shares of the %d blocks from %d to %d, "+
+			"they are more than %d blocks old", block-first, first, block-1, maxBlockEpochBacklog)
+		dcdr.skipBlockEpochSecretKeyShares(bc, first, block-1)
+	}
+	for ; block < currentBlock; block++ {
+		dcdr.Latency.EpochEnded(block)
+		if dcdr.publishEpochSecretKeyShareInEon(bc.BatchIndex(block), block) == policy.Delay {
+			break
 		}
 	}
-	dcdr.State.NextEpochSecretShare = currentBatchIndex
+	if block > dcdr.State.NextBlockEpochSecretShare {
+		dcdr.State.NextBlockEpochSecretShare = block
+	}
+}
+
+// skipBlockEpochSecretKeyShares records that we don't publish our shares for the per-block epochs
+// of the given config from first to last, both inclusive.
+func (dcdr *Decider) skipBlockEpochSecretKeyShares(bc contract.BatchConfig, first, last uint64) {
+	for block := first; block <= last; {
+		batchIndex := bc.BatchIndex(block)
+		end := last
+		if bc.BatchSpan != 0 && bc.BatchEndBlock(batchIndex)-1 < end {
+			end = bc.BatchEndBlock(batchIndex) - 1
+		}
+		dcdr.skipEpochSecretKeyShares(batchIndex, block, end)
+		block = end + 1
+	}
 }
 
 // executionTimeoutReachedOrInactive checks if the execution timeout for the given batch has been reached or
@@ -822,13 +1418,23 @@ func (dcdr *Decider) handleEpochKG() {
 	dcdr.publishEpochSecretKeyShares()
 }
 
-func (dcdr *Decider) sendEpochSecretKeyShare(epochKG *epochkg.EpochKG, epoch uint64) {
-	if _, ok := epochKG.SecretKeys[epoch]; !ok {
-		epochSecretKeyShare := epochKG.ComputeEpochSecretKeyShare(epoch)
+func (dcdr *Decider) sendEpochSecretKeyShare(epochKG *epochkg.EpochKG, namespace string, epoch uint64) {
+	if _, ok := epochKG.SecretKey(namespace, epoch); !ok {
+		if dcdr.epochSecretKeyShareSent(epochKG.Eon, namespace, epoch) {
+			log.Printf("Not sending epoch secret key share for epoch %d in eon %d, namespace %q, again",
+				epoch, epochKG.Eon, namespace)
+			return
+		}
+		dcdr.State.epochShares(epochKG.Eon, namespace).Sent.Add(epoch, epoch)
+		epochSecretKeyShare := dcdr.ShareCache.Share(epochKG, namespace, epoch)
 		dcdr.sendShuttermintMessage(
-			fmt.Sprintf("epoch secret key share, epoch=%d in eon=%d", epoch, epochKG.Eon),
-			shmsg.NewEpochSecretKeyShare(epochKG.Eon, epoch, epochSecretKeyShare),
+			fmt.Sprintf("epoch secret key share, epoch=%d in eon=%d, namespace=%q", epoch, epochKG.Eon, namespace),
+			shmsg.NewNamespacedEpochSecretKeyShare(epochKG.Eon, namespace, epoch, epochSecretKeyShare),
 		)
+		if namespace == "" {
+			dcdr.Latency.SharePublished(epoch)
+		}
+		dcdr.Metrics.ShareSent()
 	}
 }
 
@@ -882,11 +1488,12 @@ func (dcdr *Decider) handleDecryptionSignatures() {
 }
 
 func (dcdr *Decider) maybeExecuteBatch() {
-	config := dcdr.MainChain.CurrentConfig()
+	closureBlock := dcdr.MainChain.BatchClosureBlock()
+	config := dcdr.MainChain.ActiveConfig(closureBlock)
 	if !config.IsActive() {
 		return // nothing to execute if config is inactive
 	}
-	batchIndex := config.BatchIndex(dcdr.MainChain.CurrentBlock)
+	batchIndex := config.BatchIndex(closureBlock)
 
 	nextHalfStep := dcdr.MainChain.NumExecutionHalfSteps
 	if dcdr.State.PendingHalfStep != nil && nextHalfStep > *dcdr.State.PendingHalfStep {
@@ -918,10 +1525,11 @@ func (dcdr *Decider) maybeExecuteBatch() {
 // next half step to execute (i.e., the total number of already executed half steps) and the
 // current batch index.
 func getNumHalfStepsToExecute(nextHalfStep uint64, batchIndex uint64) uint64 {
-	if nextHalfStep >= batchIndex*2 {
+	cipherHalfStep := uint64(protocol.BatchIndex(batchIndex).CipherHalfStep())
+	if nextHalfStep >= cipherHalfStep {
 		return 0
 	}
-	numMissingHalfSteps := batchIndex*2 - nextHalfStep
+	numMissingHalfSteps := cipherHalfStep - nextHalfStep
 	if numMissingHalfSteps <= maxParallelHalfSteps {
 		return numMissingHalfSteps
 	}
@@ -944,9 +1552,12 @@ func (dcdr *Decider) executeCipherBatch(batchIndex uint64, config contract.Batch
 		return nil
 	}
 
-	if !stBatch.IsEmpty && uint64(len(stBatch.VerifiedSignatures)) < config.Threshold {
-		log.Printf("Not enough votes for batch %d", batchIndex)
-		return nil
+	if !stBatch.IsEmpty {
+		halfStep := uint64(protocol.BatchIndex(batchIndex).CipherHalfStep())
+		if _, ok := dcdr.collectAuthorization(halfStep, stBatch, config); !ok {
+			log.Printf("Not enough votes for batch %d", batchIndex)
+			return nil
+		}
 	}
 
 	return &fx.ExecuteCipherBatch{
@@ -971,7 +1582,8 @@ func (dcdr *Decider) executePlainBatch(batchIndex uint64, config contract.BatchC
 }
 
 func (dcdr *Decider) maybeExecuteHalfStep(nextHalfStep uint64) fx.IAction {
-	batchIndex := nextHalfStep / 2
+	halfStep := protocol.HalfStep(nextHalfStep)
+	batchIndex := uint64(halfStep.BatchIndex())
 
 	config, ok := dcdr.MainChain.ConfigForBatchIndex(batchIndex)
 	if !ok {
@@ -981,11 +1593,13 @@ func (dcdr *Decider) maybeExecuteHalfStep(nextHalfStep uint64) fx.IAction {
 	delay := dcdr.executionDelay(config, nextHalfStep)
 	executionBlock := config.BatchEndBlock(batchIndex) + delay
 	executionTimeoutBlock := config.BatchEndBlock(batchIndex) + config.ExecutionTimeout
-	isCipherBatch := nextHalfStep%2 == 0
+	isCipherBatch := halfStep.IsCipher()
 
-	// skip cipher half steps if execution timeout block + delay is passed
+	// skip cipher half steps if the execution timeout block is passed, a threshold of keypers
+	// agrees, and our delay since the agreement is passed, see maybeVoteSkipCipher
 	if isCipherBatch && dcdr.MainChain.CurrentBlock >= executionTimeoutBlock {
-		if dcdr.MainChain.CurrentBlock >= executionTimeoutBlock+delay {
+		agreedBlock, ok := dcdr.skipCipherAgreed(batchIndex, config)
+		if ok && dcdr.MainChain.CurrentBlock >= agreedBlock+delay {
 			return &fx.SkipCipherBatch{
 				BatchIndex: batchIndex,
 			}
@@ -1000,6 +1614,9 @@ func (dcdr *Decider) maybeExecuteHalfStep(nextHalfStep uint64) fx.IAction {
 
 	// execute batch if execution block is passed
 	if dcdr.MainChain.CurrentBlock >= executionBlock {
+		if !dcdr.executionAllowed(nextHalfStep) {
+			return nil
+		}
 		if isCipherBatch {
 			return dcdr.executeCipherBatch(batchIndex, config)
 		}
@@ -1008,13 +1625,30 @@ func (dcdr *Decider) maybeExecuteHalfStep(nextHalfStep uint64) fx.IAction {
 	return nil
 }
 
-func (dcdr *Decider) getSortedDecryptionSignaturesWithIndices(batch *Batch) ([][]byte, []uint64, error) {
+// executionAllowed asks the policy if we may execute the given half step.
+func (dcdr *Decider) executionAllowed(halfStep uint64) bool {
+	step := protocol.HalfStep(halfStep)
+	decision := dcdr.policy().Execute(dcdr.world(), policy.Execution{
+		HalfStep:   step,
+		BatchIndex: step.BatchIndex(),
+		Cipher:     step.IsCipher(),
+	})
+	if decision != policy.Allow {
+		log.Printf("Execution policy decided to %s executing half step %d", decision, halfStep)
+		return false
+	}
+	return true
+}
+
+func (dcdr *Decider) getSortedDecryptionSignaturesWithIndices(
+	batch *Batch, signatures map[common.Address][]byte,
+) ([][]byte, []uint64, error) {
 	config, ok := dcdr.MainChain.ConfigForBatchIndex(batch.BatchIndex)
 	if !ok {
 		panic("Error in syncBatch: config is not active")
 	}
-	if uint64(len(batch.VerifiedSignatures)) < config.Threshold {
-		return nil, nil, pkgErrors.Errorf("not enough signatures (only %d out of %d)", len(batch.VerifiedSignatures), config.Threshold)
+	if uint64(len(signatures)) < config.Threshold {
+		return nil, nil, pkgErrors.Errorf("not enough signatures (only %d out of %d)", len(signatures), config.Threshold)
 	}
 
 	type SigAndIndex struct {
@@ -1023,7 +1657,7 @@ func (dcdr *Decider) getSortedDecryptionSignaturesWithIndices(batch *Batch) ([][
 	}
 
 	sigsAndIndices := []SigAndIndex{}
-	for addr, sig := range batch.VerifiedSignatures {
+	for addr, sig := range signatures {
 		keyperIndex, ok := config.KeyperIndex(addr)
 		if !ok {
 			return nil, nil, pkgErrors.Errorf("signer %s not a keyper in batch %d", addr.Hex(), batch.BatchIndex)
@@ -1039,23 +1673,24 @@ func (dcdr *Decider) getSortedDecryptionSignaturesWithIndices(batch *Batch) ([][
 		return sigsAndIndices[i].index < sigsAndIndices[j].index
 	})
 
-	signatures := [][]byte{}
+	sortedSignatures := [][]byte{}
 	indices := []uint64{}
 	for _, sigAndIndex := range sigsAndIndices {
-		signatures = append(signatures, sigAndIndex.signature)
+		sortedSignatures = append(sortedSignatures, sigAndIndex.signature)
 		indices = append(indices, sigAndIndex.index)
 	}
 
-	return signatures, indices, nil
+	return sortedSignatures, indices, nil
 }
 
 // maybeAppeal checks if there are any accusations against anyone and if so sends an appeal if
 // possible.
 func (dcdr *Decider) maybeAppeal() {
 	dcdr.syncPendingAppeals()
+	dcdr.syncAuthorizationRequests()
 
 	for _, accusation := range dcdr.MainChain.Accusations {
-		batchIndex := accusation.HalfStep / 2
+		batchIndex := uint64(protocol.HalfStep(accusation.HalfStep).BatchIndex())
 
 		if accusation.Appealed {
 			continue
@@ -1080,7 +1715,17 @@ func (dcdr *Decider) maybeAppeal() {
 			continue // don't send appeal if we agree with accusation
 		}
 
-		signatures, indices, err := dcdr.getSortedDecryptionSignaturesWithIndices(stBatch)
+		config, ok := dcdr.MainChain.ConfigForBatchIndex(batchIndex)
+		if !ok {
+			log.Printf("Error: cannot appeal because config of batch %d is missing", batchIndex)
+			continue
+		}
+		collected, ok := dcdr.collectAuthorization(accusation.HalfStep, stBatch, config)
+		if !ok {
+			log.Printf("Cannot appeal batch %d yet, waiting for signatures", batchIndex)
+			continue
+		}
+		signatures, indices, err := dcdr.getSortedDecryptionSignaturesWithIndices(stBatch, collected)
 		if err != nil {
 			log.Printf("Error: cannot appeal batch %d: %s", batchIndex, err)
 			continue
@@ -1098,17 +1743,21 @@ func (dcdr *Decider) maybeAppeal() {
 			Authorization: authorization,
 		}
 		dcdr.State.PendingAppeals[accusation.HalfStep] = struct{}{}
+		if dcdr.State.AppealSentBlocks == nil {
+			dcdr.State.AppealSentBlocks = make(map[uint64]uint64)
+		}
+		dcdr.State.AppealSentBlocks[accusation.HalfStep] = dcdr.MainChain.CurrentBlock
 		dcdr.addAction(&action)
 	}
 }
 
 func (dcdr *Decider) maybeAccuse() {
 	for halfStep := dcdr.State.HalfStepsChecked; halfStep < dcdr.MainChain.NumExecutionHalfSteps; halfStep++ {
-		if halfStep%2 != 0 {
+		if !protocol.HalfStep(halfStep).IsCipher() {
 			continue // only accuse for cipher execution half steps, not plain ones
 		}
 
-		batchIndex := halfStep / 2
+		batchIndex := uint64(protocol.HalfStep(halfStep).BatchIndex())
 
 		receipt := dcdr.MainChain.CipherExecutionReceipts[halfStep]
 		if receipt == nil {
@@ -1148,16 +1797,24 @@ func (dcdr *Decider) maybeAccuse() {
 }
 
 // syncPendingAppeals removes any pending appeals that have been successfully handled by the main
-// chain.
+// chain or whose accusation is gone. Appeals that haven't been handled AppealTimeout main chain
+// blocks after we've sent them are removed as well, so that we appeal again if our tx failed.
 // XXX: It's possible that someone else appeals, in which case our tx would still be pending.
-// Also, we don't notice if our tx fails (but this shouldn't happen if we prepare it properly).
 func (dcdr *Decider) syncPendingAppeals() {
 	for halfStep := range dcdr.State.PendingAppeals {
-		for _, accusation := range dcdr.MainChain.Accusations {
-			if halfStep == accusation.HalfStep && accusation.Appealed {
-				delete(dcdr.State.PendingAppeals, halfStep)
-			}
+		accusation, ok := dcdr.MainChain.Accusations[halfStep]
+		sentBlock, sent := dcdr.State.AppealSentBlocks[halfStep]
+		timedOut := dcdr.Config.AppealTimeout > 0 && sent &&
+			dcdr.MainChain.CurrentBlock >= sentBlock+dcdr.Config.AppealTimeout
+		if ok && !accusation.Appealed && !timedOut {
+			continue
+		}
+		if ok && !accusation.Appealed {
+			log.Printf("Appeal for half step %d not handled after %d blocks, will appeal again",
+				halfStep, dcdr.MainChain.CurrentBlock-sentBlock)
 		}
+		delete(dcdr.State.PendingAppeals, halfStep)
+		delete(dcdr.State.AppealSentBlocks, halfStep)
 	}
 }
 
@@ -1200,14 +1857,22 @@ func (dcdr *Decider) Decide() {
 		log.Printf("Not registered as keyper in shuttermint, nothing to do")
 		return
 	}
-	dcdr.maybeSendCheckIn()
-	dcdr.maybeSendBatchConfig()
-	dcdr.maybeStartDKG()
-	dcdr.handleDKGs()
-	dcdr.handleEpochKG()
-	dcdr.handleDecryptionSignatures()
-	dcdr.maybeExecuteBatch()
-	dcdr.maybeAppeal()
-	dcdr.maybeAccuse()
+	dcdr.traced("handleHalt", dcdr.handleHalt)
+	dcdr.traced("maybeSendCheckIn", dcdr.maybeSendCheckIn)
+	dcdr.traced("maybeAnnounceCapabilities", dcdr.maybeAnnounceCapabilities)
+	dcdr.traced("maybeSendBatchConfig", dcdr.maybeSendBatchConfig)
+	dcdr.traced("handleDirectMessages", dcdr.handleDirectMessages)
+	dcdr.traced("updateEonActivations", dcdr.updateEonActivations)
+	dcdr.traced("maybeStartDKG", dcdr.maybeStartDKG)
+	dcdr.traced("handleDKGs", dcdr.handleDKGs)
+	dcdr.traced("handleEpochKG", dcdr.handleEpochKG)
+	dcdr.traced("handleDecryptionApprovals", dcdr.handleDecryptionApprovals)
+	dcdr.traced("handleDecryptionSignatures", dcdr.handleDecryptionSignatures)
+	dcdr.traced("maybeVoteSkipCipher", dcdr.maybeVoteSkipCipher)
+	dcdr.traced("maybeExecuteBatch", dcdr.maybeExecuteBatch)
+	dcdr.traced("maybeAppeal", dcdr.maybeAppeal)
+	dcdr.traced("maybeAccuse", dcdr.maybeAccuse)
+	dcdr.traced("maybeSubmitEpochKeys", dcdr.maybeSubmitEpochKeys)
+	dcdr.traced("pruneState", dcdr.pruneState)
 	dcdr.State.SyncHeight = dcdr.Shutter.CurrentBlock + 1
 }
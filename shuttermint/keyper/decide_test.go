@@ -0,0 +1,42 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+)
+
+func TestSendPolyCommitments(t *testing.T) {
+	newDKG := func(eon uint64) *DKG {
+		pure := puredkg.NewPureDKG(eon, 3, 2, 0)
+		return &DKG{Eon: eon, Pure: &pure}
+	}
+
+	dcdr := Decider{}
+	dcdr.startPhase1Dealing(newDKG(1), puredkg.Dealing)
+	dcdr.sendPolyCommitments()
+	assert.Equal(t, len(dcdr.Actions), 1)
+	msg := dcdr.Actions[0].(*fx.SendShuttermintMessage).Msg
+	assert.Equal(t, msg.GetPolyCommitment().Eon, uint64(1))
+
+	// dealing in several DKGs at once results in a single message
+	dcdr = Decider{}
+	dkg2, dkg3 := newDKG(2), newDKG(3)
+	dcdr.startPhase1Dealing(dkg2, puredkg.Dealing)
+	dcdr.startPhase1Dealing(dkg3, puredkg.Dealing)
+	dcdr.startPhase1Dealing(newDKG(4), puredkg.Accusing) // too late to deal
+	dcdr.sendPolyCommitments()
+	assert.Equal(t, len(dcdr.Actions), 1)
+	commitments := dcdr.Actions[0].(*fx.SendShuttermintMessage).Msg.GetPolyCommitments().Commitments
+	assert.Equal(t, len(commitments), 2)
+	assert.Equal(t, commitments[0].Eon, uint64(2))
+	assert.Equal(t, commitments[1].Eon, uint64(3))
+	assert.Equal(t, len(commitments[1].Gammas), 2)
+	assert.Equal(t, len(dkg3.OutgoingPolyEvalMsgs), 2) // one per other keyper
+
+	dcdr.sendPolyCommitments()
+	assert.Equal(t, len(dcdr.Actions), 1)
+}
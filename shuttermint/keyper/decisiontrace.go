@@ -0,0 +1,138 @@
+package keyper
+
+import (
+	"fmt"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/trace"
+)
+
+// traced runs the given decider function. If tracing is enabled, it records the inputs the
+// function is based on and the actions it emitted in the step's trace.
+func (dcdr *Decider) traced(name string, f func()) {
+	if dcdr.Trace == nil {
+		f()
+		return
+	}
+	inputs := dcdr.traceInputs(name)
+	dcdr.tracedActions = []string{}
+	f()
+	dcdr.Trace.Decisions = append(dcdr.Trace.Decisions, trace.Decision{
+		Func:    name,
+		Inputs:  inputs,
+		Fired:   len(dcdr.tracedActions) > 0,
+		Actions: dcdr.tracedActions,
+	})
+	dcdr.tracedActions = nil
+}
+
+// traceAction records an emitted action for the decision currently being traced.
+func (dcdr *Decider) traceAction(a fx.IAction) {
+	if dcdr.tracedActions != nil {
+		dcdr.tracedActions = append(dcdr.tracedActions, fmt.Sprint(a))
+	}
+}
+
+// traceInputs returns the parts of the state the given decider function bases its decision on.
+func (dcdr *Decider) traceInputs(name string) trace.Inputs {
+	st := dcdr.State
+	switch name {
+	case "handleHalt":
+		inputs := trace.Inputs{
+			"ShutterHeight":      dcdr.Shutter.CurrentBlock,
+			"ShutterHalted":      dcdr.ShutterHalted,
+			"NumHaltResumeVotes": len(dcdr.Shutter.HaltResumeVotes),
+			"HaltHeight":         nil,
+		}
+		if st.Halt != nil {
+			inputs["HaltHeight"] = st.Halt.Height
+		}
+		return inputs
+	case "maybeSendCheckIn":
+		return trace.Inputs{
+			"CheckInMessageSent": st.CheckInMessageSent,
+			"CheckedIn":          dcdr.Shutter.IsCheckedIn(dcdr.Config.Address()),
+		}
+	case "maybeAnnounceCapabilities":
+		inputs := trace.Inputs{
+			"ShutterHeight":          dcdr.Shutter.CurrentBlock,
+			"CapabilitiesSentHeight": st.CapabilitiesSentHeight,
+			"AnnouncedHeight":        nil,
+		}
+		if latest, ok := dcdr.Shutter.Capabilities[dcdr.Config.Address()]; ok {
+			inputs["AnnouncedHeight"] = latest.Height
+		}
+		return inputs
+	case "maybeSendBatchConfig":
+		return trace.Inputs{
+			"LastSentBatchConfigIndex": st.LastSentBatchConfigIndex,
+			"NumMainChainConfigs":      len(dcdr.MainChain.BatchConfigs),
+			"NumShutterConfigs":        len(dcdr.Shutter.BatchConfigs),
+		}
+	case "maybeStartDKG":
+		return trace.Inputs{
+			"LastEonStarted": st.LastEonStarted,
+			"NumEons":        len(dcdr.Shutter.Eons),
+			"NumDKGs":        len(st.DKGs),
+		}
+	case "handleDKGs":
+		return trace.Inputs{
+			"ShutterHeight": dcdr.Shutter.CurrentBlock,
+			"SyncHeight":    st.SyncHeight,
+			"NumDKGs":       len(st.DKGs),
+		}
+	case "handleEpochKG":
+		return trace.Inputs{
+			"MainChainBlock":            dcdr.MainChain.CurrentBlock,
+			"SyncHeight":                st.SyncHeight,
+			"NextEpochSecretShare":      st.NextEpochSecretShare,
+			"NextBlockEpochSecretShare": st.NextBlockEpochSecretShare,
+			"NumEKGs":                   len(st.EKGs),
+		}
+	case "handleDecryptionApprovals":
+		return trace.Inputs{
+			"ShutterHeight":          dcdr.Shutter.CurrentBlock,
+			"NumDecryptionApprovals": len(dcdr.Shutter.DecryptionApprovals),
+			"NumApprovalsInState":    len(st.DecryptionApprovals),
+			"NextEpochSecretShare":   st.NextEpochSecretShare,
+		}
+	case "handleDecryptionSignatures":
+		return trace.Inputs{
+			"ShutterHeight": dcdr.Shutter.CurrentBlock,
+			"NumBatches":    len(st.Batches),
+		}
+	case "maybeExecuteBatch":
+		inputs := trace.Inputs{
+			"MainChainBlock":        dcdr.MainChain.CurrentBlock,
+			"NumExecutionHalfSteps": dcdr.MainChain.NumExecutionHalfSteps,
+			"PendingHalfStep":       nil,
+		}
+		if st.PendingHalfStep != nil {
+			inputs["PendingHalfStep"] = *st.PendingHalfStep
+		}
+		return inputs
+	case "maybeVoteSkipCipher":
+		return trace.Inputs{
+			"MainChainBlock":        dcdr.MainChain.CurrentBlock,
+			"NumExecutionHalfSteps": dcdr.MainChain.NumExecutionHalfSteps,
+			"NumSkipCipherVotes":    len(st.SkipCipherVotes),
+		}
+	case "maybeAppeal":
+		return trace.Inputs{
+			"NumAccusations":    len(dcdr.MainChain.Accusations),
+			"NumPendingAppeals": len(st.PendingAppeals),
+		}
+	case "maybeAccuse":
+		return trace.Inputs{
+			"HalfStepsChecked":      st.HalfStepsChecked,
+			"NumExecutionHalfSteps": dcdr.MainChain.NumExecutionHalfSteps,
+		}
+	case "maybeSubmitEpochKeys":
+		return trace.Inputs{
+			"MainChainBlock":         dcdr.MainChain.CurrentBlock,
+			"NumEpochKeySubmissions": len(st.EpochKeySubmissions),
+		}
+	default:
+		return trace.Inputs{}
+	}
+}
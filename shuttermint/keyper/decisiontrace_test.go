@@ -0,0 +1,45 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/trace"
+)
+
+func TestTraced(t *testing.T) {
+	dcdr := Decider{
+		State:     NewState(),
+		Shutter:   observe.NewShutter(),
+		MainChain: observe.NewMainChain(0),
+	}
+	emit := func() {
+		dcdr.addAction(&fx.SendShuttermintMessage{Description: "foo"})
+		dcdr.addPriorityShuttermintMessage("bar", nil)
+	}
+
+	// without a trace, functions are just run
+	dcdr.traced("maybeAccuse", emit)
+	assert.Equal(t, len(dcdr.Actions), 2)
+
+	dcdr.Trace = &trace.Step{}
+	dcdr.State.HalfStepsChecked = 4
+	dcdr.traced("maybeAccuse", emit)
+	dcdr.traced("maybeAppeal", func() {})
+	assert.Equal(t, len(dcdr.Actions), 4)
+	assert.Equal(t, len(dcdr.Trace.Decisions), 2)
+
+	accuse := dcdr.Trace.Decisions[0]
+	assert.Equal(t, accuse.Func, "maybeAccuse")
+	assert.Assert(t, accuse.Fired)
+	assert.Equal(t, len(accuse.Actions), 2)
+	assert.Equal(t, accuse.Inputs["HalfStepsChecked"], uint64(4))
+
+	appeal := dcdr.Trace.Decisions[1]
+	assert.Assert(t, !appeal.Fired)
+	assert.Equal(t, len(appeal.Actions), 0)
+	assert.Equal(t, appeal.Inputs["NumPendingAppeals"], 0)
+}
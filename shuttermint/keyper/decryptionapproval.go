@@ -0,0 +1,281 @@
+package keyper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	pkgErrors "github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// The keys of epochs the key release policy delays until their batch's execution timeout are
+// never released, see publishEpochSecretKeyShares, e.g. if the operators have paused releases
+// while investigating a suspected key compromise. If such an epoch has to be decrypted after all,
+// the keypers do so in a governed way instead of operators publishing shares on their own:
+// operators approve the decryption via the admin API, their keypers send a DecryptionApproval,
+// and once a threshold of the eon's keypers has approved the same epoch within
+// decryptionApprovalTimeout shuttermint blocks, every keyper of the eon publishes its share for
+// it, bypassing the key release policy. Only epochs we've passed without releasing the key can be
+// approved, never upcoming ones.
+
+// Path of the admin API endpoint approving the decryption of a past epoch.
+const decryptionApprovePath = "/decryptions/approve"
+
+// decryptionApprovalTimeout is the number of shuttermint blocks within which a threshold of
+// keypers has to approve the decryption of an epoch. Our own approval expires after it as well.
+const decryptionApprovalTimeout int64 = 1000
+
+// decryptionApprovalResendTimeout is the number of shuttermint blocks after which we send our
+// approval again, if it hasn't made it into the chain.
+const decryptionApprovalResendTimeout int64 = 10
+
+// DecryptionApproval holds our local state about the decryption of a past epoch.
+type DecryptionApproval struct {
+	Eon            uint64
+	Epoch          uint64
+	ApprovedHeight int64 // shuttermint height our operator has approved at, 0 if they haven't
+	SentHeight     int64 // shuttermint height we've last sent our approval at, 0 if we haven't
+	Decided        bool  // a threshold of keypers has approved and we've published our share
+}
+
+type decryptionID struct {
+	Eon   uint64
+	Epoch uint64
+}
+
+func (st *State) findDecryptionApproval(eon, epoch uint64) *DecryptionApproval {
+	for _, a := range st.DecryptionApprovals {
+		if a.Eon == eon && a.Epoch == epoch {
+			return a
+		}
+	}
+	return nil
+}
+
+func (st *State) decryptionApproval(eon, epoch uint64) *DecryptionApproval {
+	a := st.findDecryptionApproval(eon, epoch)
+	if a == nil {
+		a = &DecryptionApproval{Eon: eon, Epoch: epoch}
+		st.DecryptionApprovals = append(st.DecryptionApprovals, a)
+	}
+	return a
+}
+
+// epochPassed checks if we've passed the given epoch when publishing our epoch secret key shares,
+// i.e. if we've either published our share for it or skipped it.
+func (st *State) epochPassed(shutter *observe.Shutter, epoch uint64) bool {
+	if len(shutter.BatchConfigs) == 0 {
+		return false
+	}
+	bc := shutter.FindBatchConfigByBatchIndex(st.NextEpochSecretShare)
+	if bc.PerBlockEpochs {
+		return epoch < st.NextBlockEpochSecretShare
+	}
+	return epoch < bc.Epoch(st.NextEpochSecretShare)
+}
+
+// checkDecryptable checks if the decryption of the given epoch may be approved.
+func (st *State) checkDecryptable(shutter *observe.Shutter, eon, epoch uint64) (*EKG, error) {
+	ekg, err := st.FindEKGByEon(eon)
+	if err != nil || ekg.EpochKG == nil {
+		return nil, pkgErrors.Errorf("no key for eon %d", eon)
+	}
+	if !st.epochPassed(shutter, epoch) {
+		return nil, pkgErrors.Errorf("epoch %d has not passed yet", epoch)
+	}
+	if _, ok := ekg.EpochKG.SecretKey("", epoch); ok {
+		return nil, pkgErrors.Errorf("the key of epoch %d in eon %d has been released already", epoch, eon)
+	}
+	return ekg, nil
+}
+
+// approveDecryption records that our operator approves the decryption of the given epoch at the
+// given shuttermint height. Our approval is sent by the next decider step.
+func (st *State) approveDecryption(shutter *observe.Shutter, eon, epoch uint64) (DecryptionApproval, error) {
+	if _, err := st.checkDecryptable(shutter, eon, epoch); err != nil {
+		return DecryptionApproval{}, err
+	}
+	a := st.decryptionApproval(eon, epoch)
+	if a.ApprovedHeight == 0 && !a.Decided {
+		a.ApprovedHeight = shutter.CurrentBlock
+		a.SentHeight = 0
+	}
+	return *a, nil
+}
+
+// decryptionApproved checks if a threshold of keypers has approved the decryption of an epoch
+// within decryptionApprovalTimeout blocks. The approvals must be for the same epoch and from the
+// keypers of its eon.
+func decryptionApproved(approvals []shutterevents.DecryptionApproval, threshold uint64) bool {
+	if threshold == 0 {
+		threshold = 1
+	}
+	for _, first := range approvals {
+		approvers := make(map[common.Address]struct{})
+		for _, ev := range approvals {
+			if ev.Height >= first.Height && ev.Height <= first.Height+decryptionApprovalTimeout {
+				approvers[ev.Sender] = struct{}{}
+			}
+		}
+		if uint64(len(approvers)) >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDecryptionApprovals sends our approvals and publishes our shares for the epochs a
+// threshold of keypers has approved the decryption of.
+func (dcdr *Decider) handleDecryptionApprovals() {
+	var ids []decryptionID
+	approvals := make(map[decryptionID][]shutterevents.DecryptionApproval)
+	for _, ev := range dcdr.Shutter.DecryptionApprovals {
+		id := decryptionID{Eon: ev.Eon, Epoch: ev.Epoch}
+		if _, ok := approvals[id]; !ok {
+			ids = append(ids, id)
+		}
+		approvals[id] = append(approvals[id], ev)
+	}
+	for _, id := range ids {
+		dcdr.maybeDecrypt(id, approvals[id])
+	}
+	dcdr.sendDecryptionApprovals(approvals)
+
+	var kept []*DecryptionApproval
+	for _, a := range dcdr.State.DecryptionApprovals {
+		if _, err := dcdr.State.FindEKGByEon(a.Eon); err != nil {
+			continue // pruned
+		}
+		if a.ApprovedHeight != 0 || a.Decided {
+			kept = append(kept, a)
+		}
+	}
+	dcdr.State.DecryptionApprovals = kept
+}
+
+// maybeDecrypt publishes our share for the given epoch once a threshold of keypers has approved
+// its decryption.
+func (dcdr *Decider) maybeDecrypt(id decryptionID, approvals []shutterevents.DecryptionApproval) {
+	ekg, err := dcdr.State.FindEKGByEon(id.Eon)
+	if err != nil || ekg.EpochKG == nil {
+		return // we're not a keyper of the eon or have pruned it
+	}
+	if a := dcdr.State.findDecryptionApproval(id.Eon, id.Epoch); a != nil && a.Decided {
+		return
+	}
+	if !decryptionApproved(approvals, ekg.EpochKG.Threshold) {
+		return
+	}
+	a := dcdr.State.decryptionApproval(id.Eon, id.Epoch)
+	a.Decided = true
+	a.ApprovedHeight = 0
+	if _, err := dcdr.State.checkDecryptable(dcdr.Shutter, id.Eon, id.Epoch); err != nil {
+		log.Printf("Not publishing our share for epoch %d in eon %d approved by the keypers: %s", id.Epoch, id.Eon, err)
+		return
+	}
+	log.Printf("Keypers approved the decryption of epoch %d in eon %d, publishing our share", id.Epoch, id.Eon)
+	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(ekg.StartBatchIndex)
+	dcdr.sendEpochSecretKeyShare(ekg.EpochKG, "", id.Epoch)
+	for _, namespace := range batchConfig.EpochNamespaces {
+		dcdr.sendEpochSecretKeyShare(ekg.EpochKG, namespace, id.Epoch)
+	}
+}
+
+// sendDecryptionApprovals sends the approvals of our operator until they're in the chain or have
+// expired.
+func (dcdr *Decider) sendDecryptionApprovals(approvals map[decryptionID][]shutterevents.DecryptionApproval) {
+	height := dcdr.Shutter.CurrentBlock
+	for _, a := range dcdr.State.DecryptionApprovals {
+		if a.ApprovedHeight == 0 {
+			continue
+		}
+		if height > a.ApprovedHeight+decryptionApprovalTimeout {
+			log.Printf(
+				"Warning: the decryption of epoch %d in eon %d we've approved at height %d hasn't been "+
+					"approved by a threshold of keypers in time",
+				a.Epoch, a.Eon, a.ApprovedHeight,
+			)
+			a.ApprovedHeight = 0
+			continue
+		}
+		if dcdr.decryptionApprovalSeen(a, approvals[decryptionID{Eon: a.Eon, Epoch: a.Epoch}]) {
+			continue
+		}
+		if a.SentHeight != 0 && height < a.SentHeight+decryptionApprovalResendTimeout {
+			continue // still waiting for our approval to make it into the chain
+		}
+		a.SentHeight = height
+		dcdr.sendShuttermintMessage(
+			fmt.Sprintf("decryption approval, epoch=%d in eon=%d", a.Epoch, a.Eon),
+			shmsg.NewDecryptionApproval(a.Eon, a.Epoch))
+	}
+}
+
+// decryptionApprovalSeen checks if our approval has made it into the chain since our operator has
+// approved.
+func (dcdr *Decider) decryptionApprovalSeen(a *DecryptionApproval, approvals []shutterevents.DecryptionApproval) bool {
+	for _, ev := range approvals {
+		if ev.Sender == dcdr.Config.Address() && ev.Height >= a.ApprovedHeight {
+			return true
+		}
+	}
+	return false
+}
+
+func (kpr *Keyper) decryptionApprovalHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveDecryptionApproval)
+}
+
+// serveDecryptionApproval lets the operator approve the decryption of the past epoch given by the
+// eon and epoch query parameters. The approval is sent in the next decider step.
+func (kpr *Keyper) serveDecryptionApproval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path != decryptionApprovePath {
+		http.NotFound(w, r)
+		return
+	}
+	eon, err := strconv.ParseUint(r.URL.Query().Get("eon"), 10, 64)
+	if err != nil {
+		http.Error(w, "eon must be a number", http.StatusBadRequest)
+		return
+	}
+	epoch, err := strconv.ParseUint(r.URL.Query().Get("epoch"), 10, 64)
+	if err != nil {
+		http.Error(w, "epoch must be a number", http.StatusBadRequest)
+		return
+	}
+
+	var approval DecryptionApproval
+	var approveErr error
+	err = kpr.runInSyncLoop(r.Context(), func(context.Context) error {
+		if kpr.syncing {
+			approveErr = pkgErrors.New("the keyper is still syncing")
+			return nil
+		}
+		approval, approveErr = kpr.State.approveDecryption(kpr.CurrentWorld().Shutter, eon, epoch)
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if approveErr != nil {
+		http.Error(w, approveErr.Error(), http.StatusConflict)
+		return
+	}
+	log.Printf("Decryption of epoch %d in eon %d approved via the admin API", epoch, eon)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	httpapi.WriteJSON(w, approval)
+}
@@ -0,0 +1,143 @@
+package keyper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// decryptionMessages returns the decryption approvals and epoch secret key shares the decider has
+// sent and clears its actions.
+func decryptionMessages(dcdr *Decider) ([]*shmsg.DecryptionApproval, []*shmsg.EpochSecretKeyShare) {
+	var approvals []*shmsg.DecryptionApproval
+	var shares []*shmsg.EpochSecretKeyShare
+	for _, action := range dcdr.Actions {
+		if send, ok := action.(*fx.SendShuttermintMessage); ok {
+			if approval := send.Msg.GetDecryptionApproval(); approval != nil {
+				approvals = append(approvals, approval)
+			}
+			if share := send.Msg.GetEpochSecretKeyShare(); share != nil {
+				shares = append(shares, share)
+			}
+		}
+	}
+	dcdr.Actions = nil
+	return approvals, shares
+}
+
+func TestDecryptionApproved(t *testing.T) {
+	keypers := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	approvals := []shutterevents.DecryptionApproval{
+		{Height: 10, Sender: keypers[0]},
+		{Height: 20, Sender: keypers[0]},
+	}
+	assert.Assert(t, decryptionApproved(approvals, 1))
+	assert.Assert(t, decryptionApproved(approvals, 0))
+	assert.Assert(t, !decryptionApproved(approvals, 2))
+	assert.Assert(t, !decryptionApproved(nil, 1))
+
+	late := append(approvals, shutterevents.DecryptionApproval{Height: 11 + decryptionApprovalTimeout, Sender: keypers[1]})
+	assert.Assert(t, decryptionApproved(late, 2), "within the timeout of the second approval")
+	late[1].Height = 5
+	assert.Assert(t, !decryptionApproved(late, 2))
+}
+
+func TestDecryptionApprovalWorkflow(t *testing.T) {
+	dcdr := policyTestDecider(t, nil)
+	dcdr.State.EKGs[0].EpochKG = singleKeyperEpochKG(t, 1)
+	dcdr.State.NextEpochSecretShare = 5
+	dcdr.Shutter.CurrentBlock = 100
+
+	_, err := dcdr.State.approveDecryption(dcdr.Shutter, 1, 5)
+	assert.ErrorContains(t, err, "not passed")
+	_, err = dcdr.State.approveDecryption(dcdr.Shutter, 2, 3)
+	assert.ErrorContains(t, err, "no key")
+	approval, err := dcdr.State.approveDecryption(dcdr.Shutter, 1, 3)
+	assert.NilError(t, err)
+	assert.Equal(t, approval.ApprovedHeight, int64(100))
+
+	// we send our approval and resend it if it doesn't make it into the chain
+	dcdr.handleDecryptionApprovals()
+	approvals, shares := decryptionMessages(dcdr)
+	assert.Equal(t, len(approvals), 1)
+	assert.Equal(t, approvals[0].Epoch, uint64(3))
+	assert.Equal(t, len(shares), 0)
+	dcdr.handleDecryptionApprovals()
+	approvals, _ = decryptionMessages(dcdr)
+	assert.Equal(t, len(approvals), 0)
+	dcdr.Shutter.CurrentBlock = 110
+	dcdr.handleDecryptionApprovals()
+	approvals, _ = decryptionMessages(dcdr)
+	assert.Equal(t, len(approvals), 1)
+
+	// once the threshold has approved, we publish our share once
+	dcdr.Shutter.DecryptionApprovals = append(dcdr.Shutter.DecryptionApprovals, shutterevents.DecryptionApproval{
+		Height: 111, Sender: dcdr.Config.Address(), Eon: 1, Epoch: 3,
+	})
+	dcdr.Shutter.CurrentBlock = 112
+	dcdr.handleDecryptionApprovals()
+	approvals, shares = decryptionMessages(dcdr)
+	assert.Equal(t, len(approvals), 0)
+	assert.Equal(t, len(shares), 1)
+	assert.Equal(t, shares[0].Epoch, uint64(3))
+	assert.Assert(t, dcdr.State.findDecryptionApproval(1, 3).Decided)
+	dcdr.handleDecryptionApprovals()
+	_, shares = decryptionMessages(dcdr)
+	assert.Equal(t, len(shares), 0)
+
+	// approvals expire if the threshold isn't reached in time
+	_, err = dcdr.State.approveDecryption(dcdr.Shutter, 1, 4)
+	assert.NilError(t, err)
+	dcdr.Shutter.CurrentBlock = 113 + decryptionApprovalTimeout
+	dcdr.handleDecryptionApprovals()
+	approvals, _ = decryptionMessages(dcdr)
+	assert.Equal(t, len(approvals), 0)
+	assert.Assert(t, dcdr.State.findDecryptionApproval(1, 4) == nil)
+	assert.Equal(t, len(dcdr.State.DecryptionApprovals), 1)
+}
+
+func TestDecryptionApprovalHandler(t *testing.T) {
+	k := NewKeyper(Config{})
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 100
+	shutter.BatchConfigs = []shutterevents.BatchConfig{{}}
+	k.world.Store(observe.World{Shutter: shutter, MainChain: observe.NewMainChain(0)})
+	k.State.EKGs = []*EKG{{Eon: 1, EpochKG: singleKeyperEpochKG(t, 1)}}
+	k.State.NextEpochSecretShare = 5
+	k.syncing = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = k.syncLoop(ctx)
+	}()
+
+	handler := k.decryptionApprovalHandler()
+	post := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	assert.Equal(t, post(http.MethodGet, decryptionApprovePath+"?eon=1&epoch=3").Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, post(http.MethodPost, decryptionApprovePath+"?eon=1").Code, http.StatusBadRequest)
+	assert.Equal(t, post(http.MethodPost, decryptionApprovePath+"?eon=1&epoch=7").Code, http.StatusConflict)
+	assert.Equal(t, post(http.MethodPost, decryptionApprovePath+"?eon=1&epoch=3").Code, http.StatusAccepted)
+
+	var approvals []DecryptionApproval
+	assert.NilError(t, k.runInSyncLoop(ctx, func(context.Context) error {
+		for _, a := range k.State.DecryptionApprovals {
+			approvals = append(approvals, *a)
+		}
+		return nil
+	}))
+	assert.DeepEqual(t, approvals, []DecryptionApproval{{Eon: 1, Epoch: 3, ApprovedHeight: 100}})
+}
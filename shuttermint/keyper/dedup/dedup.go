@@ -0,0 +1,135 @@
+// Package dedup detects duplicate and replayed ciphertexts, i.e. copies of an earlier ciphertext
+// in the same cipher batch or of a ciphertext in one of the preceding batches. A copy can't be
+// told apart from the original, so without detection a spammer can fill batches with copies of
+// the same encrypted payload, e.g. of a victim's transaction.
+//
+// The keypers sign the decrypted batches, so they must agree on the ciphertexts left out. Find
+// therefore only depends on the batches, which all keypers observe on the main chain. Window is
+// meant for services accepting ciphertexts before they are submitted to the batcher contract,
+// e.g. the sequencer hub.
+package dedup
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// Policy determines how duplicate and replayed ciphertexts are handled.
+type Policy string
+
+const (
+	// Allow treats copies like any other ciphertext.
+	Allow Policy = "allow"
+	// Drop leaves all but the first copy out.
+	Drop Policy = "drop"
+	// Reject refuses submissions containing copies. It is only supported by services accepting
+	// submissions, batches on the main chain can only be filtered.
+	Reject Policy = "reject"
+)
+
+// ParsePolicy parses a policy. The empty string is parsed as Allow.
+func ParsePolicy(s string) (Policy, error) {
+	switch Policy(s) {
+	case "", Allow:
+		return Allow, nil
+	case Drop, Reject:
+		return Policy(s), nil
+	default:
+		return "", errors.Errorf("invalid duplicate ciphertext policy %q, must be %q, %q or %q", s, Allow, Drop, Reject)
+	}
+}
+
+// Hash identifies a ciphertext. Envelopes are identified by the encrypted message they carry, so
+// that wrapping a copy in a new envelope, e.g. with another sender hint, doesn't hide it.
+func Hash(ciphertext []byte) common.Hash {
+	if m, err := shcrypto.EnvelopeMessageBytes(ciphertext); err == nil {
+		ciphertext = m
+	}
+	return crypto.Keccak256Hash(ciphertext)
+}
+
+// Find returns the indices of the ciphertexts of a batch that copy an earlier ciphertext of the
+// batch or a ciphertext of one of the previous batches.
+func Find(ciphertexts [][]byte, previous ...[][]byte) []int {
+	seen := make(map[common.Hash]bool)
+	for _, batch := range previous {
+		for _, c := range batch {
+			seen[Hash(c)] = true
+		}
+	}
+	var copies []int
+	for i, c := range ciphertexts {
+		h := Hash(c)
+		if seen[h] {
+			copies = append(copies, i)
+			continue
+		}
+		seen[h] = true
+	}
+	return copies
+}
+
+// Remove returns the ciphertexts without the ones at the given indices, which must be sorted.
+func Remove(ciphertexts [][]byte, indices []int) [][]byte {
+	res := make([][]byte, 0, len(ciphertexts)-len(indices))
+	for i, c := range ciphertexts {
+		if len(indices) > 0 && indices[0] == i {
+			indices = indices[1:]
+			continue
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// Window remembers the ciphertexts accepted for recent batches. It must not be used concurrently.
+type Window struct {
+	size    uint64
+	latest  uint64
+	batches map[uint64]map[common.Hash]bool
+}
+
+// NewWindow creates a window checking for copies from the given number of previous batches.
+func NewWindow(size uint64) *Window {
+	return &Window{size: size, batches: make(map[uint64]map[common.Hash]bool)}
+}
+
+// Contains checks if the ciphertext has been added to the given batch or to one of the previous
+// batches within the window.
+func (w *Window) Contains(batchIndex uint64, ciphertext []byte) bool {
+	h := Hash(ciphertext)
+	from := uint64(0)
+	if batchIndex > w.size {
+		from = batchIndex - w.size
+	}
+	for b := from; b <= batchIndex; b++ {
+		if w.batches[b][h] {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds the ciphertext to the given batch. Batches that have fallen out of the window of the
+// latest batch are forgotten.
+func (w *Window) Add(batchIndex uint64, ciphertext []byte) {
+	hashes, ok := w.batches[batchIndex]
+	if !ok {
+		hashes = make(map[common.Hash]bool)
+		w.batches[batchIndex] = hashes
+	}
+	hashes[Hash(ciphertext)] = true
+
+	if batchIndex <= w.latest {
+		return
+	}
+	w.latest = batchIndex
+	for b := range w.batches {
+		if b+w.size < w.latest {
+			delete(w.batches, b)
+		}
+	}
+}
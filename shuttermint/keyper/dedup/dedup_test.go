@@ -0,0 +1,55 @@
+package dedup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+func TestParsePolicy(t *testing.T) {
+	for s, expected := range map[string]Policy{"": Allow, "allow": Allow, "drop": Drop, "reject": Reject} {
+		policy, err := ParsePolicy(s)
+		assert.NilError(t, err)
+		assert.Equal(t, policy, expected)
+	}
+	_, err := ParsePolicy("ignore")
+	assert.ErrorContains(t, err, "invalid duplicate ciphertext policy")
+}
+
+func TestFind(t *testing.T) {
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	assert.Assert(t, Find([][]byte{a, b, c}) == nil)
+	assert.DeepEqual(t, Find([][]byte{a, b, a, a}), []int{2, 3})
+	assert.DeepEqual(t, Find([][]byte{a, b, c}, [][]byte{c}, nil, [][]byte{a}), []int{0, 2})
+
+	assert.DeepEqual(t, Remove([][]byte{a, b, a, a, c}, []int{2, 3}), [][]byte{a, b, c})
+	assert.DeepEqual(t, Remove([][]byte{a}, nil), [][]byte{a})
+}
+
+func TestFindEnvelopes(t *testing.T) {
+	envelope := func(hint string, message string) []byte {
+		d := append([]byte{shcrypto.EnvelopeMarker, shcrypto.EnvelopeVersion}, make([]byte, 8+shcrypto.G1Size)...)
+		d = append(d, byte(len(hint)))
+		d = append(d, hint...)
+		return append(d, message...)
+	}
+	a := []byte("a")
+	ciphertexts := [][]byte{envelope("alice", "a"), envelope("bob", "a"), a, envelope("bob", "b")}
+	assert.DeepEqual(t, Find(ciphertexts), []int{1, 2})
+}
+
+func TestWindow(t *testing.T) {
+	w := NewWindow(2)
+	w.Add(10, []byte("a"))
+	assert.Assert(t, w.Contains(10, []byte("a")))
+	assert.Assert(t, w.Contains(12, []byte("a")))
+	assert.Assert(t, !w.Contains(13, []byte("a")))
+	assert.Assert(t, !w.Contains(9, []byte("a")), "later batches are not checked")
+	assert.Assert(t, !w.Contains(10, []byte("b")))
+
+	w.Add(13, []byte("b"))
+	assert.Equal(t, len(w.batches), 1, "batch 10 has fallen out of the window")
+	assert.Assert(t, !w.Contains(12, []byte("a")))
+}
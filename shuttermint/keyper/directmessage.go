@@ -0,0 +1,77 @@
+package keyper
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// Direct messages are messages from one keyper to another, sent through shuttermint and encrypted
+// to the receiver's encryption key. They give sub-protocols a way to exchange data between keypers
+// without a message format of their own: a sub-protocol registers a handler for its topic and
+// sends its messages with sendDirectMessage. Handlers are called in the decision step the message
+// shows up in, with the payload already decrypted. Each topic is a feature, so messages are only
+// sent to keypers that have announced a handler for it, see capabilities.go.
+
+// DirectMessageHandler handles the decrypted payload of a direct message from sender.
+type DirectMessageHandler func(dcdr *Decider, sender common.Address, payload []byte)
+
+var directMessageHandlers = make(map[string]DirectMessageHandler)
+
+// registerDirectMessageHandler registers the handler for direct messages with the given topic. It
+// is meant to be called from init functions.
+func registerDirectMessageHandler(topic string, handler DirectMessageHandler) {
+	if _, ok := directMessageHandlers[topic]; ok {
+		panic("duplicate direct message handler for topic " + topic)
+	}
+	directMessageHandlers[topic] = handler
+	registerFeature(directMessageFeature(topic))
+}
+
+// sendDirectMessage sends the payload to the given keyper. It fails if the keyper hasn't checked
+// in with their encryption key yet or hasn't announced support for the topic.
+func (dcdr *Decider) sendDirectMessage(receiver common.Address, topic string, payload []byte) error {
+	if !dcdr.supports(receiver, directMessageFeature(topic)) {
+		return errors.Errorf("keyper %s doesn't support direct messages with topic %s", receiver.Hex(), topic)
+	}
+	encryptionKey, ok := dcdr.Shutter.KeyperEncryptionKeys[receiver]
+	if !ok {
+		return errors.Errorf("encryption key of keyper %s unknown", receiver.Hex())
+	}
+	encrypted, err := encryptionKey.Encrypt(rand.Reader, payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt direct message")
+	}
+	dcdr.sendShuttermintMessage(
+		fmt.Sprintf("direct message %s to %s", topic, receiver.Hex()),
+		shmsg.NewDirectMessage(receiver, topic, encrypted),
+	)
+	return nil
+}
+
+// handleDirectMessages passes the direct messages to us received since the last step to the
+// handlers of their topics.
+func (dcdr *Decider) handleDirectMessages() {
+	us := dcdr.Config.Address()
+	for _, msg := range dcdr.Shutter.GetDirectMessages(dcdr.State.SyncHeight) {
+		if msg.Receiver != us {
+			continue
+		}
+		handler, ok := directMessageHandlers[msg.Topic]
+		if !ok {
+			log.Printf("Ignoring direct message from %s with unknown topic %q", msg.Sender.Hex(), msg.Topic)
+			continue
+		}
+		payload, err := dcdr.Config.EncryptionKey.Decrypt(msg.EncryptedPayload, []byte(""), []byte(""))
+		if err != nil {
+			log.Printf("Error: failed to decrypt direct message from %s with topic %q: %+v", msg.Sender.Hex(), msg.Topic, err)
+			continue
+		}
+		handler(dcdr, msg.Sender, payload)
+	}
+}
@@ -0,0 +1,75 @@
+package keyper
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestDirectMessages(t *testing.T) {
+	newConfig := func() Config {
+		signingKey, err := crypto.GenerateKey()
+		assert.NilError(t, err)
+		encryptionKey, err := ecies.GenerateKey(rand.Reader, crypto.S256(), nil)
+		assert.NilError(t, err)
+		return Config{SigningKey: signingKey, EncryptionKey: encryptionKey}
+	}
+	senderConfig := newConfig()
+	receiverConfig := newConfig()
+
+	type received struct {
+		Sender  common.Address
+		Payload string
+	}
+	var got []received
+	registerDirectMessageHandler("test", func(dcdr *Decider, sender common.Address, payload []byte) {
+		got = append(got, received{sender, string(payload)})
+	})
+	defer func() {
+		delete(directMessageHandlers, "test")
+		delete(features, directMessageFeature("test"))
+	}()
+
+	shutter := observe.NewShutter()
+	publicKey := receiverConfig.EncryptionKey.PublicKey
+	shutter.KeyperEncryptionKeys[receiverConfig.Address()] = (*observe.EncryptionPublicKey)(&publicKey)
+	sender := Decider{Config: senderConfig, State: NewState(), Shutter: shutter}
+	assert.ErrorContains(t, sender.sendDirectMessage(receiverConfig.Address(), "test", []byte("hello")), "doesn't support")
+	shutter.Capabilities[receiverConfig.Address()] = shutterevents.Capabilities{
+		Sender:   receiverConfig.Address(),
+		Features: []string{directMessageFeature("test")},
+	}
+	assert.NilError(t, sender.sendDirectMessage(receiverConfig.Address(), "test", []byte("hello")))
+	assert.Assert(t, sender.sendDirectMessage(senderConfig.Address(), "test", []byte("hello")) != nil)
+	assert.Equal(t, len(sender.Actions), 1)
+	msg := sender.Actions[0].(*fx.SendShuttermintMessage).Msg.GetDirectMessage()
+	assert.Assert(t, msg != nil)
+
+	for _, topic := range []string{"test", "unknown"} {
+		shutter.DirectMessages = append(shutter.DirectMessages, shutterevents.DirectMessage{
+			Height:           10,
+			Sender:           senderConfig.Address(),
+			Receiver:         common.BytesToAddress(msg.Receiver),
+			Topic:            topic,
+			EncryptedPayload: msg.EncryptedPayload,
+		})
+	}
+	st := NewState()
+	st.SyncHeight = 10
+	receiver := Decider{Config: receiverConfig, State: st, Shutter: shutter}
+	receiver.handleDirectMessages()
+	assert.DeepEqual(t, got, []received{{senderConfig.Address(), "hello"}})
+
+	// messages from before the sync height have been handled already
+	st.SyncHeight = 11
+	receiver.handleDirectMessages()
+	assert.Equal(t, len(got), 1)
+}
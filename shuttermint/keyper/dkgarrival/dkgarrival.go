@@ -0,0 +1,173 @@
+// Package dkgarrival records when the DKG messages of the keypers arrive relative to the start of
+// the phase they belong to, so that phase lengths can be tuned based on real data. Arrivals are
+// measured in shuttermint blocks, using the heights of the messages in the chain, so all keypers
+// compute the same histograms for an eon.
+package dkgarrival
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// Kind is the kind of a DKG message.
+type Kind string
+
+const (
+	Commitment Kind = "commitment"
+	PolyEval   Kind = "polyEval"
+	Accusation Kind = "accusation"
+	Apology    Kind = "apology"
+)
+
+// Kinds are all kinds of DKG messages, in the order of the phases they belong to.
+var Kinds = []Kind{Commitment, PolyEval, Accusation, Apology}
+
+// Span is the range of shuttermint heights of a DKG phase, from Start to End exclusive.
+type Span struct {
+	Start int64
+	End   int64
+}
+
+// Phases are the spans of the phases of a DKG.
+type Phases struct {
+	Dealing     Span
+	Accusing    Span
+	Apologizing Span
+}
+
+func (p Phases) span(kind Kind) Span {
+	switch kind {
+	case Commitment, PolyEval:
+		return p.Dealing
+	case Accusation:
+		return p.Accusing
+	default:
+		return p.Apologizing
+	}
+}
+
+// Histogram counts the messages of a kind by the number of blocks between the start of their
+// phase and their arrival.
+type Histogram struct {
+	PhaseStart  int64    `json:"phaseStart"`
+	PhaseLength int64    `json:"phaseLength"`
+	Buckets     []uint64 `json:"buckets"` // Buckets[i] counts the messages arriving i blocks after the phase started
+	Early       uint64   `json:"early"`   // messages arriving before the phase started
+	Late        uint64   `json:"late"`    // messages arriving after the phase ended
+	// Keypers holds the arrival offsets of the messages of each keyper, in blocks after the start
+	// of the phase
+	Keypers map[common.Address][]int64 `json:"keypers"`
+}
+
+func newHistogram(span Span) *Histogram {
+	length := span.End - span.Start
+	if length < 0 {
+		length = 0
+	}
+	return &Histogram{
+		PhaseStart:  span.Start,
+		PhaseLength: length,
+		Buckets:     make([]uint64, length),
+		Keypers:     make(map[common.Address][]int64),
+	}
+}
+
+func (h *Histogram) add(sender common.Address, height int64) {
+	offset := height - h.PhaseStart
+	switch {
+	case offset < 0:
+		h.Early++
+	case offset >= h.PhaseLength:
+		h.Late++
+	default:
+		h.Buckets[offset]++
+	}
+	h.Keypers[sender] = append(h.Keypers[sender], offset)
+}
+
+// Count returns the number of messages.
+func (h *Histogram) Count() uint64 {
+	n := h.Early + h.Late
+	for _, c := range h.Buckets {
+		n += c
+	}
+	return n
+}
+
+// Percentile returns the offset at or before which the given percentage of the messages arrived
+// in the phase, and false if there were none.
+func (h *Histogram) Percentile(p int) (int64, bool) {
+	var inPhase uint64
+	for _, c := range h.Buckets {
+		inPhase += c
+	}
+	if inPhase == 0 {
+		return 0, false
+	}
+	rank := (inPhase*uint64(p) + 99) / 100 // nearest rank
+	if rank == 0 {
+		rank = 1
+	}
+	var n uint64
+	for offset, c := range h.Buckets {
+		n += c
+		if n >= rank {
+			return int64(offset), true
+		}
+	}
+	return h.PhaseLength - 1, true
+}
+
+// Report holds the arrival histograms of the DKG messages of an eon.
+type Report struct {
+	Eon      uint64              `json:"eon"`
+	Messages map[Kind]*Histogram `json:"messages"`
+}
+
+// Compute computes the arrival histograms of the messages of the given eon.
+func Compute(eon observe.Eon, phases Phases) *Report {
+	r := &Report{Eon: eon.Eon, Messages: make(map[Kind]*Histogram)}
+	for _, kind := range Kinds {
+		r.Messages[kind] = newHistogram(phases.span(kind))
+	}
+	for _, m := range eon.Commitments {
+		r.Messages[Commitment].add(m.Sender, m.Height)
+	}
+	for _, m := range eon.PolyEvals {
+		r.Messages[PolyEval].add(m.Sender, m.Height)
+	}
+	for _, m := range eon.Accusations {
+		r.Messages[Accusation].add(m.Sender, m.Height)
+	}
+	for _, m := range eon.Apologies {
+		r.Messages[Apology].add(m.Sender, m.Height)
+	}
+	return r
+}
+
+func (r *Report) String() string {
+	var parts []string
+	for _, kind := range Kinds {
+		h, ok := r.Messages[kind]
+		if !ok || h.Count() == 0 {
+			continue
+		}
+		s := fmt.Sprintf("%s: n=%d", kind, h.Count())
+		if p50, ok := h.Percentile(50); ok {
+			p90, _ := h.Percentile(90)
+			s += fmt.Sprintf(" p50=%d p90=%d", p50, p90)
+		}
+		if h.Late > 0 {
+			s += fmt.Sprintf(" late=%d", h.Late)
+		}
+		parts = append(parts, s)
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("eon %d: no DKG messages", r.Eon)
+	}
+	return fmt.Sprintf("eon %d: %s (blocks after phase start)", r.Eon, strings.Join(parts, ", "))
+}
@@ -0,0 +1,66 @@
+package dkgarrival
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestCompute(t *testing.T) {
+	a, b := common.BigToAddress(common.Big1), common.BigToAddress(common.Big2)
+	eon := observe.Eon{
+		Eon: 3,
+		Commitments: []shutterevents.PolyCommitment{
+			{Height: 100, Sender: a},
+			{Height: 104, Sender: b},
+		},
+		PolyEvals: []shutterevents.PolyEval{
+			{Height: 101, Sender: a},
+			{Height: 101, Sender: b},
+			{Height: 110, Sender: b},
+		},
+		Apologies: []shutterevents.Apology{{Height: 119, Sender: a}},
+	}
+	phases := Phases{
+		Dealing:     Span{Start: 100, End: 110},
+		Accusing:    Span{Start: 110, End: 120},
+		Apologizing: Span{Start: 120, End: 130},
+	}
+	r := Compute(eon, phases)
+	assert.Equal(t, r.Eon, uint64(3))
+
+	commitments := r.Messages[Commitment]
+	assert.Equal(t, commitments.PhaseLength, int64(10))
+	assert.DeepEqual(t, commitments.Buckets, []uint64{1, 0, 0, 0, 1, 0, 0, 0, 0, 0})
+	assert.DeepEqual(t, commitments.Keypers, map[common.Address][]int64{a: {0}, b: {4}})
+
+	evals := r.Messages[PolyEval]
+	assert.Equal(t, evals.Count(), uint64(3))
+	assert.Equal(t, evals.Late, uint64(1))
+	assert.DeepEqual(t, evals.Keypers[b], []int64{1, 10})
+	p50, ok := evals.Percentile(50)
+	assert.Assert(t, ok)
+	assert.Equal(t, p50, int64(1))
+
+	apologies := r.Messages[Apology]
+	assert.Equal(t, apologies.Early, uint64(1))
+	_, ok = apologies.Percentile(50)
+	assert.Assert(t, !ok, "no apology arrived in the apologizing phase")
+	assert.Equal(t, r.Messages[Accusation].Count(), uint64(0))
+
+	assert.Equal(t, r.String(),
+		"eon 3: commitment: n=2 p50=0 p90=4, polyEval: n=3 p50=1 p90=1 late=1, apology: n=1 (blocks after phase start)")
+}
+
+func TestPercentile(t *testing.T) {
+	h := &Histogram{PhaseLength: 4, Buckets: []uint64{0, 5, 4, 1}}
+	for p, expected := range map[int]int64{0: 1, 50: 1, 60: 2, 90: 2, 99: 3, 100: 3} {
+		offset, ok := h.Percentile(p)
+		assert.Assert(t, ok)
+		assert.Equal(t, offset, expected, "p%d", p)
+	}
+}
@@ -0,0 +1,49 @@
+package keyper
+
+import (
+	"net/http"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/dkgarrival"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+)
+
+// arrivalPhases returns the shuttermint heights of the phases of a DKG started at the given
+// height.
+func (plen *PhaseLength) arrivalPhases(eonStartHeight int64) dkgarrival.Phases {
+	return dkgarrival.Phases{
+		Dealing:     dkgarrival.Span{Start: eonStartHeight + plen.Off, End: eonStartHeight + plen.Dealing},
+		Accusing:    dkgarrival.Span{Start: eonStartHeight + plen.Dealing, End: eonStartHeight + plen.Accusing},
+		Apologizing: dkgarrival.Span{Start: eonStartHeight + plen.Accusing, End: eonStartHeight + plen.Apologizing},
+	}
+}
+
+// updateDKGArrivals publishes the DKG message arrival histograms of the eons we have generated
+// keys for, so that they can be served while the decider keeps updating the state. The reports
+// aren't modified after they've been computed.
+func (kpr *Keyper) updateDKGArrivals() {
+	var reports []*dkgarrival.Report
+	for _, s := range kpr.State.EonSummaries {
+		if s.Arrivals != nil {
+			reports = append(reports, s.Arrivals)
+		}
+	}
+	for _, ekg := range kpr.State.EKGs {
+		if ekg.Arrivals != nil {
+			reports = append(reports, ekg.Arrivals)
+		}
+	}
+	kpr.dkgArrivals.Store(reports)
+}
+
+func (kpr *Keyper) dkgArrivalsHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveDKGArrivals)
+}
+
+// serveDKGArrivals serves the DKG message arrival histograms by eon as JSON.
+func (kpr *Keyper) serveDKGArrivals(w http.ResponseWriter, _ *http.Request) {
+	reports, _ := kpr.dkgArrivals.Load().([]*dkgarrival.Report)
+	if reports == nil {
+		reports = []*dkgarrival.Report{}
+	}
+	httpapi.WriteJSON(w, reports)
+}
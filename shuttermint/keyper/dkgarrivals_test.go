@@ -0,0 +1,44 @@
+package keyper
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/dkgarrival"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestArrivalPhases(t *testing.T) {
+	plen := NewConstantPhaseLength(10)
+	assert.DeepEqual(t, plen.arrivalPhases(100), dkgarrival.Phases{
+		Dealing:     dkgarrival.Span{Start: 100, End: 110},
+		Accusing:    dkgarrival.Span{Start: 110, End: 120},
+		Apologizing: dkgarrival.Span{Start: 120, End: 130},
+	})
+}
+
+func TestServeDKGArrivals(t *testing.T) {
+	k := NewKeyper(Config{})
+	serve := func() []dkgarrival.Report {
+		rec := httptest.NewRecorder()
+		k.dkgArrivalsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/dkg/arrivals", nil))
+		var res []dkgarrival.Report
+		assert.NilError(t, json.NewDecoder(rec.Body).Decode(&res))
+		return res
+	}
+	assert.DeepEqual(t, serve(), []dkgarrival.Report{})
+
+	plen := NewConstantPhaseLength(10)
+	k.State.EKGs = []*EKG{
+		{Eon: 1},
+		{Eon: 2, Arrivals: dkgarrival.Compute(observe.Eon{Eon: 2}, plen.arrivalPhases(100))},
+	}
+	k.updateDKGArrivals()
+	res := serve()
+	assert.Equal(t, len(res), 1)
+	assert.Equal(t, res[0].Eon, uint64(2))
+	assert.Equal(t, res[0].Messages[dkgarrival.Accusation].PhaseStart, int64(110))
+}
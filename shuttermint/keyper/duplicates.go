@@ -0,0 +1,32 @@
+package keyper
+
+import (
+	"log"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/dedup"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// dropDuplicateCiphertexts returns the batch without the ciphertexts copying an earlier one of the
+// batch or one of the CiphertextReplayWindow preceding batches, if DuplicateCiphertexts is "drop".
+// Otherwise, the batch is returned as is. The result only depends on the batches observed on the
+// main chain, so that all keypers agree on it.
+func (dcdr *Decider) dropDuplicateCiphertexts(batch *observe.Batch) *observe.Batch {
+	if dedup.Policy(dcdr.Config.DuplicateCiphertexts) != dedup.Drop || len(batch.EncryptedTransactions) == 0 {
+		return batch
+	}
+	var previous [][][]byte
+	for i := uint64(1); i <= dcdr.Config.CiphertextReplayWindow && i <= batch.BatchIndex; i++ {
+		if b, ok := dcdr.MainChain.Batches[batch.BatchIndex-i]; ok {
+			previous = append(previous, b.EncryptedTransactions)
+		}
+	}
+	copies := dedup.Find(batch.EncryptedTransactions, previous...)
+	if len(copies) == 0 {
+		return batch
+	}
+	log.Printf("Dropping %d duplicate or replayed ciphertexts from batch %d", len(copies), batch.BatchIndex)
+	filtered := *batch
+	filtered.EncryptedTransactions = dedup.Remove(batch.EncryptedTransactions, copies)
+	return &filtered
+}
@@ -0,0 +1,30 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestDropDuplicateCiphertexts(t *testing.T) {
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	mainChain := observe.NewMainChain(0)
+	mainChain.Batches[3] = &observe.Batch{BatchIndex: 3, EncryptedTransactions: [][]byte{a}}
+	mainChain.Batches[4] = &observe.Batch{BatchIndex: 4, EncryptedTransactions: [][]byte{b}}
+	batch := &observe.Batch{BatchIndex: 5, EncryptedTransactions: [][]byte{a, b, c, c}}
+	dcdr := &Decider{MainChain: mainChain}
+
+	assert.Equal(t, dcdr.dropDuplicateCiphertexts(batch), batch, "copies are allowed by default")
+
+	dcdr.Config.DuplicateCiphertexts = "drop"
+	dcdr.Config.CiphertextReplayWindow = 1
+	filtered := dcdr.dropDuplicateCiphertexts(batch)
+	assert.DeepEqual(t, filtered.EncryptedTransactions, [][]byte{a, c})
+	assert.Equal(t, len(batch.EncryptedTransactions), 4, "the observed batch must not be modified")
+
+	dcdr.Config.CiphertextReplayWindow = 2
+	filtered = dcdr.dropDuplicateCiphertexts(batch)
+	assert.DeepEqual(t, filtered.EncryptedTransactions, [][]byte{c})
+}
@@ -0,0 +1,82 @@
+package keyper
+
+import (
+	"log"
+	"math"
+
+	pkgErrors "github.com/pkg/errors"
+)
+
+// EonActivation is the range of batches whose epoch secret keys are generated with the key of an
+// eon. An eon is active from its start batch index until a later eon starts. An eon restarted at
+// or before the start batch index of an earlier one, e.g. after a failed DKG, replaces the earlier
+// one completely, so that the ranges never overlap. The ranges don't depend on whether the DKGs
+// succeed: batches of an eon without key don't fall back to the key of an earlier eon.
+type EonActivation struct {
+	Eon             uint64
+	StartBatchIndex uint64
+	EndBatchIndex   uint64 // exclusive, math.MaxUint64 until a later eon starts
+}
+
+// Contains checks if the given batch belongs to the eon.
+func (a EonActivation) Contains(batchIndex uint64) bool {
+	return a.StartBatchIndex <= batchIndex && batchIndex < a.EndBatchIndex
+}
+
+// activateEon records that the given eon starts at the given batch index, ending the ranges of
+// the earlier eons there. Eons must be activated in order.
+func (st *State) activateEon(eon uint64, startBatchIndex uint64) {
+	for i := range st.EonActivations {
+		a := &st.EonActivations[i]
+		if a.EndBatchIndex <= startBatchIndex {
+			continue
+		}
+		a.EndBatchIndex = startBatchIndex
+		if a.EndBatchIndex < a.StartBatchIndex {
+			a.EndBatchIndex = a.StartBatchIndex
+		}
+	}
+	st.EonActivations = append(st.EonActivations, EonActivation{
+		Eon:             eon,
+		StartBatchIndex: startBatchIndex,
+		EndBatchIndex:   math.MaxUint64,
+	})
+}
+
+// ActiveEon returns the eon the given batch belongs to. It returns false if no eon has started
+// at or before the batch.
+func (st *State) ActiveEon(batchIndex uint64) (uint64, bool) {
+	for _, a := range st.EonActivations {
+		if a.Contains(batchIndex) {
+			return a.Eon, true
+		}
+	}
+	return 0, false
+}
+
+// FindEKGByBatchIndex returns the EKG of the eon the given batch belongs to.
+func (st *State) FindEKGByBatchIndex(batchIndex uint64) (*EKG, error) {
+	eon, ok := st.ActiveEon(batchIndex)
+	if !ok {
+		return nil, pkgErrors.Errorf("no eon active at batch %d", batchIndex)
+	}
+	return st.FindEKGByEon(eon)
+}
+
+// updateEonActivations activates the eons that have started on shuttermint since the last step.
+func (dcdr *Decider) updateEonActivations() {
+	var last uint64
+	if n := len(dcdr.State.EonActivations); n > 0 {
+		last = dcdr.State.EonActivations[n-1].Eon
+	}
+	for _, eon := range dcdr.Shutter.Eons {
+		if len(dcdr.State.EonActivations) > 0 && eon.Eon <= last {
+			continue
+		}
+		start := eon.StartEvent.BatchIndex
+		if previous, ok := dcdr.State.ActiveEon(start); ok {
+			log.Printf("Eon %d takes over from eon %d at batch %d", eon.Eon, previous, start)
+		}
+		dcdr.State.activateEon(eon.Eon, start)
+	}
+}
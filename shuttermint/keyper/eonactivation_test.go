@@ -0,0 +1,157 @@
+package keyper
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestEonActivations(t *testing.T) {
+	st := NewState()
+	active := func(batchIndex uint64) uint64 {
+		t.Helper()
+		eon, ok := st.ActiveEon(batchIndex)
+		assert.Assert(t, ok, "no eon active at batch %d", batchIndex)
+		return eon
+	}
+
+	st.activateEon(1, 10)
+	_, ok := st.ActiveEon(9)
+	assert.Assert(t, !ok)
+	assert.Equal(t, active(10), uint64(1))
+	assert.Equal(t, active(math.MaxUint64-1), uint64(1))
+
+	st.activateEon(2, 100)
+	assert.Equal(t, active(99), uint64(1))
+	assert.Equal(t, active(100), uint64(2))
+
+	// a restart at the same batch replaces the failed eon
+	st.activateEon(3, 100)
+	assert.Equal(t, active(99), uint64(1))
+	assert.Equal(t, active(100), uint64(3))
+
+	// so does a restart at an earlier batch
+	st.activateEon(4, 50)
+	assert.Equal(t, active(49), uint64(1))
+	assert.Equal(t, active(50), uint64(4))
+	assert.Equal(t, active(100), uint64(4))
+
+	st.activateEon(5, 200)
+	assert.DeepEqual(t, st.EonActivations, []EonActivation{
+		{Eon: 1, StartBatchIndex: 10, EndBatchIndex: 50},
+		{Eon: 2, StartBatchIndex: 100, EndBatchIndex: 100},
+		{Eon: 3, StartBatchIndex: 100, EndBatchIndex: 100},
+		{Eon: 4, StartBatchIndex: 50, EndBatchIndex: 200},
+		{Eon: 5, StartBatchIndex: 200, EndBatchIndex: math.MaxUint64},
+	})
+}
+
+// eonTransitionDecider returns a decider of a single keyper, with eon 1 starting at batch 0 and
+// eon 2 at batch 3, while batches up to 4 have been closed.
+func eonTransitionDecider(t *testing.T) *Decider {
+	t.Helper()
+	dcdr := policyTestDecider(t, nil)
+	keypers := dcdr.Shutter.BatchConfigs[0].Keypers
+	dcdr.Shutter.Eons = append(dcdr.Shutter.Eons, observe.Eon{
+		Eon:        2,
+		StartEvent: shutterevents.EonStarted{Eon: 2, BatchIndex: 3},
+	})
+	dcdr.State.EKGs = []*EKG{
+		{Eon: 1, Keypers: keypers, EpochKG: singleKeyperEpochKG(t, 1)},
+		{Eon: 2, Keypers: keypers, EpochKG: singleKeyperEpochKG(t, 2), StartBatchIndex: 3},
+	}
+	dcdr.updateEonActivations()
+	return dcdr
+}
+
+func TestEonTransitionPublishesSharesOfBatchEon(t *testing.T) {
+	dcdr := eonTransitionDecider(t)
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(5))
+
+	var eons []uint64
+	for _, action := range dcdr.Actions {
+		msg := action.(*fx.SendShuttermintMessage).Msg.GetEpochSecretKeyShare()
+		assert.Assert(t, msg != nil)
+		eons = append(eons, msg.Eon)
+	}
+	assert.DeepEqual(t, eons, []uint64{1, 1, 1, 2, 2})
+}
+
+func TestEonTransitionDecryptsWithBatchEon(t *testing.T) {
+	dcdr := eonTransitionDecider(t)
+	ekg := dcdr.State.EKGs[1]
+	eon := &dcdr.Shutter.Eons[1]
+	// shares of eon 2 for the last batch of eon 1 and the first batch of eon 2
+	for _, epoch := range []uint64{2, 3} {
+		eon.EpochSecretKeyShares = append(eon.EpochSecretKeyShares, shutterevents.EpochSecretKeyShare{
+			Height: 1,
+			Sender: dcdr.Config.Address(),
+			Eon:    2,
+			Epoch:  epoch,
+			Share:  ekg.EpochKG.ComputeEpochSecretKeyShare(epoch),
+		})
+	}
+	dcdr.syncEKGWithEon(0, ekg, eon)
+
+	_, ok := dcdr.State.Batches[2]
+	assert.Assert(t, !ok, "batch of eon 1 decrypted with the key of eon 2")
+	_, ok = dcdr.State.Batches[3]
+	assert.Assert(t, ok)
+}
+
+func TestUpdateEonActivations(t *testing.T) {
+	dcdr := eonTransitionDecider(t)
+	assert.Equal(t, len(dcdr.State.EonActivations), 2)
+
+	// activations are only added for new eons
+	dcdr.updateEonActivations()
+	assert.Equal(t, len(dcdr.State.EonActivations), 2)
+
+	dcdr.Shutter.Eons = append(dcdr.Shutter.Eons, observe.Eon{
+		Eon:        3,
+		StartEvent: shutterevents.EonStarted{Eon: 3, BatchIndex: 3},
+	})
+	dcdr.updateEonActivations()
+	eon, ok := dcdr.State.ActiveEon(3)
+	assert.Assert(t, ok)
+	assert.Equal(t, eon, uint64(3))
+	_, err := dcdr.State.FindEKGByBatchIndex(3)
+	assert.ErrorContains(t, err, "EKG not found")
+	ekg, err := dcdr.State.FindEKGByBatchIndex(2)
+	assert.NilError(t, err)
+	assert.Equal(t, ekg.Eon, uint64(1))
+}
+
+func TestEpochSecretKeySharesNotSentTwice(t *testing.T) {
+	dcdr := eonTransitionDecider(t)
+	self := dcdr.Config.Address()
+	ekg := dcdr.State.EKGs[0]
+	// our share for epoch 1 is in the chain, but the key hasn't been generated yet
+	dcdr.Shutter.Eons[0].EpochSecretKeyShares = append(dcdr.Shutter.Eons[0].EpochSecretKeyShares,
+		shutterevents.EpochSecretKeyShare{
+			Height: 1,
+			Sender: self,
+			Eon:    1,
+			Epoch:  1,
+			Share:  ekg.EpochKG.ComputeEpochSecretKeyShare(1),
+		})
+	// and the one for epoch 3 has been sent before a restart
+	dcdr.PendingMessages = []*shmsg.Message{
+		shmsg.NewEpochSecretKeyShare(2, 3, dcdr.State.EKGs[1].EpochKG.ComputeEpochSecretKeyShare(3)),
+	}
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(5))
+
+	var epochs []uint64
+	for _, action := range dcdr.Actions {
+		epochs = append(epochs, action.(*fx.SendShuttermintMessage).Msg.GetEpochSecretKeyShare().Epoch)
+	}
+	assert.DeepEqual(t, epochs, []uint64{0, 2, 4})
+}
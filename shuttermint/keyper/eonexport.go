@@ -0,0 +1,287 @@
+package keyper
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// eonExportVersion is the version of the EonExport format. Importing refuses exports of other
+// versions.
+const eonExportVersion = 1
+
+// EonExport is the result of an eon's DKG in a format that doesn't depend on the layout of the
+// state file, so that a live eon key share can be moved between keyper versions with
+// incompatible state files. Points are encoded like in the state file, the eon secret key share
+// is encrypted to the keyper's encryption key. The secret shares and keys of the epochs are not
+// exported, the keyper recomputes them as needed.
+type EonExport struct {
+	Version                 int
+	Eon                     uint64
+	Keyper                  common.Address
+	KeyperIndex             uint64
+	Keypers                 []common.Address
+	Threshold               uint64
+	PublicKey               hexutil.Bytes
+	PublicKeyShares         []hexutil.Bytes
+	EncryptedSecretKeyShare hexutil.Bytes
+
+	// Information about the DKG process served to light clients, see EKG
+	StartBatchIndex   uint64
+	DKGStartHeight    int64
+	DKGEndHeight      int64
+	Participants      hexutil.Bytes // participant bitmap of the transcript, nil without transcript
+	Commitment        hexutil.Bytes // aggregated commitment of the transcript, nil without transcript
+	CommitmentHeights []int64
+
+	// Checksum is the Keccak256 hash of the JSON encoding of the export with a zero checksum. It
+	// protects the parts of the export that can't be checked cryptographically.
+	Checksum common.Hash
+}
+
+// NewEonExport exports the given EKG of the given keyper. The eon secret key share is encrypted
+// to encryptionKey.
+func NewEonExport(ekg *EKG, keyper common.Address, encryptionKey *ecies.PublicKey) (*EonExport, error) {
+	ekgs := ekg.EpochKG
+	if ekgs == nil || ekgs.SecretKeyShare == nil {
+		return nil, errors.Errorf("no eon secret key share for eon %d", ekg.Eon)
+	}
+	secret := common.BigToHash((*big.Int)(ekgs.SecretKeyShare))
+	encrypted, err := ecies.Encrypt(rand.Reader, encryptionKey, secret.Bytes(), nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt eon secret key share")
+	}
+	x := &EonExport{
+		Version:                 eonExportVersion,
+		Eon:                     ekg.Eon,
+		Keyper:                  keyper,
+		KeyperIndex:             ekgs.Keyper,
+		Keypers:                 ekg.Keypers,
+		Threshold:               ekgs.Threshold,
+		PublicKey:               (*bn256.G2)(ekgs.PublicKey).Marshal(),
+		EncryptedSecretKeyShare: encrypted,
+		StartBatchIndex:         ekg.StartBatchIndex,
+		DKGStartHeight:          ekg.DKGStartHeight,
+		DKGEndHeight:            ekg.DKGEndHeight,
+		CommitmentHeights:       ekg.CommitmentHeights,
+	}
+	for _, share := range ekgs.PublicKeyShares {
+		x.PublicKeyShares = append(x.PublicKeyShares, (*bn256.G2)(share).Marshal())
+	}
+	if ekg.Transcript != nil {
+		x.Participants = ekg.Transcript.Participants
+		x.Commitment, err = ekg.Transcript.Commitment.GobEncode()
+		if err != nil {
+			return nil, err
+		}
+	}
+	x.Checksum, err = x.checksum()
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *EonExport) checksum() (common.Hash, error) {
+	c := *x
+	c.Checksum = common.Hash{}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// EKG checks the integrity of the export and restores the EKG. keyper and encryptionKey must be
+// the address and the encryption key of the keyper the export has been made for. Besides the
+// checksum, it checks that the secret key share matches the keyper's public key share and, if the
+// export contains the DKG transcript, that the public key and all public key shares match the
+// transcript's commitment.
+func (x *EonExport) EKG(keyper common.Address, encryptionKey *ecies.PrivateKey) (*EKG, error) {
+	if x.Version != eonExportVersion {
+		return nil, errors.Errorf("unsupported eon export version %d, expected %d", x.Version, eonExportVersion)
+	}
+	checksum, err := x.checksum()
+	if err != nil {
+		return nil, err
+	}
+	if checksum != x.Checksum {
+		return nil, errors.Errorf("checksum mismatch, the export is corrupted")
+	}
+	if x.Keyper != keyper {
+		return nil, errors.Errorf("export has been made for keyper %s, not for %s", x.Keyper.Hex(), keyper.Hex())
+	}
+	numKeypers := uint64(len(x.Keypers))
+	if x.KeyperIndex >= numKeypers || x.Keypers[x.KeyperIndex] != keyper {
+		return nil, errors.Errorf("keyper %s is not keyper %d of eon %d", keyper.Hex(), x.KeyperIndex, x.Eon)
+	}
+	if x.Threshold == 0 || x.Threshold > numKeypers {
+		return nil, errors.Errorf("invalid threshold %d for %d keypers", x.Threshold, numKeypers)
+	}
+	if uint64(len(x.PublicKeyShares)) != numKeypers {
+		return nil, errors.Errorf("got %d public key shares for %d keypers", len(x.PublicKeyShares), numKeypers)
+	}
+	if x.CommitmentHeights != nil && uint64(len(x.CommitmentHeights)) != numKeypers {
+		return nil, errors.Errorf("got %d commitment heights for %d keypers", len(x.CommitmentHeights), numKeypers)
+	}
+
+	publicKey := new(shcrypto.EonPublicKey)
+	if err := publicKey.GobDecode(x.PublicKey); err != nil {
+		return nil, errors.Wrap(err, "invalid eon public key")
+	}
+	publicKeyShares := make([]*shcrypto.EonPublicKeyShare, numKeypers)
+	for i, data := range x.PublicKeyShares {
+		publicKeyShares[i] = new(shcrypto.EonPublicKeyShare)
+		if err := publicKeyShares[i].GobDecode(data); err != nil {
+			return nil, errors.Wrapf(err, "invalid eon public key share of keyper %d", i)
+		}
+	}
+
+	secret, err := encryptionKey.Decrypt(x.EncryptedSecretKeyShare, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt eon secret key share, was it exported with another encryption key?")
+	}
+	secretInt := new(big.Int).SetBytes(secret)
+	if len(secret) != common.HashLength || secretInt.Cmp(bn256.Order) >= 0 {
+		return nil, errors.Errorf("invalid eon secret key share")
+	}
+	secretKeyShare := (*shcrypto.EonSecretKeyShare)(secretInt)
+	if !shcrypto.VerifyEonSecretKeyShare(secretKeyShare, publicKeyShares[x.KeyperIndex]) {
+		return nil, errors.Errorf("eon secret key share does not match our public key share")
+	}
+
+	var transcript *puredkg.Transcript
+	if len(x.Commitment) > 0 {
+		commitment := new(shcrypto.Gammas)
+		if err := commitment.GobDecode(x.Commitment); err != nil {
+			return nil, errors.Wrap(err, "invalid commitment")
+		}
+		transcript = &puredkg.Transcript{
+			Eon:          x.Eon,
+			NumKeypers:   numKeypers,
+			Threshold:    x.Threshold,
+			Participants: x.Participants,
+			Commitment:   commitment,
+			PublicKey:    publicKey,
+		}
+		if err := transcript.Verify(); err != nil {
+			return nil, errors.Wrap(err, "invalid transcript")
+		}
+		for i, share := range publicKeyShares {
+			if !share.Equal(transcript.PublicKeyShare(uint64(i))) {
+				return nil, errors.Errorf("public key share of keyper %d does not match the transcript", i)
+			}
+		}
+	}
+
+	result := &puredkg.Result{
+		Eon:             x.Eon,
+		NumKeypers:      numKeypers,
+		Threshold:       x.Threshold,
+		Keyper:          x.KeyperIndex,
+		SecretKeyShare:  secretKeyShare,
+		PublicKey:       publicKey,
+		PublicKeyShares: publicKeyShares,
+	}
+	return &EKG{
+		Eon:               x.Eon,
+		Keypers:           x.Keypers,
+		EpochKG:           epochkg.NewEpochKG(result),
+		StartBatchIndex:   x.StartBatchIndex,
+		DKGStartHeight:    x.DKGStartHeight,
+		DKGEndHeight:      x.DKGEndHeight,
+		Transcript:        transcript,
+		CommitmentHeights: x.CommitmentHeights,
+	}, nil
+}
+
+// ExportEon exports the EKG of the given eon from the keyper's state file in DBDir. The export
+// is checked to be importable with the config before it's returned.
+func ExportEon(config Config, eon uint64) (*EonExport, error) {
+	st, err := NewGobStateStore(config.DBDir).Load()
+	if err != nil {
+		return nil, err
+	}
+	ekg, err := st.State.FindEKGByEon(eon)
+	if err != nil {
+		return nil, errors.Wrapf(err, "no key generated for eon %d", eon)
+	}
+	x, err := NewEonExport(ekg, config.Address(), &config.EncryptionKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := x.EKG(config.Address(), config.EncryptionKey); err != nil {
+		return nil, errors.WithMessage(err, "export failed verification")
+	}
+	return x, nil
+}
+
+// ImportEon adds the EKG of an export to the keyper's state file in DBDir, or creates a new state
+// file containing only the EKG if there is none yet. It returns false if the state already
+// contains the same key. Importing never replaces a different key of the eon, and it refuses to
+// import an eon whose DKG the keyper is still running. The keyper must not be running while
+// its state is modified.
+func ImportEon(config Config, x *EonExport) (bool, error) {
+	ekg, err := x.EKG(config.Address(), config.EncryptionKey)
+	if err != nil {
+		return false, err
+	}
+
+	store := NewGobStateStore(config.DBDir)
+	st, err := store.Load()
+	if err == ErrNoStoredState {
+		log.Printf("No state at %s, creating a new one", store.Path())
+		st = StoredState{
+			State:     NewState(),
+			Shutter:   observe.NewShutter(),
+			MainChain: observe.NewMainChain(config.MainChainFollowDistance),
+		}
+	} else if err != nil {
+		return false, err
+	}
+
+	if existing, err := st.State.FindEKGByEon(x.Eon); err == nil {
+		if existing.EpochKG != nil && existing.EpochKG.SecretKeyShare != nil &&
+			existing.EpochKG.SecretKeyShare.Equal(ekg.EpochKG.SecretKeyShare) &&
+			existing.EpochKG.PublicKey.Equal(ekg.EpochKG.PublicKey) {
+			return false, nil
+		}
+		return false, errors.Errorf("state already contains a different key for eon %d", x.Eon)
+	}
+	for _, dkg := range st.State.DKGs {
+		if dkg.Eon == x.Eon && !dkg.IsFinalized() {
+			return false, errors.Errorf("keyper is still running the DKG for eon %d", x.Eon)
+		}
+	}
+
+	st.State.EKGs = append(st.State.EKGs, ekg)
+	sort.SliceStable(st.State.EKGs, func(i, j int) bool {
+		return st.State.EKGs[i].Eon < st.State.EKGs[j].Eon
+	})
+	if x.Eon > st.State.LastEonStarted {
+		// don't start a DKG for the eon when it's seen again
+		st.State.LastEonStarted = x.Eon
+	}
+	if err := os.MkdirAll(config.DBDir, 0o700); err != nil {
+		return false, err
+	}
+	if err := store.Save(st); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,137 @@
+package keyper
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+)
+
+// singleKeyperEKG runs a DKG with a single keyper and returns its EKG including the transcript.
+func singleKeyperEKG(t *testing.T, eon uint64, keyper common.Address) *EKG {
+	t.Helper()
+	pure := puredkg.NewPureDKG(eon, 1, 1, 0)
+	commitment, _, err := pure.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.NilError(t, pure.HandlePolyCommitmentMsg(commitment))
+	pure.StartPhase2Accusing()
+	pure.StartPhase3Apologizing()
+	pure.Finalize()
+	result, err := pure.ComputeResult()
+	assert.NilError(t, err)
+	transcript, err := pure.Transcript()
+	assert.NilError(t, err)
+	return &EKG{
+		Eon:               eon,
+		Keypers:           []common.Address{keyper},
+		EpochKG:           epochkg.NewEpochKG(&result),
+		StartBatchIndex:   10,
+		DKGStartHeight:    100,
+		DKGEndHeight:      130,
+		Transcript:        transcript,
+		CommitmentHeights: []int64{105},
+	}
+}
+
+func eonExportTestConfig(t *testing.T) Config {
+	t.Helper()
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	encryptionKey, err := ecies.GenerateKey(rand.Reader, crypto.S256(), nil)
+	assert.NilError(t, err)
+	return Config{DBDir: t.TempDir(), SigningKey: signingKey, EncryptionKey: encryptionKey}
+}
+
+func TestEonExport(t *testing.T) {
+	config := eonExportTestConfig(t)
+	ekg := singleKeyperEKG(t, 3, config.Address())
+	x, err := NewEonExport(ekg, config.Address(), &config.EncryptionKey.PublicKey)
+	assert.NilError(t, err)
+
+	data, err := json.Marshal(x)
+	assert.NilError(t, err)
+	decoded := &EonExport{}
+	assert.NilError(t, json.Unmarshal(data, decoded))
+	imported, err := decoded.EKG(config.Address(), config.EncryptionKey)
+	assert.NilError(t, err)
+	assert.Assert(t, imported.EpochKG.SecretKeyShare.Equal(ekg.EpochKG.SecretKeyShare))
+	assert.Assert(t, imported.EpochKG.PublicKey.Equal(ekg.EpochKG.PublicKey))
+	assert.Assert(t, imported.EpochKG.PublicKeyShares[0].Equal(ekg.EpochKG.PublicKeyShares[0]))
+	assert.Assert(t, imported.Transcript.Commitment.Equal(*ekg.Transcript.Commitment))
+	assert.Equal(t, imported.StartBatchIndex, ekg.StartBatchIndex)
+	assert.DeepEqual(t, imported.CommitmentHeights, ekg.CommitmentHeights)
+
+	other := eonExportTestConfig(t)
+	_, err = x.EKG(other.Address(), config.EncryptionKey)
+	assert.ErrorContains(t, err, "export has been made for keyper")
+	_, err = x.EKG(config.Address(), other.EncryptionKey)
+	assert.ErrorContains(t, err, "failed to decrypt")
+
+	tampered := *x
+	tampered.StartBatchIndex++
+	_, err = tampered.EKG(config.Address(), config.EncryptionKey)
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	// a share encrypted correctly, but not matching the public key share is detected even if
+	// the checksum is fixed up
+	otherEKG := singleKeyperEKG(t, 3, config.Address())
+	otherExport, err := NewEonExport(otherEKG, config.Address(), &config.EncryptionKey.PublicKey)
+	assert.NilError(t, err)
+	tampered = *x
+	tampered.EncryptedSecretKeyShare = otherExport.EncryptedSecretKeyShare
+	tampered.Checksum, err = tampered.checksum()
+	assert.NilError(t, err)
+	_, err = tampered.EKG(config.Address(), config.EncryptionKey)
+	assert.ErrorContains(t, err, "does not match")
+}
+
+func TestImportEon(t *testing.T) {
+	config := eonExportTestConfig(t)
+	ekg := singleKeyperEKG(t, 3, config.Address())
+	x, err := NewEonExport(ekg, config.Address(), &config.EncryptionKey.PublicKey)
+	assert.NilError(t, err)
+
+	// importing into an empty database directory creates a new state
+	imported, err := ImportEon(config, x)
+	assert.NilError(t, err)
+	assert.Assert(t, imported)
+	st, err := NewGobStateStore(config.DBDir).Load()
+	assert.NilError(t, err)
+	assert.Equal(t, len(st.State.EKGs), 1)
+	assert.Equal(t, st.State.LastEonStarted, uint64(3))
+
+	imported, err = ImportEon(config, x)
+	assert.NilError(t, err)
+	assert.Assert(t, !imported)
+
+	exported, err := ExportEon(config, 3)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, exported.PublicKeyShares, x.PublicKeyShares)
+	_, err = ExportEon(config, 4)
+	assert.ErrorContains(t, err, "no key generated for eon 4")
+
+	// an earlier eon is sorted in, a different key for an existing eon is refused
+	earlier, err := NewEonExport(singleKeyperEKG(t, 2, config.Address()), config.Address(), &config.EncryptionKey.PublicKey)
+	assert.NilError(t, err)
+	imported, err = ImportEon(config, earlier)
+	assert.NilError(t, err)
+	assert.Assert(t, imported)
+	different, err := NewEonExport(singleKeyperEKG(t, 3, config.Address()), config.Address(), &config.EncryptionKey.PublicKey)
+	assert.NilError(t, err)
+	_, err = ImportEon(config, different)
+	assert.ErrorContains(t, err, "different key for eon 3")
+
+	st, err = NewGobStateStore(config.DBDir).Load()
+	assert.NilError(t, err)
+	assert.Equal(t, len(st.State.EKGs), 2)
+	assert.Equal(t, st.State.EKGs[0].Eon, uint64(2))
+	assert.Equal(t, st.State.LastEonStarted, uint64(3))
+	assert.Assert(t, st.State.EKGs[1].EpochKG.SecretKeyShare.Equal(ekg.EpochKG.SecretKeyShare))
+}
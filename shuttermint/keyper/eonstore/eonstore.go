@@ -0,0 +1,326 @@
+// Package eonstore implements a disk-backed observe.EonArchive. It stores the events of
+// historical eons in a LevelDB database, so that long-running nodes only need to keep the active
+// eons in memory.
+package eonstore
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/pkg/errors"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	tmdb "github.com/tendermint/tm-db"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// EventKind is the kind of an archived event.
+type EventKind byte
+
+const (
+	PolyCommitments EventKind = iota + 1
+	PolyEvals
+	Accusations
+	Apologies
+	EpochSecretKeyShares
+)
+
+var (
+	counterKey   = []byte("m/counter")
+	headerPrefix = []byte("h/")
+	eventPrefix  = []byte("e/")
+)
+
+// Store is an observe.EonArchive backed by a tm-db database.
+type Store struct {
+	mux     sync.Mutex
+	db      tmdb.DB
+	counter uint64 // sequence number of the last event written
+}
+
+// Open opens or creates the store in the given directory.
+func Open(dir string) (*Store, error) {
+	db, err := tmdb.NewGoLevelDB("eons", dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open eon store in %s", dir)
+	}
+	return New(db)
+}
+
+// New creates a store using the given database.
+func New(db tmdb.DB) (*Store, error) {
+	s := &Store{db: db}
+	v, err := db.Get(counterKey)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil {
+		s.counter = binary.BigEndian.Uint64(v)
+	}
+	return s, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func uint64Bytes(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, n)
+	return b
+}
+
+func concat(parts ...[]byte) []byte {
+	var res []byte
+	for _, p := range parts {
+		res = append(res, p...)
+	}
+	return res
+}
+
+func headerKey(eon uint64) []byte {
+	return concat(headerPrefix, uint64Bytes(eon))
+}
+
+func eonEventPrefix(eon uint64) []byte {
+	return concat(eventPrefix, uint64Bytes(eon))
+}
+
+func kindPrefix(eon uint64, kind EventKind) []byte {
+	return concat(eonEventPrefix(eon), []byte{byte(kind)})
+}
+
+// prefixEnd returns the smallest key larger than all keys with the given prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := concat(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// encodeEvent encodes an event as tendermint event, i.e. the same way it's been received from
+// shuttermint, prefixed by the height.
+func encodeEvent(height int64, ev shutterevents.IEvent) ([]byte, error) {
+	abciEvent := ev.MakeABCIEvent()
+	data, err := abciEvent.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return concat(uint64Bytes(uint64(height)), data), nil
+}
+
+func decodeEvent(value []byte) (shutterevents.IEvent, error) {
+	if len(value) < 8 {
+		return nil, errors.Errorf("archived event too short")
+	}
+	height := int64(binary.BigEndian.Uint64(value))
+	var abciEvent abcitypes.Event
+	if err := abciEvent.Unmarshal(value[8:]); err != nil {
+		return nil, err
+	}
+	return shutterevents.MakeEvent(abciEvent, height)
+}
+
+// ArchiveEon stores the events of the given eon. It implements observe.EonArchive.
+func (s *Store) ArchiveEon(eon *observe.Eon) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	if !eon.Archived {
+		if err := s.deletePrefix(batch, eonEventPrefix(eon.Eon)); err != nil {
+			return err
+		}
+	}
+	header, err := encodeEvent(eon.StartHeight, eon.StartEvent)
+	if err != nil {
+		return err
+	}
+	if err := batch.Set(headerKey(eon.Eon), header); err != nil {
+		return err
+	}
+
+	counter := s.counter
+	add := func(kind EventKind, height int64, ev shutterevents.IEvent) error {
+		counter++
+		value, err := encodeEvent(height, ev)
+		if err != nil {
+			return err
+		}
+		return batch.Set(concat(kindPrefix(eon.Eon, kind), uint64Bytes(counter)), value)
+	}
+	for _, ev := range eon.Commitments {
+		if err := add(PolyCommitments, ev.Height, ev); err != nil {
+			return err
+		}
+	}
+	for _, ev := range eon.PolyEvals {
+		if err := add(PolyEvals, ev.Height, ev); err != nil {
+			return err
+		}
+	}
+	for _, ev := range eon.Accusations {
+		if err := add(Accusations, ev.Height, ev); err != nil {
+			return err
+		}
+	}
+	for _, ev := range eon.Apologies {
+		if err := add(Apologies, ev.Height, ev); err != nil {
+			return err
+		}
+	}
+	for _, ev := range eon.EpochSecretKeyShares {
+		if err := add(EpochSecretKeyShares, ev.Height, ev); err != nil {
+			return err
+		}
+	}
+	if err := batch.Set(counterKey, uint64Bytes(counter)); err != nil {
+		return err
+	}
+	if err := batch.WriteSync(); err != nil {
+		return err
+	}
+	s.counter = counter
+	return nil
+}
+
+func (s *Store) deletePrefix(batch tmdb.Batch, prefix []byte) error {
+	it, err := s.db.Iterator(prefix, prefixEnd(prefix))
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+// Eons returns the numbers of all archived eons in ascending order.
+func (s *Store) Eons() ([]uint64, error) {
+	it, err := s.db.Iterator(headerPrefix, prefixEnd(headerPrefix))
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var eons []uint64
+	for ; it.Valid(); it.Next() {
+		eons = append(eons, binary.BigEndian.Uint64(it.Key()[len(headerPrefix):]))
+	}
+	return eons, it.Error()
+}
+
+// Events returns an iterator over the archived events of the given kind in the given eon, in
+// the order they have been received.
+func (s *Store) Events(eon uint64, kind EventKind) (*Iterator, error) {
+	prefix := kindPrefix(eon, kind)
+	it, err := s.db.Iterator(prefix, prefixEnd(prefix))
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{it: it, first: true}, nil
+}
+
+// LoadEon reads all archived events of the given eon into memory.
+func (s *Store) LoadEon(eonNumber uint64) (*observe.Eon, error) {
+	value, err := s.db.Get(headerKey(eonNumber))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.Errorf("eon %d not archived", eonNumber)
+	}
+	ev, err := decodeEvent(value)
+	if err != nil {
+		return nil, err
+	}
+	start, ok := ev.(*shutterevents.EonStarted)
+	if !ok {
+		return nil, errors.Errorf("unexpected header for eon %d: %T", eonNumber, ev)
+	}
+	eon := &observe.Eon{Eon: eonNumber, StartHeight: start.Height, StartEvent: *start}
+
+	for _, kind := range []EventKind{PolyCommitments, PolyEvals, Accusations, Apologies, EpochSecretKeyShares} {
+		it, err := s.Events(eonNumber, kind)
+		if err != nil {
+			return nil, err
+		}
+		for it.Next() {
+			switch e := it.Event().(type) {
+			case *shutterevents.PolyCommitment:
+				eon.Commitments = append(eon.Commitments, *e)
+			case *shutterevents.PolyEval:
+				eon.PolyEvals = append(eon.PolyEvals, *e)
+			case *shutterevents.Accusation:
+				eon.Accusations = append(eon.Accusations, *e)
+			case *shutterevents.Apology:
+				eon.Apologies = append(eon.Apologies, *e)
+			case *shutterevents.EpochSecretKeyShare:
+				eon.EpochSecretKeyShares = append(eon.EpochSecretKeyShares, *e)
+			}
+		}
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return eon, nil
+}
+
+// Iterator iterates over archived events. Call Next before reading the first event.
+type Iterator struct {
+	it    tmdb.Iterator
+	first bool
+	event shutterevents.IEvent
+	err   error
+}
+
+// Next advances the iterator to the next event. It returns false if there are no more events or
+// an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.first {
+		it.first = false
+	} else {
+		it.it.Next()
+	}
+	if !it.it.Valid() {
+		it.err = it.it.Error()
+		return false
+	}
+	it.event, it.err = decodeEvent(it.it.Value())
+	return it.err == nil
+}
+
+// Event returns the current event.
+func (it *Iterator) Event() shutterevents.IEvent {
+	return it.event
+}
+
+// Size returns the size of the current event as archived.
+func (it *Iterator) Size() int {
+	return len(it.it.Value())
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close releases the iterator.
+func (it *Iterator) Close() error {
+	return it.it.Close()
+}
@@ -0,0 +1,259 @@
+package eonstore
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	tmdb "github.com/tendermint/tm-db"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shlib/shtest"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+var keypers = []common.Address{
+	common.BigToAddress(big.NewInt(1)),
+	common.BigToAddress(big.NewInt(2)),
+}
+
+func makeEon(t *testing.T, n uint64, height int64) observe.Eon {
+	t.Helper()
+	polynomial, err := shcrypto.RandomPolynomial(rand.Reader, 1)
+	assert.NilError(t, err)
+	return observe.Eon{
+		Eon:         n,
+		StartHeight: height,
+		StartEvent:  shutterevents.EonStarted{Height: height, Eon: n, BatchIndex: 10 * n, Seed: []byte{1}},
+		Commitments: []shutterevents.PolyCommitment{
+			{Height: height + 1, Eon: n, Sender: keypers[0], Gammas: polynomial.Gammas()},
+		},
+		Accusations: []shutterevents.Accusation{
+			{Height: height + 2, Eon: n, Sender: keypers[1], Accused: keypers[:1]},
+		},
+		Apologies: []shutterevents.Apology{
+			{Height: height + 3, Eon: n, Sender: keypers[0], Accusers: keypers[1:], PolyEval: []*big.Int{big.NewInt(5)}},
+		},
+	}
+}
+
+func newMemStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := New(tmdb.NewMemDB())
+	assert.NilError(t, err)
+	return s
+}
+
+func TestArchiveAndLoad(t *testing.T) {
+	s := newMemStore(t)
+	eon := makeEon(t, 3, 100)
+	assert.NilError(t, s.ArchiveEon(&eon))
+
+	loaded, err := s.LoadEon(3)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, &eon, loaded, shtest.BigIntComparer, shcrypto.G2Comparer)
+
+	eons, err := s.Eons()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, eons, []uint64{3})
+
+	_, err = s.LoadEon(4)
+	assert.ErrorContains(t, err, "not archived")
+}
+
+func TestArchiveAppend(t *testing.T) {
+	s := newMemStore(t)
+	eon := makeEon(t, 1, 100)
+	assert.NilError(t, s.ArchiveEon(&eon))
+
+	// archiving again replaces the events, unless the eon has already been archived
+	assert.NilError(t, s.ArchiveEon(&eon))
+	late := observe.Eon{
+		Eon:        1,
+		StartEvent: eon.StartEvent,
+		Archived:   true,
+		Accusations: []shutterevents.Accusation{
+			{Height: 200, Eon: 1, Sender: keypers[0], Accused: keypers[1:]},
+		},
+	}
+	assert.NilError(t, s.ArchiveEon(&late))
+
+	it, err := s.Events(1, Accusations)
+	assert.NilError(t, err)
+	defer it.Close()
+	var heights []int64
+	for it.Next() {
+		heights = append(heights, it.Event().(*shutterevents.Accusation).Height)
+	}
+	assert.NilError(t, it.Err())
+	assert.DeepEqual(t, heights, []int64{102, 200})
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	assert.NilError(t, err)
+	eon := makeEon(t, 1, 100)
+	assert.NilError(t, s.ArchiveEon(&eon))
+	counter := s.counter
+	assert.NilError(t, s.Close())
+
+	s, err = Open(dir)
+	assert.NilError(t, err)
+	defer s.Close()
+	assert.Equal(t, s.counter, counter)
+	loaded, err := s.LoadEon(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Commitments), 1)
+}
+
+func TestShutterArchiveEons(t *testing.T) {
+	s := newMemStore(t)
+	shutter := observe.NewShutter()
+	shutter.Eons = []observe.Eon{makeEon(t, 1, 100), makeEon(t, 2, 200), makeEon(t, 3, 300)}
+
+	archived, err := shutter.ArchiveEons(s, 1)
+	assert.NilError(t, err)
+	assert.Assert(t, archived.Eons[0].Archived && archived.Eons[1].Archived)
+	assert.Equal(t, len(archived.Eons[0].Commitments), 0)
+	assert.Equal(t, archived.Eons[1].StartEvent.BatchIndex, uint64(20))
+	assert.Assert(t, !archived.Eons[2].Archived)
+	assert.Equal(t, len(archived.Eons[2].Commitments), 1)
+	// the original object is not modified
+	assert.Equal(t, len(shutter.Eons[0].Commitments), 1)
+
+	eons, err := s.Eons()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, eons, []uint64{1, 2})
+	loaded, err := s.LoadEon(2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Apologies), 1)
+}
+
+func TestShutterArchiveNewEvents(t *testing.T) {
+	s := newMemStore(t)
+	shutter := observe.NewShutter()
+	shutter.Eons = []observe.Eon{makeEon(t, 1, 100), makeEon(t, 2, 200)}
+
+	archived, err := shutter.ArchiveNewEvents(s, 0, 1)
+	assert.NilError(t, err)
+	assert.Assert(t, archived.Eons[0].Archived)
+	assert.Equal(t, len(archived.Eons[0].Commitments), 0)
+	assert.Assert(t, !archived.Eons[1].Archived)
+	assert.Equal(t, len(archived.Eons[1].Commitments), 1)
+
+	// the next snapshot only holds events after the filter's sync height, they're appended
+	next := observe.NewShutter()
+	eon2 := makeEon(t, 2, 200)
+	eon2.Commitments = nil
+	eon2.Accusations = []shutterevents.Accusation{{Height: 250, Eon: 2, Sender: keypers[0], Accused: keypers[1:]}}
+	eon2.Apologies = nil
+	next.Eons = []observe.Eon{archived.Eons[0], eon2, makeEon(t, 3, 260)}
+	_, err = next.ArchiveNewEvents(s, 203, 1)
+	assert.NilError(t, err)
+
+	loaded, err := s.LoadEon(2)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Commitments), 1)
+	assert.Equal(t, len(loaded.Accusations), 2)
+	loaded, err = s.LoadEon(3)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Apologies), 1)
+	loaded, err = s.LoadEon(1)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Apologies), 1)
+}
+
+func TestStats(t *testing.T) {
+	s := newMemStore(t)
+	eon := makeEon(t, 1, 100)
+	share := (*shcrypto.EpochSecretKeyShare)(new(bn256.G1).ScalarBaseMult(big.NewInt(1111)))
+	eon.EpochSecretKeyShares = []shutterevents.EpochSecretKeyShare{
+		{Height: 150, Sender: keypers[0], Eon: 1, Epoch: 4, Share: share},
+		{Height: 151, Sender: keypers[1], Eon: 1, Epoch: 3, Share: share},
+		{Height: 152, Sender: keypers[1], Eon: 1, Epoch: 3, Share: share, Namespace: "other"},
+		{Height: 153, Sender: keypers[0], Eon: 1, Epoch: 3, Share: share},
+		{Height: 154, Sender: keypers[0], Eon: 1, Epoch: 3, Share: share},
+	}
+	assert.NilError(t, s.ArchiveEon(&eon))
+
+	stats, epochs, err := s.Stats(1)
+	assert.NilError(t, err)
+	assert.Equal(t, stats.StartBatchIndex, uint64(10))
+	assert.Equal(t, stats.StartHeight, int64(100))
+	assert.Equal(t, stats.EndHeight, int64(103))
+	assert.Equal(t, stats.Dealers, 1)
+	assert.Equal(t, stats.Accusations, 1)
+	assert.Equal(t, stats.Accused, 1)
+	assert.Equal(t, stats.Apologies, 1)
+	assert.Equal(t, stats.Epochs, 2)
+	assert.Assert(t, stats.DKGBytes > 0 && stats.KeyShareBytes > 0)
+
+	assert.Equal(t, len(epochs), 3)
+	assert.DeepEqual(t, []uint64{epochs[0].Epoch, epochs[1].Epoch, epochs[2].Epoch}, []uint64{3, 3, 4})
+	assert.Equal(t, epochs[0].Namespace, "")
+	assert.Equal(t, epochs[0].Shares, 3)
+	assert.Equal(t, epochs[0].Senders, 2)
+	assert.Equal(t, epochs[0].FirstHeight, int64(151))
+	assert.Equal(t, epochs[0].LastHeight, int64(154))
+	assert.Equal(t, epochs[1].Namespace, "other")
+	assert.Equal(t, epochs[0].Bytes+epochs[1].Bytes+epochs[2].Bytes, stats.KeyShareBytes)
+
+	_, _, err = s.Stats(2)
+	assert.ErrorContains(t, err, "not archived")
+}
+
+func TestApplyRetention(t *testing.T) {
+	s := newMemStore(t)
+	for n := uint64(1); n <= 4; n++ {
+		eon := makeEon(t, n, int64(100*n))
+		assert.NilError(t, s.ArchiveEon(&eon))
+	}
+	before, _, err := s.Stats(2)
+	assert.NilError(t, err)
+
+	summarized, deleted, err := s.ApplyRetention(retention.Policy{Full: 2, Keep: 3})
+	assert.NilError(t, err)
+	assert.Equal(t, summarized, 1)
+	assert.Equal(t, deleted, 1)
+	eons, err := s.Eons()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, eons, []uint64{2, 3, 4})
+
+	loaded, err := s.LoadEon(2)
+	assert.NilError(t, err)
+	assert.Equal(t, loaded.StartEvent.BatchIndex, uint64(20))
+	assert.Equal(t, len(loaded.Commitments), 0)
+	after, _, err := s.Stats(2)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, after, before)
+	loaded, err = s.LoadEon(3)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Commitments), 1)
+
+	// summarized eons are not summarized again
+	summarized, deleted, err = s.ApplyRetention(retention.Policy{Full: 2, Keep: 3})
+	assert.NilError(t, err)
+	assert.Equal(t, summarized+deleted, 0)
+}
+
+func TestShutterDropEons(t *testing.T) {
+	s := newMemStore(t)
+	shutter := observe.NewShutter()
+	shutter.Eons = []observe.Eon{makeEon(t, 1, 100), makeEon(t, 2, 200), makeEon(t, 3, 300)}
+	policy := retention.Policy{Full: 1, Keep: 2}
+
+	// eons are only dropped once their events have been archived
+	assert.Equal(t, shutter.DropEons(policy), shutter)
+	archived, err := shutter.ArchiveEons(s, 1)
+	assert.NilError(t, err)
+	dropped := archived.DropEons(policy)
+	assert.Equal(t, len(dropped.Eons), 2)
+	assert.Equal(t, dropped.Eons[0].Eon, uint64(2))
+	assert.Equal(t, len(archived.Eons), 3)
+}
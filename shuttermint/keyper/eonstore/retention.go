@@ -0,0 +1,113 @@
+package eonstore
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+)
+
+var summaryPrefix = []byte("s/")
+
+func summaryKey(eon uint64) []byte {
+	return concat(summaryPrefix, uint64Bytes(eon))
+}
+
+// summary is what's kept about a summarized eon.
+type summary struct {
+	Stats  EonStats
+	Epochs []EpochStats
+}
+
+// loadSummary returns the summary of the given eon, or nil if it hasn't been summarized.
+func (s *Store) loadSummary(eon uint64) (*summary, error) {
+	value, err := s.db.Get(summaryKey(eon))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	sum := &summary{}
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(sum); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode summary of eon %d", eon)
+	}
+	return sum, nil
+}
+
+// Summarize replaces the events of the given eon by its statistics, see Stats. The start event
+// is kept, so the eon is still listed by Eons, but LoadEon returns it without events.
+func (s *Store) Summarize(eon uint64) error {
+	if sum, err := s.loadSummary(eon); err != nil || sum != nil {
+		return err
+	}
+	stats, epochs, err := s.Stats(eon)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(summary{Stats: stats, Epochs: epochs}); err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	if err := s.deletePrefix(batch, eonEventPrefix(eon)); err != nil {
+		return err
+	}
+	if err := batch.Set(summaryKey(eon), buf.Bytes()); err != nil {
+		return err
+	}
+	return batch.WriteSync()
+}
+
+// DeleteEon removes everything stored about the given eon.
+func (s *Store) DeleteEon(eon uint64) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	if err := s.deletePrefix(batch, eonEventPrefix(eon)); err != nil {
+		return err
+	}
+	if err := batch.Delete(summaryKey(eon)); err != nil {
+		return err
+	}
+	if err := batch.Delete(headerKey(eon)); err != nil {
+		return err
+	}
+	return batch.WriteSync()
+}
+
+// ApplyRetention summarizes and deletes the archived eons according to the given policy. It
+// returns the number of eons summarized and deleted.
+func (s *Store) ApplyRetention(policy retention.Policy) (int, int, error) {
+	eons, err := s.Eons()
+	if err != nil {
+		return 0, 0, err
+	}
+	summarized, deleted := 0, 0
+	for eon, level := range policy.Levels(eons) {
+		switch level {
+		case retention.Summary:
+			sum, err := s.loadSummary(eon)
+			if err != nil {
+				return summarized, deleted, err
+			}
+			if sum != nil {
+				continue
+			}
+			if err := s.Summarize(eon); err != nil {
+				return summarized, deleted, errors.Wrapf(err, "failed to summarize eon %d", eon)
+			}
+			summarized++
+		case retention.Drop:
+			if err := s.DeleteEon(eon); err != nil {
+				return summarized, deleted, errors.Wrapf(err, "failed to delete eon %d", eon)
+			}
+			deleted++
+		}
+	}
+	return summarized, deleted, nil
+}
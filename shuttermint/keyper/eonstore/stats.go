@@ -0,0 +1,159 @@
+package eonstore
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// EonStats summarizes the DKG of an archived eon. Heights are Shuttermint block heights, sizes
+// are the sizes of the events as archived.
+type EonStats struct {
+	Eon             uint64
+	StartBatchIndex uint64
+	StartHeight     int64
+	EndHeight       int64 // height of the last DKG event, the start height if there was none
+	Dealers         int   // number of keypers that sent a poly commitment
+	PolyEvals       int
+	Accusations     int
+	Accused         int // number of distinct keypers accused
+	Apologies       int
+	DKGBytes        int // total size of the DKG events
+	Epochs          int // number of epochs key shares have been published for
+	KeyShareBytes   int // total size of the epoch secret key shares
+}
+
+// EpochStats summarizes the epoch secret key shares published for an epoch in one namespace.
+type EpochStats struct {
+	Eon         uint64
+	Epoch       uint64
+	Namespace   string
+	Shares      int
+	Senders     int   // number of distinct keypers that published a share
+	FirstHeight int64 // height of the first share
+	LastHeight  int64 // height of the last share
+	Bytes       int
+}
+
+type epochKey struct {
+	epoch     uint64
+	namespace string
+}
+
+// Stats computes statistics about the given archived eon and the epochs it has been used for,
+// without loading all of its events into memory. The epochs are sorted by epoch and namespace.
+// For summarized eons, the statistics computed when the eon has been summarized are returned.
+func (s *Store) Stats(eonNumber uint64) (EonStats, []EpochStats, error) {
+	sum, err := s.loadSummary(eonNumber)
+	if err != nil {
+		return EonStats{}, nil, err
+	}
+	if sum != nil {
+		return sum.Stats, sum.Epochs, nil
+	}
+	value, err := s.db.Get(headerKey(eonNumber))
+	if err != nil {
+		return EonStats{}, nil, err
+	}
+	if value == nil {
+		return EonStats{}, nil, errors.Errorf("eon %d not archived", eonNumber)
+	}
+	ev, err := decodeEvent(value)
+	if err != nil {
+		return EonStats{}, nil, err
+	}
+	start, ok := ev.(*shutterevents.EonStarted)
+	if !ok {
+		return EonStats{}, nil, errors.Errorf("unexpected header for eon %d: %T", eonNumber, ev)
+	}
+	stats := EonStats{
+		Eon:             eonNumber,
+		StartBatchIndex: start.BatchIndex,
+		StartHeight:     start.Height,
+		EndHeight:       start.Height,
+	}
+
+	dealers := make(map[common.Address]struct{})
+	accused := make(map[common.Address]struct{})
+	epochs := make(map[epochKey]*EpochStats)
+	senders := make(map[epochKey]map[common.Address]struct{})
+	for _, kind := range []EventKind{PolyCommitments, PolyEvals, Accusations, Apologies, EpochSecretKeyShares} {
+		it, err := s.Events(eonNumber, kind)
+		if err != nil {
+			return EonStats{}, nil, err
+		}
+		for it.Next() {
+			size := it.Size()
+			if kind != EpochSecretKeyShares {
+				stats.DKGBytes += size
+			}
+			switch e := it.Event().(type) {
+			case *shutterevents.PolyCommitment:
+				dealers[e.Sender] = struct{}{}
+				stats.updateEndHeight(e.Height)
+			case *shutterevents.PolyEval:
+				stats.PolyEvals++
+				stats.updateEndHeight(e.Height)
+			case *shutterevents.Accusation:
+				stats.Accusations++
+				for _, a := range e.Accused {
+					accused[a] = struct{}{}
+				}
+				stats.updateEndHeight(e.Height)
+			case *shutterevents.Apology:
+				stats.Apologies++
+				stats.updateEndHeight(e.Height)
+			case *shutterevents.EpochSecretKeyShare:
+				stats.KeyShareBytes += size
+				key := epochKey{epoch: e.Epoch, namespace: e.Namespace}
+				epoch, ok := epochs[key]
+				if !ok {
+					epoch = &EpochStats{
+						Eon:         eonNumber,
+						Epoch:       e.Epoch,
+						Namespace:   e.Namespace,
+						FirstHeight: e.Height,
+					}
+					epochs[key] = epoch
+					senders[key] = make(map[common.Address]struct{})
+				}
+				epoch.Shares++
+				epoch.Bytes += size
+				epoch.LastHeight = e.Height
+				senders[key][e.Sender] = struct{}{}
+			}
+		}
+		err = it.Err()
+		it.Close()
+		if err != nil {
+			return EonStats{}, nil, err
+		}
+	}
+	stats.Dealers = len(dealers)
+	stats.Accused = len(accused)
+
+	var epochStats []EpochStats
+	epochNumbers := make(map[uint64]struct{})
+	for key, epoch := range epochs {
+		epoch.Senders = len(senders[key])
+		epochStats = append(epochStats, *epoch)
+		epochNumbers[key.epoch] = struct{}{}
+	}
+	stats.Epochs = len(epochNumbers)
+	sort.Slice(epochStats, func(i, j int) bool {
+		if epochStats[i].Epoch != epochStats[j].Epoch {
+			return epochStats[i].Epoch < epochStats[j].Epoch
+		}
+		return epochStats[i].Namespace < epochStats[j].Namespace
+	})
+	return stats, epochStats, nil
+}
+
+func (stats *EonStats) updateEndHeight(height int64) {
+	if height > stats.EndHeight {
+		stats.EndHeight = height
+	}
+}
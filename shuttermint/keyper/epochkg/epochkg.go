@@ -24,13 +24,20 @@ type EpochKG struct {
 
 	SecretShares map[uint64][]*EpochSecretKeyShare
 	SecretKeys   map[uint64]*shcrypto.EpochSecretKey
+
+	// The shares and keys for epochs in non-default namespaces, indexed by namespace
+	NamespacedSecretShares map[string]map[uint64][]*EpochSecretKeyShare
+	NamespacedSecretKeys   map[string]map[uint64]*shcrypto.EpochSecretKey
 }
 
+// EpochSecretKeyShare is a share of the secret key of an epoch. Namespace is empty for the default
+// namespace.
 type EpochSecretKeyShare struct {
-	Eon    uint64
-	Epoch  uint64
-	Sender KeyperIndex
-	Share  *shcrypto.EpochSecretKeyShare
+	Eon       uint64
+	Epoch     uint64
+	Sender    KeyperIndex
+	Share     *shcrypto.EpochSecretKeyShare
+	Namespace string
 }
 
 func NewEpochKG(puredkgResult *puredkg.Result) *EpochKG {
@@ -49,10 +56,59 @@ func NewEpochKG(puredkgResult *puredkg.Result) *EpochKG {
 }
 
 func (epochkg *EpochKG) ComputeEpochSecretKeyShare(epoch uint64) *shcrypto.EpochSecretKeyShare {
-	epochID := shcrypto.ComputeEpochID(epoch)
+	return epochkg.ComputeNamespacedEpochSecretKeyShare("", epoch)
+}
+
+// ComputeNamespacedEpochSecretKeyShare computes our share of the secret key for the given epoch
+// in the given namespace.
+func (epochkg *EpochKG) ComputeNamespacedEpochSecretKeyShare(namespace string, epoch uint64) *shcrypto.EpochSecretKeyShare {
+	epochID := shcrypto.ComputeNamespacedEpochID(namespace, epoch)
 	return shcrypto.ComputeEpochSecretKeyShare(epochkg.SecretKeyShare, epochID)
 }
 
+// SecretKey returns the secret key for the given epoch in the given namespace if it has already
+// been generated.
+func (epochkg *EpochKG) SecretKey(namespace string, epoch uint64) (*shcrypto.EpochSecretKey, bool) {
+	if namespace == "" {
+		key, ok := epochkg.SecretKeys[epoch]
+		return key, ok
+	}
+	key, ok := epochkg.NamespacedSecretKeys[namespace][epoch]
+	return key, ok
+}
+
+// secretShares returns the map of collected shares for the given namespace.
+func (epochkg *EpochKG) secretShares(namespace string) map[uint64][]*EpochSecretKeyShare {
+	if namespace == "" {
+		return epochkg.SecretShares
+	}
+	if epochkg.NamespacedSecretShares == nil {
+		epochkg.NamespacedSecretShares = make(map[string]map[uint64][]*EpochSecretKeyShare)
+	}
+	shares, ok := epochkg.NamespacedSecretShares[namespace]
+	if !ok {
+		shares = make(map[uint64][]*EpochSecretKeyShare)
+		epochkg.NamespacedSecretShares[namespace] = shares
+	}
+	return shares
+}
+
+// secretKeys returns the map of generated secret keys for the given namespace.
+func (epochkg *EpochKG) secretKeys(namespace string) map[uint64]*shcrypto.EpochSecretKey {
+	if namespace == "" {
+		return epochkg.SecretKeys
+	}
+	if epochkg.NamespacedSecretKeys == nil {
+		epochkg.NamespacedSecretKeys = make(map[string]map[uint64]*shcrypto.EpochSecretKey)
+	}
+	keys, ok := epochkg.NamespacedSecretKeys[namespace]
+	if !ok {
+		keys = make(map[uint64]*shcrypto.EpochSecretKey)
+		epochkg.NamespacedSecretKeys[namespace] = keys
+	}
+	return keys
+}
+
 func (epochkg *EpochKG) computeEpochSecretKey(shares []*EpochSecretKeyShare) (*shcrypto.EpochSecretKey, error) {
 	var keyperIndices []int
 	var epochSecretKeyShares []*shcrypto.EpochSecretKeyShare
@@ -64,42 +120,45 @@ func (epochkg *EpochKG) computeEpochSecretKey(shares []*EpochSecretKeyShare) (*s
 }
 
 func (epochkg *EpochKG) addEpochSecretKeyShare(share *EpochSecretKeyShare) error {
-	shares := epochkg.SecretShares[share.Epoch]
+	sharesByEpoch := epochkg.secretShares(share.Namespace)
+	shares := sharesByEpoch[share.Epoch]
 	for _, s := range shares {
 		if s.Sender == share.Sender {
 			return errors.Errorf(
-				"already have EpochSecretKeyShare from sender %d for epoch %d",
+				"already have EpochSecretKeyShare from sender %d for epoch %d in namespace %q",
 				share.Sender,
-				share.Epoch)
+				share.Epoch,
+				share.Namespace)
 		}
 	}
 	shares = append(shares, share)
 	if len(shares) != int(epochkg.Threshold) {
-		epochkg.SecretShares[share.Epoch] = shares
+		sharesByEpoch[share.Epoch] = shares
 		return nil
 	}
 
 	secretKey, err := epochkg.computeEpochSecretKey(shares)
-	delete(epochkg.SecretShares, share.Epoch)
-	epochkg.SecretKeys[share.Epoch] = secretKey // may be nil in the error case
+	delete(sharesByEpoch, share.Epoch)
+	epochkg.secretKeys(share.Namespace)[share.Epoch] = secretKey // may be nil in the error case
 	return err
 }
 
 func (epochkg *EpochKG) HandleEpochSecretKeyShare(share *EpochSecretKeyShare) error {
-	if _, ok := epochkg.SecretKeys[share.Epoch]; ok {
+	if _, ok := epochkg.SecretKey(share.Namespace, share.Epoch); ok {
 		// We already have the key for this epoch
 		return nil
 	}
-	epochID := shcrypto.ComputeEpochID(share.Epoch)
+	epochID := shcrypto.ComputeNamespacedEpochID(share.Namespace, share.Epoch)
 	if !shcrypto.VerifyEpochSecretKeyShare(
 		share.Share,
 		epochkg.PublicKeyShares[share.Sender],
 		epochID,
 	) {
 		return errors.Errorf(
-			"cannot verify epoch secret key share from sender %d for epoch %d",
+			"cannot verify epoch secret key share from sender %d for epoch %d in namespace %q",
 			share.Sender,
-			share.Epoch)
+			share.Epoch,
+			share.Namespace)
 	}
 	err := epochkg.addEpochSecretKeyShare(share)
 	if err != nil {
@@ -103,3 +103,75 @@ func TestEpochKG(t *testing.T) {
 
 	shtest.EnsureGobable(t, kgs[0], new(EpochKG))
 }
+
+func TestEpochKGNamespaces(t *testing.T) {
+	results := Results(t)
+	var kgs []*EpochKG
+	for _, r := range results {
+		kgs = append(kgs, NewEpochKG(r))
+	}
+
+	epoch := uint64(50)
+	for _, namespace := range []string{"", "foo"} {
+		for sender, kg := range kgs {
+			share := EpochSecretKeyShare{
+				Eon:       kg.Eon,
+				Epoch:     epoch,
+				Sender:    uint64(sender),
+				Share:     kg.ComputeNamespacedEpochSecretKeyShare(namespace, epoch),
+				Namespace: namespace,
+			}
+			for _, k := range kgs {
+				err := k.HandleEpochSecretKeyShare(&share)
+				assert.NilError(t, err)
+			}
+		}
+	}
+
+	for _, kg := range kgs {
+		defaultKey, ok := kg.SecretKey("", epoch)
+		assert.Assert(t, ok)
+		assert.DeepEqual(t, kg.SecretKeys[epoch], defaultKey)
+		fooKey, ok := kg.SecretKey("foo", epoch)
+		assert.Assert(t, ok)
+		assert.Assert(t, !fooKey.Equal(defaultKey))
+		_, ok = kg.SecretKey("bar", epoch)
+		assert.Assert(t, !ok)
+	}
+
+	// a share for the wrong namespace must be rejected
+	share := EpochSecretKeyShare{
+		Eon:       kgs[0].Eon,
+		Epoch:     epoch + 1,
+		Sender:    0,
+		Share:     kgs[0].ComputeEpochSecretKeyShare(epoch + 1),
+		Namespace: "foo",
+	}
+	err := kgs[1].HandleEpochSecretKeyShare(&share)
+	assert.Assert(t, err != nil)
+}
+
+func TestShareCache(t *testing.T) {
+	results := Results(t)
+	kg := NewEpochKG(results[0])
+	cache := NewShareCache()
+
+	<-cache.Precompute(kg, "foo", 10, 5)
+	assert.Equal(t, cache.Len(), 5)
+	for epoch := uint64(10); epoch < 15; epoch++ {
+		share := cache.Share(kg, "foo", epoch)
+		assert.DeepEqual(t, share, kg.ComputeNamespacedEpochSecretKeyShare("foo", epoch))
+	}
+	assert.Equal(t, cache.Len(), 0)
+
+	// unused shares of past epochs are dropped
+	<-cache.Precompute(kg, "foo", 20, 2)
+	<-cache.Precompute(kg, "foo", 21, 2)
+	assert.Equal(t, cache.Len(), 2)
+
+	// shares not in the cache are computed on the spot, also without a cache
+	assert.DeepEqual(t, cache.Share(kg, "", 30), kg.ComputeEpochSecretKeyShare(30))
+	var noCache *ShareCache
+	<-noCache.Precompute(kg, "", 30, 1)
+	assert.DeepEqual(t, noCache.Share(kg, "", 30), kg.ComputeEpochSecretKeyShare(30))
+}
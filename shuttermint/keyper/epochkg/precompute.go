@@ -0,0 +1,133 @@
+package epochkg
+
+import (
+	"sync"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// maxShareTables is the number of eons the cache keeps share tables for.
+const maxShareTables = 4
+
+type shareCacheKey struct {
+	eon       uint64
+	namespace string
+	epoch     uint64
+}
+
+// ShareCache stores our epoch secret key shares for upcoming epochs, computed ahead of time in
+// the background. It's kept separate from EpochKG, because EpochKG is persisted as part of the
+// keyper's state, whereas the cache can always be recomputed. Shares are computed from a
+// precomputation table per eon, which is a lot faster than computing them from the epoch ids.
+type ShareCache struct {
+	mux    sync.Mutex
+	shares map[shareCacheKey]*shcrypto.EpochSecretKeyShare
+	tables map[uint64]*shcrypto.EpochSecretKeyShareTable // by eon
+}
+
+// NewShareCache creates an empty ShareCache.
+func NewShareCache() *ShareCache {
+	return &ShareCache{
+		shares: make(map[shareCacheKey]*shcrypto.EpochSecretKeyShare),
+		tables: make(map[uint64]*shcrypto.EpochSecretKeyShareTable),
+	}
+}
+
+// table returns the share table of the given eon, computing it if necessary. Only the tables of
+// the maxShareTables latest eons are kept.
+func (c *ShareCache) table(eon uint64, eonSecretKeyShare *shcrypto.EonSecretKeyShare) *shcrypto.EpochSecretKeyShareTable {
+	c.mux.Lock()
+	table, ok := c.tables[eon]
+	c.mux.Unlock()
+	if ok {
+		return table
+	}
+
+	table = shcrypto.NewEpochSecretKeyShareTable(eonSecretKeyShare)
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if existing, ok := c.tables[eon]; ok {
+		return existing
+	}
+	c.tables[eon] = table
+	for len(c.tables) > maxShareTables {
+		oldest := eon
+		for eon := range c.tables {
+			if eon < oldest {
+				oldest = eon
+			}
+		}
+		delete(c.tables, oldest)
+	}
+	return table
+}
+
+// Precompute computes our shares for the count epochs starting at fromEpoch in the background.
+// Shares of earlier epochs in the same eon and namespace that have not been used are dropped. The
+// returned channel is closed when all shares have been computed.
+func (c *ShareCache) Precompute(epochkg *EpochKG, namespace string, fromEpoch uint64, count uint64) <-chan struct{} {
+	done := make(chan struct{})
+	if c == nil {
+		close(done)
+		return done
+	}
+
+	var keys []shareCacheKey
+	c.mux.Lock()
+	for k := range c.shares {
+		if k.eon == epochkg.Eon && k.namespace == namespace && k.epoch < fromEpoch {
+			delete(c.shares, k)
+		}
+	}
+	for epoch := fromEpoch; epoch < fromEpoch+count; epoch++ {
+		k := shareCacheKey{eon: epochkg.Eon, namespace: namespace, epoch: epoch}
+		if _, ok := c.shares[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	c.mux.Unlock()
+	if len(keys) == 0 {
+		close(done)
+		return done
+	}
+
+	eon, eonSecretKeyShare := epochkg.Eon, epochkg.SecretKeyShare
+	go func() {
+		defer close(done)
+		table := c.table(eon, eonSecretKeyShare)
+		for _, k := range keys {
+			share := table.Compute(k.namespace, k.epoch)
+			c.mux.Lock()
+			c.shares[k] = share
+			c.mux.Unlock()
+		}
+	}()
+	return done
+}
+
+// Share returns our share of the secret key for the given epoch in the given namespace. It's
+// taken from the cache if it has been precomputed and computed on the spot otherwise.
+func (c *ShareCache) Share(epochkg *EpochKG, namespace string, epoch uint64) *shcrypto.EpochSecretKeyShare {
+	if c != nil {
+		k := shareCacheKey{eon: epochkg.Eon, namespace: namespace, epoch: epoch}
+		c.mux.Lock()
+		share, ok := c.shares[k]
+		delete(c.shares, k)
+		table := c.tables[epochkg.Eon]
+		c.mux.Unlock()
+		if ok {
+			return share
+		}
+		if table != nil {
+			return table.Compute(namespace, epoch)
+		}
+	}
+	return epochkg.ComputeNamespacedEpochSecretKeyShare(namespace, epoch)
+}
+
+// Len returns the number of cached shares.
+func (c *ShareCache) Len() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return len(c.shares)
+}
@@ -0,0 +1,170 @@
+package keyper
+
+import (
+	"math"
+	"sort"
+)
+
+// EpochRange is the range of epochs from First to Last, both inclusive.
+type EpochRange struct {
+	First uint64
+	Last  uint64
+}
+
+// EpochSet is a set of epochs. It is stored as sorted ranges that neither overlap nor touch, so
+// that it stays small as long as epochs are added in order.
+type EpochSet []EpochRange
+
+// Contains checks if the given epoch is in the set.
+func (s EpochSet) Contains(epoch uint64) bool {
+	i := sort.Search(len(s), func(i int) bool { return s[i].Last >= epoch })
+	return i < len(s) && s[i].First <= epoch
+}
+
+// Add adds the epochs from first to last, both inclusive, to the set.
+func (s *EpochSet) Add(first, last uint64) {
+	if first > last {
+		return
+	}
+	set := *s
+	// the ranges from i to j-1 overlap or touch the new one and are merged into it
+	i := sort.Search(len(set), func(i int) bool { return first == 0 || set[i].Last >= first-1 })
+	j := i
+	for j < len(set) && (last == math.MaxUint64 || set[j].First <= last+1) {
+		j++
+	}
+	merged := EpochRange{First: first, Last: last}
+	if j > i {
+		if set[i].First < merged.First {
+			merged.First = set[i].First
+		}
+		if set[j-1].Last > merged.Last {
+			merged.Last = set[j-1].Last
+		}
+	}
+	res := make(EpochSet, 0, len(set)-(j-i)+1)
+	res = append(res, set[:i]...)
+	res = append(res, merged)
+	res = append(res, set[j:]...)
+	*s = res
+}
+
+// EpochShares records the epochs of an eon and namespace we've published our epoch secret key
+// shares for and the ones we've skipped. Together with NextEpochSecretShare and
+// NextBlockEpochSecretShare, which tell how far we've got, every epoch we've passed is in one of
+// the sets, unless we haven't been a keyper of its eon.
+type EpochShares struct {
+	Eon       uint64
+	Namespace string
+	Sent      EpochSet // our share is in the chain or in a message we've sent
+	// Skipped holds the epochs we've decided not to publish our share for, e.g. because their
+	// batch's execution timeout has been reached while we were offline. Their shares may still
+	// be sent later if a threshold of keypers approves their decryption, see
+	// handleDecryptionApprovals.
+	Skipped EpochSet
+}
+
+func (st *State) findEpochShares(eon uint64, namespace string) *EpochShares {
+	for _, s := range st.EpochShares {
+		if s.Eon == eon && s.Namespace == namespace {
+			return s
+		}
+	}
+	return nil
+}
+
+func (st *State) epochShares(eon uint64, namespace string) *EpochShares {
+	s := st.findEpochShares(eon, namespace)
+	if s == nil {
+		s = &EpochShares{Eon: eon, Namespace: namespace}
+		st.EpochShares = append(st.EpochShares, s)
+	}
+	return s
+}
+
+// reconcileEpochShares records our epoch secret key shares that have appeared in the chain since
+// the last reconciliation and the ones in messages we've sent before a restart, but haven't seen
+// in the chain yet. The state we've restarted with can be older than the chain, e.g. after a
+// standby took over or if a step's actions ran, but its state wasn't saved. The records of eons
+// whose EKGs have been pruned are dropped.
+func (dcdr *Decider) reconcileEpochShares() {
+	self := dcdr.Config.Address()
+	reconciled := make(map[uint64]int64)
+	for _, ekg := range dcdr.State.EKGs {
+		height, ok := dcdr.State.EpochSharesReconciled[ekg.Eon]
+		if !ok {
+			height = -1 // an imported eon may have shares of ours at any height
+		}
+		reconciled[ekg.Eon] = height
+		e, err := dcdr.Shutter.FindEon(ekg.Eon)
+		if err != nil {
+			continue
+		}
+		// the shares are ordered by height
+		shares := e.EpochSecretKeyShares
+		start := sort.Search(len(shares), func(i int) bool { return shares[i].Height > height })
+		for _, share := range shares[start:] {
+			if share.Sender != self {
+				continue
+			}
+			dcdr.State.epochShares(share.Eon, share.Namespace).Sent.Add(share.Epoch, share.Epoch)
+		}
+		reconciled[ekg.Eon] = dcdr.Shutter.CurrentBlock
+	}
+	dcdr.State.EpochSharesReconciled = reconciled
+
+	for _, msg := range dcdr.PendingMessages {
+		if share := msg.GetEpochSecretKeyShare(); share != nil {
+			dcdr.State.epochShares(share.Eon, share.Namespace).Sent.Add(share.Epoch, share.Epoch)
+		}
+	}
+
+	var kept []*EpochShares
+	for _, s := range dcdr.State.EpochShares {
+		if _, ok := reconciled[s.Eon]; ok {
+			kept = append(kept, s)
+		}
+	}
+	dcdr.State.EpochShares = kept
+}
+
+// epochSecretKeyShareSent checks if we've already sent our share for the given epoch, i.e. if it
+// is in the chain or in a message we've sent, but haven't seen in the chain yet. NextEpochSecretShare
+// alone doesn't tell, since the state we've restarted with can be older than the chain.
+// Shuttermint accepts a second share of ours for the same epoch, so sending it again would only
+// waste bandwidth and block space.
+func (dcdr *Decider) epochSecretKeyShareSent(eon uint64, namespace string, epoch uint64) bool {
+	if !dcdr.epochSharesReconciled {
+		dcdr.reconcileEpochShares()
+		dcdr.epochSharesReconciled = true
+	}
+	s := dcdr.State.findEpochShares(eon, namespace)
+	return s != nil && s.Sent.Contains(epoch)
+}
+
+// skipEpochSecretKeyShares records that we don't publish our shares for the epochs from first to
+// last, both inclusive, of the given batch, e.g. since its execution timeout has been reached.
+func (dcdr *Decider) skipEpochSecretKeyShares(batchIndex uint64, first, last uint64) {
+	batchConfig := dcdr.Shutter.FindBatchConfigByBatchIndex(batchIndex)
+	if !batchConfig.IsKeyper(dcdr.Config.Address()) {
+		return
+	}
+	eon, ok := dcdr.State.ActiveEon(batchIndex)
+	if !ok {
+		return
+	}
+	if _, err := dcdr.State.FindEKGByEon(eon); err != nil {
+		return
+	}
+	namespaces := append([]string{""}, batchConfig.EpochNamespaces...)
+	for _, namespace := range namespaces {
+		for epoch := first; epoch <= last; epoch++ {
+			if !dcdr.epochSecretKeyShareSent(eon, namespace, epoch) {
+				dcdr.State.epochShares(eon, namespace).Skipped.Add(epoch, epoch)
+			}
+			if epoch == math.MaxUint64 {
+				break
+			}
+		}
+	}
+}
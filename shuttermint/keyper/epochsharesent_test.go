@@ -0,0 +1,128 @@
+package keyper
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// sentShareEpochs returns the epochs of the epoch secret key shares the decider has sent.
+func sentShareEpochs(dcdr *Decider) []uint64 {
+	var epochs []uint64
+	for _, action := range dcdr.Actions {
+		epochs = append(epochs, action.(*fx.SendShuttermintMessage).Msg.GetEpochSecretKeyShare().Epoch)
+	}
+	return epochs
+}
+
+func TestEpochSet(t *testing.T) {
+	var s EpochSet
+	s.Add(5, 7)
+	s.Add(10, 10)
+	s.Add(1, 2)
+	assert.DeepEqual(t, s, EpochSet{{1, 2}, {5, 7}, {10, 10}})
+	assert.Assert(t, s.Contains(6))
+	assert.Assert(t, s.Contains(10))
+	assert.Assert(t, !s.Contains(0))
+	assert.Assert(t, !s.Contains(8))
+	assert.Assert(t, !s.Contains(11))
+
+	s.Add(8, 9) // touches both neighbours
+	assert.DeepEqual(t, s, EpochSet{{1, 2}, {5, 10}})
+	s.Add(3, 4)
+	assert.DeepEqual(t, s, EpochSet{{1, 10}})
+	s.Add(4, 6) // already contained
+	assert.DeepEqual(t, s, EpochSet{{1, 10}})
+	s.Add(3, 2) // empty
+	assert.DeepEqual(t, s, EpochSet{{1, 10}})
+
+	s.Add(0, 0)
+	s.Add(math.MaxUint64, math.MaxUint64)
+	s.Add(math.MaxUint64-1, math.MaxUint64-1)
+	assert.DeepEqual(t, s, EpochSet{{0, 10}, {math.MaxUint64 - 1, math.MaxUint64}})
+	assert.Assert(t, s.Contains(math.MaxUint64))
+}
+
+func TestEpochSharesSurviveArchivedEvents(t *testing.T) {
+	dcdr := eonTransitionDecider(t)
+	dcdr.publishEpochSecretKeyShares()
+	assert.DeepEqual(t, sentShareEpochs(dcdr), []uint64{0, 1, 2, 3, 4})
+	assert.DeepEqual(t, dcdr.State.findEpochShares(1, "").Sent, EpochSet{{0, 2}})
+	assert.DeepEqual(t, dcdr.State.findEpochShares(2, "").Sent, EpochSet{{3, 4}})
+
+	// a later step with an older NextEpochSecretShare doesn't send the shares again, even though
+	// they aren't among the observed events
+	later := &Decider{Config: dcdr.Config, State: dcdr.State, Shutter: dcdr.Shutter, MainChain: dcdr.MainChain}
+	later.State.NextEpochSecretShare = 0
+	later.publishEpochSecretKeyShares()
+	assert.Equal(t, len(later.Actions), 0)
+
+	// the records of pruned eons are dropped
+	later.State.EKGs = later.State.EKGs[1:]
+	later.reconcileEpochShares()
+	assert.Assert(t, later.State.findEpochShares(1, "") == nil)
+	assert.Assert(t, later.State.findEpochShares(2, "") != nil)
+}
+
+func TestReconcileEpochShares(t *testing.T) {
+	dcdr := eonTransitionDecider(t)
+	self := dcdr.Config.Address()
+	eon := &dcdr.Shutter.Eons[0]
+	addShare := func(height int64, sender common.Address, epoch uint64) {
+		eon.EpochSecretKeyShares = append(eon.EpochSecretKeyShares, shutterevents.EpochSecretKeyShare{
+			Height: height,
+			Sender: sender,
+			Eon:    1,
+			Epoch:  epoch,
+		})
+		dcdr.Shutter.CurrentBlock = height
+	}
+	addShare(10, self, 0)
+	addShare(10, common.HexToAddress("0x1"), 3)
+	dcdr.reconcileEpochShares()
+	assert.DeepEqual(t, dcdr.State.findEpochShares(1, "").Sent, EpochSet{{0, 0}})
+	assert.Equal(t, dcdr.State.EpochSharesReconciled[1], int64(10))
+
+	// only the shares since the last reconciliation are looked at
+	eon.EpochSecretKeyShares[0].Epoch = 5
+	addShare(11, self, 1)
+	dcdr.reconcileEpochShares()
+	assert.DeepEqual(t, dcdr.State.findEpochShares(1, "").Sent, EpochSet{{0, 1}})
+	assert.Equal(t, dcdr.State.EpochSharesReconciled[1], int64(11))
+}
+
+func TestSkippedEpochSharesRecorded(t *testing.T) {
+	dcdr := policyTestDecider(t, nil)
+	dcdr.State.EKGs[0].EpochKG = singleKeyperEpochKG(t, 1)
+	dcdr.Shutter.BatchConfigs[0].EpochNamespaces = []string{"rollup"}
+	// the execution timeout of the batches 0 to 2 has been reached
+	dcdr.MainChain.BatchConfigs[1].ExecutionTimeout = 20
+
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(5))
+	assert.DeepEqual(t, sentShareEpochs(dcdr), []uint64{3, 3, 4, 4})
+	for _, namespace := range []string{"", "rollup"} {
+		shares := dcdr.State.findEpochShares(1, namespace)
+		assert.DeepEqual(t, shares.Skipped, EpochSet{{0, 2}})
+		assert.DeepEqual(t, shares.Sent, EpochSet{{3, 4}})
+	}
+}
+
+func TestSkippedBlockEpochSharesRecorded(t *testing.T) {
+	dcdr := policyTestDecider(t, nil)
+	dcdr.State.EKGs[0].EpochKG = singleKeyperEpochKG(t, 1)
+	dcdr.Shutter.BatchConfigs[0].PerBlockEpochs = true
+	dcdr.MainChain.CurrentBlock = 250
+
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, dcdr.State.NextBlockEpochSecretShare, uint64(250))
+	assert.Equal(t, len(dcdr.Actions), int(maxBlockEpochBacklog))
+	shares := dcdr.State.findEpochShares(1, "")
+	assert.DeepEqual(t, shares.Skipped, EpochSet{{0, 149}})
+	assert.DeepEqual(t, shares.Sent, EpochSet{{150, 249}})
+}
@@ -0,0 +1,138 @@
+package keyper
+
+import (
+	"container/list"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// polyEvalCacheSize is the number of decrypted poly evals the keyper keeps in memory.
+const polyEvalCacheSize = 1024
+
+type polyEvalKey struct {
+	eon      uint64
+	sender   common.Address
+	receiver common.Address
+}
+
+type polyEvalEntry struct {
+	key  polyEvalKey
+	hash common.Hash // of the encrypted eval
+	eval *big.Int
+}
+
+// PolyEvalCache keeps the poly evals we've decrypted, so that poly eval events observed again,
+// e.g. after a resync or when replaying, don't need another ECIES decryption. Entries are keyed by
+// eon, sender and receiver and only used if the encrypted eval has the same content hash. The
+// least recently used entries are evicted first. Like the DKG's own copies, the evals are secret,
+// so they're only kept in memory and zeroed when evicted.
+type PolyEvalCache struct {
+	size int
+
+	mux     sync.Mutex
+	entries map[polyEvalKey]*list.Element
+	order   *list.List // of *polyEvalEntry, most recently used first
+	hits    uint64
+	misses  uint64
+}
+
+// NewPolyEvalCache creates an empty cache holding up to size evals.
+func NewPolyEvalCache(size int) *PolyEvalCache {
+	return &PolyEvalCache{
+		size:    size,
+		entries: make(map[polyEvalKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Eval returns the poly eval the sender has sent to the receiver in the given eon, decrypting it
+// only if it isn't cached yet or differs from the cached one. The result is a copy the caller may
+// zero. A nil cache decrypts every time.
+func (c *PolyEvalCache) Eval(
+	eon uint64,
+	sender common.Address,
+	receiver common.Address,
+	encrypted []byte,
+	decrypt decryptfn,
+) (*big.Int, error) {
+	if c == nil {
+		return decryptPolyEval(encrypted, decrypt)
+	}
+	key := polyEvalKey{eon: eon, sender: sender, receiver: receiver}
+	hash := crypto.Keccak256Hash(encrypted)
+
+	c.mux.Lock()
+	if elem, ok := c.entries[key]; ok && elem.Value.(*polyEvalEntry).hash == hash {
+		c.order.MoveToFront(elem)
+		c.hits++
+		eval := new(big.Int).Set(elem.Value.(*polyEvalEntry).eval)
+		c.mux.Unlock()
+		return eval, nil
+	}
+	c.misses++
+	c.mux.Unlock()
+
+	eval, err := decryptPolyEval(encrypted, decrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.remove(key)
+	c.entries[key] = c.order.PushFront(&polyEvalEntry{key: key, hash: hash, eval: new(big.Int).Set(eval)})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back().Value.(*polyEvalEntry).key)
+	}
+	return eval, nil
+}
+
+// Forget removes the evals of the given eon, e.g. once its DKG has been finalized.
+func (c *PolyEvalCache) Forget(eon uint64) {
+	if c == nil {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for key := range c.entries {
+		if key.eon == eon {
+			c.remove(key)
+		}
+	}
+}
+
+// Stats returns the number of evals taken from the cache and the number of evals decrypted.
+func (c *PolyEvalCache) Stats() (hits uint64, misses uint64) {
+	if c == nil {
+		return 0, 0
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.hits, c.misses
+}
+
+// remove removes and zeroes the entry with the given key, if any. The caller must hold the lock.
+func (c *PolyEvalCache) remove(key polyEvalKey) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	shcrypto.ZeroInt(elem.Value.(*polyEvalEntry).eval)
+	c.order.Remove(elem)
+	delete(c.entries, key)
+}
+
+func decryptPolyEval(encrypted []byte, decrypt decryptfn) (*big.Int, error) {
+	evalBytes, err := decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	eval := new(big.Int).SetBytes(evalBytes)
+	shcrypto.ZeroBytes(evalBytes)
+	return eval, nil
+}
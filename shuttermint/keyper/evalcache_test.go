@@ -0,0 +1,135 @@
+package keyper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// countingDecrypt returns a decryptfn that returns its input and counts its calls.
+func countingDecrypt(calls *int) decryptfn {
+	return func(encrypted []byte) ([]byte, error) {
+		*calls++
+		if len(encrypted) == 0 {
+			return nil, errors.New("nothing to decrypt")
+		}
+		return append([]byte(nil), encrypted...), nil
+	}
+}
+
+func TestPolyEvalCache(t *testing.T) {
+	a := common.BigToAddress(big.NewInt(1))
+	b := common.BigToAddress(big.NewInt(2))
+	c := common.BigToAddress(big.NewInt(3))
+	calls := 0
+	decrypt := countingDecrypt(&calls)
+	cache := NewPolyEvalCache(2)
+
+	eval, err := cache.Eval(1, a, c, []byte{5}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, eval.Int64(), int64(5))
+	// the caller may zero the eval without affecting the cache
+	eval.SetInt64(0)
+	eval, err = cache.Eval(1, a, c, []byte{5}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, eval.Int64(), int64(5))
+	assert.Equal(t, calls, 1)
+
+	// a different encrypted eval for the same key replaces the cached one
+	eval, err = cache.Eval(1, a, c, []byte{6}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, eval.Int64(), int64(6))
+	assert.Equal(t, calls, 2)
+
+	// failed decryptions aren't cached
+	_, err = cache.Eval(1, b, c, nil, decrypt)
+	assert.ErrorContains(t, err, "nothing to decrypt")
+	_, err = cache.Eval(1, b, c, nil, decrypt)
+	assert.ErrorContains(t, err, "nothing to decrypt")
+	assert.Equal(t, calls, 4)
+
+	// the least recently used eval is evicted
+	_, err = cache.Eval(2, a, c, []byte{7}, decrypt)
+	assert.NilError(t, err)
+	_, err = cache.Eval(1, a, c, []byte{6}, decrypt)
+	assert.NilError(t, err)
+	_, err = cache.Eval(2, b, c, []byte{8}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 6)
+	_, err = cache.Eval(1, a, c, []byte{6}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 6)
+	_, err = cache.Eval(2, a, c, []byte{7}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 7)
+
+	cache.Forget(2)
+	_, err = cache.Eval(1, a, c, []byte{6}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 7)
+	_, err = cache.Eval(2, a, c, []byte{7}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 8)
+
+	hits, misses := cache.Stats()
+	assert.Equal(t, hits, uint64(4))
+	assert.Equal(t, misses, uint64(8))
+
+	var none *PolyEvalCache
+	eval, err = none.Eval(1, a, c, []byte{9}, decrypt)
+	assert.NilError(t, err)
+	assert.Equal(t, eval.Int64(), int64(9))
+	assert.Equal(t, calls, 9)
+}
+
+func TestSyncPolyEvalsIdempotent(t *testing.T) {
+	keypers := []common.Address{common.BigToAddress(big.NewInt(1)), common.BigToAddress(big.NewInt(2))}
+	dealer := puredkg.NewPureDKG(1, 2, 1, 1)
+	_, evals, err := dealer.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.Equal(t, evals[0].Receiver, puredkg.KeyperIndex(0))
+	eon := observe.Eon{
+		Eon:         1,
+		StartHeight: 100,
+		PolyEvals: []shutterevents.PolyEval{{
+			Height:         101,
+			Sender:         keypers[1],
+			Eon:            1,
+			Receivers:      []common.Address{keypers[0]},
+			EncryptedEvals: [][]byte{evals[0].Eval.Bytes()},
+		}},
+	}
+	newDKG := func() *DKG {
+		pure := puredkg.NewPureDKG(1, 2, 1, 0)
+		_, _, err := pure.StartPhase1Dealing()
+		assert.NilError(t, err)
+		return &DKG{Eon: 1, Keypers: keypers, Pure: &pure, PhaseLength: NewConstantPhaseLength(10)}
+	}
+	calls := 0
+	decrypt := countingDecrypt(&calls)
+	cache := NewPolyEvalCache(polyEvalCacheSize)
+
+	dkg := newDKG()
+	dkg.syncPolyEvals(0, eon, decrypt, cache)
+	assert.Equal(t, calls, 1)
+	assert.Assert(t, dkg.Pure.Evals[1].Cmp(evals[0].Eval) == 0)
+
+	// observing the eval again neither decrypts it again nor touches the cache
+	dkg.syncPolyEvals(0, eon, decrypt, cache)
+	assert.Equal(t, calls, 1)
+	hits, _ := cache.Stats()
+	assert.Equal(t, hits, uint64(0))
+
+	// a DKG rebuilt from scratch, e.g. when replaying, gets the eval from the cache
+	dkg = newDKG()
+	dkg.syncPolyEvals(0, eon, decrypt, cache)
+	assert.Equal(t, calls, 1)
+	assert.Assert(t, dkg.Pure.Evals[1].Cmp(evals[0].Eval) == 0)
+}
@@ -0,0 +1,261 @@
+// Package eventstream serves the shutter events observed by a keyper via gRPC, so that external
+// indexers don't need to decode the tendermint events themselves.
+package eventstream
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"reflect"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/rpc/client"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents/evtype"
+)
+
+// pollInterval is how often we check for new shuttermint blocks.
+const pollInterval = time.Second
+
+// NamespaceKey is the metadata entry naming the namespace, i.e. the deployment, a client wants to
+// receive events from. The server sends it back in the response header.
+const NamespaceKey = "shutter-namespace"
+
+// restrictedEventTypes are the types of events carrying poly evals, which are only streamed to
+// keypers if access is closed.
+var restrictedEventTypes = map[string]bool{
+	evtype.PolyEval: true,
+	evtype.Apology:  true,
+}
+
+// Source provides the shuttermint transactions to stream events from.
+type Source interface {
+	LastHeight(ctx context.Context) (int64, error)
+	FetchTxs(ctx context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx)) error
+}
+
+// ShuttermintSource fetches transactions from a shuttermint node the same way the keyper's
+// observer does.
+type ShuttermintSource struct {
+	Client client.Client
+}
+
+func (s ShuttermintSource) LastHeight(ctx context.Context) (int64, error) {
+	return observe.NewShutter().GetLastCommittedHeight(ctx, s.Client)
+}
+
+func (s ShuttermintSource) FetchTxs(
+	ctx context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx),
+) error {
+	return observe.FetchTxs(ctx, s.Client, fromHeight, toHeight, handler)
+}
+
+// Server implements the EventStream gRPC service.
+type Server struct {
+	UnimplementedEventStreamServer
+	source       Source
+	pollInterval time.Duration
+	access       *access.Checker
+	namespace    string
+}
+
+// NewServer creates a new Server streaming events from the given source.
+func NewServer(source Source) *Server {
+	return &Server{source: source, pollInterval: pollInterval}
+}
+
+// SetAccess sets the checker deciding who may receive restricted events. If not set, all events
+// are streamed to everyone.
+func (s *Server) SetAccess(checker *access.Checker) {
+	s.access = checker
+}
+
+// SetNamespace sets the namespace, usually the deployment ID, clients must request in the
+// NamespaceKey metadata entry. Requests for any other namespace are rejected, and so are requests
+// naming a namespace if none is set.
+func (s *Server) SetNamespace(namespace string) {
+	s.namespace = namespace
+}
+
+// checkNamespace checks that the client requested our namespace and confirms it in the response
+// header.
+func (s *Server) checkNamespace(stream grpc.ServerStream) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	requested := md.Get(NamespaceKey)
+	if len(requested) > 1 || (len(requested) == 1 && requested[0] != s.namespace) ||
+		(len(requested) == 0 && s.namespace != "") {
+		return status.Errorf(codes.NotFound, "unknown namespace %q, serving %q", requested, s.namespace)
+	}
+	if s.namespace == "" {
+		return nil
+	}
+	return stream.SendHeader(metadata.Pairs(NamespaceKey, s.namespace))
+}
+
+// Register registers the server with the given gRPC server.
+func (s *Server) Register(gs *grpc.Server) {
+	RegisterEventStreamServer(gs, s)
+}
+
+// ListenAndServe serves the event stream on the given address until the context is canceled.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "event stream server at %s", addr)
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+	go func() {
+		<-ctx.Done()
+		gs.Stop()
+	}()
+	err = gs.Serve(listener)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.Wrapf(err, "event stream server at %s", addr)
+}
+
+func (s *Server) SubscribeEvents(req *SubscribeEventsRequest, stream EventStream_SubscribeEventsServer) error {
+	ctx := stream.Context()
+	if err := s.checkNamespace(stream); err != nil {
+		return err
+	}
+	types := make(map[string]bool)
+	for _, t := range req.Types {
+		types[t] = true
+	}
+	restricted := !s.access.AllowedGRPC(ctx)
+
+	next := req.StartHeight
+	if next <= 0 {
+		last, err := s.source.LastHeight(ctx)
+		if err != nil {
+			return err
+		}
+		next = last + 1
+	}
+	for {
+		last, err := s.source.LastHeight(ctx)
+		if err != nil {
+			return err
+		}
+		if next <= last {
+			var sendErr error
+			err = s.source.FetchTxs(ctx, next, last, func(tx *rpctypes.ResultTx) {
+				if sendErr == nil {
+					sendErr = s.sendTxEvents(stream, tx, types, restricted)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			if sendErr != nil {
+				return sendErr
+			}
+			next = last + 1
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+func (s *Server) sendTxEvents(
+	stream EventStream_SubscribeEventsServer, tx *rpctypes.ResultTx, types map[string]bool, restricted bool,
+) error {
+	for _, abciEvent := range tx.TxResult.GetEvents() {
+		if len(types) > 0 && !types[abciEvent.Type] {
+			continue
+		}
+		if restricted && restrictedEventTypes[abciEvent.Type] {
+			continue
+		}
+		ev, err := shutterevents.MakeEvent(abciEvent, tx.Height)
+		if err != nil {
+			log.Printf("Error: malformed event: %+v ev=%+v", err, abciEvent)
+			continue
+		}
+		encoded, err := EventJSON(ev)
+		if err != nil {
+			return err
+		}
+		err = stream.Send(&Event{
+			Height: tx.Height,
+			TxHash: tx.Hash,
+			Type:   abciEvent.Type,
+			Json:   encoded,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EventJSON encodes the given event as JSON object. Byte strings, curve points, and public keys
+// are encoded as hex strings.
+func EventJSON(ev shutterevents.IEvent) (string, error) {
+	v := reflect.Indirect(reflect.ValueOf(ev))
+	fields := make(map[string]interface{})
+	for i := 0; i < v.NumField(); i++ {
+		fields[v.Type().Field(i).Name] = jsonValue(v.Field(i).Interface())
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to encode %T", ev)
+	}
+	return string(data), nil
+}
+
+func jsonValue(x interface{}) interface{} {
+	switch v := x.(type) {
+	case []byte:
+		return hex.EncodeToString(v)
+	case [][]byte:
+		res := []string{}
+		for _, b := range v {
+			res = append(res, hex.EncodeToString(b))
+		}
+		return res
+	case *shcrypto.Gammas:
+		if v == nil {
+			return nil
+		}
+		var res []string
+		for _, g := range *v {
+			res = append(res, hex.EncodeToString(g.Marshal()))
+		}
+		return res
+	case *shcrypto.EpochSecretKeyShare:
+		if v == nil {
+			return nil
+		}
+		return hex.EncodeToString((*bn256.G1)(v).Marshal())
+	case *ecies.PublicKey:
+		if v == nil {
+			return nil
+		}
+		return hex.EncodeToString(ethcrypto.FromECDSAPub(v.ExportECDSA()))
+	default:
+		return x
+	}
+}
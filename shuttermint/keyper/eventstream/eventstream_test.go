@@ -0,0 +1,212 @@
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents/evtype"
+)
+
+// fakeSource serves transactions added with addTx.
+type fakeSource struct {
+	mux  sync.Mutex
+	last int64
+	txs  []*rpctypes.ResultTx
+}
+
+func (s *fakeSource) addTx(height int64, evs ...shutterevents.IEvent) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	tx := &rpctypes.ResultTx{Height: height, Hash: []byte{byte(height)}}
+	for _, ev := range evs {
+		tx.TxResult.Events = append(tx.TxResult.Events, ev.MakeABCIEvent())
+	}
+	s.txs = append(s.txs, tx)
+	s.last = height
+}
+
+func (s *fakeSource) LastHeight(ctx context.Context) (int64, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.last, nil
+}
+
+func (s *fakeSource) FetchTxs(ctx context.Context, from, to int64, handler func(tx *rpctypes.ResultTx)) error {
+	s.mux.Lock()
+	txs := s.txs
+	s.mux.Unlock()
+	for _, tx := range txs {
+		if tx.Height >= from && tx.Height <= to {
+			handler(tx)
+		}
+	}
+	return nil
+}
+
+func TestEventJSON(t *testing.T) {
+	gammas := shcrypto.Gammas{new(bn256.G2).ScalarBaseMult(big.NewInt(1))}
+	encoded, err := EventJSON(&shutterevents.PolyCommitment{
+		Height: 5,
+		Eon:    2,
+		Sender: common.HexToAddress("0x1"),
+		Gammas: &gammas,
+	})
+	assert.NilError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NilError(t, json.Unmarshal([]byte(encoded), &decoded))
+	assert.Equal(t, decoded["Eon"], float64(2))
+	assert.Equal(t, decoded["Sender"], "0x0000000000000000000000000000000000000001")
+	assert.DeepEqual(t, decoded["Gammas"], []interface{}{common.Bytes2Hex(gammas[0].Marshal())})
+}
+
+// startServer serves the events of the given source and returns a client connected to it.
+func startServer(t *testing.T, source Source, checker *access.Checker, namespace string) (EventStreamClient, func()) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	server := NewServer(source)
+	server.pollInterval = 10 * time.Millisecond
+	server.SetAccess(checker)
+	server.SetNamespace(namespace)
+	server.Register(gs)
+	go gs.Serve(listener)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	assert.NilError(t, err)
+	return NewEventStreamClient(conn), func() {
+		conn.Close()
+		gs.Stop()
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	source := &fakeSource{}
+	source.addTx(1, &shutterevents.EonStarted{Eon: 1, BatchIndex: 10})
+	source.addTx(2,
+		&shutterevents.Accusation{Eon: 1, Sender: common.HexToAddress("0x1")},
+		&shutterevents.DecryptionSignature{BatchIndex: 3, Signature: []byte{1, 2}},
+	)
+
+	client, stop := startServer(t, source, nil, "")
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.SubscribeEvents(ctx, &SubscribeEventsRequest{
+		StartHeight: 1,
+		Types:       []string{evtype.EonStarted, evtype.Accusation},
+	})
+	assert.NilError(t, err)
+
+	ev, err := stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, ev.Height, int64(1))
+	assert.Equal(t, ev.Type, evtype.EonStarted)
+	assert.DeepEqual(t, ev.TxHash, []byte{1})
+
+	ev, err = stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, ev.Height, int64(2))
+	assert.Equal(t, ev.Type, evtype.Accusation)
+
+	// events from new blocks are streamed as well
+	source.addTx(3, &shutterevents.EonStarted{Eon: 2, BatchIndex: 20})
+	ev, err = stream.Recv()
+	assert.NilError(t, err)
+	assert.Equal(t, ev.Height, int64(3))
+	assert.Assert(t, ev.Json != "")
+}
+
+func TestSubscribeEventsClosed(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	keyper := ethcrypto.PubkeyToAddress(key.PublicKey)
+
+	source := &fakeSource{}
+	source.addTx(1,
+		&shutterevents.PolyEval{Eon: 1, Sender: keyper},
+		&shutterevents.EonStarted{Eon: 1, BatchIndex: 10},
+	)
+	audience := access.Audience(access.EventStreamAPI, keyper)
+	checker := access.NewChecker(access.Closed, audience, func(addr common.Address) bool { return addr == keyper })
+	client, stop := startServer(t, source, checker, "")
+	defer stop()
+
+	subscribe := func(ctx context.Context) *Event {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		stream, err := client.SubscribeEvents(ctx, &SubscribeEventsRequest{StartHeight: 1})
+		assert.NilError(t, err)
+		ev, err := stream.Recv()
+		assert.NilError(t, err)
+		return ev
+	}
+
+	// poly evals are withheld from anonymous clients
+	ev := subscribe(context.Background())
+	assert.Equal(t, ev.Type, evtype.EonStarted)
+
+	token, err := access.MakeToken(key, audience, time.Now())
+	assert.NilError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", access.Authorization(token))
+	ev = subscribe(ctx)
+	assert.Equal(t, ev.Type, evtype.PolyEval)
+}
+
+func TestSubscribeEventsNamespace(t *testing.T) {
+	source := &fakeSource{}
+	source.addTx(1, &shutterevents.EonStarted{Eon: 1, BatchIndex: 10})
+	client, stop := startServer(t, source, nil, "production")
+	defer stop()
+
+	subscribe := func(namespaces ...string) (*Event, metadata.MD, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		for _, ns := range namespaces {
+			ctx = metadata.AppendToOutgoingContext(ctx, NamespaceKey, ns)
+		}
+		stream, err := client.SubscribeEvents(ctx, &SubscribeEventsRequest{StartHeight: 1})
+		assert.NilError(t, err)
+		ev, err := stream.Recv()
+		if err != nil {
+			return nil, nil, err
+		}
+		header, err := stream.Header()
+		assert.NilError(t, err)
+		return ev, header, nil
+	}
+
+	ev, header, err := subscribe("production")
+	assert.NilError(t, err)
+	assert.Equal(t, ev.Type, evtype.EonStarted)
+	assert.DeepEqual(t, header.Get(NamespaceKey), []string{"production"})
+
+	for _, namespaces := range [][]string{nil, {"test"}, {"production", "test"}} {
+		_, _, err = subscribe(namespaces...)
+		assert.Equal(t, status.Code(err), codes.NotFound, namespaces)
+	}
+}
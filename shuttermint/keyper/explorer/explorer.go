@@ -0,0 +1,305 @@
+// Package explorer serves information about batches correlated across the main chain and
+// shuttermint as JSON over HTTP: the submitted transactions, the release of the epoch key, and
+// the execution on the main chain, including skipped executions and accusations. All data is
+// taken from the keyper's observers, so only batches the keyper still keeps track of can be
+// explored.
+package explorer
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// defaultNumBatches is the number of most recent batches listed if no range is given.
+const defaultNumBatches = 20
+
+// Batch is the information about a single batch.
+type Batch struct {
+	BatchIndex  uint64
+	ConfigIndex uint64
+	StartBlock  uint64
+	EndBlock    uint64
+
+	NumCipherTransactions int
+	CipherBatchHash       common.Hash
+	NumPlainTransactions  int
+	PlainBatchHash        common.Hash
+
+	Key       KeyRelease
+	Execution Execution
+
+	NumDecryptionSignatures int
+	Accusations             []Accusation
+}
+
+// KeyRelease describes the release of the epoch secret key of a batch on shuttermint.
+type KeyRelease struct {
+	Eon            uint64
+	Threshold      uint64
+	NumShares      int
+	FirstShare     int64 // shuttermint height of the first key share, 0 if none
+	ReleaseHeight  int64 // shuttermint height at which the threshold has been reached, 0 if not yet
+	SharesReceived []common.Address
+}
+
+// Execution describes the execution of a batch on the main chain.
+type Execution struct {
+	CipherHalfStepDone bool
+	PlainHalfStepDone  bool
+	Skipped            bool // the cipher half step has been done without executing the transactions
+	Executor           common.Address
+	ExecutedBatchHash  hexutil.Bytes `json:",omitempty"`
+}
+
+// Accusation is an accusation against the executor of one of the batch's half steps.
+type Accusation struct {
+	HalfStep    uint64
+	Executor    common.Address
+	Accuser     common.Address
+	Appealed    bool
+	BlockNumber uint64
+}
+
+// SenderUsage is a sender's usage of the shuttermint chain, see observe.Usage.
+type SenderUsage struct {
+	Sender common.Address
+	Keyper bool                 // the sender is a keyper in one of the batch configs
+	Recent observe.MessageUsage // in the last observe.UsageWindow blocks
+	Total  observe.MessageUsage
+}
+
+// Usage is the usage of the shuttermint chain per sender.
+type Usage struct {
+	Height  int64 // the last shuttermint block accounted for
+	Window  int64 // the number of blocks Recent covers
+	Senders []SenderUsage
+}
+
+// Server serves the explorer API.
+type Server struct {
+	world func() observe.World
+}
+
+// NewServer creates a new server exploring the world returned by the given function.
+func NewServer(world func() observe.World) *Server {
+	return &Server{world: world}
+}
+
+// Mount registers the API's endpoints on the given server. It serves a list of batches at
+// /batches, optionally restricted with the query parameters from and to, a single batch at
+// /batches/<index>, and the usage of the shuttermint chain per sender at /usage.
+func (s *Server) Mount(srv *httpapi.Server) {
+	srv.HandleFunc("/batches", s.handleBatches)
+	srv.HandleFunc("/batches/", s.handleBatch)
+	srv.HandleFunc("/usage", s.handleUsage)
+}
+
+// Handler returns an http handler serving only this API.
+func (s *Server) Handler() http.Handler {
+	srv := httpapi.NewServer()
+	s.Mount(srv)
+	return srv.Handler()
+}
+
+func (s *Server) handleBatches(w http.ResponseWriter, r *http.Request) {
+	world := s.world()
+	batchIndices := knownBatchIndices(world)
+	if len(batchIndices) == 0 {
+		httpapi.WriteJSON(w, []Batch{})
+		return
+	}
+
+	to := batchIndices[len(batchIndices)-1]
+	from := uint64(0)
+	if to >= defaultNumBatches {
+		from = to - defaultNumBatches + 1
+	}
+	var err error
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, "invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+
+	batches := []Batch{}
+	for _, batchIndex := range batchIndices {
+		if batchIndex < from || batchIndex > to {
+			continue
+		}
+		if b, ok := MakeBatch(world, batchIndex); ok {
+			batches = append(batches, b)
+		}
+	}
+	httpapi.WriteJSON(w, batches)
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	batchIndex, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/batches/"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid batch index", http.StatusBadRequest)
+		return
+	}
+	b, ok := MakeBatch(s.world(), batchIndex)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	httpapi.WriteJSON(w, b)
+}
+
+// knownBatchIndices returns the sorted indices of the batches we have any information about.
+func knownBatchIndices(world observe.World) []uint64 {
+	seen := make(map[uint64]bool)
+	for batchIndex := range world.MainChain.Batches {
+		seen[batchIndex] = true
+	}
+	for batchIndex := range world.Shutter.Batches {
+		seen[batchIndex] = true
+	}
+	for halfStep := uint64(0); halfStep < world.MainChain.NumExecutionHalfSteps; halfStep += 2 {
+		seen[uint64(protocol.HalfStep(halfStep).BatchIndex())] = true
+	}
+	res := []uint64{}
+	for batchIndex := range seen {
+		res = append(res, batchIndex)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// MakeBatch collects the information about the given batch. It returns false if the batch is
+// not covered by any batch config.
+func MakeBatch(world observe.World, batchIndex uint64) (Batch, bool) {
+	mainChain := world.MainChain
+	if len(mainChain.BatchConfigs) == 0 {
+		return Batch{}, false
+	}
+	configIndex, ok := mainChain.ConfigIndexForBatchIndex(batchIndex)
+	if !ok {
+		return Batch{}, false
+	}
+	config := mainChain.BatchConfigs[configIndex]
+	b := Batch{
+		BatchIndex:  batchIndex,
+		ConfigIndex: uint64(configIndex),
+		StartBlock:  config.BatchStartBlock(batchIndex),
+		EndBlock:    config.BatchEndBlock(batchIndex),
+	}
+
+	if batch, ok := mainChain.Batches[batchIndex]; ok {
+		b.NumCipherTransactions = len(batch.EncryptedTransactions)
+		b.CipherBatchHash = batch.EncryptedBatchHash
+		b.NumPlainTransactions = len(batch.PlainTransactions)
+		b.PlainBatchHash = batch.PlainBatchHash
+	}
+	if batch, ok := world.Shutter.Batches[batchIndex]; ok {
+		b.NumDecryptionSignatures = len(batch.DecryptionSignatures)
+	}
+	b.Key = makeKeyRelease(world.Shutter, batchIndex)
+	b.Execution = makeExecution(mainChain, batchIndex)
+	index := protocol.BatchIndex(batchIndex)
+	for _, halfStep := range []uint64{uint64(index.CipherHalfStep()), uint64(index.PlainHalfStep())} {
+		if acc, ok := mainChain.Accusations[halfStep]; ok {
+			b.Accusations = append(b.Accusations, Accusation{
+				HalfStep:    halfStep,
+				Executor:    acc.Executor,
+				Accuser:     acc.Accuser,
+				Appealed:    acc.Appealed,
+				BlockNumber: acc.BlockNumber,
+			})
+		}
+	}
+	return b, true
+}
+
+func makeKeyRelease(shutter *observe.Shutter, batchIndex uint64) KeyRelease {
+	batchConfig := shutter.FindBatchConfigByBatchIndex(batchIndex)
+	kr := KeyRelease{
+		Threshold: batchConfig.Threshold,
+	}
+	eon, err := shutter.FindEonByBatchIndex(batchIndex)
+	if err != nil {
+		return kr
+	}
+	kr.Eon = eon.Eon
+	epoch := batchConfig.Epoch(batchIndex)
+	senders := make(map[common.Address]bool)
+	for _, share := range eon.EpochSecretKeyShares {
+		if share.Epoch != epoch || share.Namespace != "" || senders[share.Sender] {
+			continue
+		}
+		senders[share.Sender] = true
+		kr.SharesReceived = append(kr.SharesReceived, share.Sender)
+		kr.NumShares++
+		if kr.FirstShare == 0 {
+			kr.FirstShare = share.Height
+		}
+		if kr.ReleaseHeight == 0 && kr.Threshold > 0 && uint64(kr.NumShares) >= kr.Threshold {
+			kr.ReleaseHeight = share.Height
+		}
+	}
+	return kr
+}
+
+func makeExecution(mainChain *observe.MainChain, batchIndex uint64) Execution {
+	cipherHalfStep := uint64(protocol.BatchIndex(batchIndex).CipherHalfStep())
+	e := Execution{
+		CipherHalfStepDone: mainChain.NumExecutionHalfSteps > cipherHalfStep,
+		PlainHalfStepDone:  mainChain.NumExecutionHalfSteps > cipherHalfStep+1,
+	}
+	if receipt, ok := mainChain.CipherExecutionReceipts[cipherHalfStep]; ok {
+		e.Skipped = !receipt.Executed
+		e.Executor = receipt.Executor
+		if receipt.Executed {
+			e.ExecutedBatchHash = receipt.BatchHash[:]
+		}
+	}
+	return e
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, _ *http.Request) {
+	httpapi.WriteJSON(w, MakeUsage(s.world().Shutter))
+}
+
+// MakeUsage collects the usage of the shuttermint chain per sender, ordered by the number of
+// bytes sent recently, largest first.
+func MakeUsage(shutter *observe.Shutter) Usage {
+	u := Usage{Height: shutter.CurrentBlock, Window: observe.UsageWindow, Senders: []SenderUsage{}}
+	if shutter.Usage == nil {
+		return u
+	}
+	recent := shutter.Usage.Recent(shutter.CurrentBlock)
+	for sender, total := range shutter.Usage.Total {
+		u.Senders = append(u.Senders, SenderUsage{
+			Sender: sender,
+			Keyper: shutter.IsKeyper(sender),
+			Recent: recent[sender],
+			Total:  total,
+		})
+	}
+	sort.Slice(u.Senders, func(i, j int) bool {
+		a, b := u.Senders[i], u.Senders[j]
+		if a.Recent.Bytes != b.Recent.Bytes {
+			return a.Recent.Bytes > b.Recent.Bytes
+		}
+		return bytes.Compare(a.Sender[:], b.Sender[:]) < 0
+	})
+	return u
+}
@@ -0,0 +1,150 @@
+package explorer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+var keypers = []common.Address{
+	common.HexToAddress("0x1"),
+	common.HexToAddress("0x2"),
+	common.HexToAddress("0x3"),
+}
+
+func testWorld() observe.World {
+	mainChain := observe.NewMainChain(0)
+	mainChain.BatchConfigs = []contract.BatchConfig{
+		{},
+		{StartBatchIndex: 0, StartBlockNumber: 100, BatchSpan: 10, Keypers: keypers, Threshold: 2},
+	}
+	mainChain.Batches[3] = &observe.Batch{
+		BatchIndex:            3,
+		EncryptedBatchHash:    common.HexToHash("0xaa"),
+		EncryptedTransactions: [][]byte{{1}, {2}},
+	}
+	mainChain.NumExecutionHalfSteps = 8
+	mainChain.CipherExecutionReceipts[2] = &contract.CipherExecutionReceipt{HalfStep: 2, Executor: keypers[0]}
+	mainChain.CipherExecutionReceipts[6] = &contract.CipherExecutionReceipt{
+		Executed: true, HalfStep: 6, Executor: keypers[1], BatchHash: common.HexToHash("0xbb"),
+	}
+	mainChain.Accusations[2] = &observe.Accusation{HalfStep: 2, Executor: keypers[0], Accuser: keypers[2]}
+
+	shutter := observe.NewShutter()
+	shutter.BatchConfigs = []shutterevents.BatchConfig{{ConfigIndex: 1, Keypers: keypers, Threshold: 2}}
+	shutter.Eons = []observe.Eon{{
+		Eon: 1,
+		EpochSecretKeyShares: []shutterevents.EpochSecretKeyShare{
+			{Height: 50, Sender: keypers[0], Eon: 1, Epoch: 3},
+			{Height: 51, Sender: keypers[0], Eon: 1, Epoch: 3},
+			{Height: 52, Sender: keypers[1], Eon: 1, Epoch: 3, Namespace: "other"},
+			{Height: 53, Sender: keypers[2], Eon: 1, Epoch: 3},
+			{Height: 54, Sender: keypers[1], Eon: 1, Epoch: 4},
+		},
+	}}
+	shutter.Batches[3] = &observe.BatchData{
+		BatchIndex:           3,
+		DecryptionSignatures: []shutterevents.DecryptionSignature{{BatchIndex: 3}},
+	}
+	return observe.World{Shutter: shutter, MainChain: mainChain}
+}
+
+func TestMakeBatch(t *testing.T) {
+	world := testWorld()
+
+	b, ok := MakeBatch(world, 3)
+	assert.Assert(t, ok)
+	assert.Equal(t, b.ConfigIndex, uint64(1))
+	assert.Equal(t, b.StartBlock, uint64(130))
+	assert.Equal(t, b.EndBlock, uint64(140))
+	assert.Equal(t, b.NumCipherTransactions, 2)
+	assert.Equal(t, b.CipherBatchHash, common.HexToHash("0xaa"))
+	assert.Equal(t, b.NumDecryptionSignatures, 1)
+	assert.DeepEqual(t, b.Key, KeyRelease{
+		Eon:            1,
+		Threshold:      2,
+		NumShares:      2,
+		FirstShare:     50,
+		ReleaseHeight:  53,
+		SharesReceived: []common.Address{keypers[0], keypers[2]},
+	})
+	assert.Assert(t, b.Execution.CipherHalfStepDone && b.Execution.PlainHalfStepDone)
+	assert.Assert(t, !b.Execution.Skipped)
+	assert.Equal(t, b.Execution.Executor, keypers[1])
+
+	b, ok = MakeBatch(world, 1)
+	assert.Assert(t, ok)
+	assert.Assert(t, b.Execution.Skipped)
+	assert.Equal(t, len(b.Accusations), 1)
+	assert.Equal(t, b.Accusations[0].Accuser, keypers[2])
+	assert.Equal(t, b.Key.ReleaseHeight, int64(0))
+}
+
+func TestHandler(t *testing.T) {
+	world := testWorld()
+	handler := NewServer(func() observe.World { return world }).Handler()
+
+	get := func(path string, v interface{}) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code == http.StatusOK {
+			assert.NilError(t, json.NewDecoder(rec.Body).Decode(v))
+		}
+		return rec.Code
+	}
+
+	var batches []Batch
+	assert.Equal(t, get("/batches", &batches), http.StatusOK)
+	assert.Equal(t, len(batches), 4)
+	assert.Equal(t, batches[0].BatchIndex, uint64(0))
+	assert.Equal(t, batches[3].BatchIndex, uint64(3))
+
+	assert.Equal(t, get("/batches?from=2&to=2", &batches), http.StatusOK)
+	assert.Equal(t, len(batches), 1)
+	assert.Equal(t, batches[0].BatchIndex, uint64(2))
+
+	var b Batch
+	assert.Equal(t, get("/batches/3", &b), http.StatusOK)
+	assert.Equal(t, b.Key.ReleaseHeight, int64(53))
+	assert.Equal(t, get("/batches/x", &b), http.StatusBadRequest)
+	assert.Equal(t, get("/batches?from=x", &batches), http.StatusBadRequest)
+
+	var u Usage
+	assert.Equal(t, get("/usage", &u), http.StatusOK)
+	assert.Equal(t, len(u.Senders), 0)
+}
+
+func TestMakeUsage(t *testing.T) {
+	shutter := testWorld().Shutter
+	shutter.CurrentBlock = 60
+	other := common.HexToAddress("0x4")
+	shutter.Usage = &observe.Usage{
+		Total: map[common.Address]observe.MessageUsage{
+			keypers[0]: {Messages: 10, Bytes: 1000},
+			keypers[1]: {Messages: 2, Bytes: 200},
+			other:      {Messages: 1, Bytes: 500},
+		},
+		Blocks: []observe.BlockUsage{
+			{Height: 50, Senders: map[common.Address]observe.MessageUsage{
+				keypers[1]: {Messages: 2, Bytes: 200},
+				other:      {Messages: 1, Bytes: 500},
+			}},
+		},
+	}
+
+	u := MakeUsage(shutter)
+	assert.Equal(t, u.Height, int64(60))
+	assert.DeepEqual(t, u.Senders, []SenderUsage{
+		{Sender: other, Recent: observe.MessageUsage{Messages: 1, Bytes: 500}, Total: observe.MessageUsage{Messages: 1, Bytes: 500}},
+		{Sender: keypers[1], Keyper: true, Recent: observe.MessageUsage{Messages: 2, Bytes: 200}, Total: observe.MessageUsage{Messages: 2, Bytes: 200}},
+		{Sender: keypers[0], Keyper: true, Total: observe.MessageUsage{Messages: 10, Bytes: 1000}},
+	})
+}
@@ -7,10 +7,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/core/types"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
 
 	"github.com/shutter-network/shutter/shlib/shcrypto"
 	"github.com/shutter-network/shutter/shuttermint/contract"
 	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
 	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
 
@@ -19,6 +22,7 @@ const (
 	executeCipherBatchBaseLimit = uint64(250_000)
 	executePlainBatchBaseLimit  = uint64(250_000) // XXX check if we can lower that value
 	skipCipherExecutionLimit    = uint64(200_000)
+	submitEpochKeyGasLimit      = uint64(200_000)
 )
 
 // IAction describes an action to run as determined by the Decider's Decide method.
@@ -43,18 +47,23 @@ var (
 	_ MainChainTX = Accuse{}
 	_ MainChainTX = Appeal{}
 	_ MainChainTX = EonKeyBroadcast{}
+	_ MainChainTX = SubmitEpochKey{}
 )
 
+// allActions contains a value of every action type.
+var allActions = []IAction{
+	&SendShuttermintMessage{},
+	&ExecuteCipherBatch{},
+	&ExecutePlainBatch{},
+	&SkipCipherBatch{},
+	&Accuse{},
+	&Appeal{},
+	&EonKeyBroadcast{},
+	&SubmitEpochKey{},
+}
+
 func init() {
-	for _, a := range []IAction{
-		&SendShuttermintMessage{},
-		&ExecuteCipherBatch{},
-		&ExecutePlainBatch{},
-		&SkipCipherBatch{},
-		&Accuse{},
-		&Appeal{},
-		&EonKeyBroadcast{},
-	} {
+	for _, a := range allActions {
 		gob.Register(a)
 	}
 }
@@ -63,6 +72,7 @@ func init() {
 type SendShuttermintMessage struct {
 	Description string
 	Msg         *shmsg.Message
+	Priority    bool // start before other actions waiting to be run, e.g. close to a deadline
 }
 
 func (a SendShuttermintMessage) String() string {
@@ -88,7 +98,7 @@ func (a ExecuteCipherBatch) gasLimit() uint64 {
 
 func (a ExecuteCipherBatch) SendTX(caller *contract.Caller, auth *bind.TransactOpts) (*types.Transaction, error) {
 	auth.GasLimit = a.gasLimit()
-	return caller.ExecutorContract.ExecuteCipherBatch(
+	return caller.ExecutorFor(a.BatchIndex).ExecuteCipherBatch(
 		auth, a.BatchIndex, a.CipherBatchHash, a.Transactions, a.KeyperIndex,
 	)
 }
@@ -98,8 +108,8 @@ func (a ExecuteCipherBatch) String() string {
 }
 
 func (a ExecuteCipherBatch) IsExpired(world observe.World) bool {
-	halfStep := 2 * a.BatchIndex
-	return world.MainChain.NumExecutionHalfSteps > halfStep
+	halfStep := protocol.BatchIndex(a.BatchIndex).CipherHalfStep()
+	return world.MainChain.NumExecutionHalfSteps > uint64(halfStep)
 }
 
 // ExecutePlainBatch is an Action that instructs the executor contract to execute a plain batch.
@@ -115,7 +125,7 @@ func (a ExecutePlainBatch) gasLimit() uint64 {
 
 func (a ExecutePlainBatch) SendTX(caller *contract.Caller, auth *bind.TransactOpts) (*types.Transaction, error) {
 	auth.GasLimit = a.gasLimit()
-	return caller.ExecutorContract.ExecutePlainBatch(auth, a.BatchIndex, a.Transactions)
+	return caller.ExecutorFor(a.BatchIndex).ExecutePlainBatch(auth, a.BatchIndex, a.Transactions)
 }
 
 func (a ExecutePlainBatch) String() string {
@@ -123,8 +133,8 @@ func (a ExecutePlainBatch) String() string {
 }
 
 func (a ExecutePlainBatch) IsExpired(world observe.World) bool {
-	halfStep := 2*a.BatchIndex + 1
-	return world.MainChain.NumExecutionHalfSteps > halfStep
+	halfStep := protocol.BatchIndex(a.BatchIndex).PlainHalfStep()
+	return world.MainChain.NumExecutionHalfSteps > uint64(halfStep)
 }
 
 // SkipCipherBatch is an Action that instructs the executor contract to skip a cipher batch.
@@ -134,7 +144,7 @@ type SkipCipherBatch struct {
 
 func (a SkipCipherBatch) SendTX(caller *contract.Caller, auth *bind.TransactOpts) (*types.Transaction, error) {
 	auth.GasLimit = skipCipherExecutionLimit
-	return caller.ExecutorContract.SkipCipherExecution(auth, a.BatchIndex)
+	return caller.ExecutorFor(a.BatchIndex).SkipCipherExecution(auth, a.BatchIndex)
 }
 
 func (a SkipCipherBatch) String() string {
@@ -142,8 +152,8 @@ func (a SkipCipherBatch) String() string {
 }
 
 func (a SkipCipherBatch) IsExpired(world observe.World) bool {
-	halfStep := 2 * a.BatchIndex
-	return world.MainChain.NumExecutionHalfSteps > halfStep
+	halfStep := protocol.BatchIndex(a.BatchIndex).CipherHalfStep()
+	return world.MainChain.NumExecutionHalfSteps > uint64(halfStep)
 }
 
 // Accuse is an action accusing the executor of a given half step at the keyper slasher.
@@ -238,3 +248,43 @@ func (a EonKeyBroadcast) String() string {
 func (a EonKeyBroadcast) IsExpired(world observe.World) bool {
 	return false
 }
+
+// SubmitEpochKey is an action submitting an epoch secret key to the decryption oracle contract.
+type SubmitEpochKey struct {
+	KeyperIndex        uint64
+	BatchIndex         uint64
+	EonStartBatchIndex uint64
+	Key                *shcrypto.EpochSecretKey
+}
+
+func (a SubmitEpochKey) SendTX(caller *contract.Caller, auth *bind.TransactOpts) (*types.Transaction, error) {
+	// Another keyper may have been faster, in which case there's nothing left to do
+	submitted, err := caller.HasEpochKey(&bind.CallOpts{Context: auth.Context}, a.BatchIndex)
+	if err != nil {
+		return nil, err
+	}
+	if submitted {
+		return nil, &NonRetriableError{Err: errors.Errorf("epoch key for batch %d already submitted", a.BatchIndex)}
+	}
+
+	auth.GasLimit = submitEpochKeyGasLimit
+	tx, err := caller.DecryptionOracleContract.SubmitEpochKey(
+		auth,
+		a.KeyperIndex,
+		a.BatchIndex,
+		a.EonStartBatchIndex,
+		(*bn256.G1)(a.Key).Marshal(),
+	)
+	if errorMsgContains(err, []string{"key already submitted"}) {
+		err = &NonRetriableError{Err: err}
+	}
+	return tx, err
+}
+
+func (a SubmitEpochKey) String() string {
+	return fmt.Sprintf("=> decryption oracle contract: submit epoch key for batch %d", a.BatchIndex)
+}
+
+func (a SubmitEpochKey) IsExpired(world observe.World) bool {
+	return false
+}
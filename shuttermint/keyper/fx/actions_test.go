@@ -32,6 +32,12 @@ func sendShuttermintMessage() *SendShuttermintMessage {
 		55,
 		false,
 		false,
+		nil,
+		false,
+		0,
+		0,
+		0,
+		0,
 	)
 	return &SendShuttermintMessage{
 		Description: "foo bar baz",
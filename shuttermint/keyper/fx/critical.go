@@ -0,0 +1,67 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/pkg/errors"
+)
+
+// maxCriticalAttempts is the number of transactions we send for a critical action before giving
+// up on it.
+const maxCriticalAttempts = 5
+
+// criticalKey returns the key of critical actions and the empty string for other actions.
+// Critical actions are main chain transactions the decider derives only once, or only again
+// after a long timeout, like accusations and appeals: once the observation window has moved on,
+// they're lost if they don't make it into the chain. They stay pending until their transaction
+// succeeds or they expire, and are sent again if their transaction fails, i.e. they are executed
+// at least once. Pending actions with the same key are the same action, so that actions the
+// decider derives again after a restart or a timeout are not added a second time.
+func criticalKey(action IAction) string {
+	switch a := action.(type) {
+	case *Accuse:
+		return criticalKey(*a)
+	case Accuse:
+		return fmt.Sprintf("accuse/%d", a.HalfStep)
+	case *Appeal:
+		return criticalKey(*a)
+	case Appeal:
+		return fmt.Sprintf("appeal/%d", a.Authorization.HalfStep)
+	default:
+		return ""
+	}
+}
+
+// retryCritical sends the transaction of a critical action again if it hasn't been confirmed. It
+// returns false if the action isn't critical, has expired or if we've given up on it, in which
+// case the caller removes it.
+func (runenv *RunEnv) retryCritical(id ActionID) bool {
+	act := runenv.PendingActions.GetAction(id)
+	if act == nil || criticalKey(act) == "" || act.IsExpired(runenv.CurrentWorld()) {
+		return false
+	}
+	attempts := runenv.PendingActions.RetryMainChainTX(id)
+	if attempts >= maxCriticalAttempts {
+		log.Printf("Error: giving up on critical action id=%d after %d transactions: %s", id, attempts, act)
+		runenv.addDeadLetter(id, act, errors.Errorf("no transaction confirmed after %d attempts", attempts))
+		return false
+	}
+	log.Printf("Critical action not confirmed, sending it again: id=%d, attempt=%d, %s", id, attempts+1, act)
+	runenv.executor.schedule(id, act)
+	return true
+}
+
+// finishInFlightTX removes the action of an in-flight transaction we've waited for, unless it's a
+// critical action whose transaction hasn't been confirmed. If ctx has been canceled, the action is
+// kept, so that we wait for the transaction again after a restart, see Load.
+func (runenv *RunEnv) finishInFlightTX(ctx context.Context, id ActionID, confirmed bool) {
+	if ctx.Err() != nil {
+		return
+	}
+	if !confirmed && runenv.retryCritical(id) {
+		return
+	}
+	runenv.PendingActions.RemoveAction(id)
+}
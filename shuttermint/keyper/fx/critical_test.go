@@ -0,0 +1,44 @@
+package fx
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestCriticalKey(t *testing.T) {
+	assert.Equal(t, criticalKey(&Accuse{HalfStep: 2, KeyperIndex: 1}), criticalKey(Accuse{HalfStep: 2}))
+	assert.Assert(t, criticalKey(&Accuse{HalfStep: 2}) != criticalKey(&Appeal{}))
+	assert.Equal(t, criticalKey(&ExecuteCipherBatch{}), "")
+	assert.Equal(t, criticalKey(&SendShuttermintMessage{}), "")
+}
+
+func TestRetryCritical(t *testing.T) {
+	mainChain := observe.NewMainChain(0)
+	dir := t.TempDir()
+	runenv := &RunEnv{
+		PendingActions: NewPendingActions(filepath.Join(dir, "actions.gob")),
+		DeadLetters:    NewDeadLetters(filepath.Join(dir, "dead-actions.gob")),
+		currentWorld:   func() observe.World { return observe.World{MainChain: mainChain} },
+	}
+	runenv.executor = newExecutor(nil)
+	runenv.PendingActions.AddActions(0, []IAction{&Accuse{HalfStep: 2}, &ExecuteCipherBatch{BatchIndex: 1}})
+
+	assert.Assert(t, !runenv.retryCritical(1), "only critical actions are retried")
+	for i := 1; i < maxCriticalAttempts; i++ {
+		assert.Assert(t, runenv.retryCritical(0))
+	}
+	assert.Equal(t, len(runenv.executor.queue), maxCriticalAttempts-1)
+	assert.Equal(t, len(runenv.DeadLetters.Letters), 0)
+	assert.Assert(t, !runenv.retryCritical(0), "we give up eventually")
+	assert.Equal(t, len(runenv.DeadLetters.Letters), 1)
+	assert.Equal(t, runenv.DeadLetters.Letters[0].ID, ActionID(0))
+
+	runenv.PendingActions.AddActions(2, []IAction{&Accuse{HalfStep: 4}})
+	mainChain.Accusations[4] = &observe.Accusation{}
+	assert.Assert(t, !runenv.retryCritical(2), "expired actions are not retried")
+	assert.Equal(t, len(runenv.DeadLetters.Letters), 1)
+}
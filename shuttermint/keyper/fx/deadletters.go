@@ -0,0 +1,100 @@
+package fx
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxDeadLetters is the number of dead letters kept, older ones are dropped.
+const maxDeadLetters = 1000
+
+// DeadLetter is an action we've given up on, because it failed with a non-retriable error or its
+// transaction didn't make it into the chain after maxCriticalAttempts attempts.
+type DeadLetter struct {
+	ID     ActionID
+	Action IAction
+	Error  string
+	Time   time.Time
+}
+
+// DeadLetterInfo describes a dead letter for humans.
+type DeadLetterInfo struct {
+	ID     ActionID  `json:"id"`
+	Action string    `json:"action"`
+	Error  string    `json:"error"`
+	Time   time.Time `json:"time"`
+}
+
+// DeadLetters keeps the most recent actions we've given up on, so that operators can find out
+// what went wrong and act on it. Like PendingActions, it is stored on disk with gob.
+type DeadLetters struct {
+	mux     sync.Mutex
+	Letters []DeadLetter
+	path    string
+}
+
+// NewDeadLetters creates an empty set of dead letters stored at path.
+func NewDeadLetters(path string) *DeadLetters {
+	return &DeadLetters{path: path}
+}
+
+// Add records that we've given up on the given action.
+func (dl *DeadLetters) Add(id ActionID, action IAction, err error) {
+	dl.mux.Lock()
+	defer dl.mux.Unlock()
+	dl.Letters = append(dl.Letters, DeadLetter{ID: id, Action: action, Error: err.Error(), Time: time.Now()})
+	if n := len(dl.Letters); n > maxDeadLetters {
+		dl.Letters = append([]DeadLetter(nil), dl.Letters[n-maxDeadLetters:]...)
+	}
+	dl.save()
+}
+
+// Info describes the dead letters, most recent first.
+func (dl *DeadLetters) Info() []DeadLetterInfo {
+	dl.mux.Lock()
+	defer dl.mux.Unlock()
+	info := make([]DeadLetterInfo, len(dl.Letters))
+	for i, l := range dl.Letters {
+		info[len(info)-1-i] = DeadLetterInfo{ID: l.ID, Action: fmt.Sprint(l.Action), Error: l.Error, Time: l.Time}
+	}
+	return info
+}
+
+// save saves the dead letters to disk. It panics if it cannot write the file to disk.
+func (dl *DeadLetters) save() {
+	tmppath := dl.path + ".tmp"
+	file, err := os.Create(tmppath)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+	err = gob.NewEncoder(file).Encode(dl)
+	if err != nil {
+		panic(err)
+	}
+	err = file.Sync()
+	if err != nil {
+		panic(err)
+	}
+	err = os.Rename(tmppath, dl.path)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Load loads the dead letters from disk.
+func (dl *DeadLetters) Load() error {
+	dl.mux.Lock()
+	defer dl.mux.Unlock()
+	file, err := os.Open(dl.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	return gob.NewDecoder(file).Decode(dl)
+}
@@ -0,0 +1,43 @@
+package fx
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestDeadLetters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-actions.gob")
+	dl := NewDeadLetters(path)
+	for i := 0; i < maxDeadLetters+2; i++ {
+		dl.Add(ActionID(i), &ExecuteCipherBatch{BatchIndex: uint64(i)}, errors.New("execution reverted"))
+	}
+	assert.Equal(t, len(dl.Letters), maxDeadLetters)
+
+	info := dl.Info()
+	assert.Equal(t, info[0].ID, ActionID(maxDeadLetters+1))
+	assert.Equal(t, info[len(info)-1].ID, ActionID(2))
+	assert.Equal(t, info[0].Error, "execution reverted")
+	assert.Equal(t, info[0].Action, (&ExecuteCipherBatch{BatchIndex: maxDeadLetters + 1}).String())
+
+	loaded := NewDeadLetters(path)
+	assert.NilError(t, loaded.Load())
+	assert.DeepEqual(t, loaded.Info(), info)
+	assert.NilError(t, NewDeadLetters(filepath.Join(t.TempDir(), "missing.gob")).Load())
+}
+
+func TestNextRetryDelay(t *testing.T) {
+	delay := minRetryDelay
+	var delays []time.Duration
+	for i := 0; i < 8; i++ {
+		delays = append(delays, delay)
+		delay = nextRetryDelay(delay)
+	}
+	assert.DeepEqual(t, delays, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second,
+		16 * time.Second, 32 * time.Second, time.Minute, time.Minute,
+	})
+}
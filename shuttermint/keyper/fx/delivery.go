@@ -0,0 +1,182 @@
+package fx
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// TxFetcher fetches the shuttermint transactions in the given range of heights, see
+// observe.FetchTxs.
+type TxFetcher func(ctx context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx)) error
+
+// sentMessage is a shuttermint message we've sent, but haven't seen in the chain yet.
+type sentMessage struct {
+	msg       *shmsg.Message
+	height    int64 // shuttermint block we've last (re-)sent the message at
+	resends   int
+	sending   bool // the message is being sent right now
+	delivered bool
+}
+
+// DeliveryTracker checks that the shuttermint messages we send end up in the chain. A message is
+// considered delivered once a successful transaction signed by us with the same content shows up
+// in a shuttermint block. Messages not delivered within a number of blocks, e.g. because they've
+// been dropped from the mempool, are due to be sent again.
+type DeliveryTracker struct {
+	Sender     common.Address // the address we sign messages with
+	Blocks     int64          // number of blocks to wait for a message before sending it again
+	MaxResends int            // number of times to send a message again before giving up
+	fetchTxs   TxFetcher
+
+	mux           sync.Mutex
+	messages      map[ActionID]*sentMessage
+	checkedHeight int64
+}
+
+// NewDeliveryTracker creates a new DeliveryTracker, which looks for our messages in the
+// transactions fetched with fetchTxs.
+func NewDeliveryTracker(sender common.Address, blocks int64, maxResends int, fetchTxs TxFetcher) *DeliveryTracker {
+	return &DeliveryTracker{
+		Sender:     sender,
+		Blocks:     blocks,
+		MaxResends: maxResends,
+		fetchTxs:   fetchTxs,
+		messages:   make(map[ActionID]*sentMessage),
+	}
+}
+
+// startSending starts tracking the message sent by the given action at the given shuttermint
+// height. It returns false if the message has already been delivered, i.e. it must not be sent
+// again.
+func (dt *DeliveryTracker) startSending(id ActionID, msg *shmsg.Message, height int64) bool {
+	dt.mux.Lock()
+	defer dt.mux.Unlock()
+	m, ok := dt.messages[id]
+	if !ok {
+		m = &sentMessage{msg: msg}
+		dt.messages[id] = m
+	}
+	if m.delivered {
+		return false
+	}
+	m.height = height
+	m.sending = true
+	return true
+}
+
+// doneSending marks the given action's message as not being sent anymore.
+func (dt *DeliveryTracker) doneSending(id ActionID) {
+	dt.mux.Lock()
+	defer dt.mux.Unlock()
+	if m, ok := dt.messages[id]; ok {
+		m.sending = false
+	}
+}
+
+// forget stops tracking the given action's message.
+func (dt *DeliveryTracker) forget(id ActionID) {
+	dt.mux.Lock()
+	defer dt.mux.Unlock()
+	delete(dt.messages, id)
+}
+
+// deliveryCheck is the result of checking the messages we've sent.
+type deliveryCheck struct {
+	delivered []ActionID // messages that made it into the chain
+	resend    []ActionID // messages that need to be sent again
+	giveUp    []ActionID // messages that haven't been delivered even though we've re-sent them
+}
+
+// check looks for our messages in the shuttermint blocks up to the given height that haven't been
+// checked before. The messages that have been delivered or that we give up on are not tracked
+// anymore, the ones to be sent again are considered sent at the given height.
+func (dt *DeliveryTracker) check(ctx context.Context, height int64) (deliveryCheck, error) {
+	dt.mux.Lock()
+	fromHeight := dt.checkedHeight + 1
+	if dt.checkedHeight == 0 || len(dt.messages) == 0 {
+		// A message sent from now on will end up in a block after the latest one we know of.
+		fromHeight = height + 1
+	}
+	dt.mux.Unlock()
+
+	var txs []*rpctypes.ResultTx
+	if fromHeight <= height {
+		err := dt.fetchTxs(ctx, fromHeight, height, func(tx *rpctypes.ResultTx) {
+			txs = append(txs, tx)
+		})
+		if err != nil {
+			return deliveryCheck{}, err
+		}
+	}
+
+	dt.mux.Lock()
+	defer dt.mux.Unlock()
+	for _, tx := range txs {
+		dt.markDelivered(tx)
+	}
+	if height > dt.checkedHeight {
+		dt.checkedHeight = height
+	}
+
+	var res deliveryCheck
+	for _, id := range dt.sortedIDs() {
+		m := dt.messages[id]
+		switch {
+		case m.sending:
+		case m.delivered:
+			res.delivered = append(res.delivered, id)
+			delete(dt.messages, id)
+		case height-m.height < dt.Blocks:
+		case m.resends >= dt.MaxResends:
+			res.giveUp = append(res.giveUp, id)
+			delete(dt.messages, id)
+		default:
+			m.resends++
+			m.height = height
+			res.resend = append(res.resend, id)
+		}
+	}
+	return res, nil
+}
+
+// markDelivered marks the messages with the same content as the given transaction as delivered
+// if the transaction has been signed by us and succeeded.
+func (dt *DeliveryTracker) markDelivered(tx *rpctypes.ResultTx) {
+	if tx.TxResult.Code != 0 {
+		return
+	}
+	signedMsg, err := base64.RawURLEncoding.DecodeString(string(tx.Tx))
+	if err != nil {
+		return
+	}
+	signer, err := shmsg.GetSigner(signedMsg)
+	if err != nil || signer != dt.Sender {
+		return
+	}
+	msgWithNonce, err := shmsg.GetMessage(signedMsg)
+	if err != nil {
+		return
+	}
+	for _, m := range dt.messages {
+		if proto.Equal(m.msg, msgWithNonce.Msg) {
+			m.delivered = true
+		}
+	}
+}
+
+func (dt *DeliveryTracker) sortedIDs() []ActionID {
+	var ids []ActionID
+	for id := range dt.messages {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
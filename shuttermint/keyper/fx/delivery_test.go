@@ -0,0 +1,113 @@
+package fx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	gocmp "github.com/google/go-cmp/cmp"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+var allowDeliveryCheck = gocmp.AllowUnexported(deliveryCheck{})
+
+// fakeChain holds the transactions returned by fetchTxs.
+type fakeChain struct {
+	txs []*rpctypes.ResultTx
+}
+
+func (c *fakeChain) add(t *testing.T, height int64, key *ecdsa.PrivateKey, msg *shmsg.Message) {
+	t.Helper()
+	signed, err := shmsg.SignMessage(&shmsg.MessageWithNonce{Msg: msg, RandomNonce: randomNonce()}, key)
+	assert.NilError(t, err)
+	c.txs = append(c.txs, &rpctypes.ResultTx{
+		Height:   height,
+		Tx:       tmtypes.Tx(base64.RawURLEncoding.EncodeToString(signed)),
+		TxResult: abcitypes.ResponseDeliverTx{Code: 0},
+	})
+}
+
+func (c *fakeChain) fetchTxs(_ context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx)) error {
+	for _, tx := range c.txs {
+		if tx.Height >= fromHeight && tx.Height <= toHeight {
+			handler(tx)
+		}
+	}
+	return nil
+}
+
+func TestDeliveryTracker(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	chain := &fakeChain{}
+	dt := NewDeliveryTracker(crypto.PubkeyToAddress(key.PublicKey), 5, 1, chain.fetchTxs)
+
+	_, err = dt.check(ctx, 10)
+	assert.NilError(t, err)
+
+	delivered := shmsg.NewEonStartVote(1)
+	lost := shmsg.NewEonStartVote(2)
+	assert.Assert(t, dt.startSending(1, delivered, 10))
+	assert.Assert(t, dt.startSending(2, lost, 10))
+	dt.doneSending(1)
+	dt.doneSending(2)
+
+	chain.add(t, 11, otherKey, lost)
+	chain.add(t, 12, key, delivered)
+	res, err := dt.check(ctx, 12)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, deliveryCheck{delivered: []ActionID{1}}, allowDeliveryCheck)
+	assert.Assert(t, dt.startSending(2, lost, 12))
+
+	res, err = dt.check(ctx, 16)
+	assert.NilError(t, err)
+	// a message being sent must not be re-sent
+	assert.DeepEqual(t, res, deliveryCheck{}, allowDeliveryCheck)
+
+	dt.doneSending(2)
+	res, err = dt.check(ctx, 17)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, deliveryCheck{resend: []ActionID{2}}, allowDeliveryCheck)
+
+	res, err = dt.check(ctx, 21)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, deliveryCheck{}, allowDeliveryCheck)
+
+	res, err = dt.check(ctx, 22)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, deliveryCheck{giveUp: []ActionID{2}}, allowDeliveryCheck)
+}
+
+func TestDeliveryTrackerDeliveredWhileSending(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	chain := &fakeChain{}
+	dt := NewDeliveryTracker(crypto.PubkeyToAddress(key.PublicKey), 5, 1, chain.fetchTxs)
+	_, err = dt.check(ctx, 1)
+	assert.NilError(t, err)
+
+	msg := shmsg.NewBatchConfigStarted(3)
+	assert.Assert(t, dt.startSending(1, msg, 1))
+	chain.add(t, 2, key, msg)
+	res, err := dt.check(ctx, 2)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, deliveryCheck{}, allowDeliveryCheck)
+
+	// a retry after the message made it into the chain must not send it again
+	assert.Assert(t, !dt.startSending(1, msg, 2))
+	dt.doneSending(1)
+	res, err = dt.check(ctx, 3)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, res, deliveryCheck{delivered: []ActionID{1}}, allowDeliveryCheck)
+}
@@ -0,0 +1,208 @@
+package fx
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// numActionWorkers is the maximum number of actions run concurrently.
+const numActionWorkers = 8
+
+// numPriorityWorkers is the number of workers reserved for priority actions, so that bulk traffic
+// like catch-up epoch secret key shares can't hold up urgent messages.
+const numPriorityWorkers = 2
+
+// dependsOn checks if the action later, which has been scheduled after the action earlier, must
+// not be started before earlier has finished:
+//
+//   - Nothing may overtake our check-in, because the other messages are useless without it.
+//   - Main chain transactions are sent in order, because they use consecutive nonces.
+//   - Messages concerning batch configs and eon starts are sent in order, because later ones
+//     build on the earlier ones.
+//   - The DKG messages of an eon are sent in order, because the decider relies on the order of
+//     the phases, e.g. an apology must not overtake the accusation it answers.
+//
+// Anything else, in particular shuttermint messages and main chain transactions, may run
+// concurrently.
+func dependsOn(later, earlier IAction) bool {
+	earlierMsg, earlierIsMsg := earlier.(*SendShuttermintMessage)
+	if earlierIsMsg && earlierMsg.Msg.GetCheckIn() != nil {
+		return true
+	}
+	_, laterIsTX := later.(MainChainTX)
+	_, earlierIsTX := earlier.(MainChainTX)
+	if laterIsTX && earlierIsTX {
+		return true
+	}
+	laterMsg, laterIsMsg := later.(*SendShuttermintMessage)
+	if laterIsMsg && earlierIsMsg {
+		if isConfigMessage(laterMsg.Msg) && isConfigMessage(earlierMsg.Msg) {
+			return true
+		}
+		for _, laterEon := range dkgMessageEons(laterMsg.Msg) {
+			for _, earlierEon := range dkgMessageEons(earlierMsg.Msg) {
+				if laterEon == earlierEon {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// dkgMessageEons returns the eons a DKG message belongs to and nil for other messages. Only
+// batched poly commitments belong to more than one eon.
+func dkgMessageEons(msg *shmsg.Message) []uint64 {
+	switch {
+	case msg.GetPolyCommitment() != nil:
+		return []uint64{msg.GetPolyCommitment().Eon}
+	case msg.GetPolyCommitments() != nil:
+		var eons []uint64
+		for _, c := range msg.GetPolyCommitments().Commitments {
+			eons = append(eons, c.Eon)
+		}
+		return eons
+	case msg.GetPolyEval() != nil:
+		return []uint64{msg.GetPolyEval().Eon}
+	case msg.GetAccusation() != nil:
+		return []uint64{msg.GetAccusation().Eon}
+	case msg.GetApology() != nil:
+		return []uint64{msg.GetApology().Eon}
+	default:
+		return nil
+	}
+}
+
+func isConfigMessage(msg *shmsg.Message) bool {
+	return msg.GetBatchConfig() != nil || msg.GetBatchConfigStarted() != nil || msg.GetEonStartVote() != nil
+}
+
+type scheduledAction struct {
+	id      ActionID
+	action  IAction
+	running bool
+}
+
+// executor runs actions concurrently while respecting the ordering constraints given by
+// dependsOn.
+type executor struct {
+	mux     sync.Mutex
+	queue   []*scheduledAction // actions that haven't finished yet, in the order they were scheduled
+	running int
+	wakeup  chan struct{}
+	ready   chan *scheduledAction
+	run     func(ctx context.Context, id ActionID, action IAction)
+}
+
+func newExecutor(run func(ctx context.Context, id ActionID, action IAction)) *executor {
+	return &executor{
+		wakeup: make(chan struct{}, 1),
+		ready:  make(chan *scheduledAction),
+		run:    run,
+	}
+}
+
+// schedule adds an action to be run once the actions scheduled before it, that it depends on, have
+// finished.
+func (ex *executor) schedule(id ActionID, action IAction) {
+	ex.mux.Lock()
+	ex.queue = append(ex.queue, &scheduledAction{id: id, action: action})
+	ex.mux.Unlock()
+	ex.notify()
+}
+
+func (ex *executor) notify() {
+	select {
+	case ex.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// isPriority checks if the action should be started before other actions that are waiting, see
+// SendShuttermintMessage.Priority and Urgent.
+func isPriority(action IAction) bool {
+	msg, ok := action.(*SendShuttermintMessage)
+	return ok && (msg.Priority || Urgent(msg.Msg))
+}
+
+// startable marks the actions that can be started now as running and returns them. Priority
+// actions get the free workers first, but still wait for the actions they depend on. Other actions
+// leave numPriorityWorkers workers free.
+func (ex *executor) startable() []*scheduledAction {
+	ex.mux.Lock()
+	defer ex.mux.Unlock()
+	var res []*scheduledAction
+	for _, priority := range []bool{true, false} {
+		workers := numActionWorkers
+		if !priority {
+			workers -= numPriorityWorkers
+		}
+		for i, a := range ex.queue {
+			if ex.running >= workers {
+				break
+			}
+			if a.running || isPriority(a.action) != priority || ex.blocked(i) {
+				continue
+			}
+			a.running = true
+			ex.running++
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+func (ex *executor) blocked(i int) bool {
+	for _, earlier := range ex.queue[:i] {
+		if dependsOn(ex.queue[i].action, earlier.action) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ex *executor) finish(id ActionID) {
+	ex.mux.Lock()
+	for i, a := range ex.queue {
+		if a.id == id {
+			ex.queue = append(ex.queue[:i], ex.queue[i+1:]...)
+			ex.running--
+			break
+		}
+	}
+	ex.mux.Unlock()
+	ex.notify()
+}
+
+// dispatch hands the actions to the workers as soon as they can be started.
+func (ex *executor) dispatch(ctx context.Context) {
+	for {
+		for _, a := range ex.startable() {
+			select {
+			case ex.ready <- a:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case <-ex.wakeup:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// work runs the actions handed out by dispatch.
+func (ex *executor) work(ctx context.Context) {
+	for {
+		select {
+		case a := <-ex.ready:
+			ex.run(ctx, a.id, a.action)
+			ex.finish(a.id)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package fx
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func checkInAction(t *testing.T) *SendShuttermintMessage {
+	t.Helper()
+	key, err := ecies.GenerateKey(rand.Reader, ecies.DefaultCurve, nil)
+	assert.NilError(t, err)
+	return &SendShuttermintMessage{Msg: shmsg.NewCheckIn(make([]byte, 32), &key.PublicKey)}
+}
+
+func TestDependsOn(t *testing.T) {
+	checkIn := checkInAction(t)
+	share := &SendShuttermintMessage{Msg: shmsg.NewDecryptionSignature(1, nil)}
+	accusation := &SendShuttermintMessage{Msg: shmsg.NewAccusation(1, nil)}
+	vote := &SendShuttermintMessage{Msg: shmsg.NewEonStartVote(1)}
+	started := &SendShuttermintMessage{Msg: shmsg.NewBatchConfigStarted(1)}
+	apology := &SendShuttermintMessage{Msg: shmsg.NewApology(1, nil, nil)}
+	otherEonApology := &SendShuttermintMessage{Msg: shmsg.NewApology(2, nil, nil)}
+	commitments := &SendShuttermintMessage{Msg: shmsg.NewPolyCommitments([]*shmsg.PolyCommitment{{Eon: 1}, {Eon: 2}})}
+	eval := &SendShuttermintMessage{Msg: shmsg.NewPolyEval(2, nil, nil)}
+	tx1 := &ExecuteCipherBatch{BatchIndex: 1}
+	tx2 := &ExecutePlainBatch{BatchIndex: 1}
+
+	assert.Assert(t, dependsOn(share, checkIn))
+	assert.Assert(t, dependsOn(tx1, checkIn))
+	assert.Assert(t, dependsOn(tx2, tx1))
+	assert.Assert(t, dependsOn(started, vote))
+	assert.Assert(t, dependsOn(apology, accusation))
+	assert.Assert(t, !dependsOn(otherEonApology, accusation))
+	assert.Assert(t, dependsOn(eval, commitments))
+	assert.Assert(t, dependsOn(otherEonApology, commitments))
+	assert.Assert(t, !dependsOn(eval, accusation))
+	assert.Assert(t, !dependsOn(accusation, share))
+	assert.Assert(t, !dependsOn(share, vote))
+	assert.Assert(t, !dependsOn(tx1, share))
+	assert.Assert(t, !dependsOn(share, tx1))
+}
+
+func TestExecutor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan ActionID, 10)
+	release := make(map[ActionID]chan struct{})
+	for id := ActionID(0); id < 4; id++ {
+		release[id] = make(chan struct{})
+	}
+	ex := newExecutor(func(ctx context.Context, id ActionID, action IAction) {
+		started <- id
+		<-release[id]
+	})
+	go ex.dispatch(ctx)
+	for i := 0; i < numActionWorkers; i++ {
+		go ex.work(ctx)
+	}
+
+	ex.schedule(0, checkInAction(t))
+	ex.schedule(1, &SendShuttermintMessage{Msg: shmsg.NewDecryptionSignature(1, nil)})
+	ex.schedule(2, &ExecuteCipherBatch{BatchIndex: 1})
+	ex.schedule(3, &ExecutePlainBatch{BatchIndex: 1})
+
+	waitStarted := func() ActionID {
+		select {
+		case id := <-started:
+			return id
+		case <-time.After(5 * time.Second):
+			t.Fatal("no action started")
+			return 0
+		}
+	}
+	assertNoneStarted := func() {
+		select {
+		case id := <-started:
+			t.Fatalf("action %d started too early", id)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	assert.Equal(t, waitStarted(), ActionID(0))
+	assertNoneStarted()
+
+	// after the check-in, the message and the first transaction run concurrently
+	close(release[0])
+	ids := map[ActionID]bool{waitStarted(): true, waitStarted(): true}
+	assert.DeepEqual(t, ids, map[ActionID]bool{1: true, 2: true})
+	assertNoneStarted()
+
+	// transactions are sent in order
+	close(release[2])
+	assert.Equal(t, waitStarted(), ActionID(3))
+	close(release[1])
+	close(release[3])
+}
+
+func TestExecutorPriority(t *testing.T) {
+	ex := newExecutor(nil)
+	for id := ActionID(0); id <= numActionWorkers; id++ {
+		ex.schedule(id, &SendShuttermintMessage{Msg: shmsg.NewDecryptionSignature(uint64(id), nil)})
+	}
+	ex.schedule(100, &SendShuttermintMessage{Msg: shmsg.NewApology(1, nil, nil), Priority: true})
+
+	started := ex.startable()
+	assert.Equal(t, len(started), numActionWorkers-numPriorityWorkers)
+	assert.Equal(t, started[0].id, ActionID(100))
+	assert.Equal(t, started[len(started)-1].id, ActionID(numActionWorkers-numPriorityWorkers-2))
+
+	// the reserved workers are left to urgent messages
+	ex.schedule(101, &SendShuttermintMessage{Msg: shmsg.NewAccusation(2, nil)})
+	ex.schedule(102, &SendShuttermintMessage{Msg: shmsg.NewHaltResumeVote(5)})
+	started = ex.startable()
+	assert.Equal(t, len(started), 2)
+	assert.Equal(t, started[0].id, ActionID(101))
+	assert.Equal(t, started[1].id, ActionID(102))
+}
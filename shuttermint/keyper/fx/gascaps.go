@@ -0,0 +1,49 @@
+package fx
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+)
+
+// GasPriceCaps limits the fees per gas of the transactions of some action types, e.g. so that
+// executions don't get arbitrarily expensive while accusations and appeals, which we must not
+// lose, aren't limited.
+type GasPriceCaps map[string]*big.Int // in wei by action type, see ActionType
+
+// ParseGasPriceCaps parses per action type caps given as "ActionType=gwei", e.g.
+// "ExecuteCipherBatch=200". Only main chain transactions can be capped.
+func ParseGasPriceCaps(specs []string) (GasPriceCaps, error) {
+	knownTypes := make(map[string]bool)
+	for _, a := range allActions {
+		if _, ok := a.(MainChainTX); ok {
+			knownTypes[ActionType(a)] = true
+		}
+	}
+	caps := make(GasPriceCaps)
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid gas price cap %q, expected ActionType=gwei", spec)
+		}
+		if !knownTypes[parts[0]] {
+			var names []string
+			for name := range knownTypes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.Errorf(
+				"unknown action type %q in gas price cap, must be one of %s", parts[0], strings.Join(names, ", "))
+		}
+		price, err := gaspricer.ParseGwei(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid gas price cap %q", spec)
+		}
+		caps[parts[0]] = price
+	}
+	return caps, nil
+}
@@ -0,0 +1,21 @@
+package fx
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseGasPriceCaps(t *testing.T) {
+	caps, err := ParseGasPriceCaps([]string{"ExecuteCipherBatch=200", "SkipCipherBatch=0.5"})
+	assert.NilError(t, err)
+	assert.Equal(t, caps[ActionType(&ExecuteCipherBatch{})].Int64(), int64(200e9))
+	assert.Equal(t, caps["SkipCipherBatch"].Int64(), int64(0.5e9))
+
+	_, err = ParseGasPriceCaps([]string{"SendShuttermintMessage=1"})
+	assert.ErrorContains(t, err, "unknown action type")
+	_, err = ParseGasPriceCaps([]string{"ExecuteCipherBatch"})
+	assert.ErrorContains(t, err, "invalid gas price cap")
+	_, err = ParseGasPriceCaps([]string{"ExecuteCipherBatch=lots"})
+	assert.ErrorContains(t, err, "invalid gas price cap")
+}
@@ -1,14 +1,17 @@
 package fx
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/base64"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/rpc/client"
 	tmtypes "github.com/tendermint/tendermint/types"
 
@@ -70,15 +73,40 @@ type MessageSender interface {
 	SendMessage(context.Context, *shmsg.Message) error
 }
 
-// RPCMessageSender signs messages and sends them via RPC to shuttermint.
+// RPCMessageSender signs messages and sends them via RPC to shuttermint. It may be used
+// concurrently. Urgent messages, see Urgent, have a lane of their own: other messages aren't
+// broadcast while urgent ones are, so that they don't compete for the same blocks.
 type RPCMessageSender struct {
 	rpcclient  client.Client
-	chainID    string
 	signingKey *ecdsa.PrivateKey
+
+	mux      sync.Mutex // protects the chain parameters below
+	chainID  string
+	workBits uint64 // work required for messages by the chain, see shmsg.MessageWork
+
+	laneMux    sync.Mutex    // protects the urgent lane below
+	numUrgent  int           // number of urgent messages being broadcast
+	urgentDone chan struct{} // closed once no urgent message is being broadcast anymore
 }
 
 var _ MessageSender = &RPCMessageSender{}
 
+// maxMessageWorkBits is the most work we're willing to put into a single message. Chains may
+// require up to shmsg.MaxMessageWorkBits, but that would keep an action worker busy for minutes.
+const maxMessageWorkBits = 24
+
+// maxUnconfirmedTxs is the number of mempool transactions we look at when checking if a message
+// is pending. It's the most tendermint returns at once.
+const maxUnconfirmedTxs = 100
+
+// Urgent checks if a message must make it into the chain quickly, because it's bound to a
+// deadline or needed to end a halt: accusations, apologies and halt resume votes. Shuttermint
+// transactions carry neither fees nor priorities, so urgent messages are sent before others
+// instead.
+func Urgent(msg *shmsg.Message) bool {
+	return msg.GetAccusation() != nil || msg.GetApology() != nil || msg.GetHaltResumeVote() != nil
+}
+
 // MockMessageSender sends all messages to a channel so that they can be checked for testing.
 type MockMessageSender struct {
 	Msgs chan *shmsg.Message
@@ -96,25 +124,57 @@ func init() {
 func NewRPCMessageSender(cl client.Client, signingKey *ecdsa.PrivateKey) RPCMessageSender {
 	return RPCMessageSender{
 		rpcclient:  cl,
-		chainID:    "",
 		signingKey: signingKey,
 	}
 }
 
 // SendMessage signs the given shmsg.Message and sends the message to shuttermint.
 func (ms *RPCMessageSender) SendMessage(ctx context.Context, msg *shmsg.Message) error {
-	if err := ms.maybeFetchChainID(ctx); err != nil {
+	chainID, workBits, err := ms.chainParams(ctx)
+	if err != nil {
 		return err
 	}
 
-	msgWithNonce := ms.addNonceAndChainID(msg)
+	if workBits > maxMessageWorkBits {
+		return &NonRetriableError{Err: errors.Errorf(
+			"chain requires %d bits of work per message, refusing to add more than %d", workBits, maxMessageWorkBits,
+		)}
+	}
+
+	msgWithNonce := addNonceAndChainID(msg, chainID)
+	if workBits > 0 {
+		if _, err := shmsg.AddWork(ctx, msgWithNonce, workBits); err != nil {
+			return err
+		}
+	}
 	signedMessage, err := shmsg.SignMessage(msgWithNonce, ms.signingKey)
 	if err != nil {
 		return err
 	}
 	var tx tmtypes.Tx = tmtypes.Tx(base64.RawURLEncoding.EncodeToString(signedMessage))
-	res, err := ms.rpcclient.BroadcastTxCommit(ctx, tx)
+	if Urgent(msg) {
+		ms.enterUrgentLane()
+		defer ms.leaveUrgentLane()
+	} else if err := ms.waitForUrgent(ctx); err != nil {
+		return err
+	}
+	// Leave some time to find out if the message has made it to the node if broadcasting it
+	// times out
+	bctx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		reserve := cleanupTimeout
+		if remaining := time.Until(deadline); remaining < 2*reserve {
+			reserve = remaining / 2
+		}
+		var cancel context.CancelFunc
+		bctx, cancel = context.WithDeadline(ctx, deadline.Add(-reserve))
+		defer cancel()
+	}
+	res, err := ms.rpcclient.BroadcastTxCommit(bctx, tx)
 	if err != nil {
+		if bctx.Err() == context.DeadlineExceeded && ms.isSent(ctx, tx) {
+			return nil
+		}
 		return err
 	}
 	if res.DeliverTx.Code != 0 {
@@ -125,29 +185,104 @@ func (ms *RPCMessageSender) SendMessage(ctx context.Context, msg *shmsg.Message)
 	return nil
 }
 
-func (ms *RPCMessageSender) addNonceAndChainID(msg *shmsg.Message) *shmsg.MessageWithNonce {
+func (ms *RPCMessageSender) enterUrgentLane() {
+	ms.laneMux.Lock()
+	defer ms.laneMux.Unlock()
+	if ms.numUrgent == 0 {
+		ms.urgentDone = make(chan struct{})
+	}
+	ms.numUrgent++
+}
+
+func (ms *RPCMessageSender) leaveUrgentLane() {
+	ms.laneMux.Lock()
+	defer ms.laneMux.Unlock()
+	ms.numUrgent--
+	if ms.numUrgent == 0 {
+		close(ms.urgentDone)
+	}
+}
+
+// waitForUrgent waits until no urgent message is being broadcast.
+func (ms *RPCMessageSender) waitForUrgent(ctx context.Context) error {
+	for {
+		ms.laneMux.Lock()
+		if ms.numUrgent == 0 {
+			ms.laneMux.Unlock()
+			return nil
+		}
+		done := ms.urgentDone
+		ms.laneMux.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isSent checks if the given transaction has been committed successfully or is waiting in the
+// node's mempool. If broadcasting a message times out, it might have made it to the node
+// nonetheless, in which case we must not send it again. Should a pending transaction be dropped
+// later on, the DeliveryTracker takes care of sending the message again.
+func (ms *RPCMessageSender) isSent(ctx context.Context, tx tmtypes.Tx) bool {
+	res, err := ms.rpcclient.Tx(ctx, tx.Hash(), false)
+	if err == nil {
+		return res.TxResult.Code == 0
+	}
+	limit := maxUnconfirmedTxs
+	unconfirmed, err := ms.rpcclient.UnconfirmedTxs(ctx, &limit)
+	if err != nil {
+		return false
+	}
+	for _, pending := range unconfirmed.Txs {
+		if bytes.Equal(pending, tx) {
+			return true
+		}
+	}
+	return false
+}
+
+func addNonceAndChainID(msg *shmsg.Message, chainID string) *shmsg.MessageWithNonce {
 	return &shmsg.MessageWithNonce{
-		ChainId:     []byte(ms.chainID),
+		ChainId:     []byte(chainID),
 		RandomNonce: randomNonce(),
 		Msg:         msg,
 	}
 }
 
-func (ms *RPCMessageSender) maybeFetchChainID(ctx context.Context) error {
+// chainParams returns the chain id and the work required for messages. They're fetched on first
+// use.
+func (ms *RPCMessageSender) chainParams(ctx context.Context) (string, uint64, error) {
+	ms.mux.Lock()
+	defer ms.mux.Unlock()
 	if ms.chainID != "" {
-		return nil
+		return ms.chainID, ms.workBits, nil
 	}
 
 	info, err := ms.rpcclient.BlockchainInfo(ctx, 0, 0)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 	if len(info.BlockMetas) == 0 {
-		return errors.Errorf("failed to fetch block meta to check chain id")
+		return "", 0, errors.Errorf("failed to fetch block meta to check chain id")
+	}
+
+	genesis, err := ms.rpcclient.Genesis(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	// only decode the part of the genesis app state we need, see app.GenesisAppState
+	var appState struct {
+		MessageWorkBits uint64 `json:"message_work_bits,omitempty"`
+	}
+	if err := amino.NewCodec().UnmarshalJSON(genesis.Genesis.AppState, &appState); err != nil {
+		return "", 0, errors.Wrap(err, "failed to decode genesis app state")
 	}
 
+	ms.workBits = appState.MessageWorkBits
 	ms.chainID = info.BlockMetas[0].Header.ChainID
-	return nil
+	return ms.chainID, ms.workBits, nil
 }
 
 func randomNonce() uint64 {
@@ -0,0 +1,168 @@
+package fx
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// fakeShuttermint implements the parts of client.Client used by RPCMessageSender.
+type fakeShuttermint struct {
+	client.Client
+	workBits uint64
+
+	infoCalls int32
+	mux       sync.Mutex
+	txs       []tmtypes.Tx
+	hang      bool         // broadcasting hangs until the context is done
+	drop      bool         // transactions broadcast while hanging don't reach the mempool
+	mempool   []tmtypes.Tx // transactions broadcast while hanging
+}
+
+func (c *fakeShuttermint) BlockchainInfo(_ context.Context, _, _ int64) (*ctypes.ResultBlockchainInfo, error) {
+	atomic.AddInt32(&c.infoCalls, 1)
+	return &ctypes.ResultBlockchainInfo{
+		BlockMetas: []*tmtypes.BlockMeta{{Header: tmtypes.Header{ChainID: "test-chain"}}},
+	}, nil
+}
+
+func (c *fakeShuttermint) Genesis(_ context.Context) (*ctypes.ResultGenesis, error) {
+	appState := []byte(`{"message_work_bits": "` + strconv.FormatUint(c.workBits, 10) + `"}`)
+	return &ctypes.ResultGenesis{Genesis: &tmtypes.GenesisDoc{AppState: appState}}, nil
+}
+
+func (c *fakeShuttermint) BroadcastTxCommit(ctx context.Context, tx tmtypes.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	c.mux.Lock()
+	if c.hang {
+		if !c.drop {
+			c.mempool = append(c.mempool, tx)
+		}
+		c.mux.Unlock()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	defer c.mux.Unlock()
+	c.txs = append(c.txs, tx)
+	return &ctypes.ResultBroadcastTxCommit{}, nil
+}
+
+func (c *fakeShuttermint) Tx(_ context.Context, _ []byte, _ bool) (*ctypes.ResultTx, error) {
+	return nil, errors.New("tx not found")
+}
+
+func (c *fakeShuttermint) UnconfirmedTxs(ctx context.Context, _ *int) (*ctypes.ResultUnconfirmedTxs, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return &ctypes.ResultUnconfirmedTxs{Txs: c.mempool}, nil
+}
+
+// TestRPCMessageSenderConcurrent sends messages from as many goroutines as there are action
+// workers. Run it with -race.
+func TestRPCMessageSenderConcurrent(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	cl := &fakeShuttermint{workBits: 2}
+	ms := NewRPCMessageSender(cl, key)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numActionWorkers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.Check(t, ms.SendMessage(context.Background(), shmsg.NewEonStartVote(uint64(i))))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, atomic.LoadInt32(&cl.infoCalls), int32(1))
+	assert.Equal(t, len(cl.txs), numActionWorkers)
+	for _, tx := range cl.txs {
+		signed, err := base64.RawURLEncoding.DecodeString(string(tx))
+		assert.NilError(t, err)
+		msg, err := shmsg.GetMessage(signed)
+		assert.NilError(t, err)
+		assert.Equal(t, string(msg.ChainId), "test-chain")
+		marshaled, err := proto.Marshal(msg)
+		assert.NilError(t, err)
+		assert.Assert(t, shmsg.MessageWork(marshaled) >= cl.workBits)
+	}
+}
+
+func TestRPCMessageSenderWorkCap(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	cl := &fakeShuttermint{workBits: maxMessageWorkBits + 1}
+	ms := NewRPCMessageSender(cl, key)
+
+	err = ms.SendMessage(context.Background(), shmsg.NewEonStartVote(1))
+	assert.ErrorContains(t, err, "refusing")
+	retriable, ok := err.(IRetriable)
+	assert.Assert(t, ok && !retriable.IsRetriable())
+	assert.Equal(t, len(cl.txs), 0)
+}
+
+// TestRPCMessageSenderPending checks that a message is considered sent if broadcasting it times
+// out, but it's waiting in the mempool.
+func TestRPCMessageSenderPending(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	cl := &fakeShuttermint{hang: true}
+	ms := NewRPCMessageSender(cl, key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.NilError(t, ms.SendMessage(ctx, shmsg.NewEonStartVote(1)))
+	assert.Equal(t, len(cl.mempool), 1)
+
+	// if the message didn't make it to the mempool, it must be sent again
+	cl.drop = true
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err = ms.SendMessage(ctx, shmsg.NewEonStartVote(2))
+	assert.Equal(t, err, context.DeadlineExceeded)
+	assert.Equal(t, len(cl.mempool), 1)
+}
+
+// TestRPCMessageSenderUrgentLane checks that other messages wait while urgent ones are being
+// broadcast.
+func TestRPCMessageSenderUrgentLane(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	cl := &fakeShuttermint{}
+	ms := NewRPCMessageSender(cl, key)
+	assert.Assert(t, Urgent(shmsg.NewAccusation(1, nil)))
+	assert.Assert(t, !Urgent(shmsg.NewEonStartVote(1)))
+
+	ms.enterUrgentLane()
+	assert.NilError(t, ms.SendMessage(context.Background(), shmsg.NewApology(1, nil, nil)))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = ms.SendMessage(ctx, shmsg.NewEonStartVote(1))
+	assert.Equal(t, err, context.DeadlineExceeded)
+	assert.Equal(t, len(cl.txs), 1)
+
+	sent := make(chan error)
+	go func() {
+		sent <- ms.SendMessage(context.Background(), shmsg.NewEonStartVote(2))
+	}()
+	ms.leaveUrgentLane()
+	assert.NilError(t, <-sent)
+	assert.Equal(t, len(cl.txs), 2)
+}
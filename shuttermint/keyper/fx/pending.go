@@ -9,6 +9,8 @@ import (
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
 
 // ActionID identifies an action.
@@ -21,6 +23,8 @@ type PendingActions struct {
 	mux               sync.Mutex
 	ActionMap         map[ActionID]IAction
 	MainChainTXHashes map[ActionID]common.Hash
+	Attempts          map[ActionID]int           // number of transactions sent before for retried critical actions
+	ReplacedTXHashes  map[ActionID][]common.Hash // replaced transactions, which may still be mined
 	CurrentID         ActionID
 	path              string
 }
@@ -30,6 +34,8 @@ func NewPendingActions(path string) *PendingActions {
 	return &PendingActions{
 		ActionMap:         make(map[ActionID]IAction),
 		MainChainTXHashes: make(map[ActionID]common.Hash),
+		Attempts:          make(map[ActionID]int),
+		ReplacedTXHashes:  make(map[ActionID][]common.Hash),
 		CurrentID:         0,
 		path:              path,
 	}
@@ -41,6 +47,13 @@ func (pending *PendingActions) ShortInfo() string {
 	return fmt.Sprintf("%d pending actions", len(pending.ActionMap))
 }
 
+// Len returns the number of pending actions.
+func (pending *PendingActions) Len() int {
+	pending.mux.Lock()
+	defer pending.mux.Unlock()
+	return len(pending.ActionMap)
+}
+
 // SortedIDs returns the sorted pending action ids.
 func (pending *PendingActions) SortedIDs() []ActionID {
 	pending.mux.Lock()
@@ -55,7 +68,8 @@ func (pending *PendingActions) SortedIDs() []ActionID {
 }
 
 // AddActions adds the given actions unless they have already been added. id is the ActionID of the
-// first action. It returns a startID, endID tuple of actions to be scheduled.
+// first action. It returns a startID, endID tuple of actions to be scheduled. Critical actions
+// equal to a pending one are skipped, so there may be no action for some of the IDs in the range.
 func (pending *PendingActions) AddActions(id ActionID, actions []IAction) (ActionID, ActionID) {
 	pending.mux.Lock()
 	defer pending.mux.Unlock()
@@ -72,6 +86,10 @@ func (pending *PendingActions) AddActions(id ActionID, actions []IAction) (Actio
 	actions = actions[skip:]
 
 	for i, act := range actions {
+		if key := criticalKey(act); key != "" && pending.hasCritical(key) {
+			log.Printf("Critical action already pending, skipping it: id=%d, %s", startID+ActionID(i), act)
+			continue
+		}
 		pending.ActionMap[startID+ActionID(i)] = act
 	}
 	pending.CurrentID += ActionID(len(actions))
@@ -79,6 +97,16 @@ func (pending *PendingActions) AddActions(id ActionID, actions []IAction) (Actio
 	return startID, pending.CurrentID
 }
 
+// hasCritical checks if a critical action with the given key is pending.
+func (pending *PendingActions) hasCritical(key string) bool {
+	for _, act := range pending.ActionMap {
+		if criticalKey(act) == key {
+			return true
+		}
+	}
+	return false
+}
+
 // SetMainChainTXHash sets the transaction hash for the given main chain action.
 func (pending *PendingActions) SetMainChainTXHash(id ActionID, hash common.Hash) {
 	pending.mux.Lock()
@@ -94,6 +122,38 @@ func (pending *PendingActions) GetMainChainTXHash(id ActionID) common.Hash {
 	return pending.MainChainTXHashes[id]
 }
 
+// ReplaceMainChainTXHash sets the hash of the transaction replacing the current one of the given
+// main chain action, see replaceTX.
+func (pending *PendingActions) ReplaceMainChainTXHash(id ActionID, hash common.Hash) {
+	pending.mux.Lock()
+	defer pending.mux.Unlock()
+	pending.ReplacedTXHashes[id] = append(pending.ReplacedTXHashes[id], pending.MainChainTXHashes[id])
+	pending.MainChainTXHashes[id] = hash
+	pending.save()
+}
+
+// GetMainChainTXHashes returns the hashes of the transactions replaced for the given main chain
+// action, followed by the hash of its current transaction. Any of them may be mined.
+func (pending *PendingActions) GetMainChainTXHashes(id ActionID) []common.Hash {
+	pending.mux.Lock()
+	defer pending.mux.Unlock()
+	hashes := append([]common.Hash(nil), pending.ReplacedTXHashes[id]...)
+	return append(hashes, pending.MainChainTXHashes[id])
+}
+
+// RetryMainChainTX forgets the transaction hash of the given main chain action, so that a new
+// transaction is sent for it when it's scheduled again. It returns the number of transactions
+// sent for it so far.
+func (pending *PendingActions) RetryMainChainTX(id ActionID) int {
+	pending.mux.Lock()
+	defer pending.mux.Unlock()
+	pending.Attempts[id]++
+	delete(pending.MainChainTXHashes, id)
+	delete(pending.ReplacedTXHashes, id)
+	pending.save()
+	return pending.Attempts[id]
+}
+
 // RemoveAction removes the action with the given id.
 func (pending *PendingActions) RemoveAction(id ActionID) {
 	pending.mux.Lock()
@@ -101,6 +161,8 @@ func (pending *PendingActions) RemoveAction(id ActionID) {
 
 	delete(pending.ActionMap, id)
 	delete(pending.MainChainTXHashes, id)
+	delete(pending.Attempts, id)
+	delete(pending.ReplacedTXHashes, id)
 	pending.save()
 }
 
@@ -111,6 +173,55 @@ func (pending *PendingActions) GetAction(id ActionID) IAction {
 	return pending.ActionMap[id]
 }
 
+// ShuttermintMessages returns the messages of the pending shuttermint message actions.
+func (pending *PendingActions) ShuttermintMessages() []*shmsg.Message {
+	pending.mux.Lock()
+	defer pending.mux.Unlock()
+
+	var msgs []*shmsg.Message
+	for _, act := range pending.ActionMap {
+		if a, ok := act.(*SendShuttermintMessage); ok {
+			msgs = append(msgs, a.Msg)
+		}
+	}
+	return msgs
+}
+
+// PendingActionInfo describes a pending action for humans.
+type PendingActionInfo struct {
+	ID          ActionID      `json:"id"`
+	Action      string        `json:"action"`
+	MainChainTX bool          `json:"mainChainTX"`
+	TXHash      *common.Hash  `json:"txHash,omitempty"` // hash of the current transaction, if sent
+	Replaced    []common.Hash `json:"replaced,omitempty"`
+	Attempts    int           `json:"attempts,omitempty"`
+}
+
+// Info describes the pending actions, ordered by id.
+func (pending *PendingActions) Info() []PendingActionInfo {
+	pending.mux.Lock()
+	defer pending.mux.Unlock()
+
+	info := []PendingActionInfo{}
+	for id, act := range pending.ActionMap {
+		i := PendingActionInfo{
+			ID:       id,
+			Action:   fmt.Sprint(act),
+			Replaced: append([]common.Hash(nil), pending.ReplacedTXHashes[id]...),
+			Attempts: pending.Attempts[id],
+		}
+		if _, ok := act.(MainChainTX); ok {
+			i.MainChainTX = true
+			if hash, ok := pending.MainChainTXHashes[id]; ok {
+				i.TXHash = &hash
+			}
+		}
+		info = append(info, i)
+	}
+	sort.Slice(info, func(i, j int) bool { return info[i].ID < info[j].ID })
+	return info
+}
+
 // save saves the pending actions to disk. It panics if it cannot write the file to disk.
 func (pending *PendingActions) save() {
 	tmppath := pending.path + ".tmp"
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"gotest.tools/v3/assert"
 
 	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
@@ -39,3 +40,51 @@ func TestAddActions(t *testing.T) {
 	pending.AddActions(ActionID(3), myactions[3:5])
 	assert.Equal(t, 8, len(pending.SortedIDs()))
 }
+
+func TestAddCriticalActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "actions.gob")
+	pending := NewPendingActions(path)
+	accuse := &Accuse{HalfStep: 4}
+	start, end := pending.AddActions(ActionID(0), []IAction{accuse, myactions[0]})
+	assert.Equal(t, start, ActionID(0))
+	assert.Equal(t, end, ActionID(2))
+
+	// the decider derived the same accusation again after a restart
+	start, end = pending.AddActions(ActionID(2), []IAction{&Accuse{HalfStep: 4}, &Accuse{HalfStep: 6}})
+	assert.Equal(t, start, ActionID(2))
+	assert.Equal(t, end, ActionID(4))
+	assert.DeepEqual(t, pending.SortedIDs(), []ActionID{0, 1, 3})
+
+	assert.Equal(t, pending.RetryMainChainTX(0), 1)
+	pending.SetMainChainTXHash(0, common.HexToHash("0x01"))
+	assert.Equal(t, pending.RetryMainChainTX(0), 2)
+	assert.Equal(t, pending.GetMainChainTXHash(0), common.Hash{})
+
+	loaded := NewPendingActions(path)
+	assert.NilError(t, loaded.Load())
+	assert.Equal(t, loaded.Attempts[0], 2)
+	loaded.RemoveAction(0)
+	assert.Equal(t, len(loaded.Attempts), 0)
+}
+
+func TestPendingActionsInfo(t *testing.T) {
+	pending := NewPendingActions(filepath.Join(t.TempDir(), "actions.gob"))
+	assert.DeepEqual(t, pending.Info(), []PendingActionInfo{})
+
+	msg := &SendShuttermintMessage{Description: "check in"}
+	pending.AddActions(ActionID(0), []IAction{&Accuse{HalfStep: 4}, msg})
+	pending.SetMainChainTXHash(0, common.HexToHash("0x01"))
+	pending.ReplaceMainChainTXHash(0, common.HexToHash("0x02"))
+
+	hash := common.HexToHash("0x02")
+	assert.DeepEqual(t, pending.Info(), []PendingActionInfo{
+		{
+			ID:          0,
+			Action:      (&Accuse{HalfStep: 4}).String(),
+			MainChainTX: true,
+			TXHash:      &hash,
+			Replaced:    []common.Hash{common.HexToHash("0x01")},
+		},
+		{ID: 1, Action: msg.String()},
+	})
+}
@@ -0,0 +1,141 @@
+package fx
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+)
+
+// minFeeBumpPercent is the percentage by which a replacement transaction must raise the fees of
+// the transaction it replaces. Nodes reject replacements paying less, 10% is geth's default.
+const minFeeBumpPercent = 10
+
+// receiptPollInterval is the time between two checks if one of the transactions of an action has
+// been mined.
+const receiptPollInterval = 500 * time.Millisecond
+
+// errNotMined is returned by waitMinedAny if none of the transactions has been mined in time.
+var errNotMined = errors.New("transaction not mined")
+
+// waitMinedAny waits until one of the transactions with the given hashes has been mined and
+// returns its receipt. If timeout is positive and none of them has been mined by then, it returns
+// errNotMined.
+func (runenv *RunEnv) waitMinedAny(ctx context.Context, hashes []common.Hash, timeout time.Duration) (*types.Receipt, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	for {
+		for _, hash := range hashes {
+			receipt, err := runenv.ContractCaller.Ethclient.TransactionReceipt(ctx, hash)
+			if err == ethereum.NotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			return receipt, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, errNotMined
+		case <-time.After(receiptPollInterval):
+		}
+	}
+}
+
+// replaceTX replaces the current transaction of the given main chain action, which hasn't been
+// mined in time, with a transaction using the same nonce and higher fees (replace-by-fee). It
+// returns false if the transaction cannot be replaced, e.g. because it's not pending anymore or
+// the gas price cap of the action doesn't allow raising its fees.
+func (runenv *RunEnv) replaceTX(ctx context.Context, id ActionID, act MainChainTX) bool {
+	hash := runenv.PendingActions.GetMainChainTXHash(id)
+	old, isPending, err := runenv.ContractCaller.Ethclient.TransactionByHash(ctx, hash)
+	if err != nil {
+		log.Printf("Cannot replace transaction id=%d, %s: %v", id, hash.Hex(), err)
+		return false
+	}
+	if !isPending {
+		return false
+	}
+
+	auth, err := runenv.ContractCaller.Auth(ctx)
+	if err != nil {
+		log.Printf("Cannot replace transaction id=%d, %s: %v", id, hash.Hex(), err)
+		return false
+	}
+	auth.Context = ctx
+	auth.Nonce = new(big.Int).SetUint64(old.Nonce())
+	fees := replacementFees(old, gaspricer.Fees{GasPrice: auth.GasPrice, GasTipCap: auth.GasTipCap, GasFeeCap: auth.GasFeeCap})
+	fees.Apply(auth)
+	settings := runenv.settings()
+	if max, ok := settings.GasPriceCaps[ActionType(act)]; ok {
+		gaspricer.Cap(auth, max)
+		if !isReplacement(old, auth.GasPrice, auth.GasTipCap, auth.GasFeeCap) {
+			log.Printf("Cannot replace transaction id=%d, %s: the gas price cap of %s doesn't allow raising its fees",
+				id, hash.Hex(), ActionType(act))
+			return false
+		}
+	}
+	auth.NoSend = true
+	tx, err := act.SendTX(runenv.ContractCaller, auth)
+	if err != nil {
+		log.Printf("Cannot replace transaction id=%d, %s: %v", id, hash.Hex(), err)
+		return false
+	}
+	err = runenv.ContractCaller.Ethclient.SendTransaction(ctx, tx)
+	if err != nil {
+		log.Printf("Cannot replace transaction id=%d, %s: %v", id, hash.Hex(), err)
+		return false
+	}
+	log.Printf("Replaced transaction id=%d, %s not mined after %s with %s, nonce=%d, %s",
+		id, hash.Hex(), settings.ReplaceTXsAfter, tx.Hash().Hex(), tx.Nonce(), act)
+	runenv.PendingActions.ReplaceMainChainTXHash(id, tx.Hash())
+	return true
+}
+
+// bump returns x raised by minFeeBumpPercent, rounded up.
+func bump(x *big.Int) *big.Int {
+	y := new(big.Int).Mul(x, big.NewInt(100+minFeeBumpPercent))
+	y.Add(y, big.NewInt(99))
+	return y.Div(y, big.NewInt(100))
+}
+
+func maxInt(x, y *big.Int) *big.Int {
+	if x.Cmp(y) >= 0 {
+		return new(big.Int).Set(x)
+	}
+	return new(big.Int).Set(y)
+}
+
+// replacementFees returns the fees of a transaction replacing old, given the fees currently
+// suggested. The fees are raised to at least the bumped fees of old. Like old, the replacement is
+// a legacy or an EIP-1559 transaction depending on the current fees.
+func replacementFees(old *types.Transaction, current gaspricer.Fees) gaspricer.Fees {
+	minTip, minFeeCap := bump(old.GasTipCap()), bump(old.GasFeeCap())
+	if current.GasPrice != nil {
+		// a legacy transaction pays its gas price as tip and fee cap
+		return gaspricer.Fees{GasPrice: maxInt(current.GasPrice, maxInt(minTip, minFeeCap))}
+	}
+	tip := maxInt(current.GasTipCap, minTip)
+	return gaspricer.Fees{GasTipCap: tip, GasFeeCap: maxInt(maxInt(current.GasFeeCap, minFeeCap), tip)}
+}
+
+// isReplacement checks if a transaction with the given fees raises the fees of old enough to
+// replace it.
+func isReplacement(old *types.Transaction, gasPrice, gasTipCap, gasFeeCap *big.Int) bool {
+	if gasPrice != nil {
+		gasTipCap, gasFeeCap = gasPrice, gasPrice
+	}
+	return gasTipCap.Cmp(bump(old.GasTipCap())) >= 0 && gasFeeCap.Cmp(bump(old.GasFeeCap())) >= 0
+}
@@ -0,0 +1,63 @@
+package fx
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shtest"
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+)
+
+func TestReplacementFees(t *testing.T) {
+	legacy := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(100)})
+	dynamic := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(201)})
+
+	// the current fees are too low, so the old ones are bumped
+	fees := replacementFees(legacy, gaspricer.Fees{GasPrice: big.NewInt(50)})
+	assert.DeepEqual(t, fees, gaspricer.Fees{GasPrice: big.NewInt(110)}, shtest.BigIntComparer)
+	fees = replacementFees(dynamic, gaspricer.Fees{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)})
+	assert.DeepEqual(t, fees, gaspricer.Fees{GasTipCap: big.NewInt(11), GasFeeCap: big.NewInt(222)}, shtest.BigIntComparer)
+
+	// the current fees are higher already
+	fees = replacementFees(legacy, gaspricer.Fees{GasPrice: big.NewInt(500)})
+	assert.DeepEqual(t, fees, gaspricer.Fees{GasPrice: big.NewInt(500)}, shtest.BigIntComparer)
+
+	// switching between legacy and EIP-1559 transactions
+	fees = replacementFees(legacy, gaspricer.Fees{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(100)})
+	assert.DeepEqual(t, fees, gaspricer.Fees{GasTipCap: big.NewInt(110), GasFeeCap: big.NewInt(110)}, shtest.BigIntComparer)
+	fees = replacementFees(dynamic, gaspricer.Fees{GasPrice: big.NewInt(50)})
+	assert.DeepEqual(t, fees, gaspricer.Fees{GasPrice: big.NewInt(222)}, shtest.BigIntComparer)
+}
+
+func TestIsReplacement(t *testing.T) {
+	dynamic := types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(200)})
+	assert.Assert(t, isReplacement(dynamic, nil, big.NewInt(11), big.NewInt(220)))
+	assert.Assert(t, !isReplacement(dynamic, nil, big.NewInt(10), big.NewInt(300)))
+	assert.Assert(t, !isReplacement(dynamic, nil, big.NewInt(20), big.NewInt(219)))
+	assert.Assert(t, isReplacement(dynamic, big.NewInt(220), nil, nil))
+	assert.Assert(t, !isReplacement(dynamic, big.NewInt(219), nil, nil))
+}
+
+func TestReplaceMainChainTXHash(t *testing.T) {
+	pending := NewPendingActions(filepath.Join(t.TempDir(), "actions.gob"))
+	pending.AddActions(ActionID(0), myactions[0:1])
+	pending.SetMainChainTXHash(0, common.HexToHash("0x01"))
+	pending.ReplaceMainChainTXHash(0, common.HexToHash("0x02"))
+	pending.ReplaceMainChainTXHash(0, common.HexToHash("0x03"))
+	assert.Equal(t, pending.GetMainChainTXHash(0), common.HexToHash("0x03"))
+	assert.DeepEqual(t, pending.GetMainChainTXHashes(0),
+		[]common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02"), common.HexToHash("0x03")})
+
+	loaded := NewPendingActions(pending.path)
+	assert.NilError(t, loaded.Load())
+	assert.DeepEqual(t, loaded.GetMainChainTXHashes(0), pending.GetMainChainTXHashes(0))
+
+	pending.RetryMainChainTX(0)
+	pending.SetMainChainTXHash(0, common.HexToHash("0x04"))
+	assert.DeepEqual(t, pending.GetMainChainTXHashes(0), []common.Hash{common.HexToHash("0x04")})
+}
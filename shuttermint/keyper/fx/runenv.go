@@ -3,14 +3,16 @@ package fx
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+	"github.com/shutter-network/shutter/shuttermint/keyper/metrics"
 	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
 	"github.com/shutter-network/shutter/shuttermint/medley"
 )
@@ -24,27 +26,56 @@ type ActionWithID struct {
 	ID     ActionID
 }
 
+// Settings are the parts of the run environment that may be changed while actions are running,
+// see Reconfigure.
+type Settings struct {
+	Timeouts          ActionTimeouts
+	GasPriceCaps      GasPriceCaps
+	ReplaceTXsAfter   time.Duration // replace transactions not mined after this long, 0 to disable
+	MaxTXReplacements int           // number of times a transaction is replaced at most
+}
+
 type RunEnv struct {
-	PendingActions       *PendingActions
-	PendingActionsPath   string
-	MessageSender        MessageSender
-	ContractCaller       *contract.Caller
-	shuttermintMessages  chan ActionID
-	mainChainTXs         chan ActionID
+	PendingActions     *PendingActions
+	PendingActionsPath string
+	MessageSender      MessageSender
+	ContractCaller     *contract.Caller
+	// Settings are set before calling Run and afterwards only with Reconfigure.
+	Settings
+	Deliveries  *DeliveryTracker // checks that our messages make it into the chain, may be nil
+	Metrics     *metrics.Metrics // may be nil
+	DeadLetters *DeadLetters     // records the actions we've given up on, may be nil
+
+	settingsMux          sync.RWMutex
+	executor             *executor
 	inFlightMainChainTXs chan ActionID
 	currentWorld         func() observe.World
 }
 
 func NewRunEnv(messageSender MessageSender, contractCaller *contract.Caller, currentWorld func() observe.World, path string) *RunEnv {
-	return &RunEnv{
+	runenv := &RunEnv{
 		PendingActions:       NewPendingActions(path),
 		MessageSender:        messageSender,
 		ContractCaller:       contractCaller,
-		shuttermintMessages:  make(chan ActionID),
-		mainChainTXs:         make(chan ActionID, numMainChainWorkers),
 		inFlightMainChainTXs: make(chan ActionID),
 		currentWorld:         currentWorld,
 	}
+	runenv.executor = newExecutor(runenv.runAction)
+	return runenv
+}
+
+// Reconfigure replaces the settings. Actions already running keep their timeout, transactions
+// waiting to be mined pick up the new settings the next time they are checked.
+func (runenv *RunEnv) Reconfigure(settings Settings) {
+	runenv.settingsMux.Lock()
+	defer runenv.settingsMux.Unlock()
+	runenv.Settings = settings
+}
+
+func (runenv *RunEnv) settings() Settings {
+	runenv.settingsMux.RLock()
+	defer runenv.settingsMux.RUnlock()
+	return runenv.Settings
 }
 
 func (runenv *RunEnv) ShortInfo() string {
@@ -54,48 +85,97 @@ func (runenv *RunEnv) ShortInfo() string {
 	return runenv.PendingActions.ShortInfo()
 }
 
-func (runenv *RunEnv) sendShuttermintMessage(ctx context.Context, id ActionID, act *SendShuttermintMessage) error {
+func (runenv *RunEnv) sendShuttermintMessage(ctx context.Context, id ActionID, act *SendShuttermintMessage, timeout time.Duration) error {
 	log.Printf("=====%s, id=%d", act, id)
-	err := runenv.MessageSender.SendMessage(ctx, act.Msg)
-	return err
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return runenv.MessageSender.SendMessage(ctx, act.Msg)
 }
 
-func (runenv *RunEnv) sendMainChainTX(ctx context.Context, id ActionID, act MainChainTX) error {
-	var err error
-	var tx *types.Transaction
-	var auth *bind.TransactOpts
-
-	auth, err = runenv.ContractCaller.Auth()
-	auth.Context = ctx
-
+func (runenv *RunEnv) sendMainChainTX(ctx context.Context, id ActionID, act MainChainTX, timeout time.Duration) error {
+	actx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		actx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	auth, err := runenv.ContractCaller.Auth(actx)
 	if err != nil {
 		return err
 	}
-	tx, err = act.SendTX(runenv.ContractCaller, auth)
+	auth.Context = actx
+	if max, ok := runenv.settings().GasPriceCaps[ActionType(act)]; ok {
+		gaspricer.Cap(auth, max)
+	}
+	// Sign the transaction first, so that we know its hash in case sending it times out
+	auth.NoSend = true
+	tx, err := act.SendTX(runenv.ContractCaller, auth)
 	if err != nil {
 		return err
 	}
+	err = runenv.ContractCaller.Ethclient.SendTransaction(actx, tx)
+	if err != nil {
+		if actx.Err() != context.DeadlineExceeded || !runenv.isKnownTX(ctx, tx.Hash()) {
+			return err
+		}
+		log.Printf("Sending transaction id=%d, %s timed out, but the node knows it", id, tx.Hash().Hex())
+	}
 	runenv.PendingActions.SetMainChainTXHash(id, tx.Hash())
-	runenv.inFlightMainChainTXs <- id
+	select {
+	case runenv.inFlightMainChainTXs <- id:
+	case <-ctx.Done():
+	}
 	return nil
 }
 
+// isKnownTX checks if the main chain node knows the transaction with the given hash. If sending a
+// transaction times out, it might have been sent nonetheless, and we must not send another
+// transaction for the same action unless it hasn't.
+func (runenv *RunEnv) isKnownTX(ctx context.Context, hash common.Hash) bool {
+	ctx, cancel := context.WithTimeout(ctx, cleanupTimeout)
+	defer cancel()
+	_, _, err := runenv.ContractCaller.Ethclient.TransactionByHash(ctx, hash)
+	return err == nil
+}
+
 var zerohash = common.Hash{}
 
-func (runenv *RunEnv) waitMined(ctx context.Context, id ActionID) {
+// waitMined waits for the transaction of the given action to be mined. It returns true if the
+// transaction succeeded. If it isn't mined within ReplaceTXsAfter, it's replaced with a
+// transaction paying higher fees, see replaceTX.
+func (runenv *RunEnv) waitMined(ctx context.Context, id ActionID) bool {
 	act := runenv.PendingActions.GetAction(id)
 	hash := runenv.PendingActions.GetMainChainTXHash(id)
 	if hash == zerohash {
 		log.Fatalf("internal error: cannot wait for the zero hash, id=%d", id)
 	}
-	receipt, err := medley.WaitMined(ctx, runenv.ContractCaller.Ethclient, hash)
+	var receipt *types.Receipt
+	var err error
+	replaceAfter := runenv.settings().ReplaceTXsAfter
+	for {
+		hashes := runenv.PendingActions.GetMainChainTXHashes(id)
+		if len(hashes) > runenv.settings().MaxTXReplacements {
+			replaceAfter = 0
+		}
+		receipt, err = runenv.waitMinedAny(ctx, hashes, replaceAfter)
+		if err != errNotMined {
+			break
+		}
+		if !runenv.replaceTX(ctx, id, act.(MainChainTX)) {
+			replaceAfter = 0 // keep waiting for the transactions we have
+		}
+	}
 	if err == context.Canceled {
-		return
+		return false
 	}
 	if err != nil {
 		log.Printf("Error waiting for transaction id=%d, %s: %v", id, hash.Hex(), err)
-		return
+		return false
 	}
+	hash = receipt.TxHash
 	if receipt.Status != types.ReceiptStatusSuccessful {
 		world := runenv.CurrentWorld() // XXX we should make sure our world includes the receipt's blocknumber
 		expired := act.IsExpired(world)
@@ -103,14 +183,15 @@ func (runenv *RunEnv) waitMined(ctx context.Context, id ActionID) {
 		tx, _, err := runenv.ContractCaller.Ethclient.TransactionByHash(ctx, hash)
 		if err != nil {
 			log.Printf("TX reverted: id=%d, gasUsed=%d, expired=%t, %s, hash=%s", id, receipt.GasUsed, expired, act, hash.Hex())
-			return
+			return false
 		}
 
 		reason := medley.GetRevertReason(ctx, runenv.ContractCaller.Ethclient, runenv.ContractCaller.Address(), tx, receipt.BlockNumber)
 		log.Printf("TX reverted: id=%d, gasUsed=%d, expired=%t, %s, hash=%s: %s", id, receipt.GasUsed, expired, act, hash.Hex(), reason)
-	} else {
-		log.Printf("TX success: id=%d, gasUsed=%d, %s, hash=%s", id, receipt.GasUsed, act, hash.Hex())
+		return false
 	}
+	log.Printf("TX success: id=%d, gasUsed=%d, %s, hash=%s", id, receipt.GasUsed, act, hash.Hex())
+	return true
 }
 
 func (runenv *RunEnv) RunActions(ctx context.Context, actionCounter uint64, actions []IAction) error {
@@ -133,34 +214,39 @@ func (runenv *RunEnv) RunActions(ctx context.Context, actionCounter uint64, acti
 // pending actions struct.
 func (runenv *RunEnv) scheduleAction(ctx context.Context, id ActionID) error {
 	act := runenv.PendingActions.GetAction(id)
-	var ch chan ActionID
 	switch a := act.(type) {
+	case nil:
+		return nil // skipped by AddActions
 	case *SendShuttermintMessage:
-		ch = runenv.shuttermintMessages
 	case MainChainTX:
 		txhash := runenv.PendingActions.GetMainChainTXHash(id)
 		if txhash != zerohash {
-			ch = runenv.inFlightMainChainTXs
-		} else {
-			ch = runenv.mainChainTXs
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case runenv.inFlightMainChainTXs <- id:
+			}
+			return nil
 		}
 	default:
 		log.Fatalf("cannot run %s", a)
 	}
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case ch <- id:
-	}
+	runenv.executor.schedule(id, act)
 	return nil
 }
 
-// Load loads the pending actions from disk and schedules the actions to be run.
+// Load loads the pending actions and dead letters from disk and schedules the actions to be run.
 func (runenv *RunEnv) Load(ctx context.Context) (bool, error) {
 	err := runenv.PendingActions.Load()
 	if err != nil {
 		return false, err
 	}
+	if runenv.DeadLetters != nil {
+		err = runenv.DeadLetters.Load()
+		if err != nil {
+			return false, err
+		}
+	}
 
 	sortedIDs := runenv.PendingActions.SortedIDs()
 	for _, id := range sortedIDs {
@@ -173,15 +259,29 @@ func (runenv *RunEnv) Load(ctx context.Context) (bool, error) {
 }
 
 func (runenv *RunEnv) handleAction(ctx context.Context, id ActionID, action IAction) (bool, error) {
+	timeout := runenv.settings().Timeouts.For(action)
 	switch a := action.(type) {
 	case *SendShuttermintMessage:
-		err := runenv.sendShuttermintMessage(ctx, id, a)
+		if runenv.Deliveries == nil {
+			err := runenv.sendShuttermintMessage(ctx, id, a, timeout)
+			if err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		if !runenv.Deliveries.startSending(id, a.Msg, runenv.CurrentWorld().Shutter.CurrentBlock) {
+			log.Printf("Message already in the chain: id=%d, %s", id, a)
+			return true, nil
+		}
+		err := runenv.sendShuttermintMessage(ctx, id, a, timeout)
+		runenv.Deliveries.doneSending(id)
 		if err != nil {
 			return false, err
 		}
-		return true, nil
+		// keep the action until we've seen the message in the chain, see watchDeliveries
+		return false, nil
 	case MainChainTX:
-		err := runenv.sendMainChainTX(ctx, id, a)
+		err := runenv.sendMainChainTX(ctx, id, a, timeout)
 		if err != nil {
 			return false, err
 		}
@@ -192,44 +292,68 @@ func (runenv *RunEnv) handleAction(ctx context.Context, id ActionID, action IAct
 	}
 }
 
-func (runenv *RunEnv) handleActions(ctx context.Context, actions chan ActionID) {
+// Actions failing with a retriable error are retried after minRetryDelay, doubling the delay with
+// every failed attempt up to maxRetryDelay, so that a node that's down isn't flooded with requests.
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// nextRetryDelay returns the delay before the attempt following one made after the given delay.
+func nextRetryDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// runAction runs the given action, retrying it until it succeeds, expires, or fails with a
+// non-retriable error. Actions failing with a non-retriable error are added to the dead letters.
+func (runenv *RunEnv) runAction(ctx context.Context, id ActionID, a IAction) {
+	var err error
+	var remove bool
+	delay := minRetryDelay
+
 	for {
+		if a.IsExpired(runenv.CurrentWorld()) {
+			log.Printf("Action expired: id=%d, %s", id, a)
+			remove = true
+			break
+		}
+		if err != nil {
+			log.Printf("Retrying action id=%d, %s; err=%s", id, a, err)
+		}
+		remove, err = runenv.handleAction(ctx, id, a)
+		if err == nil {
+			break
+		}
+		runenv.Metrics.ActionError(IsRetriable(err))
+		if !IsRetriable(err) {
+			remove = true
+			log.Printf("Non-retriable error id=%d, %s; err=%s", id, a, err)
+			runenv.addDeadLetter(id, a, err)
+			break
+		}
 		select {
-		case id := <-actions:
-			a := runenv.PendingActions.GetAction(id)
-			var err error
-			var remove bool
-
-			for {
-				if a.IsExpired(runenv.CurrentWorld()) {
-					log.Printf("Action expired: id=%d, %s", id, a)
-					remove = true
-					break
-				}
-				if err != nil {
-					log.Printf("Retrying action id=%d, %s; err=%s", id, a, err)
-				}
-				remove, err = runenv.handleAction(ctx, id, a)
-				if err == nil {
-					break
-				}
-				if !IsRetriable(err) {
-					remove = true
-					log.Printf("Non-retriable error id=%d, %s; err=%s", id, a, err)
-					break
-				}
-				select {
-				case <-time.After(time.Second):
-				case <-ctx.Done():
-					return
-				}
-			}
-			if remove {
-				runenv.PendingActions.RemoveAction(id)
-			}
+		case <-time.After(delay):
 		case <-ctx.Done():
 			return
 		}
+		delay = nextRetryDelay(delay)
+	}
+	if remove {
+		if runenv.Deliveries != nil {
+			runenv.Deliveries.forget(id)
+		}
+		runenv.PendingActions.RemoveAction(id)
+	}
+}
+
+// addDeadLetter records that we've given up on the given action.
+func (runenv *RunEnv) addDeadLetter(id ActionID, a IAction, err error) {
+	if runenv.DeadLetters != nil {
+		runenv.DeadLetters.Add(id, a, err)
 	}
 }
 
@@ -237,25 +361,72 @@ func (runenv *RunEnv) handleInFlightTXs(ctx context.Context) {
 	for {
 		select {
 		case id := <-runenv.inFlightMainChainTXs:
-			runenv.waitMined(ctx, id)
-			runenv.PendingActions.RemoveAction(id)
+			confirmed := runenv.waitMined(ctx, id)
+			runenv.finishInFlightTX(ctx, id, confirmed)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// watchDeliveries checks that the shuttermint messages we've sent make it into the chain. Delivered
+// messages are removed from the pending actions, missing ones are sent again.
+func (runenv *RunEnv) watchDeliveries(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		res, err := runenv.Deliveries.check(ctx, runenv.CurrentWorld().Shutter.CurrentBlock)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error checking message deliveries: %v", err)
+			}
+			continue
+		}
+		for _, id := range res.delivered {
+			runenv.PendingActions.RemoveAction(id)
+		}
+		for _, id := range res.giveUp {
+			log.Printf("Error: giving up on message id=%d, it did not make it into the chain after %d resends: %s",
+				id, runenv.Deliveries.MaxResends, runenv.PendingActions.GetAction(id))
+			runenv.PendingActions.RemoveAction(id)
+		}
+		for _, id := range res.resend {
+			act := runenv.PendingActions.GetAction(id)
+			if act == nil {
+				runenv.Deliveries.forget(id)
+				continue
+			}
+			log.Printf("Message not in the chain after %d blocks, sending it again: id=%d, %s",
+				runenv.Deliveries.Blocks, id, act)
+			runenv.executor.schedule(id, act)
+		}
+	}
+}
+
 func (runenv *RunEnv) CurrentWorld() observe.World {
 	return runenv.currentWorld()
 }
 
 func (runenv *RunEnv) StartBackgroundTasks(ctx context.Context, g *errgroup.Group) {
-	// Start exactly one worker, because we like to make sure the actions are started in the
-	// order given to us
-	for _, ch := range []chan ActionID{runenv.mainChainTXs, runenv.shuttermintMessages} {
-		ch := ch
+	g.Go(func() error {
+		runenv.executor.dispatch(ctx)
+		return nil
+	})
+	for i := 0; i < numActionWorkers; i++ {
+		g.Go(func() error {
+			runenv.executor.work(ctx)
+			return nil
+		})
+	}
+
+	if runenv.Deliveries != nil {
 		g.Go(func() error {
-			runenv.handleActions(ctx, ch)
+			runenv.watchDeliveries(ctx)
 			return nil
 		})
 	}
@@ -0,0 +1,65 @@
+package fx
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// cleanupTimeout limits the time spent finding out if an action that timed out has taken effect
+// anyway.
+const cleanupTimeout = 10 * time.Second
+
+// ActionTimeouts limits how long a single attempt to run an action may take. Attempts that time
+// out are retried like attempts that failed because of other temporary errors.
+type ActionTimeouts struct {
+	Default time.Duration            // limit for actions without a specific one, 0 for none
+	ByType  map[string]time.Duration // limits by action type, see ActionType
+}
+
+// ActionType returns the name of the action's type, e.g. "ExecuteCipherBatch".
+func ActionType(a IAction) string {
+	return reflect.Indirect(reflect.ValueOf(a)).Type().Name()
+}
+
+// ParseActionTimeouts parses per action type timeouts given as "ActionType=duration", e.g.
+// "SendShuttermintMessage=30s".
+func ParseActionTimeouts(defaultTimeout time.Duration, specs []string) (ActionTimeouts, error) {
+	knownTypes := make(map[string]bool)
+	for _, a := range allActions {
+		knownTypes[ActionType(a)] = true
+	}
+	timeouts := ActionTimeouts{Default: defaultTimeout, ByType: make(map[string]time.Duration)}
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return ActionTimeouts{}, errors.Errorf("invalid action timeout %q, expected ActionType=duration", spec)
+		}
+		if !knownTypes[parts[0]] {
+			var names []string
+			for name := range knownTypes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return ActionTimeouts{}, errors.Errorf(
+				"unknown action type %q in action timeout, must be one of %s", parts[0], strings.Join(names, ", "))
+		}
+		d, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return ActionTimeouts{}, errors.Wrapf(err, "invalid action timeout %q", spec)
+		}
+		timeouts.ByType[parts[0]] = d
+	}
+	return timeouts, nil
+}
+
+// For returns the timeout for the given action, 0 if there's none.
+func (t ActionTimeouts) For(a IAction) time.Duration {
+	if d, ok := t.ByType[ActionType(a)]; ok {
+		return d
+	}
+	return t.Default
+}
@@ -0,0 +1,42 @@
+package fx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestParseActionTimeouts(t *testing.T) {
+	timeouts, err := ParseActionTimeouts(time.Minute, []string{"ExecuteCipherBatch=30s"})
+	assert.NilError(t, err)
+	assert.Equal(t, timeouts.For(&ExecuteCipherBatch{}), 30*time.Second)
+	assert.Equal(t, timeouts.For(ExecuteCipherBatch{}), 30*time.Second)
+	assert.Equal(t, timeouts.For(&SendShuttermintMessage{}), time.Minute)
+
+	_, err = ParseActionTimeouts(0, []string{"ExecuteBatch=30s"})
+	assert.ErrorContains(t, err, "unknown action type")
+	_, err = ParseActionTimeouts(0, []string{"ExecuteCipherBatch"})
+	assert.ErrorContains(t, err, "invalid action timeout")
+	_, err = ParseActionTimeouts(0, []string{"ExecuteCipherBatch=soon"})
+	assert.ErrorContains(t, err, "invalid action timeout")
+}
+
+// hangingMessageSender blocks until the context is done.
+type hangingMessageSender struct{}
+
+func (hangingMessageSender) SendMessage(ctx context.Context, msg *shmsg.Message) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestHandleActionTimeout(t *testing.T) {
+	runenv := NewRunEnv(hangingMessageSender{}, nil, nil, "")
+	runenv.Timeouts = ActionTimeouts{Default: 10 * time.Millisecond}
+	_, err := runenv.handleAction(context.Background(), 0, &SendShuttermintMessage{})
+	assert.Equal(t, err, context.DeadlineExceeded)
+	assert.Assert(t, IsRetriable(err))
+}
@@ -1,20 +1,29 @@
-// Package gaspricer is used to multiply the gas price by a configurable factor. This is needed
-// because the give price returned from SuggestGasPrice is too low (at least on goerli)
+// Package gaspricer determines the fees of main chain transactions. By default, the gas price
+// suggested by the node is multiplied by a configurable factor. This is needed because the give
+// price returned from SuggestGasPrice is too low (at least on goerli). Other strategies are
+// implemented by the oracles, see Oracle.
 package gaspricer
 
 import (
 	"math/big"
+	"sync"
 
 	"github.com/pkg/errors"
 )
 
-var gasPriceMultiplier = big.NewFloat(1.5)
+var (
+	gasPriceMultiplier    = big.NewFloat(1.5)
+	gasPriceMultiplierMux sync.RWMutex
+)
 
-// SetMultiplier sets the gas price multiplier. This is a global setting.
+// SetMultiplier sets the gas price multiplier. This is a global setting, it may be changed while
+// transactions are being sent.
 func SetMultiplier(f float64) error {
 	if f < 0.0 {
 		return errors.New("gas price multiplier must be non-negative")
 	}
+	gasPriceMultiplierMux.Lock()
+	defer gasPriceMultiplierMux.Unlock()
 	gasPriceMultiplier = big.NewFloat(f)
 	return nil
 }
@@ -22,6 +31,8 @@ func SetMultiplier(f float64) error {
 // Adjust multiplies the given gas price by the configured multiplier.
 // place.
 func Adjust(price *big.Int) *big.Int {
+	gasPriceMultiplierMux.RLock()
+	defer gasPriceMultiplierMux.RUnlock()
 	p := new(big.Float).SetInt(price)
 	p.Mul(p, gasPriceMultiplier)
 	r, _ := p.Int(nil)
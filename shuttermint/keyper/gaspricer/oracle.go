@@ -0,0 +1,221 @@
+package gaspricer
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// defaultPercentileBlocks is the number of recent blocks the percentile oracle looks at if not
+// configured otherwise.
+const defaultPercentileBlocks = 20
+
+// Client is the part of the main chain client the oracles use.
+type Client interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// Fees are the fees a transaction pays per gas, either GasPrice for a legacy transaction or
+// GasTipCap and GasFeeCap for an EIP-1559 transaction.
+type Fees struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// Apply sets the fees of the transactions created with auth.
+func (f Fees) Apply(auth *bind.TransactOpts) {
+	auth.GasPrice = f.GasPrice
+	auth.GasTipCap = f.GasTipCap
+	auth.GasFeeCap = f.GasFeeCap
+}
+
+// Oracle determines the fees of our main chain transactions.
+type Oracle interface {
+	Fees(ctx context.Context, client Client) (Fees, error)
+}
+
+// NodeOracle uses the gas price suggested by the node, adjusted by the multiplier, see Adjust.
+type NodeOracle struct{}
+
+func (NodeOracle) Fees(ctx context.Context, client Client) (Fees, error) {
+	price, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return Fees{}, err
+	}
+	return Fees{GasPrice: Adjust(price)}, nil
+}
+
+// FixedOracle always uses the same gas price.
+type FixedOracle struct {
+	Price *big.Int
+}
+
+func (o FixedOracle) Fees(_ context.Context, _ Client) (Fees, error) {
+	return Fees{GasPrice: new(big.Int).Set(o.Price)}, nil
+}
+
+// DynamicOracle sends EIP-1559 transactions, with the priority fee suggested by the node adjusted
+// by the multiplier, and a fee cap allowing the base fee to double. Before London, it falls back
+// to NodeOracle.
+type DynamicOracle struct{}
+
+func (DynamicOracle) Fees(ctx context.Context, client Client) (Fees, error) {
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Fees{}, err
+	}
+	if head.BaseFee == nil {
+		return NodeOracle{}.Fees(ctx, client)
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return Fees{}, err
+	}
+	tip = Adjust(tip)
+	return Fees{GasTipCap: tip, GasFeeCap: feeCap(head.BaseFee, tip)}, nil
+}
+
+// PercentileOracle pays the given percentile of the priority fees, or gas prices before London,
+// of the transactions in the most recent blocks. If the blocks are empty, it falls back to
+// DynamicOracle.
+type PercentileOracle struct {
+	Percentile int // between 1 and 100
+	Blocks     int // number of blocks to look at
+}
+
+func (o PercentileOracle) Fees(ctx context.Context, client Client) (Fees, error) {
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Fees{}, err
+	}
+	var prices []*big.Int
+	for i := 0; i < o.Blocks && int64(i) <= head.Number.Int64(); i++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).Sub(head.Number, big.NewInt(int64(i))))
+		if err != nil {
+			return Fees{}, err
+		}
+		for _, tx := range block.Transactions() {
+			if block.BaseFee() == nil {
+				prices = append(prices, tx.GasPrice())
+				continue
+			}
+			if tip, err := tx.EffectiveGasTip(block.BaseFee()); err == nil {
+				prices = append(prices, tip)
+			}
+		}
+	}
+	if len(prices) == 0 {
+		return DynamicOracle{}.Fees(ctx, client)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	rank := (len(prices)*o.Percentile + 99) / 100 // nearest rank
+	if rank == 0 {
+		rank = 1
+	}
+	price := prices[rank-1]
+	if head.BaseFee == nil {
+		return Fees{GasPrice: price}, nil
+	}
+	return Fees{GasTipCap: price, GasFeeCap: feeCap(head.BaseFee, price)}, nil
+}
+
+// feeCap returns a fee cap allowing the base fee to double before the transaction is mined.
+func feeCap(baseFee, tip *big.Int) *big.Int {
+	return new(big.Int).Add(tip, new(big.Int).Mul(baseFee, big.NewInt(2)))
+}
+
+// SwitchableOracle delegates to an oracle that can be replaced while it's in use, e.g. when the
+// config is reloaded.
+type SwitchableOracle struct {
+	mux    sync.RWMutex
+	oracle Oracle
+}
+
+// NewSwitchableOracle creates a switchable oracle delegating to the given oracle.
+func NewSwitchableOracle(oracle Oracle) *SwitchableOracle {
+	return &SwitchableOracle{oracle: oracle}
+}
+
+// Switch makes the oracle delegate to the given oracle from now on.
+func (o *SwitchableOracle) Switch(oracle Oracle) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+	o.oracle = oracle
+}
+
+// Fees implements Oracle.
+func (o *SwitchableOracle) Fees(ctx context.Context, client Client) (Fees, error) {
+	o.mux.RLock()
+	oracle := o.oracle
+	o.mux.RUnlock()
+	return oracle.Fees(ctx, client)
+}
+
+// ParseOracle parses an oracle given as "node", "fixed:<gwei>", "eip1559" or
+// "percentile:<percentile>[:<blocks>]". The empty string selects the node's suggestion.
+func ParseOracle(s string) (Oracle, error) {
+	parts := strings.Split(s, ":")
+	switch {
+	case s == "" || s == "node":
+		return NodeOracle{}, nil
+	case s == "eip1559":
+		return DynamicOracle{}, nil
+	case parts[0] == "fixed" && len(parts) == 2:
+		price, err := ParseGwei(parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid gas price oracle %q", s)
+		}
+		return FixedOracle{Price: price}, nil
+	case parts[0] == "percentile" && (len(parts) == 2 || len(parts) == 3):
+		o := PercentileOracle{Blocks: defaultPercentileBlocks}
+		var err error
+		o.Percentile, err = strconv.Atoi(parts[1])
+		if err != nil || o.Percentile < 1 || o.Percentile > 100 {
+			return nil, errors.Errorf("invalid gas price oracle %q, the percentile must be between 1 and 100", s)
+		}
+		if len(parts) == 3 {
+			o.Blocks, err = strconv.Atoi(parts[2])
+			if err != nil || o.Blocks < 1 {
+				return nil, errors.Errorf("invalid gas price oracle %q, the number of blocks must be positive", s)
+			}
+		}
+		return o, nil
+	default:
+		return nil, errors.Errorf(
+			"invalid gas price oracle %q, expected \"node\", \"fixed:<gwei>\", \"eip1559\" or \"percentile:<percentile>[:<blocks>]\"", s)
+	}
+}
+
+// ParseGwei parses a non-negative amount of gwei, e.g. "1.5", and returns it in wei.
+func ParseGwei(s string) (*big.Int, error) {
+	f, ok := new(big.Float).SetString(s)
+	if !ok || f.Sign() < 0 {
+		return nil, errors.Errorf("invalid amount of gwei %q", s)
+	}
+	wei, _ := f.Mul(f, big.NewFloat(1e9)).Int(nil)
+	return wei, nil
+}
+
+// Cap limits the fees of the transactions created with auth to max per gas.
+func Cap(auth *bind.TransactOpts, max *big.Int) {
+	capInt := func(x *big.Int) *big.Int {
+		if x != nil && x.Cmp(max) > 0 {
+			return new(big.Int).Set(max)
+		}
+		return x
+	}
+	auth.GasPrice = capInt(auth.GasPrice)
+	auth.GasFeeCap = capInt(auth.GasFeeCap)
+	auth.GasTipCap = capInt(auth.GasTipCap)
+}
@@ -0,0 +1,144 @@
+package gaspricer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shtest"
+)
+
+// fakeClient serves a chain of blocks, the last one being the head.
+type fakeClient struct {
+	blocks []*types.Block
+}
+
+func (c *fakeClient) SuggestGasPrice(context.Context) (*big.Int, error)  { return big.NewInt(100), nil }
+func (c *fakeClient) SuggestGasTipCap(context.Context) (*big.Int, error) { return big.NewInt(10), nil }
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	block, err := c.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (c *fakeClient) BlockByNumber(_ context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil {
+		return c.blocks[len(c.blocks)-1], nil
+	}
+	return c.blocks[number.Int64()], nil
+}
+
+// newFakeClient creates a chain with one block per given list of priority fees. The blocks have
+// a base fee of 1000, unless it's nil.
+func newFakeClient(baseFee *big.Int, tips ...[]int64) *fakeClient {
+	c := &fakeClient{}
+	for i, blockTips := range tips {
+		var txs []*types.Transaction
+		for _, tip := range blockTips {
+			if baseFee == nil {
+				txs = append(txs, types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(tip)}))
+				continue
+			}
+			txs = append(txs, types.NewTx(&types.DynamicFeeTx{
+				GasTipCap: big.NewInt(tip),
+				GasFeeCap: new(big.Int).Add(baseFee, big.NewInt(tip)),
+			}))
+		}
+		header := &types.Header{Number: big.NewInt(int64(i)), BaseFee: baseFee}
+		c.blocks = append(c.blocks, types.NewBlockWithHeader(header).WithBody(txs, nil))
+	}
+	return c
+}
+
+func TestOracles(t *testing.T) {
+	old := gasPriceMultiplier
+	defer func() {
+		gasPriceMultiplier = old
+	}()
+	_ = SetMultiplier(2.0)
+	ctx := context.Background()
+	london := newFakeClient(big.NewInt(1000), []int64{1, 2}, []int64{3, 4, 5})
+	legacy := newFakeClient(nil, []int64{10, 20})
+
+	fees, err := NodeOracle{}.Fees(ctx, london)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasPrice: big.NewInt(200)}, shtest.BigIntComparer)
+
+	fees, err = FixedOracle{Price: big.NewInt(7)}.Fees(ctx, london)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasPrice: big.NewInt(7)}, shtest.BigIntComparer)
+
+	fees, err = DynamicOracle{}.Fees(ctx, london)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasTipCap: big.NewInt(20), GasFeeCap: big.NewInt(2020)}, shtest.BigIntComparer)
+	fees, err = DynamicOracle{}.Fees(ctx, legacy)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasPrice: big.NewInt(200)}, shtest.BigIntComparer)
+
+	fees, err = PercentileOracle{Percentile: 50, Blocks: 2}.Fees(ctx, london)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasTipCap: big.NewInt(3), GasFeeCap: big.NewInt(2003)}, shtest.BigIntComparer)
+	fees, err = PercentileOracle{Percentile: 100, Blocks: 1}.Fees(ctx, london)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(2005)}, shtest.BigIntComparer)
+	fees, err = PercentileOracle{Percentile: 10, Blocks: 20}.Fees(ctx, legacy)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasPrice: big.NewInt(10)}, shtest.BigIntComparer)
+
+	empty := newFakeClient(big.NewInt(1000), nil)
+	fees, err = PercentileOracle{Percentile: 50, Blocks: 20}.Fees(ctx, empty)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasTipCap: big.NewInt(20), GasFeeCap: big.NewInt(2020)}, shtest.BigIntComparer)
+}
+
+func TestSwitchableOracle(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeClient(nil, []int64{10})
+	o := NewSwitchableOracle(FixedOracle{Price: big.NewInt(7)})
+	fees, err := o.Fees(ctx, client)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasPrice: big.NewInt(7)}, shtest.BigIntComparer)
+
+	o.Switch(FixedOracle{Price: big.NewInt(9)})
+	fees, err = o.Fees(ctx, client)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fees, Fees{GasPrice: big.NewInt(9)}, shtest.BigIntComparer)
+}
+
+func TestParseOracle(t *testing.T) {
+	for s, want := range map[string]Oracle{
+		"":                 NodeOracle{},
+		"node":             NodeOracle{},
+		"eip1559":          DynamicOracle{},
+		"fixed:1.5":        FixedOracle{Price: big.NewInt(1.5e9)},
+		"percentile:60":    PercentileOracle{Percentile: 60, Blocks: defaultPercentileBlocks},
+		"percentile:60:10": PercentileOracle{Percentile: 60, Blocks: 10},
+	} {
+		o, err := ParseOracle(s)
+		assert.NilError(t, err, s)
+		assert.DeepEqual(t, o, want, shtest.BigIntComparer)
+	}
+	for _, s := range []string{"fast", "fixed", "fixed:-1", "fixed:cheap", "percentile:0", "percentile:101", "percentile:50:0"} {
+		_, err := ParseOracle(s)
+		assert.ErrorContains(t, err, "invalid", s)
+	}
+}
+
+func TestCap(t *testing.T) {
+	auth := &bind.TransactOpts{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(50)}
+	Cap(auth, big.NewInt(20))
+	assert.DeepEqual(t, auth.GasTipCap, big.NewInt(5), shtest.BigIntComparer)
+	assert.DeepEqual(t, auth.GasFeeCap, big.NewInt(20), shtest.BigIntComparer)
+	assert.Assert(t, auth.GasPrice == nil)
+
+	auth = &bind.TransactOpts{GasPrice: big.NewInt(50)}
+	Cap(auth, big.NewInt(2))
+	assert.DeepEqual(t, auth.GasPrice, big.NewInt(2), shtest.BigIntComparer)
+}
@@ -0,0 +1,93 @@
+package keyper
+
+import (
+	"log"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// Reasons for skipping a step, used as keys in Keyper.skippedSteps.
+const (
+	skipShutterBehind   = "shutter-behind"
+	skipMainChainBehind = "mainchain-behind"
+	skipInconsistent    = "inconsistent"
+)
+
+// checkWorldConsistency checks invariants of the observed state the decider relies on. The main
+// chain state lagging behind shuttermint is not an inconsistency, see configsLagging.
+func checkWorldConsistency(world observe.World) error {
+	mainChain := world.MainChain
+	if len(mainChain.BatchConfigs) == 0 {
+		return errors.New("no batch configs on main chain")
+	}
+	for i := 1; i < len(mainChain.BatchConfigs); i++ {
+		if mainChain.BatchConfigs[i].StartBatchIndex < mainChain.BatchConfigs[i-1].StartBatchIndex {
+			return errors.Errorf("main chain batch config %d starts before its predecessor", i)
+		}
+	}
+
+	shutter := world.Shutter
+	for i, eon := range shutter.Eons {
+		if i > 0 && eon.Eon <= shutter.Eons[i-1].Eon {
+			return errors.Errorf("eon %d out of order", eon.Eon)
+		}
+		bc := shutter.FindBatchConfigByBatchIndex(eon.StartEvent.BatchIndex)
+		if len(bc.Keypers) == 0 {
+			return errors.Errorf(
+				"eon %d starts at batch %d, which has no batch config",
+				eon.Eon, eon.StartEvent.BatchIndex,
+			)
+		}
+	}
+	return nil
+}
+
+// configsLagging checks if shuttermint knows about batch configs we haven't seen on the main chain
+// yet. That's normal, since we follow the main chain at a distance, but actions comparing the
+// batch configs of both chains have to wait until we've caught up.
+func configsLagging(shutter *observe.Shutter, mainChain *observe.MainChain) bool {
+	for _, bc := range shutter.BatchConfigs {
+		if bc.ConfigIndex >= uint64(len(mainChain.BatchConfigs)) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldSkipStep checks if the observed state is recent and consistent enough to make decisions
+// based on it. If not, it records the reason in kpr.skippedSteps and returns true.
+func (kpr *Keyper) shouldSkipStep(world observe.World) bool {
+	reason := ""
+	var err error
+	maxBehind := kpr.Config.MaxBlocksBehind
+	switch {
+	case maxBehind > 0 && world.Shutter.BlocksBehind() > maxBehind:
+		reason = skipShutterBehind
+		err = errors.Errorf("shuttermint state %d blocks behind", world.Shutter.BlocksBehind())
+	case maxBehind > 0 && world.MainChain.BlocksBehind() > maxBehind:
+		reason = skipMainChainBehind
+		err = errors.Errorf("main chain state %d blocks behind", world.MainChain.BlocksBehind())
+	default:
+		err = checkWorldConsistency(world)
+		if err != nil {
+			reason = skipInconsistent
+		}
+	}
+	if err == nil {
+		return false
+	}
+
+	if kpr.skippedSteps == nil {
+		kpr.skippedSteps = make(map[string]uint64)
+	}
+	kpr.skippedSteps[reason]++
+	now := time.Now()
+	if now.Sub(kpr.lastSkipLog) >= syncLogInterval {
+		kpr.lastSkipLog = now
+		log.Printf("Not making decisions: %s (skipped steps: %v)", err, kpr.skippedSteps)
+	}
+	return true
+}
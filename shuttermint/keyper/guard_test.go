@@ -0,0 +1,83 @@
+package keyper
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func consistentWorld() observe.World {
+	keypers := []common.Address{common.HexToAddress("0x1")}
+	mainChain := observe.NewMainChain(10)
+	mainChain.BatchConfigs = []contract.BatchConfig{
+		{StartBatchIndex: 0},
+		{StartBatchIndex: 10, Keypers: keypers},
+	}
+	shutter := observe.NewShutter()
+	shutter.BatchConfigs = []shutterevents.BatchConfig{
+		{ConfigIndex: 1, StartBatchIndex: 10, Keypers: keypers},
+	}
+	shutter.Eons = []observe.Eon{
+		{Eon: 1, StartEvent: shutterevents.EonStarted{Eon: 1, BatchIndex: 10}},
+		{Eon: 2, StartEvent: shutterevents.EonStarted{Eon: 2, BatchIndex: 20}},
+	}
+	return observe.World{Shutter: shutter, MainChain: mainChain}
+}
+
+func TestCheckWorldConsistency(t *testing.T) {
+	world := consistentWorld()
+	assert.NilError(t, checkWorldConsistency(world))
+
+	world = consistentWorld()
+	world.MainChain.BatchConfigs = nil
+	assert.ErrorContains(t, checkWorldConsistency(world), "no batch configs")
+
+	world = consistentWorld()
+	world.MainChain.BatchConfigs = world.MainChain.BatchConfigs[:1]
+	assert.NilError(t, checkWorldConsistency(world), "main chain lag must not block decisions")
+
+	world = consistentWorld()
+	world.Shutter.Eons[0].StartEvent.BatchIndex = 5
+	assert.ErrorContains(t, checkWorldConsistency(world), "has no batch config")
+
+	world = consistentWorld()
+	world.Shutter.Eons[1].Eon = 1
+	assert.ErrorContains(t, checkWorldConsistency(world), "out of order")
+}
+
+func TestConfigsLagging(t *testing.T) {
+	world := consistentWorld()
+	assert.Assert(t, !configsLagging(world.Shutter, world.MainChain))
+	world.MainChain.BatchConfigs = world.MainChain.BatchConfigs[:1]
+	assert.Assert(t, configsLagging(world.Shutter, world.MainChain))
+}
+
+func TestShouldSkipStep(t *testing.T) {
+	kpr := NewKeyper(Config{MaxBlocksBehind: 10})
+	world := consistentWorld()
+	world.Shutter.CurrentBlock = 100
+	world.Shutter.NodeStatus = &rpctypes.ResultStatus{}
+	world.Shutter.NodeStatus.SyncInfo.LatestBlockHeight = 105
+	assert.Assert(t, !kpr.shouldSkipStep(world))
+
+	world.Shutter.NodeStatus.SyncInfo.LatestBlockHeight = 200
+	assert.Assert(t, kpr.shouldSkipStep(world))
+	assert.Equal(t, kpr.skippedSteps[skipShutterBehind], uint64(1))
+
+	world.Shutter.NodeStatus.SyncInfo.LatestBlockHeight = 100
+	lagging := world
+	lagging.MainChain = world.MainChain.Clone()
+	lagging.MainChain.BatchConfigs = lagging.MainChain.BatchConfigs[:1]
+	assert.Assert(t, !kpr.shouldSkipStep(lagging))
+
+	world.Shutter.Eons[0].Eon = 5
+	assert.Assert(t, kpr.shouldSkipStep(world))
+	assert.Equal(t, kpr.skippedSteps[skipInconsistent], uint64(1))
+	assert.Assert(t, kpr.skipinfo() != "")
+}
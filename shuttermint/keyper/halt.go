@@ -0,0 +1,221 @@
+package keyper
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/policy"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// If shuttermint consensus halts, the main chain keeps closing batches, but the keypers can't
+// agree on anything anymore, e.g. on which batches to skip, and their view of the shuttermint
+// state gets stale. Releasing keys or executing batches based on that state could get us slashed,
+// so once our node hasn't produced a block for ShuttermintHaltTimeout, we freeze both locally.
+//
+// Resume protocol: once shuttermint produces blocks again, every frozen keyper sends a
+// HaltResumeVote with the last height it had seen before the halt. Keypers that haven't noticed
+// the halt answer recent votes of others with their own. A frozen keyper resumes once a threshold
+// of the keypers of the latest batch config has voted after its halt height, so that all keypers
+// resume on the same shuttermint state. Operators can end the freeze of their keyper without
+// waiting for the votes via the admin API, e.g. if too few keypers run a version voting to resume.
+
+// Paths of the admin API endpoints about shuttermint halts.
+const haltResumePath = "/halt/resume"
+
+// haltResumeVoteTimeout is the number of shuttermint blocks after which we send our resume vote
+// again, if it hasn't made it into the chain.
+const haltResumeVoteTimeout int64 = 10
+
+// haltResumeEchoBlocks is the number of recent shuttermint blocks in which we answer the resume
+// votes of other keypers.
+const haltResumeEchoBlocks int64 = 100
+
+// ShuttermintHalt is our local record of a shuttermint halt we've frozen key releases and
+// executions for.
+type ShuttermintHalt struct {
+	Height         int64     // last shuttermint height we've seen before the halt
+	DetectedAt     time.Time // when we've noticed the halt
+	VoteSentHeight int64     // shuttermint height we've last sent our resume vote at, 0 if we haven't
+}
+
+// shutterHalted checks if the shuttermint node we're connected to hasn't produced a block for
+// longer than the given timeout. A node catching up is not considered halted.
+func shutterHalted(shutter *observe.Shutter, timeout time.Duration, now time.Time) bool {
+	if timeout == 0 || shutter.NodeStatus == nil || !shutter.IsSynced() {
+		return false
+	}
+	latest := shutter.NodeStatus.SyncInfo.LatestBlockTime
+	return !latest.IsZero() && now.Sub(latest) > timeout
+}
+
+// haltedPolicy delays all key releases and executions while we're frozen because of a
+// shuttermint halt and leaves the other decisions to the wrapped policy.
+type haltedPolicy struct {
+	policy.Policy
+}
+
+func (haltedPolicy) ReleaseKey(observe.World, policy.KeyRelease) policy.Decision {
+	return policy.Delay
+}
+
+func (haltedPolicy) Execute(observe.World, policy.Execution) policy.Decision {
+	return policy.Delay
+}
+
+// handleHalt freezes key releases and executions when shuttermint halts and runs the resume
+// protocol once it produces blocks again.
+func (dcdr *Decider) handleHalt() {
+	st := dcdr.State
+	height := dcdr.Shutter.CurrentBlock
+	if dcdr.ShutterHalted {
+		if st.Halt != nil && height <= st.Halt.Height {
+			return
+		}
+		if st.Halt == nil {
+			log.Printf(
+				"Alert: shuttermint has not produced a block since height %d, freezing key releases and executions",
+				height,
+			)
+		} else {
+			log.Printf("Alert: shuttermint halted again at height %d before we could resume", height)
+		}
+		st.Halt = &ShuttermintHalt{Height: height, DetectedAt: time.Now()}
+		return
+	}
+
+	if st.Halt == nil {
+		dcdr.maybeEchoHaltResumeVotes()
+		return
+	}
+	if dcdr.ForceHaltResume {
+		log.Printf("Resuming key releases and executions after the halt at height %d via the admin API", st.Halt.Height)
+		st.Halt = nil
+		return
+	}
+	if height <= st.Halt.Height {
+		return // no new blocks yet
+	}
+
+	threshold, keypers := dcdr.haltResumeThreshold()
+	voters := dcdr.haltResumeVoters(st.Halt.Height, keypers)
+	if uint64(len(voters)) >= threshold {
+		log.Printf(
+			"Keypers agreed to resume after the shuttermint halt at height %d, resuming key releases and executions after %s",
+			st.Halt.Height, time.Since(st.Halt.DetectedAt).Round(time.Second),
+		)
+		st.Halt = nil
+		return
+	}
+	if _, ok := keypers[dcdr.Config.Address()]; !ok {
+		return
+	}
+	if _, voted := voters[dcdr.Config.Address()]; voted {
+		return
+	}
+	if st.Halt.VoteSentHeight != 0 && height < st.Halt.VoteSentHeight+haltResumeVoteTimeout {
+		return // still waiting for our vote to make it into the chain
+	}
+	st.Halt.VoteSentHeight = height
+	dcdr.sendHaltResumeVote(uint64(st.Halt.Height))
+}
+
+// maybeEchoHaltResumeVotes answers recent resume votes of other keypers, so that the keypers
+// frozen by a halt we haven't noticed ourselves, e.g. because it was shorter than our timeout, can
+// resume.
+func (dcdr *Decider) maybeEchoHaltResumeVotes() {
+	_, keypers := dcdr.haltResumeThreshold()
+	if _, ok := keypers[dcdr.Config.Address()]; !ok {
+		return
+	}
+	for _, ev := range dcdr.Shutter.HaltResumeVotes {
+		if ev.Height+haltResumeEchoBlocks < dcdr.Shutter.CurrentBlock || ev.HaltHeight <= dcdr.State.HaltVotedHeight {
+			continue
+		}
+		if ev.Sender == dcdr.Config.Address() {
+			dcdr.State.HaltVotedHeight = ev.HaltHeight
+			continue
+		}
+		dcdr.sendHaltResumeVote(ev.HaltHeight)
+	}
+}
+
+func (dcdr *Decider) sendHaltResumeVote(haltHeight uint64) {
+	dcdr.State.HaltVotedHeight = haltHeight
+	dcdr.sendShuttermintMessage(
+		fmt.Sprintf("halt resume vote, halt height=%d", haltHeight),
+		shmsg.NewHaltResumeVote(haltHeight))
+}
+
+// haltResumeThreshold returns the number of votes required to resume after a halt and the keypers
+// whose votes count, i.e. those of the latest batch config.
+func (dcdr *Decider) haltResumeThreshold() (uint64, map[common.Address]struct{}) {
+	keypers := make(map[common.Address]struct{})
+	if len(dcdr.Shutter.BatchConfigs) == 0 {
+		return 0, keypers
+	}
+	bc := dcdr.Shutter.BatchConfigs[len(dcdr.Shutter.BatchConfigs)-1]
+	for _, k := range bc.Keypers {
+		keypers[k] = struct{}{}
+	}
+	threshold := bc.Threshold
+	if threshold == 0 {
+		threshold = 1
+	}
+	return threshold, keypers
+}
+
+// haltResumeVoters returns the given keypers that have voted to resume in a block after the given
+// halt height.
+func (dcdr *Decider) haltResumeVoters(haltHeight int64, keypers map[common.Address]struct{}) map[common.Address]struct{} {
+	voters := make(map[common.Address]struct{})
+	for _, ev := range dcdr.Shutter.HaltResumeVotes {
+		if ev.Height <= haltHeight {
+			continue
+		}
+		if _, ok := keypers[ev.Sender]; ok {
+			voters[ev.Sender] = struct{}{}
+		}
+	}
+	return voters
+}
+
+// requestHaltResume makes the next decider step end the freeze after a shuttermint halt without
+// waiting for the resume votes of the other keypers.
+func (kpr *Keyper) requestHaltResume() {
+	atomic.StoreInt32(&kpr.haltResumeRequested, 1)
+}
+
+// takeHaltResumeRequest returns true once after requestHaltResume has been called.
+func (kpr *Keyper) takeHaltResumeRequest() bool {
+	return atomic.CompareAndSwapInt32(&kpr.haltResumeRequested, 1, 0)
+}
+
+func (kpr *Keyper) haltHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveHalt)
+}
+
+// serveHalt lets the operator end the freeze after a shuttermint halt. The freeze ends in the
+// next decider step, unless shuttermint is still halted.
+func (kpr *Keyper) serveHalt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path != haltResumePath {
+		http.NotFound(w, r)
+		return
+	}
+	kpr.requestHaltResume()
+	log.Printf("Resume after shuttermint halt requested via the admin API")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	httpapi.WriteJSON(w, map[string]bool{"Requested": true})
+}
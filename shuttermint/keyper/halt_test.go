@@ -0,0 +1,137 @@
+package keyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/policy"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestShutterHalted(t *testing.T) {
+	now := time.Unix(10000, 0)
+	shutter := observe.NewShutter()
+	assert.Assert(t, !shutterHalted(shutter, time.Minute, now))
+
+	shutter.NodeStatus = &rpctypes.ResultStatus{}
+	shutter.NodeStatus.SyncInfo.LatestBlockTime = now.Add(-2 * time.Minute)
+	assert.Assert(t, shutterHalted(shutter, time.Minute, now))
+	assert.Assert(t, !shutterHalted(shutter, 3*time.Minute, now))
+	assert.Assert(t, !shutterHalted(shutter, 0, now))
+
+	shutter.NodeStatus.SyncInfo.CatchingUp = true
+	assert.Assert(t, !shutterHalted(shutter, time.Minute, now))
+}
+
+func haltResumeVotes(dcdr *Decider) []*shmsg.HaltResumeVote {
+	var votes []*shmsg.HaltResumeVote
+	for _, action := range dcdr.Actions {
+		if send, ok := action.(*fx.SendShuttermintMessage); ok {
+			if vote := send.Msg.GetHaltResumeVote(); vote != nil {
+				votes = append(votes, vote)
+			}
+		}
+	}
+	dcdr.Actions = nil
+	return votes
+}
+
+func TestHaltResumeProtocol(t *testing.T) {
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 50
+	var deciders []*Decider
+	var keypers []common.Address
+	for i := 0; i < 3; i++ {
+		signingKey, err := crypto.GenerateKey()
+		assert.NilError(t, err)
+		config := Config{SigningKey: signingKey}
+		keypers = append(keypers, config.Address())
+		deciders = append(deciders, &Decider{
+			Config:    config,
+			State:     NewState(),
+			Shutter:   shutter,
+			MainChain: observe.NewMainChain(0),
+		})
+	}
+	shutter.BatchConfigs = []shutterevents.BatchConfig{{Keypers: keypers, Threshold: 2}}
+	a, c := deciders[0], deciders[2]
+
+	a.ShutterHalted = true
+	a.handleHalt()
+	assert.Assert(t, a.State.Halt != nil)
+	assert.Equal(t, a.State.Halt.Height, int64(50))
+	assert.Equal(t, a.policy().ReleaseKey(observe.World{}, policy.KeyRelease{}), policy.Delay)
+	assert.Equal(t, a.policy().Execute(observe.World{}, policy.Execution{}), policy.Delay)
+	assert.Equal(t, len(haltResumeVotes(a)), 0)
+
+	// once shuttermint produces blocks again, we vote to resume, but don't resend immediately
+	shutter.CurrentBlock = 60
+	a.ShutterHalted = false
+	a.handleHalt()
+	votes := haltResumeVotes(a)
+	assert.Equal(t, len(votes), 1)
+	assert.Equal(t, votes[0].HaltHeight, uint64(50))
+	a.handleHalt()
+	assert.Equal(t, len(haltResumeVotes(a)), 0)
+	assert.Assert(t, a.State.Halt != nil)
+
+	// keypers that haven't noticed the halt echo the vote once
+	shutter.HaltResumeVotes = append(shutter.HaltResumeVotes, shutterevents.HaltResumeVote{
+		Height: 55, Sender: keypers[0], HaltHeight: 50,
+	})
+	c.handleHalt()
+	votes = haltResumeVotes(c)
+	assert.Equal(t, len(votes), 1)
+	assert.Equal(t, votes[0].HaltHeight, uint64(50))
+	c.handleHalt()
+	assert.Equal(t, len(haltResumeVotes(c)), 0)
+	assert.Assert(t, c.State.Halt == nil)
+
+	// a threshold of votes after the halt height ends the freeze
+	a.handleHalt()
+	assert.Assert(t, a.State.Halt != nil)
+	shutter.HaltResumeVotes = append(shutter.HaltResumeVotes, shutterevents.HaltResumeVote{
+		Height: 58, Sender: keypers[2], HaltHeight: 50,
+	})
+	a.handleHalt()
+	assert.Assert(t, a.State.Halt == nil)
+	assert.Equal(t, a.policy().ReleaseKey(observe.World{}, policy.KeyRelease{}), policy.Allow)
+
+	// votes from before the halt height don't count, but the operator can override
+	shutter.CurrentBlock = 70
+	a.ShutterHalted = true
+	a.handleHalt()
+	shutter.CurrentBlock = 71
+	a.ShutterHalted = false
+	a.handleHalt()
+	assert.Assert(t, a.State.Halt != nil)
+	a.ForceHaltResume = true
+	a.handleHalt()
+	assert.Assert(t, a.State.Halt == nil)
+}
+
+func TestServeHalt(t *testing.T) {
+	kpr := &Keyper{}
+	assert.Assert(t, !kpr.takeHaltResumeRequest())
+
+	rec := httptest.NewRecorder()
+	kpr.haltHandler().ServeHTTP(rec, httptest.NewRequest("GET", haltResumePath, nil))
+	assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+	assert.Assert(t, !kpr.takeHaltResumeRequest())
+
+	rec = httptest.NewRecorder()
+	kpr.haltHandler().ServeHTTP(rec, httptest.NewRequest("POST", haltResumePath, nil))
+	assert.Equal(t, rec.Code, http.StatusAccepted)
+	assert.Assert(t, kpr.takeHaltResumeRequest())
+	assert.Assert(t, !kpr.takeHaltResumeRequest())
+}
@@ -0,0 +1,104 @@
+// Package httpapi implements the HTTP server the keyper's APIs are served by. The light client
+// API, the explorer and the admin API register their endpoints on a Server; APIs configured with
+// the same listen address share a single Server.
+//
+// Keypers serving multiple deployments set a namespace, usually the deployment ID, so that all
+// endpoints are served under /<namespace>/ and a client can't mistake keys from one deployment for
+// those of another. Requests outside the namespace are rejected.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shutdownTimeout limits the time spent waiting for open requests when shutting down.
+const shutdownTimeout = 5 * time.Second
+
+// validNamespace matches the namespaces accepted by ValidateNamespace.
+var validNamespace = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+// ValidateNamespace checks that the given namespace can be used as path segment. The empty
+// namespace is valid and means no namespace.
+func ValidateNamespace(namespace string) error {
+	if namespace != "" && !validNamespace.MatchString(namespace) {
+		return errors.Errorf(
+			"invalid namespace %q, must be up to 64 letters, digits, '.', '_', or '-', starting with a letter or digit",
+			namespace,
+		)
+	}
+	return nil
+}
+
+// Server serves the endpoints registered with Handle.
+type Server struct {
+	mux       *http.ServeMux
+	namespace string
+}
+
+// NewServer creates a new server without any endpoints.
+func NewServer() *Server {
+	return &Server{mux: http.NewServeMux()}
+}
+
+// Handle registers the handler for the given pattern, see http.ServeMux.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleFunc registers the handler function for the given pattern.
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// SetNamespace makes the server serve its endpoints under /<namespace>/, e.g. /<namespace>/eons
+// for an endpoint registered as /eons. The namespace must be valid, see ValidateNamespace.
+func (s *Server) SetNamespace(namespace string) {
+	s.namespace = namespace
+}
+
+// Handler returns the http handler serving all registered endpoints.
+func (s *Server) Handler() http.Handler {
+	if s.namespace == "" {
+		return s.mux
+	}
+	prefix := "/" + s.namespace
+	stripped := http.StripPrefix(prefix, s.mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			http.Error(w, fmt.Sprintf("unknown namespace, endpoints are served under %s/", prefix), http.StatusNotFound)
+			return
+		}
+		stripped.ServeHTTP(w, r)
+	})
+}
+
+// ListenAndServe serves the registered endpoints on the given address until the context is
+// canceled.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s.Handler()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	err := srv.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return errors.Wrapf(err, "http server at %s", addr)
+}
+
+// WriteJSON writes v as JSON response.
+func WriteJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
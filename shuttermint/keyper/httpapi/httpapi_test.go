@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateNamespace(t *testing.T) {
+	for _, ns := range []string{"", "production", "test-1", "v2.rinkeby_0"} {
+		assert.NilError(t, ValidateNamespace(ns), ns)
+	}
+	for _, ns := range []string{"-x", ".", "a/b", "a b", "prod%2F"} {
+		assert.ErrorContains(t, ValidateNamespace(ns), "invalid namespace", ns)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	srv := NewServer()
+	srv.SetNamespace("production")
+	srv.HandleFunc("/eons/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	})
+	httpServer := httptest.NewServer(srv.Handler())
+	defer httpServer.Close()
+
+	get := func(path string) (int, string) {
+		res, err := http.Get(httpServer.URL + path)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		body, err := ioutil.ReadAll(res.Body)
+		assert.NilError(t, err)
+		return res.StatusCode, string(body)
+	}
+
+	code, body := get("/production/eons/1")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, body, "/eons/1")
+
+	for _, path := range []string{"/eons/1", "/test/eons/1", "/productionx/eons/1", "/production"} {
+		code, _ = get(path)
+		assert.Equal(t, code, http.StatusNotFound, path)
+	}
+}
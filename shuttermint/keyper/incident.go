@@ -0,0 +1,11 @@
+package keyper
+
+// notifyIncidents hands the accusations and slashings observed since the last step to the incident
+// notifier. Incidents observed before the first step are treated as history and not reported.
+func (kpr *Keyper) notifyIncidents() {
+	if kpr.incidentHook == nil {
+		return
+	}
+	world := kpr.CurrentWorld()
+	kpr.incidentHook.Add(kpr.incidents.Detect(world.Shutter, world.MainChain))
+}
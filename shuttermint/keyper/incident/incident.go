@@ -0,0 +1,279 @@
+// Package incident notifies webhooks about accusations and slashings of any keyper, not only the
+// ones concerning us, so that the keyper community learns about incidents quickly. Incidents are
+// found in the observed shuttermint and main chain state: accusations in DKGs, accusations and
+// appeals in the keyper slasher, and slashed deposits.
+package incident
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// requestTimeout limits a single webhook request.
+const requestTimeout = 10 * time.Second
+
+// Kind is the kind of an incident.
+type Kind string
+
+const (
+	DKGAccusation Kind = "dkgAccusation" // a keyper accused others in a DKG on shuttermint
+	Accusation    Kind = "accusation"    // a keyper accused an executor in the keyper slasher
+	Appeal        Kind = "appeal"        // the accused executor appealed an accusation
+	Slashing      Kind = "slashing"      // a keyper's deposit has been slashed
+)
+
+// Incident is an accusation or slashing observed on shuttermint or the main chain.
+type Incident struct {
+	Kind     Kind             `json:"kind"`
+	Accuser  *common.Address  `json:"accuser,omitempty"`
+	Accused  []common.Address `json:"accused"`
+	Eon      uint64           `json:"eon,omitempty"`      // of DKG accusations
+	HalfStep uint64           `json:"halfStep,omitempty"` // of keyper slasher accusations and appeals
+	// Height is the shuttermint height of DKG accusations and the main chain block of keyper
+	// slasher accusations, 0 if unknown
+	Height int64 `json:"height,omitempty"`
+}
+
+func (i Incident) String() string {
+	accused := make([]string, len(i.Accused))
+	for j, a := range i.Accused {
+		accused[j] = a.Hex()
+	}
+	switch i.Kind {
+	case DKGAccusation:
+		return fmt.Sprintf("%s accused %s in the DKG of eon %d at height %d",
+			i.Accuser.Hex(), strings.Join(accused, ", "), i.Eon, i.Height)
+	case Accusation:
+		return fmt.Sprintf("%s accused %s of a wrong execution at half step %d in block %d",
+			i.Accuser.Hex(), strings.Join(accused, ", "), i.HalfStep, i.Height)
+	case Appeal:
+		return fmt.Sprintf("%s appealed the accusation at half step %d", strings.Join(accused, ", "), i.HalfStep)
+	default:
+		return fmt.Sprintf("the deposit of %s has been slashed", strings.Join(accused, ", "))
+	}
+}
+
+// Detector finds the incidents that are new in successive observations.
+type Detector struct {
+	seen   map[string]bool
+	primed bool
+}
+
+// NewDetector creates a detector. The first observation only records the incidents seen so far,
+// so that history isn't reported again after a restart.
+func NewDetector() *Detector {
+	return &Detector{seen: make(map[string]bool)}
+}
+
+// Detect returns the incidents in the given observations that haven't been returned before.
+func (d *Detector) Detect(shutter *observe.Shutter, mainChain *observe.MainChain) []Incident {
+	var incidents []Incident
+	add := func(key string, i Incident) {
+		if d.seen[key] {
+			return
+		}
+		d.seen[key] = true
+		incidents = append(incidents, i)
+	}
+
+	for _, eon := range shutter.Eons {
+		for _, a := range eon.Accusations {
+			accuser := a.Sender
+			add(fmt.Sprintf("dkg/%d/%d/%s", a.Eon, a.Height, a.Sender.Hex()), Incident{
+				Kind:    DKGAccusation,
+				Accuser: &accuser,
+				Accused: a.Accused,
+				Eon:     a.Eon,
+				Height:  a.Height,
+			})
+		}
+	}
+
+	halfSteps := make([]uint64, 0, len(mainChain.Accusations))
+	for halfStep := range mainChain.Accusations {
+		halfSteps = append(halfSteps, halfStep)
+	}
+	sort.Slice(halfSteps, func(i, j int) bool { return halfSteps[i] < halfSteps[j] })
+	for _, halfStep := range halfSteps {
+		a := mainChain.Accusations[halfStep]
+		accuser := a.Accuser
+		add(fmt.Sprintf("accusation/%d", halfStep), Incident{
+			Kind:     Accusation,
+			Accuser:  &accuser,
+			Accused:  []common.Address{a.Executor},
+			HalfStep: halfStep,
+			Height:   int64(a.BlockNumber),
+		})
+		if a.Appealed {
+			add(fmt.Sprintf("appeal/%d", halfStep), Incident{
+				Kind:     Appeal,
+				Accused:  []common.Address{a.Executor},
+				HalfStep: halfStep,
+			})
+		}
+	}
+
+	var slashed []common.Address
+	for account, deposit := range mainChain.Deposits {
+		if deposit.Slashed {
+			slashed = append(slashed, account)
+		}
+	}
+	sort.Slice(slashed, func(i, j int) bool { return bytes.Compare(slashed[i][:], slashed[j][:]) < 0 })
+	for _, account := range slashed {
+		add("slashing/"+account.Hex(), Incident{Kind: Slashing, Accused: []common.Address{account}})
+	}
+
+	if !d.primed {
+		d.primed = true
+		return nil
+	}
+	return incidents
+}
+
+// Payload is the JSON document posted to the webhooks. Text summarizes the incidents, so that
+// chat services accepting {"text": ...} can be used as webhooks directly.
+type Payload struct {
+	Keyper    common.Address `json:"keyper"` // the keyper sending the notification
+	Text      string         `json:"text"`
+	Incidents []Incident     `json:"incidents"`
+}
+
+// Notifier posts incidents to webhooks in the background, either as soon as they're added or as
+// a digest of the incidents added within an interval.
+type Notifier struct {
+	urls   []string
+	keyper common.Address
+	digest time.Duration
+	client *http.Client
+
+	mux     sync.Mutex
+	pending []Incident
+	wake    chan struct{}
+}
+
+// NewNotifier creates a notifier posting to the given webhook URLs on behalf of keyper. With a
+// digest interval of 0, incidents are posted as soon as they're added.
+func NewNotifier(urls []string, keyper common.Address, digest time.Duration) (*Notifier, error) {
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, errors.Errorf("invalid webhook URL %q, must be an absolute http(s) URL", u)
+		}
+	}
+	if digest < 0 {
+		return nil, errors.Errorf("invalid webhook digest interval %s, must not be negative", digest)
+	}
+	return &Notifier{
+		urls:   urls,
+		keyper: keyper,
+		digest: digest,
+		client: &http.Client{Timeout: requestTimeout},
+		wake:   make(chan struct{}, 1),
+	}, nil
+}
+
+// Add queues incidents for notification.
+func (n *Notifier) Add(incidents []Incident) {
+	if len(incidents) == 0 {
+		return
+	}
+	n.mux.Lock()
+	n.pending = append(n.pending, incidents...)
+	n.mux.Unlock()
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run posts the queued incidents until the context is canceled. Failed requests are logged, but
+// not repeated.
+func (n *Notifier) Run(ctx context.Context) error {
+	var tick <-chan time.Time
+	if n.digest > 0 {
+		ticker := time.NewTicker(n.digest)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-n.wake:
+			if n.digest > 0 {
+				continue
+			}
+		case <-tick:
+		}
+		n.flush(ctx)
+	}
+}
+
+// flush posts the pending incidents to all webhooks.
+func (n *Notifier) flush(ctx context.Context) {
+	n.mux.Lock()
+	incidents := n.pending
+	n.pending = nil
+	n.mux.Unlock()
+	if len(incidents) == 0 {
+		return
+	}
+
+	payload := Payload{Keyper: n.keyper, Text: Summary(incidents), Incidents: incidents}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err) // the payload only contains encodable types
+	}
+	for _, u := range n.urls {
+		if err := n.post(ctx, u, data); err != nil && ctx.Err() == nil {
+			log.Printf("Error: failed to notify webhook %s about %d incidents: %+v", u, len(incidents), err)
+		}
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, u string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+// Summary returns a human readable summary of the given incidents, one per line.
+func Summary(incidents []Incident) string {
+	lines := make([]string, len(incidents))
+	for i, incident := range incidents {
+		lines[i] = incident.String()
+	}
+	if len(incidents) == 1 {
+		return "Shutter keyper incident: " + lines[0]
+	}
+	return fmt.Sprintf("%d Shutter keyper incidents:\n%s", len(incidents), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,101 @@
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+var (
+	keyper1 = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	keyper2 = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	keyper3 = common.HexToAddress("0x3333333333333333333333333333333333333333")
+)
+
+func TestDetect(t *testing.T) {
+	shutter := &observe.Shutter{Eons: []observe.Eon{{Eon: 1}}}
+	mainChain := observe.NewMainChain(0)
+	d := NewDetector()
+
+	shutter.Eons[0].Accusations = []shutterevents.Accusation{
+		{Height: 10, Eon: 1, Sender: keyper1, Accused: []common.Address{keyper2}},
+	}
+	assert.Equal(t, len(d.Detect(shutter, mainChain)), 0) // history
+	assert.Equal(t, len(d.Detect(shutter, mainChain)), 0)
+
+	shutter.Eons[0].Accusations = append(shutter.Eons[0].Accusations, shutterevents.Accusation{
+		Height: 11, Eon: 1, Sender: keyper3, Accused: []common.Address{keyper2},
+	})
+	mainChain.Accusations[5] = &observe.Accusation{Executor: keyper2, Accuser: keyper1, HalfStep: 5, BlockNumber: 100}
+	incidents := d.Detect(shutter, mainChain)
+	assert.Equal(t, len(incidents), 2)
+	assert.Equal(t, incidents[0].Kind, DKGAccusation)
+	assert.Equal(t, *incidents[0].Accuser, keyper3)
+	assert.Equal(t, incidents[0].Height, int64(11))
+	assert.Equal(t, incidents[1].Kind, Accusation)
+	assert.DeepEqual(t, incidents[1].Accused, []common.Address{keyper2})
+	assert.Equal(t, incidents[1].HalfStep, uint64(5))
+
+	mainChain.Accusations[5].Appealed = true
+	mainChain.Deposits[keyper3] = &observe.Deposit{Account: keyper3, Slashed: true}
+	incidents = d.Detect(shutter, mainChain)
+	assert.Equal(t, len(incidents), 2)
+	assert.Equal(t, incidents[0].Kind, Appeal)
+	assert.Equal(t, incidents[1].Kind, Slashing)
+	assert.DeepEqual(t, incidents[1].Accused, []common.Address{keyper3})
+
+	// archiving an eon drops its accusations from memory, which isn't an incident
+	shutter.Eons[0].Accusations = nil
+	assert.Equal(t, len(d.Detect(shutter, mainChain)), 0)
+}
+
+func TestNewNotifier(t *testing.T) {
+	_, err := NewNotifier([]string{"https://hooks.example.com/x"}, keyper1, 0)
+	assert.NilError(t, err)
+	for _, u := range []string{"hooks.example.com/x", "ftp://hooks.example.com", "http://"} {
+		_, err := NewNotifier([]string{u}, keyper1, 0)
+		assert.ErrorContains(t, err, "invalid webhook URL")
+	}
+	_, err = NewNotifier(nil, keyper1, -1)
+	assert.ErrorContains(t, err, "invalid webhook digest interval")
+}
+
+func TestNotifierFlush(t *testing.T) {
+	var payloads []Payload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p Payload
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&p))
+		payloads = append(payloads, p)
+	}))
+	defer srv.Close()
+
+	n, err := NewNotifier([]string{srv.URL}, keyper1, 0)
+	assert.NilError(t, err)
+	n.flush(context.Background())
+	assert.Equal(t, len(payloads), 0)
+
+	accuser := keyper1
+	n.Add([]Incident{
+		{Kind: DKGAccusation, Accuser: &accuser, Accused: []common.Address{keyper2, keyper3}, Eon: 4, Height: 20},
+		{Kind: Slashing, Accused: []common.Address{keyper2}},
+	})
+	n.flush(context.Background())
+	assert.Equal(t, len(payloads), 1)
+	assert.Equal(t, payloads[0].Keyper, keyper1)
+	assert.Equal(t, len(payloads[0].Incidents), 2)
+	assert.Assert(t, strings.HasPrefix(payloads[0].Text, "2 Shutter keyper incidents:\n"))
+	assert.Assert(t, strings.Contains(payloads[0].Text, "in the DKG of eon 4 at height 20"))
+	assert.Assert(t, strings.Contains(payloads[0].Text, "the deposit of "+keyper2.Hex()+" has been slashed"))
+
+	n.flush(context.Background())
+	assert.Equal(t, len(payloads), 1)
+}
@@ -0,0 +1,86 @@
+package keyper
+
+import (
+	"path"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/ipfspub"
+	"github.com/shutter-network/shutter/shuttermint/keyper/lightapi"
+)
+
+// ipfsRoot returns the directory in the IPFS node's mutable file system we publish to.
+func (kpr *Keyper) ipfsRoot() string {
+	return path.Join("/shutter", kpr.Config.DeploymentID)
+}
+
+// updateIPFS hands the ceremony reports and epoch secret keys generated since the last step to
+// the IPFS publisher.
+func (kpr *Keyper) updateIPFS() {
+	if kpr.ipfsPublisher == nil {
+		return
+	}
+	if kpr.ipfsKeyCounts == nil {
+		kpr.ipfsKeyCounts = make(map[uint64]int)
+	}
+	for _, ekg := range kpr.State.EKGs {
+		if _, ok := kpr.ipfsKeyCounts[ekg.Eon]; !ok && ekg.Transcript != nil {
+			kpr.ipfsPublisher.AddCeremonyReport(ipfspub.CeremonyReport{
+				Eon:             ekg.Eon,
+				StartBatchIndex: ekg.StartBatchIndex,
+				Keypers:         ekg.Keypers,
+				StartHeight:     ekg.DKGStartHeight,
+				EndHeight:       ekg.DKGEndHeight,
+				Transcript:      lightapi.NewTranscriptJSON(ekg.Transcript),
+				Arrivals:        ekg.Arrivals,
+			})
+		}
+		if ekg.EpochKG == nil {
+			continue
+		}
+
+		// keys are only ever added, so unless their number changed there's nothing new
+		count := countKeys(ekg.EpochKG.SecretKeys)
+		for _, keys := range ekg.EpochKG.NamespacedSecretKeys {
+			count += countKeys(keys)
+		}
+		if n, ok := kpr.ipfsKeyCounts[ekg.Eon]; ok && n == count {
+			continue
+		}
+		kpr.ipfsKeyCounts[ekg.Eon] = count
+
+		for epoch, key := range ekg.EpochKG.SecretKeys {
+			if key != nil {
+				kpr.ipfsPublisher.AddEpochKey(ipfspub.EpochKey{
+					Eon:            ekg.Eon,
+					Epoch:          epoch,
+					EpochSecretKey: (*bn256.G1)(key).Marshal(),
+				})
+			}
+		}
+		for namespace, keys := range ekg.EpochKG.NamespacedSecretKeys {
+			for epoch, key := range keys {
+				if key != nil {
+					kpr.ipfsPublisher.AddEpochKey(ipfspub.EpochKey{
+						Eon:            ekg.Eon,
+						Namespace:      namespace,
+						Epoch:          epoch,
+						EpochSecretKey: (*bn256.G1)(key).Marshal(),
+					})
+				}
+			}
+		}
+	}
+}
+
+// countKeys counts the keys that have been computed successfully.
+func countKeys(keys map[uint64]*shcrypto.EpochSecretKey) int {
+	n := 0
+	for _, key := range keys {
+		if key != nil {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,305 @@
+// Package ipfspub publishes epoch secret keys and DKG ceremony reports to IPFS, giving
+// applications a way to retrieve them that doesn't depend on any keyper's HTTP endpoint.
+//
+// Documents are added and pinned via the HTTP API of an IPFS node and linked into its mutable
+// file system under deterministic names, see EpochKeyName and CeremonyName. The documents don't
+// depend on the keyper publishing them, so all keypers publishing to IPFS produce the same CIDs.
+package ipfspub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/dkgarrival"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/lightapi"
+)
+
+// retryInterval is how often we try again to publish documents that failed to publish.
+const retryInterval = 30 * time.Second
+
+// requestTimeout limits a single request to the IPFS node.
+const requestTimeout = time.Minute
+
+// EpochKey is the document published for an epoch secret key.
+type EpochKey struct {
+	Eon            uint64        `json:"eon"`
+	Namespace      string        `json:"namespace"` // empty for the default namespace
+	Epoch          uint64        `json:"epoch"`
+	EpochSecretKey hexutil.Bytes `json:"epochSecretKey"`
+}
+
+// CeremonyReport is the document published for the DKG ceremony of an eon.
+type CeremonyReport struct {
+	Eon             uint64                  `json:"eon"`
+	StartBatchIndex uint64                  `json:"startBatchIndex"`
+	Keypers         []common.Address        `json:"keypers"`
+	StartHeight     int64                   `json:"startHeight"` // shuttermint height the DKG started at
+	EndHeight       int64                   `json:"endHeight"`   // shuttermint height the DKG ended at
+	Transcript      lightapi.TranscriptJSON `json:"transcript"`
+	Arrivals        *dkgarrival.Report      `json:"arrivals,omitempty"` // DKG message arrivals relative to phase start
+}
+
+// EpochKeyName returns the path of an epoch secret key below the publisher's root directory.
+func EpochKeyName(key EpochKey) string {
+	namespace := key.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	return fmt.Sprintf("eon-%d/%s/epoch-%d.json", key.Eon, namespace, key.Epoch)
+}
+
+// CeremonyName returns the path of the ceremony report of an eon below the publisher's root
+// directory.
+func CeremonyName(eon uint64) string {
+	return fmt.Sprintf("eon-%d/ceremony.json", eon)
+}
+
+// Record describes a published document.
+type Record struct {
+	Name      string    `json:"name"` // relative to the root directory
+	Path      string    `json:"path"` // in the IPFS node's mutable file system
+	CID       string    `json:"cid"`
+	Published time.Time `json:"published"`
+}
+
+type document struct {
+	name string
+	data []byte
+}
+
+// Publisher publishes documents to IPFS in the background.
+type Publisher struct {
+	apiURL string
+	root   string
+	client *http.Client
+
+	mux       sync.Mutex
+	pending   []document
+	queued    map[string]bool // names of pending and published documents
+	published map[string]Record
+	wake      chan struct{}
+}
+
+// NewPublisher creates a publisher using the IPFS node whose HTTP API is served at apiURL, e.g.
+// "http://localhost:5001". Documents are linked into the node's mutable file system below root,
+// e.g. "/shutter/production".
+func NewPublisher(apiURL string, root string) *Publisher {
+	return &Publisher{
+		apiURL:    strings.TrimSuffix(apiURL, "/"),
+		root:      path.Clean("/" + root),
+		client:    &http.Client{Timeout: requestTimeout},
+		queued:    make(map[string]bool),
+		published: make(map[string]Record),
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// AddEpochKey queues an epoch secret key for publication, unless it has been added already.
+func (p *Publisher) AddEpochKey(key EpochKey) {
+	p.add(EpochKeyName(key), key)
+}
+
+// AddCeremonyReport queues the ceremony report of an eon for publication, unless it has been
+// added already.
+func (p *Publisher) AddCeremonyReport(report CeremonyReport) {
+	p.add(CeremonyName(report.Eon), report)
+}
+
+func (p *Publisher) add(name string, v interface{}) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.queued[name] {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err) // the documents only contain encodable types
+	}
+	p.queued[name] = true
+	p.pending = append(p.pending, document{name: name, data: data})
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Records returns the published documents ordered by name.
+func (p *Publisher) Records() []Record {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	res := []Record{}
+	for _, r := range p.published {
+		res = append(res, r)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// Record returns the record of the published document with the given name.
+func (p *Publisher) Record(name string) (Record, bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	r, ok := p.published[name]
+	return r, ok
+}
+
+// Run publishes the queued documents until the context is canceled. Documents that fail to
+// publish are retried. Errors are logged, but don't stop the publisher.
+func (p *Publisher) Run(ctx context.Context) error {
+	for {
+		if err := p.publishPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Error publishing to IPFS: %+v", err)
+		}
+		var retry <-chan time.Time
+		if p.numPending() > 0 {
+			retry = time.After(retryInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.wake:
+		case <-retry:
+		}
+	}
+}
+
+func (p *Publisher) numPending() int {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return len(p.pending)
+}
+
+// publishPending publishes the pending documents in order, stopping at the first failure.
+func (p *Publisher) publishPending(ctx context.Context) error {
+	for {
+		p.mux.Lock()
+		if len(p.pending) == 0 {
+			p.mux.Unlock()
+			return nil
+		}
+		doc := p.pending[0]
+		p.mux.Unlock()
+
+		record, err := p.publish(ctx, doc)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to publish %s", doc.name)
+		}
+		p.mux.Lock()
+		p.pending = p.pending[1:]
+		p.published[doc.name] = record
+		p.mux.Unlock()
+	}
+}
+
+// publish adds and pins the document and links it into the mutable file system. Documents
+// already linked, e.g. by an earlier run, are not added again.
+func (p *Publisher) publish(ctx context.Context, doc document) (Record, error) {
+	record := Record{Name: doc.name, Path: path.Join(p.root, doc.name), Published: time.Now()}
+	var stat struct{ Hash string }
+	err := p.call(ctx, "files/stat", url.Values{"arg": {record.Path}}, nil, &stat)
+	if err == nil {
+		record.CID = stat.Hash
+		return record, nil
+	}
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("file", path.Base(doc.name))
+	if err != nil {
+		return Record{}, err
+	}
+	if _, err := fw.Write(doc.data); err != nil {
+		return Record{}, err
+	}
+	if err := mw.Close(); err != nil {
+		return Record{}, err
+	}
+	var added struct{ Hash string }
+	params := url.Values{"pin": {"true"}, "cid-version": {"1"}}
+	if err := p.call(ctx, "add", params, &multipartBody{mw.FormDataContentType(), body}, &added); err != nil {
+		return Record{}, err
+	}
+	record.CID = added.Hash
+
+	params = url.Values{"arg": {"/ipfs/" + record.CID, record.Path}, "parents": {"true"}}
+	if err := p.call(ctx, "files/cp", params, nil, nil); err != nil {
+		return Record{}, err
+	}
+	log.Printf("Published %s to IPFS as %s", record.Path, record.CID)
+	return record, nil
+}
+
+type multipartBody struct {
+	contentType string
+	data        *bytes.Buffer
+}
+
+// call calls an endpoint of the IPFS HTTP API and decodes the JSON response into res, unless
+// it's nil.
+func (p *Publisher) call(
+	ctx context.Context, endpoint string, params url.Values, body *multipartBody, res interface{},
+) error {
+	u := p.apiURL + "/api/v0/" + endpoint + "?" + params.Encode()
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, u, body.data)
+		if err == nil {
+			req.Header.Set("Content-Type", body.contentType)
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	}
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("IPFS %s responded with %d: %s", endpoint, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if res == nil {
+		return nil
+	}
+	return errors.Wrapf(json.Unmarshal(data, res), "invalid response of IPFS %s", endpoint)
+}
+
+// Mount serves the published documents at /ipfs, and the record of a single document at
+// /ipfs/<name>, e.g. /ipfs/eon-1/ceremony.json.
+func (p *Publisher) Mount(srv *httpapi.Server) {
+	srv.HandleFunc("/ipfs", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, p.Records())
+	})
+	srv.HandleFunc("/ipfs/", func(w http.ResponseWriter, r *http.Request) {
+		record, ok := p.Record(strings.TrimPrefix(r.URL.Path, "/ipfs/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		httpapi.WriteJSON(w, record)
+	})
+}
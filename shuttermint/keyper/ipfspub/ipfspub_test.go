@@ -0,0 +1,82 @@
+package ipfspub
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeNode implements the parts of the IPFS HTTP API used by the publisher.
+type fakeNode struct {
+	mux   sync.Mutex
+	files map[string]string // path => cid
+	added []string          // contents of added files
+}
+
+func (n *fakeNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+	args := r.URL.Query()["arg"]
+	switch r.URL.Path {
+	case "/api/v0/files/stat":
+		cid, ok := n.files[args[0]]
+		if !ok {
+			http.Error(w, "file does not exist", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Hash": cid})
+	case "/api/v0/add":
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := ioutil.ReadAll(f)
+		n.added = append(n.added, string(data))
+		json.NewEncoder(w).Encode(map[string]string{"Hash": "cid" + string(rune('0'+len(n.added)))})
+	case "/api/v0/files/cp":
+		n.files[args[1]] = args[0][len("/ipfs/"):]
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	node := &fakeNode{files: make(map[string]string)}
+	srv := httptest.NewServer(node)
+	defer srv.Close()
+	ctx := context.Background()
+
+	p := NewPublisher(srv.URL, "shutter/test")
+	key := EpochKey{Eon: 2, Epoch: 7, EpochSecretKey: []byte{1, 2}}
+	p.AddEpochKey(key)
+	p.AddEpochKey(key) // ignored
+	p.AddCeremonyReport(CeremonyReport{Eon: 2})
+	assert.NilError(t, p.publishPending(ctx))
+
+	assert.Equal(t, len(node.added), 2)
+	assert.Equal(t, node.added[0], `{"eon":2,"namespace":"","epoch":7,"epochSecretKey":"0x0102"}`)
+	assert.DeepEqual(t, node.files, map[string]string{
+		"/shutter/test/eon-2/default/epoch-7.json": "cid1",
+		"/shutter/test/eon-2/ceremony.json":        "cid2",
+	})
+	records := p.Records()
+	assert.Equal(t, len(records), 2)
+	assert.Equal(t, records[0].Name, "eon-2/ceremony.json")
+	assert.Equal(t, records[1].CID, "cid1")
+
+	// after a restart, documents that have been published already aren't added again
+	p = NewPublisher(srv.URL, "/shutter/test/")
+	p.AddEpochKey(key)
+	assert.NilError(t, p.publishPending(ctx))
+	assert.Equal(t, len(node.added), 2)
+	record, ok := p.Record(EpochKeyName(key))
+	assert.Assert(t, ok)
+	assert.Equal(t, record.CID, "cid1")
+}
@@ -3,27 +3,53 @@ package keyper
 
 import (
 	"context"
-	"encoding/gob"
+	"crypto/ed25519"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
-	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/kr/pretty"
 	"github.com/pkg/errors"
 	"github.com/tendermint/tendermint/rpc/client"
 	"github.com/tendermint/tendermint/rpc/client/http"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/abidrift"
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+	"github.com/shutter-network/shutter/shuttermint/keyper/dedup"
+	"github.com/shutter-network/shutter/shuttermint/keyper/eonstore"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/eventstream"
+	"github.com/shutter-network/shutter/shuttermint/keyper/explorer"
 	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/incident"
+	"github.com/shutter-network/shutter/shuttermint/keyper/ipfspub"
+	"github.com/shutter-network/shutter/shuttermint/keyper/latency"
+	"github.com/shutter-network/shutter/shuttermint/keyper/leakwatch"
+	"github.com/shutter-network/shutter/shuttermint/keyper/lightapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/metrics"
 	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/policy"
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+	"github.com/shutter-network/shutter/shuttermint/keyper/rpccache"
+	"github.com/shutter-network/shutter/shuttermint/keyper/sharebackup"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/keyper/trace"
+	"github.com/shutter-network/shutter/shuttermint/keyper/txpolicy"
+	"github.com/shutter-network/shutter/shuttermint/keyper/validatorwatch"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
 
 // IsWebsocketURL returns true iff the given URL is a websocket URL, i.e. if it starts with ws://
@@ -36,33 +62,106 @@ type Keyper struct {
 	// Keep the atomic.Value as first field in order to make sure it's 64-bit aligned. Visit
 	// https://golang.org/pkg/sync/atomic/#pkg-note-BUG for more information
 	world atomic.Value // holds an observe.World struct
-
-	Config Config // Configuration of the keyper client read from the config file
-	State  *State // keyper's internal state
+	// accusations holds a copy of State.UnjustifiedAccusations, see updateAccusations
+	accusations atomic.Value
+	heartbeat   atomic.Value // holds a stepHeartbeat
+	// blockedDealing holds the []BlockedDealing of the ongoing DKGs, see updateBlockedDealing
+	blockedDealing atomic.Value
+	// dkgArrivals holds the []*dkgarrival.Report of the EKGs, see updateDKGArrivals
+	dkgArrivals atomic.Value
+	// keyperStatus holds the KeyperSetStatus of the current config, see updateKeyperStatus
+	keyperStatus atomic.Value
+	// status holds our Status, see updateStatus
+	status         atomic.Value
+	fenced         int32 // set to 1 once a standby keyper has taken over
+	releasesPaused int32 // set to 1 while key releases are paused via the admin API
+	// haltResumeRequested is set to 1 if the operator wants to end the freeze after a shuttermint
+	// halt, see requestHaltResume
+	haltResumeRequested int32
+
+	Config Config        // Configuration of the keyper client read from the config file
+	State  *State        // keyper's internal state
+	Policy policy.Policy // decides if keys may be released and batches executed, nil for the default
+	// Candidate is decision logic run in shadow mode next to the stable one, nil to disable
+	Candidate DecideFunc
+	// LoadConfig reads the config again when the keyper is asked to reload it, nil to disable
+	// reloading, see reloadConfig
+	LoadConfig func() (Config, error)
 
 	ContractCaller contract.Caller
 	shmcl          client.Client
 	MessageSender  fx.MessageSender
 	lastlogTime    time.Time
+	// stateStore persists State and the observed chains after every decider step
+	stateStore     StateStore
 	runenv         *fx.RunEnv
-
-	mainChainCh     chan *observe.MainChain    // observed main chain updates
-	shutterCh       chan *observe.Shutter      // observed shutter updates
-	signalCh        chan os.Signal             // signals received
-	shutterFilterCh chan observe.ShutterFilter // new shutter filter for garbage collecting the shutter state
+	gasPriceOracle *gaspricer.SwitchableOracle // switched when the config is reloaded
+	shareCache     *epochkg.ShareCache         // precomputed epoch secret key shares
+	polyEvalCache  *PolyEvalCache              // decrypted poly evals
+	latency        *latency.Tracker
+	lightAPI       *lightapi.Server           // nil if disabled
+	httpServers    map[string]*httpapi.Server // by listen address
+	eventStream    *eventstream.Server        // nil if disabled
+	trace          *trace.Buffer              // nil if disabled
+	eonArchive     *eonstore.Store            // nil if disabled
+	retention      retention.Policies         // by dataset
+	retainedEon    uint64                     // last eon the archive retention policy has been applied for
+	shareBackup    sharebackup.Custodians     // disabled without custodians
+	backedUpEons   map[uint64]bool            // eons whose key share backups have been exported
+	rpcCache       *rpccache.Store            // nil if disabled
+	validatorWatch *validatorwatch.Watcher    // nil if disabled
+	leakWatch      *leakwatch.Detector        // nil if disabled
+	leakWatchNext  uint64                     // first batch not handed to the leak detector yet
+	abiDrift       *abidrift.Checker          // nil if disabled
+	ipfsPublisher  *ipfspub.Publisher         // nil if disabled
+	ipfsKeyCounts  map[uint64]int             // number of epoch secret keys handed to the IPFS publisher by eon
+	incidents      *incident.Detector         // nil if disabled
+	incidentHook   *incident.Notifier         // nil if disabled
+	metrics        *metrics.Metrics           // nil if disabled
+	throttle       *bandwidthThrottle         // nil if disabled
+	syncing        bool                       // true until we've caught up with both chains after startup
+	syncStarted    time.Time
+	syncProgress   syncProgress
+	skippedSteps   map[string]uint64 // number of steps skipped because of bad state, by reason
+	divergences    uint64            // number of steps in which the candidate decider diverged
+	lastSkipLog    time.Time
+
+	replicatedMessages []*shmsg.Message // messages decided by the primary, if we're a standby
+	polyEvalsResumed   bool             // outgoing poly evals have been reconciled after startup
+	pruneRequests      chan uint64      // number of eons to prune the state to, see requestPrune
+	// syncLoopCalls are the calls of the admin RPC API to be run by the sync loop, see
+	// runInSyncLoop
+	syncLoopCalls chan syncLoopCall
+
+	mainChainCh       chan *observe.MainChain      // observed main chain updates
+	shutterCh         chan *observe.Shutter        // observed shutter updates
+	signalCh          chan os.Signal               // signals received
+	reloadCh          chan os.Signal               // signals to reload the config, see reloadConfig
+	shutterFilterCh   chan observe.ShutterFilter   // new shutter filter for garbage collecting the shutter state
+	mainChainFilterCh chan observe.MainChainFilter // new main chain filter for garbage collecting the main chain state
 }
 
 func NewKeyper(kc Config) Keyper {
+	mainChain := observe.NewMainChain(kc.MainChainFollowDistance)
+	mainChain.Confirmations = kc.confirmations()
 	world := atomic.Value{}
 	world.Store(observe.World{
 		Shutter:   observe.NewShutter(),
-		MainChain: observe.NewMainChain(kc.MainChainFollowDistance),
+		MainChain: mainChain,
 	})
 
 	return Keyper{
-		Config: kc,
-		State:  NewState(),
-		world:  world,
+		Config:     kc,
+		State:      NewState(),
+		world:      world,
+		stateStore: NewGobStateStore(kc.DBDir),
+		shareCache: epochkg.NewShareCache(),
+		latency:    latency.NewTracker(kc.KeyReleaseSLO),
+		syncing:    true,
+
+		polyEvalCache: NewPolyEvalCache(polyEvalCacheSize),
+		pruneRequests: make(chan uint64, 1),
+		syncLoopCalls: make(chan syncLoopCall),
 	}
 }
 
@@ -101,7 +200,12 @@ func NewContractCallerFromConfig(config Config) (contract.Caller, error) {
 		return contract.Caller{}, err
 	}
 
-	return contract.NewCaller(
+	decryptionOracleContract, err := contract.NewDecryptionOracleContract(config.DecryptionOracleAddress, ethcl)
+	if err != nil {
+		return contract.Caller{}, err
+	}
+
+	cc := contract.NewCaller(
 		ethcl,
 		config.SigningKey,
 		configContract,
@@ -110,7 +214,94 @@ func NewContractCallerFromConfig(config Config) (contract.Caller, error) {
 		executorContract,
 		depositContract,
 		keyperSlasher,
-	), nil
+		decryptionOracleContract,
+	)
+	cc.Cache = contract.NewCache(config.ContractCacheTTL)
+	cc.EventSources = contract.EventSources{
+		Batcher:       config.BatcherContractAddress,
+		Deposit:       config.DepositContractAddress,
+		KeyperSlasher: config.KeyperSlasherAddress,
+	}
+	if len(config.ExecutorRoutes) > 0 {
+		routes := []contract.ExecutorRoute{{
+			StartBatchIndex: 0,
+			Address:         config.ExecutorContractAddress,
+			Executor:        executorContract,
+		}}
+		for _, s := range config.ExecutorRoutes {
+			route, err := contract.ParseExecutorRoute(s, ethcl)
+			if err != nil {
+				return contract.Caller{}, err
+			}
+			routes = append(routes, route)
+		}
+		cc.ExecutorRoutes, err = contract.NewExecutorRoutes(routes...)
+		if err != nil {
+			return contract.Caller{}, err
+		}
+	}
+	return cc, nil
+}
+
+// checkContractVersions checks that the configured contracts match the ones we've been built
+// against. Unknown, but compatible versions are only accepted if RequireKnownContracts is not set.
+func checkContractVersions(ctx context.Context, config Config, client contract.CodeReader) error {
+	if err := config.RegisterContractCodeHashes(); err != nil {
+		return err
+	}
+
+	for _, c := range configuredContracts(config) {
+		check, err := contract.CheckVersion(ctx, client, c.Name, c.Address)
+		if err != nil {
+			return err
+		}
+		switch {
+		case check.Status == contract.VersionKnown:
+		case check.Status == contract.VersionCompatible && !config.RequireKnownContracts:
+			log.Printf("Warning: unknown contract version: %s", check)
+		default:
+			return errors.Errorf("refusing to use contract: %s", check)
+		}
+	}
+	return nil
+}
+
+// configuredContracts returns the contracts with a configured address, named as in
+// contract.KnownVersions.
+func configuredContracts(config Config) []abidrift.Contract {
+	var contracts []abidrift.Contract
+	for _, c := range []abidrift.Contract{
+		{Name: "ConfigContract", Address: config.ConfigContractAddress},
+		{Name: "KeyBroadcastContract", Address: config.KeyBroadcastContractAddress},
+		{Name: "BatcherContract", Address: config.BatcherContractAddress},
+		{Name: "ExecutorContract", Address: config.ExecutorContractAddress},
+		{Name: "DepositContract", Address: config.DepositContractAddress},
+		{Name: "KeyperSlasher", Address: config.KeyperSlasherAddress},
+		{Name: "DecryptionOracleContract", Address: config.DecryptionOracleAddress},
+	} {
+		if c.Address != (common.Address{}) {
+			contracts = append(contracts, c)
+		}
+	}
+	return contracts
+}
+
+// checkABIDrift checks the recent logs of the configured contracts against our ABIs. Drift is only
+// fatal if RequireKnownContracts is set. Failing to fetch the logs isn't, as some nodes limit log
+// queries.
+func checkABIDrift(ctx context.Context, config Config, checker *abidrift.Checker) error {
+	drifts, err := checker.Check(ctx)
+	if err != nil {
+		log.Printf("Warning: cannot check contract logs against our ABIs: %s", err)
+		return nil
+	}
+	for _, drift := range drifts {
+		log.Printf("Warning: contract log doesn't match our ABI, the contract may have been upgraded: %s", drift)
+	}
+	if len(drifts) > 0 && config.RequireKnownContracts {
+		return errors.Errorf("refusing to use contracts, %d recent logs don't match our ABIs", len(drifts))
+	}
+	return nil
 }
 
 func (kpr *Keyper) init() error {
@@ -126,6 +317,17 @@ func (kpr *Keyper) init() error {
 	if err != nil {
 		return errors.Wrapf(err, "start shuttermint client")
 	}
+	if kpr.Config.RPCCacheSize > 0 {
+		if kpr.Config.RPCCacheOnDisk {
+			kpr.rpcCache, err = rpccache.Open(kpr.Config.RPCCacheSize, kpr.Config.DBDir)
+			if err != nil {
+				return err
+			}
+		} else {
+			kpr.rpcCache = rpccache.NewStore(kpr.Config.RPCCacheSize, nil)
+		}
+		kpr.shmcl = rpccache.NewShuttermint(kpr.shmcl, kpr.rpcCache)
+	}
 	ms := fx.NewRPCMessageSender(kpr.shmcl, kpr.Config.SigningKey)
 	kpr.MessageSender = &ms
 
@@ -133,16 +335,223 @@ func (kpr *Keyper) init() error {
 	if err != nil {
 		return err
 	}
+	chainID, err := kpr.ContractCaller.Ethclient.ChainID(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to query main chain id")
+	}
+	if err := kpr.Config.CheckChain(chainID); err != nil {
+		return err
+	}
+	err = checkContractVersions(context.Background(), kpr.Config, kpr.ContractCaller.Ethclient)
+	if err != nil {
+		return err
+	}
+	abiDrift, err := abidrift.NewChecker(kpr.ContractCaller.Ethclient, configuredContracts(kpr.Config))
+	if err != nil {
+		return err
+	}
+	if err := checkABIDrift(context.Background(), kpr.Config, abiDrift); err != nil {
+		return err
+	}
+	if kpr.Config.ABIDriftCheckInterval > 0 {
+		kpr.abiDrift = abiDrift
+	}
+	var ethcl leakwatch.Client = kpr.ContractCaller.Ethclient
+	if kpr.rpcCache != nil {
+		cached := rpccache.NewEthereum(kpr.ContractCaller.Ethclient, kpr.rpcCache, kpr.Config.MainChainFollowDistance)
+		kpr.ContractCaller.HeaderReader = cached
+		ethcl = cached
+	}
+	oracle, err := gaspricer.ParseOracle(kpr.Config.GasPriceOracle)
+	if err != nil {
+		return err
+	}
+	kpr.gasPriceOracle = gaspricer.NewSwitchableOracle(oracle)
+	kpr.ContractCaller.GasPriceOracle = kpr.gasPriceOracle
+	if err := httpapi.ValidateNamespace(kpr.Config.DeploymentID); err != nil {
+		return errors.Wrap(err, "invalid DeploymentID")
+	}
+	if kpr.Config.LightAPIListenAddress != "" {
+		kpr.lightAPI = lightapi.NewServer(kpr.shmcl)
+		kpr.lightAPI.Mount(kpr.httpServer(kpr.Config.LightAPIListenAddress))
+	}
+	duplicates, err := dedup.ParsePolicy(kpr.Config.DuplicateCiphertexts)
+	if err != nil {
+		return err
+	}
+	if duplicates == dedup.Reject {
+		return errors.Errorf("invalid DuplicateCiphertexts %q, ciphertexts in batches can only be dropped", duplicates)
+	}
+	if kpr.Config.KeepBatches > 0 && kpr.Config.KeepBatches < kpr.Config.CiphertextReplayWindow {
+		return errors.Errorf(
+			"KeepBatches must be at least CiphertextReplayWindow (%d) to check the ciphertexts for replays",
+			kpr.Config.CiphertextReplayWindow,
+		)
+	}
+	if _, err := txpolicy.NewPolicy(
+		kpr.Config.TransactionFormat, kpr.Config.MaxTransactionSize, kpr.Config.DeniedTargets,
+	); err != nil {
+		return err
+	}
+	kpr.retention, err = kpr.Config.retentionPolicies()
+	if err != nil {
+		return err
+	}
+	kpr.shareBackup, err = sharebackup.ParseCustodians(kpr.Config.ShareBackupKeys, kpr.Config.ShareBackupThreshold)
+	if err != nil {
+		return err
+	}
+	apiAccess, err := access.ParseMode(kpr.Config.APIAccess)
+	if err != nil {
+		return err
+	}
+	if kpr.Config.EventStreamListenAddress != "" {
+		kpr.eventStream = eventstream.NewServer(eventstream.ShuttermintSource{Client: kpr.shmcl})
+		audience := access.Audience(access.EventStreamAPI, kpr.Config.Address())
+		kpr.eventStream.SetAccess(access.NewChecker(apiAccess, audience, func(addr common.Address) bool {
+			return kpr.CurrentWorld().Shutter.IsKeyper(addr)
+		}))
+		kpr.eventStream.SetNamespace(kpr.Config.DeploymentID)
+	}
+	if kpr.Config.MetricsListenAddress != "" {
+		kpr.metrics = metrics.New()
+		kpr.httpServer(kpr.Config.MetricsListenAddress).Handle("/metrics", kpr.metrics.Handler())
+	}
+	if kpr.Config.ExplorerListenAddress != "" {
+		explorer.NewServer(kpr.CurrentWorld).Mount(kpr.httpServer(kpr.Config.ExplorerListenAddress))
+	}
+	if kpr.Config.RelayerListenAddress != "" {
+		if err := kpr.initRelayer(); err != nil {
+			return err
+		}
+	}
+	if kpr.Config.ValidatorWatchWindow > 0 {
+		kpr.validatorWatch = validatorwatch.NewWatcher(
+			kpr.shmcl,
+			kpr.Config.ValidatorKey.Public().(ed25519.PublicKey),
+			int(kpr.Config.ValidatorWatchWindow),
+			int(kpr.Config.ValidatorMaxMissedBlocks),
+		)
+		kpr.validatorWatch.SetPerformance(kpr.latencyinfo)
+		kpr.validatorWatch.SetMaxClockSkew(kpr.Config.MaxClockSkew)
+	}
+	if kpr.Config.WatchKeyLeaks {
+		kpr.leakWatch = leakwatch.NewDetector(ethcl, kpr.executorAddresses())
+	}
+	if kpr.Config.IPFSPublishURL != "" {
+		kpr.ipfsPublisher = ipfspub.NewPublisher(kpr.Config.IPFSPublishURL, kpr.ipfsRoot())
+		if kpr.Config.LightAPIListenAddress != "" {
+			kpr.ipfsPublisher.Mount(kpr.httpServer(kpr.Config.LightAPIListenAddress))
+		}
+	}
+	if len(kpr.Config.WebhookURLs) > 0 {
+		kpr.incidents = incident.NewDetector()
+		kpr.incidentHook, err = incident.NewNotifier(
+			kpr.Config.WebhookURLs, kpr.Config.Address(), kpr.Config.WebhookDigestInterval)
+		if err != nil {
+			return err
+		}
+	}
+	if kpr.Config.ShuttermintBandwidth > 0 && kpr.Config.ThrottleReleaseBlocks > 0 {
+		kpr.throttle = newBandwidthThrottle(kpr.Config.ShuttermintBandwidth, kpr.Config.ThrottleReleaseBlocks)
+	}
+	if kpr.Config.DecisionTraceSize > 0 {
+		kpr.trace = trace.NewBuffer(kpr.Config.DecisionTraceSize)
+	}
+	if kpr.Config.AdminListenAddress != "" {
+		// The admin API is for the operators only, i.e. for the accounts in AdminAccounts and
+		// clients authenticating with our key, which always have the security-admin role
+		self := kpr.Config.Address()
+		accounts, err := access.ParseAccounts(kpr.Config.AdminAccounts)
+		if err != nil {
+			return err
+		}
+		accounts[self] = access.SecurityAdmin
+		adminAccess := access.NewRoleChecker(access.Audience(access.AdminAPI, self), accounts, access.LogAudit)
+		adminServer := kpr.httpServer(localAddress(kpr.Config.AdminListenAddress))
+		if kpr.trace != nil {
+			adminServer.Handle("/trace", adminAccess.RequireRole(access.Viewer, kpr.trace))
+		}
+		adminServer.Handle("/accusations", adminAccess.RequireRole(access.Viewer, kpr.accusationsHandler()))
+		adminServer.Handle("/dealing/blocked", adminAccess.RequireRole(access.Viewer, kpr.blockedDealingHandler()))
+		adminServer.Handle("/dkg/arrivals", adminAccess.RequireRole(access.Viewer, kpr.dkgArrivalsHandler()))
+		adminServer.Handle("/keypers/status", adminAccess.RequireRole(access.Viewer, kpr.keyperStatusHandler()))
+		adminServer.Handle("/status", adminAccess.RequireRole(access.Viewer, kpr.statusHandler()))
+		adminServer.Handle("/actions/dead", adminAccess.RequireRole(access.Viewer, kpr.deadActionsHandler()))
+		adminRPC, err := kpr.newAdminRPCHandler()
+		if err != nil {
+			return err
+		}
+		adminServer.Handle("/rpc", adminAccess.RequireRole(access.Operator, adminRPC))
+		adminServer.Handle("/state/prune", adminAccess.RequireRole(access.Operator, kpr.pruneHandler()))
+		adminServer.Handle("/releases/", adminAccess.RequireRole(access.SecurityAdmin, kpr.releasePauseHandler()))
+		adminServer.Handle("/halt/", adminAccess.RequireRole(access.SecurityAdmin, kpr.haltHandler()))
+		adminServer.Handle("/decryptions/", adminAccess.RequireRole(access.SecurityAdmin, kpr.decryptionApprovalHandler()))
+		adminServer.Handle("/standby/", adminAccess.RequireRole(access.SecurityAdmin, kpr.standbyHandler()))
+	}
+	if kpr.retention[retention.Observe].Full > 0 {
+		kpr.eonArchive, err = eonstore.Open(kpr.Config.DBDir)
+		if err != nil {
+			return err
+		}
+	}
 	kpr.runenv = fx.NewRunEnv(kpr.MessageSender, &kpr.ContractCaller, kpr.CurrentWorld, kpr.pathActionsGob())
+	kpr.runenv.Metrics = kpr.metrics
+	kpr.runenv.DeadLetters = fx.NewDeadLetters(kpr.pathDeadActionsGob())
+	kpr.runenv.Settings, err = runEnvSettings(kpr.Config)
+	if err != nil {
+		return err
+	}
+	if kpr.Config.MessageDeliveryBlocks > 0 {
+		kpr.runenv.Deliveries = fx.NewDeliveryTracker(
+			kpr.Config.Address(),
+			int64(kpr.Config.MessageDeliveryBlocks),
+			int(kpr.Config.MessageMaxResends),
+			func(ctx context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx)) error {
+				return observe.FetchTxs(ctx, kpr.shmcl, fromHeight, toHeight, handler)
+			},
+		)
+	}
 	kpr.mainChainCh = make(chan *observe.MainChain)
 	kpr.shutterCh = make(chan *observe.Shutter)
 	kpr.signalCh = make(chan os.Signal, 1)
 	kpr.shutterFilterCh = make(chan observe.ShutterFilter, 3)
-	signal.Notify(kpr.signalCh, syscall.SIGUSR1)
+	kpr.mainChainFilterCh = make(chan observe.MainChainFilter, 3)
+	if len(dumpStateSignals) > 0 {
+		signal.Notify(kpr.signalCh, dumpStateSignals...)
+	}
+	kpr.reloadCh = make(chan os.Signal, 1)
+	if len(reloadConfigSignals) > 0 && kpr.LoadConfig != nil {
+		signal.Notify(kpr.reloadCh, reloadConfigSignals...)
+	}
 
 	return nil
 }
 
+// httpServer returns the HTTP server listening on the given address, creating it if necessary.
+// APIs configured with the same listen address share a server.
+func (kpr *Keyper) httpServer(addr string) *httpapi.Server {
+	if kpr.httpServers == nil {
+		kpr.httpServers = make(map[string]*httpapi.Server)
+	}
+	srv, ok := kpr.httpServers[addr]
+	if !ok {
+		srv = httpapi.NewServer()
+		srv.SetNamespace(kpr.Config.DeploymentID)
+		kpr.httpServers[addr] = srv
+	}
+	return srv
+}
+
+// localAddress binds listen addresses without a host, e.g. ":8082", to localhost.
+func localAddress(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
+
 func (kpr *Keyper) dkginfo() string {
 	var ds []string
 	for i := len(kpr.State.DKGs) - 1; i >= 0; i-- {
@@ -159,6 +568,36 @@ func (kpr *Keyper) dkginfo() string {
 	return fmt.Sprintf(", DKGs: %s", strings.Join(ds, " - "))
 }
 
+func (kpr *Keyper) latencyinfo() string {
+	report := kpr.latency.Report()
+	if report.KeyGeneration.Count == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", key release p90: %s", report.KeyGeneration.P90)
+}
+
+func (kpr *Keyper) validatorinfo() string {
+	if kpr.validatorWatch == nil {
+		return ""
+	}
+	report := kpr.validatorWatch.Report()
+	if report.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", validator: %s", report)
+}
+
+func (kpr *Keyper) skipinfo() string {
+	var total uint64
+	for _, n := range kpr.skippedSteps {
+		total += n
+	}
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(", skipped steps: %d", total)
+}
+
 func (kpr *Keyper) ShortInfo() string {
 	world := kpr.CurrentWorld()
 	var notAKeyper string
@@ -170,7 +609,7 @@ func (kpr *Keyper) ShortInfo() string {
 		}
 	}
 	return fmt.Sprintf(
-		"%sshutter block %d, main chain %d, %s, last eon started %d, num half steps: %d%s",
+		"%sshutter block %d, main chain %d, %s, last eon started %d, num half steps: %d%s%s%s%s%s%s",
 		notAKeyper,
 		world.Shutter.CurrentBlock,
 		world.MainChain.CurrentBlock,
@@ -178,6 +617,11 @@ func (kpr *Keyper) ShortInfo() string {
 		kpr.State.LastEonStarted,
 		world.MainChain.NumExecutionHalfSteps,
 		kpr.dkginfo(),
+		kpr.latencyinfo(),
+		kpr.validatorinfo(),
+		kpr.skipinfo(),
+		kpr.accusationinfo(),
+		kpr.shadowinfo(),
 	)
 }
 
@@ -187,6 +631,7 @@ func (kpr *Keyper) dumpInternalState() {
 	pretty.Println("Shutter:", world.Shutter)
 	pretty.Println("Mainchain:", world.MainChain)
 	pretty.Println("State:", kpr.State)
+	log.Printf("Key release latency: %s", kpr.latency.Report())
 }
 
 // syncOnce syncs the main and shutter chain at least once. Otherwise, the state of one of the two
@@ -201,7 +646,7 @@ func (kpr *Keyper) syncOnce(ctx context.Context) {
 		case mainChain := <-kpr.mainChainCh:
 			world.MainChain = mainChain
 		case shutter := <-kpr.shutterCh:
-			world.Shutter = shutter
+			world.Shutter = kpr.archiveEons(shutter)
 		case <-ctx.Done():
 			return
 		}
@@ -217,14 +662,25 @@ func (kpr *Keyper) syncLoop(ctx context.Context) error {
 		case <-kpr.signalCh:
 			kpr.dumpInternalState()
 			continue
+		case <-kpr.reloadCh:
+			kpr.reloadConfig()
+			continue
+		case call := <-kpr.syncLoopCalls:
+			if err := call(ctx); err != nil {
+				return err
+			}
+			continue
 		case <-ctx.Done():
 			return ctx.Err()
 		case mainChain := <-kpr.mainChainCh:
 			world.MainChain = mainChain
 		case shutter := <-kpr.shutterCh:
-			world.Shutter = shutter
+			world.Shutter = kpr.archiveEons(shutter)
 		}
 		kpr.world.Store(world)
+		if kpr.checkSyncing(world) || kpr.shouldSkipStep(world) {
+			continue
+		}
 		err := kpr.runOneStep(ctx)
 		if err != nil {
 			return err
@@ -233,12 +689,35 @@ func (kpr *Keyper) syncLoop(ctx context.Context) error {
 		case kpr.shutterFilterCh <- kpr.State.GetShutterFilter(world.MainChain):
 		default:
 		}
+		select {
+		case kpr.mainChainFilterCh <- kpr.State.GetMainChainFilter(world.MainChain, kpr.Config.KeepBatches):
+		default:
+		}
+	}
+}
+
+// archiveEons appends the events of the given shutter state that haven't been archived yet to the
+// eon archive and drops all but the most recent eons from memory, then applies the retention
+// policies of the observe and archive datasets, see applyRetention. If archiving fails, the state
+// is returned as is and the events are archived with the next update.
+func (kpr *Keyper) archiveEons(shutter *observe.Shutter) *observe.Shutter {
+	if kpr.eonArchive == nil || shutter.CurrentBlock <= kpr.State.ArchivedHeight {
+		return shutter
+	}
+	archived, err := shutter.ArchiveNewEvents(
+		kpr.eonArchive, kpr.State.ArchivedHeight, int(kpr.retention[retention.Observe].Full),
+	)
+	if err != nil {
+		log.Printf("Error: %+v", err)
+		return shutter
 	}
+	kpr.State.ArchivedHeight = shutter.CurrentBlock
+	return kpr.applyRetention(archived)
 }
 
 func (kpr *Keyper) startSyncTasks(ctx context.Context, g *errgroup.Group) {
 	g.Go(func() error {
-		return observe.SyncMain(ctx, &kpr.ContractCaller, kpr.CurrentWorld().MainChain, kpr.mainChainCh)
+		return observe.SyncMain(ctx, &kpr.ContractCaller, kpr.CurrentWorld().MainChain, kpr.mainChainCh, kpr.mainChainFilterCh)
 	})
 	g.Go(func() error {
 		return observe.SyncShutter(ctx, kpr.shmcl, kpr.CurrentWorld().Shutter, kpr.shutterCh, kpr.shutterFilterCh)
@@ -259,6 +738,7 @@ func (kpr *Keyper) loadRunenv(ctx context.Context) error {
 }
 
 func (kpr *Keyper) run(ctx context.Context, g *errgroup.Group) error {
+	kpr.syncStarted = time.Now()
 	kpr.startSyncTasks(ctx, g)
 	kpr.syncOnce(ctx)
 	kpr.runenv.StartBackgroundTasks(ctx, g)
@@ -276,11 +756,79 @@ func (kpr *Keyper) run(ctx context.Context, g *errgroup.Group) error {
 }
 
 func (kpr *Keyper) Run(ctx context.Context) error {
+	if kpr.Config.StandbyPrimaryURL != "" {
+		if err := kpr.followPrimary(ctx); err != nil {
+			return err
+		}
+	}
 	if err := kpr.init(); err != nil {
 		return err
 	}
+	if kpr.Config.StandbyPrimaryURL != "" {
+		if err := kpr.checkTakeover(ctx); err != nil {
+			return err
+		}
+	}
+	if kpr.eonArchive != nil {
+		defer kpr.eonArchive.Close()
+	}
+	if kpr.rpcCache != nil {
+		defer func() {
+			log.Printf("RPC cache: %+v", kpr.rpcCache.Stats())
+			kpr.rpcCache.Close()
+		}()
+	}
 	g, groupCtx := errgroup.WithContext(ctx)
 
+	if kpr.lightAPI != nil {
+		kpr.updateLightAPI()
+	}
+	for addr, srv := range kpr.httpServers {
+		addr, srv := addr, srv
+		g.Go(func() error {
+			return srv.ListenAndServe(groupCtx, addr)
+		})
+	}
+	if kpr.eventStream != nil {
+		g.Go(func() error {
+			return kpr.eventStream.ListenAndServe(groupCtx, kpr.Config.EventStreamListenAddress)
+		})
+	}
+	if kpr.validatorWatch != nil {
+		g.Go(func() error {
+			return kpr.validatorWatch.Run(groupCtx)
+		})
+	}
+	if kpr.leakWatch != nil {
+		g.Go(func() error {
+			return kpr.leakWatch.Run(groupCtx)
+		})
+	}
+	if kpr.ipfsPublisher != nil {
+		g.Go(func() error {
+			return kpr.ipfsPublisher.Run(groupCtx)
+		})
+	}
+	if kpr.incidentHook != nil {
+		g.Go(func() error {
+			return kpr.incidentHook.Run(groupCtx)
+		})
+	}
+	if kpr.abiDrift != nil {
+		g.Go(func() error {
+			return kpr.abiDrift.Run(groupCtx, kpr.Config.ABIDriftCheckInterval)
+		})
+	}
+	if IsWebsocketURL(kpr.Config.EthereumURL) {
+		g.Go(func() error {
+			err := kpr.ContractCaller.WatchCacheInvalidations(groupCtx)
+			if err != nil {
+				// not fatal, cached values expire or get invalidated by the observer
+				log.Printf("Error: %+v", err)
+			}
+			return nil
+		})
+	}
 	g.Go(func() error {
 		return kpr.run(groupCtx, g)
 	})
@@ -292,12 +840,6 @@ func (kpr *Keyper) CurrentWorld() observe.World {
 	return kpr.world.Load().(observe.World)
 }
 
-type storedState struct {
-	State     *State
-	Shutter   *observe.Shutter
-	MainChain *observe.MainChain
-}
-
 func (kpr *Keyper) pathStateGob() string {
 	return filepath.Join(kpr.Config.DBDir, "state.gob")
 }
@@ -306,30 +848,21 @@ func (kpr *Keyper) pathActionsGob() string {
 	return filepath.Join(kpr.Config.DBDir, "actions.gob")
 }
 
-func (kpr *Keyper) LoadState() error {
-	gobpath := kpr.pathStateGob()
+func (kpr *Keyper) pathDeadActionsGob() string {
+	return filepath.Join(kpr.Config.DBDir, "dead-actions.gob")
+}
 
-	gobfile, err := os.Open(gobpath)
-	if os.IsNotExist(err) {
+func (kpr *Keyper) LoadState() error {
+	st, err := kpr.stateStore.Load()
+	if err == ErrNoStoredState {
 		return nil
 	} else if err != nil {
 		return err
 	}
-	log.Printf("Loading state from %s", gobpath)
+	log.Printf("Loaded state saved at sync height %d", st.State.SyncHeight)
 
-	defer gobfile.Close()
-	dec := gob.NewDecoder(gobfile)
-	st := storedState{}
-	err = dec.Decode(&st)
-	if err != nil {
-		return err
-	}
-
-	if st.State.SyncHeight == 0 && st.Shutter.CurrentBlock > 0 {
-		log.Printf("Fixing SyncHeight: %d", st.Shutter.CurrentBlock)
-		st.State.SyncHeight = st.Shutter.CurrentBlock // We didn't have this field in older versions
-	}
 	kpr.State = st.State
+	st.MainChain.Confirmations = kpr.Config.confirmations() // the config may have changed
 	world := observe.World{
 		Shutter:   st.Shutter,
 		MainChain: st.MainChain,
@@ -340,31 +873,12 @@ func (kpr *Keyper) LoadState() error {
 }
 
 func (kpr *Keyper) saveState() error {
-	gobpath := kpr.pathStateGob()
-	tmppath := gobpath + ".tmp"
-	file, err := os.Create(tmppath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
 	world := kpr.CurrentWorld()
-	st := storedState{
+	return kpr.stateStore.Save(StoredState{
 		State:     kpr.State,
 		Shutter:   world.Shutter,
 		MainChain: world.MainChain,
-	}
-	enc := gob.NewEncoder(file)
-	err = enc.Encode(st)
-	if err != nil {
-		return err
-	}
-
-	err = file.Sync()
-	if err != nil {
-		return err
-	}
-	err = os.Rename(tmppath, gobpath)
-	return err
+	})
 }
 
 func (kpr *Keyper) runActions(ctx context.Context) error {
@@ -385,17 +899,73 @@ func (kpr *Keyper) runActions(ctx context.Context) error {
 
 func (kpr *Keyper) decide() []fx.IAction {
 	decider := NewDecider(kpr)
+	var shadow *Decider
+	if kpr.Candidate != nil {
+		shadow = decideShadow(kpr.Candidate, decider)
+	}
 	decider.Decide()
+	if shadow != nil && compareShadow(&decider, shadow) {
+		kpr.divergences++
+	}
+	if decider.polyEvalsResumed {
+		kpr.polyEvalsResumed = true
+	}
+	if decider.Trace != nil {
+		kpr.trace.Add(*decider.Trace)
+	}
 	return decider.Actions
 }
 
+// updateLightAPI makes the transcripts of the eons we generated keys for and the epoch secret key
+// shares observed for them available via the light client API.
+func (kpr *Keyper) updateLightAPI() {
+	if kpr.lightAPI == nil {
+		return
+	}
+	shutter := kpr.CurrentWorld().Shutter
+	for _, ekg := range kpr.State.EKGs {
+		if ekg.Transcript == nil {
+			continue
+		}
+		var shares []shutterevents.EpochSecretKeyShare
+		if eon, err := shutter.FindEon(ekg.Eon); err == nil {
+			shares = eon.EpochSecretKeyShares
+		}
+		kpr.lightAPI.SetEon(lightapi.Eon{
+			Eon:                  ekg.Eon,
+			StartBatchIndex:      ekg.StartBatchIndex,
+			Keypers:              ekg.Keypers,
+			StartHeight:          ekg.DKGStartHeight,
+			EndHeight:            ekg.DKGEndHeight,
+			Transcript:           ekg.Transcript,
+			CommitmentHeights:    ekg.CommitmentHeights,
+			EpochSecretKeyShares: shares,
+		})
+	}
+}
+
 func (kpr *Keyper) runOneStep(ctx context.Context) error {
 	if len(kpr.State.Actions) > 0 {
 		panic("internal errror: kpr.State.Actions is not empty")
 	}
+	if kpr.isFenced() {
+		return errFenced
+	}
 	kpr.State.Actions = kpr.decide()
 	if err := kpr.saveState(); err != nil {
 		panic(err)
 	}
+	kpr.updateMetrics()
+	kpr.updateHeartbeat()
+	kpr.updateLightAPI()
+	kpr.updateAccusations()
+	kpr.updateBlockedDealing()
+	kpr.updateDKGArrivals()
+	kpr.updateKeyperStatus()
+	kpr.updateStatus()
+	kpr.updateLeakWatch()
+	kpr.updateIPFS()
+	kpr.notifyIncidents()
+	kpr.backupShares()
 	return kpr.runActions(ctx)
 }
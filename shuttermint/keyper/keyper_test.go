@@ -1,7 +1,16 @@
 package keyper
 
 import (
+	"encoding/json"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
 )
 
 // TestShortInfo tests that Keyper.ShortInfo() does not panic, even though the Shutter and
@@ -10,3 +19,53 @@ func TestShortInfo(_ *testing.T) {
 	k := NewKeyper(Config{})
 	k.ShortInfo()
 }
+
+func TestDeliveredPolyEval(t *testing.T) {
+	keypers := []common.Address{common.BigToAddress(common.Big1), common.BigToAddress(common.Big2)}
+	pure := puredkg.NewPureDKG(1, 2, 2, 0)
+	dkg := DKG{
+		Eon:         1,
+		Keypers:     keypers,
+		Pure:        &pure,
+		PhaseLength: NewConstantPhaseLength(10),
+	}
+	eon := observe.Eon{Eon: 1, StartHeight: 100}
+	assert.Assert(t, !dkg.deliveredPolyEval(eon, keypers[1]))
+
+	// too late
+	eon.PolyEvals = append(eon.PolyEvals, shutterevents.PolyEval{
+		Height:    110,
+		Sender:    keypers[0],
+		Eon:       1,
+		Receivers: []common.Address{keypers[1]},
+	})
+	assert.Assert(t, !dkg.deliveredPolyEval(eon, keypers[1]))
+
+	eon.PolyEvals = append(eon.PolyEvals, shutterevents.PolyEval{
+		Height:    105,
+		Sender:    keypers[0],
+		Eon:       1,
+		Receivers: []common.Address{keypers[1]},
+	})
+	assert.Assert(t, dkg.deliveredPolyEval(eon, keypers[1]))
+}
+
+func TestServeAccusations(t *testing.T) {
+	k := NewKeyper(Config{})
+	accuser := common.BigToAddress(common.Big1)
+	k.State.UnjustifiedAccusations[accuser] = 2
+	k.updateAccusations()
+	k.State.UnjustifiedAccusations[accuser] = 3
+
+	rec := httptest.NewRecorder()
+	k.accusationsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/accusations", nil))
+	var res map[string]uint64
+	assert.NilError(t, json.NewDecoder(rec.Body).Decode(&res))
+	assert.DeepEqual(t, res, map[string]uint64{accuser.Hex(): 2})
+}
+
+func TestLocalAddress(t *testing.T) {
+	assert.Equal(t, localAddress(":8082"), "localhost:8082")
+	assert.Equal(t, localAddress("0.0.0.0:8082"), "0.0.0.0:8082")
+	assert.Equal(t, localAddress("localhost:8082"), "localhost:8082")
+}
@@ -0,0 +1,144 @@
+package keyper
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// keyperStatusEpochs is the number of recent epochs KeyperStatus.SharesPublished counts the
+// shares of.
+const keyperStatusEpochs = 10
+
+// KeyperStatus summarizes what a keyper of the current config has contributed to the key material
+// of the active eon, from our point of view.
+type KeyperStatus struct {
+	Keyper             common.Address
+	CheckedIn          bool
+	EncryptionKeyKnown bool
+	InActiveEon        bool  // the keyper takes part in the active eon, i.e. it's in its committee
+	CommitmentHeight   int64 // height of its poly commitment for the active eon, 0 if none
+	// EvalDelivered is set if the keyper's poly eval reached us, directly or with an apology, so
+	// that it contributed to the active eon's key
+	EvalDelivered bool
+	// SharesPublished is the number of RecentEpochs the keyper has published its epoch secret key
+	// share for, LastShareEpoch the last epoch it did so, if any
+	SharesPublished int
+	LastShareEpoch  *uint64
+}
+
+// KeyperSetStatus is the status of all keypers of the current config.
+type KeyperSetStatus struct {
+	Height       int64 // shuttermint height the status is based on
+	ConfigIndex  uint64
+	BatchIndex   uint64 // next batch to be executed, which the config and active eon belong to
+	ActiveEon    *uint64
+	Threshold    uint64   // of the active eon, 0 if there is none
+	RecentEpochs []uint64 // the most recent epochs of the active eon any keyper published a share for
+	Keypers      []KeyperStatus
+}
+
+// computeKeyperSetStatus computes the status of the keypers of the config the next batch belongs
+// to. Only shares of the default epoch namespace are counted.
+func computeKeyperSetStatus(st *State, shutter *observe.Shutter, mainChain *observe.MainChain) KeyperSetStatus {
+	batchIndex := uint64(protocol.NextBatchIndex(mainChain.NumExecutionHalfSteps))
+	config := shutter.FindBatchConfigByBatchIndex(batchIndex)
+	status := KeyperSetStatus{
+		Height:      shutter.CurrentBlock,
+		ConfigIndex: config.ConfigIndex,
+		BatchIndex:  batchIndex,
+	}
+
+	var ekg *EKG
+	var eon *observe.Eon
+	if n, ok := st.ActiveEon(batchIndex); ok {
+		status.ActiveEon = &n
+		ekg, _ = st.FindEKGByEon(n)
+		eon, _ = shutter.FindEon(n)
+	}
+	if ekg != nil && ekg.EpochKG != nil {
+		status.Threshold = ekg.EpochKG.Threshold
+	}
+
+	shares := make(map[common.Address]map[uint64]bool)
+	if eon != nil {
+		epochs := make(map[uint64]bool)
+		for _, s := range eon.EpochSecretKeyShares {
+			if s.Namespace != "" {
+				continue
+			}
+			if shares[s.Sender] == nil {
+				shares[s.Sender] = make(map[uint64]bool)
+			}
+			shares[s.Sender][s.Epoch] = true
+			epochs[s.Epoch] = true
+		}
+		for epoch := range epochs {
+			status.RecentEpochs = append(status.RecentEpochs, epoch)
+		}
+		sort.Slice(status.RecentEpochs, func(i, j int) bool { return status.RecentEpochs[i] > status.RecentEpochs[j] })
+		if len(status.RecentEpochs) > keyperStatusEpochs {
+			status.RecentEpochs = status.RecentEpochs[:keyperStatusEpochs]
+		}
+	}
+
+	for _, keyper := range config.Keypers {
+		key, checkedIn := shutter.KeyperEncryptionKeys[keyper]
+		ks := KeyperStatus{
+			Keyper:             keyper,
+			CheckedIn:          checkedIn,
+			EncryptionKeyKnown: key != nil,
+		}
+		if ekg != nil {
+			for i, k := range ekg.Keypers {
+				if k != keyper {
+					continue
+				}
+				ks.InActiveEon = true
+				if i < len(ekg.CommitmentHeights) {
+					ks.CommitmentHeight = ekg.CommitmentHeights[i]
+				}
+				ks.EvalDelivered = ekg.Transcript != nil && ekg.Transcript.IsParticipant(uint64(i))
+			}
+		}
+		for _, epoch := range status.RecentEpochs {
+			if shares[keyper][epoch] {
+				ks.SharesPublished++
+			}
+		}
+		for epoch := range shares[keyper] {
+			if ks.LastShareEpoch == nil || epoch > *ks.LastShareEpoch {
+				e := epoch
+				ks.LastShareEpoch = &e
+			}
+		}
+		status.Keypers = append(status.Keypers, ks)
+	}
+	return status
+}
+
+// updateKeyperStatus publishes the status of the keypers of the current config, so that it can
+// be served while the decider keeps updating the state.
+func (kpr *Keyper) updateKeyperStatus() {
+	world := kpr.CurrentWorld()
+	kpr.keyperStatus.Store(computeKeyperSetStatus(kpr.State, world.Shutter, world.MainChain))
+}
+
+func (kpr *Keyper) keyperStatusHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveKeyperStatus)
+}
+
+// serveKeyperStatus serves the status of the keypers of the current config as JSON.
+func (kpr *Keyper) serveKeyperStatus(w http.ResponseWriter, _ *http.Request) {
+	status, ok := kpr.keyperStatus.Load().(KeyperSetStatus)
+	if !ok {
+		http.Error(w, "keyper status not available yet", http.StatusServiceUnavailable)
+		return
+	}
+	httpapi.WriteJSON(w, status)
+}
@@ -0,0 +1,77 @@
+package keyper
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestComputeKeyperSetStatus(t *testing.T) {
+	keypers := []common.Address{
+		common.BigToAddress(big.NewInt(1)),
+		common.BigToAddress(big.NewInt(2)),
+		common.BigToAddress(big.NewInt(3)),
+	}
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 500
+	shutter.BatchConfigs = []shutterevents.BatchConfig{{Keypers: keypers, ConfigIndex: 1, Threshold: 2}}
+	shutter.KeyperEncryptionKeys[keypers[0]] = (*observe.EncryptionPublicKey)(ecies.ImportECDSAPublic(&key.PublicKey))
+	shutter.KeyperEncryptionKeys[keypers[1]] = (*observe.EncryptionPublicKey)(ecies.ImportECDSAPublic(&key.PublicKey))
+	eon := observe.Eon{Eon: 3, StartHeight: 100}
+	for epoch := uint64(1); epoch <= 12; epoch++ {
+		eon.EpochSecretKeyShares = append(eon.EpochSecretKeyShares,
+			shutterevents.EpochSecretKeyShare{Sender: keypers[0], Eon: 3, Epoch: epoch})
+	}
+	eon.EpochSecretKeyShares = append(eon.EpochSecretKeyShares,
+		shutterevents.EpochSecretKeyShare{Sender: keypers[1], Eon: 3, Epoch: 12},
+		shutterevents.EpochSecretKeyShare{Sender: keypers[1], Eon: 3, Epoch: 2},
+		shutterevents.EpochSecretKeyShare{Sender: keypers[2], Eon: 3, Epoch: 20, Namespace: "other"})
+	shutter.Eons = []observe.Eon{eon}
+
+	st := NewState()
+	st.EonActivations = []EonActivation{{Eon: 3, StartBatchIndex: 0, EndBatchIndex: math.MaxUint64}}
+	st.EKGs = []*EKG{{
+		Eon:               3,
+		Keypers:           keypers[:2],
+		EpochKG:           &epochkg.EpochKG{Threshold: 2},
+		Transcript:        &puredkg.Transcript{NumKeypers: 2, Participants: []byte{1}},
+		CommitmentHeights: []int64{101, 0},
+	}}
+
+	status := computeKeyperSetStatus(st, shutter, observe.NewMainChain(0))
+	assert.Equal(t, status.Height, int64(500))
+	assert.Equal(t, status.ConfigIndex, uint64(1))
+	assert.Equal(t, *status.ActiveEon, uint64(3))
+	assert.Equal(t, status.Threshold, uint64(2))
+	assert.DeepEqual(t, status.RecentEpochs, []uint64{12, 11, 10, 9, 8, 7, 6, 5, 4, 3})
+	assert.Equal(t, len(status.Keypers), 3)
+
+	k := status.Keypers[0]
+	assert.Assert(t, k.CheckedIn && k.EncryptionKeyKnown && k.InActiveEon && k.EvalDelivered)
+	assert.Equal(t, k.CommitmentHeight, int64(101))
+	assert.Equal(t, k.SharesPublished, 10)
+	assert.Equal(t, *k.LastShareEpoch, uint64(12))
+
+	k = status.Keypers[1]
+	assert.Assert(t, k.CheckedIn && k.InActiveEon && !k.EvalDelivered)
+	assert.Equal(t, k.CommitmentHeight, int64(0))
+	assert.Equal(t, k.SharesPublished, 1)
+
+	k = status.Keypers[2]
+	assert.Assert(t, !k.CheckedIn && !k.EncryptionKeyKnown && !k.InActiveEon)
+	assert.Equal(t, k.SharesPublished, 0)
+	assert.Assert(t, k.LastShareEpoch == nil)
+}
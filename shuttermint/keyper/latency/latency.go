@@ -0,0 +1,170 @@
+// Package latency tracks how long it takes until the epoch secret keys are released after an
+// epoch has ended and checks the measured latencies against a service level objective (SLO).
+package latency
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxSamples is the number of most recent measurements the statistics are computed from.
+const maxSamples = 1000
+
+// Stats holds the most recent latency measurements of a single kind.
+type Stats struct {
+	samples []time.Duration
+	next    int
+	count   uint64
+}
+
+func (s *Stats) add(d time.Duration) {
+	s.count++
+	if len(s.samples) < maxSamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % maxSamples
+}
+
+// Summary describes the distribution of the recent measurements.
+type Summary struct {
+	Count uint64 // total number of measurements, including the ones not kept anymore
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("p50=%s p90=%s p99=%s max=%s (n=%d)", s.P50, s.P90, s.P99, s.Max, s.Count)
+}
+
+func (s *Stats) summary() Summary {
+	if len(s.samples) == 0 {
+		return Summary{}
+	}
+	sorted := append([]time.Duration(nil), s.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p int) time.Duration {
+		return sorted[(len(sorted)-1)*p/100]
+	}
+	return Summary{
+		Count: s.count,
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P99:   percentile(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// Tracker measures the time between the end of an epoch and (a) the publication of our epoch
+// secret key share and (b) the generation of the epoch secret key from the threshold of shares.
+// Measurements are keyed by epoch id, i.e. by the epoch a batch config maps a batch to, or by the
+// block number if per-block epochs are used. The methods can be called on a nil Tracker, in which
+// case they do nothing.
+type Tracker struct {
+	mux        sync.Mutex
+	slo        time.Duration
+	now        func() time.Time
+	ended      map[uint64]time.Time // epoch => time we noticed the epoch ended
+	published  Stats
+	generated  Stats
+	violations uint64
+}
+
+// NewTracker creates a new Tracker. Key generation latencies above slo are reported as
+// violations, a zero slo disables that check.
+func NewTracker(slo time.Duration) *Tracker {
+	return &Tracker{
+		slo:   slo,
+		now:   time.Now,
+		ended: make(map[uint64]time.Time),
+	}
+}
+
+// EpochEnded records the end of the given epoch. Only the first call for an epoch counts.
+func (t *Tracker) EpochEnded(epoch uint64) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if _, ok := t.ended[epoch]; !ok {
+		t.ended[epoch] = t.now()
+	}
+}
+
+// SharePublished records the publication of our share for the given epoch.
+func (t *Tracker) SharePublished(epoch uint64) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if ended, ok := t.ended[epoch]; ok {
+		t.published.add(t.now().Sub(ended))
+	}
+}
+
+// KeyGenerated records the generation of the secret key for the given epoch. It logs an alert if
+// the latency violates the SLO.
+func (t *Tracker) KeyGenerated(epoch uint64) {
+	if t == nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	ended, ok := t.ended[epoch]
+	if !ok {
+		return // the epoch ended before we started
+	}
+	delete(t.ended, epoch)
+	d := t.now().Sub(ended)
+	t.generated.add(d)
+
+	// Epochs whose key will never be generated shouldn't accumulate forever
+	for e := range t.ended {
+		if e+maxSamples < epoch {
+			delete(t.ended, e)
+		}
+	}
+
+	if t.slo != 0 && d > t.slo {
+		t.violations++
+		log.Printf("ALERT: key release latency SLO violated for epoch %d: %s > %s", epoch, d, t.slo)
+	}
+}
+
+// Report is a snapshot of the statistics collected by a Tracker.
+type Report struct {
+	SharePublication Summary
+	KeyGeneration    Summary
+	SLO              time.Duration
+	Violations       uint64
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf(
+		"share publication %s, key generation %s, %d SLO violations",
+		r.SharePublication, r.KeyGeneration, r.Violations,
+	)
+}
+
+// Report returns the current statistics.
+func (t *Tracker) Report() Report {
+	if t == nil {
+		return Report{}
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return Report{
+		SharePublication: t.published.summary(),
+		KeyGeneration:    t.generated.summary(),
+		SLO:              t.slo,
+		Violations:       t.violations,
+	}
+}
@@ -0,0 +1,51 @@
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tracker := NewTracker(3 * time.Second)
+	tracker.now = func() time.Time { return now }
+
+	for epoch := uint64(0); epoch < 10; epoch++ {
+		tracker.EpochEnded(epoch)
+		now = now.Add(time.Second)
+		tracker.EpochEnded(epoch) // ignored
+		tracker.SharePublished(epoch)
+		now = now.Add(time.Duration(epoch) * time.Second)
+		tracker.KeyGenerated(epoch)
+	}
+	tracker.KeyGenerated(10) // never ended, ignored
+
+	r := tracker.Report()
+	assert.Equal(t, r.SharePublication.Count, uint64(10))
+	assert.Equal(t, r.SharePublication.Max, time.Second)
+	assert.Equal(t, r.KeyGeneration.Count, uint64(10))
+	assert.Equal(t, r.KeyGeneration.P50, 5*time.Second)
+	assert.Equal(t, r.KeyGeneration.Max, 10*time.Second)
+	assert.Equal(t, r.Violations, uint64(7)) // epochs 3 to 9
+}
+
+func TestStatsKeepsRecentSamples(t *testing.T) {
+	var s Stats
+	for i := 0; i < 2*maxSamples; i++ {
+		s.add(time.Duration(i))
+	}
+	summary := s.summary()
+	assert.Equal(t, summary.Count, uint64(2*maxSamples))
+	assert.Equal(t, summary.P50, time.Duration(maxSamples+maxSamples/2-1))
+	assert.Equal(t, summary.Max, time.Duration(2*maxSamples-1))
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	tracker.EpochEnded(1)
+	tracker.SharePublished(1)
+	tracker.KeyGenerated(1)
+	assert.Equal(t, tracker.Report(), Report{})
+}
@@ -0,0 +1,57 @@
+package keyper
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/leakwatch"
+)
+
+// executorAddresses returns the addresses of all executor contracts.
+func (kpr *Keyper) executorAddresses() []common.Address {
+	addresses := []common.Address{kpr.Config.ExecutorContractAddress}
+	for _, route := range kpr.ContractCaller.ExecutorRoutes {
+		addresses = append(addresses, route.Address)
+	}
+	return addresses
+}
+
+// updateLeakWatch hands the batches we've decrypted since the last step to the leak detector.
+func (kpr *Keyper) updateLeakWatch() {
+	if kpr.leakWatch == nil {
+		return
+	}
+	var batchIndices []uint64
+	for batchIndex, batch := range kpr.State.Batches {
+		if batchIndex >= kpr.leakWatchNext && !batch.IsEmpty {
+			batchIndices = append(batchIndices, batchIndex)
+		}
+	}
+	sort.Slice(batchIndices, func(i, j int) bool { return batchIndices[i] < batchIndices[j] })
+
+	world := kpr.CurrentWorld()
+	for _, batchIndex := range batchIndices {
+		config, ok := world.MainChain.ConfigForBatchIndex(batchIndex)
+		if !ok {
+			continue
+		}
+		batch := leakwatch.Batch{
+			BatchIndex:   batchIndex,
+			Transactions: kpr.State.Batches[batchIndex].DecryptedTransactions,
+			FirstBlock:   config.BatchEndBlock(batchIndex),
+			LastBlock:    config.BatchEndBlock(batchIndex) + config.ExecutionTimeout,
+		}
+		bc := world.Shutter.FindBatchConfigByBatchIndex(batchIndex)
+		epoch := bc.Epoch(batchIndex)
+		if eon, err := world.Shutter.FindEonByBatchIndex(batchIndex); err == nil {
+			for _, share := range eon.EpochSecretKeyShares {
+				if share.Epoch == epoch && share.Namespace == "" {
+					batch.Shares = append(batch.Shares, leakwatch.Share{Sender: share.Sender, Height: share.Height})
+				}
+			}
+		}
+		kpr.leakWatch.Add(batch)
+		kpr.leakWatchNext = batchIndex + 1
+	}
+}
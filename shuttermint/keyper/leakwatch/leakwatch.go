@@ -0,0 +1,243 @@
+// Package leakwatch detects main chain transactions that copy decrypted transactions of a batch
+// before the batch has been executed. Such copies indicate that the epoch key or the decrypted
+// transactions leaked ahead of execution, e.g. to front-run the batch, breaking the core promise
+// of Shutter. The detector raises alerts that list the keypers who had released their key shares
+// for the batch and when, to help tracking down the leak. Only transactions included in main
+// chain blocks are checked, not the mempool.
+package leakwatch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+// pollInterval is how often we check for new main chain blocks.
+const pollInterval = 5 * time.Second
+
+// minLength is the minimum length of the decrypted transactions we look for. Shorter ones may
+// appear in unrelated transactions by chance.
+const minLength = 20
+
+// Client is the part of the ethclient used to fetch blocks.
+type Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// Share is the release of an epoch secret key share.
+type Share struct {
+	Sender common.Address
+	Height int64 // shuttermint height the share has been published at
+}
+
+// Batch is a decrypted batch to check for leaks.
+type Batch struct {
+	BatchIndex   uint64
+	Transactions [][]byte // the decrypted transactions
+	FirstBlock   uint64   // first main chain block a leak may appear in
+	LastBlock    uint64   // last main chain block the batch may be executed in
+	Shares       []Share  // the key shares released for the batch's epoch
+}
+
+// Leak is a main chain transaction copying a decrypted transaction before the batch's execution.
+type Leak struct {
+	BatchIndex  uint64
+	Transaction int // index of the decrypted transaction in the batch
+	Block       uint64
+	TxHash      common.Hash
+	Shares      []Share
+}
+
+type pendingBatch struct {
+	Batch
+	next uint64 // next block to check
+}
+
+// Detector checks the main chain for leaked transactions of the batches added to it.
+type Detector struct {
+	client    Client
+	executors map[common.Address]bool
+	selectors [][]byte // of the executor methods executing or skipping a cipher batch
+
+	mux     sync.Mutex
+	pending map[uint64]*pendingBatch
+	leaks   []Leak
+}
+
+// NewDetector creates a detector. Transactions sent to the given executor contracts are the
+// official executions and not considered leaks.
+func NewDetector(client Client, executors []common.Address) *Detector {
+	d := &Detector{
+		client:    client,
+		executors: make(map[common.Address]bool),
+		pending:   make(map[uint64]*pendingBatch),
+	}
+	for _, executor := range executors {
+		d.executors[executor] = true
+	}
+	for _, abiJSON := range contract.ExecutorABIs {
+		parsed, err := abi.JSON(strings.NewReader(abiJSON))
+		if err != nil {
+			panic(err)
+		}
+		for _, name := range []string{"executeCipherBatch", "skipCipherExecution"} {
+			if method, ok := parsed.Methods[name]; ok {
+				d.selectors = append(d.selectors, method.ID)
+			}
+		}
+	}
+	return d
+}
+
+// Add adds a decrypted batch to check. Adding a batch that is already pending does nothing.
+func (d *Detector) Add(batch Batch) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if _, ok := d.pending[batch.BatchIndex]; ok {
+		return
+	}
+	d.pending[batch.BatchIndex] = &pendingBatch{Batch: batch, next: batch.FirstBlock}
+}
+
+// Leaks returns the leaks detected so far.
+func (d *Detector) Leaks() []Leak {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return append([]Leak(nil), d.leaks...)
+}
+
+// Run checks new blocks until the context is canceled. Errors are logged, but don't stop the
+// detector.
+func (d *Detector) Run(ctx context.Context) error {
+	for {
+		if err := d.Check(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Error watching for key leaks: %+v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Check checks the main chain blocks up to the latest one for copies of the decrypted
+// transactions of the pending batches. Batches are done once they have been executed or their
+// last block has been checked.
+func (d *Detector) Check(ctx context.Context) error {
+	header, err := d.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch latest main chain header")
+	}
+	latest := header.Number.Uint64()
+
+	d.mux.Lock()
+	var batches []*pendingBatch
+	for _, batch := range d.pending {
+		batches = append(batches, batch)
+	}
+	d.mux.Unlock()
+	sort.Slice(batches, func(i, j int) bool { return batches[i].BatchIndex < batches[j].BatchIndex })
+
+	blocks := make(map[uint64]*types.Block) // shared by the batches
+	for _, batch := range batches {
+		done := false
+		for !done && batch.next <= latest && batch.next <= batch.LastBlock {
+			block, ok := blocks[batch.next]
+			if !ok {
+				block, err = d.client.BlockByNumber(ctx, new(big.Int).SetUint64(batch.next))
+				if err != nil {
+					return errors.Wrapf(err, "failed to fetch main chain block %d", batch.next)
+				}
+				blocks[batch.next] = block
+			}
+			done = d.checkBlock(block, batch)
+			batch.next++
+		}
+		if done || batch.next > batch.LastBlock {
+			d.mux.Lock()
+			delete(d.pending, batch.BatchIndex)
+			d.mux.Unlock()
+		}
+	}
+	return nil
+}
+
+// checkBlock checks the transactions in the block for copies of the batch's decrypted
+// transactions. It returns true if the block contains the execution of the batch, transactions
+// after it are not leaks.
+func (d *Detector) checkBlock(block *types.Block, batch *pendingBatch) bool {
+	for _, tx := range block.Transactions() {
+		if tx.To() != nil && d.executors[*tx.To()] {
+			if batchIndex, ok := d.executedBatch(tx.Data()); ok && batchIndex == batch.BatchIndex {
+				return true
+			}
+			continue
+		}
+		for i, decrypted := range batch.Transactions {
+			if len(decrypted) >= minLength && bytes.Contains(tx.Data(), decrypted) {
+				d.report(Leak{
+					BatchIndex:  batch.BatchIndex,
+					Transaction: i,
+					Block:       block.NumberU64(),
+					TxHash:      tx.Hash(),
+					Shares:      batch.Shares,
+				})
+			}
+		}
+	}
+	return false
+}
+
+// executedBatch returns the index of the batch executed or skipped by a call to an executor
+// contract with the given data.
+func (d *Detector) executedBatch(data []byte) (uint64, bool) {
+	if len(data) < 4+32 {
+		return 0, false
+	}
+	for _, selector := range d.selectors {
+		if bytes.Equal(data[:4], selector) {
+			batchIndex := new(big.Int).SetBytes(data[4:36])
+			return batchIndex.Uint64(), batchIndex.IsUint64()
+		}
+	}
+	return 0, false
+}
+
+func (d *Detector) report(leak Leak) {
+	d.mux.Lock()
+	d.leaks = append(d.leaks, leak)
+	d.mux.Unlock()
+	log.Printf(
+		"ALERT: transaction %s in main chain block %d copies decrypted transaction %d of batch %d before its execution, key shares released by: %s",
+		leak.TxHash.Hex(), leak.Block, leak.Transaction, leak.BatchIndex, describeShares(leak.Shares),
+	)
+}
+
+// describeShares lists the senders of the shares in the order they were released.
+func describeShares(shares []Share) string {
+	if len(shares) == 0 {
+		return "unknown"
+	}
+	sorted := append([]Share(nil), shares...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Height < sorted[j].Height })
+	s := make([]string, len(sorted))
+	for i, share := range sorted {
+		s[i] = fmt.Sprintf("%s at height %d", share.Sender.Hex(), share.Height)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,78 @@
+package leakwatch
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+type fakeClient struct {
+	latest uint64
+	blocks map[uint64][]*types.Transaction
+}
+
+func (c *fakeClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(c.latest)}, nil
+}
+
+func (c *fakeClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	header := &types.Header{Number: number}
+	return types.NewBlockWithHeader(header).WithBody(c.blocks[number.Uint64()], nil), nil
+}
+
+func newTx(to common.Address, data []byte) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{To: &to, Data: data})
+}
+
+func TestDetector(t *testing.T) {
+	executor := common.BigToAddress(big.NewInt(1))
+	target := common.BigToAddress(big.NewInt(2))
+	decrypted := [][]byte{[]byte("short"), []byte("a decrypted transaction of batch 5")}
+	copied := append([]byte("prefix "), decrypted[1]...)
+
+	parsed, err := abi.JSON(strings.NewReader(contract.ExecutorContractABI))
+	assert.NilError(t, err)
+	execution, err := parsed.Pack("executeCipherBatch", uint64(5), [32]byte{}, decrypted, uint64(0))
+	assert.NilError(t, err)
+
+	client := &fakeClient{
+		latest: 11,
+		blocks: map[uint64][]*types.Transaction{
+			10: {newTx(target, decrypted[0])},
+			11: {newTx(target, copied)},
+			12: {newTx(executor, execution), newTx(target, copied)},
+			13: {newTx(target, copied)},
+		},
+	}
+	shares := []Share{
+		{Sender: common.BigToAddress(big.NewInt(4)), Height: 8},
+		{Sender: common.BigToAddress(big.NewInt(3)), Height: 7},
+	}
+	d := NewDetector(client, []common.Address{executor})
+	d.Add(Batch{BatchIndex: 5, Transactions: decrypted, FirstBlock: 10, LastBlock: 20, Shares: shares})
+
+	assert.NilError(t, d.Check(context.Background()))
+	leaks := d.Leaks()
+	assert.Equal(t, len(leaks), 1)
+	assert.Equal(t, leaks[0].Block, uint64(11))
+	assert.Equal(t, leaks[0].Transaction, 1)
+	assert.Equal(t, leaks[0].TxHash, client.blocks[11][0].Hash())
+	assert.Equal(t, len(d.pending), 1)
+
+	// copies after the execution don't count
+	client.latest = 13
+	assert.NilError(t, d.Check(context.Background()))
+	assert.Equal(t, len(d.Leaks()), 1)
+	assert.Equal(t, len(d.pending), 0)
+
+	assert.Equal(t, describeShares(shares), "0x0000000000000000000000000000000000000003 at height 7, "+
+		"0x0000000000000000000000000000000000000004 at height 8")
+}
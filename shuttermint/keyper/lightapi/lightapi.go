@@ -0,0 +1,302 @@
+// Package lightapi serves what a third party needs to verify where an eon public key comes from
+// without running shuttermint: the compressed DKG transcript of the eon, the tendermint signed
+// headers and validator sets at the heights the DKG started and ended, and for every participant
+// the transaction with its poly commitment together with a proof that it is part of a signed
+// block. It also serves the released epoch secret keys, computed from the epoch secret key shares
+// the keypers published.
+package lightapi
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// maxValidatorsPerPage is the maximum page size supported by tendermint's validators endpoint.
+const maxValidatorsPerPage = 100
+
+// Eon is the information about a finished DKG process served by the API.
+type Eon struct {
+	Eon             uint64
+	StartBatchIndex uint64
+	Keypers         []common.Address
+	StartHeight     int64 // shuttermint height at which the DKG started
+	EndHeight       int64 // shuttermint height at which the DKG was finalized
+	Transcript      *puredkg.Transcript
+	// CommitmentHeights holds the shuttermint height of each keyper's poly commitment
+	// transaction, indexed like Keypers. It's 0 for keypers that didn't send one.
+	CommitmentHeights []int64
+	// EpochSecretKeyShares holds the epoch secret key shares observed for the eon.
+	EpochSecretKeyShares []shutterevents.EpochSecretKeyShare
+}
+
+// HeaderClient is the part of the tendermint client used to fetch signed headers and blocks.
+type HeaderClient interface {
+	Block(ctx context.Context, height *int64) (*ctypes.ResultBlock, error)
+	Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error)
+	Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error)
+}
+
+// Server serves eon provenance information over HTTP.
+type Server struct {
+	client HeaderClient
+
+	mu   sync.Mutex
+	eons map[uint64]Eon
+}
+
+// NewServer creates a new server fetching signed headers with the given client.
+func NewServer(client HeaderClient) *Server {
+	return &Server{
+		client: client,
+		eons:   make(map[uint64]Eon),
+	}
+}
+
+// SetEon adds or replaces the information about an eon.
+func (s *Server) SetEon(eon Eon) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.eons[eon.Eon] = eon
+}
+
+func (s *Server) getEon(eon uint64) (Eon, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.eons[eon]
+	return e, ok
+}
+
+func (s *Server) eonIndices() []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := []uint64{}
+	for eon := range s.eons {
+		res = append(res, eon)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// findEpochKey computes the secret key of the given epoch in the given namespace from the shares
+// of the latest eon a threshold of keypers has published valid shares for.
+func (s *Server) findEpochKey(namespace string, epoch uint64) (*EpochKeyResponse, error) {
+	indices := s.eonIndices()
+	for i := len(indices) - 1; i >= 0; i-- {
+		eon, ok := s.getEon(indices[i])
+		if !ok {
+			continue
+		}
+		resp, err := computeEpochKey(eon, namespace, epoch)
+		if err != nil || resp != nil {
+			return resp, err
+		}
+	}
+	return nil, nil
+}
+
+// Mount registers the API's endpoints on the given server. It serves the list of known eons at
+// /eons, an EonResponse for each of them at /eons/<eon> and an EpochKeyResponse for each released
+// epoch key at /keys?namespace=<namespace>&epoch=<epoch>.
+func (s *Server) Mount(srv *httpapi.Server) {
+	srv.HandleFunc("/eons", func(w http.ResponseWriter, r *http.Request) {
+		httpapi.WriteJSON(w, s.eonIndices())
+	})
+	srv.HandleFunc("/eons/", s.handleEon)
+	srv.HandleFunc("/keys", s.handleEpochKey)
+}
+
+// Handler returns an http handler serving only this API.
+func (s *Server) Handler() http.Handler {
+	srv := httpapi.NewServer()
+	s.Mount(srv)
+	return srv.Handler()
+}
+
+func (s *Server) handleEon(w http.ResponseWriter, r *http.Request) {
+	eonIndex, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/eons/"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid eon", http.StatusBadRequest)
+		return
+	}
+	eon, ok := s.getEon(eonIndex)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	resp, err := s.makeEonResponse(r.Context(), eon)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	httpapi.WriteJSON(w, resp)
+}
+
+func (s *Server) handleEpochKey(w http.ResponseWriter, r *http.Request) {
+	epoch, err := strconv.ParseUint(r.URL.Query().Get("epoch"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid epoch", http.StatusBadRequest)
+		return
+	}
+	resp, err := s.findEpochKey(r.URL.Query().Get("namespace"), epoch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if resp == nil {
+		http.NotFound(w, r)
+		return
+	}
+	httpapi.WriteJSON(w, resp)
+}
+
+// computeEpochKey computes the secret key of the given epoch in the given namespace from the
+// eon's epoch secret key shares. Shares that can't be verified against the eon public key shares
+// of the transcript are ignored. It returns nil if the shares of less than a threshold of keypers
+// have been observed.
+func computeEpochKey(eon Eon, namespace string, epoch uint64) (*EpochKeyResponse, error) {
+	if eon.Transcript == nil {
+		return nil, nil
+	}
+	keyperIndex := make(map[common.Address]uint64)
+	for i, keyper := range eon.Keypers {
+		keyperIndex[keyper] = uint64(i)
+	}
+	epochID := shcrypto.ComputeNamespacedEpochID(namespace, epoch)
+	resp := &EpochKeyResponse{Eon: eon.Eon, Namespace: namespace, Epoch: epoch}
+	var indices []int
+	var shares []*shcrypto.EpochSecretKeyShare
+	seen := make(map[uint64]bool)
+	for _, ev := range eon.EpochSecretKeyShares {
+		if ev.Namespace != namespace || ev.Epoch != epoch || ev.Share == nil {
+			continue
+		}
+		index, ok := keyperIndex[ev.Sender]
+		if !ok || seen[index] {
+			continue
+		}
+		if !shcrypto.VerifyEpochSecretKeyShare(ev.Share, eon.Transcript.PublicKeyShare(index), epochID) {
+			continue
+		}
+		seen[index] = true
+		indices = append(indices, int(index))
+		shares = append(shares, ev.Share)
+		resp.Shares = append(resp.Shares, EpochKeyShare{
+			Keyper: index,
+			Height: ev.Height,
+			Share:  (*bn256.G1)(ev.Share).Marshal(),
+		})
+		if uint64(len(shares)) == eon.Transcript.Threshold {
+			break
+		}
+	}
+	if eon.Transcript.Threshold == 0 || uint64(len(shares)) < eon.Transcript.Threshold {
+		return nil, nil
+	}
+	key, err := shcrypto.ComputeEpochSecretKey(indices, shares, eon.Transcript.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	resp.Key = (*bn256.G1)(key).Marshal()
+	return resp, nil
+}
+
+func (s *Server) makeEonResponse(ctx context.Context, eon Eon) (*EonResponse, error) {
+	resp := &EonResponse{
+		Eon:             eon.Eon,
+		StartBatchIndex: eon.StartBatchIndex,
+		Keypers:         eon.Keypers,
+		Transcript:      NewTranscriptJSON(eon.Transcript),
+	}
+	for _, height := range []int64{eon.StartHeight, eon.EndHeight} {
+		proof, err := s.fetchHeaderProof(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		resp.Headers = append(resp.Headers, proof)
+	}
+	for keyper := range eon.Keypers {
+		if !eon.Transcript.IsParticipant(uint64(keyper)) {
+			continue
+		}
+		if keyper >= len(eon.CommitmentHeights) || eon.CommitmentHeights[keyper] == 0 {
+			return nil, errors.Errorf("unknown poly commitment height of keyper %d", keyper)
+		}
+		proof, err := s.fetchCommitmentProof(ctx, eon, uint64(keyper))
+		if err != nil {
+			return nil, err
+		}
+		resp.Commitments = append(resp.Commitments, proof)
+	}
+	return resp, nil
+}
+
+// fetchCommitmentProof looks for the keyper's poly commitment transaction in the block at the
+// known height and proves its inclusion.
+func (s *Server) fetchCommitmentProof(ctx context.Context, eon Eon, keyper uint64) (CommitmentProof, error) {
+	height := eon.CommitmentHeights[keyper]
+	block, err := s.client.Block(ctx, &height)
+	if err != nil {
+		return CommitmentProof{}, errors.Wrapf(err, "failed to fetch block at height %d", height)
+	}
+	for i, tx := range block.Block.Data.Txs {
+		sender, msg, err := decodeTx(tx)
+		if err != nil || sender != eon.Keypers[keyper] {
+			continue
+		}
+		if findPolyCommitment(msg.Msg, eon.Eon) == nil {
+			continue
+		}
+		proofJSON, err := tmjson.Marshal(block.Block.Data.Txs.Proof(i))
+		if err != nil {
+			return CommitmentProof{}, errors.WithStack(err)
+		}
+		header, err := s.fetchHeaderProof(ctx, height)
+		if err != nil {
+			return CommitmentProof{}, err
+		}
+		return CommitmentProof{Keyper: keyper, Tx: []byte(tx), Proof: proofJSON, Header: header}, nil
+	}
+	return CommitmentProof{}, errors.Errorf(
+		"poly commitment of keyper %d not found in block at height %d", keyper, height,
+	)
+}
+
+func (s *Server) fetchHeaderProof(ctx context.Context, height int64) (HeaderProof, error) {
+	commit, err := s.client.Commit(ctx, &height)
+	if err != nil {
+		return HeaderProof{}, errors.Wrapf(err, "failed to fetch commit at height %d", height)
+	}
+	perPage := maxValidatorsPerPage
+	validators, err := s.client.Validators(ctx, &height, nil, &perPage)
+	if err != nil {
+		return HeaderProof{}, errors.Wrapf(err, "failed to fetch validators at height %d", height)
+	}
+	if validators.Count != validators.Total {
+		return HeaderProof{}, errors.Errorf("too many validators at height %d", height)
+	}
+
+	commitJSON, err := tmjson.Marshal(commit)
+	if err != nil {
+		return HeaderProof{}, errors.WithStack(err)
+	}
+	validatorsJSON, err := tmjson.Marshal(validators)
+	if err != nil {
+		return HeaderProof{}, errors.WithStack(err)
+	}
+	return HeaderProof{Height: height, Commit: commitJSON, Validators: validatorsJSON}, nil
+}
@@ -0,0 +1,292 @@
+package lightapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+const chainID = "shutter-test"
+
+type headerClient struct {
+	t          *testing.T
+	validators *tmtypes.ValidatorSet
+	privVals   []tmtypes.PrivValidator
+	txs        map[int64]tmtypes.Txs
+}
+
+func (c *headerClient) Block(_ context.Context, height *int64) (*ctypes.ResultBlock, error) {
+	return &ctypes.ResultBlock{Block: &tmtypes.Block{Data: tmtypes.Data{Txs: c.txs[*height]}}}, nil
+}
+
+func (c *headerClient) Commit(_ context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	header := &tmtypes.Header{
+		Version:            tmversion.Consensus{Block: version.BlockProtocol},
+		ChainID:            chainID,
+		Height:             *height,
+		Time:               time.Now(),
+		DataHash:           c.txs[*height].Hash(),
+		ValidatorsHash:     c.validators.Hash(),
+		NextValidatorsHash: c.validators.Hash(),
+		ProposerAddress:    c.validators.GetProposer().Address,
+	}
+	blockID := tmtypes.BlockID{
+		Hash:          header.Hash(),
+		PartSetHeader: tmtypes.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+	}
+	voteSet := tmtypes.NewVoteSet(chainID, *height, 0, tmproto.PrecommitType, c.validators)
+	commit, err := tmtypes.MakeCommit(blockID, *height, 0, voteSet, c.privVals, time.Now())
+	assert.NilError(c.t, err)
+	return &ctypes.ResultCommit{
+		SignedHeader:    tmtypes.SignedHeader{Header: header, Commit: commit},
+		CanonicalCommit: true,
+	}, nil
+}
+
+func (c *headerClient) Validators(_ context.Context, height *int64, _, _ *int) (*ctypes.ResultValidators, error) {
+	n := len(c.validators.Validators)
+	return &ctypes.ResultValidators{
+		BlockHeight: *height,
+		Validators:  c.validators.Validators,
+		Count:       n,
+		Total:       n,
+	}, nil
+}
+
+// runDKG runs a DKG process between the given number of keypers and returns the transcript, the
+// keypers' poly commitments and their eon secret key shares.
+func runDKG(t *testing.T, numKeypers uint64, threshold uint64) (
+	*puredkg.Transcript, []puredkg.PolyCommitmentMsg, []*shcrypto.EonSecretKeyShare,
+) {
+	t.Helper()
+	var dkgs []*puredkg.PureDKG
+	var commitments []puredkg.PolyCommitmentMsg
+	for i := uint64(0); i < numKeypers; i++ {
+		dkg := puredkg.NewPureDKG(1, numKeypers, threshold, i)
+		dkgs = append(dkgs, &dkg)
+	}
+	for _, dkg := range dkgs {
+		commitment, evals, err := dkg.StartPhase1Dealing()
+		assert.NilError(t, err)
+		commitments = append(commitments, commitment)
+		for _, receiver := range dkgs {
+			assert.NilError(t, receiver.HandlePolyCommitmentMsg(commitment))
+		}
+		for _, eval := range evals {
+			assert.NilError(t, dkgs[eval.Receiver].HandlePolyEvalMsg(eval))
+		}
+	}
+	for _, dkg := range dkgs {
+		dkg.StartPhase2Accusing()
+	}
+	for _, dkg := range dkgs {
+		dkg.StartPhase3Apologizing()
+		dkg.Finalize()
+	}
+	transcript, err := dkgs[0].Transcript()
+	assert.NilError(t, err)
+	var secretKeyShares []*shcrypto.EonSecretKeyShare
+	for _, dkg := range dkgs {
+		result, err := dkg.ComputeResult()
+		assert.NilError(t, err)
+		secretKeyShares = append(secretKeyShares, result.SecretKeyShare)
+	}
+	return transcript, commitments, secretKeyShares
+}
+
+// commitmentTx creates the shuttermint transaction a keyper sends its poly commitment with.
+func commitmentTx(t *testing.T, key *ecdsa.PrivateKey, chainID string, commitment puredkg.PolyCommitmentMsg) tmtypes.Tx {
+	t.Helper()
+	signed, err := shmsg.SignMessage(&shmsg.MessageWithNonce{
+		Msg:     shmsg.NewPolyCommitment(commitment.Eon, commitment.Gammas),
+		ChainId: []byte(chainID),
+	}, key)
+	assert.NilError(t, err)
+	return tmtypes.Tx(base64.RawURLEncoding.EncodeToString(signed))
+}
+
+func TestServeAndVerify(t *testing.T) {
+	validators, privVals := tmtypes.RandValidatorSet(3, 10)
+	client := &headerClient{t: t, validators: validators, privVals: privVals, txs: map[int64]tmtypes.Txs{}}
+	server := NewServer(client)
+	transcript, commitments, _ := runDKG(t, 3, 2)
+
+	var keys []*ecdsa.PrivateKey
+	var keypers []common.Address
+	for i := 0; i < 3; i++ {
+		key, err := ethcrypto.GenerateKey()
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		keypers = append(keypers, ethcrypto.PubkeyToAddress(key.PublicKey))
+	}
+	client.txs[11] = tmtypes.Txs{
+		commitmentTx(t, keys[2], chainID, commitments[0]), // signed by the wrong keyper
+		commitmentTx(t, keys[0], chainID, commitments[0]),
+		commitmentTx(t, keys[1], chainID, commitments[1]),
+	}
+	client.txs[12] = tmtypes.Txs{commitmentTx(t, keys[2], chainID, commitments[2])}
+	server.SetEon(Eon{
+		Eon:               1,
+		StartBatchIndex:   0,
+		Keypers:           keypers,
+		StartHeight:       10,
+		EndHeight:         40,
+		Transcript:        transcript,
+		CommitmentHeights: []int64{11, 11, 12},
+	})
+
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	res, err := http.Get(httpServer.URL + "/eons")
+	assert.NilError(t, err)
+	var eons []uint64
+	assert.NilError(t, json.NewDecoder(res.Body).Decode(&eons))
+	res.Body.Close()
+	assert.DeepEqual(t, []uint64{1}, eons)
+
+	res, err = http.Get(httpServer.URL + "/eons/2")
+	assert.NilError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = http.Get(httpServer.URL + "/eons/1")
+	assert.NilError(t, err)
+	var resp EonResponse
+	assert.NilError(t, json.NewDecoder(res.Body).Decode(&resp))
+	res.Body.Close()
+	assert.Equal(t, 3, len(resp.Commitments))
+
+	verified, err := Verify(&resp, chainID, transcript.PublicKey)
+	assert.NilError(t, err)
+	assert.Assert(t, verified.PublicKey.Equal(transcript.PublicKey))
+
+	_, err = Verify(&resp, "other-chain", transcript.PublicKey)
+	assert.ErrorContains(t, err, "chain")
+
+	otherTranscript, _, _ := runDKG(t, 3, 2)
+	_, err = Verify(&resp, chainID, otherTranscript.PublicKey)
+	assert.ErrorContains(t, err, "expected eon public key")
+
+	missing := resp
+	missing.Commitments = resp.Commitments[1:]
+	_, err = Verify(&missing, chainID, transcript.PublicKey)
+	assert.ErrorContains(t, err, "participant 0")
+
+	swapped := resp
+	swapped.Keypers = []common.Address{keypers[1], keypers[0], keypers[2]}
+	_, err = Verify(&swapped, chainID, transcript.PublicKey)
+	assert.ErrorContains(t, err, "signed by")
+
+	forged := resp
+	forged.Commitments = append([]CommitmentProof{}, resp.Commitments...)
+	forged.Commitments[2].Tx = []byte(commitmentTx(t, keys[2], chainID, commitments[1]))
+	_, err = Verify(&forged, chainID, transcript.PublicKey)
+	assert.ErrorContains(t, err, "different transaction")
+
+	resp.Keypers = resp.Keypers[:2]
+	_, err = Verify(&resp, chainID, transcript.PublicKey)
+	assert.ErrorContains(t, err, "keypers")
+}
+
+func TestServeEpochKeys(t *testing.T) {
+	server := NewServer(nil)
+	transcript, _, secretKeyShares := runDKG(t, 3, 2)
+	keypers := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2"), common.HexToAddress("0x3")}
+	share := func(keyper int, namespace string, epoch uint64) shutterevents.EpochSecretKeyShare {
+		epochID := shcrypto.ComputeNamespacedEpochID(namespace, epoch)
+		return shutterevents.EpochSecretKeyShare{
+			Height:    int64(100 + keyper),
+			Sender:    keypers[keyper],
+			Eon:       1,
+			Epoch:     epoch,
+			Share:     shcrypto.ComputeEpochSecretKeyShare(secretKeyShares[keyper], epochID),
+			Namespace: namespace,
+		}
+	}
+	invalid := share(0, "", 5)
+	invalid.Share = share(0, "", 6).Share
+	server.SetEon(Eon{
+		Eon:        1,
+		Keypers:    keypers,
+		Transcript: transcript,
+		EpochSecretKeyShares: []shutterevents.EpochSecretKeyShare{
+			invalid,
+			share(1, "", 5),
+			share(1, "", 5),
+			share(2, "", 5),
+			share(0, "rollup", 5),
+			share(1, "rollup", 5),
+			share(2, "", 6),
+		},
+	})
+
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+	get := func(query string) (*EpochKeyResponse, int) {
+		res, err := http.Get(httpServer.URL + "/keys?" + query)
+		assert.NilError(t, err)
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, res.StatusCode
+		}
+		var resp EpochKeyResponse
+		assert.NilError(t, json.NewDecoder(res.Body).Decode(&resp))
+		return &resp, res.StatusCode
+	}
+	checkKey := func(resp *EpochKeyResponse, namespace string, epoch uint64) {
+		t.Helper()
+		var indices []int
+		var shares []*shcrypto.EpochSecretKeyShare
+		for _, keyper := range []int{0, 1} {
+			indices = append(indices, keyper)
+			shares = append(shares, share(keyper, namespace, epoch).Share)
+		}
+		expected, err := shcrypto.ComputeEpochSecretKey(indices, shares, 2)
+		assert.NilError(t, err)
+		key, err := shcrypto.ValidateEpochSecretKey(resp.Key)
+		assert.NilError(t, err)
+		assert.Assert(t, key.Equal(expected))
+	}
+
+	resp, status := get("epoch=5")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, resp.Eon, uint64(1))
+	assert.Equal(t, len(resp.Shares), 2)
+	assert.Equal(t, resp.Shares[0].Keyper, uint64(1)) // the invalid and the duplicate share are ignored
+	assert.Equal(t, resp.Shares[1].Keyper, uint64(2))
+	assert.Equal(t, resp.Shares[1].Height, int64(102))
+	checkKey(resp, "", 5)
+
+	resp, status = get("namespace=rollup&epoch=5")
+	assert.Equal(t, status, http.StatusOK)
+	assert.Equal(t, resp.Namespace, "rollup")
+	checkKey(resp, "rollup", 5)
+
+	_, status = get("epoch=6")
+	assert.Equal(t, status, http.StatusNotFound)
+	_, status = get("namespace=other&epoch=5")
+	assert.Equal(t, status, http.StatusNotFound)
+	_, status = get("namespace=rollup")
+	assert.Equal(t, status, http.StatusBadRequest)
+}
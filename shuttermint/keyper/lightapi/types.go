@@ -0,0 +1,111 @@
+package lightapi
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// EonResponse is the response served for a single eon.
+type EonResponse struct {
+	Eon             uint64           `json:"eon"`
+	StartBatchIndex uint64           `json:"startBatchIndex"`
+	Keypers         []common.Address `json:"keypers"`
+	Transcript      TranscriptJSON   `json:"transcript"`
+	Headers         []HeaderProof    `json:"headers"` // at the start and the end of the DKG
+	// Commitments proves the poly commitment of each participant of the transcript.
+	Commitments []CommitmentProof `json:"commitments"`
+}
+
+// EpochKeyResponse is the response served for a released epoch secret key. It contains the
+// shares the key has been computed from, so that it can be checked against the eon's transcript.
+type EpochKeyResponse struct {
+	Eon       uint64          `json:"eon"`
+	Namespace string          `json:"namespace"`
+	Epoch     uint64          `json:"epoch"`
+	Key       hexutil.Bytes   `json:"key"`
+	Shares    []EpochKeyShare `json:"shares"`
+}
+
+// EpochKeyShare is an epoch secret key share a keyper published.
+type EpochKeyShare struct {
+	Keyper uint64        `json:"keyper"` // index of the keyper in the eon's keyper set
+	Height int64         `json:"height"` // shuttermint height the share was published at
+	Share  hexutil.Bytes `json:"share"`
+}
+
+// HeaderProof contains the signed header at a shuttermint height and the validator set that
+// signed it, both encoded with tendermint's JSON encoding as returned by the commit and
+// validators RPC endpoints.
+type HeaderProof struct {
+	Height     int64           `json:"height"`
+	Commit     json.RawMessage `json:"commit"`
+	Validators json.RawMessage `json:"validators"`
+}
+
+// CommitmentProof proves that a keyper broadcast a poly commitment: it contains the shuttermint
+// transaction carrying the keyper's signed PolyCommitment message, a merkle proof that the
+// transaction is part of the block at the height of Header and the signed header itself.
+type CommitmentProof struct {
+	Keyper uint64          `json:"keyper"` // index of the keyper in the eon's keyper set
+	Tx     hexutil.Bytes   `json:"tx"`
+	Proof  json.RawMessage `json:"proof"` // tendermint's TxProof in tendermint's JSON encoding
+	Header HeaderProof     `json:"header"`
+}
+
+// TranscriptJSON is the JSON representation of a puredkg.Transcript. Curve points are encoded
+// in their marshaled form.
+type TranscriptJSON struct {
+	Eon          uint64          `json:"eon"`
+	NumKeypers   uint64          `json:"numKeypers"`
+	Threshold    uint64          `json:"threshold"`
+	Participants hexutil.Bytes   `json:"participants"`
+	Commitment   []hexutil.Bytes `json:"commitment"`
+	PublicKey    hexutil.Bytes   `json:"publicKey"`
+}
+
+// NewTranscriptJSON converts the transcript to its JSON representation.
+func NewTranscriptJSON(t *puredkg.Transcript) TranscriptJSON {
+	res := TranscriptJSON{
+		Eon:          t.Eon,
+		NumKeypers:   t.NumKeypers,
+		Threshold:    t.Threshold,
+		Participants: t.Participants,
+		PublicKey:    (*bn256.G2)(t.PublicKey).Marshal(),
+	}
+	for _, g := range *t.Commitment {
+		res.Commitment = append(res.Commitment, g.Marshal())
+	}
+	return res
+}
+
+// Transcript converts the JSON representation back to a transcript.
+func (tj TranscriptJSON) Transcript() (*puredkg.Transcript, error) {
+	encodings := make([][]byte, len(tj.Commitment))
+	for i, c := range tj.Commitment {
+		encodings[i] = c
+	}
+	points, err := shcrypto.UnmarshalG2Batch(encodings)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid commitment")
+	}
+	commitment := shcrypto.Gammas(points)
+	publicKey, err := shcrypto.ValidateEonPublicKey(tj.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &puredkg.Transcript{
+		Eon:          tj.Eon,
+		NumKeypers:   tj.NumKeypers,
+		Threshold:    tj.Threshold,
+		Participants: tj.Participants,
+		Commitment:   &commitment,
+		PublicKey:    publicKey,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package lightapi
+
+import (
+	"bytes"
+	"encoding/base64"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// Verify checks the eon response for the given shuttermint chain and the expected eon public key.
+// It checks that the transcript is consistent and results in the public key, that the headers
+// have been signed by more than two thirds of the voting power of the validator sets served with
+// them and that the transcript's aggregated commitment is the sum of poly commitments the
+// participating keypers signed and that are part of those signed blocks.
+//
+// Verify does not establish that the validator sets and the keyper set themselves can be
+// trusted. Callers have to compare them against the ones they trust, e.g. the validator keys
+// the keypers registered on the main chain and the keypers of the batch config starting at
+// StartBatchIndex. Inclusion in a block doesn't prove that shuttermint accepted the transaction,
+// so a keyper could have its signed commitment counted even if it was sent too late.
+func Verify(resp *EonResponse, chainID string, eonPublicKey *shcrypto.EonPublicKey) (*puredkg.Transcript, error) {
+	transcript, err := resp.Transcript.Transcript()
+	if err != nil {
+		return nil, err
+	}
+	if transcript.Eon != resp.Eon {
+		return nil, errors.Errorf("transcript is for eon %d instead of %d", transcript.Eon, resp.Eon)
+	}
+	if transcript.NumKeypers != uint64(len(resp.Keypers)) {
+		return nil, errors.Errorf("transcript is for %d keypers, but %d are given", transcript.NumKeypers, len(resp.Keypers))
+	}
+	if err := transcript.Verify(); err != nil {
+		return nil, err
+	}
+	if !transcript.PublicKey.Equal(eonPublicKey) {
+		return nil, errors.Errorf("transcript does not result in the expected eon public key")
+	}
+
+	if len(resp.Headers) != 2 {
+		return nil, errors.Errorf("expected 2 headers, got %d", len(resp.Headers))
+	}
+	if resp.Headers[0].Height > resp.Headers[1].Height {
+		return nil, errors.Errorf("DKG ends before it starts")
+	}
+	for _, proof := range resp.Headers {
+		if err := VerifyHeaderProof(proof, chainID); err != nil {
+			return nil, err
+		}
+	}
+
+	commitments, err := verifyCommitmentProofs(resp, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := transcript.VerifyCommitments(commitments); err != nil {
+		return nil, err
+	}
+	return transcript, nil
+}
+
+// verifyCommitmentProofs checks the commitment proofs and returns the proven poly commitment of
+// each keyper, or nil if there's no proof for a keyper.
+func verifyCommitmentProofs(resp *EonResponse, chainID string) ([]*shcrypto.Gammas, error) {
+	commitments := make([]*shcrypto.Gammas, len(resp.Keypers))
+	for _, p := range resp.Commitments {
+		if p.Keyper >= uint64(len(resp.Keypers)) {
+			return nil, errors.Errorf("commitment proof for unknown keyper %d", p.Keyper)
+		}
+		if commitments[p.Keyper] != nil {
+			return nil, errors.Errorf("duplicate commitment proof for keyper %d", p.Keyper)
+		}
+		gammas, err := verifyCommitmentProof(p, resp.Eon, resp.Keypers[p.Keyper], chainID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid commitment proof for keyper %d", p.Keyper)
+		}
+		commitments[p.Keyper] = gammas
+	}
+	return commitments, nil
+}
+
+func verifyCommitmentProof(p CommitmentProof, eon uint64, keyper common.Address, chainID string) (*shcrypto.Gammas, error) {
+	header, err := verifyHeaderProof(p.Header, chainID)
+	if err != nil {
+		return nil, err
+	}
+	var txProof tmtypes.TxProof
+	if err := tmjson.Unmarshal(p.Proof, &txProof); err != nil {
+		return nil, errors.Wrap(err, "failed to decode tx proof")
+	}
+	if !bytes.Equal(txProof.Data, p.Tx) {
+		return nil, errors.Errorf("tx proof is for a different transaction")
+	}
+	if err := txProof.Validate(header.DataHash); err != nil {
+		return nil, errors.Wrapf(err, "transaction not part of block at height %d", header.Height)
+	}
+
+	sender, msg, err := decodeTx(tmtypes.Tx(p.Tx))
+	if err != nil {
+		return nil, err
+	}
+	if sender != keyper {
+		return nil, errors.Errorf("transaction has been signed by %s instead of %s", sender.Hex(), keyper.Hex())
+	}
+	if string(msg.ChainId) != chainID {
+		return nil, errors.Errorf("transaction is for chain %q", msg.ChainId)
+	}
+	if msg.Msg.GetPolyCommitment() == nil && msg.Msg.GetPolyCommitments() == nil {
+		return nil, errors.Errorf("transaction is not a poly commitment")
+	}
+	commitment := findPolyCommitment(msg.Msg, eon)
+	if commitment == nil {
+		return nil, errors.Errorf("transaction does not contain a poly commitment for eon %d", eon)
+	}
+	points, err := shcrypto.UnmarshalG2Batch(commitment.Gammas)
+	if err != nil {
+		return nil, err
+	}
+	gammas := shcrypto.Gammas(points)
+	return &gammas, nil
+}
+
+// findPolyCommitment returns the poly commitment for the given eon contained in the message, which
+// may be a single or a batched poly commitment, or nil if there is none.
+func findPolyCommitment(msg *shmsg.Message, eon uint64) *shmsg.PolyCommitment {
+	if c := msg.GetPolyCommitment(); c != nil && c.Eon == eon {
+		return c
+	}
+	if cs := msg.GetPolyCommitments(); cs != nil {
+		for _, c := range cs.Commitments {
+			if c.Eon == eon {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// decodeTx decodes a shuttermint transaction and returns its signer and message.
+func decodeTx(tx tmtypes.Tx) (common.Address, *shmsg.MessageWithNonce, error) {
+	signedMsg, err := base64.RawURLEncoding.DecodeString(string(tx))
+	if err != nil {
+		return common.Address{}, nil, errors.Wrap(err, "failed to decode transaction")
+	}
+	sender, err := shmsg.GetSigner(signedMsg)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	msg, err := shmsg.GetMessage(signedMsg)
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	return sender, msg, nil
+}
+
+// VerifyHeaderProof checks that the header in the proof has been signed by more than two thirds of
+// the voting power of the validator set in the proof.
+func VerifyHeaderProof(proof HeaderProof, chainID string) error {
+	_, err := verifyHeaderProof(proof, chainID)
+	return err
+}
+
+func verifyHeaderProof(proof HeaderProof, chainID string) (*tmtypes.Header, error) {
+	var commit ctypes.ResultCommit
+	if err := tmjson.Unmarshal(proof.Commit, &commit); err != nil {
+		return nil, errors.Wrap(err, "failed to decode commit")
+	}
+	var validators ctypes.ResultValidators
+	if err := tmjson.Unmarshal(proof.Validators, &validators); err != nil {
+		return nil, errors.Wrap(err, "failed to decode validators")
+	}
+
+	sh := commit.SignedHeader
+	if err := sh.ValidateBasic(chainID); err != nil {
+		return nil, errors.Wrapf(err, "invalid signed header at height %d", proof.Height)
+	}
+	if sh.Height != proof.Height {
+		return nil, errors.Errorf("header is for height %d instead of %d", sh.Height, proof.Height)
+	}
+	valSet, err := tmtypes.ValidatorSetFromExistingValidators(validators.Validators)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid validator set at height %d", proof.Height)
+	}
+	if !bytes.Equal(sh.ValidatorsHash, valSet.Hash()) {
+		return nil, errors.Errorf("validator set does not match header at height %d", proof.Height)
+	}
+	if err := valSet.VerifyCommitLight(chainID, sh.Commit.BlockID, sh.Height, sh.Commit); err != nil {
+		return nil, errors.Wrapf(err, "invalid commit at height %d", proof.Height)
+	}
+	return sh.Header, nil
+}
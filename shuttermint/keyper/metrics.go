@@ -0,0 +1,22 @@
+package keyper
+
+import "github.com/shutter-network/shutter/shuttermint/keyper/metrics"
+
+// updateMetrics updates the metrics describing the step that has just been decided.
+func (kpr *Keyper) updateMetrics() {
+	if kpr.metrics == nil {
+		return
+	}
+	kpr.metrics.DecideRun(len(kpr.State.Actions))
+
+	phases := make(map[uint64]int, len(kpr.State.DKGs))
+	for _, dkg := range kpr.State.DKGs {
+		phases[dkg.Eon] = int(dkg.Pure.Phase)
+	}
+	kpr.metrics.SetDKGPhases(phases)
+
+	world := kpr.CurrentWorld()
+	kpr.metrics.SetSyncLag(metrics.MainChain, world.MainChain.BlocksBehind())
+	kpr.metrics.SetSyncLag(metrics.Shuttermint, world.Shutter.BlocksBehind())
+	kpr.metrics.SetPendingActions(kpr.runenv.PendingActions.Len())
+}
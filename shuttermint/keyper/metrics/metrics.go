@@ -0,0 +1,146 @@
+// Package metrics exposes the keyper's internals as Prometheus metrics, so that operators can
+// alert on them with their existing monitoring. All methods can be called on a nil *Metrics, which
+// records nothing, so callers don't need to check if metrics are enabled.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shutter_keyper"
+
+// Chains the sync lag is reported for.
+const (
+	MainChain   = "mainchain"
+	Shuttermint = "shuttermint"
+)
+
+// Metrics holds the keyper's metrics in a registry of their own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	decideRuns     prometheus.Counter
+	decideActions  prometheus.Histogram
+	dkgPhase       *prometheus.GaugeVec
+	sharesSent     prometheus.Counter
+	pendingActions prometheus.Gauge
+	syncLag        *prometheus.GaugeVec
+	actionErrors   *prometheus.CounterVec
+}
+
+// New creates the metrics and registers them, together with the Go runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		decideRuns: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "decide_runs_total",
+			Help:      "Number of decision steps run.",
+		}),
+		decideActions: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "decide_actions",
+			Help:      "Number of actions produced per decision step.",
+			Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+		}),
+		dkgPhase: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "dkg_phase",
+			Help:      "Phase of the DKG of an eon: 0 off, 1 dealing, 2 accusing, 3 apologizing, 4 finalized.",
+		}, []string{"eon"}),
+		sharesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "epoch_secret_key_shares_sent_total",
+			Help:      "Number of epoch secret key shares we've decided to publish.",
+		}),
+		pendingActions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pending_actions",
+			Help:      "Number of actions not done yet, i.e. shuttermint messages and main chain transactions.",
+		}),
+		syncLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sync_lag_blocks",
+			Help:      "Number of blocks the observed state is behind the chain tip.",
+		}, []string{"chain"}),
+		actionErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "action_errors_total",
+			Help:      "Number of failed attempts to run an action.",
+		}, []string{"retriable"}),
+	}
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.decideRuns,
+		m.decideActions,
+		m.dkgPhase,
+		m.sharesSent,
+		m.pendingActions,
+		m.syncLag,
+		m.actionErrors,
+	)
+	return m
+}
+
+// Handler returns an http handler serving the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// DecideRun records a decision step producing the given number of actions.
+func (m *Metrics) DecideRun(numActions int) {
+	if m == nil {
+		return
+	}
+	m.decideRuns.Inc()
+	m.decideActions.Observe(float64(numActions))
+}
+
+// SetDKGPhases sets the phases of the DKGs by eon. Eons not given anymore are removed.
+func (m *Metrics) SetDKGPhases(phases map[uint64]int) {
+	if m == nil {
+		return
+	}
+	m.dkgPhase.Reset()
+	for eon, phase := range phases {
+		m.dkgPhase.WithLabelValues(strconv.FormatUint(eon, 10)).Set(float64(phase))
+	}
+}
+
+// ShareSent records that we've decided to publish an epoch secret key share.
+func (m *Metrics) ShareSent() {
+	if m == nil {
+		return
+	}
+	m.sharesSent.Inc()
+}
+
+// SetPendingActions sets the number of pending actions.
+func (m *Metrics) SetPendingActions(n int) {
+	if m == nil {
+		return
+	}
+	m.pendingActions.Set(float64(n))
+}
+
+// SetSyncLag sets the number of blocks we're behind the tip of the given chain, MainChain or
+// Shuttermint.
+func (m *Metrics) SetSyncLag(chain string, blocks uint64) {
+	if m == nil {
+		return
+	}
+	m.syncLag.WithLabelValues(chain).Set(float64(blocks))
+}
+
+// ActionError records a failed attempt to run an action.
+func (m *Metrics) ActionError(retriable bool) {
+	if m == nil {
+		return
+	}
+	m.actionErrors.WithLabelValues(strconv.FormatBool(retriable)).Inc()
+}
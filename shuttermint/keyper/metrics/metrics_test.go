@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+)
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.DecideRun(3)
+	m.SetDKGPhases(map[uint64]int{1: 2})
+	m.ShareSent()
+	m.SetPendingActions(1)
+	m.SetSyncLag(MainChain, 5)
+	m.ActionError(true)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+	m.DecideRun(0)
+	m.DecideRun(3)
+	assert.Equal(t, testutil.ToFloat64(m.decideRuns), 2.0)
+
+	m.SetDKGPhases(map[uint64]int{1: 4, 2: 1})
+	assert.Equal(t, testutil.ToFloat64(m.dkgPhase.WithLabelValues("2")), 1.0)
+	m.SetDKGPhases(map[uint64]int{2: 2})
+	assert.Equal(t, testutil.CollectAndCount(m.dkgPhase), 1)
+	assert.Equal(t, testutil.ToFloat64(m.dkgPhase.WithLabelValues("2")), 2.0)
+
+	m.ShareSent()
+	m.SetPendingActions(7)
+	m.SetSyncLag(MainChain, 5)
+	m.SetSyncLag(Shuttermint, 0)
+	m.ActionError(true)
+	m.ActionError(true)
+	m.ActionError(false)
+	assert.Equal(t, testutil.ToFloat64(m.sharesSent), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.pendingActions), 7.0)
+	assert.Equal(t, testutil.ToFloat64(m.syncLag.WithLabelValues(MainChain)), 5.0)
+	assert.Equal(t, testutil.ToFloat64(m.actionErrors.WithLabelValues("true")), 2.0)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(body), `shutter_keyper_sync_lag_blocks{chain="mainchain"} 5`))
+	assert.Assert(t, strings.Contains(string(body), "shutter_keyper_decide_actions_count 2"))
+}
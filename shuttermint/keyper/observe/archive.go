@@ -0,0 +1,129 @@
+package observe
+
+import (
+	pkgErrors "github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+)
+
+// EonArchive stores the events of historical eons outside of memory, see package eonstore.
+type EonArchive interface {
+	// ArchiveEon stores the events of the given eon. If eon.Archived is false, events archived
+	// before are replaced, otherwise the events are appended to them.
+	ArchiveEon(eon *Eon) error
+}
+
+// ArchiveEons moves the events of all but the last keep eons to the given archive, so that only
+// the active eons are kept in memory. The archived eons stay in Eons with their start event, but
+// without any other events and with Archived set. Events for these eons that arrive later are
+// kept in memory until ArchiveEons is called again.
+//
+// This method does not mutate the object in place, it rather returns a new object.
+func (shutter *Shutter) ArchiveEons(archive EonArchive, keep int) (*Shutter, error) {
+	clone := *shutter
+	clone.Eons = make([]Eon, len(shutter.Eons))
+	copy(clone.Eons, shutter.Eons)
+	for i := 0; i < len(clone.Eons)-keep; i++ {
+		eon := &clone.Eons[i]
+		if eon.Archived && !eon.hasEvents() {
+			continue
+		}
+		if err := archive.ArchiveEon(eon); err != nil {
+			return nil, pkgErrors.Wrapf(err, "failed to archive eon %d", eon.Eon)
+		}
+		clone.Eons[i] = Eon{
+			Eon:         eon.Eon,
+			StartHeight: eon.StartHeight,
+			StartEvent:  eon.StartEvent,
+			Archived:    true,
+		}
+		if clone.seen != nil {
+			clone.seen.forgetEon(eon.Eon)
+		}
+	}
+	return &clone, nil
+}
+
+func (eon *Eon) hasEvents() bool {
+	return len(eon.Commitments) > 0 ||
+		len(eon.PolyEvals) > 0 ||
+		len(eon.Accusations) > 0 ||
+		len(eon.Apologies) > 0 ||
+		len(eon.EpochSecretKeyShares) > 0
+}
+
+// ArchiveNewEvents appends the events received in blocks after fromHeight to the archive. It's
+// meant to be called for every new object received from SyncShutter, whose filter drops events
+// that have been seen before. All but the last keep eons are marked as archived and stripped of
+// their events and of the record of the messages received for them.
+//
+// This method does not mutate the object in place, it rather returns a new object.
+func (shutter *Shutter) ArchiveNewEvents(archive EonArchive, fromHeight int64, keep int) (*Shutter, error) {
+	for i := range shutter.Eons {
+		eon := &shutter.Eons[i]
+		recent := Eon{
+			Eon:                  eon.Eon,
+			StartHeight:          eon.StartHeight,
+			StartEvent:           eon.StartEvent,
+			Commitments:          eon.GetPolyCommitments(fromHeight + 1),
+			PolyEvals:            eon.GetPolyEvals(fromHeight + 1),
+			Accusations:          eon.GetAccusations(fromHeight + 1),
+			Apologies:            eon.GetApologies(fromHeight + 1),
+			EpochSecretKeyShares: eon.GetEpochSecretKeyShares(fromHeight + 1),
+			Archived:             eon.StartHeight <= fromHeight,
+		}
+		if recent.Archived && !recent.hasEvents() {
+			continue
+		}
+		if err := archive.ArchiveEon(&recent); err != nil {
+			return nil, pkgErrors.Wrapf(err, "failed to archive eon %d", eon.Eon)
+		}
+	}
+
+	clone := *shutter
+	clone.Eons = make([]Eon, len(shutter.Eons))
+	copy(clone.Eons, shutter.Eons)
+	for i := 0; i < len(clone.Eons)-keep; i++ {
+		eon := &clone.Eons[i]
+		if eon.Archived && !eon.hasEvents() {
+			continue
+		}
+		clone.Eons[i] = Eon{
+			Eon:         eon.Eon,
+			StartHeight: eon.StartHeight,
+			StartEvent:  eon.StartEvent,
+			Archived:    true,
+		}
+		if clone.seen != nil {
+			clone.seen.forgetEon(eon.Eon)
+		}
+	}
+	return &clone, nil
+}
+
+// DropEons removes the eons the given policy drops from Eons. Only archived eons without events
+// are removed, the others are kept until their events have been archived.
+//
+// This method does not mutate the object in place, it rather returns a new object, or the object
+// itself if no eon is removed.
+func (shutter *Shutter) DropEons(policy retention.Policy) *Shutter {
+	numbers := make([]uint64, len(shutter.Eons))
+	for i, eon := range shutter.Eons {
+		numbers[i] = eon.Eon
+	}
+	levels := policy.Levels(numbers)
+
+	var eons []Eon
+	for _, eon := range shutter.Eons {
+		if levels[eon.Eon] == retention.Drop && eon.Archived && !eon.hasEvents() {
+			continue
+		}
+		eons = append(eons, eon)
+	}
+	if len(eons) == len(shutter.Eons) {
+		return shutter
+	}
+	clone := *shutter
+	clone.Eons = eons
+	return &clone
+}
@@ -0,0 +1,306 @@
+package observe
+
+import (
+	"context"
+	"math/big"
+	"runtime"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+// The events of our contracts are fetched in one of two ways. The slow path queries the logs of
+// each event type separately using the contract bindings. The fast path, used if the event sources
+// are set in the contract caller, first checks the log blooms of the block headers and skips
+// querying the logs altogether if none of the blocks can contain any of our events, which is the
+// common case on chains with short block times. Otherwise, it fetches the logs of all event types
+// with a single query and decodes them concurrently. Both paths yield the same events in the same
+// order.
+
+// maxBloomCheckBlocks is the maximum number of blocks whose log blooms we check before querying
+// the logs. Larger ranges, e.g. when catching up, are queried directly.
+const maxBloomCheckBlocks = 16
+
+// mainChainEvents are the events of our contracts in a range of blocks, in the order they have
+// been emitted.
+type mainChainEvents struct {
+	TransactionsAdded []*contract.BatcherContractTransactionAdded
+	DepositsChanged   []*contract.DepositContractDepositChanged
+	Accused           []*contract.KeyperSlasherAccused
+	Appealed          []*contract.KeyperSlasherAppealed
+}
+
+// between returns the events to apply: transactions added and deposit changes after fromBlock,
+// and accusations and appeals after accusationsFrom up to accusationsTo.
+func (events *mainChainEvents) between(fromBlock, accusationsFrom, accusationsTo uint64) *mainChainEvents {
+	res := &mainChainEvents{}
+	for _, ev := range events.TransactionsAdded {
+		if ev.Raw.BlockNumber > fromBlock {
+			res.TransactionsAdded = append(res.TransactionsAdded, ev)
+		}
+	}
+	for _, ev := range events.DepositsChanged {
+		if ev.Raw.BlockNumber > fromBlock {
+			res.DepositsChanged = append(res.DepositsChanged, ev)
+		}
+	}
+	isPending := func(block uint64) bool {
+		return block > accusationsFrom && block <= accusationsTo
+	}
+	for _, ev := range events.Accused {
+		if isPending(ev.Raw.BlockNumber) {
+			res.Accused = append(res.Accused, ev)
+		}
+	}
+	for _, ev := range events.Appealed {
+		if isPending(ev.Raw.BlockNumber) {
+			res.Appealed = append(res.Appealed, ev)
+		}
+	}
+	return res
+}
+
+var (
+	transactionAddedID = eventID(contract.BatcherContractABI, "TransactionAdded")
+	depositChangedID   = eventID(contract.DepositContractABI, "DepositChanged")
+	accusedID          = eventID(contract.KeyperSlasherABI, "Accused")
+	appealedID         = eventID(contract.KeyperSlasherABI, "Appealed")
+)
+
+func eventID(abiJSON string, name string) common.Hash {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(err)
+	}
+	event, ok := parsed.Events[name]
+	if !ok {
+		panic("unknown event " + name)
+	}
+	return event.ID
+}
+
+// headerClient is the part of the ethclient used to check the log blooms.
+type headerClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// eventFilter describes the logs of the events we observe.
+type eventFilter struct {
+	addresses []common.Address
+	topics    []common.Hash
+}
+
+func newEventFilter(sources contract.EventSources) eventFilter {
+	return eventFilter{
+		addresses: []common.Address{sources.Batcher, sources.Deposit, sources.KeyperSlasher},
+		topics:    []common.Hash{transactionAddedID, depositChangedID, accusedID, appealedID},
+	}
+}
+
+// mayMatch checks if a block with the given log bloom may contain any of our events.
+func (f eventFilter) mayMatch(bloom types.Bloom) bool {
+	addressMatch := false
+	for _, address := range f.addresses {
+		if types.BloomLookup(bloom, address) {
+			addressMatch = true
+			break
+		}
+	}
+	if !addressMatch {
+		return false
+	}
+	for _, topic := range f.topics {
+		if types.BloomLookup(bloom, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// bloomRange returns the first and the last block in the given range whose log bloom may contain
+// our events. It returns false if there is no such block.
+func (f eventFilter) bloomRange(ctx context.Context, client headerClient, from, to uint64) (uint64, uint64, bool, error) {
+	matches := make([]bool, to-from+1)
+	g, gctx := errgroup.WithContext(ctx)
+	for i := range matches {
+		i := i
+		g.Go(func() error {
+			number := from + uint64(i)
+			header, err := client.HeaderByNumber(gctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				return errors.Wrapf(err, "failed to get header of main chain block %d", number)
+			}
+			matches[i] = f.mayMatch(header.Bloom)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, 0, false, err
+	}
+
+	first, last, ok := uint64(0), uint64(0), false
+	for i, match := range matches {
+		if !match {
+			continue
+		}
+		if !ok {
+			first, ok = from+uint64(i), true
+		}
+		last = from + uint64(i)
+	}
+	return first, last, ok, nil
+}
+
+// fetchEvents fetches the events of our contracts in the given range of blocks.
+func fetchEvents(ctx context.Context, cc *contract.Caller, filter *bind.FilterOpts) (*mainChainEvents, error) {
+	if !cc.EventSources.IsSet() {
+		return fetchEventsSlow(cc, filter)
+	}
+	from, to := filter.Start, *filter.End
+	if to < from {
+		return &mainChainEvents{}, nil
+	}
+	f := newEventFilter(cc.EventSources)
+	if to-from < maxBloomCheckBlocks {
+		first, last, ok, err := f.bloomRange(ctx, cc.Headers(), from, to)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return &mainChainEvents{}, nil
+		}
+		from, to = first, last
+	}
+	logs, err := cc.Ethclient.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: f.addresses,
+		Topics:    [][]common.Hash{f.topics},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter main chain logs")
+	}
+	return decodeEvents(cc, logs)
+}
+
+// decodeEvents decodes the given logs concurrently, ignoring the ones that aren't ours.
+func decodeEvents(cc *contract.Caller, logs []types.Log) (*mainChainEvents, error) {
+	decoded := make([]interface{}, len(logs))
+	chunkSize := (len(logs) + runtime.NumCPU() - 1) / runtime.NumCPU()
+	var g errgroup.Group
+	for start := 0; start < len(logs); start += chunkSize {
+		start := start
+		end := start + chunkSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+		g.Go(func() error {
+			for i := start; i < end; i++ {
+				ev, err := decodeEvent(cc, logs[i])
+				if err != nil {
+					return errors.Wrapf(err, "failed to decode log %d of tx %s", logs[i].Index, logs[i].TxHash.Hex())
+				}
+				decoded[i] = ev
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	events := &mainChainEvents{}
+	for _, ev := range decoded {
+		switch ev := ev.(type) {
+		case *contract.BatcherContractTransactionAdded:
+			events.TransactionsAdded = append(events.TransactionsAdded, ev)
+		case *contract.DepositContractDepositChanged:
+			events.DepositsChanged = append(events.DepositsChanged, ev)
+		case *contract.KeyperSlasherAccused:
+			events.Accused = append(events.Accused, ev)
+		case *contract.KeyperSlasherAppealed:
+			events.Appealed = append(events.Appealed, ev)
+		}
+	}
+	return events, nil
+}
+
+// decodeEvent decodes a log into the contract binding's event type. It returns nil if the log
+// isn't one of our events.
+func decodeEvent(cc *contract.Caller, log types.Log) (interface{}, error) {
+	if len(log.Topics) == 0 || log.Removed {
+		return nil, nil
+	}
+	sources := cc.EventSources
+	switch {
+	case log.Address == sources.Batcher && log.Topics[0] == transactionAddedID:
+		return cc.BatcherContract.ParseTransactionAdded(log)
+	case log.Address == sources.Deposit && log.Topics[0] == depositChangedID:
+		return cc.DepositContract.ParseDepositChanged(log)
+	case log.Address == sources.KeyperSlasher && log.Topics[0] == accusedID:
+		return cc.KeyperSlasher.ParseAccused(log)
+	case log.Address == sources.KeyperSlasher && log.Topics[0] == appealedID:
+		return cc.KeyperSlasher.ParseAppealed(log)
+	default:
+		return nil, nil
+	}
+}
+
+// fetchEventsSlow fetches the events of our contracts in the given range of blocks, querying the
+// logs of each event type separately.
+func fetchEventsSlow(cc *contract.Caller, filter *bind.FilterOpts) (*mainChainEvents, error) {
+	events := &mainChainEvents{}
+
+	txIt, err := cc.BatcherContract.FilterTransactionAdded(filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter for added transaction events")
+	}
+	for txIt.Next() {
+		events.TransactionsAdded = append(events.TransactionsAdded, txIt.Event)
+	}
+	if txIt.Error() != nil {
+		return nil, errors.Wrap(txIt.Error(), "failed to iterate added transaction events")
+	}
+
+	depositIt, err := cc.DepositContract.FilterDepositChanged(filter, []common.Address{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter for deposit changed events")
+	}
+	for depositIt.Next() {
+		events.DepositsChanged = append(events.DepositsChanged, depositIt.Event)
+	}
+	if depositIt.Error() != nil {
+		return nil, errors.Wrap(depositIt.Error(), "failed to iterate deposit changed events")
+	}
+
+	accusedIt, err := cc.KeyperSlasher.FilterAccused(filter, []uint64{}, []common.Address{}, []common.Address{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter accused events")
+	}
+	for accusedIt.Next() {
+		events.Accused = append(events.Accused, accusedIt.Event)
+	}
+	if accusedIt.Error() != nil {
+		return nil, errors.Wrap(accusedIt.Error(), "failed to iterate accused events")
+	}
+
+	appealedIt, err := cc.KeyperSlasher.FilterAppealed(filter, []uint64{}, []common.Address{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to filter appealed events")
+	}
+	for appealedIt.Next() {
+		events.Appealed = append(events.Appealed, appealedIt.Event)
+	}
+	if appealedIt.Error() != nil {
+		return nil, errors.Wrap(appealedIt.Error(), "failed to iterate appealed events")
+	}
+	return events, nil
+}
@@ -0,0 +1,145 @@
+package observe
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+type fakeHeaders map[uint64]types.Bloom
+
+func (h fakeHeaders) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: number, Bloom: h[number.Uint64()]}, nil
+}
+
+func newEventsTestCaller(t *testing.T) *contract.Caller {
+	t.Helper()
+	sources := contract.EventSources{
+		Batcher:       common.BigToAddress(big.NewInt(1)),
+		Deposit:       common.BigToAddress(big.NewInt(2)),
+		KeyperSlasher: common.BigToAddress(big.NewInt(3)),
+	}
+	batcher, err := contract.NewBatcherContract(sources.Batcher, nil)
+	assert.NilError(t, err)
+	deposit, err := contract.NewDepositContract(sources.Deposit, nil)
+	assert.NilError(t, err)
+	slasher, err := contract.NewKeyperSlasher(sources.KeyperSlasher, nil)
+	assert.NilError(t, err)
+	return &contract.Caller{
+		BatcherContract: batcher,
+		DepositContract: deposit,
+		KeyperSlasher:   slasher,
+		EventSources:    sources,
+	}
+}
+
+func transactionAddedLog(t *testing.T, address common.Address, batchIndex uint64) types.Log {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(contract.BatcherContractABI))
+	assert.NilError(t, err)
+	data, err := parsed.Events["TransactionAdded"].Inputs.Pack(
+		batchIndex, uint8(contract.TransactionTypeCipher), []byte{1, 2, 3}, [32]byte{4},
+	)
+	assert.NilError(t, err)
+	return types.Log{Address: address, Topics: []common.Hash{transactionAddedID}, Data: data, BlockNumber: 10 + batchIndex}
+}
+
+func appealedLog(t *testing.T, address common.Address, halfStep uint64, executor common.Address) types.Log {
+	t.Helper()
+	topics, err := abi.MakeTopics([]interface{}{halfStep}, []interface{}{executor})
+	assert.NilError(t, err)
+	return types.Log{Address: address, Topics: []common.Hash{appealedID, topics[0][0], topics[1][0]}, BlockNumber: 20}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	cc := newEventsTestCaller(t)
+	executor := common.BigToAddress(big.NewInt(5))
+	var logs []types.Log
+	for i := uint64(0); i < 50; i++ {
+		logs = append(logs, transactionAddedLog(t, cc.EventSources.Batcher, i))
+	}
+	logs = append(logs,
+		appealedLog(t, cc.EventSources.KeyperSlasher, 8, executor),
+		transactionAddedLog(t, common.BigToAddress(big.NewInt(9)), 100), // not our batcher
+	)
+
+	events, err := decodeEvents(cc, logs)
+	assert.NilError(t, err)
+	assert.Equal(t, len(events.TransactionsAdded), 50)
+	assert.Equal(t, len(events.DepositsChanged), 0)
+	assert.Equal(t, len(events.Accused), 0)
+	assert.Equal(t, len(events.Appealed), 1)
+
+	// the fast path yields the same events as the bindings used by the slow path, in order
+	for i, ev := range events.TransactionsAdded {
+		expected, err := cc.BatcherContract.ParseTransactionAdded(logs[i])
+		assert.NilError(t, err)
+		assert.DeepEqual(t, ev, expected)
+		assert.Equal(t, ev.BatchIndex, uint64(i))
+	}
+	expected, err := cc.KeyperSlasher.ParseAppealed(logs[50])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, events.Appealed[0], expected)
+	assert.Equal(t, events.Appealed[0].HalfStep, uint64(8))
+	assert.Equal(t, events.Appealed[0].Executor, executor)
+}
+
+func TestBloomRange(t *testing.T) {
+	cc := newEventsTestCaller(t)
+	f := newEventFilter(cc.EventSources)
+	ours := transactionAddedLog(t, cc.EventSources.Batcher, 1)
+	foreign := transactionAddedLog(t, common.BigToAddress(big.NewInt(9)), 1)
+	bloom := func(log types.Log) types.Bloom {
+		return types.CreateBloom(types.Receipts{{Logs: []*types.Log{&log}}})
+	}
+	assert.Assert(t, f.mayMatch(bloom(ours)))
+	assert.Assert(t, !f.mayMatch(bloom(foreign)))
+
+	headers := fakeHeaders{12: bloom(foreign), 13: bloom(ours), 15: bloom(ours)}
+	_, _, ok, err := f.bloomRange(context.Background(), headers, 10, 12)
+	assert.NilError(t, err)
+	assert.Assert(t, !ok)
+	first, last, ok, err := f.bloomRange(context.Background(), headers, 10, 16)
+	assert.NilError(t, err)
+	assert.Assert(t, ok)
+	assert.Equal(t, first, uint64(13))
+	assert.Equal(t, last, uint64(15))
+}
+
+func TestEventsBetween(t *testing.T) {
+	raw := func(block uint64) types.Log { return types.Log{BlockNumber: block} }
+	events := &mainChainEvents{}
+	for block := uint64(1); block <= 6; block++ {
+		events.TransactionsAdded = append(events.TransactionsAdded, &contract.BatcherContractTransactionAdded{Raw: raw(block)})
+		events.Accused = append(events.Accused, &contract.KeyperSlasherAccused{Raw: raw(block)})
+	}
+
+	// accusations from blocks 2 and 3 have been pending, 4 to 6 lack confirmations
+	res := events.between(3, 1, 3)
+	assert.Equal(t, len(res.TransactionsAdded), 3)
+	assert.Equal(t, res.TransactionsAdded[0].Raw.BlockNumber, uint64(4))
+	assert.Equal(t, len(res.Accused), 2)
+	assert.Equal(t, res.Accused[0].Raw.BlockNumber, uint64(2))
+	assert.Equal(t, res.Accused[1].Raw.BlockNumber, uint64(3))
+}
+
+func TestConfirmedBlock(t *testing.T) {
+	assert.Equal(t, confirmedBlock(100, 90, 0), uint64(90))
+	assert.Equal(t, confirmedBlock(100, 90, 5), uint64(90))
+	assert.Equal(t, confirmedBlock(100, 90, 20), uint64(80))
+	assert.Equal(t, confirmedBlock(10, 10, 20), uint64(0))
+
+	mainChain := NewMainChain(0)
+	mainChain.HeadBlock = 100
+	mainChain.CurrentBlock = 100
+	mainChain.Confirmations.BatchClosures = 3
+	assert.Equal(t, mainChain.BatchClosureBlock(), uint64(97))
+}
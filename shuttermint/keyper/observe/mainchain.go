@@ -14,6 +14,7 @@ import (
 
 	"github.com/shutter-network/shutter/shlib/shcrypto"
 	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
 	"github.com/shutter-network/shutter/shuttermint/medley"
 )
 
@@ -29,7 +30,10 @@ const (
 // SyncToHead method can be used to update the data. All other accesses should be read-only.
 type MainChain struct {
 	FollowDistance          uint64
+	Confirmations           Confirmations
+	AccusationsLag          uint64 // number of blocks up to CurrentBlock whose accusations are pending
 	CurrentBlock            uint64
+	HeadBlock               uint64 // latest block known to the node, CurrentBlock lags behind
 	NodeSyncProgress        *ethereum.SyncProgress
 	BatchConfigs            []contract.BatchConfig
 	Batches                 map[uint64]*Batch
@@ -37,6 +41,27 @@ type MainChain struct {
 	CipherExecutionReceipts map[uint64]*contract.CipherExecutionReceipt
 	Deposits                map[common.Address]*Deposit
 	Accusations             map[uint64]*Accusation
+	Filter                  MainChainFilter
+}
+
+// MainChainFilter is used to filter the main chain state we keep. Filtering is done in
+// MainChain.ApplyFilter.
+type MainChainFilter struct {
+	BatchIndex uint64 // the transactions of earlier batches are dropped
+}
+
+// NeedsUpdate checks if applying newFilter drops anything the filter doesn't.
+func (filter MainChainFilter) NeedsUpdate(newFilter MainChainFilter) bool {
+	return newFilter.BatchIndex > filter.BatchIndex
+}
+
+// Confirmations are the numbers of confirmations, i.e. blocks on top of the one an event happened
+// in, required before the observer exposes events of the given type. Events always have at least
+// FollowDistance confirmations, zero means no further requirement.
+type Confirmations struct {
+	BatchClosures uint64 // a batch is considered closed once its end block has enough confirmations
+	ConfigChanges uint64
+	Accusations   uint64 // applies to appeals as well
 }
 
 // Batch stores the encrypted and plain transactions submitted to the batching contract for a
@@ -149,12 +174,16 @@ func (mainchain *MainChain) ConfigForBatchIndex(batchIndex uint64) (contract.Bat
 }
 
 func (mainchain *MainChain) syncConfigs(cc *contract.Caller, opts *bind.CallOpts) error {
-	numConfigs, err := cc.ConfigContract.NumConfigs(opts)
+	numConfigs, err := cc.NumConfigs(opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to get number of configs from contract")
 	}
+	if numConfigs < uint64(len(mainchain.BatchConfigs)) {
+		// configs have been unscheduled, make sure we don't use them if they are rescheduled
+		cc.InvalidateConfigs(numConfigs)
+	}
 	for configIndex := uint64(len(mainchain.BatchConfigs)); configIndex < numConfigs; configIndex++ {
-		config, err := cc.ConfigContract.GetConfigByIndex(opts, configIndex)
+		config, err := cc.ConfigByIndex(opts, configIndex)
 		if err != nil {
 			return errors.Wrap(err, "failed to get config by index from contract")
 		}
@@ -163,25 +192,23 @@ func (mainchain *MainChain) syncConfigs(cc *contract.Caller, opts *bind.CallOpts
 	return nil
 }
 
-func (mainchain *MainChain) syncBatches(cc *contract.Caller, filter *bind.FilterOpts) error {
-	it, err := cc.BatcherContract.FilterTransactionAdded(filter)
-	if err != nil {
-		return errors.Wrap(err, "failed to filter for added transaction events")
-	}
-
-	events := []*contract.BatcherContractTransactionAdded{}
-	for it.Next() {
-		events = append(events, it.Event)
-	}
-	if it.Error() != nil {
-		return errors.Wrap(it.Error(), "failed to iterate added transaction events")
-	}
-
-	for _, event := range events {
-		mainchain.addTransaction(event)
+// ApplyFilter applies the given filter and returns a new main chain object with the filter
+// applied, i.e. without the batches before the filter's batch index. The receipts of their
+// execution are kept, because executors may be accused at any time and we need the receipt to
+// appeal.
+func (mainchain *MainChain) ApplyFilter(newFilter MainChainFilter) *MainChain {
+	if !mainchain.Filter.NeedsUpdate(newFilter) {
+		return mainchain
+	}
+	clone := *mainchain
+	clone.Filter = newFilter
+	clone.Batches = make(map[uint64]*Batch)
+	for batchIndex, batch := range mainchain.Batches {
+		if batchIndex >= newFilter.BatchIndex {
+			clone.Batches[batchIndex] = batch
+		}
 	}
-
-	return nil
+	return &clone
 }
 
 // AddTransaction adds a transaction to a batch according to a main chain TransactionAdded event.
@@ -208,18 +235,19 @@ func (mainchain *MainChain) addTransaction(event *contract.BatcherContractTransa
 func (mainchain *MainChain) syncExecutionState(cc *contract.Caller, opts *bind.CallOpts) error {
 	lastNumExecutionHalfSteps := mainchain.NumExecutionHalfSteps
 
-	numExecutionHalfSteps, err := cc.ExecutorContract.NumExecutionHalfSteps(opts)
+	numExecutionHalfSteps, err := cc.NumExecutionHalfSteps(opts)
 	if err != nil {
 		return errors.Wrap(err, "failed to get number of execution half steps from contract")
 	}
 
 	for halfStep := lastNumExecutionHalfSteps; halfStep < numExecutionHalfSteps; halfStep++ {
-		if halfStep%2 != 0 {
+		if !protocol.HalfStep(halfStep).IsCipher() {
 			// there will only be receipts for cipher execution steps, which are the even ones
 			continue
 		}
 
-		receipt, err := cc.ExecutorContract.GetReceipt(opts, halfStep)
+		batchIndex := protocol.HalfStep(halfStep).BatchIndex()
+		receipt, err := cc.ExecutorFor(uint64(batchIndex)).GetReceipt(opts, halfStep)
 		if err != nil {
 			return errors.Wrap(err, "failed to get cipher execution receipt from contract")
 		}
@@ -229,20 +257,7 @@ func (mainchain *MainChain) syncExecutionState(cc *contract.Caller, opts *bind.C
 	return nil
 }
 
-func (mainchain *MainChain) syncDeposits(cc *contract.Caller, filter *bind.FilterOpts) error {
-	eventIt, err := cc.DepositContract.FilterDepositChanged(filter, []common.Address{})
-	if err != nil {
-		return errors.Wrap(err, "failed to filter for deposit changed events")
-	}
-
-	events := []*contract.DepositContractDepositChanged{}
-	for eventIt.Next() {
-		events = append(events, eventIt.Event)
-	}
-	if eventIt.Error() != nil {
-		return errors.Wrap(eventIt.Error(), "failed to iterate deposit changed events")
-	}
-
+func (mainchain *MainChain) applyDeposits(events []*contract.DepositContractDepositChanged) {
 	for _, ev := range events {
 		deposit := mainchain.GetDeposit(ev.Account)
 		deposit.Amount = ev.Amount
@@ -251,39 +266,12 @@ func (mainchain *MainChain) syncDeposits(cc *contract.Caller, filter *bind.Filte
 		deposit.Slashed = ev.Slashed
 		mainchain.Deposits[ev.Account] = deposit
 	}
-
-	return nil
 }
 
-func (mainchain *MainChain) syncSlashings(cc *contract.Caller, filter *bind.FilterOpts) error {
-	accusedIt, err := cc.KeyperSlasher.FilterAccused(filter, []uint64{}, []common.Address{}, []common.Address{})
-	if err != nil {
-		return errors.Wrap(err, "failed to filter accused events")
-	}
-	appealedIt, err := cc.KeyperSlasher.FilterAppealed(filter, []uint64{}, []common.Address{})
-	if err != nil {
-		return errors.Wrap(err, "failed to filter appealed events")
-	}
-	// We don't filter for slashed events. Each slashing also results in a DepositChanged event in
-	// the deposit contract which we already sync.
-
-	accusedEvents := []*contract.KeyperSlasherAccused{}
-	for accusedIt.Next() {
-		accusedEvents = append(accusedEvents, accusedIt.Event)
-	}
-	if accusedIt.Error() != nil {
-		return errors.Wrap(accusedIt.Error(), "failed to iterate accused events")
-	}
-
-	appealedEvents := []*contract.KeyperSlasherAppealed{}
-	for appealedIt.Next() {
-		appealedEvents = append(appealedEvents, appealedIt.Event)
-	}
-	if appealedIt.Error() != nil {
-		return errors.Wrap(appealedIt.Error(), "failed to iterate appealed events")
-	}
-
-	for _, ev := range accusedEvents {
+// applySlashings applies the accused and appealed events. We don't observe slashed events, as each
+// slashing also results in a DepositChanged event in the deposit contract.
+func (mainchain *MainChain) applySlashings(events *mainChainEvents) error {
+	for _, ev := range events.Accused {
 		accusation := Accusation{
 			Executor:    ev.Executor,
 			Accuser:     ev.Accuser,
@@ -293,14 +281,13 @@ func (mainchain *MainChain) syncSlashings(cc *contract.Caller, filter *bind.Filt
 		}
 		mainchain.Accusations[accusation.HalfStep] = &accusation
 	}
-	for _, ev := range appealedEvents {
+	for _, ev := range events.Appealed {
 		accusation, ok := mainchain.Accusations[ev.HalfStep]
 		if !ok {
 			return errors.Errorf("got appeal without prior accusation: %+v", accusation)
 		}
 		accusation.Appealed = true
 	}
-
 	return nil
 }
 
@@ -327,7 +314,7 @@ func (mainchain *MainChain) SyncToHead(
 		return nil, errors.Wrap(err, "failed to get main chain sync progress")
 	}
 
-	latestBlockHeader, err := cc.Ethclient.HeaderByNumber(ctx, nil)
+	latestBlockHeader, err := cc.Headers().HeaderByNumber(ctx, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get latest block header of main chain")
 	}
@@ -345,44 +332,121 @@ func (mainchain *MainChain) SyncToHead(
 	}
 
 	mainchain = mainchain.Clone()
+	err = mainchain.syncUntil(ctx, cc, syncUntilBlockNumber, latestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	mainchain.CurrentBlock = syncUntilBlockNumber
+	mainchain.HeadBlock = latestBlockNumber
+	mainchain.NodeSyncProgress = syncProgress
+	return mainchain, nil
+}
 
+// SyncToBlock fetches the state at the given block number from the ethereum node, regardless of
+// the follow distance and the node's sync progress. Syncing to a block far in the past requires
+// an archive node. This method does not mutate the object in place, it rather returns a new
+// object.
+func (mainchain *MainChain) SyncToBlock(
+	ctx context.Context,
+	cc *contract.Caller,
+	blockNumber uint64,
+) (*MainChain, error) {
+	if blockNumber < mainchain.CurrentBlock {
+		return nil, errors.Errorf(
+			"cannot sync main chain back from block %d to %d", mainchain.CurrentBlock, blockNumber)
+	}
+	if blockNumber == mainchain.CurrentBlock {
+		return mainchain, nil
+	}
+	mainchain = mainchain.Clone()
+	err := mainchain.syncUntil(ctx, cc, blockNumber, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	mainchain.CurrentBlock = blockNumber
+	mainchain.HeadBlock = blockNumber
+	mainchain.NodeSyncProgress = nil
+	return mainchain, nil
+}
+
+// confirmedBlock returns the latest block up to the given block that has the given number of
+// confirmations if the head of the chain is at headBlock.
+func confirmedBlock(headBlock, block, confirmations uint64) uint64 {
+	if confirmations == 0 {
+		return block
+	}
+	if headBlock < confirmations {
+		return 0
+	}
+	if headBlock-confirmations < block {
+		return headBlock - confirmations
+	}
+	return block
+}
+
+// BatchClosureBlock returns the block up to which batches are considered closed, i.e. the latest
+// synced block with the number of confirmations required for batch closures.
+func (mainchain *MainChain) BatchClosureBlock() uint64 {
+	return confirmedBlock(mainchain.HeadBlock, mainchain.CurrentBlock, mainchain.Confirmations.BatchClosures)
+}
+
+// syncUntil applies the changes from the block after CurrentBlock up to the given block, with head
+// being the latest block known to the node. Config changes and accusations are only applied once
+// they have the required confirmations.
+func (mainchain *MainChain) syncUntil(
+	ctx context.Context,
+	cc *contract.Caller,
+	syncUntilBlockNumber uint64,
+	head uint64,
+) error {
 	opts := &bind.CallOpts{
 		BlockNumber: new(big.Int).SetUint64(syncUntilBlockNumber),
 		Context:     ctx,
 	}
+	configOpts := &bind.CallOpts{
+		BlockNumber: new(big.Int).SetUint64(
+			confirmedBlock(head, syncUntilBlockNumber, mainchain.Confirmations.ConfigChanges)),
+		Context: ctx,
+	}
+	// accusations and appeals are applied up to appliedBlock so far, and up to accusationsBlock
+	// after this sync
+	appliedBlock := mainchain.CurrentBlock - mainchain.AccusationsLag
+	accusationsBlock := confirmedBlock(head, syncUntilBlockNumber, mainchain.Confirmations.Accusations)
+	if accusationsBlock < appliedBlock {
+		accusationsBlock = appliedBlock
+	}
 	filter := &bind.FilterOpts{
-		Start: mainchain.CurrentBlock + 1,
+		Start: appliedBlock + 1,
 		End:   &syncUntilBlockNumber,
 	}
 
-	err = mainchain.syncConfigs(cc, opts)
+	err := mainchain.syncConfigs(cc, configOpts)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = mainchain.syncBatches(cc, filter)
+	events, err := fetchEvents(ctx, cc, filter)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	err = mainchain.syncExecutionState(cc, opts)
-	if err != nil {
-		return nil, err
+	events = events.between(mainchain.CurrentBlock, appliedBlock, accusationsBlock)
+	for _, event := range events.TransactionsAdded {
+		mainchain.addTransaction(event)
 	}
 
-	err = mainchain.syncDeposits(cc, filter)
+	err = mainchain.syncExecutionState(cc, opts)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = mainchain.syncSlashings(cc, filter)
+	mainchain.applyDeposits(events.DepositsChanged)
+	err = mainchain.applySlashings(events)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	mainchain.CurrentBlock = syncUntilBlockNumber
-	mainchain.NodeSyncProgress = syncProgress
-	return mainchain, nil
+	mainchain.AccusationsLag = syncUntilBlockNumber - accusationsBlock
+	return nil
 }
 
 // IsSynced checks if the node we are connected to is synced to the network. Prior to the
@@ -391,18 +455,39 @@ func (mainchain *MainChain) IsSynced() bool {
 	return mainchain.NodeSyncProgress == nil
 }
 
-// DecryptTransactions decrypts and shuffles the encrypted transactions. It will log an error
-// message for transactions that cannot be decrypted and skip over them.
-func (batch *Batch) DecryptTransactions(key *shcrypto.EpochSecretKey) [][]byte {
+// BlocksBehind returns the number of blocks we still have to sync until we're FollowDistance
+// blocks behind the tip of the chain. If the node itself is syncing, the highest block it knows of
+// is used as the tip.
+func (mainchain *MainChain) BlocksBehind() uint64 {
+	tip := mainchain.HeadBlock
+	if mainchain.NodeSyncProgress != nil && mainchain.NodeSyncProgress.HighestBlock > tip {
+		tip = mainchain.NodeSyncProgress.HighestBlock
+	}
+	if tip < mainchain.FollowDistance+mainchain.CurrentBlock {
+		return 0
+	}
+	return tip - mainchain.FollowDistance - mainchain.CurrentBlock
+}
+
+// DecryptTransactions decrypts and shuffles the encrypted transactions, which are either envelopes
+// or bare encrypted messages. It will log an error message for transactions that cannot be
+// decrypted or fail validation against the given eon, epoch ID and version of the encryption
+// scheme and skip over them, see shcrypto.Envelope.Validate.
+func (batch *Batch) DecryptTransactions(
+	key *shcrypto.EpochSecretKey, eonStartBatchIndex uint64, epochID *shcrypto.EpochID, version uint8,
+) [][]byte {
 	var res [][]byte
 	for idx, encTx := range batch.EncryptedTransactions {
-		m := shcrypto.EncryptedMessage{}
-		err := m.Unmarshal(encTx)
+		envelope, err := shcrypto.UnmarshalCiphertext(encTx)
 		if err != nil {
 			log.Printf("Error: cannot unmarshal encrypted transaction #%d for batch index=%d: %s", idx, batch.BatchIndex, err)
 			continue
 		}
-		decrypted, err := m.Decrypt(key)
+		if err := envelope.Validate(eonStartBatchIndex, epochID, version); err != nil {
+			log.Printf("Error: invalid encrypted transaction #%d for batch index=%d: %s", idx, batch.BatchIndex, err)
+			continue
+		}
+		decrypted, err := envelope.Message.Decrypt(key)
 		if err != nil {
 			log.Printf("Error: cannot decrypt encrypted transaction #%d for batch index=%d: %s", idx, batch.BatchIndex, err)
 			continue
@@ -414,12 +499,14 @@ func (batch *Batch) DecryptTransactions(key *shcrypto.EpochSecretKey) [][]byte {
 
 // SyncMain subscribes to new blocks on the main chain and syncs the main chain object with the
 // head block in a loop. It writes newly synced main chain objects to the mainChains channel, as
-// well as errors to the syncErrors channel.
+// well as errors to the syncErrors channel. Filters received from the filter channel are applied
+// before the next sync, see MainChain.ApplyFilter.
 func SyncMain(
 	ctx context.Context,
 	caller *contract.Caller,
 	mainChain *MainChain,
 	mainChains chan<- *MainChain,
+	filter <-chan MainChainFilter,
 ) error {
 	headers := make(chan *types.Header)
 	sub, err := caller.Ethclient.SubscribeNewHead(ctx, headers)
@@ -451,6 +538,8 @@ func SyncMain(
 		case <-ctx.Done():
 			sub.Unsubscribe()
 			return ctx.Err()
+		case f := <-filter:
+			mainChain = mainChain.ApplyFilter(f)
 		case <-headers:
 			newMainChain, err := mainChain.SyncToHead(ctx, caller)
 			if err != nil {
@@ -0,0 +1,65 @@
+package observe
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+)
+
+func TestDecryptTransactionsVersion(t *testing.T) {
+	eonSecretKey := big.NewInt(1111)
+	eonPublicKey := (*shcrypto.EonPublicKey)(new(bn256.G2).ScalarBaseMult(eonSecretKey))
+	epochID := shcrypto.ComputeEpochID(3)
+	key := (*shcrypto.EpochSecretKey)(new(bn256.G1).ScalarMult((*bn256.G1)(epochID), eonSecretKey))
+
+	batch := &Batch{BatchIndex: 3}
+	for _, version := range []uint8{shcrypto.LegacyVersion, shcrypto.Version1} {
+		m, err := shcrypto.EncryptWithVersion(version, []byte{version}, eonPublicKey, epochID, rand.Reader)
+		assert.NilError(t, err)
+		batch.EncryptedTransactions = append(batch.EncryptedTransactions, m.Marshal())
+	}
+
+	assert.DeepEqual(t, batch.DecryptTransactions(key, 0, epochID, shcrypto.LegacyVersion), [][]byte{{shcrypto.LegacyVersion}})
+	assert.DeepEqual(t, batch.DecryptTransactions(key, 0, epochID, shcrypto.Version1), [][]byte{{shcrypto.Version1}})
+}
+
+func TestDecryptTransactionsEnvelope(t *testing.T) {
+	eonSecretKey := big.NewInt(1111)
+	eonPublicKey := (*shcrypto.EonPublicKey)(new(bn256.G2).ScalarBaseMult(eonSecretKey))
+	epochID := shcrypto.ComputeEpochID(3)
+	key := (*shcrypto.EpochSecretKey)(new(bn256.G1).ScalarMult((*bn256.G1)(epochID), eonSecretKey))
+
+	batch := &Batch{BatchIndex: 3}
+	for _, eonStartBatchIndex := range []uint64{0, 2} {
+		m, err := shcrypto.EncryptWithVersion(shcrypto.Version1, []byte{byte(eonStartBatchIndex)}, eonPublicKey, epochID, rand.Reader)
+		assert.NilError(t, err)
+		e := &shcrypto.Envelope{EonStartBatchIndex: eonStartBatchIndex, EpochID: epochID, Message: m}
+		batch.EncryptedTransactions = append(batch.EncryptedTransactions, e.Marshal())
+	}
+
+	assert.DeepEqual(t, batch.DecryptTransactions(key, 2, epochID, shcrypto.Version1), [][]byte{{2}})
+	assert.Equal(t, len(batch.DecryptTransactions(key, 2, shcrypto.ComputeEpochID(4), shcrypto.Version1)), 0)
+}
+
+func TestMainChainApplyFilter(t *testing.T) {
+	mainChain := NewMainChain(0)
+	for batchIndex := uint64(0); batchIndex < 5; batchIndex++ {
+		mainChain.Batches[batchIndex] = &Batch{BatchIndex: batchIndex}
+		mainChain.CipherExecutionReceipts[2*batchIndex] = &contract.CipherExecutionReceipt{HalfStep: 2 * batchIndex}
+	}
+
+	filtered := mainChain.ApplyFilter(MainChainFilter{BatchIndex: 3})
+	assert.Equal(t, len(filtered.Batches), 2)
+	assert.Assert(t, filtered.Batches[3] != nil && filtered.Batches[4] != nil)
+	assert.Equal(t, len(filtered.CipherExecutionReceipts), 5)
+	assert.Equal(t, len(mainChain.Batches), 5)
+
+	assert.Equal(t, filtered.ApplyFilter(MainChainFilter{BatchIndex: 2}), filtered)
+	assert.Equal(t, len(filtered.ApplyFilter(MainChainFilter{BatchIndex: 5}).Batches), 0)
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	pkgErrors "github.com/pkg/errors"
@@ -53,6 +54,15 @@ func (epk *EncryptionPublicKey) GobDecode(data []byte) error {
 	return nil
 }
 
+// MarshalText encodes the key as hex, so that it can be encoded as JSON.
+func (epk *EncryptionPublicKey) MarshalText() ([]byte, error) {
+	data, err := epk.GobEncode()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hexutil.Encode(data)), nil
+}
+
 // Encrypt the given message m.
 func (epk *EncryptionPublicKey) Encrypt(rand io.Reader, m []byte) ([]byte, error) {
 	return ecies.Encrypt(rand, (*ecies.PublicKey)(epk), m, nil, nil)
@@ -63,10 +73,13 @@ func (epk *EncryptionPublicKey) Encrypt(rand io.Reader, m []byte) ([]byte, error
 type ShutterFilter struct {
 	SyncHeight int64
 	BatchIndex uint64
+	Eon        uint64 // earlier eons are dropped
 }
 
 func (filter ShutterFilter) NeedsUpdate(newFilter ShutterFilter) bool {
-	return newFilter.SyncHeight > filter.SyncHeight || newFilter.BatchIndex > filter.BatchIndex
+	return newFilter.SyncHeight > filter.SyncHeight ||
+		newFilter.BatchIndex > filter.BatchIndex ||
+		newFilter.Eon > filter.Eon
 }
 
 // Shutter let's a keyper fetch all necessary information from a shuttermint node. The only source
@@ -81,6 +94,17 @@ type Shutter struct {
 	Batches              map[uint64]*BatchData
 	Eons                 []Eon
 	Filter               ShutterFilter
+	RejectedEvents       map[common.Address]uint64 // number of rejected events by sender
+	DirectMessages       []shutterevents.DirectMessage
+	HaltResumeVotes      []shutterevents.HaltResumeVote
+	Usage                *Usage // nil until a transaction has been synced
+
+	// Capabilities holds the latest capability announcement of each keyper.
+	Capabilities map[common.Address]shutterevents.Capabilities
+	// DecryptionApprovals are the approvals to decrypt past epochs of the eons we still have.
+	DecryptionApprovals []shutterevents.DecryptionApproval
+
+	seen *seenMessages // DKG messages received so far, see seenMessages
 }
 
 // NewShutter creates an empty Shutter struct.
@@ -89,6 +113,8 @@ func NewShutter() *Shutter {
 		CurrentBlock:         -1,
 		KeyperEncryptionKeys: make(map[common.Address]*EncryptionPublicKey),
 		Batches:              make(map[uint64]*BatchData),
+		RejectedEvents:       make(map[common.Address]uint64),
+		Capabilities:         make(map[common.Address]shutterevents.Capabilities),
 	}
 }
 
@@ -101,6 +127,7 @@ type Eon struct {
 	Accusations          []shutterevents.Accusation
 	Apologies            []shutterevents.Apology
 	EpochSecretKeyShares []shutterevents.EpochSecretKeyShare
+	Archived             bool // events have been moved to an EonArchive
 }
 
 func (eon *Eon) ApplyFilter(syncHeight int64) *Eon {
@@ -108,6 +135,7 @@ func (eon *Eon) ApplyFilter(syncHeight int64) *Eon {
 		Eon:         eon.Eon,
 		StartHeight: eon.StartHeight,
 		StartEvent:  eon.StartEvent,
+		Archived:    eon.Archived,
 	}
 	clone.Commitments = append(clone.Commitments, eon.GetPolyCommitments(syncHeight)...)
 	clone.PolyEvals = append(clone.PolyEvals, eon.GetPolyEvals(syncHeight)...)
@@ -176,9 +204,23 @@ func (eon *Eon) GetEpochSecretKeyShares(syncHeight int64) []shutterevents.EpochS
 	return slice[idx:]
 }
 
+// GetDirectMessages returns the direct messages received at or after the given height.
+func (shutter *Shutter) GetDirectMessages(syncHeight int64) []shutterevents.DirectMessage {
+	slice := shutter.DirectMessages
+	idx := sort.Search(len(slice),
+		func(i int) bool {
+			return slice[i].Height >= syncHeight
+		})
+	if idx == len(slice) {
+		return nil
+	}
+	return slice[idx:]
+}
+
 type BatchData struct {
 	BatchIndex           uint64
 	DecryptionSignatures []shutterevents.DecryptionSignature
+	SkipCipherVotes      []shutterevents.SkipCipherVote
 }
 
 // filterSyncHeight removes events from shutter.Eons that were generated at a height below the
@@ -190,6 +232,7 @@ func (shutter *Shutter) filterSyncHeight() {
 		newEons[i] = *shutter.Eons[i].ApplyFilter(syncHeight)
 	}
 	shutter.Eons = newEons
+	shutter.DirectMessages = append([]shutterevents.DirectMessage(nil), shutter.GetDirectMessages(syncHeight)...)
 }
 
 func (shutter *Shutter) filterBatchIndex() {
@@ -203,6 +246,26 @@ func (shutter *Shutter) filterBatchIndex() {
 	shutter.Batches = newBatches
 }
 
+// filterEon removes the eons before the Filter's Eon from shutter.Eons and forgets the messages
+// received for them, so that the record of seen messages doesn't keep growing either.
+func (shutter *Shutter) filterEon() {
+	idx := shutter.searchEon(shutter.Filter.Eon)
+	if shutter.seen != nil {
+		for _, eon := range shutter.Eons[:idx] {
+			shutter.seen.forgetEon(eon.Eon)
+		}
+	}
+	shutter.Eons = shutter.Eons[idx:]
+
+	var approvals []shutterevents.DecryptionApproval
+	for _, e := range shutter.DecryptionApprovals {
+		if e.Eon >= shutter.Filter.Eon {
+			approvals = append(approvals, e)
+		}
+	}
+	shutter.DecryptionApprovals = approvals
+}
+
 // ApplyFilter applies the given filter and returns a new shutter object with the filter applied.
 func (shutter *Shutter) ApplyFilter(newFilter ShutterFilter) *Shutter {
 	if !shutter.Filter.NeedsUpdate(newFilter) {
@@ -212,6 +275,7 @@ func (shutter *Shutter) ApplyFilter(newFilter ShutterFilter) *Shutter {
 	clone.Filter = newFilter
 	clone.filterSyncHeight()
 	clone.filterBatchIndex()
+	clone.filterEon()
 	return &clone
 }
 
@@ -220,9 +284,13 @@ func (shutter *Shutter) applyTxEvents(height int64, events []abcitypes.Event) {
 		x, err := shutterevents.MakeEvent(ev, height)
 		if err != nil {
 			log.Printf("Error: malformed event: %+v ev=%+v", err, ev)
-		} else {
-			shutter.applyEvent(x)
+			continue
 		}
+		if err := shutter.validateEvent(x); err != nil {
+			shutter.reject(x, err)
+			continue
+		}
+		shutter.applyEvent(x)
 	}
 }
 
@@ -266,7 +334,13 @@ func (shutter *Shutter) applyCheckIn(e shutterevents.CheckIn) error { //nolint:u
 	return nil
 }
 
-func (shutter *Shutter) applyBatchConfig(e shutterevents.BatchConfig) error { //nolint:unparam
+func (shutter *Shutter) applyBatchConfig(e shutterevents.BatchConfig) error {
+	// shuttermint validates the epoch mapping as well, but we must never use an epoch twice
+	if n := len(shutter.BatchConfigs); n > 0 {
+		if err := e.EnsureEpochsFollow(&shutter.BatchConfigs[n-1]); err != nil {
+			return err
+		}
+	}
 	shutter.BatchConfigs = append(shutter.BatchConfigs, e)
 	return nil
 }
@@ -277,6 +351,30 @@ func (shutter *Shutter) applyDecryptionSignature(e shutterevents.DecryptionSigna
 	return nil
 }
 
+func (shutter *Shutter) applySkipCipherVote(e shutterevents.SkipCipherVote) error { //nolint:unparam
+	b := shutter.getBatchData(e.BatchIndex)
+	b.SkipCipherVotes = append(b.SkipCipherVotes, e)
+	return nil
+}
+
+func (shutter *Shutter) applyHaltResumeVote(e shutterevents.HaltResumeVote) error { //nolint:unparam
+	shutter.HaltResumeVotes = append(shutter.HaltResumeVotes, e)
+	return nil
+}
+
+func (shutter *Shutter) applyDecryptionApproval(e shutterevents.DecryptionApproval) error { //nolint:unparam
+	shutter.DecryptionApprovals = append(shutter.DecryptionApprovals, e)
+	return nil
+}
+
+func (shutter *Shutter) applyCapabilities(e shutterevents.Capabilities) error { //nolint:unparam
+	if shutter.Capabilities == nil {
+		shutter.Capabilities = make(map[common.Address]shutterevents.Capabilities)
+	}
+	shutter.Capabilities[e.Sender] = e
+	return nil
+}
+
 func (shutter *Shutter) applyEonStarted(e shutterevents.EonStarted) error {
 	idx := shutter.searchEon(e.Eon)
 	if idx < len(shutter.Eons) {
@@ -331,6 +429,11 @@ func (shutter *Shutter) applyEpochSecretKeyShare(e shutterevents.EpochSecretKeyS
 	return nil
 }
 
+func (shutter *Shutter) applyDirectMessage(e shutterevents.DirectMessage) error {
+	shutter.DirectMessages = append(shutter.DirectMessages, e)
+	return nil
+}
+
 func (shutter *Shutter) applyEvent(ev shutterevents.IEvent) {
 	var err error
 	switch e := ev.(type) {
@@ -352,6 +455,16 @@ func (shutter *Shutter) applyEvent(ev shutterevents.IEvent) {
 		err = shutter.applyApology(*e)
 	case *shutterevents.EpochSecretKeyShare:
 		err = shutter.applyEpochSecretKeyShare(*e)
+	case *shutterevents.DirectMessage:
+		err = shutter.applyDirectMessage(*e)
+	case *shutterevents.SkipCipherVote:
+		err = shutter.applySkipCipherVote(*e)
+	case *shutterevents.HaltResumeVote:
+		err = shutter.applyHaltResumeVote(*e)
+	case *shutterevents.Capabilities:
+		err = shutter.applyCapabilities(*e)
+	case *shutterevents.DecryptionApproval:
+		err = shutter.applyDecryptionApproval(*e)
 	default:
 		err = pkgErrors.Errorf("not yet implemented for %s", reflect.TypeOf(ev))
 	}
@@ -364,21 +477,52 @@ func (shutter *Shutter) fetchAndApplyEvents(ctx context.Context, shmcl client.Cl
 	if targetHeight < shutter.CurrentBlock {
 		panic("internal error: fetchAndApplyEvents bad arguments")
 	}
+	if shutter.seen != nil {
+		shutter.seen.rollback(shutter.CurrentBlock)
+	}
 	cloned := false
+	err := FetchTxs(ctx, shmcl, shutter.CurrentBlock+1, targetHeight, func(tx *rpctypes.ResultTx) {
+		if !cloned {
+			shutter = shutter.Clone()
+			cloned = true
+		}
+		shutter.recordUsage(tx.Height, tx.Tx)
+		shutter.applyTxEvents(tx.Height, tx.TxResult.GetEvents())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !cloned {
+		return shutter.ShallowClone(), nil
+	}
+	return shutter, nil
+}
+
+// TxFetcher is implemented by shuttermint clients that fetch transactions on their own, e.g.
+// from a cache.
+type TxFetcher interface {
+	FetchTxs(ctx context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx)) error
+}
 
-	currentBlock := shutter.CurrentBlock
+// FetchTxs fetches the shuttermint transactions in the given range of heights and calls handler
+// for each of them in order. If shmcl is a TxFetcher, it fetches them itself.
+func FetchTxs(ctx context.Context, shmcl client.Client, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx)) error {
+	if fetcher, ok := shmcl.(TxFetcher); ok {
+		return fetcher.FetchTxs(ctx, fromHeight, toHeight, handler)
+	}
+	currentBlock := fromHeight - 1
 	const perQuery = 500
-	logProgress := shutter.CurrentBlock+perQuery < targetHeight
+	logProgress := currentBlock+perQuery < toHeight
 
-	for {
+	for currentBlock < toHeight {
 		height := currentBlock + perQuery
-		if height > targetHeight {
-			height = targetHeight
+		if height > toHeight {
+			height = toHeight
 		}
 
 		query := fmt.Sprintf("tx.height >= %d and tx.height <= %d", currentBlock+1, height)
 		if logProgress {
-			log.Printf("fetchAndApplyEvents: query=%s targetHeight=%d", query, targetHeight)
+			log.Printf("FetchTxs: query=%s targetHeight=%d", query, toHeight)
 		}
 
 		// tendermint silently caps the perPage value at 100, make sure to stay below, otherwise
@@ -390,41 +534,32 @@ func (shutter *Shutter) fetchAndApplyEvents(ctx context.Context, shmcl client.Cl
 		for {
 			res, err := shmcl.TxSearch(ctx, query, false, &page, &perPage, "")
 			if err != nil {
-				return nil, pkgErrors.Wrap(err, "failed to fetch shuttermint txs")
-			}
-			// Create a shallow or deep clone
-			if !cloned {
-				if res.TotalCount == 0 && height == targetHeight {
-					return shutter.ShallowClone(), nil
-				}
-				shutter = shutter.Clone()
-				cloned = true
+				return pkgErrors.Wrap(err, "failed to fetch shuttermint txs")
 			}
 
 			total += len(res.Txs)
 			for _, tx := range res.Txs {
-				events := tx.TxResult.GetEvents()
-				shutter.applyTxEvents(tx.Height, events)
+				if tx.Height <= currentBlock || tx.Height > height {
+					log.Printf("Warning: ignoring tx at height %d outside of queried range %s", tx.Height, query)
+					continue
+				}
+				handler(tx)
 			}
 			if page*perPage >= res.TotalCount {
 				if total != res.TotalCount {
 					log.Fatalf("internal error. got %d transactions, expected %d transactions from shuttermint for height %d..%d",
 						total,
 						res.TotalCount,
-						shutter.CurrentBlock+1,
-						targetHeight)
+						fromHeight,
+						toHeight)
 				}
 				break
 			}
 			page++
 		}
-		if height == targetHeight {
-			break
-		}
 		currentBlock = height
 	}
-
-	return shutter, nil
+	return nil
 }
 
 // IsCheckedIn checks if the given address sent it's check-in message.
@@ -461,6 +596,23 @@ func (shutter *Shutter) FindBatchConfigByBatchIndex(batchIndex uint64) shutterev
 	return shutterevents.BatchConfig{}
 }
 
+// FindBatchIndexByEpoch returns the batch whose key is generated in the given epoch according to
+// the epoch mapping of the batch configs. It returns false if the epoch is not used for any batch.
+func (shutter *Shutter) FindBatchIndexByEpoch(epoch uint64) (uint64, bool) {
+	for i := len(shutter.BatchConfigs) - 1; i >= 0; i-- {
+		bc := shutter.BatchConfigs[i]
+		batchIndex, ok := bc.BatchIndexForEpoch(epoch)
+		if !ok || batchIndex < bc.StartBatchIndex {
+			continue
+		}
+		if i+1 < len(shutter.BatchConfigs) && batchIndex >= shutter.BatchConfigs[i+1].StartBatchIndex {
+			continue
+		}
+		return batchIndex, true
+	}
+	return 0, false
+}
+
 func (shutter *Shutter) ShallowClone() *Shutter {
 	s := *shutter
 	return &s
@@ -469,6 +621,7 @@ func (shutter *Shutter) ShallowClone() *Shutter {
 func (shutter *Shutter) Clone() *Shutter {
 	clone := new(Shutter)
 	medley.CloneWithGob(shutter, clone)
+	clone.seen = shutter.seen
 	return clone
 }
 
@@ -526,6 +679,16 @@ func (shutter *Shutter) IsSynced() bool {
 	return shutter.NodeStatus == nil || !shutter.NodeStatus.SyncInfo.CatchingUp
 }
 
+// BlocksBehind returns the number of blocks the node we're connected to has, but we haven't
+// synced yet. Note that this doesn't include the blocks the node itself is missing if it is still
+// catching up.
+func (shutter *Shutter) BlocksBehind() uint64 {
+	if shutter.NodeStatus == nil || shutter.NodeStatus.SyncInfo.LatestBlockHeight <= shutter.CurrentBlock {
+		return 0
+	}
+	return uint64(shutter.NodeStatus.SyncInfo.LatestBlockHeight - shutter.CurrentBlock)
+}
+
 // SyncShutter subscribes to new blocks and syncs the shutter object with the head block in a
 // loop. It writes newly synced shutter objects to the shutters channel, as well as errors to the
 // syncErrors channel.
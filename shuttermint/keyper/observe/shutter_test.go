@@ -8,6 +8,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	gocmp "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"gotest.tools/v3/assert"
 
 	"github.com/shutter-network/shutter/shlib/shtest"
@@ -32,7 +33,8 @@ func TestGobSerializationIssue45(t *testing.T) {
 	sh := NewShutter()
 	epk := encryptionPublicKey(t)
 	sh.KeyperEncryptionKeys[common.Address{}] = epk
-	shtest.EnsureGobable(t, sh, new(Shutter), shtest.BigIntComparer, encryptionPublicKeyComparer)
+	shtest.EnsureGobable(t, sh, new(Shutter),
+		shtest.BigIntComparer, encryptionPublicKeyComparer, cmpopts.IgnoreUnexported(Shutter{}))
 }
 
 func TestFindBatchConfigByBatchIndex(t *testing.T) {
@@ -56,3 +58,59 @@ func TestFindBatchConfigByBatchIndex(t *testing.T) {
 	assert.Equal(t, int64(2), sh.FindBatchConfigByBatchIndex(10).Height)
 	assert.Equal(t, int64(2), sh.FindBatchConfigByBatchIndex(11).Height)
 }
+
+func TestFindBatchIndexByEpoch(t *testing.T) {
+	sh := NewShutter()
+	assert.NilError(t, sh.applyBatchConfig(shutterevents.BatchConfig{StartBatchIndex: 0}))
+	assert.NilError(t, sh.applyBatchConfig(shutterevents.BatchConfig{
+		StartBatchIndex: 10,
+		EpochOffset:     100,
+		EpochStride:     2,
+	}))
+	// a config reusing epochs of earlier batches is rejected
+	assert.ErrorContains(t, sh.applyBatchConfig(shutterevents.BatchConfig{StartBatchIndex: 20}), "not greater")
+	assert.Equal(t, len(sh.BatchConfigs), 2)
+
+	batchIndex, ok := sh.FindBatchIndexByEpoch(9)
+	assert.Assert(t, ok)
+	assert.Equal(t, batchIndex, uint64(9))
+	_, ok = sh.FindBatchIndexByEpoch(10)
+	assert.Assert(t, !ok)
+	_, ok = sh.FindBatchIndexByEpoch(101)
+	assert.Assert(t, !ok)
+	batchIndex, ok = sh.FindBatchIndexByEpoch(120)
+	assert.Assert(t, ok)
+	assert.Equal(t, batchIndex, uint64(10))
+}
+
+func TestApplyFilterEon(t *testing.T) {
+	keyper1 := common.BigToAddress(common.Big1)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{
+		Keypers:   []common.Address{keyper1},
+		Threshold: 1,
+	})
+	for eon := uint64(1); eon <= 3; eon++ {
+		assert.NilError(t, sh.applyEonStarted(shutterevents.EonStarted{Eon: eon, BatchIndex: 10 * eon}))
+		accusation := &shutterevents.Accusation{Height: int64(eon), Sender: keyper1, Eon: eon}
+		assert.NilError(t, sh.validateEvent(accusation))
+		sh.applyEvent(accusation)
+		sh.applyEvent(&shutterevents.DecryptionApproval{Height: int64(eon), Sender: keyper1, Eon: eon, Epoch: 5})
+	}
+
+	filtered := sh.ApplyFilter(ShutterFilter{Eon: 2})
+	assert.Equal(t, len(filtered.Eons), 2)
+	assert.Equal(t, filtered.Eons[0].Eon, uint64(2))
+	assert.Equal(t, len(sh.Eons), 3)
+	_, err := filtered.FindEon(1)
+	assert.Assert(t, err != nil)
+	assert.Equal(t, len(filtered.DecryptionApprovals), 2)
+	assert.Equal(t, filtered.DecryptionApprovals[0].Eon, uint64(2))
+	assert.Equal(t, len(sh.DecryptionApprovals), 3)
+
+	// the messages of dropped eons are forgotten, the ones of the others are not
+	assert.Equal(t, len(sh.seen.eons), 2)
+	assert.ErrorContains(t, filtered.validateEvent(&shutterevents.Accusation{Sender: keyper1, Eon: 2}), "duplicate")
+
+	assert.Equal(t, filtered.ApplyFilter(ShutterFilter{Eon: 1}), filtered)
+}
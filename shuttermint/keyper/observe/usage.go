@@ -0,0 +1,94 @@
+package observe
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// UsageWindow is the number of most recent shuttermint blocks Usage.Recent accounts for.
+const UsageWindow = 1000
+
+// MessageUsage counts the shuttermint transactions of a sender and their size in bytes.
+type MessageUsage struct {
+	Messages uint64
+	Bytes    uint64
+}
+
+func (u *MessageUsage) add(other MessageUsage) {
+	u.Messages += other.Messages
+	u.Bytes += other.Bytes
+}
+
+// BlockUsage is the usage per sender in a single shuttermint block.
+type BlockUsage struct {
+	Height  int64
+	Senders map[common.Address]MessageUsage
+}
+
+// Usage accounts the transactions in shuttermint blocks per sender, i.e. per signer of the
+// message, including transactions the app has rejected. Transactions that aren't signed messages
+// are accounted to the zero address.
+type Usage struct {
+	Total  map[common.Address]MessageUsage // in all blocks synced so far
+	Blocks []BlockUsage                    // blocks with transactions within the last UsageWindow blocks, in order
+}
+
+// NewUsage creates an empty Usage.
+func NewUsage() *Usage {
+	return &Usage{Total: make(map[common.Address]MessageUsage)}
+}
+
+// add accounts a transaction of the given size in the block at the given height. Heights must
+// not decrease.
+func (u *Usage) add(height int64, sender common.Address, size int) {
+	tx := MessageUsage{Messages: 1, Bytes: uint64(size)}
+	total := u.Total[sender]
+	total.add(tx)
+	u.Total[sender] = total
+
+	if n := len(u.Blocks); n == 0 || u.Blocks[n-1].Height != height {
+		u.Blocks = append(u.Blocks, BlockUsage{Height: height, Senders: make(map[common.Address]MessageUsage)})
+	}
+	block := &u.Blocks[len(u.Blocks)-1]
+	recent := block.Senders[sender]
+	recent.add(tx)
+	block.Senders[sender] = recent
+
+	i := 0
+	for i < len(u.Blocks) && u.Blocks[i].Height <= height-UsageWindow {
+		i++
+	}
+	u.Blocks = u.Blocks[i:]
+}
+
+// Recent returns the usage per sender in the UsageWindow blocks up to the given height.
+func (u *Usage) Recent(height int64) map[common.Address]MessageUsage {
+	res := make(map[common.Address]MessageUsage)
+	if u == nil {
+		return res
+	}
+	for _, block := range u.Blocks {
+		if block.Height <= height-UsageWindow || block.Height > height {
+			continue
+		}
+		for sender, usage := range block.Senders {
+			r := res[sender]
+			r.add(usage)
+			res[sender] = r
+		}
+	}
+	return res
+}
+
+// recordUsage accounts the given transaction, see Usage.
+func (shutter *Shutter) recordUsage(height int64, tx []byte) {
+	if shutter.Usage == nil {
+		shutter.Usage = NewUsage()
+	}
+	sender, err := shmsg.GetSigner(tx)
+	if err != nil {
+		sender = common.Address{}
+	}
+	shutter.Usage.add(height, sender, len(tx))
+}
@@ -0,0 +1,60 @@
+package observe
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestUsage(t *testing.T) {
+	a := common.BigToAddress(common.Big1)
+	b := common.BigToAddress(common.Big2)
+	u := NewUsage()
+	u.add(10, a, 100)
+	u.add(10, a, 50)
+	u.add(10, b, 20)
+	u.add(500, b, 30)
+
+	assert.DeepEqual(t, u.Recent(500), map[common.Address]MessageUsage{
+		a: {Messages: 2, Bytes: 150},
+		b: {Messages: 2, Bytes: 50},
+	})
+	assert.DeepEqual(t, u.Recent(100), map[common.Address]MessageUsage{
+		a: {Messages: 2, Bytes: 150},
+		b: {Messages: 1, Bytes: 20},
+	})
+	assert.DeepEqual(t, u.Recent(10+UsageWindow), map[common.Address]MessageUsage{
+		b: {Messages: 1, Bytes: 30},
+	})
+
+	// blocks that have fallen out of the window are forgotten, the totals are kept
+	u.add(10+UsageWindow, a, 1)
+	assert.Equal(t, len(u.Blocks), 2)
+	assert.Equal(t, u.Blocks[0].Height, int64(500))
+	assert.DeepEqual(t, u.Total, map[common.Address]MessageUsage{
+		a: {Messages: 3, Bytes: 151},
+		b: {Messages: 2, Bytes: 50},
+	})
+
+	var none *Usage
+	assert.Equal(t, len(none.Recent(0)), 0)
+}
+
+func TestRecordUsage(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	tx, err := shmsg.SignMessage(&shmsg.MessageWithNonce{RandomNonce: 1}, key)
+	assert.NilError(t, err)
+
+	shutter := NewShutter()
+	shutter.recordUsage(1, tx)
+	shutter.recordUsage(1, []byte("garbage"))
+	assert.DeepEqual(t, shutter.Usage.Recent(1), map[common.Address]MessageUsage{
+		crypto.PubkeyToAddress(key.PublicKey): {Messages: 1, Bytes: uint64(len(tx))},
+		{}:                                    {Messages: 1, Bytes: 7},
+	})
+}
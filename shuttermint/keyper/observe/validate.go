@@ -0,0 +1,280 @@
+package observe
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	pkgErrors "github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// maxEncryptedEvalSize is the maximum size of an encrypted poly eval. The eval itself is at most
+// 32 bytes, ecies adds less than 128 bytes.
+const maxEncryptedEvalSize = 256
+
+// eventSender returns the sender of the given event if it has one.
+func eventSender(ev shutterevents.IEvent) (common.Address, bool) {
+	switch e := ev.(type) {
+	case *shutterevents.CheckIn:
+		return e.Sender, true
+	case *shutterevents.DecryptionSignature:
+		return e.Sender, true
+	case *shutterevents.PolyCommitment:
+		return e.Sender, true
+	case *shutterevents.PolyEval:
+		return e.Sender, true
+	case *shutterevents.Accusation:
+		return e.Sender, true
+	case *shutterevents.Apology:
+		return e.Sender, true
+	case *shutterevents.EpochSecretKeyShare:
+		return e.Sender, true
+	case *shutterevents.DirectMessage:
+		return e.Sender, true
+	case *shutterevents.SkipCipherVote:
+		return e.Sender, true
+	case *shutterevents.HaltResumeVote:
+		return e.Sender, true
+	case *shutterevents.Capabilities:
+		return e.Sender, true
+	case *shutterevents.DecryptionApproval:
+		return e.Sender, true
+	default:
+		return common.Address{}, false
+	}
+}
+
+// seenMessages records the DKG messages received so far, so that duplicates can be rejected. It is
+// shared by all Shutter objects derived from each other and neither cloned nor persisted, because
+// it holds an entry per pair of keypers. A Shutter object loaded from disk rebuilds it from the
+// events it still holds.
+type seenMessages struct {
+	mux  sync.Mutex
+	eons map[uint64]map[string]int64 // the height each message has been received at by eon
+}
+
+func newSeenMessages() *seenMessages {
+	return &seenMessages{eons: make(map[uint64]map[string]int64)}
+}
+
+// mark records that the given message has been received for the eon at the given height. It
+// fails if it has been received before.
+func (seen *seenMessages) mark(eon uint64, height int64, format string, args ...interface{}) error {
+	seen.mux.Lock()
+	defer seen.mux.Unlock()
+	key := fmt.Sprintf(format, args...)
+	messages, ok := seen.eons[eon]
+	if !ok {
+		messages = make(map[string]int64)
+		seen.eons[eon] = messages
+	}
+	if _, ok := messages[key]; ok {
+		return pkgErrors.Errorf("duplicate message %s", key)
+	}
+	messages[key] = height
+	return nil
+}
+
+// rollback forgets the messages received after the given height. It is used to discard the
+// messages of a sync attempt that failed, so that they are not rejected when fetched again.
+func (seen *seenMessages) rollback(height int64) {
+	seen.mux.Lock()
+	defer seen.mux.Unlock()
+	for _, messages := range seen.eons {
+		for key, h := range messages {
+			if h > height {
+				delete(messages, key)
+			}
+		}
+	}
+}
+
+// forgetEon forgets the messages received for the given eon.
+func (seen *seenMessages) forgetEon(eon uint64) {
+	seen.mux.Lock()
+	defer seen.mux.Unlock()
+	delete(seen.eons, eon)
+}
+
+// markEventsSeen records the messages the eon holds as received.
+func (seen *seenMessages) markEventsSeen(eon *Eon) {
+	for _, e := range eon.Commitments {
+		_ = seen.mark(eon.Eon, e.Height, "commitment %s", e.Sender.Hex())
+	}
+	for _, e := range eon.PolyEvals {
+		for _, r := range e.Receivers {
+			_ = seen.mark(eon.Eon, e.Height, "polyeval %s %s", e.Sender.Hex(), r.Hex())
+		}
+	}
+	for _, e := range eon.Accusations {
+		_ = seen.mark(eon.Eon, e.Height, "accusation %s", e.Sender.Hex())
+	}
+	for _, e := range eon.Apologies {
+		_ = seen.mark(eon.Eon, e.Height, "apology %s", e.Sender.Hex())
+	}
+}
+
+// seenMessages returns the messages received so far, rebuilding them from the eons if necessary.
+func (shutter *Shutter) seenMessages() *seenMessages {
+	if shutter.seen == nil {
+		shutter.seen = newSeenMessages()
+		for i := range shutter.Eons {
+			shutter.seen.markEventsSeen(&shutter.Eons[i])
+		}
+	}
+	return shutter.seen
+}
+
+// eonKeypers returns the eon with the given index and the committee of keypers taking part in its
+// DKG. It fails if the sender is not a member of the committee.
+func (shutter *Shutter) eonKeypers(eonIndex uint64, sender common.Address) (*Eon, shutterevents.BatchConfig, []common.Address, error) {
+	eon, err := shutter.FindEon(eonIndex)
+	if err != nil {
+		return nil, shutterevents.BatchConfig{}, nil, err
+	}
+	config := shutter.FindBatchConfigByBatchIndex(eon.StartEvent.BatchIndex)
+	committee := config.Committee(eon.StartEvent.Seed)
+	if !isMember(committee, sender) {
+		return nil, shutterevents.BatchConfig{}, nil, pkgErrors.Errorf("sender is not in the committee of eon %d", eonIndex)
+	}
+	return eon, config, committee, nil
+}
+
+func isMember(committee []common.Address, addr common.Address) bool {
+	for _, k := range committee {
+		if k == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func ensureKeypers(committee []common.Address, addresses []common.Address) error {
+	if len(addresses) > len(committee) {
+		return pkgErrors.Errorf("too many keypers referenced (%d > %d)", len(addresses), len(committee))
+	}
+	seen := make(map[common.Address]bool)
+	for _, a := range addresses {
+		if !isMember(committee, a) {
+			return pkgErrors.Errorf("%s is not in the committee", a.Hex())
+		}
+		if seen[a] {
+			return pkgErrors.Errorf("%s referenced twice", a.Hex())
+		}
+		seen[a] = true
+	}
+	return nil
+}
+
+// validateEvent checks that the event is plausible given the events received so far. It guards
+// puredkg and epochkg from impossible input that can only originate from misbehaving keypers or a
+// misbehaving shuttermint node. Events that pass are marked as seen, so that duplicates are
+// rejected later on.
+func (shutter *Shutter) validateEvent(ev shutterevents.IEvent) error {
+	switch e := ev.(type) {
+	case *shutterevents.PolyCommitment:
+		eon, config, _, err := shutter.eonKeypers(e.Eon, e.Sender)
+		if err != nil {
+			return err
+		}
+		if e.Gammas == nil || e.Gammas.Degree() != shcrypto.DegreeFromThreshold(config.Threshold) {
+			return pkgErrors.Errorf("invalid number of gammas")
+		}
+		return shutter.seenMessages().mark(eon.Eon, e.Height, "commitment %s", e.Sender.Hex())
+	case *shutterevents.PolyEval:
+		eon, _, committee, err := shutter.eonKeypers(e.Eon, e.Sender)
+		if err != nil {
+			return err
+		}
+		if len(e.Receivers) != len(e.EncryptedEvals) {
+			return pkgErrors.Errorf("%d receivers, but %d evals", len(e.Receivers), len(e.EncryptedEvals))
+		}
+		if err := ensureKeypers(committee, e.Receivers); err != nil {
+			return err
+		}
+		for _, encrypted := range e.EncryptedEvals {
+			if len(encrypted) > maxEncryptedEvalSize {
+				return pkgErrors.Errorf("encrypted eval too large (%d bytes)", len(encrypted))
+			}
+		}
+		for _, r := range e.Receivers {
+			if err := shutter.seenMessages().mark(eon.Eon, e.Height, "polyeval %s %s", e.Sender.Hex(), r.Hex()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *shutterevents.Accusation:
+		eon, _, committee, err := shutter.eonKeypers(e.Eon, e.Sender)
+		if err != nil {
+			return err
+		}
+		if err := ensureKeypers(committee, e.Accused); err != nil {
+			return err
+		}
+		return shutter.seenMessages().mark(eon.Eon, e.Height, "accusation %s", e.Sender.Hex())
+	case *shutterevents.Apology:
+		eon, _, committee, err := shutter.eonKeypers(e.Eon, e.Sender)
+		if err != nil {
+			return err
+		}
+		if len(e.Accusers) != len(e.PolyEval) {
+			return pkgErrors.Errorf("%d accusers, but %d evals", len(e.Accusers), len(e.PolyEval))
+		}
+		if err := ensureKeypers(committee, e.Accusers); err != nil {
+			return err
+		}
+		return shutter.seenMessages().mark(eon.Eon, e.Height, "apology %s", e.Sender.Hex())
+	case *shutterevents.EpochSecretKeyShare:
+		_, _, _, err := shutter.eonKeypers(e.Eon, e.Sender)
+		if err != nil {
+			return err
+		}
+		if e.Share == nil {
+			return pkgErrors.Errorf("missing share")
+		}
+		return nil
+	case *shutterevents.DirectMessage:
+		if !shutter.IsKeyper(e.Sender) || !shutter.IsKeyper(e.Receiver) {
+			return pkgErrors.Errorf("direct messages can only be sent between keypers")
+		}
+		return nil
+	case *shutterevents.SkipCipherVote:
+		bc := shutter.FindBatchConfigByBatchIndex(e.BatchIndex)
+		if !bc.IsKeyper(e.Sender) {
+			return pkgErrors.Errorf("sender is not a keyper of batch %d", e.BatchIndex)
+		}
+		return nil
+	case *shutterevents.HaltResumeVote:
+		if !shutter.IsKeyper(e.Sender) {
+			return pkgErrors.Errorf("sender is not a keyper")
+		}
+		return nil
+	case *shutterevents.Capabilities:
+		if !shutter.IsKeyper(e.Sender) {
+			return pkgErrors.Errorf("sender is not a keyper")
+		}
+		return nil
+	case *shutterevents.DecryptionApproval:
+		_, _, _, err := shutter.eonKeypers(e.Eon, e.Sender)
+		return err
+	default:
+		return nil
+	}
+}
+
+// reject counts and logs a rejected event.
+func (shutter *Shutter) reject(ev shutterevents.IEvent, err error) {
+	sender, _ := eventSender(ev)
+	if shutter.RejectedEvents == nil {
+		shutter.RejectedEvents = make(map[common.Address]uint64)
+	}
+	shutter.RejectedEvents[sender]++
+	log.Printf(
+		"Warning: rejected event from %s (%d rejected so far): %s, event: %+v",
+		sender.Hex(), shutter.RejectedEvents[sender], err, ev,
+	)
+}
@@ -0,0 +1,208 @@
+package observe
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/medley"
+)
+
+func TestValidateEvent(t *testing.T) {
+	keyper1 := common.BigToAddress(common.Big1)
+	keyper2 := common.BigToAddress(common.Big2)
+	outsider := common.BigToAddress(common.Big3)
+
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{
+		StartBatchIndex: 0,
+		Keypers:         []common.Address{keyper1, keyper2},
+		Threshold:       1,
+	})
+	assert.NilError(t, sh.applyEonStarted(shutterevents.EonStarted{Eon: 1}))
+
+	validEval := &shutterevents.PolyEval{
+		Sender:         keyper1,
+		Eon:            1,
+		Receivers:      []common.Address{keyper2},
+		EncryptedEvals: [][]byte{{1}},
+	}
+	assert.NilError(t, sh.validateEvent(validEval))
+	assert.ErrorContains(t, sh.validateEvent(validEval), "duplicate")
+
+	invalid := []shutterevents.IEvent{
+		&shutterevents.Accusation{Sender: keyper1, Eon: 2},
+		&shutterevents.Accusation{Sender: outsider, Eon: 1},
+		&shutterevents.Accusation{Sender: keyper1, Eon: 1, Accused: []common.Address{outsider}},
+		&shutterevents.Accusation{Sender: keyper1, Eon: 1, Accused: []common.Address{keyper2, keyper2}},
+		&shutterevents.PolyEval{
+			Sender:         keyper2,
+			Eon:            1,
+			Receivers:      []common.Address{keyper1},
+			EncryptedEvals: [][]byte{},
+		},
+		&shutterevents.PolyEval{
+			Sender:         keyper2,
+			Eon:            1,
+			Receivers:      []common.Address{keyper1},
+			EncryptedEvals: [][]byte{make([]byte, maxEncryptedEvalSize+1)},
+		},
+		&shutterevents.Apology{Sender: keyper1, Eon: 1, Accusers: []common.Address{keyper2}},
+		&shutterevents.PolyCommitment{Sender: keyper1, Eon: 1},
+	}
+	for _, ev := range invalid {
+		assert.Assert(t, sh.validateEvent(ev) != nil, "event %+v", ev)
+	}
+
+	assert.NilError(t, sh.validateEvent(&shutterevents.Accusation{
+		Sender:  keyper1,
+		Eon:     1,
+		Accused: []common.Address{keyper2},
+	}))
+}
+
+func TestRejectCountsBySender(t *testing.T) {
+	sh := NewShutter()
+	sender := common.BigToAddress(common.Big1)
+	ev := &shutterevents.Accusation{Sender: sender}
+	err := sh.validateEvent(ev)
+	assert.Assert(t, err != nil)
+	sh.reject(ev, err)
+	sh.reject(ev, err)
+	assert.Equal(t, uint64(2), sh.RejectedEvents[sender])
+}
+
+func TestValidateEventCommittee(t *testing.T) {
+	keypers := []common.Address{
+		common.BigToAddress(common.Big1),
+		common.BigToAddress(common.Big2),
+		common.BigToAddress(common.Big3),
+	}
+	config := shutterevents.BatchConfig{Keypers: keypers, Threshold: 2, CommitteeSize: 2}
+	seed := []byte("seed")
+	committee := config.Committee(seed)
+	var outsider common.Address
+	for _, k := range keypers {
+		if !isMember(committee, k) {
+			outsider = k
+		}
+	}
+
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, config)
+	assert.NilError(t, sh.applyEonStarted(shutterevents.EonStarted{Eon: 1, Seed: seed}))
+
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.Accusation{Sender: outsider, Eon: 1}), "committee")
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.Accusation{
+		Sender:  committee[0],
+		Eon:     1,
+		Accused: []common.Address{outsider},
+	}), "committee")
+
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.PolyCommitment{
+		Sender: committee[0],
+		Eon:    1,
+		Gammas: shcrypto.ZeroGammas(2),
+	}), "gammas")
+	assert.NilError(t, sh.validateEvent(&shutterevents.PolyCommitment{
+		Sender: committee[0],
+		Eon:    1,
+		Gammas: shcrypto.ZeroGammas(1),
+	}))
+}
+
+func TestSeenMessages(t *testing.T) {
+	keyper1 := common.BigToAddress(common.Big1)
+	keyper2 := common.BigToAddress(common.Big2)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{
+		Keypers:   []common.Address{keyper1, keyper2},
+		Threshold: 1,
+	})
+	assert.NilError(t, sh.applyEonStarted(shutterevents.EonStarted{Eon: 1}))
+
+	accusation := func(sender common.Address, height int64) *shutterevents.Accusation {
+		return &shutterevents.Accusation{Height: height, Sender: sender, Eon: 1, Accused: []common.Address{keyper2}}
+	}
+	assert.NilError(t, sh.validateEvent(accusation(keyper1, 5)))
+	sh.applyEvent(accusation(keyper1, 5))
+	sh.CurrentBlock = 5
+
+	clone := sh.Clone()
+	assert.ErrorContains(t, clone.validateEvent(accusation(keyper1, 6)), "duplicate")
+
+	// messages of a failed sync attempt are forgotten
+	assert.NilError(t, clone.validateEvent(accusation(keyper2, 6)))
+	sh.seen.rollback(sh.CurrentBlock)
+	assert.NilError(t, sh.validateEvent(accusation(keyper2, 6)))
+
+	// a Shutter object loaded from disk rebuilds the messages from its events
+	loaded := new(Shutter)
+	medley.CloneWithGob(sh, loaded)
+	assert.Assert(t, loaded.seen == nil)
+	assert.ErrorContains(t, loaded.validateEvent(accusation(keyper1, 7)), "duplicate")
+}
+
+func TestValidateDirectMessage(t *testing.T) {
+	keyper1 := common.BigToAddress(common.Big1)
+	keyper2 := common.BigToAddress(common.Big2)
+	outsider := common.BigToAddress(common.Big3)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{
+		Keypers:   []common.Address{keyper1, keyper2},
+		Threshold: 1,
+	})
+
+	assert.NilError(t, sh.validateEvent(&shutterevents.DirectMessage{Sender: keyper1, Receiver: keyper2}))
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.DirectMessage{Sender: outsider, Receiver: keyper2}), "keypers")
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.DirectMessage{Sender: keyper1, Receiver: outsider}), "keypers")
+}
+
+func TestValidateSkipCipherVote(t *testing.T) {
+	keyper1 := common.BigToAddress(common.Big1)
+	keyper2 := common.BigToAddress(common.Big2)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs,
+		shutterevents.BatchConfig{Keypers: []common.Address{keyper1}, Threshold: 1},
+		shutterevents.BatchConfig{StartBatchIndex: 10, Keypers: []common.Address{keyper2}, Threshold: 1},
+	)
+
+	assert.NilError(t, sh.validateEvent(&shutterevents.SkipCipherVote{Sender: keyper1, BatchIndex: 9}))
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.SkipCipherVote{Sender: keyper1, BatchIndex: 10}), "not a keyper")
+	assert.NilError(t, sh.validateEvent(&shutterevents.SkipCipherVote{Sender: keyper2, BatchIndex: 10}))
+}
+
+func TestValidateHaltResumeVote(t *testing.T) {
+	keyper := common.BigToAddress(common.Big1)
+	outsider := common.BigToAddress(common.Big2)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{Keypers: []common.Address{keyper}, Threshold: 1})
+
+	assert.NilError(t, sh.validateEvent(&shutterevents.HaltResumeVote{Sender: keyper, HaltHeight: 5}))
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.HaltResumeVote{Sender: outsider, HaltHeight: 5}), "not a keyper")
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	keyper := common.BigToAddress(common.Big1)
+	outsider := common.BigToAddress(common.Big2)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{Keypers: []common.Address{keyper}, Threshold: 1})
+
+	assert.NilError(t, sh.validateEvent(&shutterevents.Capabilities{Sender: keyper, Version: "v1"}))
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.Capabilities{Sender: outsider, Version: "v1"}), "not a keyper")
+}
+
+func TestValidateDecryptionApproval(t *testing.T) {
+	keyper := common.BigToAddress(common.Big1)
+	outsider := common.BigToAddress(common.Big2)
+	sh := NewShutter()
+	sh.BatchConfigs = append(sh.BatchConfigs, shutterevents.BatchConfig{Keypers: []common.Address{keyper}, Threshold: 1})
+	assert.NilError(t, sh.applyEonStarted(shutterevents.EonStarted{Eon: 1}))
+
+	assert.NilError(t, sh.validateEvent(&shutterevents.DecryptionApproval{Sender: keyper, Eon: 1, Epoch: 5}))
+	assert.ErrorContains(t, sh.validateEvent(&shutterevents.DecryptionApproval{Sender: outsider, Eon: 1, Epoch: 5}), "committee")
+	assert.Assert(t, sh.validateEvent(&shutterevents.DecryptionApproval{Sender: keyper, Eon: 2, Epoch: 5}) != nil)
+}
@@ -0,0 +1,71 @@
+// Package policy defines hooks operators can use to veto or delay the release of epoch keys and
+// the execution of batches, e.g. to never release keys for batches flagged by an external
+// allowlist service. Policies are plugged into the keyper by setting Keyper.Policy before running
+// it. The default policy allows everything.
+package policy
+
+import (
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// Decision is the outcome of a policy check.
+type Decision int
+
+const (
+	// Allow lets the keyper go ahead.
+	Allow Decision = iota
+	// Delay makes the keyper ask again in the next step.
+	Delay
+	// Veto makes the keyper refrain from the action for good.
+	Veto
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Delay:
+		return "delay"
+	case Veto:
+		return "veto"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyRelease describes the publication of our epoch secret key share for an epoch.
+type KeyRelease struct {
+	BatchIndex protocol.BatchIndex // the batch the epoch belongs to
+	Epoch      protocol.EpochIndex // the epoch, a main chain block number if per-block epochs are used
+	Eon        protocol.EonIndex
+	Namespaces []string // the additional namespaces shares are published for
+}
+
+// Execution describes the execution of a half step on the main chain.
+type Execution struct {
+	HalfStep   protocol.HalfStep
+	BatchIndex protocol.BatchIndex
+	Cipher     bool // true for the cipher half step, false for the plain one
+}
+
+// Policy decides whether the keyper may release keys and execute batches. The methods are called
+// from the decider and must return quickly, as they block the keyper's main loop.
+type Policy interface {
+	// ReleaseKey is called before our epoch secret key share is published. Releases happen in
+	// order, so delaying one holds back the later ones as well. A release may only be delayed
+	// until the batch's execution timeout (or, with per-block epochs, for a limited number of
+	// blocks), afterwards the share is not published anymore.
+	ReleaseKey(world observe.World, release KeyRelease) Decision
+	// Execute is called before we execute a half step. Skipping cipher batches after their
+	// execution timeout is not subject to the policy. Other keypers may still execute a half step
+	// we refrained from.
+	Execute(world observe.World, execution Execution) Decision
+}
+
+// Default is the default policy, which allows everything.
+type Default struct{}
+
+func (Default) ReleaseKey(observe.World, KeyRelease) Decision { return Allow }
+
+func (Default) Execute(observe.World, Execution) Decision { return Allow }
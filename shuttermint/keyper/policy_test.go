@@ -0,0 +1,164 @@
+package keyper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/latency"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/policy"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+// testPolicy vetoes everything except for delaying the release of one batch.
+type testPolicy struct {
+	delayedBatch uint64
+	releases     []uint64
+	executions   []uint64
+}
+
+func (p *testPolicy) ReleaseKey(_ observe.World, release policy.KeyRelease) policy.Decision {
+	p.releases = append(p.releases, uint64(release.BatchIndex))
+	if uint64(release.BatchIndex) == p.delayedBatch {
+		return policy.Delay
+	}
+	return policy.Veto
+}
+
+func (p *testPolicy) Execute(_ observe.World, execution policy.Execution) policy.Decision {
+	p.executions = append(p.executions, uint64(execution.HalfStep))
+	return policy.Veto
+}
+
+func policyTestDecider(t *testing.T, p policy.Policy) *Decider {
+	t.Helper()
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	config := Config{SigningKey: signingKey}
+	keypers := []common.Address{config.Address()}
+
+	mainChain := observe.NewMainChain(0)
+	mainChain.BatchConfigs = []contract.BatchConfig{
+		{},
+		{StartBatchIndex: 0, BatchSpan: 10, Keypers: keypers, Threshold: 1, ExecutionTimeout: 1000},
+	}
+	mainChain.CurrentBlock = 55
+	shutter := observe.NewShutter()
+	shutter.BatchConfigs = []shutterevents.BatchConfig{{ConfigIndex: 1, Keypers: keypers, Threshold: 1}}
+	shutter.Eons = []observe.Eon{{Eon: 1, StartEvent: shutterevents.EonStarted{Eon: 1}}}
+	state := NewState()
+	state.EKGs = []*EKG{{Eon: 1}}
+	dcdr := &Decider{Config: config, State: state, Shutter: shutter, MainChain: mainChain, Policy: p}
+	dcdr.updateEonActivations()
+	return dcdr
+}
+
+func TestKeyReleasePolicy(t *testing.T) {
+	p := &testPolicy{delayedBatch: 3}
+	dcdr := policyTestDecider(t, p)
+
+	dcdr.publishEpochSecretKeyShares()
+	assert.DeepEqual(t, p.releases, []uint64{0, 1, 2, 3})
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(3))
+	assert.Equal(t, len(dcdr.Actions), 0)
+
+	// the delayed batch is asked for again
+	p.releases = nil
+	dcdr.publishEpochSecretKeyShares()
+	assert.DeepEqual(t, p.releases, []uint64{3})
+}
+
+func TestExecutionPolicy(t *testing.T) {
+	dcdr := policyTestDecider(t, nil)
+	action := dcdr.maybeExecuteHalfStep(1)
+	_, ok := action.(*fx.ExecutePlainBatch)
+	assert.Assert(t, ok)
+
+	p := &testPolicy{}
+	dcdr.Policy = p
+	assert.Assert(t, dcdr.maybeExecuteHalfStep(1) == nil)
+	assert.DeepEqual(t, p.executions, []uint64{1})
+}
+
+func TestPausedReleases(t *testing.T) {
+	p := &testPolicy{delayedBatch: 3}
+	kpr := &Keyper{Policy: p}
+	handler := kpr.releasePauseHandler()
+	post := func(path string) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		assert.Equal(t, rec.Code, http.StatusOK)
+	}
+
+	post(releasesPausePath)
+	dcdr := policyTestDecider(t, kpr.policy())
+	dcdr.publishEpochSecretKeyShares()
+	assert.Assert(t, p.releases == nil, "the wrapped policy must not be asked while paused")
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(0))
+	assert.Equal(t, len(dcdr.Actions), 0)
+
+	// executions are still left to the wrapped policy
+	assert.Assert(t, dcdr.maybeExecuteHalfStep(1) == nil)
+	assert.DeepEqual(t, p.executions, []uint64{1})
+
+	post(releasesResumePath)
+	assert.Equal(t, kpr.policy(), policy.Policy(p))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, releasesPausePath, nil))
+	assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+}
+
+// singleKeyperEpochKG runs a DKG with a single keyper and returns its EpochKG.
+func singleKeyperEpochKG(t *testing.T, eon uint64) *epochkg.EpochKG {
+	t.Helper()
+	pure := puredkg.NewPureDKG(eon, 1, 1, 0)
+	commitment, _, err := pure.StartPhase1Dealing()
+	assert.NilError(t, err)
+	assert.NilError(t, pure.HandlePolyCommitmentMsg(commitment))
+	pure.StartPhase2Accusing()
+	pure.StartPhase3Apologizing()
+	pure.Finalize()
+	result, err := pure.ComputeResult()
+	assert.NilError(t, err)
+	return epochkg.NewEpochKG(&result)
+}
+
+// TestLatencyEpochMapping checks that the key release latency is measured for the epochs the
+// batches are mapped to, not for the batch indices.
+func TestLatencyEpochMapping(t *testing.T) {
+	dcdr := policyTestDecider(t, nil)
+	dcdr.Shutter.BatchConfigs[0].EpochOffset = 100
+	dcdr.Shutter.BatchConfigs[0].EpochStride = 3
+	ekg := dcdr.State.EKGs[0]
+	ekg.Keypers = dcdr.Shutter.BatchConfigs[0].Keypers
+	ekg.EpochKG = singleKeyperEpochKG(t, 1)
+	dcdr.Latency = latency.NewTracker(0)
+
+	dcdr.publishEpochSecretKeyShares()
+	assert.Equal(t, dcdr.State.NextEpochSecretShare, uint64(5))
+	assert.Equal(t, dcdr.Latency.Report().SharePublication.Count, uint64(5))
+
+	eon := &dcdr.Shutter.Eons[0]
+	for batchIndex := uint64(0); batchIndex < 5; batchIndex++ {
+		epoch := dcdr.Shutter.BatchConfigs[0].Epoch(batchIndex)
+		eon.EpochSecretKeyShares = append(eon.EpochSecretKeyShares, shutterevents.EpochSecretKeyShare{
+			Height: 1,
+			Sender: dcdr.Config.Address(),
+			Eon:    1,
+			Epoch:  epoch,
+			Share:  ekg.EpochKG.ComputeEpochSecretKeyShare(epoch),
+		})
+	}
+	dcdr.syncEKGWithEon(0, ekg, eon)
+	assert.Equal(t, dcdr.Latency.Report().KeyGeneration.Count, uint64(5))
+}
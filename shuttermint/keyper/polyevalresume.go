@@ -0,0 +1,79 @@
+package keyper
+
+import (
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// pendingPolyEvalReceivers returns the receivers of our poly evals for the given eon that are in
+// messages we've sent, but haven't seen in the chain yet.
+func (dcdr *Decider) pendingPolyEvalReceivers(eon uint64) map[common.Address]bool {
+	receivers := make(map[common.Address]bool)
+	for _, msg := range dcdr.PendingMessages {
+		polyEval := msg.GetPolyEval()
+		if polyEval == nil || polyEval.Eon != eon {
+			continue
+		}
+		for _, r := range polyEval.Receivers {
+			receivers[common.BytesToAddress(r)] = true
+		}
+	}
+	return receivers
+}
+
+// resumePolyEvals reconciles the outgoing poly evals of a DKG in the dealing phase with our poly
+// evals in the chain after a restart. Messages we've decided to send before can still be lost,
+// e.g. if we gave up on delivering them, so OutgoingPolyEvalMsgs alone doesn't tell us who's still
+// waiting for our eval. Receivers whose eval is in the chain or in a pending message are dropped
+// from the queue, and the evals of all other receivers are recomputed from our polynomial and
+// queued again. Sending exactly the missing receivers matters, since shuttermint rejects the whole
+// message if it contains a receiver we've already sent an eval to.
+func (dcdr *Decider) resumePolyEvals(dkg *DKG, eon observe.Eon) {
+	if dkg.Pure.Phase != puredkg.Dealing || dkg.Pure.Polynomial == nil {
+		return
+	}
+	pending := dcdr.pendingPolyEvalReceivers(dkg.Eon)
+	done := func(receiver common.Address) bool {
+		return pending[receiver] || dkg.deliveredPolyEval(eon, receiver)
+	}
+
+	queued := make(map[puredkg.KeyperIndex]bool)
+	var outgoing []puredkg.PolyEvalMsg
+	for _, p := range dkg.OutgoingPolyEvalMsgs {
+		if done(dkg.Keypers[p.Receiver]) {
+			shcrypto.ZeroInt(p.Eval)
+			continue
+		}
+		queued[p.Receiver] = true
+		outgoing = append(outgoing, p)
+	}
+	numDropped := len(dkg.OutgoingPolyEvalMsgs) - len(outgoing)
+
+	numResumed := 0
+	for i, receiver := range dkg.Keypers {
+		index := puredkg.KeyperIndex(i)
+		if index == dkg.Pure.Keyper || queued[index] || done(receiver) {
+			continue
+		}
+		outgoing = append(outgoing, puredkg.PolyEvalMsg{
+			Eon:      dkg.Eon,
+			Sender:   dkg.Pure.Keyper,
+			Receiver: index,
+			Eval:     dkg.Pure.Polynomial.EvalForKeyper(i),
+		})
+		numResumed++
+	}
+	dkg.OutgoingPolyEvalMsgs = outgoing
+
+	if numDropped > 0 || numResumed > 0 {
+		log.Printf(
+			"Resuming poly evals for eon %d: %d already sent, %d lost and queued again, %d outgoing",
+			dkg.Eon, numDropped, numResumed, len(outgoing),
+		)
+	}
+}
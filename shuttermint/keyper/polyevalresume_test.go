@@ -0,0 +1,68 @@
+package keyper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestResumePolyEvals(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	config := Config{SigningKey: signingKey}
+	keypers := []common.Address{config.Address()}
+	for i := int64(1); i < 5; i++ {
+		keypers = append(keypers, common.BigToAddress(big.NewInt(i)))
+	}
+
+	pure := puredkg.NewPureDKG(1, uint64(len(keypers)), 3, 0)
+	_, polyEvals, err := pure.StartPhase1Dealing()
+	assert.NilError(t, err)
+	dkg := DKG{
+		Eon:         1,
+		Keypers:     keypers,
+		Pure:        &pure,
+		PhaseLength: NewConstantPhaseLength(10),
+		// the eval for keyper 4 is still waiting for its encryption key
+		OutgoingPolyEvalMsgs: []puredkg.PolyEvalMsg{polyEvals[3]},
+	}
+	eon := observe.Eon{
+		Eon:         1,
+		StartHeight: 100,
+		PolyEvals: []shutterevents.PolyEval{
+			{Height: 101, Sender: keypers[0], Eon: 1, Receivers: []common.Address{keypers[1]}},
+			{Height: 101, Sender: keypers[2], Eon: 1, Receivers: []common.Address{keypers[3]}},
+		},
+	}
+	dcdr := Decider{
+		Config:  config,
+		Shutter: observe.NewShutter(),
+		PendingMessages: []*shmsg.Message{
+			shmsg.NewPolyEval(1, []common.Address{keypers[2]}, [][]byte{{}}),
+			shmsg.NewPolyEval(2, []common.Address{keypers[3]}, [][]byte{{}}),
+		},
+	}
+
+	// keyper 1 has our eval, keyper 2 will get it, the one for keyper 3 got lost
+	dcdr.resumePolyEvals(&dkg, eon)
+	var receivers []puredkg.KeyperIndex
+	for _, p := range dkg.OutgoingPolyEvalMsgs {
+		receivers = append(receivers, p.Receiver)
+		assert.Assert(t, p.Eval.Cmp(pure.Polynomial.EvalForKeyper(int(p.Receiver))) == 0)
+	}
+	assert.DeepEqual(t, receivers, []puredkg.KeyperIndex{4, 3})
+
+	// nothing to resume once the dealing phase is over
+	dkg.OutgoingPolyEvalMsgs = nil
+	pure.Phase = puredkg.Accusing
+	dcdr.resumePolyEvals(&dkg, eon)
+	assert.Equal(t, len(dkg.OutgoingPolyEvalMsgs), 0)
+}
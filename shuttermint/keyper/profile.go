@@ -0,0 +1,160 @@
+package keyper
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// ConfigProfile is a set of defaults for a kind of deployment, selected with the Profile config
+// key. Its defaults take precedence over ConfigDefaults, but not over values set in the config
+// file, the environment, or flags. Independent of how a value has been set, configs using the
+// profile must pass its interlocks, see CheckProfile and CheckChain.
+type ConfigProfile struct {
+	Defaults         map[string]interface{}
+	MinConfirmations uint64 // required main chain confirmations of any event the keyper acts on
+	RealFunds        bool   // false if the profile must not be used on chains with real funds
+}
+
+// ConfigProfiles are the profiles baked into the binary. "mainnet" is conservative and meant for
+// chains with real funds, "testnet" for public test networks, and "dev" for local test setups.
+var ConfigProfiles = map[string]ConfigProfile{
+	"mainnet": {
+		Defaults: map[string]interface{}{
+			"MainChainFollowDistance": 12,
+			"RequireKnownContracts":   true,
+			"APIAccess":               "closed",
+			"WatchKeyLeaks":           true,
+			"MaxBlocksBehind":         10,
+			"SyncTolerance":           2,
+		},
+		MinConfirmations: 6,
+		RealFunds:        true,
+	},
+	"testnet": {
+		Defaults: map[string]interface{}{
+			"MainChainFollowDistance": 3,
+		},
+		MinConfirmations: 1,
+	},
+	"dev": {
+		Defaults: map[string]interface{}{
+			"MainChainFollowDistance": 0,
+			"DKGPhaseLength":          30,
+			"ExecutionStaggering":     5,
+			"ContractCacheTTL":        "0s",
+			"MaxBlocksBehind":         0,
+		},
+	},
+}
+
+// realFundsChains are the main chains the keyper is known to be used on with real funds.
+var realFundsChains = map[uint64]string{
+	1:   "Ethereum mainnet",
+	100: "Gnosis Chain",
+}
+
+// ProfileNames returns the names of the config profiles in alphabetical order.
+func ProfileNames() []string {
+	var names []string
+	for name := range ConfigProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupProfile returns the profile with the given name. The empty name selects no profile.
+func lookupProfile(name string) (ConfigProfile, bool, error) {
+	if name == "" {
+		return ConfigProfile{}, false, nil
+	}
+	profile, ok := ConfigProfiles[name]
+	if !ok {
+		return ConfigProfile{}, false, errors.Errorf(
+			"unknown Profile %q, must be one of %s", name, strings.Join(ProfileNames(), ", "),
+		)
+	}
+	return profile, true, nil
+}
+
+// ApplyProfileDefaults sets the defaults of the profile selected in the given Viper object. It
+// must be called after SetConfigDefaults and after reading the config file.
+func ApplyProfileDefaults(v *viper.Viper) error {
+	profile, _, err := lookupProfile(v.GetString("Profile"))
+	if err != nil {
+		return err
+	}
+	for key, value := range profile.Defaults {
+		v.SetDefault(key, value)
+	}
+	return nil
+}
+
+type confirmationSetting struct {
+	key string
+	n   uint64
+}
+
+// effectiveConfirmations returns the number of confirmations the keyper waits for before acting on
+// each kind of main chain event. Events always have at least MainChainFollowDistance.
+func (config *Config) effectiveConfirmations() []confirmationSetting {
+	settings := []confirmationSetting{
+		{"BatchClosureConfirmations", config.BatchClosureConfirmations},
+		{"ConfigChangeConfirmations", config.ConfigChangeConfirmations},
+		{"AccusationConfirmations", config.AccusationConfirmations},
+	}
+	for i := range settings {
+		if settings[i].n < config.MainChainFollowDistance {
+			settings[i].n = config.MainChainFollowDistance
+		}
+	}
+	return settings
+}
+
+// CheckProfile checks the config against the interlocks of its profile that don't depend on the
+// chain.
+func (config *Config) CheckProfile() error {
+	profile, ok, err := lookupProfile(config.Profile)
+	if err != nil || !ok {
+		return err
+	}
+	for _, c := range config.effectiveConfirmations() {
+		if c.n < profile.MinConfirmations {
+			return errors.Errorf(
+				"refusing to wait for only %d confirmations (%s) with Profile %q, it requires at least %d",
+				c.n, c.key, config.Profile, profile.MinConfirmations,
+			)
+		}
+	}
+	return nil
+}
+
+// CheckChain checks that the config can be used on the main chain with the given id. On chains
+// with real funds, it refuses profiles not meant for them and acting on unconfirmed events,
+// whether or not a profile is selected.
+func (config *Config) CheckChain(chainID *big.Int) error {
+	if !chainID.IsUint64() {
+		return nil
+	}
+	chain, ok := realFundsChains[chainID.Uint64()]
+	if !ok {
+		return nil
+	}
+	profile, hasProfile, err := lookupProfile(config.Profile)
+	if err != nil {
+		return err
+	}
+	if hasProfile && !profile.RealFunds {
+		return errors.Errorf("refusing to use Profile %q on %s", config.Profile, chain)
+	}
+	for _, c := range config.effectiveConfirmations() {
+		if c.n == 0 {
+			return errors.Errorf("refusing to act on unconfirmed events (%s is 0) on %s", c.key, chain)
+		}
+	}
+	return nil
+}
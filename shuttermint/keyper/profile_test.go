@@ -0,0 +1,98 @@
+package keyper
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/spf13/viper"
+	"gotest.tools/v3/assert"
+)
+
+func readProfileConfig(t *testing.T, extra string, replacedKeys ...string) (Config, error) {
+	t.Helper()
+	path := writeTestConfig(t, extra, append(replacedKeys, "Profile")...)
+	v := viper.New()
+	v.SetConfigFile(path)
+	SetConfigDefaults(v)
+	assert.NilError(t, v.ReadInConfig())
+	if err := ApplyProfileDefaults(v); err != nil {
+		return Config{}, err
+	}
+	config := Config{}
+	assert.NilError(t, config.Unmarshal(v, true))
+	return config, nil
+}
+
+func TestApplyProfileDefaults(t *testing.T) {
+	config, err := readProfileConfig(
+		t,
+		"Profile = \"mainnet\"\nAPIAccess = \"open\"\n",
+		"MainChainFollowDistance", "RequireKnownContracts", "APIAccess", "MaxBlocksBehind",
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, config.MainChainFollowDistance, uint64(12))
+	assert.Equal(t, config.RequireKnownContracts, true)
+	assert.Equal(t, config.MaxBlocksBehind, uint64(10))
+	// values in the config file take precedence over the profile
+	assert.Equal(t, config.APIAccess, "open")
+	assert.NilError(t, config.CheckProfile())
+
+	// without a profile, the plain defaults apply
+	config, err = readProfileConfig(t, "", "MaxBlocksBehind")
+	assert.NilError(t, err)
+	assert.Equal(t, config.MaxBlocksBehind, uint64(20))
+
+	_, err = readProfileConfig(t, "Profile = \"prod\"\n")
+	assert.ErrorContains(t, err, `unknown Profile "prod", must be one of dev, mainnet, testnet`)
+}
+
+func TestCheckProfile(t *testing.T) {
+	config := Config{Profile: "mainnet", MainChainFollowDistance: 12}
+	assert.NilError(t, config.CheckProfile())
+	config.AccusationConfirmations = 20
+	assert.NilError(t, config.CheckProfile())
+
+	// explicit confirmations below the follow distance don't reduce it
+	config.BatchClosureConfirmations = 1
+	assert.NilError(t, config.CheckProfile())
+
+	config.MainChainFollowDistance = 2
+	assert.Error(
+		t,
+		config.CheckProfile(),
+		`refusing to wait for only 2 confirmations (BatchClosureConfirmations) with Profile "mainnet", it requires at least 6`,
+	)
+	config.BatchClosureConfirmations = 6
+	assert.ErrorContains(t, config.CheckProfile(), "(ConfigChangeConfirmations)")
+
+	assert.NilError(t, (&Config{Profile: "dev"}).CheckProfile())
+	assert.NilError(t, (&Config{}).CheckProfile())
+	assert.ErrorContains(t, (&Config{Profile: "testnet"}).CheckProfile(), "at least 1")
+	assert.ErrorContains(t, (&Config{Profile: "prod"}).CheckProfile(), "unknown Profile")
+}
+
+func TestCheckChain(t *testing.T) {
+	mainnet := big.NewInt(1)
+	gnosis := big.NewInt(100)
+	local := big.NewInt(1337)
+
+	assert.NilError(t, (&Config{Profile: "mainnet", MainChainFollowDistance: 12}).CheckChain(mainnet))
+	assert.NilError(t, (&Config{MainChainFollowDistance: 3}).CheckChain(gnosis))
+	assert.Error(
+		t,
+		(&Config{Profile: "dev", MainChainFollowDistance: 3}).CheckChain(gnosis),
+		`refusing to use Profile "dev" on Gnosis Chain`,
+	)
+	assert.ErrorContains(t, (&Config{Profile: "testnet", MainChainFollowDistance: 3}).CheckChain(mainnet), "testnet")
+
+	// 0 confirmations are refused on chains with real funds, with or without a profile
+	assert.Error(
+		t,
+		(&Config{}).CheckChain(mainnet),
+		"refusing to act on unconfirmed events (BatchClosureConfirmations is 0) on Ethereum mainnet",
+	)
+	confirmed := Config{BatchClosureConfirmations: 1, ConfigChangeConfirmations: 1, AccusationConfirmations: 1}
+	assert.NilError(t, confirmed.CheckChain(mainnet))
+	assert.NilError(t, (&Config{Profile: "dev"}).CheckChain(local))
+	assert.NilError(t, (&Config{}).CheckChain(new(big.Int).Lsh(big.NewInt(1), 70)))
+}
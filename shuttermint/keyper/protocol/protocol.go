@@ -0,0 +1,76 @@
+// Package protocol defines distinct types for the indices used throughout the Shutter protocol, so
+// that batch indices, epochs, eons and half steps can't be mixed up unnoticed, and helpers encoding
+// the relations between them. Converting to and from uint64 is explicit, e.g. at the boundaries to
+// the contracts and the shuttermint messages, which use bare integers.
+package protocol
+
+// BatchIndex is the index of a batch of transactions.
+type BatchIndex uint64
+
+// EpochIndex identifies the epoch whose key decrypts a batch. Unless per-block epochs are used,
+// epochs are mapped to batches by an EpochMapping. With per-block epochs, the epoch is a main
+// chain block number.
+type EpochIndex uint64
+
+// EonIndex is the number of an eon, i.e. of a DKG run and the eon key it produced.
+type EonIndex uint64
+
+// HalfStep is the index of a half step in the execution of batches on the main chain. Each batch
+// is executed in two half steps, first the cipher half step and then the plain one.
+type HalfStep uint64
+
+// CipherHalfStep returns the half step in which the encrypted transactions of the batch are
+// executed.
+func (b BatchIndex) CipherHalfStep() HalfStep {
+	return HalfStep(2 * b)
+}
+
+// PlainHalfStep returns the half step in which the plain transactions of the batch are executed.
+func (b BatchIndex) PlainHalfStep() HalfStep {
+	return HalfStep(2*b + 1)
+}
+
+// BatchIndex returns the batch the half step belongs to.
+func (h HalfStep) BatchIndex() BatchIndex {
+	return BatchIndex(h / 2)
+}
+
+// IsCipher checks if the half step executes the encrypted transactions of its batch.
+func (h HalfStep) IsCipher() bool {
+	return h%2 == 0
+}
+
+// NextBatchIndex returns the batch the next half step to execute belongs to, given the number of
+// half steps executed so far. All batches before it have been executed completely.
+func NextBatchIndex(numExecutedHalfSteps uint64) BatchIndex {
+	return HalfStep(numExecutedHalfSteps).BatchIndex()
+}
+
+// EpochMapping assigns the epoch Offset + batchIndex * Stride to each batch. A zero stride is
+// treated as 1.
+type EpochMapping struct {
+	Offset uint64
+	Stride uint64
+}
+
+func (m EpochMapping) stride() uint64 {
+	if m.Stride == 0 {
+		return 1
+	}
+	return m.Stride
+}
+
+// Epoch returns the epoch whose key is used for the given batch.
+func (m EpochMapping) Epoch(b BatchIndex) EpochIndex {
+	return EpochIndex(m.Offset + uint64(b)*m.stride())
+}
+
+// BatchIndex returns the batch whose key is generated in the given epoch. It returns false if the
+// mapping does not assign the epoch to any batch.
+func (m EpochMapping) BatchIndex(epoch EpochIndex) (BatchIndex, bool) {
+	e := uint64(epoch)
+	if e < m.Offset || (e-m.Offset)%m.stride() != 0 {
+		return 0, false
+	}
+	return BatchIndex((e - m.Offset) / m.stride()), true
+}
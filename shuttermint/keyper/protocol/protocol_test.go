@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHalfSteps(t *testing.T) {
+	b := BatchIndex(7)
+	assert.Equal(t, b.CipherHalfStep(), HalfStep(14))
+	assert.Equal(t, b.PlainHalfStep(), HalfStep(15))
+	assert.Assert(t, b.CipherHalfStep().IsCipher())
+	assert.Assert(t, !b.PlainHalfStep().IsCipher())
+	assert.Equal(t, b.CipherHalfStep().BatchIndex(), b)
+	assert.Equal(t, b.PlainHalfStep().BatchIndex(), b)
+	assert.Equal(t, NextBatchIndex(15), b)
+}
+
+func TestEpochMapping(t *testing.T) {
+	m := EpochMapping{Offset: 100, Stride: 3}
+	assert.Equal(t, m.Epoch(5), EpochIndex(115))
+	b, ok := m.BatchIndex(115)
+	assert.Assert(t, ok)
+	assert.Equal(t, b, BatchIndex(5))
+	for _, epoch := range []EpochIndex{99, 116} {
+		_, ok = m.BatchIndex(epoch)
+		assert.Assert(t, !ok, epoch)
+	}
+
+	var identity EpochMapping
+	assert.Equal(t, identity.Epoch(5), EpochIndex(5))
+	b, ok = identity.BatchIndex(5)
+	assert.Assert(t, ok)
+	assert.Equal(t, b, BatchIndex(5))
+}
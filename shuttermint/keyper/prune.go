@@ -0,0 +1,147 @@
+package keyper
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+)
+
+// superseded checks if all batches of the eon starting at the given batch index have been
+// executed, because the eon starting at nextStartBatchIndex has taken over.
+func (dcdr *Decider) superseded(nextStartBatchIndex uint64) bool {
+	return uint64(protocol.NextBatchIndex(dcdr.MainChain.NumExecutionHalfSteps)) >= nextStartBatchIndex
+}
+
+// archived checks if the events of the given eon have been moved to the eon archive, or if
+// archiving is disabled.
+func (dcdr *Decider) archived(eon uint64) bool {
+	if dcdr.Retention[retention.Observe].Full == 0 {
+		return true
+	}
+	e, err := dcdr.Shutter.FindEon(eon)
+	return err == nil && e.Archived
+}
+
+// pruneState removes the DKGs and EKGs of old eons from the state, keeping the ones of the most
+// recent PruneKeepEons eons. An EKG is only removed once the next eon has taken over and all
+// batches of its own eon have been executed, and neither are removed before the eon has been
+// archived. A removed EKG is replaced by a summary of its eon, which is kept until the eon is
+// beyond the state retention policy's Keep. Doing nothing if PruneKeepEons is 0.
+func (dcdr *Decider) pruneState() {
+	keep := int(dcdr.PruneKeepEons)
+	if keep == 0 {
+		return
+	}
+
+	var ekgs []*EKG
+	summaries := dcdr.State.EonSummaries
+	for i, ekg := range dcdr.State.EKGs {
+		if i < len(dcdr.State.EKGs)-keep &&
+			dcdr.superseded(dcdr.State.EKGs[i+1].StartBatchIndex) &&
+			dcdr.archived(ekg.Eon) {
+			summaries = append(summaries, newEonSummary(ekg))
+			continue
+		}
+		ekgs = append(ekgs, ekg)
+	}
+	summaries = dcdr.dropEonSummaries(summaries, ekgs)
+
+	var dkgs []DKG
+	for i, dkg := range dcdr.State.DKGs {
+		if i < len(dcdr.State.DKGs)-keep && dkg.IsFinalized() && dcdr.archived(dkg.Eon) {
+			continue
+		}
+		dkgs = append(dkgs, dkg)
+	}
+
+	numEKGs := len(dcdr.State.EKGs) - len(ekgs)
+	numDKGs := len(dcdr.State.DKGs) - len(dkgs)
+	if numEKGs > 0 || numDKGs > 0 {
+		log.Printf("Pruned %d EKGs and %d DKGs of old eons from the state", numEKGs, numDKGs)
+		dcdr.State.EKGs = ekgs
+		dcdr.State.DKGs = dkgs
+	}
+	if numDropped := len(dcdr.State.EonSummaries) + numEKGs - len(summaries); numDropped > 0 {
+		log.Printf("Dropped the summaries of %d old eons from the state", numDropped)
+	}
+	dcdr.State.EonSummaries = summaries
+}
+
+// dropEonSummaries returns the given summaries without the ones of the eons the state retention
+// policy drops, counting the eons of both the summaries and the remaining EKGs.
+func (dcdr *Decider) dropEonSummaries(summaries []*EonSummary, ekgs []*EKG) []*EonSummary {
+	policy := retention.Policy{Full: dcdr.PruneKeepEons, Keep: dcdr.Retention[retention.State].Keep}
+	var eons []uint64
+	for _, s := range summaries {
+		eons = append(eons, s.Eon)
+	}
+	for _, ekg := range ekgs {
+		eons = append(eons, ekg.Eon)
+	}
+	levels := policy.Levels(eons)
+
+	var kept []*EonSummary
+	for _, s := range summaries {
+		if levels[s.Eon] != retention.Drop {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// requestPrune makes the next decider step prune the state to the given number of eons, even if
+// pruning is disabled in the config.
+func (kpr *Keyper) requestPrune(keep uint64) {
+	select {
+	case <-kpr.pruneRequests:
+	default:
+	}
+	select {
+	case kpr.pruneRequests <- keep:
+	default: // another request came in, that one wins
+	}
+}
+
+// pruneKeepEons returns the number of eons the next decider step should prune the state to.
+func (kpr *Keyper) pruneKeepEons() uint64 {
+	select {
+	case keep := <-kpr.pruneRequests:
+		return keep
+	default:
+		return kpr.retention[retention.State].Full
+	}
+}
+
+func (kpr *Keyper) pruneHandler() http.Handler {
+	return http.HandlerFunc(kpr.servePrune)
+}
+
+// servePrune lets the operator prune the state to the number of eons given by the keep query
+// parameter, or to the state retention policy's Full by default. Pruning happens in the next decider step.
+func (kpr *Keyper) servePrune(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	keep := kpr.retention[retention.State].Full
+	if s := r.URL.Query().Get("keep"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || n == 0 {
+			http.Error(w, "keep must be a positive number of eons", http.StatusBadRequest)
+			return
+		}
+		keep = n
+	}
+	if keep == 0 {
+		http.Error(w, "the state retention policy keeps all eons, the keep parameter is required", http.StatusBadRequest)
+		return
+	}
+	kpr.requestPrune(keep)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	httpapi.WriteJSON(w, map[string]uint64{"Keep": keep})
+}
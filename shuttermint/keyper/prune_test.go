@@ -0,0 +1,111 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+)
+
+func TestPruneState(t *testing.T) {
+	shutter := observe.NewShutter()
+	mainChain := observe.NewMainChain(0)
+	st := NewState()
+	for eon := uint64(1); eon <= 4; eon++ {
+		shutter.Eons = append(shutter.Eons, observe.Eon{Eon: eon, Archived: eon <= 2})
+		pure := puredkg.NewPureDKG(eon, 3, 2, 0)
+		pure.Phase = puredkg.Finalized
+		st.DKGs = append(st.DKGs, DKG{Eon: eon, Pure: &pure})
+		st.EKGs = append(st.EKGs, &EKG{Eon: eon, StartBatchIndex: 10 * eon})
+	}
+	dcdr := Decider{
+		Retention: retention.Policies{retention.Observe: {Full: 2}},
+		State:     st,
+		Shutter:   shutter,
+		MainChain: mainChain,
+	}
+	eons := func() (dkgs []uint64, ekgs []uint64) {
+		for _, dkg := range st.DKGs {
+			dkgs = append(dkgs, dkg.Eon)
+		}
+		for _, ekg := range st.EKGs {
+			ekgs = append(ekgs, ekg.Eon)
+		}
+		return dkgs, ekgs
+	}
+
+	// disabled
+	dcdr.pruneState()
+	dkgs, ekgs := eons()
+	assert.Equal(t, len(dkgs), 4)
+	assert.Equal(t, len(ekgs), 4)
+
+	// eon 2 hasn't taken over from eon 1 yet, eon 3 isn't archived
+	dcdr.PruneKeepEons = 1
+	mainChain.NumExecutionHalfSteps = 2 * 15
+	dcdr.pruneState()
+	dkgs, ekgs = eons()
+	assert.DeepEqual(t, dkgs, []uint64{3, 4})
+	assert.DeepEqual(t, ekgs, []uint64{1, 2, 3, 4})
+
+	mainChain.NumExecutionHalfSteps = 2 * 40
+	dcdr.pruneState()
+	dkgs, ekgs = eons()
+	assert.DeepEqual(t, dkgs, []uint64{3, 4})
+	assert.DeepEqual(t, ekgs, []uint64{3, 4})
+	assert.Equal(t, len(st.EonSummaries), 2)
+	assert.Equal(t, st.EonSummaries[0].Eon, uint64(1))
+	assert.Equal(t, st.EonSummaries[1].StartBatchIndex, uint64(20))
+
+	// only the most recent 3 eons are kept at all
+	dcdr.Retention[retention.State] = retention.Policy{Full: 1, Keep: 3}
+	dcdr.pruneState()
+	assert.Equal(t, len(st.EonSummaries), 1)
+	assert.Equal(t, st.EonSummaries[0].Eon, uint64(2))
+}
+
+func TestSyncPendingAppeals(t *testing.T) {
+	mainChain := observe.NewMainChain(0)
+	mainChain.CurrentBlock = 200
+	mainChain.Accusations[2] = &observe.Accusation{HalfStep: 2, Appealed: true}
+	mainChain.Accusations[4] = &observe.Accusation{HalfStep: 4}
+	mainChain.Accusations[6] = &observe.Accusation{HalfStep: 6}
+	st := NewState()
+	for _, halfStep := range []uint64{2, 4, 6, 8} {
+		st.PendingAppeals[halfStep] = struct{}{}
+		st.AppealSentBlocks[halfStep] = 150
+	}
+	st.AppealSentBlocks[6] = 50
+	dcdr := Decider{Config: Config{AppealTimeout: 100}, State: st, MainChain: mainChain}
+
+	dcdr.syncPendingAppeals()
+	assert.DeepEqual(t, st.PendingAppeals, map[uint64]struct{}{4: {}})
+	assert.DeepEqual(t, st.AppealSentBlocks, map[uint64]uint64{4: 150})
+}
+
+func TestGetShutterFilterEon(t *testing.T) {
+	mainChain := observe.NewMainChain(0)
+	st := NewState()
+	assert.Equal(t, st.GetShutterFilter(mainChain).Eon, uint64(0))
+
+	st.EKGs = append(st.EKGs, &EKG{Eon: 4})
+	st.DKGs = append(st.DKGs, DKG{Eon: 5}, DKG{Eon: 3})
+	assert.Equal(t, st.GetShutterFilter(mainChain).Eon, uint64(3))
+}
+
+func TestGetMainChainFilter(t *testing.T) {
+	mainChain := observe.NewMainChain(0)
+	mainChain.NumExecutionHalfSteps = 2 * 50
+	st := NewState()
+	st.HalfStepsChecked = 2 * 40
+
+	assert.Equal(t, st.GetMainChainFilter(mainChain, 0), observe.MainChainFilter{})
+	assert.Equal(t, st.GetMainChainFilter(mainChain, 40), observe.MainChainFilter{})
+	// batches not checked for accusations yet are kept
+	assert.Equal(t, st.GetMainChainFilter(mainChain, 10), observe.MainChainFilter{BatchIndex: 30})
+	st.HalfStepsChecked = 2 * 50
+	assert.Equal(t, st.GetMainChainFilter(mainChain, 10), observe.MainChainFilter{BatchIndex: 40})
+}
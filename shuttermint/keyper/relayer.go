@@ -0,0 +1,29 @@
+package keyper
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/relayer"
+)
+
+// initRelayer checks the relayer config and serves the relayer on its listen address.
+func (kpr *Keyper) initRelayer() error {
+	if kpr.Config.RelayerKey == nil {
+		return errors.New("RelayerKey is required to serve the relayer")
+	}
+	if kpr.Config.RelayerKey.D.Cmp(kpr.Config.SigningKey.D) == 0 {
+		return errors.New("RelayerKey must not be the SigningKey")
+	}
+	if kpr.Config.RelayerQuotaWindow <= 0 && (kpr.Config.RelayerSenderQuota > 0 || kpr.Config.RelayerTotalQuota > 0) {
+		return errors.New("RelayerQuotaWindow must be positive to enforce the relayer quotas")
+	}
+	caller := kpr.ContractCaller.WithSigningKey(kpr.Config.RelayerKey)
+	r := relayer.New(kpr.Config.BatcherContractAddress, relayer.NewContractBatcher(caller), relayer.Quota{
+		Window:    kpr.Config.RelayerQuotaWindow,
+		PerSender: kpr.Config.RelayerSenderQuota,
+		Total:     kpr.Config.RelayerTotalQuota,
+		MaxSize:   kpr.Config.RelayerMaxSize,
+	})
+	r.Mount(kpr.httpServer(kpr.Config.RelayerListenAddress))
+	return nil
+}
@@ -0,0 +1,273 @@
+// Package relayer lets users without funds on the main chain submit encrypted transactions. Users
+// sign a submission of their ciphertext off-chain and post it to the relayer, which adds it to
+// the batcher contract from an account the keyper operator funds, paying the batcher fee and the
+// gas. Quotas per sender and for all senders together limit how much a single user or a flood of
+// throwaway accounts can spend from that account.
+//
+// The signature only identifies the sender for the quotas, the batcher contract sees the relayer
+// as sender of every transaction. It covers the batcher contract address, so a submission for one
+// deployment can't be relayed to another one.
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/dedup"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+)
+
+// maxRequestSize limits the size of a request body, independent of the configured maximum
+// ciphertext size.
+const maxRequestSize = 1 << 20
+
+const submissionHashPrefix = "shutter-relay:"
+
+// Submission is an encrypted transaction a user asks the relayer to add to a batch.
+type Submission struct {
+	BatchIndex  uint64
+	Transaction hexutil.Bytes // the ciphertext, bare or in an envelope
+	Signature   hexutil.Bytes // the sender's signature, see Sign
+}
+
+// submissionHash returns the hash the sender of a submission signs, the Ethereum signed message
+// hash of keccak256(prefix || batcher || batchIndex || transaction).
+func submissionHash(batcher common.Address, batchIndex uint64, tx []byte) []byte {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], batchIndex)
+	return crypto.Keccak256(
+		[]byte("\x19Ethereum Signed Message:\n32"),
+		crypto.Keccak256([]byte(submissionHashPrefix), batcher.Bytes(), index[:], tx),
+	)
+}
+
+// Sign creates a submission of the given transaction for the relayer of the given batcher
+// contract, signed by key.
+func Sign(key *ecdsa.PrivateKey, batcher common.Address, batchIndex uint64, tx []byte) (Submission, error) {
+	sig, err := crypto.Sign(submissionHash(batcher, batchIndex, tx), key)
+	if err != nil {
+		return Submission{}, errors.Wrap(err, "failed to sign submission")
+	}
+	return Submission{BatchIndex: batchIndex, Transaction: tx, Signature: sig}, nil
+}
+
+// Sender recovers the sender of the submission for the given batcher contract.
+func (s Submission) Sender(batcher common.Address) (common.Address, error) {
+	if len(s.Signature) != crypto.SignatureLength {
+		return common.Address{}, errors.Errorf(
+			"signature has %d bytes, expected %d", len(s.Signature), crypto.SignatureLength,
+		)
+	}
+	pubkey, err := crypto.SigToPub(submissionHash(batcher, s.BatchIndex, s.Transaction), s.Signature)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "invalid signature")
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// Quota limits the transactions relayed within each window of the given length.
+type Quota struct {
+	Window    time.Duration
+	PerSender uint64 // transactions per sender and window, 0 for no limit
+	Total     uint64 // transactions of all senders together per window, 0 for no limit
+	MaxSize   uint64 // maximum size of a ciphertext in bytes, 0 for no limit
+}
+
+// ErrQuotaExceeded is returned by Relay if relaying a submission would exceed the quota.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrInvalidSubmission is returned by Relay for submissions that are rejected without trying to
+// relay them, e.g. because they are not signed properly.
+var ErrInvalidSubmission = errors.New("invalid submission")
+
+// Batcher adds transactions to the batcher contract, paying the fee.
+type Batcher interface {
+	AddTransaction(ctx context.Context, batchIndex uint64, tx []byte) (common.Hash, error)
+}
+
+// Relayer relays submissions to the batcher contract within the quota.
+type Relayer struct {
+	batcherAddress common.Address
+	batcher        Batcher
+	quota          Quota
+	now            func() time.Time
+
+	mux         sync.Mutex
+	windowStart time.Time
+	total       uint64
+	bySender    map[common.Address]uint64
+	relayed     map[common.Hash]bool // ciphertexts relayed in the current window
+}
+
+// New creates a relayer for the batcher contract at the given address, sending transactions with
+// the given batcher.
+func New(batcherAddress common.Address, batcher Batcher, quota Quota) *Relayer {
+	return &Relayer{
+		batcherAddress: batcherAddress,
+		batcher:        batcher,
+		quota:          quota,
+		now:            time.Now,
+		bySender:       make(map[common.Address]uint64),
+		relayed:        make(map[common.Hash]bool),
+	}
+}
+
+// Relay checks the submission, charges it to the sender's quota and adds it to the batcher
+// contract. It returns the hash of the main chain transaction. Submissions whose transaction
+// can't be sent are not charged.
+func (r *Relayer) Relay(ctx context.Context, s Submission) (common.Hash, error) {
+	sender, err := r.check(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := dedup.Hash(s.Transaction)
+	if err := r.charge(sender, hash); err != nil {
+		return common.Hash{}, err
+	}
+	txHash, err := r.batcher.AddTransaction(ctx, s.BatchIndex, s.Transaction)
+	if err != nil {
+		r.refund(sender, hash)
+		return common.Hash{}, errors.Wrapf(err, "failed to add transaction for batch %d", s.BatchIndex)
+	}
+	return txHash, nil
+}
+
+// check returns the sender of the submission if it's signed and its transaction is a ciphertext.
+func (r *Relayer) check(s Submission) (common.Address, error) {
+	if r.quota.MaxSize > 0 && uint64(len(s.Transaction)) > r.quota.MaxSize {
+		return common.Address{}, errors.Wrapf(ErrInvalidSubmission,
+			"transaction has %d bytes, at most %d are relayed", len(s.Transaction), r.quota.MaxSize)
+	}
+	if _, err := shcrypto.UnmarshalCiphertext(s.Transaction); err != nil {
+		return common.Address{}, errors.Wrapf(ErrInvalidSubmission, "transaction is not a ciphertext: %v", err)
+	}
+	sender, err := s.Sender(r.batcherAddress)
+	if err != nil {
+		return common.Address{}, errors.Wrapf(ErrInvalidSubmission, "%v", err)
+	}
+	return sender, nil
+}
+
+// charge counts a transaction with the given ciphertext hash against the sender's and the total
+// quota of the current window.
+func (r *Relayer) charge(sender common.Address, hash common.Hash) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	now := r.now()
+	if r.windowStart.IsZero() || !now.Before(r.windowStart.Add(r.quota.Window)) {
+		r.windowStart = now
+		r.total = 0
+		r.bySender = make(map[common.Address]uint64)
+		r.relayed = make(map[common.Hash]bool)
+	}
+	if r.relayed[hash] {
+		return errors.Wrap(ErrInvalidSubmission, "transaction has been relayed already")
+	}
+	if r.quota.Total > 0 && r.total >= r.quota.Total {
+		return errors.Wrapf(ErrQuotaExceeded, "relayed %d transactions since %s", r.total, r.windowStart.Format(time.RFC3339))
+	}
+	if r.quota.PerSender > 0 && r.bySender[sender] >= r.quota.PerSender {
+		return errors.Wrapf(ErrQuotaExceeded, "relayed %d transactions of %s since %s",
+			r.bySender[sender], sender.Hex(), r.windowStart.Format(time.RFC3339))
+	}
+	r.total++
+	r.bySender[sender]++
+	r.relayed[hash] = true
+	return nil
+}
+
+// refund takes back a charge of the current window.
+func (r *Relayer) refund(sender common.Address, hash common.Hash) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if !r.relayed[hash] {
+		return // the window has passed
+	}
+	delete(r.relayed, hash)
+	r.total--
+	r.bySender[sender]--
+}
+
+// Mount registers the relayer's endpoint on the given server. It accepts a Submission as JSON
+// posted to /relay and responds with the hash of the main chain transaction.
+func (r *Relayer) Mount(srv *httpapi.Server) {
+	srv.HandleFunc("/relay", r.serveRelay)
+}
+
+func (r *Relayer) serveRelay(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var s Submission
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxRequestSize)).Decode(&s); err != nil {
+		http.Error(w, "invalid submission: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	txHash, err := r.Relay(req.Context(), s)
+	switch {
+	case errors.Is(err, ErrInvalidSubmission):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, ErrQuotaExceeded):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	httpapi.WriteJSON(w, map[string]common.Hash{"TransactionHash": txHash})
+}
+
+// ContractBatcher adds transactions to the batcher contract with the account of the caller,
+// paying the fee the contract currently requires.
+type ContractBatcher struct {
+	caller *contract.Caller
+
+	mux sync.Mutex // serializes transactions, so that they get different nonces
+}
+
+// NewContractBatcher creates a batcher sending transactions with the given caller. No other
+// component must send transactions from the caller's account, otherwise they may end up with the
+// same nonce.
+func NewContractBatcher(caller *contract.Caller) *ContractBatcher {
+	return &ContractBatcher{caller: caller}
+}
+
+// AddTransaction implements Batcher.
+func (b *ContractBatcher) AddTransaction(ctx context.Context, batchIndex uint64, tx []byte) (common.Hash, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	auth, err := b.caller.Auth(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	fee, err := b.caller.BatcherContract.MinFee(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "failed to query batcher fee")
+	}
+	auth.Context = ctx
+	auth.Value = new(big.Int).SetUint64(fee)
+	t, err := b.caller.BatcherContract.AddTransaction(auth, batchIndex, contract.TransactionTypeCipher, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return t.Hash(), nil
+}
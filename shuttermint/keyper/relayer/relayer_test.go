@@ -0,0 +1,162 @@
+package relayer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+)
+
+var batcherAddress = common.HexToAddress("0x0000000000000000000000000000000000000b47")
+
+type fakeBatcher struct {
+	txs  [][]byte
+	fail bool
+}
+
+func (b *fakeBatcher) AddTransaction(_ context.Context, _ uint64, tx []byte) (common.Hash, error) {
+	if b.fail {
+		return common.Hash{}, errors.New("out of funds")
+	}
+	b.txs = append(b.txs, tx)
+	return common.BytesToHash(ethcrypto.Keccak256(tx)), nil
+}
+
+func ciphertext(t *testing.T) []byte {
+	t.Helper()
+	eonPublicKey := (*shcrypto.EonPublicKey)(new(bn256.G2).ScalarBaseMult(big.NewInt(1111)))
+	m, err := shcrypto.EncryptWithVersion(shcrypto.Version1, []byte("hello"), eonPublicKey, shcrypto.ComputeEpochID(3), rand.Reader)
+	assert.NilError(t, err)
+	return m.Marshal()
+}
+
+func TestSubmissionSender(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	s, err := Sign(key, batcherAddress, 5, []byte("tx"))
+	assert.NilError(t, err)
+
+	sender, err := s.Sender(batcherAddress)
+	assert.NilError(t, err)
+	assert.Equal(t, sender, ethcrypto.PubkeyToAddress(key.PublicKey))
+
+	// the signature is bound to the deployment and the batch
+	sender, err = s.Sender(common.HexToAddress("0x01"))
+	assert.Assert(t, err != nil || sender != ethcrypto.PubkeyToAddress(key.PublicKey))
+	s.BatchIndex = 6
+	sender, err = s.Sender(batcherAddress)
+	assert.Assert(t, err != nil || sender != ethcrypto.PubkeyToAddress(key.PublicKey))
+
+	s.Signature = s.Signature[1:]
+	_, err = s.Sender(batcherAddress)
+	assert.ErrorContains(t, err, "signature has 64 bytes")
+}
+
+func TestRelayQuota(t *testing.T) {
+	alice, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	bob, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	batcher := &fakeBatcher{}
+	r := New(batcherAddress, batcher, Quota{Window: time.Hour, PerSender: 2, Total: 3})
+	now := time.Unix(1000, 0)
+	r.now = func() time.Time { return now }
+
+	relay := func(key *ecdsa.PrivateKey) error {
+		s, err := Sign(key, batcherAddress, 1, ciphertext(t))
+		assert.NilError(t, err)
+		_, err = r.Relay(context.Background(), s)
+		return err
+	}
+
+	assert.NilError(t, relay(alice))
+	assert.NilError(t, relay(alice))
+	assert.Assert(t, errors.Is(relay(alice), ErrQuotaExceeded))
+	assert.NilError(t, relay(bob))
+	assert.Assert(t, errors.Is(relay(bob), ErrQuotaExceeded))
+	assert.Equal(t, len(batcher.txs), 3)
+
+	now = now.Add(time.Hour)
+	assert.NilError(t, relay(alice))
+
+	// transactions that can't be sent are not charged
+	batcher.fail = true
+	assert.ErrorContains(t, relay(alice), "out of funds")
+	batcher.fail = false
+	assert.NilError(t, relay(alice))
+	assert.Equal(t, len(batcher.txs), 5)
+}
+
+func TestRelayRejects(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	batcher := &fakeBatcher{}
+	r := New(batcherAddress, batcher, Quota{Window: time.Hour, MaxSize: 300})
+
+	tx := ciphertext(t)
+	s, err := Sign(key, batcherAddress, 1, tx)
+	assert.NilError(t, err)
+	_, err = r.Relay(context.Background(), s)
+	assert.NilError(t, err)
+	_, err = r.Relay(context.Background(), s)
+	assert.ErrorContains(t, err, "relayed already")
+
+	s, err = Sign(key, batcherAddress, 1, append(tx, make([]byte, 100)...))
+	assert.NilError(t, err)
+	_, err = r.Relay(context.Background(), s)
+	assert.Assert(t, errors.Is(err, ErrInvalidSubmission))
+
+	s, err = Sign(key, batcherAddress, 1, []byte("plaintext"))
+	assert.NilError(t, err)
+	_, err = r.Relay(context.Background(), s)
+	assert.ErrorContains(t, err, "not a ciphertext")
+	assert.Equal(t, len(batcher.txs), 1)
+}
+
+func TestServeRelay(t *testing.T) {
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	r := New(batcherAddress, &fakeBatcher{}, Quota{Window: time.Hour, PerSender: 1})
+	srv := httpapi.NewServer()
+	r.Mount(srv)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	post := func(s Submission) *http.Response {
+		body, err := json.Marshal(s)
+		assert.NilError(t, err)
+		resp, err := http.Post(ts.URL+"/relay", "application/json", bytes.NewReader(body))
+		assert.NilError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	s, err := Sign(key, batcherAddress, 1, ciphertext(t))
+	assert.NilError(t, err)
+	assert.Equal(t, post(s).StatusCode, http.StatusOK)
+	s, err = Sign(key, batcherAddress, 1, ciphertext(t))
+	assert.NilError(t, err)
+	assert.Equal(t, post(s).StatusCode, http.StatusTooManyRequests)
+	s.Signature = nil
+	assert.Equal(t, post(s).StatusCode, http.StatusBadRequest)
+
+	resp, err := http.Get(ts.URL + "/relay")
+	assert.NilError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusMethodNotAllowed)
+}
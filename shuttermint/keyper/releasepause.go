@@ -0,0 +1,69 @@
+package keyper
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/policy"
+)
+
+// Paths of the admin API endpoints pausing and resuming key releases.
+const (
+	releasesPausePath  = "/releases/pause"
+	releasesResumePath = "/releases/resume"
+)
+
+// pausedPolicy delays all key releases and leaves the other decisions to the wrapped policy.
+type pausedPolicy struct {
+	policy.Policy
+}
+
+func (pausedPolicy) ReleaseKey(observe.World, policy.KeyRelease) policy.Decision {
+	return policy.Delay
+}
+
+// policy returns the policy for the next decider step, nil for the default one.
+func (kpr *Keyper) policy() policy.Policy {
+	if !kpr.releasesArePaused() {
+		return kpr.Policy
+	}
+	if kpr.Policy == nil {
+		return pausedPolicy{policy.Default{}}
+	}
+	return pausedPolicy{kpr.Policy}
+}
+
+func (kpr *Keyper) releasesArePaused() bool {
+	return atomic.LoadInt32(&kpr.releasesPaused) == 1
+}
+
+func (kpr *Keyper) releasePauseHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveReleasePause)
+}
+
+// serveReleasePause lets the operator pause and resume the release of our epoch secret key
+// shares, e.g. while investigating a suspected key compromise. Releases are delayed as by a
+// policy, so shares not released before their batch's execution timeout are not released at all.
+func (kpr *Keyper) serveReleasePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	switch r.URL.Path {
+	case releasesPausePath:
+		if atomic.CompareAndSwapInt32(&kpr.releasesPaused, 0, 1) {
+			log.Printf("Key releases paused via the admin API")
+		}
+	case releasesResumePath:
+		if atomic.CompareAndSwapInt32(&kpr.releasesPaused, 1, 0) {
+			log.Printf("Key releases resumed via the admin API")
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	httpapi.WriteJSON(w, map[string]bool{"Paused": kpr.releasesArePaused()})
+}
@@ -0,0 +1,147 @@
+package keyper
+
+import (
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+)
+
+// fixedConfigKeys identify the keyper and its deployment. A reloaded config changing any of them
+// is rejected as a whole, as it most likely belongs to a different keyper.
+var fixedConfigKeys = map[string]bool{
+	"DBDir":                    true,
+	"SigningKey":               true,
+	"ValidatorSeed":            true,
+	"EncryptionKey":            true,
+	"RelayerKey":               true,
+	"ConfigContract":           true,
+	"BatcherContract":          true,
+	"KeyBroadcastContract":     true,
+	"ExecutorContract":         true,
+	"DepositContract":          true,
+	"KeyperSlasher":            true,
+	"DecryptionOracleContract": true,
+}
+
+// liveConfigKeys are applied by reloadConfig right away. Changes of all other keys take effect
+// after the next restart.
+var liveConfigKeys = map[string]bool{
+	"ExecutionStaggering":        true,
+	"OracleSubmissionStaggering": true,
+	"GasPriceMultiplier":         true,
+	"GasPriceOracle":             true,
+	"MaxGasPrices":               true,
+	"ReplaceTXsAfter":            true,
+	"MaxTXReplacements":          true,
+	"ActionTimeout":              true,
+	"ActionTimeouts":             true,
+}
+
+// runEnvSettings returns the settings of the run environment configured in config.
+func runEnvSettings(config Config) (fx.Settings, error) {
+	timeouts, err := fx.ParseActionTimeouts(config.ActionTimeout, config.ActionTimeouts)
+	if err != nil {
+		return fx.Settings{}, err
+	}
+	caps, err := fx.ParseGasPriceCaps(config.MaxGasPrices)
+	if err != nil {
+		return fx.Settings{}, err
+	}
+	if config.ReplaceTXsAfter < 0 {
+		return fx.Settings{}, errors.Errorf("ReplaceTXsAfter must not be negative")
+	}
+	return fx.Settings{
+		Timeouts:          timeouts,
+		GasPriceCaps:      caps,
+		ReplaceTXsAfter:   config.ReplaceTXsAfter,
+		MaxTXReplacements: int(config.MaxTXReplacements),
+	}, nil
+}
+
+// changedConfigKeys returns the keys whose values differ between the two configs.
+func changedConfigKeys(current, reloaded Config) []ConfigKey {
+	var changed []ConfigKey
+	a := reflect.ValueOf(current)
+	b := reflect.ValueOf(reloaded)
+	for _, key := range ConfigKeys() {
+		if !reflect.DeepEqual(a.FieldByName(key.Field).Interface(), b.FieldByName(key.Field).Interface()) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+func configKeyNames(keys []ConfigKey) string {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// reloadConfig reads the config with LoadConfig and applies it, see applyConfig. If that fails,
+// the keyper keeps running with the current config.
+func (kpr *Keyper) reloadConfig() {
+	log.Printf("Reloading config")
+	config, err := kpr.LoadConfig()
+	if err == nil {
+		err = kpr.applyConfig(config)
+	}
+	if err != nil {
+		log.Printf("Error: failed to reload config, keeping the current one: %+v", err)
+	}
+}
+
+// applyConfig applies the changes of the live config keys. It fails without applying anything if
+// one of the fixed keys has changed or the new values are invalid. Changes of the other keys are
+// logged only, they take effect after a restart.
+func (kpr *Keyper) applyConfig(config Config) error {
+	var fixed, live, restart []ConfigKey
+	for _, key := range changedConfigKeys(kpr.Config, config) {
+		switch {
+		case fixedConfigKeys[key.Name]:
+			fixed = append(fixed, key)
+		case liveConfigKeys[key.Name]:
+			live = append(live, key)
+		default:
+			restart = append(restart, key)
+		}
+	}
+	if len(fixed) > 0 {
+		return errors.Errorf("%s cannot be changed, the config seems to belong to a different keyper", configKeyNames(fixed))
+	}
+
+	oracle, err := gaspricer.ParseOracle(config.GasPriceOracle)
+	if err != nil {
+		return err
+	}
+	settings, err := runEnvSettings(config)
+	if err != nil {
+		return err
+	}
+	if err := gaspricer.SetMultiplier(config.GasPriceMultiplier); err != nil {
+		return err
+	}
+	kpr.gasPriceOracle.Switch(oracle)
+	kpr.runenv.Reconfigure(settings)
+	current := reflect.ValueOf(&kpr.Config).Elem()
+	reloaded := reflect.ValueOf(config)
+	for _, key := range live {
+		current.FieldByName(key.Field).Set(reloaded.FieldByName(key.Field))
+	}
+
+	if len(live) > 0 {
+		log.Printf("Applied the reloaded config: %s", configKeyNames(live))
+	} else {
+		log.Printf("Reloaded config, nothing to apply")
+	}
+	if len(restart) > 0 {
+		log.Printf("Changes of %s take effect after a restart", configKeyNames(restart))
+	}
+	return nil
+}
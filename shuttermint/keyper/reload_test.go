@@ -0,0 +1,77 @@
+package keyper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/gaspricer"
+)
+
+func newReloadTestKeyper(t *testing.T) *Keyper {
+	t.Helper()
+	config := Config{
+		EthereumURL:         "ws://localhost:8545",
+		ExecutionStaggering: 5,
+		GasPriceMultiplier:  1.5,
+		GasPriceOracle:      "node",
+	}
+	assert.NilError(t, config.GenerateNewKeys())
+	settings, err := runEnvSettings(config)
+	assert.NilError(t, err)
+	runenv := &fx.RunEnv{Settings: settings}
+	t.Cleanup(func() { _ = gaspricer.SetMultiplier(1.5) })
+	return &Keyper{
+		Config:         config,
+		runenv:         runenv,
+		gasPriceOracle: gaspricer.NewSwitchableOracle(gaspricer.NodeOracle{}),
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	kpr := newReloadTestKeyper(t)
+	config := kpr.Config
+	config.ExecutionStaggering = 7
+	config.MaxGasPrices = []string{"ExecuteCipherBatch=200"}
+	config.ReplaceTXsAfter = time.Minute
+	config.ActionTimeout = time.Second
+	config.EthereumURL = "ws://localhost:8546"
+	assert.NilError(t, kpr.applyConfig(config))
+
+	assert.Equal(t, kpr.Config.ExecutionStaggering, uint64(7))
+	assert.DeepEqual(t, kpr.Config.MaxGasPrices, []string{"ExecuteCipherBatch=200"})
+	assert.Equal(t, kpr.runenv.Settings.ReplaceTXsAfter, time.Minute)
+	assert.Equal(t, kpr.runenv.Settings.Timeouts.Default, time.Second)
+	assert.Equal(t, len(kpr.runenv.Settings.GasPriceCaps), 1)
+	// takes effect after a restart only
+	assert.Equal(t, kpr.Config.EthereumURL, "ws://localhost:8545")
+}
+
+func TestApplyConfigRejects(t *testing.T) {
+	kpr := newReloadTestKeyper(t)
+
+	config := kpr.Config
+	config.ExecutionStaggering = 7
+	assert.NilError(t, config.GenerateNewKeys())
+	err := kpr.applyConfig(config)
+	assert.ErrorContains(t, err, "SigningKey, ValidatorSeed, EncryptionKey cannot be changed")
+
+	config = kpr.Config
+	config.ExecutionStaggering = 7
+	config.BatcherContractAddress = common.HexToAddress("0x01")
+	assert.ErrorContains(t, kpr.applyConfig(config), "BatcherContract cannot be changed")
+
+	// invalid values are rejected without applying any of the valid ones
+	config = kpr.Config
+	config.ExecutionStaggering = 7
+	config.MaxGasPrices = []string{"ExecuteCipherBatch=lots"}
+	assert.Assert(t, kpr.applyConfig(config) != nil)
+	config.MaxGasPrices = nil
+	config.GasPriceMultiplier = -1
+	assert.Assert(t, kpr.applyConfig(config) != nil)
+	assert.Equal(t, kpr.Config.ExecutionStaggering, uint64(5))
+	assert.Equal(t, kpr.Config.GasPriceMultiplier, 1.5)
+}
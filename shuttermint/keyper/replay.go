@@ -0,0 +1,337 @@
+package keyper
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/rpc/client"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// ReplayStep is the outcome of running the decider for a single shuttermint block during a replay.
+type ReplayStep struct {
+	Height         int64
+	MainChainBlock uint64
+	Actions        []string
+	Messages       []string // keys of the shuttermint messages among the actions, see ReplayDiff
+}
+
+// ReplayDiff compares the shuttermint messages produced during a replay with the ones the keyper
+// actually sent. Messages are identified by their type and, if they have one, their eon, epoch,
+// or batch index.
+type ReplayDiff struct {
+	Missing    []string // sent by the keyper, but not produced in the replay
+	Unexpected []string // produced in the replay, but not sent by the keyper
+}
+
+// Replayer re-runs the decider against recorded chain data. The observed state is reconstructed
+// block by block from the shuttermint chain and the main chain, which requires an archive node
+// for the latter. No actions are executed and the keyper's state file is left untouched.
+type Replayer struct {
+	Config Config
+	Shmcl  client.Client
+	Caller *contract.Caller
+
+	State      *State
+	shutter    *observe.Shutter
+	mainChain  *observe.MainChain
+	shareCache *epochkg.ShareCache
+
+	mainChainBlock     uint64 // latest main chain block not newer than the last shuttermint block
+	haveMainChainBlock bool
+}
+
+// NewReplayer creates a replayer starting from an empty state.
+func NewReplayer(config Config, shmcl client.Client, caller *contract.Caller) *Replayer {
+	mainChain := observe.NewMainChain(config.MainChainFollowDistance)
+	mainChain.Confirmations = config.confirmations()
+	return &Replayer{
+		Config:     config,
+		Shmcl:      shmcl,
+		Caller:     caller,
+		State:      NewState(),
+		shutter:    observe.NewShutter(),
+		mainChain:  mainChain,
+		shareCache: epochkg.NewShareCache(),
+	}
+}
+
+// LoadSnapshot makes the replayer start from a copy of the keyper's state file instead of an
+// empty state. Replaying from genesis produces different secrets than the keyper has used, so a
+// snapshot from before the incident gives more faithful results.
+func (r *Replayer) LoadSnapshot(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open state snapshot")
+	}
+	defer file.Close()
+	st, err := decodeStoredState(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode state snapshot %s", path)
+	}
+	r.loadStoredState(st)
+	return nil
+}
+
+// LoadSupportBundle makes the replayer start from the state in a support bundle, see
+// SupportBundle. The bundle lacks the secrets of the DKGs and EKGs, so they are dropped and the
+// replay doesn't produce the messages requiring them, e.g. poly evals and epoch secret key shares.
+func (r *Replayer) LoadSupportBundle(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open support bundle")
+	}
+	defer file.Close()
+	b, err := ReadSupportBundle(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read support bundle %s", path)
+	}
+	b.State.DKGs = nil
+	b.State.EKGs = nil
+	r.loadStoredState(StoredState{State: b.State, Shutter: b.Shutter, MainChain: b.MainChain})
+	return nil
+}
+
+func (r *Replayer) loadStoredState(st StoredState) {
+	r.State = st.State
+	r.shutter = st.Shutter
+	r.mainChain = st.MainChain
+	r.mainChain.Confirmations = r.Config.confirmations()
+	r.mainChainBlock = st.MainChain.CurrentBlock + st.MainChain.FollowDistance
+	r.haveMainChainBlock = true
+}
+
+// Replay runs the decider for every shuttermint block up to toHeight, continuing from the last
+// replayed block. Steps from fromHeight on are passed to handleStep and taken into account in the
+// diff.
+func (r *Replayer) Replay(
+	ctx context.Context, fromHeight, toHeight int64, handleStep func(ReplayStep),
+) (ReplayDiff, error) {
+	if toHeight < fromHeight {
+		return ReplayDiff{}, errors.Errorf("to height %d is below from height %d", toHeight, fromHeight)
+	}
+	if r.shutter.CurrentBlock >= fromHeight {
+		return ReplayDiff{}, errors.Errorf(
+			"cannot replay from height %d, state is at height %d already", fromHeight, r.shutter.CurrentBlock)
+	}
+	var sent, produced []string
+	err := observe.FetchTxs(ctx, r.Shmcl, fromHeight, toHeight, func(tx *rpctypes.ResultTx) {
+		if key, ok := r.sentMessage(tx); ok {
+			sent = append(sent, key)
+		}
+	})
+	if err != nil {
+		return ReplayDiff{}, err
+	}
+	for height := r.shutter.CurrentBlock + 1; height <= toHeight; height++ {
+		step, err := r.step(ctx, height)
+		if err != nil {
+			return ReplayDiff{}, err
+		}
+		if height >= fromHeight {
+			produced = append(produced, step.Messages...)
+			handleStep(step)
+		}
+	}
+	return diffMessages(sent, produced), nil
+}
+
+func (r *Replayer) step(ctx context.Context, height int64) (ReplayStep, error) {
+	block, err := r.Shmcl.Block(ctx, &height)
+	if err != nil {
+		return ReplayStep{}, errors.Wrapf(err, "failed to fetch shuttermint block %d", height)
+	}
+	shutter, err := r.shutter.SyncToHeight(ctx, r.Shmcl, height)
+	if err != nil {
+		return ReplayStep{}, err
+	}
+	// pretend we're live at the replayed height
+	shutter.LastCommittedHeight = height
+	shutter.NodeStatus = nil
+	r.shutter = shutter
+
+	head, err := r.mainChainBlockAt(ctx, uint64(block.Block.Time.Unix()))
+	if err != nil {
+		return ReplayStep{}, err
+	}
+	if head >= r.mainChain.FollowDistance+r.mainChain.CurrentBlock {
+		mainChain, err := r.mainChain.SyncToBlock(ctx, r.Caller, head-r.mainChain.FollowDistance)
+		if err != nil {
+			return ReplayStep{}, err
+		}
+		r.mainChain = mainChain
+	}
+
+	dcdr := Decider{
+		Config:      r.Config,
+		State:       r.State,
+		Shutter:     r.shutter,
+		MainChain:   r.mainChain,
+		Actions:     []fx.IAction{},
+		PhaseLength: NewConstantPhaseLength(int64(r.Config.DKGPhaseLength)),
+		ShareCache:  r.shareCache,
+	}
+	dcdr.Decide()
+	r.State.ActionCounter += uint64(len(dcdr.Actions))
+
+	step := ReplayStep{Height: height, MainChainBlock: r.mainChain.CurrentBlock}
+	for _, a := range dcdr.Actions {
+		step.Actions = append(step.Actions, fmt.Sprint(a))
+		if msg, ok := a.(*fx.SendShuttermintMessage); ok {
+			step.Messages = append(step.Messages, messageKey(msg.Msg))
+		}
+	}
+	return step, nil
+}
+
+// mainChainBlockAt returns the latest main chain block with a timestamp not after the given time.
+// Shuttermint blocks are replayed in order, so after the first lookup we search forward from the
+// previous result.
+func (r *Replayer) mainChainBlockAt(ctx context.Context, timestamp uint64) (uint64, error) {
+	headerAt := func(number *big.Int) (*types.Header, error) {
+		header, err := r.Caller.Ethclient.HeaderByNumber(ctx, number)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch main chain block %s", number)
+		}
+		return header, nil
+	}
+	latest, err := headerAt(nil)
+	if err != nil {
+		return 0, err
+	}
+	if !r.haveMainChainBlock {
+		// binary search for the first block after the timestamp
+		lo, hi := uint64(0), latest.Number.Uint64()+1
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			header, err := headerAt(new(big.Int).SetUint64(mid))
+			if err != nil {
+				return 0, err
+			}
+			if header.Time > timestamp {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		if lo == 0 {
+			return 0, errors.Errorf("main chain genesis is after shuttermint time %d", timestamp)
+		}
+		r.mainChainBlock = lo - 1
+		r.haveMainChainBlock = true
+		return r.mainChainBlock, nil
+	}
+	for r.mainChainBlock < latest.Number.Uint64() {
+		header, err := headerAt(new(big.Int).SetUint64(r.mainChainBlock + 1))
+		if err != nil {
+			return 0, err
+		}
+		if header.Time > timestamp {
+			break
+		}
+		r.mainChainBlock++
+	}
+	return r.mainChainBlock, nil
+}
+
+// sentMessage returns the key of the message in the given transaction if it has been sent by us.
+func (r *Replayer) sentMessage(tx *rpctypes.ResultTx) (string, bool) {
+	if tx.TxResult.Code != 0 {
+		return "", false
+	}
+	signedMsg, err := base64.RawURLEncoding.DecodeString(string(tx.Tx))
+	if err != nil {
+		return "", false
+	}
+	signer, err := shmsg.GetSigner(signedMsg)
+	if err != nil || signer != r.Config.Address() {
+		return "", false
+	}
+	msg, err := shmsg.GetMessage(signedMsg)
+	if err != nil {
+		return "", false
+	}
+	return messageKey(msg.Msg), true
+}
+
+// messageKey identifies a message by its type and, if it has one, its eon, epoch, or batch index.
+func messageKey(msg *shmsg.Message) string {
+	kind := strings.TrimPrefix(fmt.Sprintf("%T", msg.Payload), "*shmsg.Message_")
+	switch p := msg.Payload.(type) {
+	case *shmsg.Message_BatchConfig:
+		return fmt.Sprintf("%s config=%d", kind, p.BatchConfig.ConfigIndex)
+	case *shmsg.Message_BatchConfigStarted:
+		return fmt.Sprintf("%s config=%d", kind, p.BatchConfigStarted.BatchConfigIndex)
+	case *shmsg.Message_DecryptionSignature:
+		return fmt.Sprintf("%s batch=%d", kind, p.DecryptionSignature.BatchIndex)
+	case *shmsg.Message_SkipCipherVote:
+		return fmt.Sprintf("%s batch=%d", kind, p.SkipCipherVote.BatchIndex)
+	case *shmsg.Message_HaltResumeVote:
+		return fmt.Sprintf("%s halt=%d", kind, p.HaltResumeVote.HaltHeight)
+	case *shmsg.Message_DecryptionApproval:
+		return fmt.Sprintf("%s eon=%d epoch=%d", kind, p.DecryptionApproval.Eon, p.DecryptionApproval.Epoch)
+	case *shmsg.Message_Capabilities:
+		return fmt.Sprintf("%s version=%s", kind, p.Capabilities.Version)
+	case *shmsg.Message_PolyEval:
+		return fmt.Sprintf("%s eon=%d", kind, p.PolyEval.Eon)
+	case *shmsg.Message_PolyCommitment:
+		return fmt.Sprintf("%s eon=%d", kind, p.PolyCommitment.Eon)
+	case *shmsg.Message_PolyCommitments:
+		var eons []string
+		for _, c := range p.PolyCommitments.Commitments {
+			eons = append(eons, strconv.FormatUint(c.Eon, 10))
+		}
+		return fmt.Sprintf("%s eons=%s", kind, strings.Join(eons, ","))
+	case *shmsg.Message_Accusation:
+		return fmt.Sprintf("%s eon=%d", kind, p.Accusation.Eon)
+	case *shmsg.Message_Apology:
+		return fmt.Sprintf("%s eon=%d", kind, p.Apology.Eon)
+	case *shmsg.Message_EonStartVote:
+		return fmt.Sprintf("%s batch=%d", kind, p.EonStartVote.StartBatchIndex)
+	case *shmsg.Message_EpochSecretKeyShare:
+		s := p.EpochSecretKeyShare
+		if s.Namespace != "" {
+			return fmt.Sprintf("%s eon=%d epoch=%d namespace=%s", kind, s.Eon, s.Epoch, s.Namespace)
+		}
+		return fmt.Sprintf("%s eon=%d epoch=%d", kind, s.Eon, s.Epoch)
+	default:
+		return kind
+	}
+}
+
+// diffMessages compares the sent and produced message keys as multisets.
+func diffMessages(sent, produced []string) ReplayDiff {
+	counts := make(map[string]int)
+	for _, k := range sent {
+		counts[k]++
+	}
+	for _, k := range produced {
+		counts[k]--
+	}
+	diff := ReplayDiff{}
+	for k, n := range counts {
+		for ; n > 0; n-- {
+			diff.Missing = append(diff.Missing, k)
+		}
+		for ; n < 0; n++ {
+			diff.Unexpected = append(diff.Unexpected, k)
+		}
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Unexpected)
+	return diff
+}
@@ -0,0 +1,38 @@
+package keyper
+
+import (
+	"math/big"
+	"testing"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+func TestMessageKey(t *testing.T) {
+	share := (*shcrypto.EpochSecretKeyShare)(new(bn256.G1).ScalarBaseMult(big.NewInt(1)))
+	assert.Equal(t, messageKey(shmsg.NewAccusation(3, nil)), "Accusation eon=3")
+	assert.Equal(t, messageKey(shmsg.NewDecryptionSignature(7, nil)), "DecryptionSignature batch=7")
+	assert.Equal(
+		t,
+		messageKey(shmsg.NewPolyCommitments([]*shmsg.PolyCommitment{{Eon: 2}, {Eon: 3}})),
+		"PolyCommitments eons=2,3",
+	)
+	assert.Equal(t, messageKey(shmsg.NewEpochSecretKeyShare(1, 5, share)), "EpochSecretKeyShare eon=1 epoch=5")
+	assert.Equal(
+		t,
+		messageKey(shmsg.NewNamespacedEpochSecretKeyShare(1, "ns", 5, share)),
+		"EpochSecretKeyShare eon=1 epoch=5 namespace=ns",
+	)
+}
+
+func TestDiffMessages(t *testing.T) {
+	diff := diffMessages(
+		[]string{"a", "b", "b", "c"},
+		[]string{"b", "c", "d"},
+	)
+	assert.DeepEqual(t, diff, ReplayDiff{Missing: []string{"a", "b"}, Unexpected: []string{"d"}})
+	assert.DeepEqual(t, diffMessages([]string{"a"}, []string{"a"}), ReplayDiff{})
+}
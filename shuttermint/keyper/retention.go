@@ -0,0 +1,51 @@
+package keyper
+
+import (
+	"log"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+)
+
+// retentionPolicies returns the retention policies of the datasets kept about past eons.
+// ArchiveKeepEons and StateRetentionEons are the number of eons kept fully in memory and in the
+// state, unless Retention sets a policy for these datasets.
+func (kc Config) retentionPolicies() (retention.Policies, error) {
+	policies, err := retention.ParsePolicies(retention.Policies{
+		retention.Observe: {Full: kc.ArchiveKeepEons},
+		retention.State:   {Full: kc.StateRetentionEons},
+	}, kc.Retention)
+	if err != nil {
+		return nil, err
+	}
+	if policies[retention.Observe].Full == 0 && policies[retention.Archive] != (retention.Policy{}) {
+		return nil, errors.New("the archive retention policy requires archiving to be enabled, see ArchiveKeepEons")
+	}
+	return policies, nil
+}
+
+// applyRetention drops the eons the observe policy drops from memory and summarizes and deletes
+// the archived eons according to the archive policy. The archive is only checked once a new eon
+// has started.
+func (kpr *Keyper) applyRetention(shutter *observe.Shutter) *observe.Shutter {
+	shutter = shutter.DropEons(kpr.retention[retention.Observe])
+	if len(shutter.Eons) == 0 {
+		return shutter
+	}
+	last := shutter.Eons[len(shutter.Eons)-1].Eon
+	if last == kpr.retainedEon || kpr.retention[retention.Archive] == (retention.Policy{}) {
+		return shutter
+	}
+	summarized, deleted, err := kpr.eonArchive.ApplyRetention(kpr.retention[retention.Archive])
+	if err != nil {
+		log.Printf("Error: failed to apply the archive retention policy: %+v", err)
+		return shutter
+	}
+	kpr.retainedEon = last
+	if summarized > 0 || deleted > 0 {
+		log.Printf("Summarized %d and deleted %d archived eons", summarized, deleted)
+	}
+	return shutter
+}
@@ -0,0 +1,154 @@
+// Package retention implements the retention policies for the data a keyper keeps about past
+// eons. A policy keeps the most recent eons of a dataset fully, older ones only as a summary, and
+// nothing about the eons beyond that, so that the storage a keyper needs is bounded instead of
+// growing with every eon.
+package retention
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Dataset names a kind of data kept about past eons.
+type Dataset string
+
+const (
+	// Observe are the shuttermint events of the eons kept in memory. Summarized eons keep their
+	// start event only, their events are moved to the archive.
+	Observe Dataset = "observe"
+	// State are the DKGs and EKGs in the keyper state. Summarized eons keep the information about
+	// their DKG, but not the key material.
+	State Dataset = "state"
+	// Archive are the shuttermint events of past eons stored on disk. Summarized eons keep the
+	// statistics about their DKG and epochs only.
+	Archive Dataset = "archive"
+)
+
+// Datasets are all datasets policies can be configured for.
+var Datasets = []Dataset{Observe, State, Archive}
+
+// Level is the level of detail an eon is kept with.
+type Level int
+
+const (
+	Full Level = iota
+	Summary
+	Drop
+)
+
+func (l Level) String() string {
+	switch l {
+	case Full:
+		return "full"
+	case Summary:
+		return "summary"
+	default:
+		return "drop"
+	}
+}
+
+// Policy determines how much is kept about the eons of a dataset. The zero value keeps
+// everything.
+type Policy struct {
+	Full uint64 // number of most recent eons kept fully, 0 to keep all eons fully
+	Keep uint64 // number of most recent eons kept at all, including the full ones, 0 to keep all
+}
+
+// ParsePolicy parses a policy given as "full" or "full:keep", e.g. "10:100".
+func ParsePolicy(s string) (Policy, error) {
+	fullStr, keepStr, hasKeep := s, "", false
+	if i := strings.Index(s, ":"); i >= 0 {
+		fullStr, keepStr, hasKeep = s[:i], s[i+1:], true
+	}
+	var p Policy
+	var err error
+	p.Full, err = strconv.ParseUint(fullStr, 10, 64)
+	if err != nil {
+		return Policy{}, errors.Errorf("invalid retention policy %q, expected \"full[:keep]\"", s)
+	}
+	if hasKeep {
+		p.Keep, err = strconv.ParseUint(keepStr, 10, 64)
+		if err != nil {
+			return Policy{}, errors.Errorf("invalid retention policy %q, expected \"full[:keep]\"", s)
+		}
+	}
+	if p.Keep != 0 && (p.Full == 0 || p.Keep < p.Full) {
+		return Policy{}, errors.Errorf("invalid retention policy %q, keep must not be less than full", s)
+	}
+	return p, nil
+}
+
+func (p Policy) String() string {
+	if p.Keep == 0 {
+		return strconv.FormatUint(p.Full, 10)
+	}
+	return strconv.FormatUint(p.Full, 10) + ":" + strconv.FormatUint(p.Keep, 10)
+}
+
+// Level returns the level an eon is kept with, given the number of more recent eons.
+func (p Policy) Level(newer uint64) Level {
+	switch {
+	case p.Full == 0 || newer < p.Full:
+		return Full
+	case p.Keep == 0 || newer < p.Keep:
+		return Summary
+	default:
+		return Drop
+	}
+}
+
+// Levels returns the level each of the given eons is kept with.
+func (p Policy) Levels(eons []uint64) map[uint64]Level {
+	sorted := append([]uint64(nil), eons...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	levels := make(map[uint64]Level, len(sorted))
+	for i, eon := range sorted {
+		levels[eon] = p.Level(uint64(len(sorted) - 1 - i))
+	}
+	return levels
+}
+
+// Policies holds the policy of each dataset.
+type Policies map[Dataset]Policy
+
+// ParsePolicies parses policies given as "dataset=full[:keep]", e.g. "archive=100:1000". The
+// given defaults apply to datasets without a policy.
+func ParsePolicies(defaults Policies, entries []string) (Policies, error) {
+	policies := make(Policies, len(Datasets))
+	for d, p := range defaults {
+		policies[d] = p
+	}
+	seen := make(map[Dataset]bool)
+	for _, e := range entries {
+		i := strings.Index(e, "=")
+		if i < 0 {
+			return nil, errors.Errorf("invalid retention policy %q, expected \"dataset=full[:keep]\"", e)
+		}
+		d := Dataset(e[:i])
+		if !d.valid() {
+			return nil, errors.Errorf("invalid retention policy %q, unknown dataset %q", e, d)
+		}
+		if seen[d] {
+			return nil, errors.Errorf("duplicate retention policy for dataset %q", d)
+		}
+		seen[d] = true
+		p, err := ParsePolicy(e[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		policies[d] = p
+	}
+	return policies, nil
+}
+
+func (d Dataset) valid() bool {
+	for _, v := range Datasets {
+		if d == v {
+			return true
+		}
+	}
+	return false
+}
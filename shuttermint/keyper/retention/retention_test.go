@@ -0,0 +1,52 @@
+package retention
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy("10:100")
+	assert.NilError(t, err)
+	assert.Equal(t, p, Policy{Full: 10, Keep: 100})
+	assert.Equal(t, p.String(), "10:100")
+	p, err = ParsePolicy("0")
+	assert.NilError(t, err)
+	assert.Equal(t, p, Policy{})
+
+	for _, s := range []string{"", "x", "10:", "10:x", "10:5", "0:5", "-1"} {
+		_, err := ParsePolicy(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	levels := Policy{Full: 2, Keep: 3}.Levels([]uint64{5, 2, 4, 3})
+	assert.DeepEqual(t, levels, map[uint64]Level{2: Drop, 3: Summary, 4: Full, 5: Full})
+	levels = Policy{}.Levels([]uint64{1, 2})
+	assert.DeepEqual(t, levels, map[uint64]Level{1: Full, 2: Full})
+	assert.Equal(t, Policy{Full: 1}.Level(100), Summary)
+}
+
+func TestParsePolicies(t *testing.T) {
+	defaults := Policies{Observe: {Full: 10}}
+	policies, err := ParsePolicies(defaults, []string{"archive=100:1000", "state=5"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policies, Policies{
+		Observe: {Full: 10},
+		State:   {Full: 5},
+		Archive: {Full: 100, Keep: 1000},
+	})
+	policies, err = ParsePolicies(defaults, []string{"observe=1:2"})
+	assert.NilError(t, err)
+	assert.Equal(t, policies[Observe], Policy{Full: 1, Keep: 2})
+	assert.Equal(t, defaults[Observe], Policy{Full: 10})
+
+	_, err = ParsePolicies(nil, []string{"journal=1"})
+	assert.ErrorContains(t, err, "unknown dataset")
+	_, err = ParsePolicies(nil, []string{"state=1", "state=2"})
+	assert.ErrorContains(t, err, "duplicate")
+	_, err = ParsePolicies(nil, []string{"state"})
+	assert.ErrorContains(t, err, "expected")
+}
@@ -0,0 +1,29 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/retention"
+)
+
+func TestRetentionPolicies(t *testing.T) {
+	kc := Config{ArchiveKeepEons: 10, StateRetentionEons: 5, Retention: []string{"archive=100:1000"}}
+	policies, err := kc.retentionPolicies()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policies, retention.Policies{
+		retention.Observe: {Full: 10},
+		retention.State:   {Full: 5},
+		retention.Archive: {Full: 100, Keep: 1000},
+	})
+
+	kc.Retention = []string{"state=2:20"}
+	policies, err = kc.retentionPolicies()
+	assert.NilError(t, err)
+	assert.Equal(t, policies[retention.State], retention.Policy{Full: 2, Keep: 20})
+
+	kc = Config{Retention: []string{"archive=1"}}
+	_, err = kc.retentionPolicies()
+	assert.ErrorContains(t, err, "requires archiving")
+}
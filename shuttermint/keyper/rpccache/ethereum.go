@@ -0,0 +1,106 @@
+package rpccache
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/pkg/errors"
+)
+
+// EthereumClient is the part of the ethclient whose answers are cached.
+type EthereumClient interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// Ethereum caches the headers and blocks of final main chain blocks. A block is considered final
+// once it is at least finalityDepth blocks below the latest block the client has been asked
+// for, i.e. the keyper's MainChainFollowDistance. Other queries are passed through.
+type Ethereum struct {
+	client        EthereumClient
+	store         *Store
+	finalityDepth uint64
+
+	mux    sync.Mutex
+	latest uint64 // 0 until the latest block has been queried
+}
+
+// NewEthereum creates a caching main chain client.
+func NewEthereum(client EthereumClient, store *Store, finalityDepth uint64) *Ethereum {
+	return &Ethereum{client: client, store: store, finalityDepth: finalityDepth}
+}
+
+func (e *Ethereum) observeLatest(number *big.Int) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	if number.IsUint64() && number.Uint64() > e.latest {
+		e.latest = number.Uint64()
+	}
+}
+
+// isFinal checks if the block with the given number is final. Negative numbers and numbers
+// exceeding uint64 refer to pending or latest blocks and are never final.
+func (e *Ethereum) isFinal(number *big.Int) bool {
+	if number == nil || number.Sign() < 0 || !number.IsUint64() {
+		return false
+	}
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.latest > 0 && number.Uint64()+e.finalityDepth <= e.latest
+}
+
+// HeaderByNumber returns the header of the block with the given number, or of the latest block
+// if number is nil.
+func (e *Ethereum) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if !e.isFinal(number) {
+		header, err := e.client.HeaderByNumber(ctx, number)
+		if err == nil && number == nil {
+			e.observeLatest(header.Number)
+		}
+		return header, err
+	}
+	value, err := e.store.fetch("eth/header/"+number.String(), func() ([]byte, error) {
+		header, err := e.client.HeaderByNumber(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		return rlp.EncodeToBytes(header)
+	})
+	if err != nil {
+		return nil, err
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(value, header); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cached header of main chain block %s", number)
+	}
+	return header, nil
+}
+
+// BlockByNumber returns the block with the given number, or the latest block if number is nil.
+func (e *Ethereum) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if !e.isFinal(number) {
+		block, err := e.client.BlockByNumber(ctx, number)
+		if err == nil && number == nil {
+			e.observeLatest(block.Number())
+		}
+		return block, err
+	}
+	value, err := e.store.fetch("eth/block/"+number.String(), func() ([]byte, error) {
+		block, err := e.client.BlockByNumber(ctx, number)
+		if err != nil {
+			return nil, err
+		}
+		return rlp.EncodeToBytes(block)
+	})
+	if err != nil {
+		return nil, err
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(value, block); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cached main chain block %s", number)
+	}
+	return block, nil
+}
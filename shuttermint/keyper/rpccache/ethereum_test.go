@@ -0,0 +1,53 @@
+package rpccache
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+)
+
+type fakeEthereum struct {
+	latest  uint64
+	queries int
+}
+
+func (f *fakeEthereum) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	f.queries++
+	if number == nil {
+		number = new(big.Int).SetUint64(f.latest)
+	}
+	return &types.Header{Number: number, Difficulty: big.NewInt(1), Extra: []byte("fake")}, nil
+}
+
+func (f *fakeEthereum) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	header, _ := f.HeaderByNumber(ctx, number)
+	return types.NewBlockWithHeader(header), nil
+}
+
+func TestEthereumCachesFinalBlocks(t *testing.T) {
+	ctx := context.Background()
+	upstream := &fakeEthereum{latest: 100}
+	e := NewEthereum(upstream, NewStore(10, nil), 10)
+
+	header, err := e.HeaderByNumber(ctx, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, header.Number.Uint64(), uint64(100))
+
+	for i := 0; i < 2; i++ {
+		header, err = e.HeaderByNumber(ctx, big.NewInt(90))
+		assert.NilError(t, err)
+		assert.Equal(t, header.Number.Uint64(), uint64(90))
+		assert.Equal(t, string(header.Extra), "fake")
+
+		block, err := e.BlockByNumber(ctx, big.NewInt(90))
+		assert.NilError(t, err)
+		assert.Equal(t, block.NumberU64(), uint64(90))
+
+		_, err = e.HeaderByNumber(ctx, big.NewInt(91)) // not final yet
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, upstream.queries, 1+2+2) // latest, block 90 once, block 91 twice
+}
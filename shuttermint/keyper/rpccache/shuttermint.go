@@ -0,0 +1,131 @@
+package rpccache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/rpc/client"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// txChunkSize is the number of shuttermint blocks whose transactions are cached as one entry.
+// Aligning the entries to fixed ranges of blocks lets observers querying different, overlapping
+// ranges share them.
+const txChunkSize = 100
+
+// indexingMargin is the number of blocks below the latest committed block we consider the
+// transactions of final. Tendermint indexes transactions asynchronously after committing a block,
+// so a query for the latest blocks may miss some of their transactions.
+const indexingMargin = 2
+
+// Shuttermint is a shuttermint client caching the transactions of committed blocks. It
+// implements observe.TxFetcher, so observe.FetchTxs serves transactions from the cache. All
+// other calls are passed through.
+type Shuttermint struct {
+	client.Client
+	store *Store
+
+	mux    sync.Mutex
+	latest int64 // 0 until the latest block has been queried
+}
+
+var _ observe.TxFetcher = (*Shuttermint)(nil)
+
+// NewShuttermint creates a caching shuttermint client.
+func NewShuttermint(cl client.Client, store *Store) *Shuttermint {
+	return &Shuttermint{Client: cl, store: store}
+}
+
+func (s *Shuttermint) observeLatest(height int64) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if height > s.latest {
+		s.latest = height
+	}
+}
+
+// finalHeight returns the height of the last block whose transactions can be cached.
+func (s *Shuttermint) finalHeight() int64 {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.latest - indexingMargin
+}
+
+// Block returns the block at the given height, or the latest block if height is nil.
+func (s *Shuttermint) Block(ctx context.Context, height *int64) (*rpctypes.ResultBlock, error) {
+	res, err := s.Client.Block(ctx, height)
+	if err == nil && height == nil && res.Block != nil {
+		s.observeLatest(res.Block.Height)
+	}
+	return res, err
+}
+
+// Status returns the status of the node.
+func (s *Shuttermint) Status(ctx context.Context) (*rpctypes.ResultStatus, error) {
+	res, err := s.Client.Status(ctx)
+	if err == nil {
+		s.observeLatest(res.SyncInfo.LatestBlockHeight)
+	}
+	return res, err
+}
+
+// FetchTxs calls handler for the transactions in the given range of blocks in order. The
+// transactions of final blocks are cached in chunks of txChunkSize blocks, the ones of later
+// blocks are always fetched from the node.
+func (s *Shuttermint) FetchTxs(
+	ctx context.Context, fromHeight, toHeight int64, handler func(tx *rpctypes.ResultTx),
+) error {
+	if fromHeight < 1 {
+		fromHeight = 1
+	}
+	final := s.finalHeight()
+	height := fromHeight
+	for height <= toHeight {
+		start := height - (height-1)%txChunkSize
+		end := start + txChunkSize - 1
+		if end > final {
+			break
+		}
+		txs, err := s.chunk(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			if tx.Height >= height && tx.Height <= toHeight {
+				handler(tx)
+			}
+		}
+		height = end + 1
+	}
+	if height > toHeight {
+		return nil
+	}
+	return observe.FetchTxs(ctx, s.Client, height, toHeight, handler)
+}
+
+// chunk returns the transactions of the given final blocks.
+func (s *Shuttermint) chunk(ctx context.Context, start, end int64) ([]*rpctypes.ResultTx, error) {
+	value, err := s.store.fetch(fmt.Sprintf("shm/txs/%d", start), func() ([]byte, error) {
+		txs := []*rpctypes.ResultTx{}
+		err := observe.FetchTxs(ctx, s.Client, start, end, func(tx *rpctypes.ResultTx) {
+			txs = append(txs, tx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return tmjson.Marshal(txs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var txs []*rpctypes.ResultTx
+	if err := tmjson.Unmarshal(value, &txs); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode cached shuttermint txs of blocks %d..%d", start, end)
+	}
+	return txs, nil
+}
@@ -0,0 +1,79 @@
+package rpccache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/tendermint/tendermint/rpc/client"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"gotest.tools/v3/assert"
+)
+
+// fakeShuttermint serves one transaction per block.
+type fakeShuttermint struct {
+	client.Client
+	height  int64
+	queries []string
+}
+
+func (f *fakeShuttermint) Block(ctx context.Context, height *int64) (*rpctypes.ResultBlock, error) {
+	return &rpctypes.ResultBlock{Block: &tmtypes.Block{Header: tmtypes.Header{Height: f.height}}}, nil
+}
+
+func (f *fakeShuttermint) TxSearch(
+	ctx context.Context, query string, prove bool, page, perPage *int, orderBy string,
+) (*rpctypes.ResultTxSearch, error) {
+	f.queries = append(f.queries, query)
+	var from, to int64
+	if _, err := fmt.Sscanf(query, "tx.height >= %d and tx.height <= %d", &from, &to); err != nil {
+		return nil, err
+	}
+	res := &rpctypes.ResultTxSearch{TotalCount: int(to - from + 1)}
+	first := from + int64((*page-1)**perPage)
+	for h := first; h <= to && h < first+int64(*perPage); h++ {
+		res.Txs = append(res.Txs, &rpctypes.ResultTx{Height: h, Tx: tmtypes.Tx(fmt.Sprint(h))})
+	}
+	return res, nil
+}
+
+func fetchHeights(t *testing.T, s *Shuttermint, from, to int64) []int64 {
+	t.Helper()
+	var heights []int64
+	err := s.FetchTxs(context.Background(), from, to, func(tx *rpctypes.ResultTx) {
+		assert.Equal(t, string(tx.Tx), fmt.Sprint(tx.Height))
+		heights = append(heights, tx.Height)
+	})
+	assert.NilError(t, err)
+	return heights
+}
+
+func TestShuttermintFetchTxs(t *testing.T) {
+	upstream := &fakeShuttermint{height: 250}
+	s := NewShuttermint(upstream, NewStore(10, nil))
+
+	// nothing is cached before the latest height is known
+	assert.Equal(t, len(fetchHeights(t, s, 1, 10)), 10)
+	assert.Equal(t, len(upstream.queries), 1)
+	_, err := s.Block(context.Background(), nil)
+	assert.NilError(t, err)
+
+	upstream.queries = nil
+	heights := fetchHeights(t, s, 50, 249)
+	assert.Equal(t, len(heights), 200)
+	assert.Equal(t, heights[0], int64(50))
+	assert.Equal(t, heights[199], int64(249))
+	// blocks 1..200 are fetched as two cached chunks, the rest is passed through
+	assert.DeepEqual(t, upstream.queries, []string{
+		"tx.height >= 1 and tx.height <= 100",
+		"tx.height >= 101 and tx.height <= 200",
+		"tx.height >= 201 and tx.height <= 249",
+	})
+
+	upstream.queries = nil
+	heights = fetchHeights(t, s, 120, 130)
+	assert.Equal(t, len(heights), 11)
+	assert.Equal(t, heights[0], int64(120))
+	assert.Equal(t, len(upstream.queries), 0)
+}
@@ -0,0 +1,162 @@
+// Package rpccache caches the answers of main chain and shuttermint RPC queries about data that
+// can't change anymore, i.e. the headers and blocks of final main chain blocks and the
+// transactions of committed shuttermint blocks. The observers and APIs of a keyper share a single
+// cache, so that overlapping queries only hit the nodes once. Entries are kept in memory and
+// optionally on disk, so that they survive restarts.
+package rpccache
+
+import (
+	"container/list"
+	"log"
+	"sync"
+
+	"github.com/pkg/errors"
+	tmdb "github.com/tendermint/tm-db"
+	"golang.org/x/sync/singleflight"
+)
+
+// Store holds the cached entries. It keeps the most recently used ones in memory and, if it has
+// a database, all of them on disk.
+type Store struct {
+	mux     sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List // of *entry, most recently used first
+	db      tmdb.DB    // nil if entries are kept in memory only
+	group   singleflight.Group
+	stats   Stats
+}
+
+type entry struct {
+	key   string
+	value []byte
+}
+
+// Stats counts the lookups in a store.
+type Stats struct {
+	Hits   uint64 // served from memory or disk
+	Misses uint64 // fetched from a node
+	Shared uint64 // misses whose fetch was shared with concurrent lookups of the same entry
+}
+
+// NewStore creates a store keeping up to size entries in memory. db may be nil to keep entries
+// in memory only.
+func NewStore(size int, db tmdb.DB) *Store {
+	return &Store{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+		db:      db,
+	}
+}
+
+// Open creates a store keeping up to size entries in memory and all of them in a database in
+// the given directory.
+func Open(size int, dir string) (*Store, error) {
+	db, err := tmdb.NewGoLevelDB("rpccache", dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open rpc cache in %s", dir)
+	}
+	return NewStore(size, db), nil
+}
+
+// Close closes the underlying database, if any.
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Stats returns the lookup counters.
+func (s *Store) Stats() Stats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.stats
+}
+
+// get looks up the entry in memory and then on disk. Errors reading the database are logged and
+// treated as misses, as the entry can be fetched again.
+func (s *Store) get(key string) ([]byte, bool) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.lru.MoveToFront(el)
+		return el.Value.(*entry).value, true
+	}
+	if s.db == nil {
+		return nil, false
+	}
+	value, err := s.db.Get([]byte(key))
+	if err != nil {
+		log.Printf("Warning: failed to read rpc cache entry %s: %s", key, err)
+		return nil, false
+	}
+	if value == nil {
+		return nil, false
+	}
+	s.remember(key, value)
+	return value, true
+}
+
+// put stores the entry in memory and on disk.
+func (s *Store) put(key string, value []byte) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.remember(key, value)
+	if s.db == nil {
+		return
+	}
+	if err := s.db.Set([]byte(key), value); err != nil {
+		log.Printf("Warning: failed to write rpc cache entry %s: %s", key, err)
+	}
+}
+
+// remember adds the entry to the in-memory part of the store, evicting the least recently used
+// one if it is full. The caller must hold the lock.
+func (s *Store) remember(key string, value []byte) {
+	if el, ok := s.entries[key]; ok {
+		el.Value.(*entry).value = value
+		s.lru.MoveToFront(el)
+		return
+	}
+	s.entries[key] = s.lru.PushFront(&entry{key: key, value: value})
+	for s.lru.Len() > s.size {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*entry).key)
+	}
+}
+
+func (s *Store) count(f func(*Stats)) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	f(&s.stats)
+}
+
+// fetch returns the cached entry or calls f to fetch it and caches the result. Concurrent calls
+// for the same key share a single call of f.
+func (s *Store) fetch(key string, f func() ([]byte, error)) ([]byte, error) {
+	if value, ok := s.get(key); ok {
+		s.count(func(stats *Stats) { stats.Hits++ })
+		return value, nil
+	}
+	value, err, shared := s.group.Do(key, func() (interface{}, error) {
+		value, err := f()
+		if err != nil {
+			return nil, err
+		}
+		s.put(key, value)
+		return value, nil
+	})
+	s.count(func(stats *Stats) {
+		stats.Misses++
+		if shared {
+			stats.Shared++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]byte), nil
+}
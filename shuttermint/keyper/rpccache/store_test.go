@@ -0,0 +1,74 @@
+package rpccache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	tmdb "github.com/tendermint/tm-db"
+	"gotest.tools/v3/assert"
+)
+
+func TestStoreEviction(t *testing.T) {
+	s := NewStore(2, nil)
+	s.put("a", []byte("1"))
+	s.put("b", []byte("2"))
+	_, ok := s.get("a") // a is now used more recently than b
+	assert.Assert(t, ok)
+	s.put("c", []byte("3"))
+
+	_, ok = s.get("b")
+	assert.Assert(t, !ok)
+	v, ok := s.get("a")
+	assert.Assert(t, ok)
+	assert.Equal(t, string(v), "1")
+	_, ok = s.get("c")
+	assert.Assert(t, ok)
+}
+
+func TestStoreOnDisk(t *testing.T) {
+	db := tmdb.NewMemDB()
+	s := NewStore(1, db)
+	s.put("a", []byte("1"))
+	s.put("b", []byte("2"))
+
+	// evicted entries and entries of earlier runs are read from disk
+	v, ok := s.get("a")
+	assert.Assert(t, ok)
+	assert.Equal(t, string(v), "1")
+	v, ok = NewStore(1, db).get("b")
+	assert.Assert(t, ok)
+	assert.Equal(t, string(v), "2")
+}
+
+func TestStoreFetch(t *testing.T) {
+	s := NewStore(10, nil)
+	calls := 0
+	release := make(chan struct{})
+	fetch := func() ([]byte, error) {
+		calls++
+		<-release
+		return []byte("value"), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := s.fetch("key", fetch)
+			assert.NilError(t, err)
+			assert.Equal(t, string(v), "value")
+		}()
+	}
+	time.Sleep(10 * time.Millisecond) // let the lookups wait for the first fetch
+	close(release)
+	wg.Wait()
+	assert.Equal(t, calls, 1)
+
+	v, err := s.fetch("key", fetch)
+	assert.NilError(t, err)
+	assert.Equal(t, string(v), "value")
+	assert.Equal(t, calls, 1)
+	assert.Assert(t, s.Stats().Hits > 0)
+}
@@ -0,0 +1,65 @@
+package keyper
+
+import (
+	"bytes"
+	"crypto/rand"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+)
+
+// selfTestNamespace is the namespace of the epoch the self test of new key material encrypts
+// to. It's not used for any batch, so our share for it doesn't reveal anything about real epochs.
+const selfTestNamespace = "keyper-self-test"
+
+// selfTestEpochKG runs the decryption path with the key material of a freshly finalized DKG,
+// before real batches depend on it: it encrypts a random message to a test epoch with the eon
+// public key and the eon's version of the encryption scheme, computes our share of the epoch secret key, and verifies the share against our eon
+// public key share. If the threshold is one, our share is the epoch secret key, so we decrypt
+// the message as well. Otherwise, the shares of other keypers would be required for that.
+func selfTestEpochKG(ekg *epochkg.EpochKG, version uint8) error {
+	if ekg.Keyper >= uint64(len(ekg.PublicKeyShares)) {
+		return errors.Errorf("have %d eon public key shares, but our index is %d", len(ekg.PublicKeyShares), ekg.Keyper)
+	}
+	publicKeyShare := ekg.PublicKeyShares[ekg.Keyper]
+	if !shcrypto.VerifyEonSecretKeyShare(ekg.SecretKeyShare, publicKeyShare) {
+		return errors.New("eon secret key share does not match our eon public key share")
+	}
+
+	message := make([]byte, 32)
+	if _, err := rand.Read(message); err != nil {
+		return errors.Wrap(err, "failed to generate test message")
+	}
+	epoch := ekg.Eon // any epoch does, the namespace keeps it apart from the real ones
+	epochID := shcrypto.ComputeNamespacedEpochID(selfTestNamespace, epoch)
+	m, err := shcrypto.EncryptWithVersion(version, message, ekg.PublicKey, epochID, rand.Reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt test message")
+	}
+	encrypted := &shcrypto.EncryptedMessage{}
+	if err := encrypted.Unmarshal(m.Marshal()); err != nil {
+		return errors.Wrap(err, "failed to decode test message encrypted with the eon public key")
+	}
+
+	share := ekg.ComputeNamespacedEpochSecretKeyShare(selfTestNamespace, epoch)
+	if !shcrypto.VerifyEpochSecretKeyShare(share, publicKeyShare, epochID) {
+		return errors.New("epoch secret key share does not match our eon public key share")
+	}
+	if ekg.Threshold != 1 {
+		return nil
+	}
+	key, err := shcrypto.ComputeEpochSecretKey([]int{int(ekg.Keyper)}, []*shcrypto.EpochSecretKeyShare{share}, 1)
+	if err != nil {
+		return errors.Wrap(err, "failed to compute epoch secret key")
+	}
+	decrypted, err := encrypted.Decrypt(key)
+	if err != nil {
+		return errors.Wrap(err, "failed to decrypt test message")
+	}
+	if !bytes.Equal(decrypted, message) {
+		return errors.New("decrypted test message differs from the encrypted one")
+	}
+	return nil
+}
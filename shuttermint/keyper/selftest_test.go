@@ -0,0 +1,29 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+func TestSelfTestEpochKG(t *testing.T) {
+	for v := shcrypto.LegacyVersion; v <= shcrypto.LatestVersion; v++ {
+		assert.NilError(t, selfTestEpochKG(singleKeyperEpochKG(t, 1), v))
+	}
+	assert.ErrorContains(t, selfTestEpochKG(singleKeyperEpochKG(t, 1), shcrypto.LatestVersion+1), "unknown encryption version")
+
+	other := singleKeyperEpochKG(t, 2)
+	ekg := singleKeyperEpochKG(t, 1)
+	ekg.SecretKeyShare = other.SecretKeyShare
+	assert.ErrorContains(t, selfTestEpochKG(ekg, shcrypto.LatestVersion), "eon secret key share does not match")
+
+	ekg = singleKeyperEpochKG(t, 1)
+	ekg.PublicKey = other.PublicKey
+	assert.ErrorContains(t, selfTestEpochKG(ekg, shcrypto.LatestVersion), "test message")
+
+	ekg = singleKeyperEpochKG(t, 1)
+	ekg.Keyper = 1
+	assert.ErrorContains(t, selfTestEpochKG(ekg, shcrypto.LatestVersion), "our index is 1")
+}
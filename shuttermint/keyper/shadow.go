@@ -0,0 +1,91 @@
+package keyper
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+)
+
+// DecideFunc runs one decision step, filling in the decider's Actions. (*Decider).Decide is the
+// stable implementation.
+type DecideFunc func(dcdr *Decider)
+
+// Shadow mode: if Keyper.Candidate is set, each step additionally runs the candidate decision
+// logic on a copy of the state and the same observed world. Only the stable decider's actions are
+// run and only its state is kept, the candidate's actions are merely compared to them and
+// divergences are logged. This allows trying out changes to the decision logic on live keypers
+// without risking their behavior.
+
+// cloneState returns a deep copy of the given state.
+func cloneState(st *State) (*State, error) {
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(st); err != nil {
+		return nil, err
+	}
+	clone := new(State)
+	if err := gob.NewDecoder(buf).Decode(clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// decideShadow runs the candidate decision logic on a copy of the given decider, which must not
+// have run yet. It returns nil if the candidate fails.
+func decideShadow(candidate DecideFunc, stable Decider) (shadow *Decider) {
+	st, err := cloneState(stable.State)
+	if err != nil {
+		log.Printf("Error: failed to copy the state for the candidate decider: %+v", err)
+		return nil
+	}
+	shadow = &stable
+	shadow.State = st
+	shadow.Actions = []fx.IAction{}
+	shadow.Latency = nil // the candidate's key releases don't happen
+	shadow.Trace = nil
+	shadow.Metrics = nil
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Error: candidate decider panicked: %v", r)
+			shadow = nil
+		}
+	}()
+	candidate(shadow)
+	return shadow
+}
+
+func describeActions(actions []fx.IAction) []string {
+	s := make([]string, len(actions))
+	for i, a := range actions {
+		s[i] = fmt.Sprint(a)
+	}
+	return s
+}
+
+// compareShadow logs if the candidate decider came up with different actions than the stable one.
+// It returns true if they diverged.
+func compareShadow(stable, shadow *Decider) bool {
+	want := describeActions(stable.Actions)
+	got := describeActions(shadow.Actions)
+	if reflect.DeepEqual(want, got) {
+		return false
+	}
+	log.Printf(
+		"Candidate decider diverged at shuttermint height %d, main chain block %d:\n  stable:    %s\n  candidate: %s",
+		stable.Shutter.CurrentBlock, stable.MainChain.CurrentBlock,
+		strings.Join(want, "\n             "), strings.Join(got, "\n             "),
+	)
+	return true
+}
+
+func (kpr *Keyper) shadowinfo() string {
+	if kpr.Candidate == nil {
+		return ""
+	}
+	return fmt.Sprintf(", candidate diverged in %d steps", kpr.divergences)
+}
@@ -0,0 +1,36 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestShadowDecider(t *testing.T) {
+	stable := Decider{
+		State:     NewState(),
+		Shutter:   observe.NewShutter(),
+		MainChain: observe.NewMainChain(0),
+		Actions:   []fx.IAction{},
+	}
+	stable.State.LastEonStarted = 1
+
+	shadow := decideShadow(func(dcdr *Decider) {
+		dcdr.State.LastEonStarted = 2
+		dcdr.addAction(&fx.Accuse{HalfStep: 3})
+	}, stable)
+	assert.Assert(t, shadow != nil)
+	assert.Equal(t, shadow.State.LastEonStarted, uint64(2))
+	assert.Equal(t, stable.State.LastEonStarted, uint64(1)) // the candidate works on a copy
+	assert.Equal(t, len(stable.Actions), 0)
+	assert.Assert(t, compareShadow(&stable, shadow))
+
+	stable.addAction(&fx.Accuse{HalfStep: 3})
+	assert.Assert(t, !compareShadow(&stable, shadow))
+
+	shadow = decideShadow(func(dcdr *Decider) { panic("bug") }, stable)
+	assert.Assert(t, shadow == nil)
+}
@@ -0,0 +1,57 @@
+package keyper
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/sharebackup"
+)
+
+// shareBackupDir returns the directory the key share backups are exported to.
+func (kc Config) shareBackupDir() string {
+	if kc.ShareBackupDir != "" {
+		return kc.ShareBackupDir
+	}
+	return filepath.Join(kc.DBDir, "share-backups")
+}
+
+// backupShares exports the backups of the eon secret key shares generated since the last step to
+// the custodians, see sharebackup. Eons whose backups have been exported before, e.g. before a
+// restart, are skipped. If exporting fails, it's tried again in the next step.
+func (kpr *Keyper) backupShares() {
+	if !kpr.shareBackup.Enabled() {
+		return
+	}
+	if kpr.backedUpEons == nil {
+		kpr.backedUpEons = make(map[uint64]bool)
+	}
+	dir := kpr.Config.shareBackupDir()
+	for _, ekg := range kpr.State.EKGs {
+		if kpr.backedUpEons[ekg.Eon] {
+			continue
+		}
+		if sharebackup.Exported(dir, ekg.Eon) {
+			kpr.backedUpEons[ekg.Eon] = true
+			continue
+		}
+		ekgs := ekg.EpochKG
+		if ekgs == nil || ekgs.SecretKeyShare == nil || uint64(len(ekgs.PublicKeyShares)) <= ekgs.Keyper {
+			continue
+		}
+		backups, err := sharebackup.New(
+			ekg.Eon, kpr.Config.Address(), ekgs.SecretKeyShare, ekgs.PublicKeyShares[ekgs.Keyper], kpr.shareBackup,
+		)
+		if err == nil {
+			err = sharebackup.Export(dir, backups)
+		}
+		if err != nil {
+			log.Printf("Error: failed to back up our key share of eon %d: %+v", ekg.Eon, err)
+			continue
+		}
+		kpr.backedUpEons[ekg.Eon] = true
+		log.Printf(
+			"Exported the backup of our key share of eon %d to %d custodians in %s, copy it off this host",
+			ekg.Eon, len(backups), dir,
+		)
+	}
+}
@@ -0,0 +1,275 @@
+// Package sharebackup backs up a keyper's eon secret key shares to a set of custodians controlled
+// by the operator. The share is split again with Shamir's scheme, so that any threshold of the
+// custodians can recover it, while fewer learn nothing about it. Each custodian's part is encrypted
+// to the custodian's public key, so the backups can be shipped off the keyper's host without
+// further protection. Losing the host then doesn't destroy our ability to help decrypting the
+// batches of old eons.
+package sharebackup
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// backupVersion is the version of the Backup format. Recovering refuses backups of other
+// versions.
+const backupVersion = 1
+
+// Custodians are the public keys backups are encrypted to, and the number of them needed to
+// recover a share.
+type Custodians struct {
+	Keys      []*ecdsa.PublicKey
+	Threshold uint64
+}
+
+// ParseCustodians parses the hex encoded secp256k1 public keys of the custodians, compressed or
+// not. The threshold must be between 1 and the number of custodians.
+func ParseCustodians(keys []string, threshold uint64) (Custodians, error) {
+	c := Custodians{Threshold: threshold}
+	seen := make(map[common.Address]bool)
+	for _, s := range keys {
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return Custodians{}, errors.Errorf("invalid custodian key %q, must be hex encoded", s)
+		}
+		var key *ecdsa.PublicKey
+		if len(b) == 33 {
+			key, err = crypto.DecompressPubkey(b)
+		} else {
+			key, err = crypto.UnmarshalPubkey(b)
+		}
+		if err != nil {
+			return Custodians{}, errors.Wrapf(err, "invalid custodian key %q", s)
+		}
+		address := crypto.PubkeyToAddress(*key)
+		if seen[address] {
+			return Custodians{}, errors.Errorf("duplicate custodian key %q", s)
+		}
+		seen[address] = true
+		c.Keys = append(c.Keys, key)
+	}
+	if len(c.Keys) > 0 && (threshold == 0 || threshold > uint64(len(c.Keys))) {
+		return Custodians{}, errors.Errorf(
+			"invalid custodian threshold %d, must be between 1 and the number of custodians (%d)",
+			threshold, len(c.Keys))
+	}
+	return c, nil
+}
+
+// Enabled checks if there are any custodians to back up to.
+func (c Custodians) Enabled() bool {
+	return len(c.Keys) > 0
+}
+
+// Backup is a custodian's part of our eon secret key share for an eon.
+type Backup struct {
+	Version   int
+	Eon       uint64
+	Keyper    common.Address
+	Threshold uint64 // number of custodians needed to recover the share
+	// PublicKeyShare is the keyper's eon public key share, the recovered share is checked against
+	// it
+	PublicKeyShare hexutil.Bytes
+	Index          uint64         // the custodian's index, starting at 0
+	Custodian      common.Address // address of the custodian's key
+	EncryptedPart  hexutil.Bytes  // the custodian's part of the share, encrypted to its key
+}
+
+// Split splits secret into n parts with Shamir's scheme, so that any threshold of them recover
+// it. Part i is the evaluation of a random polynomial of degree threshold-1 at x=i+1.
+func Split(secret *big.Int, threshold, n uint64) ([]*big.Int, error) {
+	if threshold == 0 || threshold > n {
+		return nil, errors.Errorf("invalid threshold %d for %d parts", threshold, n)
+	}
+	poly, err := shcrypto.RandomPolynomial(rand.Reader, threshold-1)
+	if err != nil {
+		return nil, err
+	}
+	defer poly.Zero()
+	(*poly)[0].Set(secret)
+	parts := make([]*big.Int, n)
+	for i := range parts {
+		parts[i] = poly.EvalForKeyper(i)
+	}
+	return parts, nil
+}
+
+// Part is a part of a share, as returned by Split, with its index.
+type Part struct {
+	Index uint64
+	Value *big.Int
+}
+
+// Combine recovers the secret from the given parts with Lagrange interpolation at 0.
+func Combine(parts []Part) (*big.Int, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("no parts given")
+	}
+	secret := new(big.Int)
+	for i, p := range parts {
+		xi := big.NewInt(int64(p.Index) + 1)
+		num, den := big.NewInt(1), big.NewInt(1)
+		for j, q := range parts {
+			if i == j {
+				continue
+			}
+			if q.Index == p.Index {
+				return nil, errors.Errorf("part %d given twice", p.Index)
+			}
+			xj := big.NewInt(int64(q.Index) + 1)
+			num.Mul(num, xj)
+			num.Mod(num, bn256.Order)
+			den.Mul(den, new(big.Int).Sub(xj, xi))
+			den.Mod(den, bn256.Order)
+		}
+		coefficient := num.Mul(num, den.ModInverse(den, bn256.Order))
+		secret.Add(secret, coefficient.Mul(coefficient, p.Value))
+		secret.Mod(secret, bn256.Order)
+	}
+	return secret, nil
+}
+
+// New splits the eon secret key share of the given keyper among the custodians and encrypts the
+// parts, returning one backup per custodian.
+func New(
+	eon uint64,
+	keyper common.Address,
+	share *shcrypto.EonSecretKeyShare,
+	publicKeyShare *shcrypto.EonPublicKeyShare,
+	custodians Custodians,
+) ([]*Backup, error) {
+	parts, err := Split((*big.Int)(share), custodians.Threshold, uint64(len(custodians.Keys)))
+	if err != nil {
+		return nil, err
+	}
+	pks, err := publicKeyShare.GobEncode()
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]*Backup, len(parts))
+	for i, part := range parts {
+		plain := common.BigToHash(part)
+		encrypted, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(custodians.Keys[i]), plain.Bytes(), nil, nil)
+		part.SetInt64(0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encrypt part %d of the eon secret key share", i)
+		}
+		backups[i] = &Backup{
+			Version:        backupVersion,
+			Eon:            eon,
+			Keyper:         keyper,
+			Threshold:      custodians.Threshold,
+			PublicKeyShare: pks,
+			Index:          uint64(i),
+			Custodian:      crypto.PubkeyToAddress(*custodians.Keys[i]),
+			EncryptedPart:  encrypted,
+		}
+	}
+	return backups, nil
+}
+
+// Decrypt decrypts the custodian's part of the share with the custodian's key.
+func (b *Backup) Decrypt(key *ecdsa.PrivateKey) (Part, error) {
+	if b.Version != backupVersion {
+		return Part{}, errors.Errorf("unsupported backup version %d, expected %d", b.Version, backupVersion)
+	}
+	if crypto.PubkeyToAddress(key.PublicKey) != b.Custodian {
+		return Part{}, errors.Errorf("backup %d of eon %d is for custodian %s", b.Index, b.Eon, b.Custodian.Hex())
+	}
+	plain, err := ecies.ImportECDSA(key).Decrypt(b.EncryptedPart, nil, nil)
+	if err != nil {
+		return Part{}, errors.Wrapf(err, "failed to decrypt backup %d of eon %d", b.Index, b.Eon)
+	}
+	return Part{Index: b.Index, Value: new(big.Int).SetBytes(plain)}, nil
+}
+
+// Recover decrypts the given backups of a share with the custodians' keys and combines them. At
+// least a threshold of backups of the same eon and keyper is required, a backup whose custodian's
+// key is missing is skipped. The recovered share is checked against the public key share.
+func Recover(backups []*Backup, keys []*ecdsa.PrivateKey) (*shcrypto.EonSecretKeyShare, error) {
+	if len(backups) == 0 {
+		return nil, errors.New("no backups given")
+	}
+	keysByAddress := make(map[common.Address]*ecdsa.PrivateKey)
+	for _, key := range keys {
+		keysByAddress[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
+	first := backups[0]
+	var parts []Part
+	for _, b := range backups {
+		if b.Eon != first.Eon || b.Keyper != first.Keyper {
+			return nil, errors.Errorf("backups of different shares given, eon %d of %s and eon %d of %s",
+				first.Eon, first.Keyper.Hex(), b.Eon, b.Keyper.Hex())
+		}
+		key, ok := keysByAddress[b.Custodian]
+		if !ok {
+			continue
+		}
+		part, err := b.Decrypt(key)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+	if uint64(len(parts)) < first.Threshold {
+		return nil, errors.Errorf("%d of %d required backups could be decrypted", len(parts), first.Threshold)
+	}
+	secret, err := Combine(parts)
+	if err != nil {
+		return nil, err
+	}
+	share := (*shcrypto.EonSecretKeyShare)(secret)
+	publicKeyShare := new(shcrypto.EonPublicKeyShare)
+	if err := publicKeyShare.GobDecode(first.PublicKeyShare); err != nil {
+		return nil, errors.Wrap(err, "invalid public key share")
+	}
+	if !shcrypto.VerifyEonSecretKeyShare(share, publicKeyShare) {
+		return nil, errors.New("the recovered share doesn't match the public key share")
+	}
+	return share, nil
+}
+
+// FileName returns the name of the file the backup of a custodian is exported to.
+func FileName(eon uint64, index uint64) string {
+	return fmt.Sprintf("eon-%d-custodian-%d.json", eon, index)
+}
+
+// Exported checks if the backups of the given eon have been exported to dir.
+func Exported(dir string, eon uint64) bool {
+	_, err := os.Stat(filepath.Join(dir, FileName(eon, 0)))
+	return err == nil
+}
+
+// Export writes the backups to dir, one file per custodian. The first custodian's file is
+// written last, so that Exported only reports complete exports.
+func Export(dir string, backups []*Backup) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	for i := len(backups) - 1; i >= 0; i-- {
+		b := backups[i]
+		data, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, FileName(b.Eon, b.Index)), data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sharebackup
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret, err := rand.Int(rand.Reader, bn256.Order)
+	assert.NilError(t, err)
+	parts, err := Split(secret, 3, 5)
+	assert.NilError(t, err)
+	assert.Equal(t, len(parts), 5)
+
+	combined, err := Combine([]Part{{4, parts[4]}, {0, parts[0]}, {2, parts[2]}})
+	assert.NilError(t, err)
+	assert.Equal(t, combined.Cmp(secret), 0)
+	combined, err = Combine([]Part{{1, parts[1]}, {3, parts[3]}})
+	assert.NilError(t, err)
+	assert.Assert(t, combined.Cmp(secret) != 0)
+
+	_, err = Combine([]Part{{1, parts[1]}, {1, parts[1]}})
+	assert.ErrorContains(t, err, "given twice")
+	_, err = Split(secret, 6, 5)
+	assert.ErrorContains(t, err, "invalid threshold")
+}
+
+func TestParseCustodians(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	uncompressed := hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey))
+	compressed := hexutil.Encode(crypto.CompressPubkey(&key.PublicKey))
+
+	c, err := ParseCustodians([]string{compressed}, 1)
+	assert.NilError(t, err)
+	assert.Assert(t, c.Enabled())
+	assert.Equal(t, crypto.PubkeyToAddress(*c.Keys[0]), crypto.PubkeyToAddress(key.PublicKey))
+
+	c, err = ParseCustodians(nil, 0)
+	assert.NilError(t, err)
+	assert.Assert(t, !c.Enabled())
+
+	_, err = ParseCustodians([]string{uncompressed, compressed}, 1)
+	assert.ErrorContains(t, err, "duplicate custodian key")
+	_, err = ParseCustodians([]string{uncompressed}, 2)
+	assert.ErrorContains(t, err, "invalid custodian threshold")
+	_, err = ParseCustodians([]string{"0x1234"}, 1)
+	assert.ErrorContains(t, err, "invalid custodian key")
+}
+
+func TestBackupRecover(t *testing.T) {
+	var keys []*ecdsa.PrivateKey
+	custodians := Custodians{Threshold: 2}
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		assert.NilError(t, err)
+		keys = append(keys, key)
+		custodians.Keys = append(custodians.Keys, &key.PublicKey)
+	}
+	secret, err := rand.Int(rand.Reader, bn256.Order)
+	assert.NilError(t, err)
+	share := (*shcrypto.EonSecretKeyShare)(secret)
+	publicKeyShare := (*shcrypto.EonPublicKeyShare)(new(bn256.G2).ScalarBaseMult(secret))
+	keyper := common.BigToAddress(big.NewInt(7))
+
+	backups, err := New(5, keyper, share, publicKeyShare, custodians)
+	assert.NilError(t, err)
+	assert.Equal(t, len(backups), 3)
+	assert.Equal(t, backups[1].Custodian, crypto.PubkeyToAddress(keys[1].PublicKey))
+
+	recovered, err := Recover(backups, keys[1:])
+	assert.NilError(t, err)
+	assert.Assert(t, recovered.Equal(share))
+	recovered, err = Recover(backups[:2], keys)
+	assert.NilError(t, err)
+	assert.Assert(t, recovered.Equal(share))
+
+	_, err = Recover(backups, keys[:1])
+	assert.ErrorContains(t, err, "1 of 2 required backups")
+	_, err = backups[0].Decrypt(keys[1])
+	assert.ErrorContains(t, err, "is for custodian")
+
+	other, err := New(6, keyper, share, publicKeyShare, custodians)
+	assert.NilError(t, err)
+	_, err = Recover([]*Backup{backups[0], other[1]}, keys)
+	assert.ErrorContains(t, err, "backups of different shares")
+
+	dir := t.TempDir()
+	assert.Assert(t, !Exported(dir, 5))
+	assert.NilError(t, Export(dir, backups))
+	assert.Assert(t, Exported(dir, 5))
+}
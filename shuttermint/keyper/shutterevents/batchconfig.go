@@ -1,9 +1,17 @@
 package shutterevents
 
 import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"sort"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
 	"github.com/shutter-network/shutter/shuttermint/medley"
 	"github.com/shutter-network/shutter/shuttermint/shmsg"
 )
@@ -35,10 +43,97 @@ func (bc *BatchConfig) EnsureValid() error {
 	if int(bc.Threshold) > len(bc.Keypers) {
 		return errors.Errorf("threshold too high")
 	}
+	if bc.CommitteeSize != 0 && bc.Threshold > bc.CommitteeSize {
+		return errors.Errorf("threshold higher than committee size")
+	}
+	if bc.PerBlockEpochs && (bc.EpochOffset != 0 || bc.EpochStride > 1) {
+		return errors.Errorf("epoch offset and stride cannot be used with per-block epochs")
+	}
+	if bc.StartBatchIndex > (math.MaxUint64-bc.EpochOffset)/bc.epochStride() {
+		return errors.Errorf("epoch of start batch overflows")
+	}
+	if bc.EncryptionVersion > uint64(shcrypto.LatestVersion) {
+		return errors.Errorf("unknown encryption version %d", bc.EncryptionVersion)
+	}
 	// XXX maybe we should check for duplicate addresses
 	return nil
 }
 
+func (bc *BatchConfig) epochStride() uint64 {
+	if bc.EpochStride == 0 {
+		return 1
+	}
+	return bc.EpochStride
+}
+
+// EpochMapping returns the mapping of the config's batches to epochs.
+func (bc *BatchConfig) EpochMapping() protocol.EpochMapping {
+	return protocol.EpochMapping{Offset: bc.EpochOffset, Stride: bc.EpochStride}
+}
+
+// Epoch returns the epoch whose key is used for the given batch, i.e.
+// EpochOffset + batchIndex * EpochStride.
+func (bc *BatchConfig) Epoch(batchIndex uint64) uint64 {
+	return uint64(bc.EpochMapping().Epoch(protocol.BatchIndex(batchIndex)))
+}
+
+// BatchIndexForEpoch returns the batch whose key is generated in the given epoch. It returns false
+// if the mapping does not assign the epoch to any batch.
+func (bc *BatchConfig) BatchIndexForEpoch(epoch uint64) (uint64, bool) {
+	batchIndex, ok := bc.EpochMapping().BatchIndex(protocol.EpochIndex(epoch))
+	return uint64(batchIndex), ok
+}
+
+// EnsureEpochsFollow checks that the epochs of the config's batches come after the epochs used by
+// the previous config, so that no epoch is used for more than one batch.
+func (bc *BatchConfig) EnsureEpochsFollow(previous *BatchConfig) error {
+	if bc.PerBlockEpochs || previous.PerBlockEpochs || bc.StartBatchIndex <= previous.StartBatchIndex {
+		return nil
+	}
+	lastEpoch := previous.Epoch(bc.StartBatchIndex - 1)
+	if firstEpoch := bc.Epoch(bc.StartBatchIndex); firstEpoch <= lastEpoch {
+		return errors.Errorf(
+			"first epoch of config %d (%d) not greater than last epoch of the previous config (%d)",
+			bc.ConfigIndex,
+			firstEpoch,
+			lastEpoch,
+		)
+	}
+	return nil
+}
+
+// Committee returns the keypers taking part in the DKG of an eon whose start event carries the
+// given seed. If CommitteeSize is zero or not smaller than the number of keypers, this is the full
+// keyper set. Otherwise, CommitteeSize keypers are sampled deterministically from the seed. The
+// committee members are ordered as in Keypers.
+func (bc *BatchConfig) Committee(seed []byte) []common.Address {
+	n := len(bc.Keypers)
+	if bc.CommitteeSize == 0 || bc.CommitteeSize >= uint64(n) {
+		return bc.Keypers
+	}
+
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	// partial Fisher-Yates shuffle, drawing randomness from keccak256(seed || i)
+	for i := 0; i < int(bc.CommitteeSize); i++ {
+		var counter [8]byte
+		binary.BigEndian.PutUint64(counter[:], uint64(i))
+		r := new(big.Int).SetBytes(crypto.Keccak256(seed, counter[:]))
+		j := i + int(r.Mod(r, big.NewInt(int64(n-i))).Int64())
+		indices[i], indices[j] = indices[j], indices[i]
+	}
+	selected := indices[:bc.CommitteeSize]
+	sort.Ints(selected)
+
+	committee := make([]common.Address, len(selected))
+	for i, idx := range selected {
+		committee[i] = bc.Keypers[idx]
+	}
+	return committee
+}
+
 // BatchConfigFromMessage extracts the batch config received in a message.
 func BatchConfigFromMessage(m *shmsg.BatchConfig) (BatchConfig, error) {
 	var keypers []common.Address
@@ -62,6 +157,17 @@ func BatchConfigFromMessage(m *shmsg.BatchConfig) (BatchConfig, error) {
 	}
 	configContractAddress := common.BytesToAddress(m.ConfigContractAddress)
 
+	if err := medley.EnsureUniqueStrings(m.EpochNamespaces); err != nil {
+		return BatchConfig{}, err
+	}
+	var epochNamespaces []string
+	for _, ns := range m.EpochNamespaces {
+		if ns == "" {
+			return BatchConfig{}, errors.Errorf("epoch namespace must not be empty")
+		}
+		epochNamespaces = append(epochNamespaces, ns)
+	}
+
 	bc := BatchConfig{
 		StartBatchIndex:       m.StartBatchIndex,
 		Keypers:               keypers,
@@ -70,6 +176,12 @@ func BatchConfigFromMessage(m *shmsg.BatchConfig) (BatchConfig, error) {
 		ConfigIndex:           m.ConfigIndex,
 		Started:               m.Started,
 		ValidatorsUpdated:     m.ValidatorsUpdated,
+		EpochNamespaces:       epochNamespaces,
+		PerBlockEpochs:        m.PerBlockEpochs,
+		CommitteeSize:         m.CommitteeSize,
+		EpochOffset:           m.EpochOffset,
+		EpochStride:           m.EpochStride,
+		EncryptionVersion:     m.EncryptionVersion,
 	}
 	return bc, nil
 }
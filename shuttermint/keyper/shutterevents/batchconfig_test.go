@@ -0,0 +1,94 @@
+package shutterevents_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestCommittee(t *testing.T) {
+	var keypers []common.Address
+	for i := 0; i < 20; i++ {
+		keypers = append(keypers, common.BigToAddress(big.NewInt(int64(i+1))))
+	}
+	bc := shutterevents.BatchConfig{Keypers: keypers, Threshold: 3}
+	assert.DeepEqual(t, keypers, bc.Committee([]byte("seed")))
+
+	bc.CommitteeSize = 5
+	committee := bc.Committee([]byte("seed"))
+	assert.Equal(t, 5, len(committee))
+	assert.DeepEqual(t, committee, bc.Committee([]byte("seed")))
+
+	lastIndex := -1
+	for _, m := range committee {
+		index, ok := bc.KeyperIndex(m)
+		assert.Assert(t, ok)
+		assert.Assert(t, int(index) > lastIndex, "committee not ordered")
+		lastIndex = int(index)
+	}
+
+	different := false
+	for i := 0; i < 10 && !different; i++ {
+		other := bc.Committee([]byte{byte(i)})
+		for j := range other {
+			different = different || other[j] != committee[j]
+		}
+	}
+	assert.Assert(t, different, "committee does not depend on seed")
+
+	bc.CommitteeSize = 2
+	assert.ErrorContains(t, bc.EnsureValid(), "committee size")
+}
+
+func TestEpochMapping(t *testing.T) {
+	keypers := []common.Address{common.BigToAddress(big.NewInt(1))}
+	bc := shutterevents.BatchConfig{Keypers: keypers, Threshold: 1}
+	assert.Equal(t, bc.Epoch(7), uint64(7))
+
+	bc.EpochOffset = 100
+	bc.EpochStride = 3
+	assert.NilError(t, bc.EnsureValid())
+	assert.Equal(t, bc.Epoch(0), uint64(100))
+	assert.Equal(t, bc.Epoch(7), uint64(121))
+	batchIndex, ok := bc.BatchIndexForEpoch(121)
+	assert.Assert(t, ok)
+	assert.Equal(t, batchIndex, uint64(7))
+	_, ok = bc.BatchIndexForEpoch(122)
+	assert.Assert(t, !ok)
+	_, ok = bc.BatchIndexForEpoch(99)
+	assert.Assert(t, !ok)
+
+	bc.PerBlockEpochs = true
+	assert.ErrorContains(t, bc.EnsureValid(), "per-block")
+	bc.PerBlockEpochs = false
+	bc.StartBatchIndex = 1 << 63
+	assert.ErrorContains(t, bc.EnsureValid(), "overflows")
+}
+
+func TestEncryptionVersion(t *testing.T) {
+	keypers := []common.Address{common.BigToAddress(big.NewInt(1))}
+	bc := shutterevents.BatchConfig{Keypers: keypers, Threshold: 1, EncryptionVersion: uint64(shcrypto.LatestVersion)}
+	assert.NilError(t, bc.EnsureValid())
+	bc.EncryptionVersion++
+	assert.ErrorContains(t, bc.EnsureValid(), "encryption version")
+}
+
+func TestEnsureEpochsFollow(t *testing.T) {
+	previous := shutterevents.BatchConfig{StartBatchIndex: 10}
+	next := shutterevents.BatchConfig{StartBatchIndex: 20, ConfigIndex: 1}
+	assert.NilError(t, next.EnsureEpochsFollow(&previous))
+
+	// batch 19 used epoch 19, so batch 20 must not use an earlier one
+	next.EpochOffset = 0
+	next.EpochStride = 2
+	assert.NilError(t, next.EnsureEpochsFollow(&previous))
+	previous.EpochOffset = 30
+	assert.ErrorContains(t, next.EnsureEpochsFollow(&previous), "not greater")
+	next.EpochOffset = 10
+	assert.NilError(t, next.EnsureEpochsFollow(&previous))
+}
@@ -166,6 +166,12 @@ type BatchConfig struct {
 	ConfigContractAddress common.Address
 	Started               bool
 	ValidatorsUpdated     bool
+	EpochNamespaces       []string
+	PerBlockEpochs        bool
+	CommitteeSize         uint64 // number of keypers taking part in the DKG, 0 for all of them
+	EpochOffset           uint64 // epoch of batch 0
+	EpochStride           uint64 // number of epochs per batch, 0 for 1
+	EncryptionVersion     uint64 // version of the threshold encryption scheme, see shcrypto
 }
 
 func (bc BatchConfig) MakeABCIEvent() abcitypes.Event {
@@ -189,6 +195,12 @@ func (bc BatchConfig) MakeABCIEvent() abcitypes.Event {
 				Key:   []byte("ConfigIndex"),
 				Value: []byte(fmt.Sprintf("%d", bc.ConfigIndex)),
 			},
+			newStringsPair("EpochNamespaces", bc.EpochNamespaces),
+			newBoolPair("PerBlockEpochs", bc.PerBlockEpochs),
+			newUintPair("CommitteeSize", bc.CommitteeSize),
+			newUintPair("EpochOffset", bc.EpochOffset),
+			newUintPair("EpochStride", bc.EpochStride),
+			newUintPair("EncryptionVersion", bc.EncryptionVersion),
 		},
 	}
 }
@@ -219,12 +231,60 @@ func makeBatchConfig(ev abcitypes.Event, height int64) (*BatchConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// EpochNamespaces, PerBlockEpochs, CommitteeSize, the epoch mapping and EncryptionVersion are
+	// optional, events emitted by older versions do not contain them
+	var epochNamespaces []string
+	if len(ev.Attributes) > 4 && string(ev.Attributes[4].Key) == "EpochNamespaces" {
+		epochNamespaces, err = decodeStrings(ev.Attributes[4].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var perBlockEpochs bool
+	if len(ev.Attributes) > 5 && string(ev.Attributes[5].Key) == "PerBlockEpochs" {
+		perBlockEpochs, err = decodeBool(ev.Attributes[5].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var committeeSize uint64
+	if len(ev.Attributes) > 6 && string(ev.Attributes[6].Key) == "CommitteeSize" {
+		committeeSize, err = decodeUint64(ev.Attributes[6].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var epochOffset, epochStride uint64
+	if len(ev.Attributes) > 8 && string(ev.Attributes[7].Key) == "EpochOffset" {
+		epochOffset, err = decodeUint64(ev.Attributes[7].Value)
+		if err != nil {
+			return nil, err
+		}
+		epochStride, err = decodeUint64(ev.Attributes[8].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var encryptionVersion uint64
+	if len(ev.Attributes) > 9 && string(ev.Attributes[9].Key) == "EncryptionVersion" {
+		encryptionVersion, err = decodeUint64(ev.Attributes[9].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &BatchConfig{
-		Height:          height,
-		StartBatchIndex: startBatchIndex,
-		Threshold:       threshold,
-		Keypers:         keypers,
-		ConfigIndex:     configIndex,
+		Height:            height,
+		StartBatchIndex:   startBatchIndex,
+		Threshold:         threshold,
+		Keypers:           keypers,
+		ConfigIndex:       configIndex,
+		EpochNamespaces:   epochNamespaces,
+		PerBlockEpochs:    perBlockEpochs,
+		CommitteeSize:     committeeSize,
+		EpochOffset:       epochOffset,
+		EpochStride:       epochStride,
+		EncryptionVersion: encryptionVersion,
 	}, nil
 }
 
@@ -340,6 +400,7 @@ type EonStarted struct {
 	Height     int64
 	Eon        uint64
 	BatchIndex uint64
+	Seed       []byte // randomness used to sample the DKG committee
 }
 
 func (msg EonStarted) MakeABCIEvent() abcitypes.Event {
@@ -348,6 +409,10 @@ func (msg EonStarted) MakeABCIEvent() abcitypes.Event {
 		Attributes: []abcitypes.EventAttribute{
 			newUintPair("Eon", msg.Eon),
 			newUintPair("BatchIndex", msg.BatchIndex),
+			{
+				Key:   []byte("Seed"),
+				Value: encodeBytes(msg.Seed),
+			},
 		},
 	}
 }
@@ -455,13 +520,15 @@ func makePolyEval(ev abcitypes.Event, height int64) (*PolyEval, error) {
 	}, nil
 }
 
-// EpochSecretKeyShare represents a message containing an epoch secret key.
+// EpochSecretKeyShare represents a message containing an epoch secret key. Namespace is the epoch
+// namespace the share belongs to, it's empty for the default namespace.
 type EpochSecretKeyShare struct {
-	Height int64
-	Sender common.Address
-	Eon    uint64
-	Epoch  uint64
-	Share  *shcrypto.EpochSecretKeyShare
+	Height    int64
+	Sender    common.Address
+	Eon       uint64
+	Epoch     uint64
+	Share     *shcrypto.EpochSecretKeyShare
+	Namespace string
 }
 
 func (msg EpochSecretKeyShare) MakeABCIEvent() abcitypes.Event {
@@ -472,6 +539,10 @@ func (msg EpochSecretKeyShare) MakeABCIEvent() abcitypes.Event {
 			newUintPair("Eon", msg.Eon),
 			newUintPair("Epoch", msg.Epoch),
 			newEpochSecretKeyShare("Share", msg.Share),
+			{
+				Key:   []byte("Namespace"),
+				Value: encodeBytes([]byte(msg.Namespace)),
+			},
 		},
 	}
 }
@@ -501,12 +572,268 @@ func makeEpochSecretKeyShare(ev abcitypes.Event, height int64) (*EpochSecretKeyS
 		return nil, err
 	}
 
+	// Namespace is optional, events emitted by older versions do not contain it
+	var namespace []byte
+	if len(ev.Attributes) > 4 && string(ev.Attributes[4].Key) == "Namespace" {
+		namespace, err = decodeBytes(ev.Attributes[4].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &EpochSecretKeyShare{
+		Height:    height,
+		Sender:    sender,
+		Eon:       eon,
+		Epoch:     epoch,
+		Share:     share,
+		Namespace: string(namespace),
+	}, nil
+}
+
+// DirectMessage represents an encrypted message from one keyper to another. Topic tells the
+// receiver which sub-protocol the payload belongs to.
+type DirectMessage struct {
+	Height           int64
+	Sender           common.Address
+	Receiver         common.Address
+	Topic            string
+	EncryptedPayload []byte
+}
+
+func (msg DirectMessage) MakeABCIEvent() abcitypes.Event {
+	return abcitypes.Event{
+		Type: evtype.DirectMessage,
+		Attributes: []abcitypes.EventAttribute{
+			newAddressPair("Sender", msg.Sender),
+			newAddressPair("Receiver", msg.Receiver),
+			{
+				Key:   []byte("Topic"),
+				Value: encodeBytes([]byte(msg.Topic)),
+			},
+			{
+				Key:   []byte("EncryptedPayload"),
+				Value: encodeBytes(msg.EncryptedPayload),
+			},
+		},
+	}
+}
+
+func makeDirectMessage(ev abcitypes.Event, height int64) (*DirectMessage, error) {
+	err := expectAttributes(ev, "Sender", "Receiver", "Topic", "EncryptedPayload")
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := decodeAddress(ev.Attributes[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err := decodeAddress(ev.Attributes[1].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	topic, err := decodeBytes(ev.Attributes[2].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := decodeBytes(ev.Attributes[3].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DirectMessage{
+		Height:           height,
+		Sender:           sender,
+		Receiver:         receiver,
+		Topic:            string(topic),
+		EncryptedPayload: payload,
+	}, nil
+}
+
+// SkipCipherVote is generated by shuttermint when a keyper votes to skip the cipher execution of
+// a batch after its execution timeout has passed.
+type SkipCipherVote struct {
+	Height     int64
+	BatchIndex uint64
+	Sender     common.Address
+}
+
+func (msg SkipCipherVote) MakeABCIEvent() abcitypes.Event {
+	return abcitypes.Event{
+		Type: evtype.SkipCipherVote,
+		Attributes: []abcitypes.EventAttribute{
+			newUintPair("BatchIndex", msg.BatchIndex),
+			newAddressPair("Sender", msg.Sender),
+		},
+	}
+}
+
+func makeSkipCipherVote(ev abcitypes.Event, height int64) (*SkipCipherVote, error) {
+	err := expectAttributes(ev, "BatchIndex", "Sender")
+	if err != nil {
+		return nil, err
+	}
+
+	batchIndex, err := decodeUint64(ev.Attributes[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := decodeAddress(ev.Attributes[1].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SkipCipherVote{
+		Height:     height,
+		BatchIndex: batchIndex,
+		Sender:     sender,
+	}, nil
+}
+
+// HaltResumeVote is generated by shuttermint when a keyper votes to resume key releases and
+// executions after a shuttermint halt.
+type HaltResumeVote struct {
+	Height     int64
+	Sender     common.Address
+	HaltHeight uint64
+}
+
+func (msg HaltResumeVote) MakeABCIEvent() abcitypes.Event {
+	return abcitypes.Event{
+		Type: evtype.HaltResumeVote,
+		Attributes: []abcitypes.EventAttribute{
+			newAddressPair("Sender", msg.Sender),
+			newUintPair("HaltHeight", msg.HaltHeight),
+		},
+	}
+}
+
+func makeHaltResumeVote(ev abcitypes.Event, height int64) (*HaltResumeVote, error) {
+	err := expectAttributes(ev, "Sender", "HaltHeight")
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := decodeAddress(ev.Attributes[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	haltHeight, err := decodeUint64(ev.Attributes[1].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HaltResumeVote{
+		Height:     height,
+		Sender:     sender,
+		HaltHeight: haltHeight,
+	}, nil
+}
+
+// Capabilities is generated by shuttermint when a keyper announces its software version and the
+// optional sub-protocols it supports.
+type Capabilities struct {
+	Height   int64
+	Sender   common.Address
+	Version  string
+	Features []string
+}
+
+func (msg Capabilities) MakeABCIEvent() abcitypes.Event {
+	return abcitypes.Event{
+		Type: evtype.Capabilities,
+		Attributes: []abcitypes.EventAttribute{
+			newAddressPair("Sender", msg.Sender),
+			{
+				Key:   []byte("Version"),
+				Value: encodeBytes([]byte(msg.Version)),
+			},
+			newStringsPair("Features", msg.Features),
+		},
+	}
+}
+
+func makeCapabilities(ev abcitypes.Event, height int64) (*Capabilities, error) {
+	err := expectAttributes(ev, "Sender", "Version", "Features")
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := decodeAddress(ev.Attributes[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := decodeBytes(ev.Attributes[1].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := decodeStrings(ev.Attributes[2].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Capabilities{
+		Height:   height,
+		Sender:   sender,
+		Version:  string(version),
+		Features: features,
+	}, nil
+}
+
+// DecryptionApproval is generated by shuttermint when a keyper approves publishing the epoch
+// secret key shares for a past epoch whose key hasn't been released.
+type DecryptionApproval struct {
+	Height int64
+	Sender common.Address
+	Eon    uint64
+	Epoch  uint64
+}
+
+func (msg DecryptionApproval) MakeABCIEvent() abcitypes.Event {
+	return abcitypes.Event{
+		Type: evtype.DecryptionApproval,
+		Attributes: []abcitypes.EventAttribute{
+			newAddressPair("Sender", msg.Sender),
+			newUintPair("Eon", msg.Eon),
+			newUintPair("Epoch", msg.Epoch),
+		},
+	}
+}
+
+func makeDecryptionApproval(ev abcitypes.Event, height int64) (*DecryptionApproval, error) {
+	err := expectAttributes(ev, "Sender", "Eon", "Epoch")
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := decodeAddress(ev.Attributes[0].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	eon, err := decodeUint64(ev.Attributes[1].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	epoch, err := decodeUint64(ev.Attributes[2].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecryptionApproval{
 		Height: height,
 		Sender: sender,
 		Eon:    eon,
 		Epoch:  epoch,
-		Share:  share,
 	}, nil
 }
 
@@ -532,10 +859,20 @@ func makeEonStarted(ev abcitypes.Event, height int64) (*EonStarted, error) {
 		return nil, err
 	}
 
+	// Seed is optional, events emitted by older versions do not contain it
+	var seed []byte
+	if len(ev.Attributes) > 2 && string(ev.Attributes[2].Key) == "Seed" {
+		seed, err = decodeBytes(ev.Attributes[2].Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &EonStarted{
 		Height:     height,
 		Eon:        eon,
 		BatchIndex: batchIndex,
+		Seed:       seed,
 	}, nil
 }
 
@@ -560,6 +897,16 @@ func MakeEvent(ev abcitypes.Event, height int64) (IEvent, error) {
 		return makeApology(ev, height)
 	case evtype.EpochSecretKeyShare:
 		return makeEpochSecretKeyShare(ev, height)
+	case evtype.DirectMessage:
+		return makeDirectMessage(ev, height)
+	case evtype.SkipCipherVote:
+		return makeSkipCipherVote(ev, height)
+	case evtype.HaltResumeVote:
+		return makeHaltResumeVote(ev, height)
+	case evtype.Capabilities:
+		return makeCapabilities(ev, height)
+	case evtype.DecryptionApproval:
+		return makeDecryptionApproval(ev, height)
 	default:
 		return nil, errors.Errorf("cannot make event from type %s", ev.Type)
 	}
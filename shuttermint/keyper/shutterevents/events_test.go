@@ -103,6 +103,19 @@ func TestBatchConfig(t *testing.T) {
 		ConfigIndex:     uint64(0xffffffffffffffff),
 	}
 	roundtrip(t, ev)
+
+	ev.EpochNamespaces = []string{"auction", "vote"}
+	ev.PerBlockEpochs = true
+	ev.CommitteeSize = 5
+	roundtrip(t, ev)
+
+	ev.PerBlockEpochs = false
+	ev.EpochOffset = 1000
+	ev.EpochStride = 2
+	roundtrip(t, ev)
+
+	ev.EncryptionVersion = 1
+	roundtrip(t, ev)
 }
 
 func TestCheckIn(t *testing.T) {
@@ -123,7 +136,7 @@ func TestDecryptionSignature(t *testing.T) {
 }
 
 func TestEonStarted(t *testing.T) {
-	ev := &shutterevents.EonStarted{Eon: eon, BatchIndex: 9999}
+	ev := &shutterevents.EonStarted{Eon: eon, BatchIndex: 9999, Seed: []byte("seed")}
 	roundtrip(t, ev)
 }
 
@@ -161,3 +174,47 @@ func TestEpochSecretKeyShare(t *testing.T) {
 	}
 	roundtrip(t, share)
 }
+
+func TestDirectMessage(t *testing.T) {
+	ev := &shutterevents.DirectMessage{
+		Sender:           sender,
+		Receiver:         addresses[0],
+		Topic:            "vote",
+		EncryptedPayload: []byte("encrypted"),
+	}
+	roundtrip(t, ev)
+}
+
+func TestSkipCipherVote(t *testing.T) {
+	ev := &shutterevents.SkipCipherVote{
+		BatchIndex: 17,
+		Sender:     sender,
+	}
+	roundtrip(t, ev)
+}
+
+func TestCapabilities(t *testing.T) {
+	ev := &shutterevents.Capabilities{
+		Sender:   sender,
+		Version:  "v1.2.3",
+		Features: []string{"a", "b"},
+	}
+	roundtrip(t, ev)
+}
+
+func TestDecryptionApproval(t *testing.T) {
+	ev := &shutterevents.DecryptionApproval{
+		Sender: sender,
+		Eon:    3,
+		Epoch:  17,
+	}
+	roundtrip(t, ev)
+}
+
+func TestHaltResumeVote(t *testing.T) {
+	ev := &shutterevents.HaltResumeVote{
+		Sender:     sender,
+		HaltHeight: 1234,
+	}
+	roundtrip(t, ev)
+}
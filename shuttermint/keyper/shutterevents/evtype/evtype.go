@@ -11,4 +11,9 @@ var (
 	PolyCommitment      = "shutter.poly-commitment-registered"
 	PolyEval            = "shutter.poly-eval-registered"
 	EpochSecretKeyShare = "shutter.epoch-secret-key-share"
+	DirectMessage       = "shutter.direct-message"
+	SkipCipherVote      = "shutter.skip-cipher-vote"
+	HaltResumeVote      = "shutter.halt-resume-vote"
+	Capabilities        = "shutter.capabilities"
+	DecryptionApproval  = "shutter.decryption-approval"
 )
@@ -33,6 +33,20 @@ func newByteSequencePair(key string, value [][]byte) abcitypes.EventAttribute {
 	}
 }
 
+func newStringsPair(key string, value []string) abcitypes.EventAttribute {
+	return abcitypes.EventAttribute{
+		Key:   []byte(key),
+		Value: encodeStrings(value),
+	}
+}
+
+func newBoolPair(key string, value bool) abcitypes.EventAttribute {
+	return abcitypes.EventAttribute{
+		Key:   []byte(key),
+		Value: encodeBool(value),
+	}
+}
+
 func newUintPair(key string, value uint64) abcitypes.EventAttribute {
 	return abcitypes.EventAttribute{
 		Key:   []byte(key),
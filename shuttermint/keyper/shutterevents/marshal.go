@@ -10,7 +10,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
-	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/pkg/errors"
 
@@ -74,12 +73,7 @@ func decodeEpochSecretKeyShare(v []byte) (*shcrypto.EpochSecretKeyShare, error)
 	if err != nil {
 		return nil, err
 	}
-	share := new(shcrypto.EpochSecretKeyShare)
-	err = share.GobDecode(decoded)
-	if err != nil {
-		return nil, err
-	}
-	return share, nil
+	return shcrypto.ValidateEpochSecretKeyShare(decoded)
 }
 
 // encodeByteSequence encodes a slice o byte strings as a comma separated string.
@@ -108,6 +102,40 @@ func decodeByteSequence(val []byte) ([][]byte, error) {
 	return res, nil
 }
 
+// encodeStrings encodes a slice of strings as a comma separated list of hex encoded strings.
+func encodeStrings(v []string) []byte {
+	var bs [][]byte
+	for _, a := range v {
+		bs = append(bs, []byte(a))
+	}
+	return encodeByteSequence(bs)
+}
+
+// decodeStrings parses a list of strings encoded with encodeStrings.
+func decodeStrings(val []byte) ([]string, error) {
+	bs, err := decodeByteSequence(val)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, b := range bs {
+		res = append(res, string(b))
+	}
+	return res, nil
+}
+
+func encodeBool(val bool) []byte {
+	return []byte(strconv.FormatBool(val))
+}
+
+func decodeBool(val []byte) (bool, error) {
+	v, err := strconv.ParseBool(string(val))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse event")
+	}
+	return v, nil
+}
+
 // encodePubkey encodes the PublicKey as a string suitable for putting it into a tendermint
 // event, i.e. an utf-8 compatible string.
 func encodePubkey(pubkey *ecdsa.PublicKey) []byte {
@@ -135,20 +163,19 @@ func encodeGammas(gammas *shcrypto.Gammas) []byte {
 
 func decodeGammas(eventValue []byte) (shcrypto.Gammas, error) {
 	parts := strings.Split(string(eventValue), ",")
-	var res shcrypto.Gammas
-	for _, p := range parts {
+	encodings := make([][]byte, len(parts))
+	for i, p := range parts {
 		marshaledG2, err := hex.DecodeString(p)
 		if err != nil {
 			return shcrypto.Gammas{}, err
 		}
-		g := new(bn256.G2)
-		_, err = g.Unmarshal(marshaledG2)
-		if err != nil {
-			return shcrypto.Gammas{}, err
-		}
-		res = append(res, g)
+		encodings[i] = marshaledG2
 	}
-	return res, nil
+	points, err := shcrypto.UnmarshalG2Batch(encodings)
+	if err != nil {
+		return shcrypto.Gammas{}, err
+	}
+	return shcrypto.Gammas(points), nil
 }
 
 func encodeAddress(a common.Address) []byte {
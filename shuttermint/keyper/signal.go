@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package keyper
+
+import (
+	"os"
+	"syscall"
+)
+
+// dumpStateSignals are the signals that make the keyper dump its internal state.
+var dumpStateSignals = []os.Signal{syscall.SIGUSR1}
+
+// reloadConfigSignals are the signals that make the keyper reload its config.
+var reloadConfigSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,9 @@
+package keyper
+
+import "os"
+
+// dumpStateSignals is empty on Windows, which doesn't have SIGUSR1.
+var dumpStateSignals = []os.Signal{}
+
+// reloadConfigSignals is empty on Windows, which doesn't have SIGHUP.
+var reloadConfigSignals = []os.Signal{}
@@ -0,0 +1,118 @@
+package keyper
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// If a cipher batch hasn't been executed before its execution timeout, it has to be skipped.
+// Instead of every keyper sending a skip transaction, the keypers vote on shuttermint and only
+// skip once a threshold of them agrees. The keyper sending the transaction is staggered like for
+// executions, so usually only one of them pays for it.
+
+// skipCipherVoteTimeout is the number of shuttermint blocks after which we send our vote again,
+// if it hasn't made it into the chain.
+const skipCipherVoteTimeout int64 = 10
+
+// SkipCipherVote holds our local state about skipping the cipher execution of a batch.
+type SkipCipherVote struct {
+	SentHeight  int64  // shuttermint height we've last sent our vote at, 0 if we haven't voted
+	AgreedBlock uint64 // main chain block we've first seen a threshold of votes at, 0 if not yet
+}
+
+func (dcdr *Decider) skipCipherVote(batchIndex uint64) *SkipCipherVote {
+	if dcdr.State.SkipCipherVotes == nil {
+		dcdr.State.SkipCipherVotes = make(map[uint64]*SkipCipherVote)
+	}
+	v, ok := dcdr.State.SkipCipherVotes[batchIndex]
+	if !ok {
+		v = &SkipCipherVote{}
+		dcdr.State.SkipCipherVotes[batchIndex] = v
+	}
+	return v
+}
+
+// skipCipherVoters returns the keypers of the given config that have voted to skip the cipher
+// execution of the batch.
+func (dcdr *Decider) skipCipherVoters(batchIndex uint64, config contract.BatchConfig) map[common.Address]struct{} {
+	voters := make(map[common.Address]struct{})
+	shBatch, ok := dcdr.Shutter.Batches[batchIndex]
+	if !ok {
+		return voters
+	}
+	for _, ev := range shBatch.SkipCipherVotes {
+		if config.IsKeyper(ev.Sender) {
+			voters[ev.Sender] = struct{}{}
+		}
+	}
+	return voters
+}
+
+// skipCipherAgreed checks if a threshold of keypers has voted to skip the cipher execution of the
+// batch and returns the main chain block we've first noticed it at.
+func (dcdr *Decider) skipCipherAgreed(batchIndex uint64, config contract.BatchConfig) (uint64, bool) {
+	threshold := config.Threshold
+	if threshold == 0 {
+		threshold = 1
+	}
+	if uint64(len(dcdr.skipCipherVoters(batchIndex, config))) < threshold {
+		return 0, false
+	}
+	v := dcdr.skipCipherVote(batchIndex)
+	if v.AgreedBlock == 0 {
+		v.AgreedBlock = dcdr.MainChain.CurrentBlock
+		log.Printf("Keypers agreed to skip cipher execution of batch %d", batchIndex)
+	}
+	return v.AgreedBlock, true
+}
+
+// maybeVoteSkipCipher votes to skip the cipher execution of the upcoming batches whose execution
+// timeout has passed.
+func (dcdr *Decider) maybeVoteSkipCipher() {
+	dcdr.syncSkipCipherVotes()
+
+	nextHalfStep := dcdr.MainChain.NumExecutionHalfSteps
+	for halfStep := nextHalfStep; halfStep < nextHalfStep+maxParallelHalfSteps; halfStep++ {
+		if !protocol.HalfStep(halfStep).IsCipher() {
+			continue
+		}
+		batchIndex := uint64(protocol.HalfStep(halfStep).BatchIndex())
+		config, ok := dcdr.MainChain.ConfigForBatchIndex(batchIndex)
+		if !ok {
+			return
+		}
+		if dcdr.MainChain.CurrentBlock < config.BatchEndBlock(batchIndex)+config.ExecutionTimeout {
+			return // later batches time out even later
+		}
+		if !config.IsKeyper(dcdr.Config.Address()) {
+			continue
+		}
+		if _, voted := dcdr.skipCipherVoters(batchIndex, config)[dcdr.Config.Address()]; voted {
+			continue
+		}
+		v := dcdr.skipCipherVote(batchIndex)
+		if v.SentHeight != 0 && dcdr.Shutter.CurrentBlock < v.SentHeight+skipCipherVoteTimeout {
+			continue // still waiting for our vote to make it into the chain
+		}
+		v.SentHeight = dcdr.Shutter.CurrentBlock
+		dcdr.sendShuttermintMessage(
+			fmt.Sprintf("skip cipher vote, batch index=%d", batchIndex),
+			shmsg.NewSkipCipherVote(batchIndex))
+	}
+}
+
+// syncSkipCipherVotes removes the votes of batches whose cipher half step has been executed or
+// skipped.
+func (dcdr *Decider) syncSkipCipherVotes() {
+	for batchIndex := range dcdr.State.SkipCipherVotes {
+		if uint64(protocol.BatchIndex(batchIndex).CipherHalfStep()) < dcdr.MainChain.NumExecutionHalfSteps {
+			delete(dcdr.State.SkipCipherVotes, batchIndex)
+		}
+	}
+}
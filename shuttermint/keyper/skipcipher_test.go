@@ -0,0 +1,80 @@
+package keyper
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func TestSkipCipherVoting(t *testing.T) {
+	shutter := observe.NewShutter()
+	mainChain := observe.NewMainChain(0)
+	config := contract.BatchConfig{Threshold: 2, BatchSpan: 10, ExecutionTimeout: 40}
+	batchIndex := uint64(1)
+	halfStep := uint64(protocol.BatchIndex(batchIndex).CipherHalfStep())
+	timeoutBlock := config.BatchEndBlock(batchIndex) + config.ExecutionTimeout
+
+	var deciders []*Decider
+	for i := 0; i < 3; i++ {
+		signingKey, err := crypto.GenerateKey()
+		assert.NilError(t, err)
+		kc := Config{SigningKey: signingKey, ExecutionStaggering: 3}
+		config.Keypers = append(config.Keypers, kc.Address())
+		deciders = append(deciders, &Decider{Config: kc, State: NewState(), Shutter: shutter, MainChain: mainChain})
+	}
+	mainChain.BatchConfigs = []contract.BatchConfig{config}
+	mainChain.NumExecutionHalfSteps = halfStep
+	a, b := deciders[0], deciders[1] // b is the first to skip this half step, a the last
+
+	// nobody votes before the timeout
+	mainChain.CurrentBlock = timeoutBlock - 1
+	a.maybeVoteSkipCipher()
+	assert.Equal(t, len(a.Actions), 0)
+
+	mainChain.CurrentBlock = timeoutBlock
+	shutter.CurrentBlock = 100
+	for _, dcdr := range deciders {
+		dcdr.maybeVoteSkipCipher()
+		assert.Equal(t, len(dcdr.Actions), 1)
+		msg := dcdr.Actions[0].(*fx.SendShuttermintMessage).Msg
+		assert.Equal(t, msg.GetSkipCipherVote().BatchIndex, batchIndex)
+	}
+	// don't vote again while waiting for the vote to be included
+	a.maybeVoteSkipCipher()
+	assert.Equal(t, len(a.Actions), 1)
+	// without a threshold of votes nobody skips
+	assert.Assert(t, a.maybeExecuteHalfStep(halfStep) == nil)
+	assert.Assert(t, b.maybeExecuteHalfStep(halfStep) == nil)
+
+	shBatch := &observe.BatchData{BatchIndex: batchIndex}
+	shutter.Batches[batchIndex] = shBatch
+	for _, dcdr := range deciders[:2] {
+		shBatch.SkipCipherVotes = append(shBatch.SkipCipherVotes, shutterevents.SkipCipherVote{
+			Height:     101,
+			BatchIndex: batchIndex,
+			Sender:     dcdr.Config.Address(),
+		})
+	}
+
+	// the keypers skip one after the other, counting from the block they've seen the agreement at
+	mainChain.CurrentBlock = timeoutBlock + 10
+	assert.DeepEqual(t, b.maybeExecuteHalfStep(halfStep), &fx.SkipCipherBatch{BatchIndex: batchIndex})
+	assert.Assert(t, a.maybeExecuteHalfStep(halfStep) == nil)
+	mainChain.CurrentBlock = timeoutBlock + 16
+	assert.DeepEqual(t, a.maybeExecuteHalfStep(halfStep), &fx.SkipCipherBatch{BatchIndex: batchIndex})
+
+	// the vote state is removed once the half step has been skipped
+	_, ok := a.State.SkipCipherVotes[batchIndex]
+	assert.Assert(t, ok)
+	mainChain.NumExecutionHalfSteps = halfStep + 1
+	a.syncSkipCipherVotes()
+	_, ok = a.State.SkipCipherVotes[batchIndex]
+	assert.Assert(t, !ok)
+}
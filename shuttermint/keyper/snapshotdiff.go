@@ -0,0 +1,367 @@
+package keyper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// ObservedSnapshot is the observed state of the shuttermint chain and the main chain as stored in
+// a keyper's state file or a support bundle.
+type ObservedSnapshot struct {
+	Shutter   *observe.Shutter
+	MainChain *observe.MainChain
+}
+
+// ReadObservedSnapshot reads the observed state from a copy of a keyper's state.gob or from a
+// support bundle, see SupportBundle. Support bundles don't contain any secrets, so they're the
+// better choice for comparing the states of different keypers.
+func ReadObservedSnapshot(path string) (ObservedSnapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ObservedSnapshot{}, errors.Wrap(err, "failed to open state snapshot")
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+	magic, err := r.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b { // gzip, i.e. a support bundle
+		b, err := ReadSupportBundle(r)
+		if err != nil {
+			return ObservedSnapshot{}, errors.Wrapf(err, "failed to read support bundle %s", path)
+		}
+		return ObservedSnapshot{Shutter: b.Shutter, MainChain: b.MainChain}, nil
+	}
+	st, err := decodeStoredState(r)
+	if err != nil {
+		return ObservedSnapshot{}, errors.Wrapf(err, "failed to decode state snapshot %s", path)
+	}
+	return ObservedSnapshot{Shutter: st.Shutter, MainChain: st.MainChain}, nil
+}
+
+// ReconstructObservedSnapshot rebuilds the observed state at the heights of the given snapshot
+// from the chains, which requires an archive node for the main chain. The result is filtered and
+// its eons are archived like the ones of the snapshot, so that the two can be compared with
+// DiffObservedSnapshots.
+func ReconstructObservedSnapshot(
+	ctx context.Context, shmcl client.Client, caller *contract.Caller, like ObservedSnapshot,
+) (ObservedSnapshot, error) {
+	shutter, err := observe.NewShutter().SyncToHeight(ctx, shmcl, like.Shutter.CurrentBlock)
+	if err != nil {
+		return ObservedSnapshot{}, err
+	}
+	shutter = shutter.ApplyFilter(like.Shutter.Filter)
+	archived := make(map[uint64]bool)
+	for _, eon := range like.Shutter.Eons {
+		archived[eon.Eon] = eon.Archived
+	}
+	for i, eon := range shutter.Eons {
+		if archived[eon.Eon] {
+			shutter.Eons[i] = observe.Eon{
+				Eon:         eon.Eon,
+				StartHeight: eon.StartHeight,
+				StartEvent:  eon.StartEvent,
+				Archived:    true,
+			}
+		}
+	}
+
+	mainChain := observe.NewMainChain(like.MainChain.FollowDistance)
+	mainChain.Confirmations = like.MainChain.Confirmations
+	mainChain, err = mainChain.SyncToBlock(ctx, caller, like.MainChain.CurrentBlock)
+	if err != nil {
+		return ObservedSnapshot{}, err
+	}
+	return ObservedSnapshot{Shutter: shutter, MainChain: mainChain}, nil
+}
+
+// SnapshotDiffSection lists the discrepancies in one part of the observed state, e.g. an eon or
+// a batch. Events and other list items only present in the first snapshot are prefixed with "-",
+// the ones only present in the second one with "+". Other fields are reported as "name: a != b".
+type SnapshotDiffSection struct {
+	Name  string
+	Lines []string
+}
+
+// SnapshotDiff is the list of sections of the observed state in which two snapshots differ.
+type SnapshotDiff []SnapshotDiffSection
+
+// DiffObservedSnapshots compares two snapshots field by field. Fields that change with every
+// sync, like the status of the node, are ignored. Events of eons that have been archived in
+// either snapshot are not compared, as they're not in the snapshots.
+func DiffObservedSnapshots(a, b ObservedSnapshot) SnapshotDiff {
+	d := &snapshotDiffer{}
+	d.diffShutter(a.Shutter, b.Shutter)
+	d.diffMainChain(a.MainChain, b.MainChain)
+	return d.diff
+}
+
+type snapshotDiffer struct {
+	diff    SnapshotDiff
+	section string
+}
+
+func (d *snapshotDiffer) add(line string) {
+	n := len(d.diff)
+	if n == 0 || d.diff[n-1].Name != d.section {
+		d.diff = append(d.diff, SnapshotDiffSection{Name: d.section})
+		n++
+	}
+	d.diff[n-1].Lines = append(d.diff[n-1].Lines, line)
+}
+
+// value compares a single field.
+func (d *snapshotDiffer) value(field string, a, b interface{}) {
+	if ra, rb := renderSnapshotValue(a), renderSnapshotValue(b); ra != rb {
+		d.add(fmt.Sprintf("%s: %s != %s", field, ra, rb))
+	}
+}
+
+// items compares two slices of events or other items as multisets.
+func (d *snapshotDiffer) items(field string, a, b interface{}) {
+	counts := make(map[string]int)
+	var order []string
+	count := func(slice interface{}, delta int) {
+		v := reflect.ValueOf(slice)
+		for i := 0; i < v.Len(); i++ {
+			r := renderSnapshotValue(v.Index(i).Interface())
+			if _, ok := counts[r]; !ok {
+				order = append(order, r)
+			}
+			counts[r] += delta
+		}
+	}
+	count(a, 1)
+	count(b, -1)
+	for _, r := range order {
+		for n := counts[r]; n > 0; n-- {
+			d.add(fmt.Sprintf("- %s %s", field, r))
+		}
+		for n := counts[r]; n < 0; n++ {
+			d.add(fmt.Sprintf("+ %s %s", field, r))
+		}
+	}
+}
+
+// renderSnapshotValue renders a value for comparison. JSON is used since it's deterministic, in
+// contrast to fmt which prints the addresses of nested pointers. Empty lists and maps are
+// rendered as null, since gob doesn't distinguish them from nil ones.
+func renderSnapshotValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v (%s)", v, err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return string(data)
+	}
+	data, err = json.Marshal(dropEmptyJSON(generic))
+	if err != nil {
+		return fmt.Sprintf("%v (%s)", v, err)
+	}
+	return string(data)
+}
+
+func dropEmptyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i := range v {
+			v[i] = dropEmptyJSON(v[i])
+		}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for k := range v {
+			v[k] = dropEmptyJSON(v[k])
+		}
+	}
+	return v
+}
+
+func (d *snapshotDiffer) diffShutter(a, b *observe.Shutter) {
+	d.section = "shutter"
+	d.value("CurrentBlock", a.CurrentBlock, b.CurrentBlock)
+	d.value("LastCommittedHeight", a.LastCommittedHeight, b.LastCommittedHeight)
+	d.value("Filter", a.Filter, b.Filter)
+	d.value("RejectedEvents", a.RejectedEvents, b.RejectedEvents)
+	d.items("DirectMessage", a.DirectMessages, b.DirectMessages)
+	d.items("HaltResumeVote", a.HaltResumeVotes, b.HaltResumeVotes)
+	d.items("DecryptionApproval", a.DecryptionApprovals, b.DecryptionApprovals)
+	var usageA, usageB map[common.Address]observe.MessageUsage
+	if a.Usage != nil {
+		usageA = a.Usage.Total
+	}
+	if b.Usage != nil {
+		usageB = b.Usage.Total
+	}
+	d.value("Usage", usageA, usageB)
+
+	for _, addr := range addressKeys(a.KeyperEncryptionKeys, b.KeyperEncryptionKeys) {
+		d.section = fmt.Sprintf("shutter encryption key of %s", addr.Hex())
+		d.value("EncryptionKey", a.KeyperEncryptionKeys[addr], b.KeyperEncryptionKeys[addr])
+	}
+
+	for _, addr := range addressKeys(a.Capabilities, b.Capabilities) {
+		d.section = fmt.Sprintf("shutter capabilities of %s", addr.Hex())
+		d.value("Capabilities", a.Capabilities[addr], b.Capabilities[addr])
+	}
+
+	for i := 0; i < len(a.BatchConfigs) || i < len(b.BatchConfigs); i++ {
+		d.section = fmt.Sprintf("shutter batch config %d", i)
+		d.value("BatchConfig", indexOrNil(a.BatchConfigs, i), indexOrNil(b.BatchConfigs, i))
+	}
+
+	eonsA, eonsB := make(map[uint64]*observe.Eon), make(map[uint64]*observe.Eon)
+	for i := range a.Eons {
+		eonsA[a.Eons[i].Eon] = &a.Eons[i]
+	}
+	for i := range b.Eons {
+		eonsB[b.Eons[i].Eon] = &b.Eons[i]
+	}
+	for _, eon := range uint64Keys(eonsA, eonsB) {
+		d.section = fmt.Sprintf("shutter eon %d", eon)
+		d.diffEon(eonsA[eon], eonsB[eon])
+	}
+
+	for _, batchIndex := range uint64Keys(a.Batches, b.Batches) {
+		d.section = fmt.Sprintf("shutter batch %d", batchIndex)
+		ba, bb := a.Batches[batchIndex], b.Batches[batchIndex]
+		if ba == nil {
+			ba = &observe.BatchData{}
+		}
+		if bb == nil {
+			bb = &observe.BatchData{}
+		}
+		d.items("DecryptionSignature", ba.DecryptionSignatures, bb.DecryptionSignatures)
+		d.items("SkipCipherVote", ba.SkipCipherVotes, bb.SkipCipherVotes)
+	}
+}
+
+func (d *snapshotDiffer) diffEon(a, b *observe.Eon) {
+	if b == nil {
+		d.add("- EonStarted " + renderSnapshotValue(a.StartEvent))
+		return
+	}
+	if a == nil {
+		d.add("+ EonStarted " + renderSnapshotValue(b.StartEvent))
+		return
+	}
+	d.value("StartHeight", a.StartHeight, b.StartHeight)
+	d.value("StartEvent", a.StartEvent, b.StartEvent)
+	d.value("Archived", a.Archived, b.Archived)
+	if a.Archived || b.Archived {
+		return
+	}
+	d.items("PolyCommitment", a.Commitments, b.Commitments)
+	d.items("PolyEval", a.PolyEvals, b.PolyEvals)
+	d.items("Accusation", a.Accusations, b.Accusations)
+	d.items("Apology", a.Apologies, b.Apologies)
+	d.items("EpochSecretKeyShare", a.EpochSecretKeyShares, b.EpochSecretKeyShares)
+}
+
+func (d *snapshotDiffer) diffMainChain(a, b *observe.MainChain) {
+	d.section = "main chain"
+	d.value("CurrentBlock", a.CurrentBlock, b.CurrentBlock)
+	d.value("FollowDistance", a.FollowDistance, b.FollowDistance)
+	d.value("Confirmations", a.Confirmations, b.Confirmations)
+	d.value("AccusationsLag", a.AccusationsLag, b.AccusationsLag)
+	d.value("NumExecutionHalfSteps", a.NumExecutionHalfSteps, b.NumExecutionHalfSteps)
+
+	for i := 0; i < len(a.BatchConfigs) || i < len(b.BatchConfigs); i++ {
+		d.section = fmt.Sprintf("main chain batch config %d", i)
+		d.value("BatchConfig", indexOrNil(a.BatchConfigs, i), indexOrNil(b.BatchConfigs, i))
+	}
+
+	for _, batchIndex := range uint64Keys(a.Batches, b.Batches) {
+		d.section = fmt.Sprintf("main chain batch %d", batchIndex)
+		ba, bb := a.Batches[batchIndex], b.Batches[batchIndex]
+		if ba == nil {
+			ba = &observe.Batch{}
+		}
+		if bb == nil {
+			bb = &observe.Batch{}
+		}
+		d.value("EncryptedBatchHash", ba.EncryptedBatchHash, bb.EncryptedBatchHash)
+		d.value("PlainBatchHash", ba.PlainBatchHash, bb.PlainBatchHash)
+		d.items("EncryptedTransaction", hexTransactions(ba.EncryptedTransactions), hexTransactions(bb.EncryptedTransactions))
+		d.items("PlainTransaction", hexTransactions(ba.PlainTransactions), hexTransactions(bb.PlainTransactions))
+	}
+
+	for _, halfStep := range uint64Keys(a.CipherExecutionReceipts, b.CipherExecutionReceipts) {
+		d.section = fmt.Sprintf("main chain cipher execution receipt %d", halfStep)
+		d.value("Receipt", a.CipherExecutionReceipts[halfStep], b.CipherExecutionReceipts[halfStep])
+	}
+	for _, addr := range addressKeys(a.Deposits, b.Deposits) {
+		d.section = fmt.Sprintf("main chain deposit of %s", addr.Hex())
+		d.value("Deposit", a.Deposits[addr], b.Deposits[addr])
+	}
+	for _, halfStep := range uint64Keys(a.Accusations, b.Accusations) {
+		d.section = fmt.Sprintf("main chain accusation %d", halfStep)
+		d.value("Accusation", a.Accusations[halfStep], b.Accusations[halfStep])
+	}
+}
+
+// uint64Keys returns the union of the keys of two maps with uint64 keys in ascending order.
+func uint64Keys(a, b interface{}) []uint64 {
+	seen := make(map[uint64]struct{})
+	for _, m := range []interface{}{a, b} {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			seen[k.Uint()] = struct{}{}
+		}
+	}
+	keys := make([]uint64, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// addressKeys returns the union of the keys of two maps with address keys in ascending order.
+func addressKeys(a, b interface{}) []common.Address {
+	seen := make(map[common.Address]struct{})
+	for _, m := range []interface{}{a, b} {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			seen[k.Interface().(common.Address)] = struct{}{}
+		}
+	}
+	keys := make([]common.Address, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	return keys
+}
+
+// indexOrNil returns the i-th element of the given slice or nil if it's too short.
+func indexOrNil(slice interface{}, i int) interface{} {
+	v := reflect.ValueOf(slice)
+	if i >= v.Len() {
+		return nil
+	}
+	return v.Index(i).Interface()
+}
+
+func hexTransactions(txs [][]byte) []string {
+	res := make([]string, len(txs))
+	for i, tx := range txs {
+		res[i] = hexutil.Encode(tx)
+	}
+	return res
+}
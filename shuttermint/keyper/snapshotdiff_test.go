@@ -0,0 +1,100 @@
+package keyper
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/shutterevents"
+)
+
+func snapshotDiffTestSnapshot() ObservedSnapshot {
+	sender := common.BigToAddress(big.NewInt(1))
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 100
+	shutter.Eons = []observe.Eon{
+		{Eon: 1, StartHeight: 10, StartEvent: shutterevents.EonStarted{Height: 10, Eon: 1}, Archived: true},
+		{
+			Eon:         2,
+			StartHeight: 50,
+			StartEvent:  shutterevents.EonStarted{Height: 50, Eon: 2, BatchIndex: 5},
+			Accusations: []shutterevents.Accusation{{Height: 60, Eon: 2, Sender: sender}},
+		},
+	}
+	shutter.Batches[7] = &observe.BatchData{
+		BatchIndex:           7,
+		DecryptionSignatures: []shutterevents.DecryptionSignature{{Height: 90, BatchIndex: 7, Sender: sender}},
+	}
+	mainChain := observe.NewMainChain(0)
+	mainChain.CurrentBlock = 1000
+	mainChain.Batches[7] = &observe.Batch{BatchIndex: 7, EncryptedTransactions: [][]byte{{1, 2}}}
+	return ObservedSnapshot{Shutter: shutter, MainChain: mainChain}
+}
+
+func TestDiffObservedSnapshots(t *testing.T) {
+	a := snapshotDiffTestSnapshot()
+	assert.Equal(t, len(DiffObservedSnapshots(a, snapshotDiffTestSnapshot())), 0)
+
+	b := snapshotDiffTestSnapshot()
+	b.Shutter.Eons[0].Archived = false // archived in a, so the events are not compared
+	b.Shutter.Eons[0].Apologies = []shutterevents.Apology{{Height: 20, Eon: 1}}
+	b.Shutter.Eons[1].Accusations = nil
+	b.Shutter.Eons = append(b.Shutter.Eons, observe.Eon{Eon: 3, StartEvent: shutterevents.EonStarted{Eon: 3}})
+	b.Shutter.Batches[7].DecryptionSignatures = append(
+		b.Shutter.Batches[7].DecryptionSignatures,
+		shutterevents.DecryptionSignature{Height: 91, BatchIndex: 7},
+	)
+	b.MainChain.CurrentBlock = 1001
+	b.MainChain.Batches[7].EncryptedTransactions = [][]byte{{1, 3}}
+
+	diff := DiffObservedSnapshots(a, b)
+	var names []string
+	for _, section := range diff {
+		names = append(names, section.Name)
+	}
+	assert.DeepEqual(t, names, []string{
+		"shutter eon 1", "shutter eon 2", "shutter eon 3", "shutter batch 7", "main chain", "main chain batch 7",
+	})
+	assert.DeepEqual(t, diff[0].Lines, []string{"Archived: true != false"})
+	assert.Equal(t, len(diff[1].Lines), 1)
+	assert.Assert(t, bytes.HasPrefix([]byte(diff[1].Lines[0]), []byte("- Accusation {")))
+	assert.Assert(t, bytes.HasPrefix([]byte(diff[2].Lines[0]), []byte("+ EonStarted {")))
+	assert.Equal(t, len(diff[3].Lines), 1)
+	assert.Assert(t, bytes.HasPrefix([]byte(diff[3].Lines[0]), []byte("+ DecryptionSignature {")))
+	assert.DeepEqual(t, diff[4].Lines, []string{"CurrentBlock: 1000 != 1001"})
+	assert.DeepEqual(t, diff[5].Lines, []string{
+		`- EncryptedTransaction "0x0102"`,
+		`+ EncryptedTransaction "0x0103"`,
+	})
+}
+
+func TestReadObservedSnapshot(t *testing.T) {
+	s := snapshotDiffTestSnapshot()
+	dir := t.TempDir()
+
+	stateGob := new(bytes.Buffer)
+	assert.NilError(t, gob.NewEncoder(stateGob).Encode(
+		StoredState{State: NewState(), Shutter: s.Shutter, MainChain: s.MainChain}))
+	statePath := filepath.Join(dir, "state.gob")
+	assert.NilError(t, ioutil.WriteFile(statePath, stateGob.Bytes(), 0o600))
+
+	bundle := new(bytes.Buffer)
+	assert.NilError(t, (&SupportBundle{State: NewState(), Shutter: s.Shutter, MainChain: s.MainChain}).Write(bundle))
+	bundlePath := filepath.Join(dir, "bundle.tar.gz")
+	assert.NilError(t, ioutil.WriteFile(bundlePath, bundle.Bytes(), 0o600))
+
+	for _, path := range []string{statePath, bundlePath} {
+		read, err := ReadObservedSnapshot(path)
+		assert.NilError(t, err)
+		assert.Equal(t, len(DiffObservedSnapshots(s, read)), 0)
+	}
+	_, err := ReadObservedSnapshot(filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "failed to open state snapshot")
+}
@@ -0,0 +1,150 @@
+package keyper
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/standby"
+	"github.com/shutter-network/shutter/shuttermint/shmsg"
+)
+
+// maxReplicatedMessages limits the number of messages from replicated states we remember for
+// checkTakeover.
+const maxReplicatedMessages = 1000
+
+var errFenced = errors.New("fenced by standby keyper")
+
+// stepHeartbeat is the information about our last decider step served to standby keypers.
+type stepHeartbeat struct {
+	actionCounter uint64
+	syncHeight    int64
+	time          time.Time
+}
+
+// updateHeartbeat records that the state after a decider step has been saved.
+func (kpr *Keyper) updateHeartbeat() {
+	kpr.heartbeat.Store(stepHeartbeat{
+		actionCounter: kpr.State.ActionCounter,
+		syncHeight:    kpr.State.SyncHeight,
+		time:          time.Now(),
+	})
+}
+
+// standbyHandler serves our heartbeat and state to standby keypers.
+func (kpr *Keyper) standbyHandler() *standby.Handler {
+	return &standby.Handler{
+		StateFile: kpr.pathStateGob(),
+		Heartbeat: func() (standby.Heartbeat, bool) {
+			hb, ok := kpr.heartbeat.Load().(stepHeartbeat)
+			if !ok {
+				return standby.Heartbeat{}, false
+			}
+			return standby.Heartbeat{
+				ActionCounter: hb.actionCounter,
+				SyncHeight:    hb.syncHeight,
+				StepAge:       time.Since(hb.time),
+			}, true
+		},
+		Fence: func() {
+			atomic.StoreInt32(&kpr.fenced, 1)
+		},
+	}
+}
+
+func (kpr *Keyper) isFenced() bool {
+	return atomic.LoadInt32(&kpr.fenced) != 0
+}
+
+// followPrimary replicates the state of the primary keyper until it is down and loads the
+// replicated state.
+func (kpr *Keyper) followPrimary(ctx context.Context) error {
+	if kpr.Config.StandbyFailoverDelay <= 0 {
+		return errors.New("StandbyFailoverDelay must be positive")
+	}
+	log.Printf("Running as standby of the primary keyper at %s", kpr.Config.StandbyPrimaryURL)
+	c := standby.NewClient(kpr.Config.StandbyPrimaryURL, kpr.Config.SigningKey)
+	err := standby.Follow(ctx, c, kpr.Config.StandbyFailoverDelay, kpr.saveReplicatedState)
+	if err != nil {
+		return err
+	}
+	return kpr.LoadState()
+}
+
+// saveReplicatedState stores the state received from the primary as our own and remembers the
+// messages it decided to send.
+func (kpr *Keyper) saveReplicatedState(data []byte) error {
+	st, err := decodeStoredState(bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "invalid state received from primary")
+	}
+	for _, a := range st.State.Actions {
+		if m, ok := a.(*fx.SendShuttermintMessage); ok {
+			kpr.replicatedMessages = append(kpr.replicatedMessages, m.Msg)
+		}
+	}
+	if n := len(kpr.replicatedMessages); n > maxReplicatedMessages {
+		kpr.replicatedMessages = kpr.replicatedMessages[n-maxReplicatedMessages:]
+	}
+
+	return kpr.stateStore.Save(st)
+}
+
+// checkTakeover makes sure that the replicated state is recent enough to take over from the
+// primary: every message signed by us in the shuttermint blocks after the state's sync height
+// must be one the replicated states decided to send. Otherwise, the primary has made progress we
+// don't know about, and deciding from our older state could make us send conflicting messages,
+// e.g. a second poly commitment for the same eon.
+func (kpr *Keyper) checkTakeover(ctx context.Context) error {
+	status, err := kpr.shmcl.Status(ctx)
+	if err != nil {
+		return err
+	}
+	latest := status.SyncInfo.LatestBlockHeight
+	self := kpr.Config.Address()
+	unknown := 0
+	err = observe.FetchTxs(ctx, kpr.shmcl, kpr.State.SyncHeight, latest, func(tx *rpctypes.ResultTx) {
+		if tx.TxResult.Code != 0 {
+			return
+		}
+		signedMsg, err := base64.RawURLEncoding.DecodeString(string(tx.Tx))
+		if err != nil {
+			return
+		}
+		signer, err := shmsg.GetSigner(signedMsg)
+		if err != nil || signer != self {
+			return
+		}
+		msg, err := shmsg.GetMessage(signedMsg)
+		if err != nil {
+			return
+		}
+		for _, m := range kpr.replicatedMessages {
+			if proto.Equal(m, msg.Msg) {
+				return
+			}
+		}
+		log.Printf("Message sent by the primary at height %d is not in the replicated state: %s", tx.Height, msg.Msg)
+		unknown++
+	})
+	if err != nil {
+		return err
+	}
+	if unknown > 0 {
+		return errors.Errorf(
+			"primary sent %d messages after the last replicated state, refusing to take over with outdated state",
+			unknown,
+		)
+	}
+	log.Printf("Taking over from the primary keyper at shuttermint height %d", latest)
+	return nil
+}
@@ -0,0 +1,228 @@
+// Package standby lets a passive standby keyper replicate the persisted state of the primary
+// keyper and take over once the primary has stopped working. The primary serves a heartbeat and
+// its state file via the admin API. The standby shares the primary's keys, authenticates with
+// admin tokens, and polls both. When the primary hasn't made progress for the failover delay, the
+// standby fences it, i.e. tells it to stop, and returns so that the caller can start the keyper
+// from the replicated state.
+package standby
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/medley"
+)
+
+// Paths of the endpoints served by Handler.
+const (
+	HeartbeatPath = "/standby/heartbeat"
+	StatePath     = "/standby/state"
+	FencePath     = "/standby/fence"
+)
+
+// requestTimeout limits a single request to the primary.
+const requestTimeout = 30 * time.Second
+
+// minPollInterval is the minimum time between two heartbeat requests.
+var minPollInterval = time.Second
+
+// Heartbeat is the primary's report of its progress.
+type Heartbeat struct {
+	ActionCounter uint64
+	SyncHeight    int64
+	StepAge       time.Duration // time since the primary's last decider step
+}
+
+// Handler serves the primary's side of the replication. Mount it on the admin API, behind an
+// access check only accepting security admins, e.g. the keyper itself.
+type Handler struct {
+	StateFile string                   // path of the persisted state
+	Heartbeat func() (Heartbeat, bool) // false if there hasn't been a step yet
+	Fence     func()                   // stops the primary
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == HeartbeatPath && r.Method == http.MethodGet:
+		hb, ok := h.Heartbeat()
+		if !ok {
+			http.Error(w, "no state yet", http.StatusServiceUnavailable)
+			return
+		}
+		httpapi.WriteJSON(w, hb)
+	case r.URL.Path == StatePath && r.Method == http.MethodGet:
+		// The state file is replaced atomically, so we always read a complete one.
+		d, err := ioutil.ReadFile(h.StateFile)
+		if err != nil {
+			http.Error(w, "cannot read state", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(d)
+	case r.URL.Path == FencePath && r.Method == http.MethodPost:
+		log.Printf("Fenced by standby keyper, stopping")
+		h.Fence()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// StatusError is returned by the client if the primary responded with an error. The primary is
+// still running in that case.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("primary responded with %d: %s", e.Code, e.Body)
+}
+
+// Client talks to the admin API of the primary.
+type Client struct {
+	URL  string            // base URL of the primary's admin API
+	Key  *ecdsa.PrivateKey // the signing key shared by primary and standby
+	HTTP *http.Client
+}
+
+// NewClient creates a client for the admin API at the given URL.
+func NewClient(url string, key *ecdsa.PrivateKey) *Client {
+	return &Client{
+		URL:  strings.TrimSuffix(url, "/"),
+		Key:  key,
+		HTTP: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string) ([]byte, error) {
+	audience := access.Audience(access.AdminAPI, ethcrypto.PubkeyToAddress(c.Key.PublicKey))
+	token, err := access.MakeToken(c.Key, audience, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", access.Authorization(token))
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, &StatusError{Code: res.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	return body, nil
+}
+
+// Heartbeat fetches the primary's heartbeat.
+func (c *Client) Heartbeat(ctx context.Context) (Heartbeat, error) {
+	body, err := c.do(ctx, http.MethodGet, HeartbeatPath)
+	if err != nil {
+		return Heartbeat{}, err
+	}
+	var hb Heartbeat
+	if err := json.Unmarshal(body, &hb); err != nil {
+		return Heartbeat{}, errors.Wrap(err, "invalid heartbeat")
+	}
+	return hb, nil
+}
+
+// State fetches the primary's persisted state.
+func (c *Client) State(ctx context.Context) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, StatePath)
+}
+
+// Fence tells the primary to stop.
+func (c *Client) Fence(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodPost, FencePath)
+	return err
+}
+
+// Follow replicates the primary's state, passing it to save whenever the primary reports
+// progress. It returns once the primary is considered down, i.e. it hasn't been reachable or
+// hasn't made a step for failoverDelay, and either has been fenced or cannot be reached anymore.
+// A primary that is reachable but refuses to be fenced is not taken over from, to prevent both
+// from signing messages, and neither is one whose state hasn't been replicated at all.
+func Follow(ctx context.Context, c *Client, failoverDelay time.Duration, save func(state []byte) error) error {
+	pollInterval := failoverDelay / 6
+	if pollInterval < minPollInterval {
+		pollInterval = minPollInterval
+	}
+	var last Heartbeat
+	replicated := false
+	lastHealthy := time.Now()
+	for {
+		hb, err := c.Heartbeat(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch {
+		case err != nil:
+			log.Printf("Standby: no heartbeat from primary: %s", err)
+		case hb.StepAge > failoverDelay:
+			log.Printf("Standby: primary hasn't made a step for %s", hb.StepAge)
+		default:
+			lastHealthy = time.Now()
+			if !replicated || hb.ActionCounter != last.ActionCounter || hb.SyncHeight != last.SyncHeight {
+				state, err := c.State(ctx)
+				if err != nil {
+					log.Printf("Standby: failed to fetch state from primary: %s", err)
+					break
+				}
+				if err := save(state); err != nil {
+					return err
+				}
+				last = hb
+				replicated = true
+			}
+		}
+
+		if time.Since(lastHealthy) >= failoverDelay {
+			if !replicated {
+				log.Printf("Standby: primary is down, but there's no replicated state to take over with")
+			} else if fence(ctx, c) {
+				return nil
+			}
+		}
+		medley.Sleep(ctx, pollInterval)
+	}
+}
+
+// fence tries to fence the primary and returns true if we may take over, i.e. if the primary has
+// been fenced or cannot be reached.
+func fence(ctx context.Context, c *Client) bool {
+	err := c.Fence(ctx)
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		log.Printf("Standby: fenced primary, taking over")
+		return true
+	case errors.As(err, &statusErr):
+		log.Printf("Standby: primary refused to be fenced, not taking over: %s", err)
+		return false
+	case ctx.Err() != nil:
+		return false
+	default:
+		log.Printf("Standby: primary unreachable, taking over: %s", err)
+		return true
+	}
+}
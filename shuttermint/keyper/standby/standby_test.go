@@ -0,0 +1,128 @@
+package standby
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/access"
+)
+
+func init() {
+	minPollInterval = 10 * time.Millisecond
+}
+
+type fakePrimary struct {
+	mux       sync.Mutex
+	heartbeat Heartbeat
+	fenced    bool
+	refuse    bool // reject fencing
+}
+
+func (p *fakePrimary) setStepAge(age time.Duration) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.heartbeat.StepAge = age
+}
+
+func (p *fakePrimary) isFenced() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.fenced
+}
+
+func newPrimary(t *testing.T, refuse bool) (*fakePrimary, *httptest.Server, *Client) {
+	t.Helper()
+	key, err := ethcrypto.GenerateKey()
+	assert.NilError(t, err)
+	self := ethcrypto.PubkeyToAddress(key.PublicKey)
+	checker := access.NewChecker(access.Closed, access.Audience(access.AdminAPI, self), func(addr common.Address) bool {
+		return addr == self
+	})
+
+	stateFile := filepath.Join(t.TempDir(), "state.gob")
+	assert.NilError(t, ioutil.WriteFile(stateFile, []byte("state"), 0o600))
+	p := &fakePrimary{refuse: refuse}
+	h := &Handler{
+		StateFile: stateFile,
+		Heartbeat: func() (Heartbeat, bool) {
+			p.mux.Lock()
+			defer p.mux.Unlock()
+			return p.heartbeat, true
+		},
+		Fence: func() {
+			p.mux.Lock()
+			defer p.mux.Unlock()
+			p.fenced = true
+		},
+	}
+	srv := httptest.NewServer(checker.RequireHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == FencePath && p.refuse {
+			http.Error(w, "no", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})))
+	t.Cleanup(srv.Close)
+	return p, srv, NewClient(srv.URL+"/", key)
+}
+
+func TestFollowUnreachable(t *testing.T) {
+	_, srv, c := newPrimary(t, false)
+	var mux sync.Mutex
+	var saved []string
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		srv.Close()
+	}()
+	err := Follow(context.Background(), c, 100*time.Millisecond, func(state []byte) error {
+		mux.Lock()
+		defer mux.Unlock()
+		saved = append(saved, string(state))
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, saved, []string{"state"})
+}
+
+func TestFollowFencesStuckPrimary(t *testing.T) {
+	p, _, c := newPrimary(t, false)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		p.setStepAge(time.Hour)
+	}()
+	err := Follow(context.Background(), c, 100*time.Millisecond, func([]byte) error { return nil })
+	assert.NilError(t, err)
+	assert.Assert(t, p.isFenced())
+}
+
+func TestFollowDoesNotTakeOver(t *testing.T) {
+	// a primary refusing to be fenced is not taken over from
+	p, _, c := newPrimary(t, true)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		p.setStepAge(time.Hour)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err := Follow(ctx, c, 50*time.Millisecond, func([]byte) error { return nil })
+	assert.Equal(t, err, context.DeadlineExceeded)
+	assert.Assert(t, !p.isFenced())
+
+	// neither is one we haven't replicated the state of
+	_, srv, c := newPrimary(t, false)
+	srv.Close()
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err = Follow(ctx, c, 50*time.Millisecond, func([]byte) error { return nil })
+	assert.Equal(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,121 @@
+package keyper
+
+import (
+	"encoding/gob"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// ErrNoStoredState is returned by StateStore.Load if no state has been saved yet.
+var ErrNoStoredState = errors.New("no keyper state has been saved yet")
+
+// StoredState is what the keyper persists after every decider step: the decider's State,
+// including the DKGs and EKGs, and the observed chains it has been decided on.
+type StoredState struct {
+	State     *State
+	Shutter   *observe.Shutter
+	MainChain *observe.MainChain
+}
+
+// StateStore persists the keyper's state. The keyper saves its state after every decider step and
+// loads it on startup.
+type StateStore interface {
+	// Load returns the saved state. It returns ErrNoStoredState if no state has been saved yet.
+	Load() (StoredState, error)
+	// Save replaces the saved state. The new state must be durable once Save returns, and the old
+	// one must stay intact if saving fails.
+	Save(st StoredState) error
+}
+
+// GobStateStore stores the state gob encoded in the file state.gob in a directory. It is the
+// format standby keypers replicate and the replay and snapshot tools read.
+type GobStateStore struct {
+	Dir string
+}
+
+// NewGobStateStore creates a store saving the state in the given directory.
+func NewGobStateStore(dir string) *GobStateStore {
+	return &GobStateStore{Dir: dir}
+}
+
+// Path returns the path of the state file.
+func (s *GobStateStore) Path() string {
+	return filepath.Join(s.Dir, "state.gob")
+}
+
+// Load reads the state file.
+func (s *GobStateStore) Load() (StoredState, error) {
+	path := s.Path()
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return StoredState{}, ErrNoStoredState
+	} else if err != nil {
+		return StoredState{}, errors.Wrap(err, "failed to open keyper state")
+	}
+	defer file.Close()
+	st, err := decodeStoredState(file)
+	if err != nil {
+		return StoredState{}, errors.Wrapf(err, "failed to decode keyper state %s", path)
+	}
+	return st, nil
+}
+
+// Save replaces the state file. The new state is written to a temporary file first, so that the
+// old one stays intact if writing fails, and the directory is synced after the rename, so that the
+// new state survives a crash of the host.
+func (s *GobStateStore) Save(st StoredState) error {
+	path := s.Path()
+	tmppath := path + ".tmp"
+	file, err := os.Create(tmppath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	enc := gob.NewEncoder(file)
+	err = enc.Encode(st)
+	if err != nil {
+		return err
+	}
+
+	err = file.Sync()
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmppath, path)
+	if err != nil {
+		return err
+	}
+	return syncDir(s.Dir)
+}
+
+// decodeStoredState decodes a gob encoded state, e.g. a state file or a state replicated from the
+// primary keyper.
+func decodeStoredState(r io.Reader) (StoredState, error) {
+	dec := gob.NewDecoder(r)
+	st := StoredState{}
+	err := dec.Decode(&st)
+	if err != nil {
+		return StoredState{}, err
+	}
+	if st.State.SyncHeight == 0 && st.Shutter.CurrentBlock > 0 {
+		log.Printf("Fixing SyncHeight: %d", st.Shutter.CurrentBlock)
+		st.State.SyncHeight = st.Shutter.CurrentBlock // We didn't have this field in older versions
+	}
+	return st, nil
+}
+
+// syncDir flushes the directory entries of dir to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,77 @@
+package keyper
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// memoryStateStore keeps the state in memory.
+type memoryStateStore struct {
+	st    *StoredState
+	saves int
+}
+
+func (s *memoryStateStore) Load() (StoredState, error) {
+	if s.st == nil {
+		return StoredState{}, ErrNoStoredState
+	}
+	return *s.st, nil
+}
+
+func (s *memoryStateStore) Save(st StoredState) error {
+	s.st = &st
+	s.saves++
+	return nil
+}
+
+func TestGobStateStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewGobStateStore(dir)
+	_, err := store.Load()
+	assert.Equal(t, err, ErrNoStoredState)
+
+	st := StoredState{State: NewState(), Shutter: observe.NewShutter(), MainChain: observe.NewMainChain(0)}
+	st.State.SyncHeight = 5
+	st.State.LastEonStarted = 2
+	assert.NilError(t, store.Save(st))
+	st.State.SyncHeight = 6
+	assert.NilError(t, store.Save(st))
+
+	loaded, err := store.Load()
+	assert.NilError(t, err)
+	assert.Equal(t, loaded.State.SyncHeight, int64(6))
+	assert.Equal(t, loaded.State.LastEonStarted, uint64(2))
+	_, err = os.Stat(filepath.Join(dir, "state.gob.tmp"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	assert.NilError(t, ioutil.WriteFile(store.Path(), []byte("garbage"), 0o600))
+	_, err = store.Load()
+	assert.ErrorContains(t, err, "failed to decode keyper state")
+
+	err = NewGobStateStore(filepath.Join(dir, "missing")).Save(st)
+	assert.Assert(t, err != nil)
+}
+
+func TestKeyperStateStore(t *testing.T) {
+	store := &memoryStateStore{}
+	kpr := NewKeyper(Config{})
+	kpr.stateStore = store
+	assert.NilError(t, kpr.LoadState())
+	assert.Equal(t, kpr.State.SyncHeight, int64(0))
+
+	kpr.State.SyncHeight = 7
+	assert.NilError(t, kpr.saveState())
+	assert.Equal(t, store.saves, 1)
+
+	restarted := NewKeyper(Config{})
+	restarted.stateStore = store
+	assert.NilError(t, restarted.LoadState())
+	assert.Equal(t, restarted.State.SyncHeight, int64(7))
+	assert.Equal(t, restarted.CurrentWorld().Shutter, kpr.CurrentWorld().Shutter)
+}
@@ -0,0 +1,119 @@
+package keyper
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/httpapi"
+	"github.com/shutter-network/shutter/shuttermint/keyper/latency"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// statusErrors is the number of most recent dead letters Status.Errors contains.
+const statusErrors = 10
+
+// DKGStatus describes a DKG.
+type DKGStatus struct {
+	Eon         uint64
+	Phase       string
+	NumKeypers  int
+	StartHeight int64 // shuttermint height the eon started at, 0 if unknown
+}
+
+// Status is an overview of what the keyper is doing, for operators watching it, e.g. with
+// "keyper top".
+type Status struct {
+	Time time.Time // of the step the status is based on
+
+	ShutterHeight         int64
+	MainChainHeight       uint64
+	MainChainHead         uint64 // latest block known to the main chain node
+	NumExecutionHalfSteps uint64
+	Syncing               bool // catching up with the chains after startup
+
+	ActiveEon *uint64
+	DKGs      []DKGStatus // in progress
+	EKGs      []uint64    // eons we've generated keys for
+
+	PendingActions []fx.PendingActionInfo
+	Errors         []fx.DeadLetterInfo // most recent first
+	SkippedSteps   map[string]uint64   // by reason
+	Latency        latency.Report
+}
+
+// computeStatus computes the parts of the status derived from the state and the observed chains.
+func computeStatus(st *State, shutter *observe.Shutter, mainChain *observe.MainChain) Status {
+	status := Status{
+		ShutterHeight:         shutter.CurrentBlock,
+		MainChainHeight:       mainChain.CurrentBlock,
+		MainChainHead:         mainChain.HeadBlock,
+		NumExecutionHalfSteps: mainChain.NumExecutionHalfSteps,
+		EKGs:                  []uint64{},
+		DKGs:                  []DKGStatus{},
+	}
+	batchIndex := uint64(protocol.NextBatchIndex(mainChain.NumExecutionHalfSteps))
+	if n, ok := st.ActiveEon(batchIndex); ok {
+		status.ActiveEon = &n
+	}
+	for _, dkg := range st.DKGs {
+		if !dkg.IsFinalized() {
+			status.DKGs = append(status.DKGs, dkgStatus(dkg, shutter))
+		}
+	}
+	for _, ekg := range st.EKGs {
+		status.EKGs = append(status.EKGs, ekg.Eon)
+	}
+	return status
+}
+
+func dkgStatus(dkg DKG, shutter *observe.Shutter) DKGStatus {
+	ds := DKGStatus{Eon: dkg.Eon, Phase: puredkg.Finalized.String(), NumKeypers: len(dkg.Keypers)}
+	if dkg.Pure != nil {
+		ds.Phase = dkg.Pure.Phase.String()
+	}
+	if eon, err := shutter.FindEon(dkg.Eon); err == nil {
+		ds.StartHeight = eon.StartHeight
+	}
+	return ds
+}
+
+// updateStatus publishes the parts of the status only the decider's goroutine may access, so that
+// the status can be served while the decider keeps updating the state.
+func (kpr *Keyper) updateStatus() {
+	world := kpr.CurrentWorld()
+	status := computeStatus(kpr.State, world.Shutter, world.MainChain)
+	status.Time = time.Now()
+	status.Syncing = kpr.syncing
+	status.SkippedSteps = make(map[string]uint64)
+	for reason, n := range kpr.skippedSteps {
+		status.SkippedSteps[reason] = n
+	}
+	kpr.status.Store(status)
+}
+
+func (kpr *Keyper) statusHandler() http.Handler {
+	return http.HandlerFunc(kpr.serveStatus)
+}
+
+// serveStatus serves the keyper's status as JSON, the pending actions, errors and latencies as of
+// now.
+func (kpr *Keyper) serveStatus(w http.ResponseWriter, _ *http.Request) {
+	status, ok := kpr.status.Load().(Status)
+	if !ok {
+		http.Error(w, "status not available yet", http.StatusServiceUnavailable)
+		return
+	}
+	status.PendingActions = kpr.runenv.PendingActions.Info()
+	status.Errors = []fx.DeadLetterInfo{}
+	if kpr.runenv.DeadLetters != nil {
+		status.Errors = kpr.runenv.DeadLetters.Info()
+		if len(status.Errors) > statusErrors {
+			status.Errors = status.Errors[:statusErrors]
+		}
+	}
+	status.Latency = kpr.latency.Report()
+	httpapi.WriteJSON(w, status)
+}
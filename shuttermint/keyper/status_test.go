@@ -0,0 +1,75 @@
+package keyper
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestComputeStatus(t *testing.T) {
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 500
+	shutter.Eons = []observe.Eon{{Eon: 4, StartHeight: 450}}
+	mainChain := observe.NewMainChain(0)
+	mainChain.CurrentBlock = 1000
+	mainChain.HeadBlock = 1002
+
+	st := NewState()
+	st.EonActivations = []EonActivation{{Eon: 3, StartBatchIndex: 0, EndBatchIndex: math.MaxUint64}}
+	st.EKGs = []*EKG{{Eon: 2}, {Eon: 3}}
+	keypers := []common.Address{common.HexToAddress("0x1"), common.HexToAddress("0x2")}
+	pure := puredkg.NewPureDKG(4, 2, 2, 0)
+	st.DKGs = []DKG{
+		{Eon: 3, Keypers: keypers},
+		{Eon: 4, Keypers: keypers, Pure: &pure},
+	}
+
+	status := computeStatus(st, shutter, mainChain)
+	assert.Equal(t, status.ShutterHeight, int64(500))
+	assert.Equal(t, status.MainChainHeight, uint64(1000))
+	assert.Equal(t, status.MainChainHead, uint64(1002))
+	assert.Equal(t, *status.ActiveEon, uint64(3))
+	assert.DeepEqual(t, status.EKGs, []uint64{2, 3})
+	assert.DeepEqual(t, status.DKGs, []DKGStatus{{Eon: 4, Phase: "Off", NumKeypers: 2, StartHeight: 450}})
+}
+
+func TestServeStatus(t *testing.T) {
+	k := NewKeyper(Config{})
+	k.world.Store(observe.World{Shutter: observe.NewShutter(), MainChain: observe.NewMainChain(0)})
+	k.runenv = fx.NewRunEnv(nil, nil, k.CurrentWorld, filepath.Join(t.TempDir(), "actions.gob"))
+	k.runenv.DeadLetters = fx.NewDeadLetters(filepath.Join(t.TempDir(), "dead.gob"))
+
+	rec := httptest.NewRecorder()
+	k.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+	assert.Equal(t, rec.Code, http.StatusServiceUnavailable)
+
+	k.skippedSteps = map[string]uint64{"bad state": 2}
+	k.updateStatus()
+	k.skippedSteps["bad state"] = 3
+	k.runenv.PendingActions.AddActions(0, []fx.IAction{&fx.SendShuttermintMessage{Description: "check in"}})
+	for i := 0; i < statusErrors+1; i++ {
+		k.runenv.DeadLetters.Add(fx.ActionID(i), &fx.SkipCipherBatch{}, errors.New("failed"))
+	}
+
+	rec = httptest.NewRecorder()
+	k.statusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/status", nil))
+	assert.Equal(t, rec.Code, http.StatusOK)
+	var status Status
+	assert.NilError(t, json.NewDecoder(rec.Body).Decode(&status))
+	assert.DeepEqual(t, status.SkippedSteps, map[string]uint64{"bad state": 2})
+	assert.Equal(t, len(status.PendingActions), 1)
+	assert.Equal(t, status.PendingActions[0].Action, "=> shuttermint: check in")
+	assert.Equal(t, len(status.Errors), statusErrors)
+	assert.Equal(t, status.Errors[0].ID, fx.ActionID(statusErrors))
+}
@@ -0,0 +1,204 @@
+package keyper
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// Files in a support bundle. state.json is meant to be read by humans, state.gob by the replay
+// tooling, see Replayer.LoadSupportBundle.
+const (
+	bundleVersionFile = "version.json"
+	bundleConfigFile  = "config.toml"
+	bundleStateJSON   = "state.json"
+	bundleStateGob    = "state.gob"
+	bundleTracesFile  = "traces.json"
+	bundleLogFile     = "keyper.log"
+)
+
+// SupportBundle describes a keyper for attaching to bug reports. It must not contain any
+// secrets: the state is redacted with RedactState, the config with Config.EffectiveValues, and
+// the log with Config.Redact.
+type SupportBundle struct {
+	Created   time.Time
+	Version   json.RawMessage // version information, see shversion.GetInfo
+	Config    []string        // the effective config, one line per key
+	State     *State
+	Shutter   *observe.Shutter
+	MainChain *observe.MainChain
+	Traces    json.RawMessage // recent decision traces as served by the admin API, nil if unavailable
+	Log       []byte          // recent log lines, nil if unavailable
+}
+
+// NewSupportBundle creates a bundle from the keyper's state file in DBDir and its config.
+// sources are passed to Config.EffectiveValues.
+func NewSupportBundle(config Config, sources map[string]string) (*SupportBundle, error) {
+	st, err := NewGobStateStore(config.DBDir).Load()
+	if err != nil {
+		return nil, err
+	}
+	return &SupportBundle{
+		Created:   time.Now(),
+		Config:    config.EffectiveValues(sources),
+		State:     RedactState(st.State),
+		Shutter:   st.Shutter,
+		MainChain: st.MainChain,
+	}, nil
+}
+
+// RedactState returns a copy of the state without the secrets of our DKGs and EKGs, i.e. our
+// polynomials, the poly evals we've received or are about to send, and our eon secret key
+// shares. The copy shares everything else with st.
+func RedactState(st *State) *State {
+	redacted := *st
+	redacted.DKGs = make([]DKG, len(st.DKGs))
+	for i, dkg := range st.DKGs {
+		dkg.Pure = nil
+		dkg.OutgoingPolyEvalMsgs = nil
+		dkg.Apologies = nil
+		redacted.DKGs[i] = dkg
+	}
+	redacted.EKGs = make([]*EKG, len(st.EKGs))
+	for i, ekg := range st.EKGs {
+		e := *ekg
+		e.EpochKG = nil
+		redacted.EKGs[i] = &e
+	}
+	return &redacted
+}
+
+// Redact replaces our secret keys in the given text, e.g. a log, by "<redacted>".
+func (config *Config) Redact(text []byte) []byte {
+	var secrets []string
+	if config.SigningKey != nil {
+		secrets = append(secrets, hex.EncodeToString(crypto.FromECDSA(config.SigningKey)))
+	}
+	if config.EncryptionKey != nil {
+		secrets = append(secrets, hex.EncodeToString(crypto.FromECDSA(config.EncryptionKey.ExportECDSA())))
+	}
+	if config.ValidatorKey != nil {
+		secrets = append(secrets, hex.EncodeToString(config.ValidatorKey.Seed()))
+	}
+	for _, secret := range secrets {
+		text = bytes.ReplaceAll(text, []byte(secret), []byte("<redacted>"))
+		text = bytes.ReplaceAll(text, bytes.ToUpper([]byte(secret)), []byte("<redacted>"))
+	}
+	return text
+}
+
+// canonicalJSON encodes v as indented JSON. Map keys are sorted, so encoding the same value
+// always results in the same bytes and bundles can be diffed.
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Write writes the bundle as a gzipped tar archive.
+func (b *SupportBundle) Write(w io.Writer) error {
+	st := StoredState{State: b.State, Shutter: b.Shutter, MainChain: b.MainChain}
+	stateJSON, err := canonicalJSON(st)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state as JSON")
+	}
+	stateGob := new(bytes.Buffer)
+	if err := gob.NewEncoder(stateGob).Encode(st); err != nil {
+		return errors.Wrap(err, "failed to encode state")
+	}
+	var config bytes.Buffer
+	for _, line := range b.Config {
+		config.WriteString(line + "\n")
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{bundleVersionFile, b.Version},
+		{bundleConfigFile, config.Bytes()},
+		{bundleStateJSON, stateJSON},
+		{bundleStateGob, stateGob.Bytes()},
+		{bundleTracesFile, b.Traces},
+		{bundleLogFile, b.Log},
+	}
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		if f.data == nil {
+			continue
+		}
+		hdr := &tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.data)), ModTime: b.Created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// ReadSupportBundle reads a bundle written by SupportBundle.Write.
+func ReadSupportBundle(r io.Reader) (*SupportBundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "not a support bundle")
+	}
+	tr := tar.NewReader(gz)
+	b := &SupportBundle{}
+	haveState := false
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read support bundle")
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s from support bundle", hdr.Name)
+		}
+		b.Created = hdr.ModTime
+		switch hdr.Name {
+		case bundleVersionFile:
+			b.Version = data
+		case bundleConfigFile:
+			for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+				b.Config = append(b.Config, string(line))
+			}
+		case bundleStateGob:
+			st, err := decodeStoredState(bytes.NewReader(data))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode state of support bundle")
+			}
+			b.State, b.Shutter, b.MainChain = st.State, st.Shutter, st.MainChain
+			haveState = true
+		case bundleTracesFile:
+			b.Traces = data
+		case bundleLogFile:
+			b.Log = data
+		}
+	}
+	if !haveState {
+		return nil, errors.Errorf("support bundle lacks %s", bundleStateGob)
+	}
+	return b, nil
+}
@@ -0,0 +1,66 @@
+package keyper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/puredkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/epochkg"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestSupportBundle(t *testing.T) {
+	pure := puredkg.NewPureDKG(1, 2, 2, 0)
+	st := NewState()
+	st.DKGs = []DKG{{Eon: 1, Pure: &pure, OutgoingPolyEvalMsgs: []puredkg.PolyEvalMsg{{Eon: 1}}}}
+	st.EKGs = []*EKG{{Eon: 1, EpochKG: &epochkg.EpochKG{}}}
+	encryptionKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	shutter := observe.NewShutter()
+	shutter.KeyperEncryptionKeys[common.Address{}] = (*observe.EncryptionPublicKey)(
+		ecies.ImportECDSAPublic(&encryptionKey.PublicKey))
+
+	b := &SupportBundle{
+		Created:   time.Unix(1600000000, 0),
+		Version:   []byte(`{"version":"test"}`),
+		Config:    []string{"DBDir = \"db\"", "SigningKey = \"<redacted>\""},
+		State:     RedactState(st),
+		Shutter:   shutter,
+		MainChain: observe.NewMainChain(0),
+	}
+	assert.Assert(t, st.DKGs[0].Pure != nil) // the original state is untouched
+	assert.Assert(t, st.EKGs[0].EpochKG != nil)
+
+	buf := new(bytes.Buffer)
+	assert.NilError(t, b.Write(buf))
+	again := new(bytes.Buffer)
+	assert.NilError(t, b.Write(again))
+	assert.DeepEqual(t, buf.Bytes(), again.Bytes())
+
+	read, err := ReadSupportBundle(buf)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, read.Config, b.Config)
+	assert.Equal(t, string(read.Version), string(b.Version))
+	assert.Assert(t, read.Log == nil)
+	assert.Equal(t, len(read.State.DKGs), 1)
+	assert.Assert(t, read.State.DKGs[0].Pure == nil)
+	assert.Equal(t, len(read.State.DKGs[0].OutgoingPolyEvalMsgs), 0)
+	assert.Assert(t, read.State.EKGs[0].EpochKG == nil)
+	assert.Equal(t, len(read.Shutter.KeyperEncryptionKeys), 1)
+}
+
+func TestConfigRedact(t *testing.T) {
+	signingKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	config := Config{SigningKey: signingKey}
+	secret := hex.EncodeToString(crypto.FromECDSA(signingKey))
+	text := []byte("loaded key " + secret + "\n")
+	assert.Equal(t, string(config.Redact(text)), "loaded key <redacted>\n")
+}
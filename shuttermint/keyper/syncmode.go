@@ -0,0 +1,84 @@
+package keyper
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+// syncLogInterval is the minimum time between two sync progress log messages.
+const syncLogInterval = 10 * time.Second
+
+// syncProgress tracks how fast we catch up with the chains in order to estimate when we'll be
+// done.
+type syncProgress struct {
+	start          time.Time
+	startRemaining uint64
+	lastLog        time.Time
+}
+
+// eta estimates the time until no blocks remain. It returns false if no estimate is possible
+// yet.
+func (p *syncProgress) eta(now time.Time, remaining uint64) (time.Duration, bool) {
+	if p.start.IsZero() || remaining > p.startRemaining {
+		p.start = now
+		p.startRemaining = remaining
+		return 0, false
+	}
+	done := p.startRemaining - remaining
+	elapsed := now.Sub(p.start)
+	if done == 0 || elapsed <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(elapsed) / float64(done) * float64(remaining)), true
+}
+
+// syncStatus describes how far behind the observed state of both chains is.
+func syncStatus(world observe.World) string {
+	shutter := fmt.Sprintf("%d blocks behind", world.Shutter.BlocksBehind())
+	if !world.Shutter.IsSynced() {
+		shutter = "node catching up"
+	}
+	mainChain := fmt.Sprintf("%d blocks behind", world.MainChain.BlocksBehind())
+	if !world.MainChain.IsSynced() {
+		mainChain = fmt.Sprintf("node syncing, %s", mainChain)
+	}
+	return fmt.Sprintf("shuttermint %s, main chain %s", shutter, mainChain)
+}
+
+// isWithinSyncTolerance checks if both chains have been synced up to SyncTolerance blocks.
+func (kpr *Keyper) isWithinSyncTolerance(world observe.World) bool {
+	return world.Shutter.IsSynced() &&
+		world.MainChain.IsSynced() &&
+		world.Shutter.BlocksBehind() <= kpr.Config.SyncTolerance &&
+		world.MainChain.BlocksBehind() <= kpr.Config.SyncTolerance
+}
+
+// checkSyncing returns true while the keyper is still in syncing mode after startup. In syncing
+// mode we don't make any decisions, because they would be based on outdated state. Syncing mode
+// ends once we're within SyncTolerance blocks of both chain tips.
+func (kpr *Keyper) checkSyncing(world observe.World) bool {
+	if !kpr.syncing {
+		return false
+	}
+	now := time.Now()
+	if kpr.isWithinSyncTolerance(world) {
+		kpr.syncing = false
+		log.Printf("Sync complete after %s, %s", now.Sub(kpr.syncStarted).Round(time.Second), syncStatus(world))
+		return false
+	}
+
+	remaining := world.Shutter.BlocksBehind() + world.MainChain.BlocksBehind()
+	eta, ok := kpr.syncProgress.eta(now, remaining)
+	if now.Sub(kpr.syncProgress.lastLog) >= syncLogInterval {
+		kpr.syncProgress.lastLog = now
+		etaInfo := "unknown"
+		if ok {
+			etaInfo = eta.Round(time.Second).String()
+		}
+		log.Printf("Syncing: %s, ETA %s", syncStatus(world), etaInfo)
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package keyper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	rpctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+)
+
+func TestSyncProgressETA(t *testing.T) {
+	p := syncProgress{}
+	start := time.Unix(1000, 0)
+	_, ok := p.eta(start, 1000)
+	assert.Assert(t, !ok)
+	_, ok = p.eta(start.Add(time.Second), 1000)
+	assert.Assert(t, !ok)
+
+	eta, ok := p.eta(start.Add(10*time.Second), 900)
+	assert.Assert(t, ok)
+	assert.Equal(t, eta, 90*time.Second)
+
+	// falling behind restarts the estimate
+	_, ok = p.eta(start.Add(20*time.Second), 2000)
+	assert.Assert(t, !ok)
+	eta, ok = p.eta(start.Add(30*time.Second), 1000)
+	assert.Assert(t, ok)
+	assert.Equal(t, eta, 10*time.Second)
+}
+
+func TestCheckSyncing(t *testing.T) {
+	kpr := NewKeyper(Config{SyncTolerance: 5})
+	shutter := observe.NewShutter()
+	shutter.CurrentBlock = 100
+	shutter.NodeStatus = &rpctypes.ResultStatus{}
+	shutter.NodeStatus.SyncInfo.LatestBlockHeight = 200
+	mainChain := observe.NewMainChain(10)
+	mainChain.CurrentBlock = 50
+	mainChain.HeadBlock = 62
+	world := observe.World{Shutter: shutter, MainChain: mainChain}
+
+	assert.Equal(t, shutter.BlocksBehind(), uint64(100))
+	assert.Equal(t, mainChain.BlocksBehind(), uint64(2))
+	assert.Assert(t, kpr.checkSyncing(world))
+
+	shutter.CurrentBlock = 196
+	mainChain.NodeSyncProgress = &ethereum.SyncProgress{HighestBlock: 1000}
+	assert.Assert(t, kpr.checkSyncing(world))
+	mainChain.NodeSyncProgress = nil
+	shutter.NodeStatus.SyncInfo.CatchingUp = true
+	assert.Assert(t, kpr.checkSyncing(world))
+	shutter.NodeStatus.SyncInfo.CatchingUp = false
+
+	assert.Assert(t, !kpr.checkSyncing(world))
+	assert.Assert(t, !kpr.syncing)
+
+	// once synced, we don't go back to syncing mode
+	shutter.CurrentBlock = 100
+	assert.Assert(t, !kpr.checkSyncing(world))
+}
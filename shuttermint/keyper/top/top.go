@@ -0,0 +1,154 @@
+// Package top renders the status the keyper serves on its admin API as a screen for operators, see
+// "keyper top". The screen is plain text, redrawing it is up to the caller.
+package top
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/latency"
+)
+
+// maxListed is the number of entries listed per section, the remaining ones are only counted.
+const maxListed = 10
+
+// staleAfter is the age after which the status is marked as stale, e.g. because the keyper is
+// stuck or doesn't receive new blocks.
+const staleAfter = time.Minute
+
+type screen struct {
+	w   io.Writer
+	err error
+}
+
+func (s *screen) printf(format string, args ...interface{}) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format+"\n", args...)
+}
+
+func (s *screen) section(title string, n int) {
+	s.printf("")
+	s.printf("%s (%d)", title, n)
+}
+
+func (s *screen) more(n int) {
+	if n > maxListed {
+		s.printf("  ... and %d more", n-maxListed)
+	}
+}
+
+// Render writes the screen showing the status as of now.
+func Render(w io.Writer, status keyper.Status, now time.Time) error {
+	s := &screen{w: w}
+
+	age := now.Sub(status.Time).Truncate(time.Second)
+	var stale string
+	if age > staleAfter {
+		stale = ", STALE"
+	}
+	s.printf("keyper top - %s, status %s old%s", now.Format("15:04:05"), age, stale)
+
+	var syncing string
+	if status.Syncing {
+		syncing = ", catching up after startup"
+	}
+	var behind uint64
+	if status.MainChainHead > status.MainChainHeight {
+		behind = status.MainChainHead - status.MainChainHeight
+	}
+	s.printf("sync:    shuttermint %d, main chain %d (%d behind head), %d half steps%s",
+		status.ShutterHeight, status.MainChainHeight, behind, status.NumExecutionHalfSteps, syncing)
+
+	activeEon := "none"
+	if status.ActiveEon != nil {
+		activeEon = fmt.Sprint(*status.ActiveEon)
+	}
+	s.printf("eons:    active %s, keys for %s", activeEon, formatEons(status.EKGs))
+	for _, dkg := range status.DKGs {
+		s.printf("dkg:     eon %d in phase %s, %d keypers, started at %d",
+			dkg.Eon, dkg.Phase, dkg.NumKeypers, dkg.StartHeight)
+	}
+	s.printf("latency: shares %s", formatLatency(status.Latency.SharePublication))
+	s.printf("         keys   %s", formatLatency(status.Latency.KeyGeneration))
+	if status.Latency.SLO != 0 {
+		s.printf("         SLO %s, %d violations", status.Latency.SLO, status.Latency.Violations)
+	}
+
+	var messages, txs []fx.PendingActionInfo
+	for _, a := range status.PendingActions {
+		if a.MainChainTX {
+			txs = append(txs, a)
+		} else {
+			messages = append(messages, a)
+		}
+	}
+	s.section("outgoing shuttermint messages", len(messages))
+	for i, a := range messages {
+		if i == maxListed {
+			break
+		}
+		s.printf("  %6d %s", a.ID, a.Action)
+	}
+	s.more(len(messages))
+
+	s.section("pending main chain transactions", len(txs))
+	for i, a := range txs {
+		if i == maxListed {
+			break
+		}
+		tx := "not sent"
+		if a.TXHash != nil {
+			tx = a.TXHash.Hex()
+		}
+		if len(a.Replaced) > 0 {
+			tx += fmt.Sprintf(", replaced %d times", len(a.Replaced))
+		}
+		if a.Attempts > 0 {
+			tx += fmt.Sprintf(", attempt %d", a.Attempts+1)
+		}
+		s.printf("  %6d %s: %s", a.ID, a.Action, tx)
+	}
+	s.more(len(txs))
+
+	s.section("recent errors", len(status.Errors))
+	for i, e := range status.Errors {
+		if i == maxListed {
+			break
+		}
+		s.printf("  %s %s: %s", e.Time.Format("15:04:05"), e.Action, e.Error)
+	}
+	if len(status.SkippedSteps) > 0 {
+		var reasons []string
+		for reason, n := range status.SkippedSteps {
+			reasons = append(reasons, fmt.Sprintf("%s=%d", reason, n))
+		}
+		sort.Strings(reasons)
+		s.printf("  skipped steps: %s", strings.Join(reasons, ", "))
+	}
+	return s.err
+}
+
+func formatEons(eons []uint64) string {
+	if len(eons) == 0 {
+		return "none"
+	}
+	var res []string
+	for _, eon := range eons {
+		res = append(res, fmt.Sprint(eon))
+	}
+	return strings.Join(res, ", ")
+}
+
+func formatLatency(s latency.Summary) string {
+	if s.Count == 0 {
+		return "no measurements yet"
+	}
+	return s.String()
+}
@@ -0,0 +1,67 @@
+package top
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper"
+	"github.com/shutter-network/shutter/shuttermint/keyper/fx"
+	"github.com/shutter-network/shutter/shuttermint/keyper/latency"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	eon := uint64(3)
+	hash := common.HexToHash("0x01")
+	status := keyper.Status{
+		Time:            now.Add(-2 * time.Second),
+		ShutterHeight:   500,
+		MainChainHeight: 1000,
+		MainChainHead:   1002,
+		ActiveEon:       &eon,
+		DKGs:            []keyper.DKGStatus{{Eon: 4, Phase: "Dealing", NumKeypers: 3, StartHeight: 450}},
+		EKGs:            []uint64{2, 3},
+		PendingActions: []fx.PendingActionInfo{
+			{ID: 7, Action: "=> shuttermint: check in"},
+			{ID: 8, Action: "=> main chain: accuse", MainChainTX: true, TXHash: &hash, Replaced: []common.Hash{{}}},
+		},
+		Errors:       []fx.DeadLetterInfo{{ID: 5, Action: "=> main chain: appeal", Error: "reverted", Time: now}},
+		SkippedSteps: map[string]uint64{"shutter behind": 2},
+		Latency:      latency.Report{KeyGeneration: latency.Summary{Count: 1, P50: time.Second}},
+	}
+	for i := 0; i < maxListed+2; i++ {
+		status.PendingActions = append(status.PendingActions, fx.PendingActionInfo{ID: fx.ActionID(100 + i)})
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NilError(t, Render(buf, status, now))
+	screen := buf.String()
+	for _, line := range []string{
+		"keyper top - 12:00:00, status 2s old\n",
+		"sync:    shuttermint 500, main chain 1000 (2 behind head), 0 half steps\n",
+		"eons:    active 3, keys for 2, 3\n",
+		"dkg:     eon 4 in phase Dealing, 3 keypers, started at 450\n",
+		"shares no measurements yet\n",
+		"outgoing shuttermint messages (13)\n",
+		"       7 => shuttermint: check in\n",
+		"  ... and 3 more\n",
+		"pending main chain transactions (1)\n",
+		fmt.Sprintf("       8 => main chain: accuse: %s, replaced 1 times\n", hash.Hex()),
+		"recent errors (1)\n",
+		"  12:00:00 => main chain: appeal: reverted\n",
+		"  skipped steps: shutter behind=2\n",
+	} {
+		assert.Assert(t, strings.Contains(screen, line), "missing %q in\n%s", line, screen)
+	}
+	assert.Assert(t, !strings.Contains(screen, "STALE"))
+
+	buf.Reset()
+	assert.NilError(t, Render(buf, status, now.Add(time.Hour)))
+	assert.Assert(t, strings.Contains(buf.String(), "STALE"))
+}
@@ -0,0 +1,108 @@
+// Package trace records machine-readable traces of the keyper's decisions, i.e. for every step
+// which of the decider's functions emitted actions, the inputs they saw and the actions they
+// emitted. The most recent steps are kept in a ring buffer that is served as JSON, so that it's
+// possible to find out after the fact why the keyper did something.
+package trace
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Inputs are the values a decision has been based on, e.g. heights, indices and flags.
+type Inputs map[string]interface{}
+
+// Decision is the trace of a single decider function.
+type Decision struct {
+	Func    string
+	Inputs  Inputs
+	Fired   bool     // true if the function emitted actions
+	Actions []string `json:",omitempty"`
+}
+
+// Step is the trace of a single decider run.
+type Step struct {
+	Time           time.Time
+	ShutterHeight  int64
+	MainChainBlock uint64
+	Decisions      []Decision
+}
+
+// Buffer keeps the traces of the most recent steps.
+type Buffer struct {
+	mux   sync.Mutex
+	steps []Step
+	next  int // position the next step is written to once the buffer is full
+	size  int
+}
+
+// NewBuffer creates a buffer keeping the given number of steps.
+func NewBuffer(size int) *Buffer {
+	if size <= 0 {
+		panic("trace buffer size must be positive")
+	}
+	return &Buffer{size: size}
+}
+
+// Add adds a step, dropping the oldest one if the buffer is full.
+func (b *Buffer) Add(step Step) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if len(b.steps) < b.size {
+		b.steps = append(b.steps, step)
+		return
+	}
+	b.steps[b.next] = step
+	b.next = (b.next + 1) % b.size
+}
+
+// Steps returns the buffered steps, oldest first.
+func (b *Buffer) Steps() []Step {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	res := make([]Step, 0, len(b.steps))
+	res = append(res, b.steps[b.next:]...)
+	res = append(res, b.steps[:b.next]...)
+	return res
+}
+
+// ServeHTTP serves the buffered steps as JSON, oldest first. The query parameter n limits the
+// response to the n most recent steps, fired=true restricts it to decisions that emitted actions.
+func (b *Buffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	steps := b.Steps()
+	if v := r.URL.Query().Get("n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid n", http.StatusBadRequest)
+			return
+		}
+		if n < len(steps) {
+			steps = steps[len(steps)-n:]
+		}
+	}
+	if r.URL.Query().Get("fired") == "true" {
+		steps = firedOnly(steps)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(steps)
+}
+
+func firedOnly(steps []Step) []Step {
+	res := []Step{}
+	for _, step := range steps {
+		var decisions []Decision
+		for _, d := range step.Decisions {
+			if d.Fired {
+				decisions = append(decisions, d)
+			}
+		}
+		if len(decisions) > 0 {
+			step.Decisions = decisions
+			res = append(res, step)
+		}
+	}
+	return res
+}
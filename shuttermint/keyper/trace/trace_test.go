@@ -0,0 +1,57 @@
+package trace
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func heights(steps []Step) []int64 {
+	res := []int64{}
+	for _, s := range steps {
+		res = append(res, s.ShutterHeight)
+	}
+	return res
+}
+
+func TestBuffer(t *testing.T) {
+	b := NewBuffer(3)
+	assert.DeepEqual(t, heights(b.Steps()), []int64{})
+	for h := int64(1); h <= 5; h++ {
+		b.Add(Step{ShutterHeight: h})
+	}
+	assert.DeepEqual(t, heights(b.Steps()), []int64{3, 4, 5})
+}
+
+func TestServeHTTP(t *testing.T) {
+	b := NewBuffer(10)
+	b.Add(Step{ShutterHeight: 1, Decisions: []Decision{{Func: "maybeAccuse"}}})
+	b.Add(Step{ShutterHeight: 2, Decisions: []Decision{
+		{Func: "maybeAccuse"},
+		{Func: "handleEpochKG", Fired: true, Actions: []string{"share"}},
+	}})
+	b.Add(Step{ShutterHeight: 3})
+
+	get := func(query string) []Step {
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trace"+query, nil))
+		assert.Equal(t, rec.Code, http.StatusOK)
+		var steps []Step
+		assert.NilError(t, json.NewDecoder(rec.Body).Decode(&steps))
+		return steps
+	}
+
+	assert.DeepEqual(t, heights(get("")), []int64{1, 2, 3})
+	assert.DeepEqual(t, heights(get("?n=2")), []int64{2, 3})
+	fired := get("?fired=true")
+	assert.DeepEqual(t, heights(fired), []int64{2})
+	assert.Equal(t, len(fired[0].Decisions), 1)
+	assert.Equal(t, fired[0].Decisions[0].Func, "handleEpochKG")
+
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trace?n=x", nil))
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+}
@@ -0,0 +1,25 @@
+package keyper
+
+import (
+	"log"
+
+	"github.com/shutter-network/shutter/shuttermint/keyper/txpolicy"
+)
+
+// replaceInvalidTransactions returns the decrypted transactions of a batch with the ones
+// violating the transaction policy of the deployment replaced by empty transactions. The policy
+// has been validated at startup. The result only depends on the transactions and on the config
+// options of the policy, which must be the same for all keypers.
+func (dcdr *Decider) replaceInvalidTransactions(batchIndex uint64, txs [][]byte) [][]byte {
+	policy, err := txpolicy.NewPolicy(
+		dcdr.Config.TransactionFormat, dcdr.Config.MaxTransactionSize, dcdr.Config.DeniedTargets,
+	)
+	if err != nil {
+		log.Panicf("invalid transaction policy: %+v", err)
+	}
+	res, rejections := policy.Pipeline().Apply(txs)
+	for _, r := range rejections {
+		log.Printf("Replacing invalid transaction #%d of batch %d: %s", r.Index, batchIndex, r.Err)
+	}
+	return res
+}
@@ -0,0 +1,19 @@
+package keyper
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReplaceInvalidTransactions(t *testing.T) {
+	a, b := []byte("a"), []byte("bb")
+	dcdr := &Decider{}
+	assert.DeepEqual(t, dcdr.replaceInvalidTransactions(1, [][]byte{a, b}), [][]byte{a, b})
+
+	dcdr.Config.MaxTransactionSize = 1
+	assert.DeepEqual(t, dcdr.replaceInvalidTransactions(1, [][]byte{a, b}), [][]byte{a, {}})
+
+	dcdr.Config.TransactionFormat = "proxy"
+	assert.DeepEqual(t, dcdr.replaceInvalidTransactions(1, [][]byte{a, b}), [][]byte{{}, {}})
+}
@@ -0,0 +1,297 @@
+// Package txpolicy validates the decrypted transactions of a cipher batch before it is executed.
+// Each transaction runs through a pipeline of validators, e.g. checking its size, its signature
+// and nonce, or the receiver it is forwarded to. Invalid transactions are replaced by empty ones,
+// so that the target contract rejects them without executing anything.
+//
+// The keypers sign the decrypted batches, so they must agree on the transactions replaced. The
+// validators therefore only depend on the batch and on the policy, which must be the same for
+// all keypers.
+package txpolicy
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Format is the format of the transactions the target contract accepts.
+type Format string
+
+const (
+	// Raw transactions are passed to the target contract as they are, without any assumptions
+	// about their content.
+	Raw Format = "raw"
+	// Proxy transactions are abi.encode(address receiver, bytes data), as forwarded by the
+	// TargetProxyContract.
+	Proxy Format = "proxy"
+	// Signed transactions are abi.encode(bytes payload, uint8 v, bytes32 r, bytes32 s) with
+	// payload abi.encode(uint64 nonce, bytes data), signed by the sender with the Ethereum signed
+	// message prefix over keccak256(payload), as executed by the TestTargetContract.
+	Signed Format = "signed"
+)
+
+// ParseFormat parses a transaction format. The empty string is parsed as Raw.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", Raw:
+		return Raw, nil
+	case Proxy, Signed:
+		return Format(s), nil
+	default:
+		return "", errors.Errorf("invalid transaction format %q, must be %q, %q or %q", s, Raw, Proxy, Signed)
+	}
+}
+
+// Policy is the deployment policy transactions must adhere to.
+type Policy struct {
+	Format        Format
+	MaxSize       uint64 // maximum size of a transaction in bytes, 0 for no limit
+	DeniedTargets []common.Address
+}
+
+// NewPolicy parses a policy from the config options. Denied targets can only be checked for the
+// proxy format, because the receiver isn't part of the transactions of the other formats.
+func NewPolicy(format string, maxSize uint64, deniedTargets []string) (Policy, error) {
+	f, err := ParseFormat(format)
+	if err != nil {
+		return Policy{}, err
+	}
+	policy := Policy{Format: f, MaxSize: maxSize}
+	for _, s := range deniedTargets {
+		if !common.IsHexAddress(s) {
+			return Policy{}, errors.Errorf("invalid denied target %q, must be a hex address", s)
+		}
+		policy.DeniedTargets = append(policy.DeniedTargets, common.HexToAddress(s))
+	}
+	if len(policy.DeniedTargets) > 0 && f != Proxy {
+		return Policy{}, errors.Errorf("denied targets require the %q transaction format, not %q", Proxy, f)
+	}
+	return policy, nil
+}
+
+// Pipeline returns a new pipeline enforcing the policy. Validators keeping state, like the one
+// for nonces, are scoped to the pipeline, so a new one must be used for each batch.
+func (p Policy) Pipeline() Pipeline {
+	var pipeline Pipeline
+	if p.MaxSize > 0 {
+		pipeline = append(pipeline, MaxSize(p.MaxSize))
+	}
+	switch p.Format {
+	case Proxy:
+		pipeline = append(pipeline, DenyTargets(p.DeniedTargets...))
+	case Signed:
+		pipeline = append(pipeline, NewNonceValidator())
+	}
+	return pipeline
+}
+
+// Validator checks a single transaction.
+type Validator interface {
+	Validate(tx []byte) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(tx []byte) error
+
+// Validate calls f.
+func (f ValidatorFunc) Validate(tx []byte) error {
+	return f(tx)
+}
+
+// Rejection describes a transaction that failed validation.
+type Rejection struct {
+	Index int
+	Err   error
+}
+
+// Pipeline runs each transaction through its validators in order, stopping at the first one
+// rejecting it.
+type Pipeline []Validator
+
+// Apply returns the transactions with the ones rejected by the pipeline replaced by empty
+// transactions, and the rejections. The given slice is not modified.
+func (p Pipeline) Apply(txs [][]byte) ([][]byte, []Rejection) {
+	if len(p) == 0 {
+		return txs, nil
+	}
+	var res [][]byte
+	var rejections []Rejection
+	for i, tx := range txs {
+		err := p.validate(tx)
+		if err == nil {
+			continue
+		}
+		if res == nil {
+			res = make([][]byte, len(txs))
+			copy(res, txs)
+		}
+		res[i] = []byte{}
+		rejections = append(rejections, Rejection{Index: i, Err: err})
+	}
+	if res == nil {
+		return txs, nil
+	}
+	return res, rejections
+}
+
+func (p Pipeline) validate(tx []byte) error {
+	for _, v := range p {
+		if err := v.Validate(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxSize rejects transactions larger than the given number of bytes.
+func MaxSize(n uint64) Validator {
+	return ValidatorFunc(func(tx []byte) error {
+		if uint64(len(tx)) > n {
+			return errors.Errorf("transaction has %d bytes, at most %d are allowed", len(tx), n)
+		}
+		return nil
+	})
+}
+
+var (
+	abiAddress, _ = abi.NewType("address", "", nil)
+	abiBytes, _   = abi.NewType("bytes", "", nil)
+	abiBytes32, _ = abi.NewType("bytes32", "", nil)
+	abiUint8, _   = abi.NewType("uint8", "", nil)
+	abiUint64, _  = abi.NewType("uint64", "", nil)
+
+	proxyArguments   = abi.Arguments{{Type: abiAddress}, {Type: abiBytes}}
+	signedArguments  = abi.Arguments{{Type: abiBytes}, {Type: abiUint8}, {Type: abiBytes32}, {Type: abiBytes32}}
+	payloadArguments = abi.Arguments{{Type: abiUint64}, {Type: abiBytes}}
+)
+
+// DecodeProxy decodes a transaction in the proxy format.
+func DecodeProxy(tx []byte) (common.Address, []byte, error) {
+	vs, err := proxyArguments.UnpackValues(tx)
+	if err != nil {
+		return common.Address{}, nil, errors.Wrap(err, "transaction is not in the proxy format")
+	}
+	return vs[0].(common.Address), vs[1].([]byte), nil
+}
+
+// EncodeProxy encodes a transaction in the proxy format.
+func EncodeProxy(receiver common.Address, data []byte) ([]byte, error) {
+	return proxyArguments.Pack(receiver, data)
+}
+
+// DenyTargets rejects proxy transactions forwarded to one of the given receivers, as well as
+// transactions not in the proxy format.
+func DenyTargets(targets ...common.Address) Validator {
+	denied := make(map[common.Address]bool, len(targets))
+	for _, t := range targets {
+		denied[t] = true
+	}
+	return ValidatorFunc(func(tx []byte) error {
+		receiver, _, err := DecodeProxy(tx)
+		if err != nil {
+			return err
+		}
+		if denied[receiver] {
+			return errors.Errorf("receiver %s is denied", receiver.Hex())
+		}
+		return nil
+	})
+}
+
+// SignedTransaction is a decoded transaction in the signed format.
+type SignedTransaction struct {
+	Sender common.Address
+	Nonce  uint64
+	Data   []byte
+}
+
+// DecodeSigned decodes a transaction in the signed format and recovers its sender.
+func DecodeSigned(tx []byte) (SignedTransaction, error) {
+	vs, err := signedArguments.UnpackValues(tx)
+	if err != nil {
+		return SignedTransaction{}, errors.Wrap(err, "transaction is not in the signed format")
+	}
+	payload, v, r, s := vs[0].([]byte), vs[1].(uint8), vs[2].([32]byte), vs[3].([32]byte)
+	if v != 27 && v != 28 {
+		return SignedTransaction{}, errors.Errorf("invalid signature recovery id %d", v)
+	}
+	sig := make([]byte, 0, crypto.SignatureLength)
+	sig = append(sig, r[:]...)
+	sig = append(sig, s[:]...)
+	sig = append(sig, v-27)
+	pubkey, err := crypto.SigToPub(signedHash(payload), sig)
+	if err != nil {
+		return SignedTransaction{}, errors.Wrap(err, "invalid signature")
+	}
+
+	ps, err := payloadArguments.UnpackValues(payload)
+	if err != nil {
+		return SignedTransaction{}, errors.Wrap(err, "invalid payload")
+	}
+	return SignedTransaction{
+		Sender: crypto.PubkeyToAddress(*pubkey),
+		Nonce:  ps[0].(uint64),
+		Data:   ps[1].([]byte),
+	}, nil
+}
+
+// EncodeSigned encodes a transaction in the signed format, signing it with the given function,
+// e.g. a closure around crypto.Sign.
+func EncodeSigned(nonce uint64, data []byte, sign func(hash []byte) ([]byte, error)) ([]byte, error) {
+	payload, err := payloadArguments.Pack(nonce, data)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := sign(signedHash(payload))
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.Errorf("signature has %d bytes, expected %d", len(sig), crypto.SignatureLength)
+	}
+	var r, s [32]byte
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	return signedArguments.Pack(payload, sig[64]+27, r, s)
+}
+
+// signedHash returns the hash the sender of a signed transaction signs, the Ethereum signed
+// message hash of keccak256(payload).
+func signedHash(payload []byte) []byte {
+	return crypto.Keccak256(
+		[]byte("\x19Ethereum Signed Message:\n32"),
+		crypto.Keccak256(payload),
+	)
+}
+
+type senderNonce struct {
+	sender common.Address
+	nonce  uint64
+}
+
+// NonceValidator rejects signed transactions with an invalid signature and the ones reusing the
+// nonce of a sender that an earlier transaction of the batch has used already. Nonces used in
+// earlier batches are only known to the target contract, which rejects them itself.
+type NonceValidator struct {
+	used map[senderNonce]bool
+}
+
+// NewNonceValidator creates a nonce validator for a single batch.
+func NewNonceValidator() *NonceValidator {
+	return &NonceValidator{used: make(map[senderNonce]bool)}
+}
+
+// Validate implements Validator.
+func (v *NonceValidator) Validate(tx []byte) error {
+	signed, err := DecodeSigned(tx)
+	if err != nil {
+		return err
+	}
+	key := senderNonce{sender: signed.Sender, nonce: signed.Nonce}
+	if v.used[key] {
+		return errors.Errorf("nonce %d of sender %s is used twice", signed.Nonce, signed.Sender.Hex())
+	}
+	v.used[key] = true
+	return nil
+}
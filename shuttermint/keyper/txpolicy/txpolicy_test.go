@@ -0,0 +1,112 @@
+package txpolicy
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy, err := NewPolicy("", 0, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, policy.Format, Raw)
+	assert.Equal(t, len(policy.Pipeline()), 0)
+
+	denied := "0x1111111111111111111111111111111111111111"
+	policy, err = NewPolicy("proxy", 100, []string{denied})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, policy.DeniedTargets, []common.Address{common.HexToAddress(denied)})
+	assert.Equal(t, len(policy.Pipeline()), 2)
+
+	_, err = NewPolicy("json", 0, nil)
+	assert.ErrorContains(t, err, "invalid transaction format")
+	_, err = NewPolicy("proxy", 0, []string{"0x11"})
+	assert.ErrorContains(t, err, "invalid denied target")
+	_, err = NewPolicy("signed", 0, []string{denied})
+	assert.ErrorContains(t, err, "denied targets require the \"proxy\" transaction format")
+}
+
+func TestPipeline(t *testing.T) {
+	a, b, c := []byte("a"), []byte("bb"), []byte("c")
+	notB := ValidatorFunc(func(tx []byte) error {
+		if string(tx) == "bb" {
+			return errors.New("b")
+		}
+		return nil
+	})
+	txs := [][]byte{a, b, c}
+
+	res, rejections := Pipeline{MaxSize(2), notB}.Apply(txs)
+	assert.DeepEqual(t, res, [][]byte{a, {}, c})
+	assert.Equal(t, len(rejections), 1)
+	assert.Equal(t, rejections[0].Index, 1)
+	assert.Error(t, rejections[0].Err, "b")
+	assert.DeepEqual(t, txs, [][]byte{a, b, c})
+
+	res, rejections = Pipeline{MaxSize(1), notB}.Apply(txs)
+	assert.DeepEqual(t, res, [][]byte{a, {}, c})
+	assert.ErrorContains(t, rejections[0].Err, "transaction has 2 bytes, at most 1 are allowed")
+
+	res, rejections = Pipeline{MaxSize(2)}.Apply(txs)
+	assert.DeepEqual(t, res, txs)
+	assert.Assert(t, rejections == nil)
+}
+
+func TestDenyTargets(t *testing.T) {
+	allowed := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	denied := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	v := DenyTargets(denied)
+
+	tx, err := EncodeProxy(allowed, []byte("data"))
+	assert.NilError(t, err)
+	assert.NilError(t, v.Validate(tx))
+	receiver, data, err := DecodeProxy(tx)
+	assert.NilError(t, err)
+	assert.Equal(t, receiver, allowed)
+	assert.DeepEqual(t, data, []byte("data"))
+
+	tx, err = EncodeProxy(denied, nil)
+	assert.NilError(t, err)
+	assert.ErrorContains(t, v.Validate(tx), "is denied")
+	assert.ErrorContains(t, v.Validate([]byte("garbage")), "not in the proxy format")
+}
+
+func TestNonceValidator(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	other, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	encode := func(k *ecdsa.PrivateKey, nonce uint64) []byte {
+		tx, err := EncodeSigned(nonce, []byte("data"), func(hash []byte) ([]byte, error) {
+			return crypto.Sign(hash, k)
+		})
+		assert.NilError(t, err)
+		return tx
+	}
+
+	signed, err := DecodeSigned(encode(key, 7))
+	assert.NilError(t, err)
+	assert.Equal(t, signed.Sender, crypto.PubkeyToAddress(key.PublicKey))
+	assert.Equal(t, signed.Nonce, uint64(7))
+	assert.DeepEqual(t, signed.Data, []byte("data"))
+
+	v := NewNonceValidator()
+	assert.NilError(t, v.Validate(encode(key, 1)))
+	assert.NilError(t, v.Validate(encode(key, 2)))
+	assert.NilError(t, v.Validate(encode(other, 1)))
+	assert.ErrorContains(t, v.Validate(encode(key, 1)), "nonce 1 of sender")
+	assert.ErrorContains(t, v.Validate([]byte("garbage")), "not in the signed format")
+
+	tx, err := signedArguments.Pack([]byte("payload"), uint8(29), [32]byte{}, [32]byte{})
+	assert.NilError(t, err)
+	assert.ErrorContains(t, v.Validate(tx), "invalid signature recovery id 29")
+
+	res, rejections := (Policy{Format: Signed}).Pipeline().Apply([][]byte{encode(key, 5), encode(key, 5)})
+	assert.Assert(t, len(res[0]) > 0)
+	assert.DeepEqual(t, res[1], []byte{})
+	assert.Equal(t, len(rejections), 1)
+}
@@ -0,0 +1,103 @@
+package validatorwatch
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// Validators put their local wall-clock time into their precommit votes, so every commit tells
+// us how far the clocks of the validators, i.e. the keypers, are apart. We measure each
+// validator's clock offset against the median of the votes in the same commit and smooth it over
+// the watch window, as the votes also differ by the time it takes to gossip them. Features
+// scheduled by wall-clock time silently misbehave if the keypers' clocks diverge, so we alert if
+// the offsets spread further than the configured maximum.
+
+// clockOffset is the smoothed clock offset of a validator.
+type clockOffset struct {
+	offset time.Duration
+	height int64 // last height the validator voted at
+}
+
+// median returns the median of the timestamps of the votes for the block in the given commit.
+func median(sigs []tmtypes.CommitSig) (time.Time, bool) {
+	var times []time.Time
+	for _, sig := range sigs {
+		if sig.ForBlock() && !sig.Timestamp.IsZero() {
+			times = append(times, sig.Timestamp)
+		}
+	}
+	if len(times) == 0 {
+		return time.Time{}, false
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times[len(times)/2], true
+}
+
+// SetMaxClockSkew enables the clock check, alerting if the clock offsets of the validators spread
+// further than maxSkew.
+func (w *Watcher) SetMaxClockSkew(maxSkew time.Duration) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.maxClockSkew = maxSkew
+}
+
+// checkClocks updates the clock offsets from the votes in the given commit. The caller must hold
+// the lock.
+func (w *Watcher) checkClocks(commit *tmtypes.Commit) {
+	if w.maxClockSkew <= 0 {
+		return
+	}
+	ref, ok := median(commit.Signatures)
+	if !ok {
+		return
+	}
+	if w.offsets == nil {
+		w.offsets = make(map[string]clockOffset)
+	}
+	// exponential moving average with the same center of mass as a simple moving average over
+	// the watch window
+	alpha := 2 / (float64(w.windowSize) + 1)
+	for _, sig := range commit.Signatures {
+		if !sig.ForBlock() || sig.Timestamp.IsZero() {
+			continue
+		}
+		key := sig.ValidatorAddress.String()
+		offset := sig.Timestamp.Sub(ref)
+		if avg, ok := w.offsets[key]; ok {
+			offset = avg.offset + time.Duration(alpha*float64(offset-avg.offset))
+		}
+		w.offsets[key] = clockOffset{offset: offset, height: commit.Height}
+	}
+
+	var minOffset, maxOffset time.Duration
+	var earliest, latest string
+	first := true
+	for key, o := range w.offsets {
+		if o.height <= commit.Height-int64(w.windowSize) {
+			delete(w.offsets, key) // hasn't voted within the window
+			continue
+		}
+		offset := o.offset
+		if first || offset < minOffset {
+			minOffset, earliest = offset, key
+		}
+		if first || offset > maxOffset {
+			maxOffset, latest = offset, key
+		}
+		first = false
+	}
+	w.report.ClockSpread = maxOffset - minOffset
+	w.report.ClockOffset = w.offsets[w.address.String()].offset
+
+	skewed := w.report.ClockSpread > w.maxClockSkew
+	if skewed && !w.clockAlerting {
+		log.Printf(
+			"ALERT: the clocks of the shuttermint validators are %s apart, more than the allowed %s (earliest: %s, latest: %s), our clock is off by %s%s",
+			w.report.ClockSpread, w.maxClockSkew, earliest, latest, w.report.ClockOffset, w.performance(),
+		)
+	}
+	w.clockAlerting = skewed
+}
@@ -0,0 +1,257 @@
+// Package validatorwatch monitors how our own validator signs shuttermint blocks. A keyper whose
+// validator misses too many blocks or drops out of the validator set can't take part in the keyper
+// protocol anymore, so the watcher raises alerts early and includes the keyper's own performance
+// figures in them to make it easy to correlate the two.
+package validatorwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	tmed25519 "github.com/tendermint/tendermint/crypto/ed25519"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// pollInterval is how often we check for new shuttermint blocks.
+const pollInterval = 2 * time.Second
+
+// maxValidatorsPerPage is the maximum page size supported by tendermint's validators endpoint.
+const maxValidatorsPerPage = 100
+
+// Client is the part of the tendermint client used to fetch commits and validator sets.
+type Client interface {
+	Status(ctx context.Context) (*ctypes.ResultStatus, error)
+	Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error)
+	Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error)
+}
+
+// Report is a snapshot of our validator's recent signing behavior.
+type Report struct {
+	Height         int64 // last height checked
+	Window         int   // number of recent blocks we've been a validator in, at most the window size
+	Missed         int   // number of those blocks we didn't sign
+	LateRounds     uint64
+	InValidatorSet bool
+	InNextSet      bool
+
+	// Clock offsets, see SetMaxClockSkew
+	ClockOffset time.Duration // of our validator against the median of all validators
+	ClockSpread time.Duration // between the validators with the earliest and the latest clock
+}
+
+func (r Report) String() string {
+	if !r.InValidatorSet {
+		return fmt.Sprintf("not in validator set at height %d", r.Height)
+	}
+	s := fmt.Sprintf(
+		"missed %d of last %d blocks, %d blocks committed in later rounds",
+		r.Missed, r.Window, r.LateRounds,
+	)
+	if r.ClockSpread > 0 {
+		s += fmt.Sprintf(", clocks %s apart", r.ClockSpread)
+	}
+	return s
+}
+
+// Watcher checks the commits of new shuttermint blocks for our validator's signatures.
+type Watcher struct {
+	client      Client
+	address     tmcrypto.Address
+	windowSize  int
+	maxMissed   int
+	performance func() string
+
+	mux             sync.Mutex
+	next            int64  // next height to check, 0 if we haven't started yet
+	signed          []bool // ring buffer of the most recent blocks we've been a validator in
+	pos             int
+	report          Report
+	missedAlerting  bool
+	dropOutAlerting bool
+
+	maxClockSkew  time.Duration // 0 if the clock check is disabled
+	offsets       map[string]clockOffset
+	clockAlerting bool
+
+	validatorsHash []byte
+	validators     []*tmtypes.Validator // cached validator set with the hash above
+}
+
+// NewWatcher creates a watcher for the validator with the given public key. It alerts if the
+// validator misses more than maxMissed of the last windowSize blocks or is about to leave the
+// validator set.
+func NewWatcher(client Client, pubkey ed25519.PublicKey, windowSize, maxMissed int) *Watcher {
+	return &Watcher{
+		client:      client,
+		address:     tmed25519.PubKey(pubkey).Address(),
+		windowSize:  windowSize,
+		maxMissed:   maxMissed,
+		performance: func() string { return "" },
+	}
+}
+
+// SetPerformance sets the function describing the keyper's protocol performance that is included
+// in alerts.
+func (w *Watcher) SetPerformance(performance func() string) {
+	w.performance = performance
+}
+
+// Report returns the current statistics.
+func (w *Watcher) Report() Report {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.report
+}
+
+// Run checks new blocks until the context is canceled. Errors are logged, but don't stop the
+// watcher.
+func (w *Watcher) Run(ctx context.Context) error {
+	for {
+		if err := w.Check(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Error watching validator: %+v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Check checks all blocks with a canonical commit that haven't been checked yet.
+func (w *Watcher) Check(ctx context.Context) error {
+	status, err := w.client.Status(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch shuttermint status")
+	}
+	// The commit of the latest block may still be missing signatures, so we stop one block
+	// earlier.
+	last := status.SyncInfo.LatestBlockHeight - 1
+	if w.next == 0 {
+		w.next = last - int64(w.windowSize) + 1
+		if w.next < 1 {
+			w.next = 1
+		}
+	}
+	for ; w.next <= last; w.next++ {
+		if err := w.checkHeight(ctx, w.next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Watcher) checkHeight(ctx context.Context, height int64) error {
+	commit, err := w.client.Commit(ctx, &height)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch commit at height %d", height)
+	}
+	header := commit.SignedHeader.Header
+	validators, err := w.fetchValidators(ctx, height, header.ValidatorsHash)
+	if err != nil {
+		return err
+	}
+	index := w.indexOf(validators)
+	inNextSet := index >= 0
+	if !bytes.Equal(header.NextValidatorsHash, header.ValidatorsHash) {
+		nextHeight := height + 1
+		nextValidators, err := w.fetchValidators(ctx, nextHeight, header.NextValidatorsHash)
+		if err != nil {
+			return err
+		}
+		inNextSet = w.indexOf(nextValidators) >= 0
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.report.Height = height
+	w.report.InValidatorSet = index >= 0
+	w.report.InNextSet = inNextSet
+	if index >= 0 {
+		signatures := commit.SignedHeader.Commit.Signatures
+		signed := index < len(signatures) && signatures[index].ForBlock() &&
+			bytes.Equal(signatures[index].ValidatorAddress, w.address)
+		w.addBlock(signed)
+		if commit.SignedHeader.Commit.Round > 0 {
+			w.report.LateRounds++
+		}
+	}
+	w.checkClocks(commit.SignedHeader.Commit)
+	w.alert()
+	return nil
+}
+
+// addBlock records whether we've signed a block we've been a validator in.
+func (w *Watcher) addBlock(signed bool) {
+	if len(w.signed) < w.windowSize {
+		w.signed = append(w.signed, signed)
+	} else {
+		if !w.signed[w.pos] {
+			w.report.Missed--
+		}
+		w.signed[w.pos] = signed
+		w.pos = (w.pos + 1) % w.windowSize
+	}
+	if !signed {
+		w.report.Missed++
+	}
+	w.report.Window = len(w.signed)
+}
+
+// alert logs alerts when the missed blocks exceed the limit or we're about to leave the
+// validator set. Each alert is logged once until the condition clears.
+func (w *Watcher) alert() {
+	missing := w.report.Missed > w.maxMissed
+	if missing && !w.missedAlerting {
+		log.Printf(
+			"ALERT: our validator %s missed %d of the last %d shuttermint blocks%s",
+			w.address, w.report.Missed, w.report.Window, w.performance(),
+		)
+	}
+	w.missedAlerting = missing
+
+	droppingOut := w.report.InValidatorSet && !w.report.InNextSet
+	if droppingOut && !w.dropOutAlerting {
+		log.Printf(
+			"ALERT: our validator %s is not in the validator set after height %d, the keyper will not be able to take part in shuttermint consensus anymore%s",
+			w.address, w.report.Height, w.performance(),
+		)
+	}
+	w.dropOutAlerting = droppingOut
+}
+
+func (w *Watcher) indexOf(validators []*tmtypes.Validator) int {
+	for i, v := range validators {
+		if bytes.Equal(v.Address, w.address) {
+			return i
+		}
+	}
+	return -1
+}
+
+// fetchValidators fetches the validator set at the given height, unless it's the same as the one
+// fetched before.
+func (w *Watcher) fetchValidators(ctx context.Context, height int64, hash []byte) ([]*tmtypes.Validator, error) {
+	if w.validators != nil && bytes.Equal(hash, w.validatorsHash) {
+		return w.validators, nil
+	}
+	perPage := maxValidatorsPerPage
+	res, err := w.client.Validators(ctx, &height, nil, &perPage)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch validators at height %d", height)
+	}
+	if res.Count != res.Total {
+		return nil, errors.Errorf("too many validators at height %d", height)
+	}
+	w.validatorsHash = hash
+	w.validators = res.Validators
+	return res.Validators, nil
+}
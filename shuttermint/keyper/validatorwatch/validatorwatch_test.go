@@ -0,0 +1,186 @@
+package validatorwatch
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	tmed25519 "github.com/tendermint/tendermint/crypto/ed25519"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"gotest.tools/v3/assert"
+)
+
+// fakeClient serves blocks in which the validators at the indices in signers signed.
+type fakeClient struct {
+	latest     int64
+	validators []*tmtypes.Validator
+	next       []*tmtypes.Validator // validator set from height nextFrom on
+	nextFrom   int64
+	signers    map[int64][]int
+	skews      map[int]time.Duration // clock offsets of the validators at the given indices
+}
+
+func (c *fakeClient) validatorsAt(height int64) []*tmtypes.Validator {
+	if c.next != nil && height >= c.nextFrom {
+		return c.next
+	}
+	return c.validators
+}
+
+func (c *fakeClient) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: c.latest}}, nil
+}
+
+func (c *fakeClient) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit, error) {
+	validators := c.validatorsAt(*height)
+	sigs := make([]tmtypes.CommitSig, len(validators))
+	for i := range sigs {
+		sigs[i] = tmtypes.NewCommitSigAbsent()
+	}
+	for _, i := range c.signers[*height] {
+		sigs[i] = tmtypes.CommitSig{
+			BlockIDFlag:      tmtypes.BlockIDFlagCommit,
+			ValidatorAddress: validators[i].Address,
+			Timestamp:        time.Unix(*height, 0).Add(c.skews[i]),
+		}
+	}
+	header := tmtypes.Header{
+		Height:             *height,
+		ValidatorsHash:     tmtypes.NewValidatorSet(validators).Hash(),
+		NextValidatorsHash: tmtypes.NewValidatorSet(c.validatorsAt(*height + 1)).Hash(),
+	}
+	return &ctypes.ResultCommit{SignedHeader: tmtypes.SignedHeader{
+		Header: &header,
+		Commit: &tmtypes.Commit{Height: *height, Signatures: sigs},
+	}}, nil
+}
+
+func (c *fakeClient) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
+	validators := c.validatorsAt(*height)
+	return &ctypes.ResultValidators{Validators: validators, Count: len(validators), Total: len(validators)}, nil
+}
+
+func newValidator(t *testing.T) (ed25519.PublicKey, *tmtypes.Validator) {
+	t.Helper()
+	pubkey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NilError(t, err)
+	return pubkey, tmtypes.NewValidator(tmed25519.PubKey(pubkey), 10)
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	ours, ourValidator := newValidator(t)
+	_, other := newValidator(t)
+	validators := tmtypes.NewValidatorSet([]*tmtypes.Validator{ourValidator, other}).Validators
+	ourIndex := 0
+	if validators[1].Address.String() == ourValidator.Address.String() {
+		ourIndex = 1
+	}
+
+	client := &fakeClient{latest: 11, validators: validators, signers: make(map[int64][]int)}
+	for h := int64(1); h <= 10; h++ {
+		client.signers[h] = []int{0, 1}
+	}
+	client.signers[4] = []int{1 - ourIndex}
+	client.signers[5] = []int{1 - ourIndex}
+
+	w := NewWatcher(client, ours, 8, 1)
+	assert.NilError(t, w.Check(ctx))
+	report := w.Report()
+	assert.Equal(t, report.Height, int64(10))
+	assert.Equal(t, report.Window, 8)
+	assert.Equal(t, report.Missed, 2)
+	assert.Assert(t, report.InValidatorSet && report.InNextSet)
+	assert.Assert(t, w.missedAlerting)
+
+	// blocks we missed drop out of the window
+	client.latest = 15
+	for h := int64(11); h <= 14; h++ {
+		client.signers[h] = []int{0, 1}
+	}
+	assert.NilError(t, w.Check(ctx))
+	report = w.Report()
+	assert.Equal(t, report.Height, int64(14))
+	assert.Equal(t, report.Missed, 0)
+	assert.Assert(t, !w.missedAlerting)
+
+	// we're removed from the validator set
+	client.next = []*tmtypes.Validator{other}
+	client.nextFrom = 16
+	client.latest = 17
+	client.signers[15] = []int{0, 1}
+	client.signers[16] = []int{0}
+	assert.NilError(t, w.Check(ctx))
+	report = w.Report()
+	assert.Equal(t, report.Height, int64(16))
+	assert.Assert(t, !report.InValidatorSet)
+	assert.Assert(t, !w.dropOutAlerting)
+	assert.Equal(t, report.Window, 8)
+}
+
+func TestWatcherDropOut(t *testing.T) {
+	ours, ourValidator := newValidator(t)
+	_, other := newValidator(t)
+	client := &fakeClient{
+		latest:     3,
+		validators: []*tmtypes.Validator{ourValidator, other},
+		next:       []*tmtypes.Validator{other},
+		nextFrom:   3,
+		signers:    map[int64][]int{1: {0, 1}, 2: {0, 1}},
+	}
+	w := NewWatcher(client, ours, 10, 1)
+	assert.NilError(t, w.Check(context.Background()))
+	report := w.Report()
+	assert.Equal(t, report.Height, int64(2))
+	assert.Assert(t, report.InValidatorSet)
+	assert.Assert(t, !report.InNextSet)
+	assert.Assert(t, w.dropOutAlerting)
+}
+
+func TestWatcherClocks(t *testing.T) {
+	ctx := context.Background()
+	ours, ourValidator := newValidator(t)
+	_, other1 := newValidator(t)
+	_, other2 := newValidator(t)
+	validators := tmtypes.NewValidatorSet([]*tmtypes.Validator{ourValidator, other1, other2}).Validators
+	ourIndex := 0
+	for i, v := range validators {
+		if v.Address.String() == ourValidator.Address.String() {
+			ourIndex = i
+		}
+	}
+
+	client := &fakeClient{
+		latest:     11,
+		validators: validators,
+		signers:    make(map[int64][]int),
+		skews:      map[int]time.Duration{ourIndex: 5 * time.Second},
+	}
+	for h := int64(1); h <= 30; h++ {
+		client.signers[h] = []int{0, 1, 2}
+	}
+
+	w := NewWatcher(client, ours, 8, 1)
+	assert.NilError(t, w.Check(ctx))
+	assert.Equal(t, w.Report().ClockSpread, time.Duration(0)) // disabled
+
+	w = NewWatcher(client, ours, 8, 1)
+	w.SetMaxClockSkew(2 * time.Second)
+	assert.NilError(t, w.Check(ctx))
+	report := w.Report()
+	assert.Equal(t, report.ClockSpread, 5*time.Second)
+	assert.Equal(t, report.ClockOffset, 5*time.Second)
+	assert.Assert(t, w.clockAlerting)
+
+	// our clock is fixed, the smoothed offset decays
+	client.skews = nil
+	client.latest = 31
+	assert.NilError(t, w.Check(ctx))
+	report = w.Report()
+	assert.Assert(t, report.ClockSpread < time.Second)
+	assert.Assert(t, report.ClockOffset > 0 && report.ClockOffset < time.Second)
+	assert.Assert(t, !w.clockAlerting)
+}
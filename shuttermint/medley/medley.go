@@ -102,6 +102,18 @@ func EnsureUniqueAddresses(addrs []common.Address) error {
 	return nil
 }
 
+// EnsureUniqueStrings makes sure the slice of strings doesn't contain duplicate entries.
+func EnsureUniqueStrings(strs []string) error {
+	seen := make(map[string]struct{})
+	for _, s := range strs {
+		if _, ok := seen[s]; ok {
+			return pkgErrors.Errorf("duplicate entry: %s", s)
+		}
+		seen[s] = struct{}{}
+	}
+	return nil
+}
+
 // DedupAddresses returns a new slice containing only unique addresses.
 func DedupAddresses(addrs []common.Address) []common.Address {
 	var res []common.Address
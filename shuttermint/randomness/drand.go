@@ -0,0 +1,63 @@
+package randomness
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Drand fetches randomness from a drand beacon via its HTTP API. The round is the drand round.
+//
+// The beacon signature is not verified, so the operator of the HTTP endpoint has to be trusted.
+type Drand struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewDrand creates a source fetching randomness from the drand HTTP API at url, e.g.
+// https://api.drand.sh.
+func NewDrand(url string) *Drand {
+	return &Drand{URL: strings.TrimSuffix(url, "/"), Client: http.DefaultClient}
+}
+
+type drandResponse struct {
+	Round      uint64 `json:"round"`
+	Randomness string `json:"randomness"`
+}
+
+func (d *Drand) Randomness(ctx context.Context, round uint64) ([]byte, error) {
+	url := fmt.Sprintf("%s/public/%d", d.URL, round)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch drand round %d", round)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch drand round %d: %s", round, resp.Status)
+	}
+
+	var r drandResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, errors.Wrap(err, "failed to decode drand response")
+	}
+	if r.Round != round {
+		return nil, errors.Errorf("drand returned round %d instead of %d", r.Round, round)
+	}
+	randomness, err := hex.DecodeString(r.Randomness)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid drand randomness")
+	}
+	if len(randomness) != 32 {
+		return nil, errors.Errorf("drand randomness has unexpected length %d", len(randomness))
+	}
+	return randomness, nil
+}
@@ -0,0 +1,28 @@
+package randomness
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Local draws randomness from a local random number generator. The round is ignored, every call
+// returns a fresh value.
+type Local struct {
+	Reader io.Reader
+}
+
+// NewLocal creates a source using the operating system's CSPRNG.
+func NewLocal() *Local {
+	return &Local{Reader: rand.Reader}
+}
+
+func (l *Local) Randomness(_ context.Context, _ uint64) ([]byte, error) {
+	r := make([]byte, 32)
+	if _, err := io.ReadFull(l.Reader, r); err != nil {
+		return nil, errors.Wrap(err, "failed to read randomness")
+	}
+	return r, nil
+}
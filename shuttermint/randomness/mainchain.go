@@ -0,0 +1,35 @@
+package randomness
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// HeaderReader is the part of ethclient.Client used by MainChain.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// MainChain uses main chain block data as randomness, the round is the block number. Block
+// producers can bias the value to some extent, so it should only be used where that is
+// acceptable.
+type MainChain struct {
+	Client HeaderReader
+	// PrevRandao selects the prevrandao value (stored in the mix digest field) instead of the
+	// block hash.
+	PrevRandao bool
+}
+
+func (m *MainChain) Randomness(ctx context.Context, round uint64) ([]byte, error) {
+	header, err := m.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(round))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch header of block %d", round)
+	}
+	if m.PrevRandao {
+		return header.MixDigest.Bytes(), nil
+	}
+	return header.Hash().Bytes(), nil
+}
@@ -0,0 +1,67 @@
+// Package randomness provides pluggable sources of randomness for decisions that don't have to be
+// agreed on by shuttermint. Which source is appropriate depends on the deployment: a local CSPRNG
+// is unpredictable but not verifiable by others, main chain block data is public but can be
+// biased by block producers, and drand provides publicly verifiable randomness at the cost of an
+// external dependency.
+//
+// The protocol's own randomness doesn't come from these sources. Eon committees are sampled with
+// a seed derived from the shuttermint block hash, which every node knows without network access.
+// The executor order is derived from the epoch secret key (see shcrypto.Shuffle), so that every
+// executor gets the same order, and encryption sigmas have to come from a CSPRNG (see
+// shcrypto.RandomSigma).
+package randomness
+
+import (
+	"context"
+	"encoding/binary"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Source is a source of 32 byte random values. Deterministic sources return the same value for
+// the same round to everyone, the meaning of the round depends on the source.
+type Source interface {
+	Randomness(ctx context.Context, round uint64) ([]byte, error)
+}
+
+// Derive returns a value derived from the randomness of the given round that is specific to the
+// given domain. Use different domains for different purposes, so that they don't share values.
+func Derive(ctx context.Context, src Source, round uint64, domain string) ([]byte, error) {
+	r, err := src.Randomness(ctx, round)
+	if err != nil {
+		return nil, err
+	}
+	var roundBytes [8]byte
+	binary.BigEndian.PutUint64(roundBytes[:], round)
+	return crypto.Keccak256([]byte(domain), roundBytes[:], r), nil
+}
+
+// Parse creates a source from a specification string as used in config files:
+//
+//   - "local": the local CSPRNG
+//   - "blockhash": main chain block hashes, rounds are block numbers
+//   - "prevrandao": the main chain's prevrandao value, rounds are block numbers
+//   - "drand:<url>": the drand beacon served at url
+//
+// The client is only used for the main chain sources and may be nil otherwise.
+func Parse(spec string, client HeaderReader) (Source, error) {
+	switch {
+	case spec == "local":
+		return NewLocal(), nil
+	case spec == "blockhash" || spec == "prevrandao":
+		if client == nil {
+			return nil, errors.Errorf("randomness source %s requires a main chain client", spec)
+		}
+		return &MainChain{Client: client, PrevRandao: spec == "prevrandao"}, nil
+	case strings.HasPrefix(spec, "drand:"):
+		url := strings.TrimPrefix(spec, "drand:")
+		if url == "" {
+			return nil, errors.Errorf("missing drand url")
+		}
+		return NewDrand(url), nil
+	default:
+		return nil, errors.Errorf("unknown randomness source %q", spec)
+	}
+}
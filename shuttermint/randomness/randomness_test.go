@@ -0,0 +1,100 @@
+package randomness
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/v3/assert"
+)
+
+type headerReader map[uint64]*types.Header
+
+func (h headerReader) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	header, ok := h[number.Uint64()]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return header, nil
+}
+
+func TestLocal(t *testing.T) {
+	src := &Local{Reader: bytes.NewReader(bytes.Repeat([]byte{7}, 40))}
+	r, err := src.Randomness(context.Background(), 0)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, bytes.Repeat([]byte{7}, 32), r)
+
+	_, err = src.Randomness(context.Background(), 0)
+	assert.Assert(t, err != nil)
+}
+
+func TestMainChain(t *testing.T) {
+	header := &types.Header{Number: big.NewInt(5), MixDigest: common.BigToHash(big.NewInt(42))}
+	client := headerReader{5: header}
+
+	r, err := (&MainChain{Client: client}).Randomness(context.Background(), 5)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, header.Hash().Bytes(), r)
+
+	r, err = (&MainChain{Client: client, PrevRandao: true}).Randomness(context.Background(), 5)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, header.MixDigest.Bytes(), r)
+
+	_, err = (&MainChain{Client: client}).Randomness(context.Background(), 6)
+	assert.Assert(t, err != nil)
+}
+
+func TestDrand(t *testing.T) {
+	randomness := bytes.Repeat([]byte{0xab}, 32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/public/10":
+			fmt.Fprintf(w, `{"round": 10, "randomness": "%x"}`, randomness)
+		case "/public/11":
+			fmt.Fprint(w, `{"round": 12, "randomness": "00"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	src, err := Parse("drand:"+server.URL, nil)
+	assert.NilError(t, err)
+	r, err := src.Randomness(context.Background(), 10)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, randomness, r)
+
+	_, err = src.Randomness(context.Background(), 11)
+	assert.ErrorContains(t, err, "instead of")
+	_, err = src.Randomness(context.Background(), 12)
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestDerive(t *testing.T) {
+	src := &Local{Reader: bytes.NewReader(bytes.Repeat([]byte{1}, 64))}
+	a, err := Derive(context.Background(), src, 1, "committee")
+	assert.NilError(t, err)
+	b, err := Derive(context.Background(), src, 1, "shuffle")
+	assert.NilError(t, err)
+	assert.Equal(t, 32, len(a))
+	assert.Assert(t, !bytes.Equal(a, b))
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse("local", nil)
+	assert.NilError(t, err)
+	_, err = Parse("blockhash", nil)
+	assert.ErrorContains(t, err, "client")
+	_, err = Parse("prevrandao", headerReader{})
+	assert.NilError(t, err)
+	_, err = Parse("drand:", nil)
+	assert.Assert(t, err != nil)
+	_, err = Parse("dice", nil)
+	assert.ErrorContains(t, err, "unknown")
+}
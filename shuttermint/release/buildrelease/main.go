@@ -0,0 +1,47 @@
+// buildrelease builds the shuttermint release binaries. Run it from the shuttermint directory:
+//
+//	go run ./release/buildrelease -targets linux-amd64,windows-amd64
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/shutter-network/shutter/shuttermint/release"
+)
+
+func main() {
+	var (
+		opts    release.Options
+		targets string
+	)
+	flag.StringVar(&opts.Go, "go", "go", "go command to use")
+	flag.StringVar(&opts.Dir, "dir", ".", "directory of the shuttermint module")
+	flag.StringVar(&opts.OutDir, "out", "bin", "output directory")
+	flag.StringVar(&opts.Version, "version", "", "version to embed, defaults to git describe")
+	flag.StringVar(&targets, "targets", "", "comma separated list of os-arch targets, defaults to all")
+	flag.Parse()
+
+	ts := release.DefaultTargets
+	if targets != "" {
+		ts = nil
+		for _, s := range strings.Split(targets, ",") {
+			t, err := release.ParseTarget(strings.TrimSpace(s))
+			if err != nil {
+				log.Fatal(err)
+			}
+			ts = append(ts, t)
+		}
+	}
+
+	ctx := context.Background()
+	opts, err := release.OptionsFromGit(ctx, opts)
+	if err != nil {
+		log.Fatalf("%+v", err)
+	}
+	if err := release.Release(ctx, opts, ts); err != nil {
+		log.Fatalf("%+v", err)
+	}
+}
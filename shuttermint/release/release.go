@@ -0,0 +1,208 @@
+// Package release builds the shuttermint binaries for all supported platforms. The builds are
+// reproducible: they don't use cgo, strip file system paths, and take the build date from the
+// commit being built.
+package release
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const versionPackage = "github.com/shutter-network/shutter/shuttermint/cmd/shversion"
+
+// CgoFreePackages are the packages that must build without cgo on all targets. shcrypto is used
+// by the keyper as well as by client libraries, so it has to work everywhere.
+var CgoFreePackages = []string{"github.com/shutter-network/shutter/shlib/shcrypto/..."}
+
+// Target is a platform to build for.
+type Target struct {
+	OS   string
+	Arch string
+}
+
+func (t Target) String() string {
+	return t.OS + "-" + t.Arch
+}
+
+// ParseTarget parses a target of the form "os-arch".
+func ParseTarget(s string) (Target, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return Target{}, errors.Errorf("invalid target %q, expected os-arch", s)
+	}
+	return Target{OS: parts[0], Arch: parts[1]}, nil
+}
+
+// DefaultTargets are the targets we release binaries for.
+var DefaultTargets = []Target{
+	{"linux", "amd64"},
+	{"linux", "arm"},
+	{"linux", "arm64"},
+	{"darwin", "amd64"},
+	{"darwin", "arm64"},
+	{"windows", "amd64"},
+	{"windows", "arm64"},
+	{"freebsd", "amd64"},
+	{"openbsd", "amd64"},
+}
+
+// Options configures a release build.
+type Options struct {
+	Go        string    // go command to use
+	Dir       string    // directory of the shuttermint module
+	OutDir    string    // directory to put the binaries in
+	Version   string    // version string embedded in the binaries
+	Commit    string    // commit hash embedded in the binaries
+	BuildDate time.Time // build date embedded in the binaries
+}
+
+// LDFlags returns the linker flags for the build. They strip debug information and the build
+// id, which would otherwise make the binaries differ between builds.
+func (opts Options) LDFlags() string {
+	flags := []string{
+		"-s", "-w", "-buildid=",
+		"-X", versionPackage + ".version=" + opts.Version,
+		"-X", versionPackage + ".commit=" + opts.Commit,
+	}
+	if !opts.BuildDate.IsZero() {
+		flags = append(flags, "-X", versionPackage+".buildDate="+opts.BuildDate.UTC().Format(time.RFC3339))
+	}
+	return strings.Join(flags, " ")
+}
+
+// BinaryName returns the file name of the binary for the given target.
+func (opts Options) BinaryName(target Target) string {
+	name := fmt.Sprintf("shuttermint-%s-%s", target, opts.Version)
+	if target.OS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+func (opts Options) goCommand(ctx context.Context, target Target, args ...string) *exec.Cmd {
+	goCmd := opts.Go
+	if goCmd == "" {
+		goCmd = "go"
+	}
+	cmd := exec.CommandContext(ctx, goCmd, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS="+target.OS, "GOARCH="+target.Arch)
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// Build builds the shuttermint binary for the given target and returns its path.
+func Build(ctx context.Context, opts Options, target Target) (string, error) {
+	path, err := filepath.Abs(filepath.Join(opts.OutDir, opts.BinaryName(target)))
+	if err != nil {
+		return "", err
+	}
+	cmd := opts.goCommand(ctx, target, "build", "-trimpath", "-ldflags", opts.LDFlags(), "-o", path, ".")
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "failed to build for %s", target)
+	}
+	return path, nil
+}
+
+// CheckCgoFree checks that the given packages build for the target without cgo.
+func CheckCgoFree(ctx context.Context, opts Options, target Target, packages []string) error {
+	args := append([]string{"vet"}, packages...)
+	if err := opts.goCommand(ctx, target, args...).Run(); err != nil {
+		return errors.Wrapf(err, "%s doesn't build without cgo for %s", strings.Join(packages, " "), target)
+	}
+	return nil
+}
+
+// WriteChecksums writes a SHA256SUMS file for the given files to dir.
+func WriteChecksums(dir string, paths []string) error {
+	sorted := append([]string{}, paths...)
+	sort.Strings(sorted)
+	var lines []string
+	for _, path := range sorted {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		lines = append(lines, fmt.Sprintf("%x  %s\n", h.Sum(nil), filepath.Base(path)))
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(strings.Join(lines, "")), 0o644)
+}
+
+// Release checks that shcrypto builds without cgo and builds the binaries for all targets.
+func Release(ctx context.Context, opts Options, targets []Target) error {
+	if err := os.MkdirAll(opts.OutDir, 0o755); err != nil {
+		return errors.Wrap(err, "failed to create output directory")
+	}
+	var paths []string
+	for _, target := range targets {
+		if err := CheckCgoFree(ctx, opts, target, CgoFreePackages); err != nil {
+			return err
+		}
+		fmt.Printf("Building %s\n", opts.BinaryName(target))
+		path, err := Build(ctx, opts, target)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+	return WriteChecksums(opts.OutDir, paths)
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "git %s failed", strings.Join(args, " "))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// OptionsFromGit fills in the version, commit, and build date from the git repository in
+// opts.Dir. A version already set is kept. The build date is the commit date unless
+// SOURCE_DATE_EPOCH is set.
+func OptionsFromGit(ctx context.Context, opts Options) (Options, error) {
+	var err error
+	if opts.Version == "" {
+		opts.Version, err = git(ctx, opts.Dir, "describe", "--tags", "--always", "--abbrev=4", "--dirty")
+		if err != nil {
+			return opts, err
+		}
+	}
+	opts.Commit, err = git(ctx, opts.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return opts, err
+	}
+
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		epoch, err = git(ctx, opts.Dir, "show", "-s", "--format=%ct", "HEAD")
+		if err != nil {
+			return opts, err
+		}
+	}
+	secs, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return opts, errors.Wrapf(err, "invalid build date %q", epoch)
+	}
+	opts.BuildDate = time.Unix(secs, 0).UTC()
+	return opts, nil
+}
@@ -0,0 +1,58 @@
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("windows-arm64")
+	assert.NilError(t, err)
+	assert.Equal(t, target, Target{OS: "windows", Arch: "arm64"})
+	assert.Equal(t, target.String(), "windows-arm64")
+
+	for _, s := range []string{"", "linux", "linux-", "-amd64", "linux-amd64-v2"} {
+		_, err := ParseTarget(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	opts := Options{
+		Version:   "v1.2.3",
+		Commit:    "abcdef",
+		BuildDate: time.Unix(1600000000, 0),
+	}
+	assert.Equal(t, opts.BinaryName(Target{"linux", "arm64"}), "shuttermint-linux-arm64-v1.2.3")
+	assert.Equal(t, opts.BinaryName(Target{"windows", "amd64"}), "shuttermint-windows-amd64-v1.2.3.exe")
+
+	ldflags := opts.LDFlags()
+	assert.Assert(t, strings.Contains(ldflags, "-buildid="))
+	assert.Assert(t, strings.Contains(ldflags, versionPackage+".version=v1.2.3"))
+	assert.Assert(t, strings.Contains(ldflags, versionPackage+".commit=abcdef"))
+	assert.Assert(t, strings.Contains(ldflags, versionPackage+".buildDate=2020-09-13T12:26:40Z"))
+}
+
+func TestWriteChecksums(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	b := filepath.Join(dir, "b")
+	a := filepath.Join(dir, "a")
+	assert.NilError(t, ioutil.WriteFile(a, []byte("a"), 0o644))
+	assert.NilError(t, ioutil.WriteFile(b, []byte("b"), 0o644))
+	assert.NilError(t, WriteChecksums(dir, []string{b, a}))
+
+	sums, err := ioutil.ReadFile(filepath.Join(dir, "SHA256SUMS"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(sums),
+		"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  a\n"+
+			"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d  b\n")
+}
@@ -0,0 +1,256 @@
+// Loadtest submits encrypted transactions to the batcher contract of a test network at increasing
+// rates and measures how long it takes until they are included in a batch, until the key for
+// their batch is released and until the batch is executed. The report shows up to which rate the
+// network keeps up, e.g. for capacity planning or to detect performance regressions.
+//
+// Run it against a network started with 'shuttermint testnet up':
+//
+//	loadtest -c testnet/contracts.json --rates 1,2,5,10 --stage-duration 1m
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/rpc/client/http"
+
+	"github.com/shutter-network/shutter/shuttermint/cmd/deploy"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/sandbox"
+)
+
+var flags struct {
+	EthereumURL    string
+	ShuttermintURL string
+	ContractsPath  string
+	Rates          string
+	StageDuration  time.Duration
+	DrainTimeout   time.Duration
+	Size           int
+	NumAccounts    int
+	FirstAccount   int
+	MaxLatency     time.Duration
+}
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Measure the encrypted transaction throughput of a test network",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rates, err := parseRates(flags.Rates)
+		if err != nil {
+			return err
+		}
+		if err := validateFlags(); err != nil {
+			return err
+		}
+		return loadtest(rates)
+	},
+	SilenceUsage: true,
+}
+
+func init() {
+	loadtestCmd.Flags().StringVarP(
+		&flags.EthereumURL,
+		"ethereum-url",
+		"e",
+		"http://localhost:8545",
+		"Ethereum JSON RPC URL",
+	)
+	loadtestCmd.Flags().StringVarP(
+		&flags.ShuttermintURL,
+		"shuttermint-url",
+		"s",
+		"http://localhost:26657",
+		"Shuttermint RPC URL",
+	)
+	loadtestCmd.Flags().StringVarP(
+		&flags.ContractsPath,
+		"contracts",
+		"c",
+		"",
+		"path to the contracts.json file",
+	)
+	loadtestCmd.MarkFlagRequired("contracts")
+	loadtestCmd.Flags().StringVar(
+		&flags.Rates,
+		"rates",
+		"1,2,5,10",
+		"comma separated transaction rates in transactions per second, one stage per rate",
+	)
+	loadtestCmd.Flags().DurationVar(
+		&flags.StageDuration,
+		"stage-duration",
+		time.Minute,
+		"how long to submit transactions at each rate",
+	)
+	loadtestCmd.Flags().DurationVar(
+		&flags.DrainTimeout,
+		"drain-timeout",
+		2*time.Minute,
+		"how long to wait for the submitted transactions to be executed after the last stage",
+	)
+	loadtestCmd.Flags().IntVar(
+		&flags.Size,
+		"size",
+		100,
+		"size of the transactions before encryption in bytes",
+	)
+	loadtestCmd.Flags().IntVar(
+		&flags.NumAccounts,
+		"accounts",
+		4,
+		"number of ganache accounts to send transactions from concurrently",
+	)
+	loadtestCmd.Flags().IntVar(
+		&flags.FirstAccount,
+		"first-account",
+		1,
+		"index of the first ganache account to use",
+	)
+	loadtestCmd.Flags().DurationVar(
+		&flags.MaxLatency,
+		"max-latency",
+		0,
+		"fail if the 90th percentile of the execution latency of any stage exceeds this, 0 to disable",
+	)
+}
+
+func parseRates(s string) ([]float64, error) {
+	var rates []float64
+	for _, r := range strings.Split(s, ",") {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+		if err != nil || rate <= 0 {
+			return nil, errors.Errorf("invalid flag --rates: %q is not a positive number", r)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func validateFlags() error {
+	if flags.Size <= 0 {
+		return errors.Errorf("invalid flag --size: must be at least 1")
+	}
+	if flags.NumAccounts <= 0 {
+		return errors.Errorf("invalid flag --accounts: must be at least 1")
+	}
+	if flags.FirstAccount < 0 || flags.FirstAccount+flags.NumAccounts > sandbox.NumGanacheKeys() {
+		return errors.Errorf("invalid flags --first-account, --accounts: there are only %d ganache accounts",
+			sandbox.NumGanacheKeys())
+	}
+	if flags.StageDuration <= 0 {
+		return errors.Errorf("invalid flag --stage-duration: must be positive")
+	}
+	return nil
+}
+
+type contracts struct {
+	config       *contract.ConfigContract
+	batcher      *contract.BatcherContract
+	executor     *contract.ExecutorContract
+	keyBroadcast *contract.KeyBroadcastContract
+}
+
+func newContracts(client *ethclient.Client, path string) (*contracts, error) {
+	addresses, err := deploy.LoadContractsJSON(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load contracts JSON file at %s", path)
+	}
+	c := &contracts{}
+	if c.config, err = contract.NewConfigContract(addresses.ConfigContract, client); err != nil {
+		return nil, err
+	}
+	if c.batcher, err = contract.NewBatcherContract(addresses.BatcherContract, client); err != nil {
+		return nil, err
+	}
+	if c.executor, err = contract.NewExecutorContract(addresses.ExecutorContract, client); err != nil {
+		return nil, err
+	}
+	if c.keyBroadcast, err = contract.NewKeyBroadcastContract(addresses.KeyBroadcastContract, client); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func loadtest(rates []float64) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		log.Printf("Got signal '%s'. Stopping the load test.", sig)
+		cancel()
+	}()
+
+	client, err := ethclient.DialContext(ctx, flags.EthereumURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to Ethereum node at %s", flags.EthereumURL)
+	}
+	shmcl, err := http.New(flags.ShuttermintURL, "/websocket")
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to Shuttermint node at %s", flags.ShuttermintURL)
+	}
+	cs, err := newContracts(client, flags.ContractsPath)
+	if err != nil {
+		return err
+	}
+
+	rec := newRecorder()
+	trk := newTracker(client, shmcl, cs, rec)
+	if err := trk.update(ctx); err != nil {
+		return err
+	}
+	trackerDone := make(chan error, 1)
+	trackerCtx, stopTracker := context.WithCancel(ctx)
+	defer stopTracker()
+	go func() { trackerDone <- trk.run(trackerCtx) }()
+
+	snd, err := newSender(ctx, client, cs, trk, rec)
+	if err != nil {
+		return err
+	}
+	for i, rate := range rates {
+		log.Printf("Stage %d: sending %g transactions per second for %s", i, rate, flags.StageDuration)
+		snd.runStage(ctx, i, rate, flags.StageDuration)
+	}
+	snd.wait()
+
+	log.Printf("Waiting up to %s for the transactions to be executed", flags.DrainTimeout)
+	drainCtx, cancelDrain := context.WithTimeout(ctx, flags.DrainTimeout)
+	rec.waitDone(drainCtx)
+	cancelDrain()
+	stopTracker()
+	if err := <-trackerDone; err != nil {
+		log.Printf("Error: %+v", err)
+	}
+
+	stages := rec.stages(rates, flags.StageDuration)
+	printReport(os.Stdout, stages)
+	if flags.MaxLatency > 0 {
+		for _, s := range stages {
+			if p90 := s.execution.percentile(90); p90 > flags.MaxLatency {
+				return errors.Errorf("stage %d: execution latency p90 %s exceeds %s", s.index, p90, flags.MaxLatency)
+			}
+		}
+	}
+	return nil
+}
+
+func main() {
+	if err := loadtestCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// minExecutedShare is the share of submitted transactions that must be executed for a stage to
+// count as sustained.
+const minExecutedShare = 0.95
+
+type txRecord struct {
+	stage      int
+	batchIndex uint64
+	size       int
+	submitted  time.Time
+	included   time.Time // zero until mined
+	failed     bool      // mined, but reverted, e.g. because the batch was closed or full
+}
+
+type batchRecord struct {
+	keyReleased time.Time
+	executed    time.Time
+}
+
+// recorder records the submitted transactions and the progress of their batches.
+type recorder struct {
+	mux     sync.Mutex
+	txs     []*txRecord
+	batches map[uint64]*batchRecord
+	drops   map[int]int // by stage
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		batches: make(map[uint64]*batchRecord),
+		drops:   make(map[int]int),
+	}
+}
+
+func (rec *recorder) submitted(stage int, batchIndex uint64, size int, t time.Time) int {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	rec.txs = append(rec.txs, &txRecord{stage: stage, batchIndex: batchIndex, size: size, submitted: t})
+	if _, ok := rec.batches[batchIndex]; !ok {
+		rec.batches[batchIndex] = &batchRecord{}
+	}
+	return len(rec.txs) - 1
+}
+
+func (rec *recorder) included(id int, t time.Time, success bool) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	rec.txs[id].included = t
+	rec.txs[id].failed = !success
+}
+
+func (rec *recorder) dropped(stage int) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	rec.drops[stage]++
+}
+
+func (rec *recorder) keyReleased(batchIndex uint64, t time.Time) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	if b, ok := rec.batches[batchIndex]; ok && b.keyReleased.IsZero() {
+		b.keyReleased = t
+	}
+}
+
+func (rec *recorder) executed(batchIndex uint64, t time.Time) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	if b, ok := rec.batches[batchIndex]; ok && b.executed.IsZero() {
+		b.executed = t
+	}
+}
+
+// pendingBatches returns the batches we've sent transactions to that haven't been executed yet.
+func (rec *recorder) pendingBatches() []uint64 {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	var res []uint64
+	for batchIndex, b := range rec.batches {
+		if b.executed.IsZero() {
+			res = append(res, batchIndex)
+		}
+	}
+	return res
+}
+
+// waitDone waits until all batches have been executed or the context is done.
+func (rec *recorder) waitDone(ctx context.Context) {
+	for len(rec.pendingBatches()) > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+type latencies []time.Duration
+
+// percentile returns the smallest latency that is greater than or equal to p percent of the
+// latencies.
+func (l latencies) percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+	sorted := append(latencies(nil), l...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+type stageResult struct {
+	index      int
+	rate       float64
+	duration   time.Duration
+	submitted  int
+	dropped    int
+	included   int
+	failed     int
+	executed   int
+	bytes      int
+	inclusion  latencies
+	keyRelease latencies
+	execution  latencies
+}
+
+// sustained checks if the network kept up with the stage's rate.
+func (s stageResult) sustained() bool {
+	return s.dropped == 0 && s.submitted > 0 && float64(s.executed) >= minExecutedShare*float64(s.submitted)
+}
+
+func (rec *recorder) stages(rates []float64, duration time.Duration) []stageResult {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	res := make([]stageResult, len(rates))
+	for i, rate := range rates {
+		res[i] = stageResult{index: i, rate: rate, duration: duration, dropped: rec.drops[i]}
+	}
+	for _, tx := range rec.txs {
+		s := &res[tx.stage]
+		s.submitted++
+		if tx.included.IsZero() {
+			continue
+		}
+		if tx.failed {
+			s.failed++
+			continue
+		}
+		s.included++
+		s.inclusion = append(s.inclusion, tx.included.Sub(tx.submitted))
+		b := rec.batches[tx.batchIndex]
+		if !b.keyReleased.IsZero() {
+			s.keyRelease = append(s.keyRelease, b.keyReleased.Sub(tx.submitted))
+		}
+		if !b.executed.IsZero() {
+			s.executed++
+			s.bytes += tx.size
+			s.execution = append(s.execution, b.executed.Sub(tx.submitted))
+		}
+	}
+	return res
+}
+
+func formatLatencies(l latencies) string {
+	if len(l) == 0 {
+		return "-"
+	}
+	round := func(d time.Duration) time.Duration { return d.Round(100 * time.Millisecond) }
+	return fmt.Sprintf("%s/%s/%s", round(l.percentile(50)), round(l.percentile(90)), round(l.percentile(99)))
+}
+
+func printReport(out io.Writer, stages []stageResult) {
+	w := tabwriter.NewWriter(out, 1, 1, 2, ' ', 0)
+	fmt.Fprintf(w, "Stage\tRate\tSubmitted\tDropped\tFailed\tExecuted\tTx/s\tBytes/s\tInclusion\tKey release\tExecution\n")
+	for _, s := range stages {
+		seconds := s.duration.Seconds()
+		fmt.Fprintf(w, "%d\t%g\t%d\t%d\t%d\t%d\t%.2f\t%.0f\t%s\t%s\t%s\n",
+			s.index, s.rate, s.submitted, s.dropped, s.failed, s.executed,
+			float64(s.executed)/seconds, float64(s.bytes)/seconds,
+			formatLatencies(s.inclusion), formatLatencies(s.keyRelease), formatLatencies(s.execution),
+		)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nLatencies are p50/p90/p99 since submission, measured with a resolution of %s.\n", pollInterval)
+
+	maxRate := 0.0
+	for _, s := range stages {
+		if s.sustained() && s.rate > maxRate {
+			maxRate = s.rate
+		}
+	}
+	if maxRate == 0 {
+		fmt.Fprintf(out, "The network didn't sustain any of the rates.\n")
+	} else {
+		fmt.Fprintf(out, "The network sustained up to %g transactions per second.\n", maxRate)
+	}
+}
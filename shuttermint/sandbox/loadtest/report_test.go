@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	var l latencies
+	assert.Equal(t, l.percentile(50), time.Duration(0))
+	for i := 10; i >= 1; i-- {
+		l = append(l, time.Duration(i)*time.Second)
+	}
+	assert.Equal(t, l.percentile(0), 1*time.Second)
+	assert.Equal(t, l.percentile(50), 5*time.Second)
+	assert.Equal(t, l.percentile(90), 9*time.Second)
+	assert.Equal(t, l.percentile(99), 10*time.Second)
+	assert.Equal(t, l.percentile(100), 10*time.Second)
+	assert.Equal(t, l[0], 10*time.Second) // not sorted in place
+}
+
+func TestStages(t *testing.T) {
+	rec := newRecorder()
+	start := time.Now()
+
+	// stage 0: both transactions executed
+	id := rec.submitted(0, 5, 100, start)
+	rec.included(id, start.Add(time.Second), true)
+	id = rec.submitted(0, 5, 100, start)
+	rec.included(id, start.Add(2*time.Second), true)
+	// stage 1: one executed, one reverted, one not mined, one dropped
+	id = rec.submitted(1, 6, 100, start)
+	rec.included(id, start.Add(time.Second), true)
+	id = rec.submitted(1, 6, 100, start)
+	rec.included(id, start.Add(time.Second), false)
+	rec.submitted(1, 7, 100, start)
+	rec.dropped(1)
+
+	assert.Equal(t, len(rec.pendingBatches()), 3)
+	rec.keyReleased(5, start.Add(3*time.Second))
+	rec.executed(5, start.Add(4*time.Second))
+	rec.keyReleased(6, start.Add(3*time.Second))
+	rec.executed(6, start.Add(5*time.Second))
+	rec.executed(6, start.Add(10*time.Second)) // ignored, executed already
+	assert.DeepEqual(t, rec.pendingBatches(), []uint64{7})
+
+	stages := rec.stages([]float64{1, 2}, time.Second)
+	assert.Equal(t, len(stages), 2)
+
+	s := stages[0]
+	assert.Equal(t, s.submitted, 2)
+	assert.Equal(t, s.executed, 2)
+	assert.Equal(t, s.bytes, 200)
+	assert.Equal(t, s.execution.percentile(100), 4*time.Second)
+	assert.Assert(t, s.sustained())
+
+	s = stages[1]
+	assert.Equal(t, s.submitted, 3)
+	assert.Equal(t, s.dropped, 1)
+	assert.Equal(t, s.failed, 1)
+	assert.Equal(t, s.included, 1)
+	assert.Equal(t, s.executed, 1)
+	assert.Equal(t, s.execution.percentile(100), 5*time.Second)
+	assert.Assert(t, !s.sustained())
+
+	out := new(bytes.Buffer)
+	printReport(out, stages)
+	assert.Assert(t, strings.Contains(out.String(), "sustained up to 1 transactions per second"))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/contract"
+	"github.com/shutter-network/shutter/shuttermint/medley"
+	"github.com/shutter-network/shutter/shuttermint/sandbox"
+)
+
+const addTransactionGasLimit = 500000
+
+type job struct {
+	stage int
+}
+
+// sender submits encrypted transactions from several accounts concurrently. Each account sends
+// its transactions one after the other, without waiting for them to be mined, so the number of
+// accounts limits the rate only if the main chain doesn't keep up.
+type sender struct {
+	client *ethclient.Client
+	cs     *contracts
+	trk    *tracker
+	rec    *recorder
+
+	jobs    chan job
+	workers sync.WaitGroup
+}
+
+func newSender(ctx context.Context, client *ethclient.Client, cs *contracts, trk *tracker, rec *recorder) (*sender, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query chain id")
+	}
+	snd := &sender{
+		client: client,
+		cs:     cs,
+		trk:    trk,
+		rec:    rec,
+		jobs:   make(chan job, flags.NumAccounts),
+	}
+	for i := 0; i < flags.NumAccounts; i++ {
+		key := sandbox.GanacheKey(flags.FirstAccount + i)
+		opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create transactor")
+		}
+		nonce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to query nonce")
+		}
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		opts.GasLimit = addTransactionGasLimit
+		snd.workers.Add(1)
+		go snd.work(ctx, opts)
+	}
+	return snd, nil
+}
+
+// runStage sends transactions at the given rate for the given duration. Ticks that find all
+// accounts busy are dropped and show up as a lower achieved rate in the report.
+func (snd *sender) runStage(ctx context.Context, stage int, rate float64, duration time.Duration) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+	defer ticker.Stop()
+	end := time.After(duration)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-end:
+			return
+		case <-ticker.C:
+			select {
+			case snd.jobs <- job{stage: stage}:
+			default:
+				snd.rec.dropped(stage)
+			}
+		}
+	}
+}
+
+// wait stops the workers once they've sent the queued transactions.
+func (snd *sender) wait() {
+	close(snd.jobs)
+	snd.workers.Wait()
+}
+
+func (snd *sender) work(ctx context.Context, opts *bind.TransactOpts) {
+	defer snd.workers.Done()
+	for j := range snd.jobs {
+		if ctx.Err() != nil {
+			continue
+		}
+		if err := snd.send(ctx, opts, j.stage); err != nil {
+			log.Printf("Error: %s", err)
+			snd.rec.dropped(j.stage)
+		}
+	}
+}
+
+func (snd *sender) send(ctx context.Context, opts *bind.TransactOpts, stage int) error {
+	blockNumber, err := snd.client.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to query block number")
+	}
+	nextBatchIndex, err := snd.cs.config.NextBatchIndex(blockNumber)
+	if err != nil {
+		return errors.Wrap(err, "failed to determine batch index")
+	}
+	if nextBatchIndex == 0 {
+		return errors.Errorf("first batch hasn't started yet (block %d)", blockNumber)
+	}
+	batchIndex := nextBatchIndex - 1
+
+	eonKey, epoch, version, err := snd.trk.encryptionKey(ctx, batchIndex)
+	if err != nil {
+		return err
+	}
+	payload := make([]byte, flags.Size)
+	if _, err := rand.Read(payload); err != nil {
+		return err
+	}
+	m, err := shcrypto.EncryptWithVersion(version, payload, eonKey, shcrypto.ComputeEpochID(epoch), rand.Reader)
+	if err != nil {
+		return err
+	}
+	encrypted := m.Marshal()
+
+	opts.Context = ctx
+	submitted := time.Now()
+	tx, err := snd.cs.batcher.AddTransaction(opts, batchIndex, contract.TransactionTypeCipher, encrypted)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send transaction for batch %d", batchIndex)
+	}
+	opts.Nonce.Add(opts.Nonce, big.NewInt(1))
+	id := snd.rec.submitted(stage, batchIndex, len(encrypted), submitted)
+
+	go func() {
+		receipt, err := medley.WaitMined(ctx, snd.client, tx.Hash())
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Error: failed to wait for tx %s: %s", tx.Hash().Hex(), err)
+			}
+			return
+		}
+		snd.rec.included(id, time.Now(), receipt.Status == types.ReceiptStatusSuccessful)
+	}()
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/observe"
+	"github.com/shutter-network/shutter/shuttermint/keyper/protocol"
+)
+
+// pollInterval is how often the tracker checks for key releases and executions. It limits the
+// resolution of the measured latencies.
+const pollInterval = time.Second
+
+// tracker follows the shuttermint chain and the executor contract to find out when the keys of the
+// batches we've sent transactions to are released and when the batches are executed.
+type tracker struct {
+	client *ethclient.Client
+	shmcl  client.Client
+	cs     *contracts
+	rec    *recorder
+
+	mux     sync.Mutex
+	shutter *observe.Shutter
+	eonKeys map[uint64]*shcrypto.EonPublicKey // by start batch index of the eon
+}
+
+func newTracker(client *ethclient.Client, shmcl client.Client, cs *contracts, rec *recorder) *tracker {
+	return &tracker{
+		client:  client,
+		shmcl:   shmcl,
+		cs:      cs,
+		rec:     rec,
+		shutter: observe.NewShutter(),
+		eonKeys: make(map[uint64]*shcrypto.EonPublicKey),
+	}
+}
+
+func (trk *tracker) run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+		if err := trk.update(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Error: %+v", err)
+		}
+	}
+}
+
+func (trk *tracker) update(ctx context.Context) error {
+	trk.mux.Lock()
+	shutter := trk.shutter
+	trk.mux.Unlock()
+	shutter, err := shutter.SyncToHead(ctx, trk.shmcl)
+	if err != nil {
+		return errors.Wrap(err, "failed to sync with shuttermint")
+	}
+	trk.mux.Lock()
+	trk.shutter = shutter
+	trk.mux.Unlock()
+
+	numExecutionHalfSteps, err := trk.cs.executor.NumExecutionHalfSteps(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return errors.Wrap(err, "failed to query number of execution half steps")
+	}
+	now := time.Now()
+	for _, batchIndex := range trk.rec.pendingBatches() {
+		if trk.keyReleased(shutter, batchIndex) {
+			trk.rec.keyReleased(batchIndex, now)
+		}
+		if numExecutionHalfSteps > uint64(protocol.BatchIndex(batchIndex).CipherHalfStep()) {
+			trk.rec.executed(batchIndex, now)
+		}
+	}
+	return nil
+}
+
+// keyReleased checks if a threshold of keypers has published their shares of the batch's key.
+func (trk *tracker) keyReleased(shutter *observe.Shutter, batchIndex uint64) bool {
+	eon, err := shutter.FindEonByBatchIndex(batchIndex)
+	if err != nil {
+		return false
+	}
+	bc := shutter.FindBatchConfigByBatchIndex(batchIndex)
+	epoch := bc.Epoch(batchIndex)
+	senders := make(map[common.Address]bool)
+	for _, share := range eon.EpochSecretKeyShares {
+		if share.Epoch == epoch && share.Namespace == "" {
+			senders[share.Sender] = true
+		}
+	}
+	return bc.Threshold > 0 && uint64(len(senders)) >= bc.Threshold
+}
+
+// encryptionKey returns the eon public key, the epoch and the version of the encryption scheme to
+// encrypt transactions for the given batch with.
+func (trk *tracker) encryptionKey(ctx context.Context, batchIndex uint64) (*shcrypto.EonPublicKey, uint64, uint8, error) {
+	trk.mux.Lock()
+	defer trk.mux.Unlock()
+
+	eon, err := trk.shutter.FindEonByBatchIndex(batchIndex)
+	if err != nil {
+		return nil, 0, 0, errors.Errorf("no eon for batch %d yet", batchIndex)
+	}
+	bc := trk.shutter.FindBatchConfigByBatchIndex(batchIndex)
+	epoch := bc.Epoch(batchIndex)
+
+	// the keypers decrypt with the version of the config the eon has started in
+	startBatchIndex := eon.StartEvent.BatchIndex
+	eonConfig := trk.shutter.FindBatchConfigByBatchIndex(startBatchIndex)
+	version := uint8(eonConfig.EncryptionVersion)
+	if key, ok := trk.eonKeys[startBatchIndex]; ok {
+		return key, epoch, version, nil
+	}
+	keyBytes, err := trk.cs.keyBroadcast.GetBestKey(&bind.CallOpts{Context: ctx}, startBatchIndex)
+	if err != nil {
+		return nil, 0, 0, errors.Wrapf(err, "failed to query eon key for start batch %d", startBatchIndex)
+	}
+	if len(keyBytes) == 0 {
+		return nil, 0, 0, errors.Errorf("eon key for start batch %d not broadcast yet", startBatchIndex)
+	}
+	key := new(shcrypto.EonPublicKey)
+	if err := key.Unmarshal(keyBytes); err != nil {
+		return nil, 0, 0, errors.Wrapf(err, "invalid eon key for start batch %d", startBatchIndex)
+	}
+	trk.eonKeys[startBatchIndex] = key
+	return key, epoch, version, nil
+}
@@ -0,0 +1,93 @@
+package sequencer
+
+import (
+	"context"
+	"io"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// Server makes a Service available via gRPC.
+type Server struct {
+	UnimplementedSequencerServer
+	service Service
+}
+
+// NewServer creates a new Server for the given service.
+func NewServer(service Service) *Server {
+	return &Server{service: service}
+}
+
+// Register registers the server with the given gRPC server.
+func (s *Server) Register(gs *grpc.Server) {
+	RegisterSequencerServer(gs, s)
+}
+
+func (s *Server) SubmitTransactions(ctx context.Context, req *SubmitTransactionsRequest) (*SubmitTransactionsResponse, error) {
+	err := s.service.SubmitTransactions(ctx, req.BatchIndex, req.Transactions)
+	if err != nil {
+		return nil, err
+	}
+	return &SubmitTransactionsResponse{}, nil
+}
+
+func (s *Server) SubscribeEpochKeys(req *SubscribeEpochKeysRequest, stream Sequencer_SubscribeEpochKeysServer) error {
+	return s.service.SubscribeEpochKeys(stream.Context(), req.StartBatchIndex, func(k EpochKey) error {
+		return stream.Send(&EpochKeyNotification{
+			BatchIndex: k.BatchIndex,
+			Key:        (*bn256.G1)(k.Key).Marshal(),
+		})
+	})
+}
+
+// Client implements Service by talking to a Server via gRPC.
+type Client struct {
+	client SequencerClient
+}
+
+var _ Service = &Client{}
+
+// NewClient creates a new Client using the given connection.
+func NewClient(cc grpc.ClientConnInterface) *Client {
+	return &Client{client: NewSequencerClient(cc)}
+}
+
+func (c *Client) SubmitTransactions(ctx context.Context, batchIndex uint64, txs [][]byte) error {
+	_, err := c.client.SubmitTransactions(ctx, &SubmitTransactionsRequest{
+		BatchIndex:   batchIndex,
+		Transactions: txs,
+	})
+	return err
+}
+
+func (c *Client) SubscribeEpochKeys(ctx context.Context, startBatchIndex uint64, handler func(EpochKey) error) error {
+	stream, err := c.client.SubscribeEpochKeys(ctx, &SubscribeEpochKeysRequest{StartBatchIndex: startBatchIndex})
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		key, err := shcrypto.ValidateEpochSecretKey(msg.Key)
+		if err != nil {
+			return errors.Wrapf(err, "batch %d", msg.BatchIndex)
+		}
+		err = handler(EpochKey{BatchIndex: msg.BatchIndex, Key: key})
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package sequencer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/dedup"
+)
+
+// Hub is an in-memory Service. Submitted transactions are collected per batch until Shutter
+// takes them, epoch keys are published by the keyper and handed out to all subscribers.
+type Hub struct {
+	mux          sync.Mutex
+	transactions map[uint64][][]byte
+	keys         map[uint64]*shcrypto.EpochSecretKey
+	published    chan struct{} // closed and replaced whenever a key is published
+
+	duplicates dedup.Policy
+	window     *dedup.Window // nil if duplicates are allowed
+}
+
+var _ Service = &Hub{}
+
+// NewHub creates a new, empty Hub.
+func NewHub() *Hub {
+	return &Hub{
+		transactions: make(map[uint64][][]byte),
+		keys:         make(map[uint64]*shcrypto.EpochSecretKey),
+		published:    make(chan struct{}),
+		duplicates:   dedup.Allow,
+	}
+}
+
+// SetDuplicatePolicy sets how submitted transactions copying a transaction submitted before for
+// the same batch or for one of the window previous batches are handled. By default, they are
+// allowed.
+func (h *Hub) SetDuplicatePolicy(policy dedup.Policy, window uint64) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	h.duplicates = policy
+	h.window = nil
+	if policy != dedup.Allow {
+		h.window = dedup.NewWindow(window)
+	}
+}
+
+// SubmitTransactions stores the transactions for the given batch. It fails if the epoch key of
+// the batch has already been published, or, depending on the duplicate policy, if a transaction
+// is a copy, in which case none of the transactions are stored.
+func (h *Hub) SubmitTransactions(ctx context.Context, batchIndex uint64, txs [][]byte) error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if _, ok := h.keys[batchIndex]; ok {
+		return errors.Errorf("batch %d is already closed", batchIndex)
+	}
+	if h.window == nil {
+		for _, tx := range txs {
+			h.transactions[batchIndex] = append(h.transactions[batchIndex], append([]byte(nil), tx...))
+		}
+		return nil
+	}
+
+	// copies within the submission count as well, so track them in a separate window until we
+	// know whether we accept it
+	var accepted [][]byte
+	submitted := dedup.NewWindow(0)
+	for i, tx := range txs {
+		if h.window.Contains(batchIndex, tx) || submitted.Contains(batchIndex, tx) {
+			if h.duplicates == dedup.Reject {
+				return errors.Errorf("transaction %d is a duplicate or replay", i)
+			}
+			continue
+		}
+		submitted.Add(batchIndex, tx)
+		accepted = append(accepted, tx)
+	}
+	for _, tx := range accepted {
+		h.window.Add(batchIndex, tx)
+		h.transactions[batchIndex] = append(h.transactions[batchIndex], append([]byte(nil), tx...))
+	}
+	return nil
+}
+
+// TakeTransactions returns and forgets the transactions submitted for the given batch.
+func (h *Hub) TakeTransactions(batchIndex uint64) [][]byte {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	txs := h.transactions[batchIndex]
+	delete(h.transactions, batchIndex)
+	return txs
+}
+
+// PublishEpochKey makes the epoch key for the given batch available to subscribers.
+func (h *Hub) PublishEpochKey(batchIndex uint64, key *shcrypto.EpochSecretKey) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	if _, ok := h.keys[batchIndex]; ok {
+		return
+	}
+	h.keys[batchIndex] = key
+	close(h.published)
+	h.published = make(chan struct{})
+}
+
+// SubscribeEpochKeys calls handler for each published epoch key, starting at startBatchIndex.
+func (h *Hub) SubscribeEpochKeys(ctx context.Context, startBatchIndex uint64, handler func(EpochKey) error) error {
+	batchIndex := startBatchIndex
+	for {
+		h.mux.Lock()
+		key, ok := h.keys[batchIndex]
+		published := h.published
+		h.mux.Unlock()
+
+		if ok {
+			if err := handler(EpochKey{BatchIndex: batchIndex, Key: key}); err != nil {
+				return err
+			}
+			batchIndex++
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-published:
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// Package sequencer defines the interface through which an external rollup sequencer submits
+// encrypted transactions to Shutter and receives the epoch keys needed to decrypt them. The gRPC
+// Server and Client allow sequencers to use it without linking any of Shutter's internal packages.
+package sequencer
+
+import (
+	"context"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+)
+
+// EpochKey is the epoch secret key for a batch.
+type EpochKey struct {
+	BatchIndex uint64
+	Key        *shcrypto.EpochSecretKey
+}
+
+// Service is provided by Shutter to rollup sequencers.
+type Service interface {
+	// SubmitTransactions requests the given encrypted transactions to be included in the batch
+	// with the given index.
+	SubmitTransactions(ctx context.Context, batchIndex uint64, txs [][]byte) error
+
+	// SubscribeEpochKeys calls handler for each epoch key in order of batch index, starting at
+	// startBatchIndex. It blocks until ctx is canceled or handler returns an error.
+	SubscribeEpochKeys(ctx context.Context, startBatchIndex uint64, handler func(EpochKey) error) error
+}
@@ -0,0 +1,128 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"gotest.tools/v3/assert"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
+	"github.com/shutter-network/shutter/shuttermint/keyper/dedup"
+)
+
+var errStop = errors.New("stop")
+
+func makeKey(i int64) *shcrypto.EpochSecretKey {
+	return (*shcrypto.EpochSecretKey)(new(bn256.G1).ScalarBaseMult(big.NewInt(i)))
+}
+
+// collectKeys subscribes to service and returns the first n keys it receives.
+func collectKeys(t *testing.T, service Service, startBatchIndex uint64, n int) []EpochKey {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var keys []EpochKey
+	err := service.SubscribeEpochKeys(ctx, startBatchIndex, func(k EpochKey) error {
+		keys = append(keys, k)
+		if len(keys) == n {
+			return errStop
+		}
+		return nil
+	})
+	assert.Assert(t, err != nil)
+	assert.ErrorContains(t, err, errStop.Error())
+	return keys
+}
+
+func TestHubTransactions(t *testing.T) {
+	ctx := context.Background()
+	hub := NewHub()
+
+	assert.NilError(t, hub.SubmitTransactions(ctx, 3, [][]byte{[]byte("a")}))
+	assert.NilError(t, hub.SubmitTransactions(ctx, 3, [][]byte{[]byte("b")}))
+	assert.DeepEqual(t, hub.TakeTransactions(3), [][]byte{[]byte("a"), []byte("b")})
+	assert.Equal(t, len(hub.TakeTransactions(3)), 0)
+
+	hub.PublishEpochKey(3, makeKey(1))
+	err := hub.SubmitTransactions(ctx, 3, [][]byte{[]byte("c")})
+	assert.ErrorContains(t, err, "already closed")
+}
+
+func TestHubDuplicates(t *testing.T) {
+	ctx := context.Background()
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+
+	hub := NewHub()
+	hub.SetDuplicatePolicy(dedup.Drop, 2)
+	assert.NilError(t, hub.SubmitTransactions(ctx, 3, [][]byte{a, b, a}))
+	assert.NilError(t, hub.SubmitTransactions(ctx, 3, [][]byte{b, c}))
+	assert.DeepEqual(t, hub.TakeTransactions(3), [][]byte{a, b, c})
+	assert.NilError(t, hub.SubmitTransactions(ctx, 5, [][]byte{a, []byte("d")}))
+	assert.NilError(t, hub.SubmitTransactions(ctx, 6, [][]byte{a}))
+	assert.DeepEqual(t, hub.TakeTransactions(5), [][]byte{[]byte("d")})
+	// batch 3 is out of the window
+	assert.DeepEqual(t, hub.TakeTransactions(6), [][]byte{a})
+
+	hub.SetDuplicatePolicy(dedup.Reject, 2)
+	assert.NilError(t, hub.SubmitTransactions(ctx, 7, [][]byte{a}))
+	err := hub.SubmitTransactions(ctx, 7, [][]byte{b, a})
+	assert.ErrorContains(t, err, "transaction 1 is a duplicate")
+	err = hub.SubmitTransactions(ctx, 8, [][]byte{c, c})
+	assert.ErrorContains(t, err, "transaction 1 is a duplicate")
+	assert.DeepEqual(t, hub.TakeTransactions(7), [][]byte{a})
+	assert.Equal(t, len(hub.TakeTransactions(8)), 0)
+}
+
+func TestHubSubscribe(t *testing.T) {
+	hub := NewHub()
+	hub.PublishEpochKey(5, makeKey(5))
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		hub.PublishEpochKey(6, makeKey(6))
+	}()
+
+	keys := collectKeys(t, hub, 5, 2)
+	assert.Equal(t, keys[0].BatchIndex, uint64(5))
+	assert.Equal(t, keys[1].BatchIndex, uint64(6))
+	assert.Assert(t, keys[1].Key.Equal(makeKey(6)))
+}
+
+func TestGRPC(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	hub := NewHub()
+	NewServer(hub).Register(gs)
+	go gs.Serve(listener)
+	defer gs.Stop()
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithInsecure(),
+	)
+	assert.NilError(t, err)
+	defer conn.Close()
+	client := NewClient(conn)
+
+	txs := [][]byte{[]byte("tx1"), []byte("tx2")}
+	assert.NilError(t, client.SubmitTransactions(context.Background(), 7, txs))
+	assert.DeepEqual(t, hub.TakeTransactions(7), txs)
+
+	hub.PublishEpochKey(7, makeKey(7))
+	hub.PublishEpochKey(8, makeKey(8))
+	keys := collectKeys(t, client, 7, 2)
+	assert.Equal(t, keys[0].BatchIndex, uint64(7))
+	assert.Assert(t, keys[0].Key.Equal(makeKey(7)))
+	assert.Assert(t, keys[1].Key.Equal(makeKey(8)))
+
+	err = client.SubmitTransactions(context.Background(), 7, txs)
+	assert.ErrorContains(t, err, "already closed")
+}
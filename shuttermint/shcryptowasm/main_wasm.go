@@ -39,13 +39,26 @@ var encrypt = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		return returnValue([]byte{}, err)
 	}
 
-	if len(args) != 4 {
-		return errorReturnValue(errors.Errorf("expected 4 arguments, got %d", len(args)))
+	// The optional fifth argument is the version of the encryption scheme, i.e. the
+	// EncryptionVersion of the eon's batch config. It defaults to the legacy version.
+	if len(args) != 4 && len(args) != 5 {
+		return errorReturnValue(errors.Errorf("expected 4 or 5 arguments, got %d", len(args)))
 	}
 	messageJS := args[0]
 	eonPublicKeyJS := args[1]
 	epochIndexJS := args[2]
 	sigmaJS := args[3]
+	version := shcrypto.LegacyVersion
+	if len(args) == 5 {
+		if err := validateVersion(args[4]); err != nil {
+			return errorReturnValue(err)
+		}
+		version = uint8(args[4].Int())
+	}
+	p, err := shcrypto.ParamsForVersion(version)
+	if err != nil {
+		return errorReturnValue(err)
+	}
 
 	if err := validateMessage(messageJS); err != nil {
 		return errorReturnValue(err)
@@ -66,7 +79,7 @@ var encrypt = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 	eonPublicKeyBytes := make([]byte, eonPublicKeyJS.Length())
 	js.CopyBytesToGo(eonPublicKeyBytes, eonPublicKeyJS)
 	eonPublicKey := new(shcrypto.EonPublicKey)
-	err := eonPublicKey.Unmarshal(eonPublicKeyBytes)
+	err = eonPublicKey.Unmarshal(eonPublicKeyBytes)
 	if err != nil {
 		return errorReturnValue(errors.Wrap(err, "failed to decode eon public key"))
 	}
@@ -77,7 +90,7 @@ var encrypt = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 	var sigma shcrypto.Block
 	js.CopyBytesToGo(sigma[:], sigmaJS)
 
-	m := shcrypto.Encrypt(message, eonPublicKey, epochID, sigma)
+	m := shcrypto.EncryptWithParams(p, message, eonPublicKey, epochID, sigma)
 	encoded := m.Marshal()
 	return returnValue(encoded, nil)
 })
@@ -100,6 +113,16 @@ func validateEpochIndex(v js.Value) error {
 	return nil
 }
 
+func validateVersion(v js.Value) error {
+	if v.Type() != js.TypeNumber {
+		return errors.Errorf("expected number, got non-number")
+	}
+	if v.Int() < 0 || v.Int() > int(shcrypto.LatestVersion) {
+		return errors.Errorf("unknown encryption version %d", v.Int())
+	}
+	return nil
+}
+
 func validateSigma(v js.Value) error {
 	return validateBlock(v)
 }
@@ -6,6 +6,8 @@ package shmsg
 
 import (
 	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+
+	"github.com/shutter-network/shutter/shlib/shcrypto"
 )
 
 // Set marshals the given value and stores the byte array.
@@ -15,9 +17,7 @@ func (g1 *G1) Set(v *bn256.G1) {
 
 // Get unmarshals the marshaled value.
 func (g1 *G1) Get() (*bn256.G1, error) {
-	v := new(bn256.G1)
-	_, err := v.Unmarshal(g1.G1Bytes)
-	return v, err
+	return shcrypto.UnmarshalG1(g1.G1Bytes)
 }
 
 // Set marshals the given value and stores the byte array.
@@ -27,9 +27,7 @@ func (g2 *G2) Set(v *bn256.G2) {
 
 // Get unmarshals the marshaled value.
 func (g2 *G2) Get() (*bn256.G2, error) {
-	v := new(bn256.G2)
-	_, err := v.Unmarshal(g2.G2Bytes)
-	return v, err
+	return shcrypto.UnmarshalG2(g2.G2Bytes)
 }
 
 // Set marshals the given value and stores the byte array.
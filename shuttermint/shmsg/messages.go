@@ -19,6 +19,12 @@ func NewBatchConfig(
 	configIndex uint64,
 	started bool,
 	validatorsUpdated bool,
+	epochNamespaces []string,
+	perBlockEpochs bool,
+	committeeSize uint64,
+	epochOffset uint64,
+	epochStride uint64,
+	encryptionVersion uint64,
 ) *Message {
 	var keypersBytes [][]byte
 	for _, k := range keypers {
@@ -35,6 +41,12 @@ func NewBatchConfig(
 				ConfigIndex:           configIndex,
 				Started:               started,
 				ValidatorsUpdated:     validatorsUpdated,
+				EpochNamespaces:       epochNamespaces,
+				PerBlockEpochs:        perBlockEpochs,
+				CommitteeSize:         committeeSize,
+				EpochOffset:           epochOffset,
+				EpochStride:           epochStride,
+				EncryptionVersion:     encryptionVersion,
 			},
 		},
 	}
@@ -97,16 +109,32 @@ func NewAccusation(eon uint64, accused []common.Address) *Message {
 
 // NewPolyCommitment creates a new poly commitment message containing gamma values.
 func NewPolyCommitment(eon uint64, gammas *shcrypto.Gammas) *Message {
+	return &Message{
+		Payload: &Message_PolyCommitment{
+			PolyCommitment: NewPolyCommitmentPayload(eon, gammas),
+		},
+	}
+}
+
+// NewPolyCommitmentPayload creates the payload of a poly commitment message. Use it together with
+// NewPolyCommitments to send commitments for several eons at once.
+func NewPolyCommitmentPayload(eon uint64, gammas *shcrypto.Gammas) *PolyCommitment {
 	gammaBytes := [][]byte{}
 	for _, gamma := range *gammas {
 		gammaBytes = append(gammaBytes, gamma.Marshal())
 	}
+	return &PolyCommitment{
+		Eon:    eon,
+		Gammas: gammaBytes,
+	}
+}
 
+// NewPolyCommitments creates a new message containing the poly commitments for several eons.
+func NewPolyCommitments(commitments []*PolyCommitment) *Message {
 	return &Message{
-		Payload: &Message_PolyCommitment{
-			PolyCommitment: &PolyCommitment{
-				Eon:    eon,
-				Gammas: gammaBytes,
+		Payload: &Message_PolyCommitments{
+			PolyCommitments: &PolyCommitments{
+				Commitments: commitments,
 			},
 		},
 	}
@@ -166,13 +194,83 @@ func NewCheckIn(validatorPublicKey []byte, encryptionKey *ecies.PublicKey) *Mess
 }
 
 func NewEpochSecretKeyShare(eon, epoch uint64, share *shcrypto.EpochSecretKeyShare) *Message {
+	return NewNamespacedEpochSecretKeyShare(eon, "", epoch, share)
+}
+
+// NewNamespacedEpochSecretKeyShare creates a new EpochSecretKeyShare message for an epoch in the
+// given namespace. The empty namespace is the default namespace.
+func NewNamespacedEpochSecretKeyShare(eon uint64, namespace string, epoch uint64, share *shcrypto.EpochSecretKeyShare) *Message {
 	encoded, _ := share.GobEncode()
 	return &Message{
 		Payload: &Message_EpochSecretKeyShare{
 			EpochSecretKeyShare: &EpochSecretKeyShare{
+				Eon:       eon,
+				Epoch:     epoch,
+				Share:     encoded,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+// NewDirectMessage creates a new DirectMessage message to the given keyper. The payload must
+// already be encrypted to the receiver's encryption key.
+func NewDirectMessage(receiver common.Address, topic string, encryptedPayload []byte) *Message {
+	return &Message{
+		Payload: &Message_DirectMessage{
+			DirectMessage: &DirectMessage{
+				Receiver:         receiver.Bytes(),
+				Topic:            topic,
+				EncryptedPayload: encryptedPayload,
+			},
+		},
+	}
+}
+
+// NewSkipCipherVote creates a new message voting to skip the cipher execution of the given batch.
+func NewSkipCipherVote(batchIndex uint64) *Message {
+	return &Message{
+		Payload: &Message_SkipCipherVote{
+			SkipCipherVote: &SkipCipherVote{
+				BatchIndex: batchIndex,
+			},
+		},
+	}
+}
+
+// NewHaltResumeVote creates a new message voting to resume key releases and executions after the
+// shuttermint halt following the given height.
+func NewHaltResumeVote(haltHeight uint64) *Message {
+	return &Message{
+		Payload: &Message_HaltResumeVote{
+			HaltResumeVote: &HaltResumeVote{
+				HaltHeight: haltHeight,
+			},
+		},
+	}
+}
+
+// NewCapabilities creates a new message announcing the keyper's software version and the optional
+// sub-protocols it supports.
+func NewCapabilities(version string, features []string) *Message {
+	return &Message{
+		Payload: &Message_Capabilities{
+			Capabilities: &Capabilities{
+				Version:  version,
+				Features: features,
+			},
+		},
+	}
+}
+
+// NewDecryptionApproval creates a new message approving the publication of the epoch secret key
+// shares for the given past epoch of the eon.
+func NewDecryptionApproval(eon, epoch uint64) *Message {
+	return &Message{
+		Payload: &Message_DecryptionApproval{
+			DecryptionApproval: &DecryptionApproval{
 				Eon:   eon,
 				Epoch: epoch,
-				Share: encoded,
 			},
 		},
 	}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/proto"
 	"gotest.tools/v3/assert"
 
 	"github.com/shutter-network/shutter/shlib/shcrypto"
@@ -30,6 +31,23 @@ func TestNewPolyCommitmentMsg(t *testing.T) {
 	}
 }
 
+func TestNewPolyCommitmentsMsg(t *testing.T) {
+	var commitments []*PolyCommitment
+	for eon := uint64(3); eon < 5; eon++ {
+		poly, err := shcrypto.RandomPolynomial(rand.Reader, 2)
+		assert.NilError(t, err)
+		commitments = append(commitments, NewPolyCommitmentPayload(eon, poly.Gammas()))
+	}
+
+	msgContainer := NewPolyCommitments(commitments)
+	msg := msgContainer.GetPolyCommitments()
+	assert.Assert(t, msg != nil)
+	assert.Equal(t, 2, len(msg.Commitments))
+	assert.Equal(t, uint64(3), msg.Commitments[0].Eon)
+	assert.Equal(t, uint64(4), msg.Commitments[1].Eon)
+	assert.Equal(t, 3, len(msg.Commitments[1].Gammas))
+}
+
 func TestNewPolyEvalMsg(t *testing.T) {
 	eon := uint64(10)
 	receiver := common.BigToAddress(big.NewInt(0xaabbcc))
@@ -42,3 +60,29 @@ func TestNewPolyEvalMsg(t *testing.T) {
 	assert.Equal(t, eon, msg.Eon)
 	assert.DeepEqual(t, receiver.Bytes(), msg.Receivers[0])
 }
+
+func TestNewCapabilitiesMsg(t *testing.T) {
+	msgContainer := NewCapabilities("v1.2.3", []string{"a", "b"})
+	data, err := proto.Marshal(msgContainer)
+	assert.NilError(t, err)
+	decoded := &Message{}
+	assert.NilError(t, proto.Unmarshal(data, decoded))
+
+	msg := decoded.GetCapabilities()
+	assert.Assert(t, msg != nil)
+	assert.Equal(t, "v1.2.3", msg.Version)
+	assert.DeepEqual(t, []string{"a", "b"}, msg.Features)
+}
+
+func TestNewDecryptionApprovalMsg(t *testing.T) {
+	msgContainer := NewDecryptionApproval(3, 17)
+	data, err := proto.Marshal(msgContainer)
+	assert.NilError(t, err)
+	decoded := &Message{}
+	assert.NilError(t, proto.Unmarshal(data, decoded))
+
+	msg := decoded.GetDecryptionApproval()
+	assert.Assert(t, msg != nil)
+	assert.Equal(t, uint64(3), msg.Eon)
+	assert.Equal(t, uint64(17), msg.Epoch)
+}
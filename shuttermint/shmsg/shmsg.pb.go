@@ -2,7 +2,7 @@
 // versions:
 // 	protoc-gen-go v1.25.0
 // 	protoc        v3.13.0
-// source: shmsg/shmsg.proto
+// source: shmsg.proto
 
 package shmsg
 
@@ -36,7 +36,7 @@ type G1 struct {
 func (x *G1) Reset() {
 	*x = G1{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[0]
+		mi := &file_shmsg_proto_msgTypes[0]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -49,7 +49,7 @@ func (x *G1) String() string {
 func (*G1) ProtoMessage() {}
 
 func (x *G1) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[0]
+	mi := &file_shmsg_proto_msgTypes[0]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -62,7 +62,7 @@ func (x *G1) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use G1.ProtoReflect.Descriptor instead.
 func (*G1) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{0}
+	return file_shmsg_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *G1) GetG1Bytes() []byte {
@@ -83,7 +83,7 @@ type G2 struct {
 func (x *G2) Reset() {
 	*x = G2{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[1]
+		mi := &file_shmsg_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -96,7 +96,7 @@ func (x *G2) String() string {
 func (*G2) ProtoMessage() {}
 
 func (x *G2) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[1]
+	mi := &file_shmsg_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -109,7 +109,7 @@ func (x *G2) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use G2.ProtoReflect.Descriptor instead.
 func (*G2) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{1}
+	return file_shmsg_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *G2) GetG2Bytes() []byte {
@@ -130,7 +130,7 @@ type GT struct {
 func (x *GT) Reset() {
 	*x = GT{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[2]
+		mi := &file_shmsg_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -143,7 +143,7 @@ func (x *GT) String() string {
 func (*GT) ProtoMessage() {}
 
 func (x *GT) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[2]
+	mi := &file_shmsg_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -156,7 +156,7 @@ func (x *GT) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GT.ProtoReflect.Descriptor instead.
 func (*GT) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{2}
+	return file_shmsg_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *GT) GetGtbytes() []byte {
@@ -178,12 +178,18 @@ type BatchConfig struct {
 	ConfigIndex           uint64   `protobuf:"varint,5,opt,name=config_index,json=configIndex,proto3" json:"config_index,omitempty"`
 	Started               bool     `protobuf:"varint,6,opt,name=started,proto3" json:"started,omitempty"`
 	ValidatorsUpdated     bool     `protobuf:"varint,7,opt,name=validatorsUpdated,proto3" json:"validatorsUpdated,omitempty"`
+	EpochNamespaces       []string `protobuf:"bytes,8,rep,name=epoch_namespaces,json=epochNamespaces,proto3" json:"epoch_namespaces,omitempty"`
+	PerBlockEpochs        bool     `protobuf:"varint,9,opt,name=per_block_epochs,json=perBlockEpochs,proto3" json:"per_block_epochs,omitempty"`         // epochs are main chain block numbers instead of batch indices
+	CommitteeSize         uint64   `protobuf:"varint,10,opt,name=committee_size,json=committeeSize,proto3" json:"committee_size,omitempty"`             // number of keypers taking part in the DKG, 0 for all of them
+	EpochOffset           uint64   `protobuf:"varint,11,opt,name=epoch_offset,json=epochOffset,proto3" json:"epoch_offset,omitempty"`                   // epoch of batch 0
+	EpochStride           uint64   `protobuf:"varint,12,opt,name=epoch_stride,json=epochStride,proto3" json:"epoch_stride,omitempty"`                   // number of epochs per batch, 0 for 1
+	EncryptionVersion     uint64   `protobuf:"varint,13,opt,name=encryption_version,json=encryptionVersion,proto3" json:"encryption_version,omitempty"` // version of the threshold encryption scheme, see shcrypto
 }
 
 func (x *BatchConfig) Reset() {
 	*x = BatchConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[3]
+		mi := &file_shmsg_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -196,7 +202,7 @@ func (x *BatchConfig) String() string {
 func (*BatchConfig) ProtoMessage() {}
 
 func (x *BatchConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[3]
+	mi := &file_shmsg_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -209,7 +215,7 @@ func (x *BatchConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchConfig.ProtoReflect.Descriptor instead.
 func (*BatchConfig) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{3}
+	return file_shmsg_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *BatchConfig) GetStartBatchIndex() uint64 {
@@ -261,6 +267,48 @@ func (x *BatchConfig) GetValidatorsUpdated() bool {
 	return false
 }
 
+func (x *BatchConfig) GetEpochNamespaces() []string {
+	if x != nil {
+		return x.EpochNamespaces
+	}
+	return nil
+}
+
+func (x *BatchConfig) GetPerBlockEpochs() bool {
+	if x != nil {
+		return x.PerBlockEpochs
+	}
+	return false
+}
+
+func (x *BatchConfig) GetCommitteeSize() uint64 {
+	if x != nil {
+		return x.CommitteeSize
+	}
+	return 0
+}
+
+func (x *BatchConfig) GetEpochOffset() uint64 {
+	if x != nil {
+		return x.EpochOffset
+	}
+	return 0
+}
+
+func (x *BatchConfig) GetEpochStride() uint64 {
+	if x != nil {
+		return x.EpochStride
+	}
+	return 0
+}
+
+func (x *BatchConfig) GetEncryptionVersion() uint64 {
+	if x != nil {
+		return x.EncryptionVersion
+	}
+	return 0
+}
+
 type BatchConfigStarted struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -272,7 +320,7 @@ type BatchConfigStarted struct {
 func (x *BatchConfigStarted) Reset() {
 	*x = BatchConfigStarted{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[4]
+		mi := &file_shmsg_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -285,7 +333,7 @@ func (x *BatchConfigStarted) String() string {
 func (*BatchConfigStarted) ProtoMessage() {}
 
 func (x *BatchConfigStarted) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[4]
+	mi := &file_shmsg_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -298,7 +346,7 @@ func (x *BatchConfigStarted) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BatchConfigStarted.ProtoReflect.Descriptor instead.
 func (*BatchConfigStarted) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{4}
+	return file_shmsg_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BatchConfigStarted) GetBatchConfigIndex() uint64 {
@@ -320,7 +368,7 @@ type CheckIn struct {
 func (x *CheckIn) Reset() {
 	*x = CheckIn{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[5]
+		mi := &file_shmsg_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -333,7 +381,7 @@ func (x *CheckIn) String() string {
 func (*CheckIn) ProtoMessage() {}
 
 func (x *CheckIn) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[5]
+	mi := &file_shmsg_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -346,7 +394,7 @@ func (x *CheckIn) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckIn.ProtoReflect.Descriptor instead.
 func (*CheckIn) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{5}
+	return file_shmsg_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CheckIn) GetValidatorPublicKey() []byte {
@@ -375,7 +423,7 @@ type DecryptionSignature struct {
 func (x *DecryptionSignature) Reset() {
 	*x = DecryptionSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[6]
+		mi := &file_shmsg_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -388,7 +436,7 @@ func (x *DecryptionSignature) String() string {
 func (*DecryptionSignature) ProtoMessage() {}
 
 func (x *DecryptionSignature) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[6]
+	mi := &file_shmsg_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -401,7 +449,7 @@ func (x *DecryptionSignature) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DecryptionSignature.ProtoReflect.Descriptor instead.
 func (*DecryptionSignature) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{6}
+	return file_shmsg_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *DecryptionSignature) GetBatchIndex() uint64 {
@@ -431,7 +479,7 @@ type PolyEval struct {
 func (x *PolyEval) Reset() {
 	*x = PolyEval{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[7]
+		mi := &file_shmsg_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -444,7 +492,7 @@ func (x *PolyEval) String() string {
 func (*PolyEval) ProtoMessage() {}
 
 func (x *PolyEval) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[7]
+	mi := &file_shmsg_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -457,7 +505,7 @@ func (x *PolyEval) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolyEval.ProtoReflect.Descriptor instead.
 func (*PolyEval) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{7}
+	return file_shmsg_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PolyEval) GetEon() uint64 {
@@ -493,7 +541,7 @@ type PolyCommitment struct {
 func (x *PolyCommitment) Reset() {
 	*x = PolyCommitment{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[8]
+		mi := &file_shmsg_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -506,7 +554,7 @@ func (x *PolyCommitment) String() string {
 func (*PolyCommitment) ProtoMessage() {}
 
 func (x *PolyCommitment) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[8]
+	mi := &file_shmsg_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -519,7 +567,7 @@ func (x *PolyCommitment) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PolyCommitment.ProtoReflect.Descriptor instead.
 func (*PolyCommitment) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{8}
+	return file_shmsg_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *PolyCommitment) GetEon() uint64 {
@@ -548,7 +596,7 @@ type Accusation struct {
 func (x *Accusation) Reset() {
 	*x = Accusation{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[9]
+		mi := &file_shmsg_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -561,7 +609,7 @@ func (x *Accusation) String() string {
 func (*Accusation) ProtoMessage() {}
 
 func (x *Accusation) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[9]
+	mi := &file_shmsg_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -574,7 +622,7 @@ func (x *Accusation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Accusation.ProtoReflect.Descriptor instead.
 func (*Accusation) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{9}
+	return file_shmsg_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Accusation) GetEon() uint64 {
@@ -604,7 +652,7 @@ type Apology struct {
 func (x *Apology) Reset() {
 	*x = Apology{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[10]
+		mi := &file_shmsg_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -617,7 +665,7 @@ func (x *Apology) String() string {
 func (*Apology) ProtoMessage() {}
 
 func (x *Apology) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[10]
+	mi := &file_shmsg_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -630,7 +678,7 @@ func (x *Apology) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Apology.ProtoReflect.Descriptor instead.
 func (*Apology) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{10}
+	return file_shmsg_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Apology) GetEon() uint64 {
@@ -659,15 +707,16 @@ type EpochSecretKeyShare struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Eon   uint64 `protobuf:"varint,1,opt,name=eon,proto3" json:"eon,omitempty"`
-	Epoch uint64 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
-	Share []byte `protobuf:"bytes,3,opt,name=share,proto3" json:"share,omitempty"`
+	Eon       uint64 `protobuf:"varint,1,opt,name=eon,proto3" json:"eon,omitempty"`
+	Epoch     uint64 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Share     []byte `protobuf:"bytes,3,opt,name=share,proto3" json:"share,omitempty"`
+	Namespace string `protobuf:"bytes,4,opt,name=namespace,proto3" json:"namespace,omitempty"` // empty for the default namespace
 }
 
 func (x *EpochSecretKeyShare) Reset() {
 	*x = EpochSecretKeyShare{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[11]
+		mi := &file_shmsg_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -680,7 +729,7 @@ func (x *EpochSecretKeyShare) String() string {
 func (*EpochSecretKeyShare) ProtoMessage() {}
 
 func (x *EpochSecretKeyShare) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[11]
+	mi := &file_shmsg_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -693,7 +742,7 @@ func (x *EpochSecretKeyShare) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EpochSecretKeyShare.ProtoReflect.Descriptor instead.
 func (*EpochSecretKeyShare) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{11}
+	return file_shmsg_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *EpochSecretKeyShare) GetEon() uint64 {
@@ -717,6 +766,13 @@ func (x *EpochSecretKeyShare) GetShare() []byte {
 	return nil
 }
 
+func (x *EpochSecretKeyShare) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
 type EonStartVote struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -728,7 +784,7 @@ type EonStartVote struct {
 func (x *EonStartVote) Reset() {
 	*x = EonStartVote{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[12]
+		mi := &file_shmsg_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -741,7 +797,7 @@ func (x *EonStartVote) String() string {
 func (*EonStartVote) ProtoMessage() {}
 
 func (x *EonStartVote) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[12]
+	mi := &file_shmsg_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -754,7 +810,7 @@ func (x *EonStartVote) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EonStartVote.ProtoReflect.Descriptor instead.
 func (*EonStartVote) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{12}
+	return file_shmsg_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *EonStartVote) GetStartBatchIndex() uint64 {
@@ -764,6 +820,320 @@ func (x *EonStartVote) GetStartBatchIndex() uint64 {
 	return 0
 }
 
+type DirectMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Receiver         []byte `protobuf:"bytes,1,opt,name=receiver,proto3" json:"receiver,omitempty"`
+	Topic            string `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`                                               // the sub-protocol the payload belongs to
+	EncryptedPayload []byte `protobuf:"bytes,3,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"` // ecies encrypted to the receiver's encryption key
+}
+
+func (x *DirectMessage) Reset() {
+	*x = DirectMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_shmsg_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DirectMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DirectMessage) ProtoMessage() {}
+
+func (x *DirectMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_shmsg_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DirectMessage.ProtoReflect.Descriptor instead.
+func (*DirectMessage) Descriptor() ([]byte, []int) {
+	return file_shmsg_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DirectMessage) GetReceiver() []byte {
+	if x != nil {
+		return x.Receiver
+	}
+	return nil
+}
+
+func (x *DirectMessage) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *DirectMessage) GetEncryptedPayload() []byte {
+	if x != nil {
+		return x.EncryptedPayload
+	}
+	return nil
+}
+
+type PolyCommitments struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Commitments []*PolyCommitment `protobuf:"bytes,1,rep,name=commitments,proto3" json:"commitments,omitempty"`
+}
+
+func (x *PolyCommitments) Reset() {
+	*x = PolyCommitments{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_shmsg_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolyCommitments) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolyCommitments) ProtoMessage() {}
+
+func (x *PolyCommitments) ProtoReflect() protoreflect.Message {
+	mi := &file_shmsg_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolyCommitments.ProtoReflect.Descriptor instead.
+func (*PolyCommitments) Descriptor() ([]byte, []int) {
+	return file_shmsg_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PolyCommitments) GetCommitments() []*PolyCommitment {
+	if x != nil {
+		return x.Commitments
+	}
+	return nil
+}
+
+type SkipCipherVote struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BatchIndex uint64 `protobuf:"varint,1,opt,name=batch_index,json=batchIndex,proto3" json:"batch_index,omitempty"`
+}
+
+func (x *SkipCipherVote) Reset() {
+	*x = SkipCipherVote{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_shmsg_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SkipCipherVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipCipherVote) ProtoMessage() {}
+
+func (x *SkipCipherVote) ProtoReflect() protoreflect.Message {
+	mi := &file_shmsg_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipCipherVote.ProtoReflect.Descriptor instead.
+func (*SkipCipherVote) Descriptor() ([]byte, []int) {
+	return file_shmsg_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SkipCipherVote) GetBatchIndex() uint64 {
+	if x != nil {
+		return x.BatchIndex
+	}
+	return 0
+}
+
+type HaltResumeVote struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	HaltHeight uint64 `protobuf:"varint,1,opt,name=halt_height,json=haltHeight,proto3" json:"halt_height,omitempty"` // last shuttermint height the keyper has seen before the halt
+}
+
+func (x *HaltResumeVote) Reset() {
+	*x = HaltResumeVote{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_shmsg_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HaltResumeVote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HaltResumeVote) ProtoMessage() {}
+
+func (x *HaltResumeVote) ProtoReflect() protoreflect.Message {
+	mi := &file_shmsg_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HaltResumeVote.ProtoReflect.Descriptor instead.
+func (*HaltResumeVote) Descriptor() ([]byte, []int) {
+	return file_shmsg_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *HaltResumeVote) GetHaltHeight() uint64 {
+	if x != nil {
+		return x.HaltHeight
+	}
+	return 0
+}
+
+type Capabilities struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version  string   `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`   // software version of the keyper
+	Features []string `protobuf:"bytes,2,rep,name=features,proto3" json:"features,omitempty"` // optional sub-protocols the keyper supports
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_shmsg_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_shmsg_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_shmsg_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Capabilities) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Capabilities) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+type DecryptionApproval struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Eon   uint64 `protobuf:"varint,1,opt,name=eon,proto3" json:"eon,omitempty"`
+	Epoch uint64 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+}
+
+func (x *DecryptionApproval) Reset() {
+	*x = DecryptionApproval{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_shmsg_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DecryptionApproval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecryptionApproval) ProtoMessage() {}
+
+func (x *DecryptionApproval) ProtoReflect() protoreflect.Message {
+	mi := &file_shmsg_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecryptionApproval.ProtoReflect.Descriptor instead.
+func (*DecryptionApproval) Descriptor() ([]byte, []int) {
+	return file_shmsg_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DecryptionApproval) GetEon() uint64 {
+	if x != nil {
+		return x.Eon
+	}
+	return 0
+}
+
+func (x *DecryptionApproval) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
 type Message struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -776,17 +1146,23 @@ type Message struct {
 	//	*Message_DecryptionSignature
 	//	*Message_PolyEval
 	//	*Message_PolyCommitment
+	//	*Message_PolyCommitments
 	//	*Message_Accusation
 	//	*Message_Apology
 	//	*Message_EonStartVote
 	//	*Message_EpochSecretKeyShare
+	//	*Message_DirectMessage
+	//	*Message_SkipCipherVote
+	//	*Message_HaltResumeVote
+	//	*Message_Capabilities
+	//	*Message_DecryptionApproval
 	Payload isMessage_Payload `protobuf_oneof:"payload"`
 }
 
 func (x *Message) Reset() {
 	*x = Message{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[13]
+		mi := &file_shmsg_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -799,7 +1175,7 @@ func (x *Message) String() string {
 func (*Message) ProtoMessage() {}
 
 func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[13]
+	mi := &file_shmsg_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -812,7 +1188,7 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Message.ProtoReflect.Descriptor instead.
 func (*Message) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{13}
+	return file_shmsg_proto_rawDescGZIP(), []int{19}
 }
 
 func (m *Message) GetPayload() isMessage_Payload {
@@ -864,6 +1240,13 @@ func (x *Message) GetPolyCommitment() *PolyCommitment {
 	return nil
 }
 
+func (x *Message) GetPolyCommitments() *PolyCommitments {
+	if x, ok := x.GetPayload().(*Message_PolyCommitments); ok {
+		return x.PolyCommitments
+	}
+	return nil
+}
+
 func (x *Message) GetAccusation() *Accusation {
 	if x, ok := x.GetPayload().(*Message_Accusation); ok {
 		return x.Accusation
@@ -892,6 +1275,41 @@ func (x *Message) GetEpochSecretKeyShare() *EpochSecretKeyShare {
 	return nil
 }
 
+func (x *Message) GetDirectMessage() *DirectMessage {
+	if x, ok := x.GetPayload().(*Message_DirectMessage); ok {
+		return x.DirectMessage
+	}
+	return nil
+}
+
+func (x *Message) GetSkipCipherVote() *SkipCipherVote {
+	if x, ok := x.GetPayload().(*Message_SkipCipherVote); ok {
+		return x.SkipCipherVote
+	}
+	return nil
+}
+
+func (x *Message) GetHaltResumeVote() *HaltResumeVote {
+	if x, ok := x.GetPayload().(*Message_HaltResumeVote); ok {
+		return x.HaltResumeVote
+	}
+	return nil
+}
+
+func (x *Message) GetCapabilities() *Capabilities {
+	if x, ok := x.GetPayload().(*Message_Capabilities); ok {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *Message) GetDecryptionApproval() *DecryptionApproval {
+	if x, ok := x.GetPayload().(*Message_DecryptionApproval); ok {
+		return x.DecryptionApproval
+	}
+	return nil
+}
+
 type isMessage_Payload interface {
 	isMessage_Payload()
 }
@@ -921,6 +1339,10 @@ type Message_PolyCommitment struct {
 	PolyCommitment *PolyCommitment `protobuf:"bytes,10,opt,name=poly_commitment,json=polyCommitment,proto3,oneof"`
 }
 
+type Message_PolyCommitments struct {
+	PolyCommitments *PolyCommitments `protobuf:"bytes,16,opt,name=poly_commitments,json=polyCommitments,proto3,oneof"`
+}
+
 type Message_Accusation struct {
 	Accusation *Accusation `protobuf:"bytes,11,opt,name=accusation,proto3,oneof"`
 }
@@ -937,6 +1359,26 @@ type Message_EpochSecretKeyShare struct {
 	EpochSecretKeyShare *EpochSecretKeyShare `protobuf:"bytes,14,opt,name=epoch_secret_key_share,json=epochSecretKeyShare,proto3,oneof"`
 }
 
+type Message_DirectMessage struct {
+	DirectMessage *DirectMessage `protobuf:"bytes,15,opt,name=direct_message,json=directMessage,proto3,oneof"`
+}
+
+type Message_SkipCipherVote struct {
+	SkipCipherVote *SkipCipherVote `protobuf:"bytes,17,opt,name=skip_cipher_vote,json=skipCipherVote,proto3,oneof"`
+}
+
+type Message_HaltResumeVote struct {
+	HaltResumeVote *HaltResumeVote `protobuf:"bytes,18,opt,name=halt_resume_vote,json=haltResumeVote,proto3,oneof"`
+}
+
+type Message_Capabilities struct {
+	Capabilities *Capabilities `protobuf:"bytes,19,opt,name=capabilities,proto3,oneof"`
+}
+
+type Message_DecryptionApproval struct {
+	DecryptionApproval *DecryptionApproval `protobuf:"bytes,20,opt,name=decryption_approval,json=decryptionApproval,proto3,oneof"`
+}
+
 func (*Message_BatchConfig) isMessage_Payload() {}
 
 func (*Message_BatchConfigStarted) isMessage_Payload() {}
@@ -949,6 +1391,8 @@ func (*Message_PolyEval) isMessage_Payload() {}
 
 func (*Message_PolyCommitment) isMessage_Payload() {}
 
+func (*Message_PolyCommitments) isMessage_Payload() {}
+
 func (*Message_Accusation) isMessage_Payload() {}
 
 func (*Message_Apology) isMessage_Payload() {}
@@ -957,6 +1401,16 @@ func (*Message_EonStartVote) isMessage_Payload() {}
 
 func (*Message_EpochSecretKeyShare) isMessage_Payload() {}
 
+func (*Message_DirectMessage) isMessage_Payload() {}
+
+func (*Message_SkipCipherVote) isMessage_Payload() {}
+
+func (*Message_HaltResumeVote) isMessage_Payload() {}
+
+func (*Message_Capabilities) isMessage_Payload() {}
+
+func (*Message_DecryptionApproval) isMessage_Payload() {}
+
 type MessageWithNonce struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -970,7 +1424,7 @@ type MessageWithNonce struct {
 func (x *MessageWithNonce) Reset() {
 	*x = MessageWithNonce{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_shmsg_shmsg_proto_msgTypes[14]
+		mi := &file_shmsg_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -983,7 +1437,7 @@ func (x *MessageWithNonce) String() string {
 func (*MessageWithNonce) ProtoMessage() {}
 
 func (x *MessageWithNonce) ProtoReflect() protoreflect.Message {
-	mi := &file_shmsg_shmsg_proto_msgTypes[14]
+	mi := &file_shmsg_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -996,7 +1450,7 @@ func (x *MessageWithNonce) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MessageWithNonce.ProtoReflect.Descriptor instead.
 func (*MessageWithNonce) Descriptor() ([]byte, []int) {
-	return file_shmsg_shmsg_proto_rawDescGZIP(), []int{14}
+	return file_shmsg_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *MessageWithNonce) GetMsg() *Message {
@@ -1020,144 +1474,212 @@ func (x *MessageWithNonce) GetRandomNonce() uint64 {
 	return 0
 }
 
-var File_shmsg_shmsg_proto protoreflect.FileDescriptor
-
-var file_shmsg_shmsg_proto_rawDesc = []byte{
-	0x0a, 0x11, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2f, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x22, 0x1e, 0x0a, 0x02, 0x47, 0x31,
-	0x12, 0x18, 0x0a, 0x07, 0x67, 0x31, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x07, 0x67, 0x31, 0x62, 0x79, 0x74, 0x65, 0x73, 0x22, 0x1e, 0x0a, 0x02, 0x47, 0x32,
-	0x12, 0x18, 0x0a, 0x07, 0x67, 0x32, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x07, 0x67, 0x32, 0x62, 0x79, 0x74, 0x65, 0x73, 0x22, 0x1e, 0x0a, 0x02, 0x47, 0x54,
-	0x12, 0x18, 0x0a, 0x07, 0x67, 0x74, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x07, 0x67, 0x74, 0x62, 0x79, 0x74, 0x65, 0x73, 0x22, 0x94, 0x02, 0x0a, 0x0b, 0x42,
-	0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74,
-	0x61, 0x72, 0x74, 0x5f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x42, 0x61, 0x74, 0x63,
-	0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72,
-	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x73,
-	0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x36,
-	0x0a, 0x17, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
-	0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x15, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41,
-	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x63, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61,
-	0x72, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72,
-	0x74, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x11, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
-	0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
-	0x64, 0x22, 0x42, 0x0a, 0x12, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x62, 0x61, 0x74, 0x63, 0x68,
-	0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x10, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x6f, 0x0a, 0x07, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e,
-	0x12, 0x30, 0x0a, 0x14, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x75,
-	0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x12,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b,
-	0x65, 0x79, 0x12, 0x32, 0x0a, 0x15, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x13, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x75, 0x62,
-	0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x22, 0x54, 0x0a, 0x13, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1f, 0x0a,
-	0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x0a, 0x62, 0x61, 0x74, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c,
-	0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x63, 0x0a, 0x08,
-	0x50, 0x6f, 0x6c, 0x79, 0x45, 0x76, 0x61, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65,
-	0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x72,
-	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x6e, 0x63, 0x72,
-	0x79, 0x70, 0x74, 0x65, 0x64, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0c, 0x52, 0x0e, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x45, 0x76, 0x61, 0x6c,
-	0x73, 0x22, 0x3a, 0x0a, 0x0e, 0x50, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d,
-	0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x06, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x73, 0x22, 0x38, 0x0a,
-	0x0a, 0x41, 0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x65,
-	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x18, 0x0a,
-	0x07, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x07,
-	0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x64, 0x22, 0x56, 0x0a, 0x07, 0x41, 0x70, 0x6f, 0x6c, 0x6f,
-	0x67, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x03, 0x65, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x72, 0x73,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x08, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x72, 0x73,
-	0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x03,
-	0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x6f, 0x6c, 0x79, 0x45, 0x76, 0x61, 0x6c, 0x73, 0x22,
-	0x53, 0x0a, 0x13, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x4b, 0x65,
-	0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63,
-	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x14,
-	0x0a, 0x05, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x73,
-	0x68, 0x61, 0x72, 0x65, 0x22, 0x3a, 0x0a, 0x0c, 0x45, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x72, 0x74,
-	0x56, 0x6f, 0x74, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x62, 0x61,
+var File_shmsg_proto protoreflect.FileDescriptor
+
+var file_shmsg_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x73,
+	0x68, 0x6d, 0x73, 0x67, 0x22, 0x1e, 0x0a, 0x02, 0x47, 0x31, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x31,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x67, 0x31, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x22, 0x1e, 0x0a, 0x02, 0x47, 0x32, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x32,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x67, 0x32, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x22, 0x1e, 0x0a, 0x02, 0x47, 0x54, 0x12, 0x18, 0x0a, 0x07, 0x67, 0x74,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x67, 0x74, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x22, 0x85, 0x04, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x2a, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x62, 0x61,
 	0x74, 0x63, 0x68, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
 	0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78,
-	0x22, 0xfd, 0x04, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x37, 0x0a, 0x0c,
-	0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x48, 0x00, 0x52, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x4d, 0x0a, 0x14, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x42, 0x61, 0x74, 0x63,
-	0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x48, 0x00,
-	0x52, 0x12, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x74, 0x61,
-	0x72, 0x74, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x69, 0x6e,
-	0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x43,
-	0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e, 0x48, 0x00, 0x52, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x49,
-	0x6e, 0x12, 0x4f, 0x0a, 0x14, 0x64, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
-	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69,
-	0x6f, 0x6e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x13, 0x64,
-	0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x12, 0x2e, 0x0a, 0x09, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x18,
-	0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x50, 0x6f,
-	0x6c, 0x79, 0x45, 0x76, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x70, 0x6f, 0x6c, 0x79, 0x45, 0x76,
-	0x61, 0x6c, 0x12, 0x40, 0x0a, 0x0f, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69,
-	0x74, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68,
-	0x6d, 0x73, 0x67, 0x2e, 0x50, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65,
-	0x6e, 0x74, 0x48, 0x00, 0x52, 0x0e, 0x70, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
-	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x33, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67,
-	0x2e, 0x41, 0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0a, 0x61,
-	0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x07, 0x61, 0x70, 0x6f,
-	0x6c, 0x6f, 0x67, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x6d,
-	0x73, 0x67, 0x2e, 0x41, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x48, 0x00, 0x52, 0x07, 0x61, 0x70,
-	0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x3b, 0x0a, 0x0e, 0x65, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61,
-	0x72, 0x74, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
-	0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x45, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x56, 0x6f,
-	0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x65, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x56, 0x6f,
-	0x74, 0x65, 0x12, 0x51, 0x0a, 0x16, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x5f, 0x73, 0x65, 0x63, 0x72,
-	0x65, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x0e, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x45, 0x70, 0x6f, 0x63, 0x68,
-	0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x48, 0x00,
-	0x52, 0x13, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x4b, 0x65, 0x79,
-	0x53, 0x68, 0x61, 0x72, 0x65, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
-	0x22, 0x72, 0x0a, 0x10, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x57, 0x69, 0x74, 0x68, 0x4e,
-	0x6f, 0x6e, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x5f,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x49,
-	0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x5f, 0x6e, 0x6f, 0x6e, 0x63,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x4e,
-	0x6f, 0x6e, 0x63, 0x65, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x12, 0x18, 0x0a, 0x07, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0c, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x70, 0x65, 0x72, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x36, 0x0a, 0x17, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x15, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x2c, 0x0a,
+	0x11, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x6f, 0x72, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x65,
+	0x70, 0x6f, 0x63, 0x68, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x18,
+	0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x65, 0x72, 0x5f, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0e, 0x70, 0x65, 0x72, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x45, 0x70, 0x6f, 0x63, 0x68, 0x73,
+	0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x65, 0x5f, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x74, 0x65, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x65,
+	0x70, 0x6f, 0x63, 0x68, 0x4f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x5f, 0x73, 0x74, 0x72, 0x69, 0x64, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0b, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x53, 0x74, 0x72, 0x69, 0x64, 0x65, 0x12, 0x2d, 0x0a,
+	0x12, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x65, 0x6e, 0x63, 0x72, 0x79,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x42, 0x0a, 0x12,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x74, 0x61, 0x72, 0x74,
+	0x65, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x78,
+	0x22, 0x6f, 0x0a, 0x07, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e, 0x12, 0x30, 0x0a, 0x14, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x12, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x6f, 0x72, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x32, 0x0a,
+	0x15, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x13, 0x65, 0x6e,
+	0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x22, 0x54, 0x0a, 0x13, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x63,
+	0x68, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x62,
+	0x61, 0x74, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x63, 0x0a, 0x08, 0x50, 0x6f, 0x6c, 0x79, 0x45,
+	0x76, 0x61, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65,
+	0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x72, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64,
+	0x5f, 0x65, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0e, 0x65, 0x6e,
+	0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x45, 0x76, 0x61, 0x6c, 0x73, 0x22, 0x3a, 0x0a, 0x0e,
+	0x50, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c,
+	0x52, 0x06, 0x67, 0x61, 0x6d, 0x6d, 0x61, 0x73, 0x22, 0x38, 0x0a, 0x0a, 0x41, 0x63, 0x63, 0x75,
+	0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x63, 0x63, 0x75,
+	0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x07, 0x61, 0x63, 0x63, 0x75, 0x73,
+	0x65, 0x64, 0x22, 0x56, 0x0a, 0x07, 0x41, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0c, 0x52, 0x08, 0x61, 0x63, 0x63, 0x75, 0x73, 0x65, 0x72, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x70,
+	0x6f, 0x6c, 0x79, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x09, 0x70, 0x6f, 0x6c, 0x79, 0x45, 0x76, 0x61, 0x6c, 0x73, 0x22, 0x71, 0x0a, 0x13, 0x45, 0x70,
+	0x6f, 0x63, 0x68, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72,
+	0x65, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03,
+	0x65, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x61,
+	0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x73, 0x68, 0x61, 0x72, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0x3a, 0x0a,
+	0x0c, 0x45, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x2a, 0x0a,
+	0x11, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x69, 0x6e, 0x64,
+	0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x6e, 0x0a, 0x0d, 0x44, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x72, 0x65,
+	0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x12, 0x2b, 0x0a, 0x11,
+	0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74,
+	0x65, 0x64, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x4a, 0x0a, 0x0f, 0x50, 0x6f, 0x6c,
+	0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x0b,
+	0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x50, 0x6f, 0x6c, 0x79, 0x43, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x31, 0x0a, 0x0e, 0x53, 0x6b, 0x69, 0x70, 0x43, 0x69, 0x70,
+	0x68, 0x65, 0x72, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68,
+	0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x62, 0x61,
+	0x74, 0x63, 0x68, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x31, 0x0a, 0x0e, 0x48, 0x61, 0x6c, 0x74,
+	0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x61,
+	0x6c, 0x74, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0a, 0x68, 0x61, 0x6c, 0x74, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x44, 0x0a, 0x0c, 0x43,
+	0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x73, 0x22, 0x3c, 0x0a, 0x12, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x41,
+	0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x65, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f,
+	0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x22,
+	0x90, 0x08, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x37, 0x0a, 0x0c, 0x62,
+	0x61, 0x74, 0x63, 0x68, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x48, 0x00, 0x52, 0x0b, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x4d, 0x0a, 0x14, 0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52,
+	0x12, 0x62, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x2b, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x69, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x49, 0x6e, 0x48, 0x00, 0x52, 0x07, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x49, 0x6e,
+	0x12, 0x4f, 0x0a, 0x14, 0x64, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x48, 0x00, 0x52, 0x13, 0x64, 0x65,
+	0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x12, 0x2e, 0x0a, 0x09, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x65, 0x76, 0x61, 0x6c, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x50, 0x6f, 0x6c,
+	0x79, 0x45, 0x76, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x70, 0x6f, 0x6c, 0x79, 0x45, 0x76, 0x61,
+	0x6c, 0x12, 0x40, 0x0a, 0x0f, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x6d, 0x65, 0x6e, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x6d,
+	0x73, 0x67, 0x2e, 0x50, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e,
+	0x74, 0x48, 0x00, 0x52, 0x0e, 0x70, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x43, 0x0a, 0x10, 0x70, 0x6f, 0x6c, 0x79, 0x5f, 0x63, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x50, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x48, 0x00, 0x52, 0x0f, 0x70, 0x6f, 0x6c, 0x79, 0x43, 0x6f, 0x6d,
+	0x6d, 0x69, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x33, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x75,
+	0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73,
+	0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48,
+	0x00, 0x52, 0x0a, 0x61, 0x63, 0x63, 0x75, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a,
+	0x07, 0x61, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x41, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x48, 0x00,
+	0x52, 0x07, 0x61, 0x70, 0x6f, 0x6c, 0x6f, 0x67, 0x79, 0x12, 0x3b, 0x0a, 0x0e, 0x65, 0x6f, 0x6e,
+	0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x45, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x65, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x51, 0x0a, 0x16, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x5f,
+	0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x45,
+	0x70, 0x6f, 0x63, 0x68, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x53, 0x68, 0x61,
+	0x72, 0x65, 0x48, 0x00, 0x52, 0x13, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x53, 0x65, 0x63, 0x72, 0x65,
+	0x74, 0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x3d, 0x0a, 0x0e, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x14, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x0d, 0x64, 0x69, 0x72, 0x65, 0x63,
+	0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x41, 0x0a, 0x10, 0x73, 0x6b, 0x69, 0x70,
+	0x5f, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x53, 0x6b, 0x69, 0x70, 0x43,
+	0x69, 0x70, 0x68, 0x65, 0x72, 0x56, 0x6f, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0e, 0x73, 0x6b, 0x69,
+	0x70, 0x43, 0x69, 0x70, 0x68, 0x65, 0x72, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x41, 0x0a, 0x10, 0x68,
+	0x61, 0x6c, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x18,
+	0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x48, 0x61,
+	0x6c, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0e,
+	0x68, 0x61, 0x6c, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x39,
+	0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x13,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x43, 0x61, 0x70,
+	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0c, 0x63, 0x61, 0x70,
+	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x4c, 0x0a, 0x13, 0x64, 0x65, 0x63,
+	0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c,
+	0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x44,
+	0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x41, 0x70, 0x70, 0x72, 0x6f, 0x76, 0x61,
+	0x6c, 0x48, 0x00, 0x52, 0x12, 0x64, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x41,
+	0x70, 0x70, 0x72, 0x6f, 0x76, 0x61, 0x6c, 0x42, 0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f,
+	0x61, 0x64, 0x22, 0x72, 0x0a, 0x10, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x57, 0x69, 0x74,
+	0x68, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x73, 0x68, 0x6d, 0x73, 0x67, 0x2e, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x5f, 0x6e, 0x6f,
+	0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x72, 0x61, 0x6e, 0x64, 0x6f,
+	0x6d, 0x4e, 0x6f, 0x6e, 0x63, 0x65, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x73, 0x68, 0x6d, 0x73,
+	0x67, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
-	file_shmsg_shmsg_proto_rawDescOnce sync.Once
-	file_shmsg_shmsg_proto_rawDescData = file_shmsg_shmsg_proto_rawDesc
+	file_shmsg_proto_rawDescOnce sync.Once
+	file_shmsg_proto_rawDescData = file_shmsg_proto_rawDesc
 )
 
-func file_shmsg_shmsg_proto_rawDescGZIP() []byte {
-	file_shmsg_shmsg_proto_rawDescOnce.Do(func() {
-		file_shmsg_shmsg_proto_rawDescData = protoimpl.X.CompressGZIP(file_shmsg_shmsg_proto_rawDescData)
+func file_shmsg_proto_rawDescGZIP() []byte {
+	file_shmsg_proto_rawDescOnce.Do(func() {
+		file_shmsg_proto_rawDescData = protoimpl.X.CompressGZIP(file_shmsg_proto_rawDescData)
 	})
-	return file_shmsg_shmsg_proto_rawDescData
+	return file_shmsg_proto_rawDescData
 }
 
-var file_shmsg_shmsg_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
-var file_shmsg_shmsg_proto_goTypes = []interface{}{
+var file_shmsg_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_shmsg_proto_goTypes = []interface{}{
 	(*G1)(nil),                  // 0: shmsg.G1
 	(*G2)(nil),                  // 1: shmsg.G2
 	(*GT)(nil),                  // 2: shmsg.GT
@@ -1171,35 +1693,48 @@ var file_shmsg_shmsg_proto_goTypes = []interface{}{
 	(*Apology)(nil),             // 10: shmsg.Apology
 	(*EpochSecretKeyShare)(nil), // 11: shmsg.EpochSecretKeyShare
 	(*EonStartVote)(nil),        // 12: shmsg.EonStartVote
-	(*Message)(nil),             // 13: shmsg.Message
-	(*MessageWithNonce)(nil),    // 14: shmsg.MessageWithNonce
-}
-var file_shmsg_shmsg_proto_depIdxs = []int32{
-	3,  // 0: shmsg.Message.batch_config:type_name -> shmsg.BatchConfig
-	4,  // 1: shmsg.Message.batch_config_started:type_name -> shmsg.BatchConfigStarted
-	5,  // 2: shmsg.Message.check_in:type_name -> shmsg.CheckIn
-	6,  // 3: shmsg.Message.decryption_signature:type_name -> shmsg.DecryptionSignature
-	7,  // 4: shmsg.Message.poly_eval:type_name -> shmsg.PolyEval
-	8,  // 5: shmsg.Message.poly_commitment:type_name -> shmsg.PolyCommitment
-	9,  // 6: shmsg.Message.accusation:type_name -> shmsg.Accusation
-	10, // 7: shmsg.Message.apology:type_name -> shmsg.Apology
-	12, // 8: shmsg.Message.eon_start_vote:type_name -> shmsg.EonStartVote
-	11, // 9: shmsg.Message.epoch_secret_key_share:type_name -> shmsg.EpochSecretKeyShare
-	13, // 10: shmsg.MessageWithNonce.msg:type_name -> shmsg.Message
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
-}
-
-func init() { file_shmsg_shmsg_proto_init() }
-func file_shmsg_shmsg_proto_init() {
-	if File_shmsg_shmsg_proto != nil {
+	(*DirectMessage)(nil),       // 13: shmsg.DirectMessage
+	(*PolyCommitments)(nil),     // 14: shmsg.PolyCommitments
+	(*SkipCipherVote)(nil),      // 15: shmsg.SkipCipherVote
+	(*HaltResumeVote)(nil),      // 16: shmsg.HaltResumeVote
+	(*Capabilities)(nil),        // 17: shmsg.Capabilities
+	(*DecryptionApproval)(nil),  // 18: shmsg.DecryptionApproval
+	(*Message)(nil),             // 19: shmsg.Message
+	(*MessageWithNonce)(nil),    // 20: shmsg.MessageWithNonce
+}
+var file_shmsg_proto_depIdxs = []int32{
+	8,  // 0: shmsg.PolyCommitments.commitments:type_name -> shmsg.PolyCommitment
+	3,  // 1: shmsg.Message.batch_config:type_name -> shmsg.BatchConfig
+	4,  // 2: shmsg.Message.batch_config_started:type_name -> shmsg.BatchConfigStarted
+	5,  // 3: shmsg.Message.check_in:type_name -> shmsg.CheckIn
+	6,  // 4: shmsg.Message.decryption_signature:type_name -> shmsg.DecryptionSignature
+	7,  // 5: shmsg.Message.poly_eval:type_name -> shmsg.PolyEval
+	8,  // 6: shmsg.Message.poly_commitment:type_name -> shmsg.PolyCommitment
+	14, // 7: shmsg.Message.poly_commitments:type_name -> shmsg.PolyCommitments
+	9,  // 8: shmsg.Message.accusation:type_name -> shmsg.Accusation
+	10, // 9: shmsg.Message.apology:type_name -> shmsg.Apology
+	12, // 10: shmsg.Message.eon_start_vote:type_name -> shmsg.EonStartVote
+	11, // 11: shmsg.Message.epoch_secret_key_share:type_name -> shmsg.EpochSecretKeyShare
+	13, // 12: shmsg.Message.direct_message:type_name -> shmsg.DirectMessage
+	15, // 13: shmsg.Message.skip_cipher_vote:type_name -> shmsg.SkipCipherVote
+	16, // 14: shmsg.Message.halt_resume_vote:type_name -> shmsg.HaltResumeVote
+	17, // 15: shmsg.Message.capabilities:type_name -> shmsg.Capabilities
+	18, // 16: shmsg.Message.decryption_approval:type_name -> shmsg.DecryptionApproval
+	19, // 17: shmsg.MessageWithNonce.msg:type_name -> shmsg.Message
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_shmsg_proto_init() }
+func file_shmsg_proto_init() {
+	if File_shmsg_proto != nil {
 		return
 	}
 	if !protoimpl.UnsafeEnabled {
-		file_shmsg_shmsg_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*G1); i {
 			case 0:
 				return &v.state
@@ -1211,7 +1746,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*G2); i {
 			case 0:
 				return &v.state
@@ -1223,7 +1758,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GT); i {
 			case 0:
 				return &v.state
@@ -1235,7 +1770,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BatchConfig); i {
 			case 0:
 				return &v.state
@@ -1247,7 +1782,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BatchConfigStarted); i {
 			case 0:
 				return &v.state
@@ -1259,7 +1794,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CheckIn); i {
 			case 0:
 				return &v.state
@@ -1271,7 +1806,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DecryptionSignature); i {
 			case 0:
 				return &v.state
@@ -1283,7 +1818,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PolyEval); i {
 			case 0:
 				return &v.state
@@ -1295,7 +1830,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PolyCommitment); i {
 			case 0:
 				return &v.state
@@ -1307,7 +1842,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Accusation); i {
 			case 0:
 				return &v.state
@@ -1319,7 +1854,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Apology); i {
 			case 0:
 				return &v.state
@@ -1331,7 +1866,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*EpochSecretKeyShare); i {
 			case 0:
 				return &v.state
@@ -1343,7 +1878,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*EonStartVote); i {
 			case 0:
 				return &v.state
@@ -1355,7 +1890,79 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DirectMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_shmsg_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolyCommitments); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_shmsg_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SkipCipherVote); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_shmsg_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HaltResumeVote); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_shmsg_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Capabilities); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_shmsg_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecryptionApproval); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_shmsg_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Message); i {
 			case 0:
 				return &v.state
@@ -1367,7 +1974,7 @@ func file_shmsg_shmsg_proto_init() {
 				return nil
 			}
 		}
-		file_shmsg_shmsg_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_shmsg_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*MessageWithNonce); i {
 			case 0:
 				return &v.state
@@ -1380,34 +1987,40 @@ func file_shmsg_shmsg_proto_init() {
 			}
 		}
 	}
-	file_shmsg_shmsg_proto_msgTypes[13].OneofWrappers = []interface{}{
+	file_shmsg_proto_msgTypes[19].OneofWrappers = []interface{}{
 		(*Message_BatchConfig)(nil),
 		(*Message_BatchConfigStarted)(nil),
 		(*Message_CheckIn)(nil),
 		(*Message_DecryptionSignature)(nil),
 		(*Message_PolyEval)(nil),
 		(*Message_PolyCommitment)(nil),
+		(*Message_PolyCommitments)(nil),
 		(*Message_Accusation)(nil),
 		(*Message_Apology)(nil),
 		(*Message_EonStartVote)(nil),
 		(*Message_EpochSecretKeyShare)(nil),
+		(*Message_DirectMessage)(nil),
+		(*Message_SkipCipherVote)(nil),
+		(*Message_HaltResumeVote)(nil),
+		(*Message_Capabilities)(nil),
+		(*Message_DecryptionApproval)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_shmsg_shmsg_proto_rawDesc,
+			RawDescriptor: file_shmsg_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
-		GoTypes:           file_shmsg_shmsg_proto_goTypes,
-		DependencyIndexes: file_shmsg_shmsg_proto_depIdxs,
-		MessageInfos:      file_shmsg_shmsg_proto_msgTypes,
+		GoTypes:           file_shmsg_proto_goTypes,
+		DependencyIndexes: file_shmsg_proto_depIdxs,
+		MessageInfos:      file_shmsg_proto_msgTypes,
 	}.Build()
-	File_shmsg_shmsg_proto = out.File
-	file_shmsg_shmsg_proto_rawDesc = nil
-	file_shmsg_shmsg_proto_goTypes = nil
-	file_shmsg_shmsg_proto_depIdxs = nil
+	File_shmsg_proto = out.File
+	file_shmsg_proto_rawDesc = nil
+	file_shmsg_proto_goTypes = nil
+	file_shmsg_proto_depIdxs = nil
 }
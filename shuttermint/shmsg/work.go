@@ -0,0 +1,55 @@
+package shmsg
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxMessageWorkBits is the highest amount of work that can be required for messages.
+const MaxMessageWorkBits = 32
+
+// workCheckInterval is the number of nonces AddWork tries between checks of its context.
+const workCheckInterval = 1 << 12
+
+// MessageWork returns the amount of work that has been put into a marshaled MessageWithNonce, i.e.
+// the number of leading zero bits of its hash. Shuttermint chains can require a minimum amount
+// of work as fee for every message, so that the chain cannot be spammed cheaply.
+func MessageWork(marshaled []byte) uint64 {
+	h := crypto.Keccak256(marshaled)
+	var work uint64
+	for _, b := range h {
+		if b != 0 {
+			return work + uint64(bits.LeadingZeros8(b))
+		}
+		work += 8
+	}
+	return work
+}
+
+// AddWork changes the nonce of the given message until its work is at least the given number of
+// bits. It returns the resulting work. It gives up with the context's error once the context is
+// done.
+func AddWork(ctx context.Context, msg *MessageWithNonce, workBits uint64) (uint64, error) {
+	if workBits > MaxMessageWorkBits {
+		return 0, errors.Errorf("cannot add more than %d bits of work, %d requested", MaxMessageWorkBits, workBits)
+	}
+	for i := 1; ; i++ {
+		marshaled, err := proto.Marshal(msg)
+		if err != nil {
+			return 0, err
+		}
+		if work := MessageWork(marshaled); work >= workBits {
+			return work, nil
+		}
+		msg.RandomNonce++
+		if i%workCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package shmsg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/v3/assert"
+)
+
+func TestMessageWork(t *testing.T) {
+	assert.Equal(t, MessageWork([]byte("foo")), uint64(1)) // keccak256("foo") starts with 0x41
+
+	msg := makeMessage()
+	work, err := AddWork(context.Background(), msg, 10)
+	assert.NilError(t, err)
+	assert.Assert(t, work >= 10)
+
+	privateKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	signed, err := SignMessage(msg, privateKey)
+	assert.NilError(t, err)
+	assert.Equal(t, MessageWork(signed[crypto.SignatureLength:]), work)
+
+	_, err = AddWork(context.Background(), msg, MaxMessageWorkBits+1)
+	assert.ErrorContains(t, err, "cannot add")
+}
+
+func TestAddWorkCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := AddWork(ctx, makeMessage(), MaxMessageWorkBits)
+	assert.Equal(t, err, context.Canceled)
+}